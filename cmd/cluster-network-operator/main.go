@@ -17,6 +17,8 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 
 	_ "github.com/openshift/cluster-network-operator/pkg/client"
+	"github.com/openshift/cluster-network-operator/pkg/cmd/check"
+	"github.com/openshift/cluster-network-operator/pkg/cmd/renderdiff"
 	"github.com/openshift/cluster-network-operator/pkg/version"
 
 	utilflag "k8s.io/component-base/cli/flag"
@@ -86,6 +88,8 @@ which is a kubeconfig from which to take just the URL to the apiserver`,
 	cmd2.Short = "Start the cluster network operator"
 
 	cmd.AddCommand(cmd2)
+	cmd.AddCommand(renderdiff.NewRenderDiffCommand())
+	cmd.AddCommand(check.NewCheckCommand())
 
 	return cmd
 }
@@ -51,6 +51,62 @@ oc annotate --local -o yaml \
   "${SINGLE_NODE_DEV_PROFILE}" \
   -f _output/crds/network.operator.openshift.io_operatorpkis.yaml >> manifests/0000_70_cluster-network-operator_01_pki_crd.yaml
 
+echo "${HEADER}" > manifests/0000_70_cluster-network-operator_01_topology_crd.yaml
+oc annotate --local -o yaml \
+  "${RELEASE_PROFILE}" \
+  "${ROKS_PROFILE}" \
+  "${SINGLE_NODE_DEV_PROFILE}" \
+  -f _output/crds/network.operator.openshift.io_networktopologies.yaml >> manifests/0000_70_cluster-network-operator_01_topology_crd.yaml
+
+echo "${HEADER}" > manifests/0000_70_cluster-network-operator_01_cluster_network_usage_crd.yaml
+oc annotate --local -o yaml \
+  "${RELEASE_PROFILE}" \
+  "${ROKS_PROFILE}" \
+  "${SINGLE_NODE_DEV_PROFILE}" \
+  -f _output/crds/network.operator.openshift.io_clusternetworkusages.yaml >> manifests/0000_70_cluster-network-operator_01_cluster_network_usage_crd.yaml
+
+echo "${HEADER}" > manifests/0000_70_cluster-network-operator_01_ovsflows_crd.yaml
+oc annotate --local -o yaml \
+  "${RELEASE_PROFILE}" \
+  "${ROKS_PROFILE}" \
+  "${SINGLE_NODE_DEV_PROFILE}" \
+  -f _output/crds/network.operator.openshift.io_ovsflowsconfigs.yaml >> manifests/0000_70_cluster-network-operator_01_ovsflows_crd.yaml
+
+echo "${HEADER}" > manifests/0000_70_cluster-network-operator_01_egress_snat_crd.yaml
+oc annotate --local -o yaml \
+  "${RELEASE_PROFILE}" \
+  "${ROKS_PROFILE}" \
+  "${SINGLE_NODE_DEV_PROFILE}" \
+  -f _output/crds/network.operator.openshift.io_egresssnatpools.yaml >> manifests/0000_70_cluster-network-operator_01_egress_snat_crd.yaml
+
+echo "${HEADER}" > manifests/0000_70_cluster-network-operator_01_ovn_probe_crd.yaml
+oc annotate --local -o yaml \
+  "${RELEASE_PROFILE}" \
+  "${ROKS_PROFILE}" \
+  "${SINGLE_NODE_DEV_PROFILE}" \
+  -f _output/crds/network.operator.openshift.io_ovnprobeprofiles.yaml >> manifests/0000_70_cluster-network-operator_01_ovn_probe_crd.yaml
+
+echo "${HEADER}" > manifests/0000_70_cluster-network-operator_01_ovn_route_advertisement_crd.yaml
+oc annotate --local -o yaml \
+  "${RELEASE_PROFILE}" \
+  "${ROKS_PROFILE}" \
+  "${SINGLE_NODE_DEV_PROFILE}" \
+  -f _output/crds/network.operator.openshift.io_ovnrouteadvertisements.yaml >> manifests/0000_70_cluster-network-operator_01_ovn_route_advertisement_crd.yaml
+
+echo "${HEADER}" > manifests/0000_70_cluster-network-operator_01_ovn_node_pool_crd.yaml
+oc annotate --local -o yaml \
+  "${RELEASE_PROFILE}" \
+  "${ROKS_PROFILE}" \
+  "${SINGLE_NODE_DEV_PROFILE}" \
+  -f _output/crds/network.operator.openshift.io_ovnnodepools.yaml >> manifests/0000_70_cluster-network-operator_01_ovn_node_pool_crd.yaml
+
+echo "${HEADER}" > manifests/0000_70_cluster-network-operator_01_ipsec_external_gateway_crd.yaml
+oc annotate --local -o yaml \
+  "${RELEASE_PROFILE}" \
+  "${ROKS_PROFILE}" \
+  "${SINGLE_NODE_DEV_PROFILE}" \
+  -f _output/crds/network.operator.openshift.io_ipsecexternalgateways.yaml >> manifests/0000_70_cluster-network-operator_01_ipsec_external_gateway_crd.yaml
+
 # and also the CRD from library-go
 oc annotate --local -o yaml --overwrite \
   "${RELEASE_PROFILE}" \
@@ -0,0 +1,57 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterNetworkUsage is the number of node subnets allocated out of each cluster network, for
+// capacity planning. It is read-only: the CNO maintains a single ClusterNetworkUsage called
+// "cluster", and updates its status when the default network is OpenShiftSDN or OVNKubernetes.
+// The usage is not in the status of the network.config.openshift.io configuration, as that status
+// is an API of the openshift/api module, which the CNO cannot extend.
+//
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:path=clusternetworkusages,scope=Cluster
+// +kubebuilder:subresource:status
+type ClusterNetworkUsage struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status ClusterNetworkUsageStatus `json:"status,omitempty"`
+}
+
+// ClusterNetworkUsageStatus is the usage of the cluster networks.
+type ClusterNetworkUsageStatus struct {
+	// clusterNetworks is the usage of each clusterNetwork entry of the cluster configuration
+	// +optional
+	ClusterNetworks []ClusterNetworkEntryUsage `json:"clusterNetworks,omitempty"`
+}
+
+// ClusterNetworkEntryUsage is the usage of a cluster network.
+type ClusterNetworkEntryUsage struct {
+	// cidr is the cidr of the cluster network
+	CIDR string `json:"cidr"`
+
+	// hostPrefix is the size of the node subnets allocated out of the cluster network
+	HostPrefix uint32 `json:"hostPrefix"`
+
+	// allocatedSubnets is the number of node subnets allocated out of the cluster network
+	AllocatedSubnets int32 `json:"allocatedSubnets"`
+
+	// totalSubnets is the number of node subnets the cluster network holds
+	TotalSubnets int64 `json:"totalSubnets"`
+
+	// utilizationPercent is the percentage of the node subnets of the cluster network that are allocated
+	UtilizationPercent int32 `json:"utilizationPercent"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterNetworkUsageList contains a list of ClusterNetworkUsage
+type ClusterNetworkUsageList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterNetworkUsage `json:"items"`
+}
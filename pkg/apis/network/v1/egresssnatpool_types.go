@@ -0,0 +1,50 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// EgressSNATPool is a pool of pre-approved addresses the traffic of the pods leaving the cluster
+// through the gateway of a group of nodes is SNATed to, instead of the node IP, when the default
+// network is OVNKubernetes. When several pools select a node for the same cluster network, the
+// first one by name is used.
+//
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:path=egresssnatpools,scope=Cluster
+type EgressSNATPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:validation:Required
+	Spec EgressSNATPoolSpec `json:"spec"`
+}
+
+// EgressSNATPoolSpec is the addresses of the pool, and the cluster network and nodes it applies to.
+type EgressSNATPoolSpec struct {
+	// addresses are the IP addresses and CIDRs the egress traffic is SNATed to. They must not
+	// overlap the cluster and service networks.
+	// +kubebuilder:validation:MinItems=1
+	Addresses []string `json:"addresses"`
+
+	// clusterNetwork is the CIDR of the cluster network whose pods egress from the pool, as set in
+	// the network configuration. The pool applies to every cluster network of the IP family of its
+	// addresses when empty.
+	// +optional
+	ClusterNetwork string `json:"clusterNetwork,omitempty"`
+
+	// nodeSelector selects the nodes whose gateway SNATs to the pool. The pool applies to every
+	// node when empty.
+	// +optional
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// EgressSNATPoolList contains a list of EgressSNATPool
+type EgressSNATPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []EgressSNATPool `json:"items"`
+}
@@ -0,0 +1,49 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// IPsecExternalGateway is a host, or a gateway to networks, outside of the cluster that the nodes
+// encrypt their traffic to with IPsec, when the default network is OVNKubernetes with IPsec enabled
+// and the networkoperator.openshift.io/ipsec-mode annotation of the operator configuration is Full.
+// The peers authenticate each other with a pre-shared key.
+//
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:path=ipsecexternalgateways,scope=Cluster
+type IPsecExternalGateway struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:validation:Required
+	Spec IPsecExternalGatewaySpec `json:"spec"`
+}
+
+// IPsecExternalGatewaySpec is the address of the peer, the networks behind it, and its key.
+type IPsecExternalGatewaySpec struct {
+	// address is the IP address of the external host or gateway.
+	// +kubebuilder:validation:Required
+	Address string `json:"address"`
+
+	// subnets are the CIDRs reached through the gateway, of the IP family of its address. The
+	// traffic to them is encrypted in tunnel mode. When empty, only the traffic to the address
+	// itself is encrypted, in transport mode.
+	// +optional
+	Subnets []string `json:"subnets,omitempty"`
+
+	// pskSecretName is the name of the Secret, in the openshift-ovn-kubernetes namespace, whose
+	// "psk" key is the pre-shared key of the peer.
+	// +kubebuilder:validation:Required
+	PSKSecretName string `json:"pskSecretName"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// IPsecExternalGatewayList contains a list of IPsecExternalGateway
+type IPsecExternalGatewayList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IPsecExternalGateway `json:"items"`
+}
@@ -0,0 +1,67 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NetworkTopology is a summary of the logical topology of the OVN northbound database,
+// which drives the scale of the OVN control plane. It is read-only: the CNO maintains
+// a single NetworkTopology called "cluster", and periodically updates its status
+// when the default network is OVNKubernetes.
+//
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:path=networktopologies,scope=Cluster
+// +kubebuilder:subresource:status
+type NetworkTopology struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status NetworkTopologyStatus `json:"status,omitempty"`
+}
+
+// NetworkTopologyStatus is the number of objects of each kind of the OVN logical topology.
+type NetworkTopologyStatus struct {
+	// logicalSwitches is the number of logical switches
+	LogicalSwitches int32 `json:"logicalSwitches"`
+
+	// logicalSwitchPorts is the number of logical switch ports
+	LogicalSwitchPorts int32 `json:"logicalSwitchPorts"`
+
+	// logicalRouters is the number of logical routers
+	LogicalRouters int32 `json:"logicalRouters"`
+
+	// loadBalancers is the number of load balancers
+	LoadBalancers int32 `json:"loadBalancers"`
+
+	// acls is the number of ACLs
+	ACLs int32 `json:"acls"`
+
+	// nodes is the number of logical switch ports of the logical switch of each node
+	// +optional
+	Nodes []NodeTopology `json:"nodes,omitempty"`
+
+	// lastUpdateTime is when the topology was last summarized
+	// +optional
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// NodeTopology is the logical topology of a node.
+type NodeTopology struct {
+	// name is the name of the node
+	Name string `json:"name"`
+
+	// logicalSwitchPorts is the number of ports of the logical switch of the node,
+	// mostly one per pod of the node
+	LogicalSwitchPorts int32 `json:"logicalSwitchPorts"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NetworkTopologyList contains a list of NetworkTopology
+type NetworkTopologyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NetworkTopology `json:"items"`
+}
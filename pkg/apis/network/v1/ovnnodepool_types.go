@@ -0,0 +1,69 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// OVNNodePool sets the node mode of ovn-kubernetes on the nodes of a MachineConfigPool, when the
+// default network is OVNKubernetes. It replaces the dpu-mode-config ConfigMap, which set a single
+// mode for the nodes labeled network.operator.openshift.io/dpu-host or network.operator.openshift.io/dpu.
+// The nodes of no OVNNodePool run in the Full mode.
+//
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:path=ovnnodepools,scope=Cluster
+type OVNNodePool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:validation:Required
+	Spec OVNNodePoolSpec `json:"spec"`
+}
+
+// OVNNodePoolSpec is the pool of nodes, and the mode of ovn-kubernetes on them.
+type OVNNodePoolSpec struct {
+	// machineConfigPool is the name of the MachineConfigPool whose nodes, selected by its nodeSelector,
+	// run in the mode. The MachineConfigPools of the OVNNodePools must not share nodes.
+	// +kubebuilder:validation:MinLength=1
+	MachineConfigPool string `json:"machineConfigPool"`
+
+	// mode is the node mode of ovn-kubernetes: Full, the default, runs the whole of ovnkube-node;
+	// DPUHost runs the part of ovnkube-node managing the pods of hosts whose network is offloaded to
+	// a DPU; DPU runs only host-network pods on the DPUs of the infrastructure cluster, along with
+	// kube-proxy.
+	Mode NodeMode `json:"mode"`
+
+	// image is the ovn-kubernetes image of the ovnkube-node daemonset of a DPUHost pool, a build of
+	// ovn-kubernetes for the NICs of the vendor of its DPUs. Defaults to the image of the release.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// extraArgs are additional flags of ovnkube on the nodes of a DPUHost pool, for the configuration
+	// specific to the vendor of its DPUs, such as "--ovnkube-node-mgmt-port-netdev=enp3s0f0v0".
+	// Each is a single flag, with its value after "=".
+	// +optional
+	ExtraArgs []string `json:"extraArgs,omitempty"`
+}
+
+// NodeMode is a node mode of ovn-kubernetes
+// +kubebuilder:validation:Enum=Full;DPUHost;DPU
+type NodeMode string
+
+const (
+	// FullNodeMode runs the whole of ovnkube-node
+	FullNodeMode NodeMode = "Full"
+	// DPUHostNodeMode runs ovnkube-node for the hosts whose network is offloaded to a DPU
+	DPUHostNodeMode NodeMode = "DPUHost"
+	// DPUNodeMode runs only host-network pods, on the DPUs of the infrastructure cluster
+	DPUNodeMode NodeMode = "DPU"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// OVNNodePoolList contains a list of OVNNodePool
+type OVNNodePoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OVNNodePool `json:"items"`
+}
@@ -0,0 +1,55 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// OVNProbeProfile sets the intervals of the probes of ovn-controller for a group of nodes, when the
+// default network is OVNKubernetes, so that the nodes connected over high-latency links, such as
+// WAN-connected edge nodes, are not spuriously disconnected. When several profiles select a node,
+// the first one by name is used.
+//
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:path=ovnprobeprofiles,scope=Cluster
+type OVNProbeProfile struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:validation:Required
+	Spec OVNProbeProfileSpec `json:"spec"`
+}
+
+// OVNProbeProfileSpec is the probe intervals of the profile, and the nodes it applies to.
+type OVNProbeProfileSpec struct {
+	// nodeSelector selects the nodes the profile applies to. The profile applies to every node
+	// when empty.
+	// +optional
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+
+	// remoteProbeInterval is the interval, in milliseconds, of the inactivity probe of the
+	// connection of ovn-controller to the southbound database. 0 disables the probe, otherwise it
+	// must be at least 1000. The cluster-wide interval is used when unset.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=3600000
+	// +optional
+	RemoteProbeInterval *int32 `json:"remoteProbeInterval,omitempty"`
+
+	// openflowProbeInterval is the interval, in seconds, of the inactivity probe of the OpenFlow
+	// connection of ovn-controller to the local vswitch. 0 disables the probe. The default of
+	// ovnkube-node is used when unset.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=3600
+	// +optional
+	OpenFlowProbeInterval *int32 `json:"openflowProbeInterval,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// OVNProbeProfileList contains a list of OVNProbeProfile
+type OVNProbeProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OVNProbeProfile `json:"items"`
+}
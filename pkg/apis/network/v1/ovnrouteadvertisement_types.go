@@ -0,0 +1,77 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// OVNRouteAdvertisement advertises, over BGP, the subnets of the pods and the egress IPs of a group
+// of nodes to the routers of a routed datacenter fabric, when the default network is OVNKubernetes.
+// The routes are advertised by an FRR speaker on every node. When several advertisements select a
+// node, the first one by name is used.
+//
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:path=ovnrouteadvertisements,scope=Cluster
+type OVNRouteAdvertisement struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:validation:Required
+	Spec OVNRouteAdvertisementSpec `json:"spec"`
+}
+
+// OVNRouteAdvertisementSpec is the BGP sessions of the nodes, the routes they advertise, and the nodes it
+// applies to.
+type OVNRouteAdvertisementSpec struct {
+	// nodeSelector selects the nodes that advertise the routes. The advertisement applies to every
+	// node when empty.
+	// +optional
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+
+	// asn is the autonomous system number of the nodes.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4294967295
+	ASN int64 `json:"asn"`
+
+	// peers are the routers each node opens a BGP session with.
+	// +kubebuilder:validation:MinItems=1
+	Peers []BGPPeer `json:"peers"`
+
+	// advertisements are the routes the nodes advertise: PodNetwork, the subnets of the pods of
+	// the node, and EgressIP, the egress IPs assigned to the node.
+	// +kubebuilder:validation:MinItems=1
+	Advertisements []AdvertisementType `json:"advertisements"`
+}
+
+// BGPPeer is a router the nodes open a BGP session with
+type BGPPeer struct {
+	// address is the IP address of the router. The routes of the IP family of the address are
+	// advertised to it.
+	Address string `json:"address"`
+
+	// asn is the autonomous system number of the router.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4294967295
+	ASN int64 `json:"asn"`
+}
+
+// AdvertisementType is a kind of routes advertised by the nodes
+// +kubebuilder:validation:Enum=PodNetwork;EgressIP
+type AdvertisementType string
+
+const (
+	// PodNetworkAdvertisement advertises the subnets of the pods of the node
+	PodNetworkAdvertisement AdvertisementType = "PodNetwork"
+	// EgressIPAdvertisement advertises the egress IPs assigned to the node
+	EgressIPAdvertisement AdvertisementType = "EgressIP"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// OVNRouteAdvertisementList contains a list of OVNRouteAdvertisement
+type OVNRouteAdvertisementList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OVNRouteAdvertisement `json:"items"`
+}
@@ -0,0 +1,73 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// OVSFlowsConfig configures the export of the flows of the OVS bridge of every node to an
+// IPFIX collector, when the default network is OVNKubernetes. The CNO only honors the
+// OVSFlowsConfig called "ovs-flows-config" in the openshift-network-operator namespace.
+// It supersedes the ovs-flows-config ConfigMap, which the CNO converts to an OVSFlowsConfig
+// when there is none.
+//
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:path=ovsflowsconfigs,scope=Namespaced
+type OVSFlowsConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:validation:Required
+	Spec OVSFlowsConfigSpec `json:"spec"`
+}
+
+// OVSFlowsConfigSpec is the collector the flows are exported to, and how they are sampled and
+// aggregated. Either sharedTarget or nodePort must be set.
+type OVSFlowsConfigSpec struct {
+	// sharedTarget is the "<host>:<port>" of the collector shared by all the nodes
+	// +kubebuilder:validation:Pattern=`^.+:[0-9]+$`
+	// +optional
+	SharedTarget string `json:"sharedTarget,omitempty"`
+
+	// nodePort is the port of the collector listening on each node, when there is no shared
+	// collector
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	NodePort int32 `json:"nodePort,omitempty"`
+
+	// cacheActiveTimeout is the max period during which the flows are aggregated before they
+	// are sent, truncated to the second
+	// +optional
+	CacheActiveTimeout *metav1.Duration `json:"cacheActiveTimeout,omitempty"`
+
+	// cacheMaxFlows is the max number of flows in an aggregate, which is sent when it is reached
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	CacheMaxFlows *int32 `json:"cacheMaxFlows,omitempty"`
+
+	// sampling is the sampling rate of the flows: 100 means one flow in 100 is sent, 0 disables
+	// the sampling
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Sampling *int32 `json:"sampling,omitempty"`
+
+	// tls sends the flows to the shared collector over TLS, through a relay on each node
+	// +optional
+	TLS bool `json:"tls,omitempty"`
+
+	// caBundle is the PEM bundle the shared collector is verified with over TLS. The
+	// cluster-wide trusted CA bundle is used when empty.
+	// +optional
+	CABundle string `json:"caBundle,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// OVSFlowsConfigList contains a list of OVSFlowsConfig
+type OVSFlowsConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OVSFlowsConfig `json:"items"`
+}
@@ -19,6 +19,22 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(GroupVersion,
 		&OperatorPKI{},
 		&OperatorPKIList{},
+		&NetworkTopology{},
+		&NetworkTopologyList{},
+		&ClusterNetworkUsage{},
+		&ClusterNetworkUsageList{},
+		&OVSFlowsConfig{},
+		&OVSFlowsConfigList{},
+		&EgressSNATPool{},
+		&EgressSNATPoolList{},
+		&OVNProbeProfile{},
+		&OVNProbeProfileList{},
+		&OVNRouteAdvertisement{},
+		&OVNRouteAdvertisementList{},
+		&OVNNodePool{},
+		&OVNNodePoolList{},
+		&IPsecExternalGateway{},
+		&IPsecExternalGatewayList{},
 	)
 	metav1.AddToGroupVersion(scheme, GroupVersion)
 	return nil
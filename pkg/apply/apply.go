@@ -3,9 +3,9 @@ package apply
 import (
 	"context"
 	"fmt"
-	"log"
 
 	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/openshift/cluster-network-operator/pkg/util/logging"
 
 	"github.com/pkg/errors"
 
@@ -17,6 +17,8 @@ import (
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+var log = logging.Logger(logging.Apply)
+
 // ApplyObject applies the desired object against the apiserver,
 // merging it with any existing objects if already present.
 func ApplyObject(ctx context.Context, client k8sclient.Client, obj *uns.Unstructured) error {
@@ -28,7 +30,7 @@ func ApplyObject(ctx context.Context, client k8sclient.Client, obj *uns.Unstruct
 	gvk := obj.GroupVersionKind()
 	// used for logging and errors
 	objDesc := fmt.Sprintf("(%s) %s/%s", gvk.String(), namespace, name)
-	log.Printf("reconciling %s", objDesc)
+	log.V(2).Info("reconciling", "object", objDesc)
 
 	if err := IsObjectSupported(obj); err != nil {
 		return errors.Wrapf(err, "object %s unsupported", objDesc)
@@ -37,21 +39,28 @@ func ApplyObject(ctx context.Context, client k8sclient.Client, obj *uns.Unstruct
 	// Get existing
 	existing := &uns.Unstructured{}
 	existing.SetGroupVersionKind(gvk)
-	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+	// Transient failures are retried from the Get, so that conflicts are resolved against the
+	// latest version of the object
+	attempts := 0
+	err := retry.OnError(retryPolicy(obj), isTransient, func() error {
+		attempts++
+		if attempts > 1 {
+			log.Info("retrying after a transient failure", "object", objDesc, "attempt", attempts)
+		}
 		err := client.Get(ctx, types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, existing)
 
 		if err != nil && apierrors.IsNotFound(err) {
-			log.Printf("does not exist, creating %s", objDesc)
+			log.Info("does not exist, creating", "object", objDesc)
 			err := client.Create(ctx, obj)
 			if err != nil {
-				log.Printf("create of %s was unsucessful", objDesc)
+				log.Error(err, "create was unsuccessful", "object", objDesc)
 				return err
 			}
-			log.Printf("successfully created %s", objDesc)
+			log.Info("successfully created", "object", objDesc)
 			return nil
 		}
 		if err != nil {
-			log.Printf("could not retrieve %s", objDesc)
+			log.Error(err, "could not retrieve", "object", objDesc)
 			return err
 		}
 
@@ -62,22 +71,26 @@ func ApplyObject(ctx context.Context, client k8sclient.Client, obj *uns.Unstruct
 
 		// Merge the desired object with what actually exists
 		if err := MergeObjectForUpdate(existing, obj); err != nil {
-			log.Printf("could not merge %s with existing", objDesc)
+			log.Error(err, "could not merge with existing", "object", objDesc)
 			return err
 		}
 		if !equality.Semantic.DeepEqual(existing, obj) {
 			if err := client.Update(ctx, obj); err != nil {
-				log.Printf("update of %s was unsuccessful", objDesc)
+				log.Error(err, "update was unsuccessful", "object", objDesc)
 				return err
 			} else {
-				log.Printf("update was successful")
+				log.Info("update was successful", "object", objDesc)
 			}
 		}
 		return nil
 	})
 
 	if err != nil {
-		return errors.Wrapf(err, "ApplyObject of %s was unsuccessful", objDesc)
+		return &Error{
+			Transient: isTransient(err),
+			Attempts:  attempts,
+			err:       errors.Wrapf(err, "ApplyObject of %s was unsuccessful", objDesc),
+		}
 	}
 	return nil
 }
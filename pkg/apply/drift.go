@@ -0,0 +1,152 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/openshift/cluster-network-operator/pkg/names"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Drift is a rendered object whose live state no longer matches it
+type Drift struct {
+	Object string
+	// Fields are the paths of the fields that differ, or empty if the object is missing
+	Fields []string
+}
+
+func (d Drift) String() string {
+	if len(d.Fields) == 0 {
+		return d.Object + " (missing)"
+	}
+	return fmt.Sprintf("%s (%v)", d.Object, d.Fields)
+}
+
+// DetectDrift compares the live objects with the desired ones, and returns those that
+// were changed or removed by something else than the operator. Only the fields set in
+// the desired objects are compared, as the apiserver defaults the others.
+func DetectDrift(ctx context.Context, client k8sclient.Client, objs []*uns.Unstructured) ([]Drift, error) {
+	drifts := []Drift{}
+	for _, obj := range objs {
+		// create-only objects are not expected to match their rendered state
+		if obj.GetAnnotations()[names.CreateOnlyAnnotation] == "true" {
+			continue
+		}
+		gvk := obj.GroupVersionKind()
+		desc := fmt.Sprintf("%s %s", gvk.Kind, obj.GetName())
+		if obj.GetNamespace() != "" {
+			desc = fmt.Sprintf("%s %s/%s", gvk.Kind, obj.GetNamespace(), obj.GetName())
+		}
+
+		live := &uns.Unstructured{}
+		live.SetGroupVersionKind(gvk)
+		if err := client.Get(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}, live); err != nil {
+			if apierrors.IsNotFound(err) {
+				drifts = append(drifts, Drift{Object: desc})
+				continue
+			}
+			return nil, err
+		}
+		if fields := driftedFields(obj.Object, live.Object); len(fields) > 0 {
+			drifts = append(drifts, Drift{Object: desc, Fields: fields})
+		}
+	}
+	return drifts, nil
+}
+
+// driftedFields returns the paths of the fields of the desired object that differ in the live one.
+// The status, and the metadata other than the labels and annotations, are owned by the apiserver,
+// and the stringData of the secrets is only written.
+func driftedFields(desired, live map[string]interface{}) []string {
+	fields := []string{}
+	for key, value := range desired {
+		switch key {
+		case "status", "apiVersion", "kind", "stringData":
+			continue
+		case "metadata":
+			desiredMeta, _ := value.(map[string]interface{})
+			liveMeta, _ := live[key].(map[string]interface{})
+			for _, metaKey := range []string{"labels", "annotations"} {
+				fields = append(fields, diffValue("metadata."+metaKey, desiredMeta[metaKey], liveMeta[metaKey])...)
+			}
+			continue
+		}
+		fields = append(fields, diffValue(key, value, live[key])...)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// diffValue returns the paths under path where the live value differs from the desired one
+func diffValue(path string, desired, live interface{}) []string {
+	switch d := desired.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			if len(d) == 0 && live == nil {
+				return nil
+			}
+			return []string{path}
+		}
+		fields := []string{}
+		for key, value := range d {
+			fields = append(fields, diffValue(path+"."+key, value, l[key])...)
+		}
+		return fields
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok {
+			if len(d) == 0 && live == nil {
+				return nil
+			}
+			return []string{path}
+		}
+		if len(d) != len(l) {
+			return []string{path}
+		}
+		fields := []string{}
+		for i := range d {
+			fields = append(fields, diffValue(fmt.Sprintf("%s[%d]", path, i), d[i], l[i])...)
+		}
+		return fields
+	default:
+		if scalarEqual(desired, live) {
+			return nil
+		}
+		return []string{path}
+	}
+}
+
+// scalarEqual compares two scalars, ignoring the differences introduced by the serialization
+// of the numbers, the omitted zero values and the canonicalization of the quantities by the apiserver
+func scalarEqual(desired, live interface{}) bool {
+	if reflect.DeepEqual(desired, live) {
+		return true
+	}
+	// the zero values are omitted from the live objects
+	if live == nil && reflect.ValueOf(desired).IsZero() {
+		return true
+	}
+	d, l := fmt.Sprint(desired), fmt.Sprint(live)
+	if d == l {
+		return true
+	}
+	dq, err := resource.ParseQuantity(d)
+	if err != nil {
+		return false
+	}
+	lq, err := resource.ParseQuantity(l)
+	if err != nil {
+		return false
+	}
+	return dq.Cmp(lq) == 0
+}
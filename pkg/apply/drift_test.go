@@ -0,0 +1,116 @@
+package apply
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDetectDrift(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	desired := UnstructuredFromYaml(t, `
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: ds
+  namespace: ns
+  labels:
+    app: ds
+spec:
+  selector:
+    matchLabels:
+      app: ds
+  template:
+    metadata:
+      labels:
+        app: ds
+    spec:
+      hostNetwork: false
+      tolerations: []
+      containers:
+      - name: c
+        image: image:1
+        resources:
+          requests:
+            cpu: 0.01
+            memory: 300Mi
+        ports:
+        - containerPort: 9103`)
+
+	// the apiserver defaults and canonicalizes the live object
+	live := UnstructuredFromYaml(t, `
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: ds
+  namespace: ns
+  uid: 1234
+  labels:
+    app: ds
+  annotations:
+    deprecated.daemonset.template.generation: "1"
+spec:
+  revisionHistoryLimit: 10
+  selector:
+    matchLabels:
+      app: ds
+  template:
+    metadata:
+      labels:
+        app: ds
+    spec:
+      containers:
+      - name: c
+        image: image:1
+        imagePullPolicy: IfNotPresent
+        resources:
+          requests:
+            cpu: 10m
+            memory: 300Mi
+        ports:
+        - containerPort: 9103
+          protocol: TCP
+status:
+  numberReady: 3`)
+
+	missing := UnstructuredFromYaml(t, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+  namespace: ns
+data:
+  a: b`)
+
+	client := fake.NewClientBuilder().WithObjects(live.DeepCopy()).Build()
+	drifts, err := DetectDrift(context.TODO(), client, []*uns.Unstructured{desired, missing})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(drifts).To(Equal([]Drift{{Object: "ConfigMap ns/cm"}}))
+	g.Expect(drifts[0].String()).To(Equal("ConfigMap ns/cm (missing)"))
+
+	// the image and the labels were edited; the environment is not rendered, so it is not compared
+	edited := live.DeepCopy()
+	edited.SetLabels(map[string]string{"app": "other"})
+	containers := edited.Object["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})
+	container := containers[0].(map[string]interface{})
+	container["image"] = "image:2"
+	container["env"] = []interface{}{map[string]interface{}{"name": "DEBUG", "value": "1"}}
+	client = fake.NewClientBuilder().WithObjects(edited).Build()
+	drifts, err = DetectDrift(context.TODO(), client, []*uns.Unstructured{desired})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(drifts).To(Equal([]Drift{{
+		Object: "DaemonSet ns/ds",
+		Fields: []string{"metadata.labels.app", "spec.template.spec.containers[0].image"},
+	}}))
+	g.Expect(drifts[0].String()).To(Equal("DaemonSet ns/ds ([metadata.labels.app spec.template.spec.containers[0].image])"))
+
+	// create-only objects are ignored
+	desired.SetAnnotations(map[string]string{"networkoperator.openshift.io/create-only": "true"})
+	drifts, err = DetectDrift(context.TODO(), client, []*uns.Unstructured{desired})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(drifts).To(BeEmpty())
+}
@@ -0,0 +1,110 @@
+package apply
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/openshift/cluster-network-operator/pkg/names"
+
+	"github.com/pkg/errors"
+
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The tiers of objects, in the order they are applied. The objects of a tier may depend
+// on the objects of the previous tiers, but not on those of their own tier.
+const (
+	// namespaces hold all the other namespaced objects
+	tierNamespaces = iota
+	// CRDs define the kinds of the custom resources
+	tierCRDs
+	// RBAC grants the permissions of the workloads
+	tierRBAC
+	// configuration consumed by the workloads: configmaps, secrets, services, custom resources...
+	tierConfig
+	// workloads run the pods
+	tierWorkloads
+	// admission webhooks call into the workloads, so they may only be registered once these exist
+	tierAdmission
+)
+
+// maxParallelApplies is the number of objects of a tier applied concurrently
+const maxParallelApplies = 10
+
+// applyTier returns the tier of an object, according to its kind
+func applyTier(obj *uns.Unstructured) int {
+	gvk := obj.GroupVersionKind()
+	switch gvk.Kind {
+	case "Namespace":
+		return tierNamespaces
+	case "CustomResourceDefinition":
+		return tierCRDs
+	case "ServiceAccount", "Role", "RoleBinding", "ClusterRole", "ClusterRoleBinding":
+		return tierRBAC
+	case "DaemonSet", "Deployment", "StatefulSet", "Job", "CronJob", "Pod":
+		return tierWorkloads
+	case "ValidatingWebhookConfiguration", "MutatingWebhookConfiguration", "APIService":
+		return tierAdmission
+	}
+	return tierConfig
+}
+
+// OrderObjects groups the objects by tier, in the order they should be applied. Objects keep
+// their relative order within a tier.
+func OrderObjects(objs []*uns.Unstructured) [][]*uns.Unstructured {
+	sorted := make([]*uns.Unstructured, len(objs))
+	copy(sorted, objs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return applyTier(sorted[i]) < applyTier(sorted[j])
+	})
+
+	tiers := [][]*uns.Unstructured{}
+	for i, obj := range sorted {
+		if i == 0 || applyTier(obj) != applyTier(sorted[i-1]) {
+			tiers = append(tiers, nil)
+		}
+		tiers[len(tiers)-1] = append(tiers[len(tiers)-1], obj)
+	}
+	return tiers
+}
+
+// ApplyObjects applies the desired objects against the apiserver, tier by tier, with the
+// objects of a tier applied in parallel. It stops after the first tier where an object
+// failed to apply, unless that object has the ignore-errors annotation, and returns the
+// error of the first such object.
+func ApplyObjects(ctx context.Context, client k8sclient.Client, objs []*uns.Unstructured) error {
+	for _, tier := range OrderObjects(objs) {
+		errs := make([]error, len(tier))
+		sem := make(chan struct{}, maxParallelApplies)
+		wg := sync.WaitGroup{}
+		for i, obj := range tier {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int, obj *uns.Unstructured) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				errs[i] = ApplyObject(ctx, client, obj)
+			}(i, obj)
+		}
+		wg.Wait()
+
+		for i, err := range errs {
+			if err == nil {
+				continue
+			}
+			obj := tier[i]
+			err = errors.Wrapf(err, "could not apply (%s) %s/%s", obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+			log.Error(err, "apply failed")
+
+			// Ignore errors if we've asked to do so.
+			if _, ok := obj.GetAnnotations()[names.IgnoreObjectErrorAnnotation]; ok {
+				log.Info("Object has ignore-errors annotation set, continuing")
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package apply
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift/cluster-network-operator/pkg/names"
+
+	. "github.com/onsi/gomega"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newObj(apiVersion, kind, namespace, name string) *uns.Unstructured {
+	obj := &uns.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+func TestOrderObjects(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	ds := newObj("apps/v1", "DaemonSet", "ns1", "ds")
+	webhook := newObj("admissionregistration.k8s.io/v1", "ValidatingWebhookConfiguration", "", "webhook")
+	cm1 := newObj("v1", "ConfigMap", "ns1", "cm1")
+	role := newObj("rbac.authorization.k8s.io/v1", "Role", "ns1", "role")
+	svc := newObj("v1", "Service", "ns1", "svc")
+	crd := newObj("apiextensions.k8s.io/v1", "CustomResourceDefinition", "", "crd")
+	cm2 := newObj("v1", "ConfigMap", "ns1", "cm2")
+	sa := newObj("v1", "ServiceAccount", "ns1", "sa")
+	ns := newObj("v1", "Namespace", "", "ns1")
+
+	tiers := OrderObjects([]*uns.Unstructured{ds, webhook, cm1, role, svc, crd, cm2, sa, ns})
+	g.Expect(tiers).To(Equal([][]*uns.Unstructured{
+		{ns},
+		{crd},
+		{role, sa},
+		{cm1, svc, cm2},
+		{ds},
+		{webhook},
+	}))
+
+	g.Expect(OrderObjects(nil)).To(BeEmpty())
+}
+
+func TestApplyObjects(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	client := fake.NewClientBuilder().Build()
+	objs := []*uns.Unstructured{
+		newObj("apps/v1", "DaemonSet", "ns1", "ds"),
+		newObj("v1", "Namespace", "", "ns1"),
+	}
+	for i := 0; i < 2*maxParallelApplies; i++ {
+		objs = append(objs, newObj("v1", "ConfigMap", "ns1", "cm"+string(rune('a'+i))))
+	}
+	g.Expect(ApplyObjects(context.TODO(), client, objs)).To(Succeed())
+	for _, obj := range objs {
+		existing := &uns.Unstructured{}
+		existing.SetGroupVersionKind(obj.GroupVersionKind())
+		g.Expect(client.Get(context.TODO(), types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}, existing)).To(Succeed())
+	}
+
+	// an invalid object stops the objects of the next tiers from being applied
+	client = fake.NewClientBuilder().Build()
+	invalid := newObj("v1", "ConfigMap", "ns1", "")
+	ds := newObj("apps/v1", "DaemonSet", "ns1", "ds")
+	err := ApplyObjects(context.TODO(), client, []*uns.Unstructured{ds, invalid})
+	g.Expect(err).To(MatchError(ContainSubstring("has no name")))
+	existing := &uns.Unstructured{}
+	existing.SetGroupVersionKind(ds.GroupVersionKind())
+	g.Expect(client.Get(context.TODO(), types.NamespacedName{Namespace: "ns1", Name: "ds"}, existing)).NotTo(Succeed())
+
+	// unless it has the ignore-errors annotation
+	invalid.SetAnnotations(map[string]string{names.IgnoreObjectErrorAnnotation: ""})
+	g.Expect(ApplyObjects(context.TODO(), client, []*uns.Unstructured{ds, invalid})).To(Succeed())
+	g.Expect(client.Get(context.TODO(), types.NamespacedName{Namespace: "ns1", Name: "ds"}, existing)).To(Succeed())
+}
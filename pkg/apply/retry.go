@@ -0,0 +1,88 @@
+package apply
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/openshift/cluster-network-operator/pkg/names"
+
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// DefaultRetryPolicy is how the transient failures to apply an object are retried: 5 attempts,
+// 200ms apart at first and doubling each time, with up to 50% of jitter so that the objects
+// applied in parallel do not retry in lockstep.
+var DefaultRetryPolicy = wait.Backoff{
+	Steps:    5,
+	Duration: 200 * time.Millisecond,
+	Factor:   2.0,
+	Jitter:   0.5,
+}
+
+// Error is a failure to apply an object, once the transient failures were retried.
+type Error struct {
+	// Transient is whether the last failure was transient, so that a later reconciliation may
+	// succeed without any change.
+	Transient bool
+	// Attempts is the number of times the object was applied
+	Attempts int
+
+	err error
+}
+
+func (e *Error) Error() string {
+	return e.err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// IsTransientError returns whether err is, or wraps, a transient failure to apply an object.
+func IsTransientError(err error) bool {
+	var applyErr *Error
+	return errors.As(err, &applyErr) && applyErr.Transient
+}
+
+// isTransient returns whether an error of the apiserver may go away on its own: conflicts,
+// which are retried against the latest version of the object, throttling, timeouts,
+// unavailable apiservers and kinds whose CRD was just created.
+func isTransient(err error) bool {
+	switch {
+	case apierrors.IsConflict(err),
+		apierrors.IsServerTimeout(err),
+		apierrors.IsTimeout(err),
+		apierrors.IsTooManyRequests(err),
+		apierrors.IsServiceUnavailable(err),
+		apierrors.IsInternalError(err),
+		meta.IsNoMatchError(err),
+		utilnet.IsConnectionReset(err),
+		utilnet.IsConnectionRefused(err),
+		utilnet.IsProbableEOF(err):
+		return true
+	}
+	return false
+}
+
+// retryPolicy returns how the transient failures to apply the object are retried: the default
+// policy, with the number of retries set by the apply-retries annotation.
+func retryPolicy(obj *uns.Unstructured) wait.Backoff {
+	policy := DefaultRetryPolicy
+	anno, ok := obj.GetAnnotations()[names.ApplyRetriesAnnotation]
+	if !ok {
+		return policy
+	}
+	retries, err := strconv.Atoi(anno)
+	if err != nil || retries < 0 {
+		log.Info("ignoring invalid annotation value, must be a non-negative integer", "annotation", names.ApplyRetriesAnnotation, "value", anno)
+		return policy
+	}
+	policy.Steps = retries + 1
+	return policy
+}
@@ -0,0 +1,97 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/openshift/cluster-network-operator/pkg/names"
+
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// failingClient fails the first creations of objects with the given errors
+type failingClient struct {
+	k8sclient.Client
+	errs    []error
+	creates int
+}
+
+func (c *failingClient) Create(ctx context.Context, obj k8sclient.Object, opts ...k8sclient.CreateOption) error {
+	c.creates++
+	if len(c.errs) > 0 {
+		err := c.errs[0]
+		c.errs = c.errs[1:]
+		return err
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func TestApplyObjectRetries(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	defer func(policy wait.Backoff) { DefaultRetryPolicy = policy }(DefaultRetryPolicy)
+	DefaultRetryPolicy.Duration = time.Millisecond
+
+	gr := schema.GroupResource{Resource: "configmaps"}
+	timeout := apierrors.NewServerTimeout(gr, "create", 1)
+	conflict := apierrors.NewConflict(gr, "cm", fmt.Errorf("changed"))
+	invalid := apierrors.NewBadRequest("invalid")
+
+	// transient failures are retried
+	client := &failingClient{Client: fake.NewClientBuilder().Build(), errs: []error{timeout, conflict}}
+	g.Expect(ApplyObject(context.TODO(), client, newObj("v1", "ConfigMap", "ns1", "cm"))).To(Succeed())
+	g.Expect(client.creates).To(Equal(3))
+
+	// permanent failures are not
+	client = &failingClient{Client: fake.NewClientBuilder().Build(), errs: []error{invalid}}
+	err := ApplyObject(context.TODO(), client, newObj("v1", "ConfigMap", "ns1", "cm"))
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(IsTransientError(err)).To(BeFalse())
+	g.Expect(err).To(MatchError(ContainSubstring("invalid")))
+	g.Expect(client.creates).To(Equal(1))
+
+	// transient failures that persist are reported as such
+	client = &failingClient{Client: fake.NewClientBuilder().Build(), errs: []error{timeout, timeout, timeout, timeout, timeout, timeout}}
+	err = ApplyObject(context.TODO(), client, newObj("v1", "ConfigMap", "ns1", "cm"))
+	g.Expect(IsTransientError(err)).To(BeTrue())
+	g.Expect(err.(*Error).Attempts).To(Equal(5))
+	g.Expect(client.creates).To(Equal(5))
+
+	// including once wrapped by ApplyObjects
+	client = &failingClient{Client: fake.NewClientBuilder().Build(), errs: []error{timeout, timeout, timeout, timeout, timeout}}
+	err = ApplyObjects(context.TODO(), client, []*uns.Unstructured{newObj("v1", "ConfigMap", "ns1", "cm")})
+	g.Expect(IsTransientError(err)).To(BeTrue())
+
+	// the annotation sets the number of retries
+	obj := newObj("v1", "ConfigMap", "ns1", "cm")
+	obj.SetAnnotations(map[string]string{names.ApplyRetriesAnnotation: "0"})
+	client = &failingClient{Client: fake.NewClientBuilder().Build(), errs: []error{conflict}}
+	err = ApplyObject(context.TODO(), client, obj)
+	g.Expect(IsTransientError(err)).To(BeTrue())
+	g.Expect(client.creates).To(Equal(1))
+}
+
+func TestRetryPolicy(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	obj := newObj("v1", "ConfigMap", "ns1", "cm")
+	g.Expect(retryPolicy(obj)).To(Equal(DefaultRetryPolicy))
+
+	obj.SetAnnotations(map[string]string{names.ApplyRetriesAnnotation: "9"})
+	g.Expect(retryPolicy(obj).Steps).To(Equal(10))
+	g.Expect(retryPolicy(obj).Jitter).To(Equal(DefaultRetryPolicy.Jitter))
+
+	// invalid values are ignored
+	for _, value := range []string{"-1", "many", ""} {
+		obj.SetAnnotations(map[string]string{names.ApplyRetriesAnnotation: value})
+		g.Expect(retryPolicy(obj)).To(Equal(DefaultRetryPolicy), value)
+	}
+}
@@ -1,9 +1,16 @@
 package bootstrap
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/gophercloud/utils/openstack/clientconfig"
 	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-network-operator/pkg/featuregates"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 type KuryrBootstrapResult struct {
@@ -29,9 +36,238 @@ type KuryrBootstrapResult struct {
 	NoProxy                  string
 }
 
+// PolicyAuditForwarding is the forwarding of the ACL audit logs of the nodes
+type PolicyAuditForwarding struct {
+	// Type is either "kafka", with the Brokers and Topic to send the logs to, or "http",
+	// with the HTTPS URL to post them to
+	Type    string
+	Brokers string
+	Topic   string
+	URL     string
+	// Image is the vector image forwarding the logs
+	Image string
+	// CABundle and ClientCert are set when the TLS Secret holds a CA bundle to verify the
+	// destination with, and a client certificate to authenticate with
+	CABundle   bool
+	ClientCert bool
+}
+
+// GatewayNextHops are the next hops of the external gateway of a group of nodes
+type GatewayNextHops struct {
+	// Name is the name of the node group
+	Name string
+	// NodeSelector is the label selector of the nodes of the group
+	NodeSelector string
+	// NextHops are the comma-separated next hops, one per IP family
+	NextHops string
+}
+
+// EgressSNATPool is a pool of addresses the egress traffic of the pods of a group of nodes is SNATed to
+type EgressSNATPool struct {
+	// Name is the name of the pool
+	Name string
+	// NodeSelector is the label selector of the nodes of the pool, empty for all the nodes
+	NodeSelector string
+	// Addresses maps the CIDRs of the cluster networks the pool applies to, to the comma-separated
+	// CIDRs of the pool of their IP family
+	Addresses map[string]string
+}
+
+// IPsecExternalGateway is a peer outside of the cluster that the nodes encrypt their traffic to
+type IPsecExternalGateway struct {
+	// Name is the name of the gateway
+	Name string
+	// Address is the IP address of the peer
+	Address string
+	// Subnets are the space-separated CIDRs behind the peer, empty to encrypt the traffic to the address only
+	Subnets string
+	// PSKSecretName is the Secret of the pre-shared key of the peer
+	PSKSecretName string
+}
+
+// OVNProbeProfile is the intervals of the probes of ovn-controller on a group of nodes
+type OVNProbeProfile struct {
+	// Name is the name of the profile
+	Name string
+	// NodeSelector is the label selector of the nodes of the profile, empty for all the nodes
+	NodeSelector string
+	// RemoteProbeInterval is the interval of the southbound database probe in milliseconds, empty when unset
+	RemoteProbeInterval string
+	// OpenFlowProbeInterval is the interval of the OpenFlow probe in seconds, empty when unset
+	OpenFlowProbeInterval string
+}
+
+// OVNRouteAdvertisement is the BGP sessions of a group of nodes and the routes they advertise
+type OVNRouteAdvertisement struct {
+	// Name is the name of the advertisement
+	Name string
+	// NodeSelector is the label selector of the nodes of the advertisement, empty for all the nodes
+	NodeSelector string
+	// ASN is the autonomous system number of the nodes
+	ASN int64
+	// Peers are the "<address>,<asn>" of the routers, space-separated
+	Peers string
+	// PodNetwork and EgressIP are whether the subnets of the pods, and the egress IPs, of the nodes
+	// are advertised
+	PodNetwork bool
+	EgressIP   bool
+}
+
+// IsolatedNamespace is a namespace isolated like in the Multitenant mode of openshift-sdn
+type IsolatedNamespace struct {
+	// Name is the name of the namespace
+	Name string
+	// Peers are the namespaces that reach it: those that shared its NetID, and the global ones
+	Peers []string
+}
+
+// OVNNodePool is a pool of nodes running ovn-kubernetes in the dpu-host or the dpu node mode
+type OVNNodePool struct {
+	// Name is the name of the OVNNodePool, empty for the pool converted from the deprecated
+	// dpu-mode-config ConfigMap, which selects the nodes by their labels
+	Name string
+	// Mode is the node mode of the pool, "dpu-host" or "dpu"
+	Mode string
+	// NodeSelector are the requirements the nodes of the pool meet, from the nodeSelector of
+	// its MachineConfigPool
+	NodeSelector []corev1.NodeSelectorRequirement
+	// Image and ExtraArgs are the ovn-kubernetes image and the additional ovnkube flags of the
+	// dpu-host pools with DPUs of a specific vendor, the image of the release and none by default
+	Image     string
+	ExtraArgs []string
+}
+
 type OVNConfigBoostrapResult struct {
 	GatewayMode string
-	NodeMode    string
+	// NodePools are the pools of nodes not running in the full node mode, sorted by name. The
+	// other nodes run in the full node mode.
+	NodePools     []OVNNodePool
+	PrePullerMode string
+	// PolicyAuditMaxLogFiles and PolicyAuditMaxLogAge (in days) are the retention
+	// policy of the rotated ACL audit log files on the nodes.
+	PolicyAuditMaxLogFiles int
+	PolicyAuditMaxLogAge   int
+	// PolicyAuditForwarding is where the ACL audit logs are forwarded to, if anywhere
+	PolicyAuditForwarding *PolicyAuditForwarding
+	// LBAffinityTimeout and LBIdleTimeout are the session affinity and idle timeouts, in seconds,
+	// of the OVN load balancers. Zero keeps the ovn-kubernetes defaults.
+	LBAffinityTimeout int
+	LBIdleTimeout     int
+	// DisableEmptyLBEvents stops the load balancers without backends from raising the events
+	// the idled services are woken up on
+	DisableEmptyLBEvents bool
+	// DBClientReconnectBackoff is the maximum backoff, in milliseconds, between the reconnections of
+	// ovnkube-master to the OVN databases, and DBClientMaxInflightTxns the maximum number of transactions
+	// it has in flight on each of them. Zero keeps the ovn-kubernetes defaults.
+	DBClientReconnectBackoff int
+	DBClientMaxInflightTxns  int
+	// EgressFirewallDNSRefreshInterval is the interval, in seconds, at which the DNS names of the
+	// EgressFirewall rules are resolved again, and EgressFirewallDNSResolver the host:port of the upstream
+	// resolver they are resolved with. Zero values keep the ovn-kubernetes defaults.
+	EgressFirewallDNSRefreshInterval int
+	EgressFirewallDNSResolver        string
+	// OVSDBMode is how ovnkube-master reaches the OVN NB database, "legacy" or "libovsdb". TxnBatchSize is
+	// the maximum number of operations it batches in a transaction, and LflowCacheLimit the maximum number
+	// of entries of the logical flow cache of ovn-controller. Zero keeps the defaults.
+	OVSDBMode       string
+	TxnBatchSize    int
+	LflowCacheLimit int
+	// DBMaintenanceSchedule is the cron schedule of the compaction of the OVN databases, if any,
+	// with a snapshot of the compacted databases when DBMaintenanceSnapshot is set.
+	DBMaintenanceSchedule string
+	DBMaintenanceSnapshot bool
+	// NamespaceHardening restricts the ingress traffic to the pods of the namespace with NetworkPolicies
+	NamespaceHardening bool
+	// MinimalRBAC runs each workload with a ServiceAccount of its own, and narrows down the
+	// ClusterRoles of ovnkube-master and ovnkube-node
+	MinimalRBAC bool
+	// DBEndpointName is the DNS name the nodes reach the OVN databases on, if any, rather than the master IPs
+	DBEndpointName string
+	// CrashForensicsRetention is the number of crash reports kept on each node, zero
+	// disables their collection.
+	CrashForensicsRetention int
+	// DatapathFlowsCeiling is the number of datapath flows of a node the alerts fire above, zero
+	// disables the collection of the datapath metrics of OVS.
+	DatapathFlowsCeiling int
+	// DisabledComponents are the ovn-kubernetes components that were force-disabled
+	DisabledComponents []string
+	// Debug deploys the ovnkube-debug pod. DebugDumpRequest, when set, identifies
+	// the requested dump of the databases.
+	Debug            bool
+	DebugDumpRequest string
+	// Tools deploys the ovn-tools DaemonSet
+	Tools bool
+	// ForceUnsafeChangeRequest identifies the last request that forced an unsafe change, if any. It is
+	// set by the operator configuration controller, and the ovnkube-master and ovnkube-node pods are
+	// restarted whenever it changes.
+	ForceUnsafeChangeRequest string
+	// MultiExternalGateway enables the AdminPolicyBasedExternalRoute resources, with BFD
+	// enabled by default on their next hops when ExternalGatewayBFD is set.
+	MultiExternalGateway bool
+	ExternalGatewayBFD   bool
+	// ResourceProfile is the requested resource profile of ovn-kubernetes
+	ResourceProfile string
+	// NodeUpgradeMode is the requested rollout mode of ovnkube-node
+	NodeUpgradeMode string
+	// UpgradeHold keeps ovnkube-master and ovnkube-node at their current release, when set
+	UpgradeHold bool
+	// ScaleUpNodes is the number of nodes joining the cluster, when the adaptive tuning relaxes the settings
+	// of the OVN control plane during a scale-up, zero otherwise
+	ScaleUpNodes int
+	// ControlPlaneOnly defers the ovnkube-node rollouts, set during the control-plane-only windows
+	ControlPlaneOnly bool
+	// NodePortRange is the "<first>-<last>" range of the node ports of the services, which the
+	// health check node ports are allocated from
+	NodePortRange string
+	// V4JoinSubnet and V6JoinSubnet override the join subnets of ovn-kubernetes, when set
+	V4JoinSubnet string
+	V6JoinSubnet string
+	// V4MasqueradeSubnet and V6MasqueradeSubnet override the internal masquerade subnets of
+	// ovn-kubernetes, when set
+	V4MasqueradeSubnet string
+	V6MasqueradeSubnet string
+	// NodeLocalDNSIP is the link-local address of the node-local DNS cache of the nodes, if any
+	NodeLocalDNSIP string
+	// ReservedNodeSubnets are the ranges of the cluster networks no node subnet is allocated from
+	ReservedNodeSubnets []string
+	// GatewayNextHops are the external gateway next hops of the node groups, by name
+	GatewayNextHops []GatewayNextHops
+	// EgressSNATPools are the egress SNAT address pools, by name
+	EgressSNATPools []EgressSNATPool
+	// IPsecMode is the requested IPsec mode, Internal or Full
+	IPsecMode string
+	// IPsecExternalGateways are the peers outside of the cluster of the Full IPsec mode, by name
+	IPsecExternalGateways []IPsecExternalGateway
+	// IPsecIKE and IPsecESP are the comma-separated proposals of the connections to the IPsecExternalGateways,
+	// and IPsecIKELifetime and IPsecSALifetime their rekey intervals in seconds, empty or 0 for the defaults
+	IPsecIKE         string
+	IPsecESP         string
+	IPsecIKELifetime int
+	IPsecSALifetime  int
+	// IPsecForceEncapsulation forces the UDP encapsulation of the ESP packets
+	IPsecForceEncapsulation bool
+	// ProbeProfiles are the probe intervals of ovn-controller of the node groups, by name
+	ProbeProfiles []OVNProbeProfile
+	// RouteAdvertisements are the BGP advertisements of the node groups, by name
+	RouteAdvertisements []OVNRouteAdvertisement
+	// MultitenantNetIDs are the NetIDs of the namespaces of a cluster migrated from the Multitenant
+	// mode of openshift-sdn, by namespace, when their isolation is preserved. IsolatedNamespaces
+	// are the namespaces that are isolated.
+	MultitenantNetIDs  map[string]uint32
+	IsolatedNamespaces []IsolatedNamespace
+}
+
+// ExternalOVN is an OVN deployment, managed outside of the cluster, that the nodes are attached to
+// instead of the OVN databases of ovnkube-master
+type ExternalOVN struct {
+	// NBDBList and SBDBList are the comma-separated "ssl:<host>:<port>" endpoints of the NB and SB databases
+	NBDBList string
+	SBDBList string
+	// CertHash is the hash of the client certificate of the nodes, which ovnkube-node mounts from
+	// the Secret provided along with the deployment, and CABundle the PEM bundle the databases are
+	// verified with
+	CertHash string
+	CABundle string
 }
 
 type OVNBootstrapResult struct {
@@ -41,13 +277,182 @@ type OVNBootstrapResult struct {
 	ExistingNodeDaemonset   *appsv1.DaemonSet
 	OVNKubernetesConfig     *OVNConfigBoostrapResult
 	PrePullerDaemonset      *appsv1.DaemonSet
+	PrePullerJob            *batchv1.Job
 	FlowsConfig             *FlowsConfig
+	// RemovedMasterIPs are the masters that left the cluster but are still
+	// members of the OVN databases raft clusters.
+	RemovedMasterIPs []string
+	DBScaleDownJob   *batchv1.Job
+	// DBSchemaCheckJob is the existing job checking the schemas of the OVN databases before
+	// ovnkube-master is upgraded, and DBSchemaCheckFailure the reason it failed, if it did.
+	DBSchemaCheckJob     *batchv1.Job
+	DBSchemaCheckFailure string
+	// PreviousMasterIPs are the masters the OVN databases were last bootstrapped with,
+	// the nodes are pointed at the new endpoints in place when they differ from MasterIPs.
+	PreviousMasterIPs []string
+	// PodNetworkChecks maps the nodes that ran the check of the pod network to its result,
+	// as annotated by their ovnkube-node pods.
+	PodNetworkChecks map[string]string
+	// PreNodeRolloutHook and PostNodeRolloutHook are the specs of the Jobs run before and after
+	// ovnkube-node is rolled out to a new release, PreNodeRolloutJob and PostNodeRolloutJob the
+	// existing Jobs.
+	PreNodeRolloutHook  *batchv1.JobSpec
+	PostNodeRolloutHook *batchv1.JobSpec
+	PreNodeRolloutJob   *batchv1.Job
+	PostNodeRolloutJob  *batchv1.Job
+	// MasterNodeSelector selects the nodes hosting the OVN masters
+	MasterNodeSelector map[string]string
+	// MasterTolerations are the keys of the taints tolerated by the OVN masters
+	MasterTolerations []string
+	// MasterTopologyKey is the node label key of the topology domains the OVN masters are
+	// spread across, one per domain
+	MasterTopologyKey string
+	// ManagementIPs maps master node names to their management IP. It is only set
+	// when the OVN databases are placed on the management network.
+	ManagementIPs map[string]string
+	// MissingObjects are the critical objects of a deployed ovn-kubernetes that were
+	// removed from the cluster, as "Kind namespace/name".
+	MissingObjects []string
+	// External is the OVN deployment the nodes are attached to, if any. ovnkube-master is
+	// then not deployed, and MasterIPs is empty.
+	External *ExternalOVN
+}
+
+// ThirdPartyCNIBootstrapResult is the configuration of a default network that is not deployed by the operator
+type ThirdPartyCNIBootstrapResult struct {
+	// DaemonSet is the DaemonSet of the third-party network, if any, whose readiness is reported
+	DaemonSet *types.NamespacedName
+	// ConfigFile is the name of the CNI configuration file Multus waits for, if any
+	ConfigFile string
+}
+
+type KubeProxyBootstrapResult struct {
+	// Config is the KubeProxyConfiguration, in YAML, of the standalone kube-proxy
+	Config string
+}
+
+// NodeRollout is the rolling update of the ovnkube-node and multus DaemonSets
+type NodeRollout struct {
+	// MaxUnavailable and MaxSurge are the maximum number, or percentage, of nodes whose pod is
+	// unavailable, and surged, during a rollout. Empty keeps the defaults.
+	MaxUnavailable string
+	MaxSurge       string
+}
+
+// OperatorTuning are the behavior knobs of the operator, read from the network-operator-config
+// ConfigMap, or else from the environment of the operator. Empty values are defaulted where they are used.
+type OperatorTuning struct {
+	// MasterDiscoveryTimeout, MasterDiscoveryPoll and MasterDiscoveryBackoff, in seconds, tune the
+	// wait for the master nodes while bootstrapping OVNKubernetes
+	MasterDiscoveryTimeout int
+	MasterDiscoveryPoll    int
+	MasterDiscoveryBackoff int
+	// the raft election timers of the OVN databases, in seconds, and the probe intervals of the
+	// OVN daemons, in milliseconds
+	NBRaftElectionTimer       string
+	SBRaftElectionTimer       string
+	ControllerInactivityProbe string
+	NBInactivityProbe         string
+	NorthdProbeInterval       string
+	// ResyncPeriod is the interval of the periodic reconciliation of the operator configuration
+	ResyncPeriod time.Duration
+	// LogFormat is the format of the logs of the operator, "text" or "json", and LogLevels the
+	// verbosity of its modules, by name
+	LogFormat string
+	LogLevels map[string]int
 }
 
 type BootstrapResult struct {
-	Kuryr KuryrBootstrapResult
-	OVN   OVNBootstrapResult
-	Infra InfraBootstrapResult
+	Kuryr         KuryrBootstrapResult
+	OVN           OVNBootstrapResult
+	Infra         InfraBootstrapResult
+	KubeProxy     KubeProxyBootstrapResult
+	ThirdPartyCNI ThirdPartyCNIBootstrapResult
+	Tuning        OperatorTuning
+
+	// FeatureGates is the state of the feature gates of the cluster, injected into the render
+	// data of every component
+	FeatureGates featuregates.FeatureGates
+
+	// CNILatencyProbe deploys the probe measuring the latency of the CNI commands on each node
+	CNILatencyProbe bool
+
+	// MTUProbe deploys the probe of the path MTU between the nodes with the network diagnostics
+	MTUProbe bool
+
+	// NodeRollout tunes the rollouts of the DaemonSets running on every node
+	NodeRollout NodeRollout
+
+	// ConntrackTuning, when set, deploys the daemonset sizing the conntrack table of each node
+	ConntrackTuning *ConntrackTuning
+
+	// MachineConfigs generates the MachineConfigs holding the prerequisites of the network on the nodes
+	MachineConfigs bool
+
+	// BrExInterface is the uplink interface of the nodes, which the MachineConfigs attach to the br-ex
+	// bridge of ovn-kubernetes, if any
+	BrExInterface string
+
+	// NetworkingConsolePlugin deploys the networking plugin of the console
+	NetworkingConsolePlugin bool
+
+	// NetworkCleanup is the former default network type whose state is left to clean up on the
+	// nodes, if any
+	NetworkCleanup string
+
+	// ExcludedObjects are the rendered objects left out of the manifests applied
+	ExcludedObjects []ExcludedObject
+
+	// UplinkMTU is the lowest uplink MTU reported by the nodes, or zero if none reported it,
+	// and UplinkMTUNode the node reporting it.
+	UplinkMTU     int
+	UplinkMTUNode string
+
+	// Events are the decisions taken while bootstrapping and rendering the
+	// network, to be reported as Events on the operator configuration.
+	Events []Event
+}
+
+// ExcludedObject identifies a rendered object by its group, kind, namespace and name. The
+// namespace is empty for cluster-scoped objects.
+type ExcludedObject struct {
+	Group     string
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// ConntrackTuning is the sizing of the conntrack table and the conntrack timeouts applied on
+// every node. Zero values leave the kernel settings as they are.
+type ConntrackTuning struct {
+	// MaxPerGiB is the number of conntrack entries per GiB of memory of the node, not less
+	// than Min. Max, when set, is the number of entries regardless of the memory.
+	MaxPerGiB int
+	Min       int
+	Max       int
+	// the timeouts, in seconds
+	TCPTimeoutEstablished int
+	TCPTimeoutCloseWait   int
+	TCPTimeoutTimeWait    int
+	UDPTimeout            int
+	UDPTimeoutStream      int
+}
+
+// Event is a decision of the operator worth an entry in the audit trail of the cluster.
+type Event struct {
+	// Type is either corev1.EventTypeNormal or corev1.EventTypeWarning
+	Type    string
+	Reason  string
+	Message string
+}
+
+// RecordEvent adds an Event to the bootstrap result
+func (r *BootstrapResult) RecordEvent(eventType, reason, messageFmt string, args ...interface{}) {
+	r.Events = append(r.Events, Event{
+		Type:    eventType,
+		Reason:  reason,
+		Message: fmt.Sprintf(messageFmt, args...),
+	})
 }
 
 type InfraBootstrapResult struct {
@@ -55,9 +460,36 @@ type InfraBootstrapResult struct {
 	PlatformRegion       string
 	PlatformStatus       *configv1.PlatformStatus
 	ExternalControlPlane bool
+	// ControlPlaneTopology and InfrastructureTopology are the topologies of the control plane and of the
+	// infrastructure nodes, which may change at runtime, e.g. when a single-node cluster is expanded
+	ControlPlaneTopology   configv1.TopologyMode
+	InfrastructureTopology configv1.TopologyMode
 
 	// KubeCloudConfig is the contents of the openshift-config-managed/kube-cloud-config ConfigMap
 	KubeCloudConfig map[string]string
+
+	// APIServerInternalIP and IngressIP are the VIPs of the on-premise platforms, served by
+	// load balancers on the nodes when SelfHostedLoadBalancer is set.
+	APIServerInternalIP    string
+	IngressIP              string
+	SelfHostedLoadBalancer bool
+
+	// LoadBalancerHealthCheckSources are the CIDRs the health checks of the cloud load balancers
+	// come from, which the nodes must accept on the health check node ports of the services of type
+	// LoadBalancer with externalTrafficPolicy Local
+	LoadBalancerHealthCheckSources []string
+
+	// EgressIPCapacityLimited is set on the cloud platforms, where each node can only host the
+	// number of egress IPs published in its cloud.network.openshift.io/egress-ipconfig annotation
+	EgressIPCapacityLimited bool
+
+	// FIPS is set when the cluster was installed in FIPS mode, where the components must only use
+	// FIPS-approved cryptography
+	FIPS bool
+
+	// TLSProfile is the TLS security profile of the API server, which the TLS endpoints of the
+	// components follow. It is nil for the default Intermediate profile.
+	TLSProfile *configv1.TLSProfileSpec
 }
 
 type FlowsConfig struct {
@@ -72,4 +504,9 @@ type FlowsConfig struct {
 
 	// Sampling is the sampling rate on the reporter. 100 means one flow on 100 is sent. 0 means disabled.
 	Sampling *uint
+
+	// TLS sends the flows to the shared target over TLS, through a relay on each node, and CABundle is
+	// the PEM bundle the target is verified with. The cluster-wide trusted CA bundle is used when empty.
+	TLS      bool
+	CABundle string
 }
@@ -0,0 +1,308 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operv1 "github.com/openshift/api/operator/v1"
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
+	"github.com/openshift/cluster-network-operator/pkg/controller/statusmanager"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/openshift/cluster-network-operator/pkg/network"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	certutil "k8s.io/client-go/util/cert"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Level is the outcome of a check
+type Level string
+
+const (
+	LevelOK   Level = "OK"
+	LevelWarn Level = "WARN"
+	LevelFail Level = "FAIL"
+)
+
+// Result is the outcome of a check, along with what it found
+type Result struct {
+	Level   Level
+	Message string
+}
+
+// Section is a group of related checks
+type Section struct {
+	Name    string
+	Results []Result
+}
+
+func (s *Section) add(level Level, format string, args ...interface{}) {
+	s.Results = append(s.Results, Result{Level: level, Message: fmt.Sprintf(format, args...)})
+}
+
+// Report is the outcome of all the checks
+type Report struct {
+	Sections []*Section
+}
+
+// Failed returns the number of failed checks
+func (r *Report) Failed() int {
+	failed := 0
+	for _, s := range r.Sections {
+		for _, res := range s.Results {
+			if res.Level == LevelFail {
+				failed++
+			}
+		}
+	}
+	return failed
+}
+
+// WriteText writes the report, one section after the other
+func (r *Report) WriteText(w io.Writer) error {
+	for i, s := range r.Sections {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s\n", s.Name); err != nil {
+			return err
+		}
+		for _, res := range s.Results {
+			// multi-line messages are indented under their first line
+			msg := strings.ReplaceAll(res.Message, "\n", "\n         ")
+			if _, err := fmt.Fprintf(w, "  %-6s %s\n", "["+string(res.Level)+"]", msg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Run runs all the checks against the cluster. A check that cannot read what it needs fails,
+// the others still run.
+func Run(ctx context.Context, cl client.Reader, now time.Time, certThreshold time.Duration) *Report {
+	co := &configv1.ClusterOperator{}
+	coErr := cl.Get(ctx, types.NamespacedName{Name: "network"}, co)
+	conf := &operv1.Network{}
+	confErr := cl.Get(ctx, types.NamespacedName{Name: names.OPERATOR_CONFIG}, conf)
+
+	return &Report{
+		Sections: []*Section{
+			checkClusterOperator(co, coErr),
+			checkRollouts(ctx, cl, co, coErr),
+			checkOVNDatabases(ctx, cl, conf, confErr),
+			checkCertificates(ctx, cl, now, certThreshold),
+		},
+	}
+}
+
+// checkClusterOperator reports the conditions of the network ClusterOperator
+func checkClusterOperator(co *configv1.ClusterOperator, err error) *Section {
+	s := &Section{Name: "Cluster operator"}
+	if err != nil {
+		s.add(LevelFail, "Failed to get the network ClusterOperator: %v", err)
+		return s
+	}
+	for _, condType := range []configv1.ClusterStatusConditionType{
+		configv1.OperatorAvailable, configv1.OperatorDegraded, configv1.OperatorProgressing,
+	} {
+		var cond *configv1.ClusterOperatorStatusCondition
+		for i := range co.Status.Conditions {
+			if co.Status.Conditions[i].Type == condType {
+				cond = &co.Status.Conditions[i]
+			}
+		}
+		if cond == nil {
+			s.add(LevelWarn, "%s is not set", condType)
+			continue
+		}
+
+		healthy := configv1.ConditionFalse
+		if condType == configv1.OperatorAvailable {
+			healthy = configv1.ConditionTrue
+		}
+		level := LevelOK
+		if cond.Status != healthy {
+			level = LevelFail
+			if condType == configv1.OperatorProgressing {
+				level = LevelWarn
+			}
+		}
+		msg := fmt.Sprintf("%s=%s", condType, cond.Status)
+		if cond.Reason != "" {
+			msg += fmt.Sprintf(" (%s)", cond.Reason)
+		}
+		if cond.Message != "" {
+			msg += ": " + cond.Message
+		}
+		s.add(level, "%s", msg)
+	}
+	return s
+}
+
+// checkRollouts reports the progress of the DaemonSets and Deployments the ClusterOperator relates
+// to, as the status manager does
+func checkRollouts(ctx context.Context, cl client.Reader, co *configv1.ClusterOperator, err error) *Section {
+	s := &Section{Name: "Rollouts"}
+	if err != nil {
+		s.add(LevelFail, "Failed to get the related objects of the network ClusterOperator: %v", err)
+		return s
+	}
+	installComplete := false
+	for _, cond := range co.Status.Conditions {
+		if cond.Type == configv1.OperatorAvailable && cond.Status == configv1.ConditionTrue {
+			installComplete = true
+		}
+	}
+
+	for _, ref := range co.Status.RelatedObjects {
+		if ref.Group != appsv1.GroupName {
+			continue
+		}
+		nsn := types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}
+		switch strings.ToLower(ref.Resource) {
+		case "daemonsets":
+			ds := &appsv1.DaemonSet{}
+			if err := cl.Get(ctx, nsn, ds); err != nil {
+				s.add(LevelFail, "Failed to get DaemonSet %q: %v", nsn.String(), err)
+				continue
+			}
+			msg, unavailable := statusmanager.DaemonSetProgress(ds, installComplete)
+			checkRollout(ctx, cl, s, "DaemonSet", nsn, ds, ds.Spec.Selector, msg, unavailable)
+		case "deployments":
+			dep := &appsv1.Deployment{}
+			if err := cl.Get(ctx, nsn, dep); err != nil {
+				s.add(LevelFail, "Failed to get Deployment %q: %v", nsn.String(), err)
+				continue
+			}
+			msg, unavailable := statusmanager.DeploymentProgress(dep, installComplete)
+			checkRollout(ctx, cl, s, "Deployment", nsn, dep, dep.Spec.Selector, msg, unavailable)
+		}
+	}
+	if len(s.Results) == 0 {
+		s.add(LevelWarn, "No DaemonSets or Deployments are related to the network ClusterOperator")
+	}
+	return s
+}
+
+// checkRollout reports the progress of a DaemonSet or Deployment, and its pods in CrashLoopBackOff
+func checkRollout(ctx context.Context, cl client.Reader, s *Section, kind string, nsn types.NamespacedName, obj client.Object, selector *metav1.LabelSelector, msg string, unavailable bool) {
+	_, nonCritical := obj.GetAnnotations()[names.NonCriticalAnnotation]
+	_, hung := obj.GetAnnotations()[names.RolloutHungAnnotation]
+	if msg == "" {
+		s.add(LevelOK, "%s %q is rolled out", kind, nsn.String())
+		return
+	}
+
+	level := LevelWarn
+	if hung {
+		level = LevelFail
+		msg += ", and is not making progress"
+	}
+	if unavailable && !nonCritical && selector != nil {
+		crashing, err := statusmanager.CrashLoopBackOffPods(ctx, cl, nsn, selector.MatchLabels, kind)
+		if err != nil {
+			msg += fmt.Sprintf("\nfailed to list its pods: %v", err)
+		}
+		if len(crashing) > 0 {
+			level = LevelFail
+			msg += "\n" + strings.Join(crashing, "\n")
+		}
+	}
+	s.add(level, "%s", msg)
+}
+
+// checkOVNDatabases reports the masters that are members of the OVN databases raft clusters, as
+// recorded by the operator, against the nodes hosting the masters
+func checkOVNDatabases(ctx context.Context, cl client.Reader, conf *operv1.Network, err error) *Section {
+	s := &Section{Name: "OVN databases"}
+	if err != nil {
+		s.add(LevelFail, "Failed to get the operator configuration: %v", err)
+		return s
+	}
+	if conf.Spec.DefaultNetwork.Type != operv1.NetworkTypeOVNKubernetes {
+		s.add(LevelOK, "The default network is %s, there are no OVN databases", conf.Spec.DefaultNetwork.Type)
+		return s
+	}
+	if _, ok := conf.GetAnnotations()[names.OVNExternalAnnotation]; ok {
+		s.add(LevelOK, "The nodes are attached to an external OVN deployment")
+		return s
+	}
+
+	annotations := conf.GetAnnotations()
+	members := sets.NewString()
+	for _, member := range strings.Split(annotations[names.OVNDBMembersAnnotation], ",") {
+		if member = strings.TrimSpace(member); member != "" {
+			members.Insert(member)
+		}
+	}
+	if members.Len() == 0 {
+		s.add(LevelFail, "No members of the OVN databases are recorded in %s", names.OVNDBMembersAnnotation)
+	} else {
+		s.add(LevelOK, "Members of the OVN databases: %s", strings.Join(members.List(), ", "))
+	}
+
+	if removed := annotations[names.OVNDBRemovedMembersAnnotation]; removed != "" {
+		s.add(LevelWarn, "Masters that left the cluster are still to be removed from the OVN databases: %s", removed)
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := cl.List(ctx, nodes, client.MatchingLabels(network.OVNMasterNodeSelector(conf))); err != nil {
+		s.add(LevelFail, "Failed to list the master nodes: %v", err)
+		return s
+	}
+	if members.Len() > 0 && len(nodes.Items) != members.Len() {
+		s.add(LevelWarn, "%d master nodes are found, and %d members of the OVN databases", len(nodes.Items), members.Len())
+	}
+	return s
+}
+
+// checkCertificates reports the expiry of the CAs and certificates of every OperatorPKI
+func checkCertificates(ctx context.Context, cl client.Reader, now time.Time, threshold time.Duration) *Section {
+	s := &Section{Name: "Certificates"}
+	pkis := &netopv1.OperatorPKIList{}
+	if err := cl.List(ctx, pkis); err != nil {
+		s.add(LevelFail, "Failed to list the OperatorPKIs: %v", err)
+		return s
+	}
+	if len(pkis.Items) == 0 {
+		s.add(LevelOK, "No OperatorPKIs are found")
+		return s
+	}
+
+	for _, pki := range pkis.Items {
+		for _, name := range []string{pki.Name + "-ca", pki.Name + "-cert"} {
+			nsn := types.NamespacedName{Namespace: pki.Namespace, Name: name}
+			secret := &corev1.Secret{}
+			if err := cl.Get(ctx, nsn, secret); err != nil {
+				s.add(LevelFail, "Failed to get Secret %q: %v", nsn.String(), err)
+				continue
+			}
+			certs, err := certutil.ParseCertsPEM(secret.Data[corev1.TLSCertKey])
+			if err != nil {
+				s.add(LevelFail, "Failed to parse the certificate of Secret %q: %v", nsn.String(), err)
+				continue
+			}
+			notAfter := certs[0].NotAfter
+			switch {
+			case !now.Before(notAfter):
+				s.add(LevelFail, "The certificate of Secret %q expired at %s", nsn.String(), notAfter.UTC().Format(time.RFC3339))
+			case notAfter.Sub(now) < threshold:
+				s.add(LevelWarn, "The certificate of Secret %q expires at %s", nsn.String(), notAfter.UTC().Format(time.RFC3339))
+			default:
+				s.add(LevelOK, "The certificate of Secret %q expires at %s", nsn.String(), notAfter.UTC().Format(time.RFC3339))
+			}
+		}
+	}
+	return s
+}
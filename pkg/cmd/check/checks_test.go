@@ -0,0 +1,170 @@
+package check
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	operv1 "github.com/openshift/api/operator/v1"
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/openshift/library-go/pkg/crypto"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func certSecret(g *WithT, name string, validity time.Duration) *corev1.Secret {
+	ca, err := crypto.MakeSelfSignedCAConfigForDuration(name, validity)
+	g.Expect(err).NotTo(HaveOccurred())
+	cert, key, err := ca.GetPEMBytes()
+	g.Expect(err).NotTo(HaveOccurred())
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ovn-kubernetes", Name: name},
+		Data:       map[string][]byte{corev1.TLSCertKey: cert, corev1.TLSPrivateKeyKey: key},
+	}
+}
+
+func masterNode(name string) *corev1.Node {
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:   name,
+		Labels: map[string]string{"node-role.kubernetes.io/master": ""},
+	}}
+}
+
+func findResult(s *Section, level Level) []string {
+	out := []string{}
+	for _, res := range s.Results {
+		if res.Level == level {
+			out = append(out, res.Message)
+		}
+	}
+	return out
+}
+
+func TestRun(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	g.Expect(configv1.Install(scheme)).To(Succeed())
+	g.Expect(operv1.Install(scheme)).To(Succeed())
+	g.Expect(netopv1.Install(scheme)).To(Succeed())
+
+	co := &configv1.ClusterOperator{
+		ObjectMeta: metav1.ObjectMeta{Name: "network"},
+		Status: configv1.ClusterOperatorStatus{
+			Conditions: []configv1.ClusterOperatorStatusCondition{
+				{Type: configv1.OperatorAvailable, Status: configv1.ConditionTrue},
+				{Type: configv1.OperatorDegraded, Status: configv1.ConditionTrue, Reason: "RolloutHung", Message: "ovnkube-node is crashing"},
+				{Type: configv1.OperatorProgressing, Status: configv1.ConditionTrue},
+			},
+			RelatedObjects: []configv1.ObjectReference{
+				{Group: "apps", Resource: "daemonsets", Namespace: "openshift-ovn-kubernetes", Name: "ovnkube-node"},
+				{Group: "apps", Resource: "deployments", Namespace: "openshift-network-diagnostics", Name: "network-check-source"},
+				{Group: "", Resource: "namespaces", Name: "openshift-ovn-kubernetes"},
+			},
+		},
+	}
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ovn-kubernetes", Name: "ovnkube-node"},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "ovnkube-node"}},
+		},
+		Status: appsv1.DaemonSetStatus{
+			DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberAvailable: 2, NumberUnavailable: 1,
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ovn-kubernetes", Name: "ovnkube-node-abcde", Labels: map[string]string{"app": "ovnkube-node"}},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "ovnkube-node", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+			},
+		},
+	}
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-network-diagnostics", Name: "network-check-source"},
+		Status:     appsv1.DeploymentStatus{AvailableReplicas: 1},
+	}
+	conf := &operv1.Network{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: names.OPERATOR_CONFIG,
+			Annotations: map[string]string{
+				names.OVNDBMembersAnnotation:        "10.0.0.1,10.0.0.2,10.0.0.3",
+				names.OVNDBRemovedMembersAnnotation: "10.0.0.4",
+			},
+		},
+		Spec: operv1.NetworkSpec{DefaultNetwork: operv1.DefaultNetworkDefinition{Type: operv1.NetworkTypeOVNKubernetes}},
+	}
+	pki := &netopv1.OperatorPKI{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ovn-kubernetes", Name: "ovn"}}
+
+	now := time.Now()
+	objs := []client.Object{
+		co, ds, pod, dep, conf, pki,
+		masterNode("master-0"), masterNode("master-1"),
+		certSecret(g, "ovn-ca", 10*365*24*time.Hour),
+		certSecret(g, "ovn-cert", 7*24*time.Hour),
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+
+	r := Run(context.TODO(), cl, now, 30*24*time.Hour)
+	g.Expect(r.Sections).To(HaveLen(4))
+
+	clusterOperator, rollouts, databases, certificates := r.Sections[0], r.Sections[1], r.Sections[2], r.Sections[3]
+	g.Expect(findResult(clusterOperator, LevelOK)).To(ConsistOf("Available=True"))
+	g.Expect(findResult(clusterOperator, LevelFail)).To(ConsistOf("Degraded=True (RolloutHung): ovnkube-node is crashing"))
+	g.Expect(findResult(clusterOperator, LevelWarn)).To(ConsistOf("Progressing=True"))
+
+	g.Expect(findResult(rollouts, LevelOK)).To(ConsistOf(`Deployment "openshift-network-diagnostics/network-check-source" is rolled out`))
+	failed := findResult(rollouts, LevelFail)
+	g.Expect(failed).To(HaveLen(1))
+	g.Expect(failed[0]).To(HavePrefix(`DaemonSet "openshift-ovn-kubernetes/ovnkube-node" is not available (awaiting 1 nodes)`))
+	g.Expect(failed[0]).To(ContainSubstring("pod ovnkube-node-abcde is in CrashLoopBackOff State"))
+
+	g.Expect(findResult(databases, LevelOK)).To(ConsistOf("Members of the OVN databases: 10.0.0.1, 10.0.0.2, 10.0.0.3"))
+	g.Expect(findResult(databases, LevelWarn)).To(ConsistOf(
+		"Masters that left the cluster are still to be removed from the OVN databases: 10.0.0.4",
+		"2 master nodes are found, and 3 members of the OVN databases",
+	))
+
+	g.Expect(findResult(certificates, LevelOK)).To(HaveLen(1))
+	g.Expect(findResult(certificates, LevelOK)[0]).To(HavePrefix(`The certificate of Secret "openshift-ovn-kubernetes/ovn-ca" expires at`))
+	g.Expect(findResult(certificates, LevelWarn)).To(HaveLen(1))
+	g.Expect(findResult(certificates, LevelWarn)[0]).To(HavePrefix(`The certificate of Secret "openshift-ovn-kubernetes/ovn-cert" expires at`))
+
+	// an expired certificate fails
+	r = Run(context.TODO(), cl, now.Add(8*24*time.Hour), 30*24*time.Hour)
+	g.Expect(findResult(r.Sections[3], LevelFail)).To(HaveLen(1))
+	g.Expect(r.Failed()).To(Equal(3))
+
+	out := &bytes.Buffer{}
+	g.Expect(r.WriteText(out)).To(Succeed())
+	g.Expect(out.String()).To(HavePrefix("Cluster operator\n  [OK]   Available=True\n"))
+	g.Expect(out.String()).To(ContainSubstring("\nRollouts\n"))
+	g.Expect(out.String()).To(ContainSubstring("\n  [FAIL] The certificate of Secret \"openshift-ovn-kubernetes/ovn-cert\" expired at"))
+}
+
+func TestRunUnreachable(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	g.Expect(configv1.Install(scheme)).To(Succeed())
+	g.Expect(operv1.Install(scheme)).To(Succeed())
+	g.Expect(netopv1.Install(scheme)).To(Succeed())
+	cl := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	// every check still reports
+	r := Run(context.TODO(), cl, time.Now(), time.Hour)
+	g.Expect(r.Sections).To(HaveLen(4))
+	g.Expect(r.Failed()).To(Equal(3))
+	g.Expect(findResult(r.Sections[3], LevelOK)).To(ConsistOf("No OperatorPKIs are found"))
+}
@@ -0,0 +1,60 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	cnoclient "github.com/openshift/cluster-network-operator/pkg/client"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+type options struct {
+	kubeconfig    string
+	certThreshold time.Duration
+}
+
+// NewCheckCommand returns the command running, against a live cluster, the health checks the
+// operator reports its status from, and printing a report of them for support.
+func NewCheckCommand() *cobra.Command {
+	o := &options{}
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check the health of the cluster network components",
+		Long: `Check the rollout of the DaemonSets and Deployments of the cluster network, the
+membership of the OVN databases and the expiry of the certificates managed by the operator,
+and print a human-readable report. It runs in-cluster, or against the cluster of --kubeconfig.
+It fails when any of the checks fails.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run()
+		},
+		SilenceUsage: true,
+	}
+	cmd.Flags().StringVar(&o.kubeconfig, "kubeconfig", "", "the kubeconfig of the cluster, in-cluster configuration is used by default")
+	cmd.Flags().DurationVar(&o.certThreshold, "cert-expiry-threshold", 30*24*time.Hour, "warn about the certificates expiring within this duration")
+	return cmd
+}
+
+func (o *options) run() error {
+	cfg, err := clientcmd.BuildConfigFromFlags("", o.kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to load the client configuration: %w", err)
+	}
+	protocfg := *cfg
+	protocfg.ContentType = "application/vnd.kubernetes.protobuf"
+	c, err := cnoclient.New(cfg, &protocfg)
+	if err != nil {
+		return err
+	}
+
+	r := Run(context.TODO(), c.Dynamic(), time.Now(), o.certThreshold)
+	if err := r.WriteText(os.Stdout); err != nil {
+		return err
+	}
+	if failed := r.Failed(); failed > 0 {
+		return fmt.Errorf("%d checks failed", failed)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package renderdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/ghodss/yaml"
+	configv1 "github.com/openshift/api/config/v1"
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/network"
+	"github.com/openshift/cluster-network-operator/pkg/testsupport"
+	"github.com/openshift/cluster-network-operator/pkg/util/manifestdiff"
+	"github.com/spf13/cobra"
+)
+
+type options struct {
+	fromManifests string
+	toManifests   string
+	config        string
+	platform      string
+	mtu           int
+	output        string
+}
+
+// NewRenderDiffCommand returns the command rendering the manifests of two releases of the operator
+// with identical inputs, and reporting the objects and fields the upgrade from one to the other
+// adds, removes or changes.
+func NewRenderDiffCommand() *cobra.Command {
+	o := &options{}
+	cmd := &cobra.Command{
+		Use:   "render-diff --from-manifests DIR --to-manifests DIR",
+		Short: "Compare the manifests rendered by two releases of the operator",
+		Long: `Render the manifests of two releases of the operator, from their bindata directories,
+with the same operator configuration and cluster state, and report the objects and fields
+added, removed or changed from one to the other.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run()
+		},
+		SilenceUsage: true,
+	}
+	cmd.Flags().StringVar(&o.fromManifests, "from-manifests", "", "the bindata directory of the release upgraded from")
+	cmd.Flags().StringVar(&o.toManifests, "to-manifests", "/bindata", "the bindata directory of the release upgraded to")
+	cmd.Flags().StringVar(&o.config, "config", "", "a networks.operator.openshift.io object to render, by default an OVNKubernetes cluster")
+	cmd.Flags().StringVar(&o.platform, "platform", string(configv1.NonePlatformType), "the platform type of the cluster")
+	cmd.Flags().IntVar(&o.mtu, "mtu", 1500, "the MTU of the nodes")
+	cmd.Flags().StringVarP(&o.output, "output", "o", "text", "the output format, text or json")
+	_ = cmd.MarkFlagRequired("from-manifests")
+	return cmd
+}
+
+func (o *options) run() error {
+	if o.output != "text" && o.output != "json" {
+		return fmt.Errorf("--output must be text or json, is: %q", o.output)
+	}
+
+	spec := testsupport.NewNetworkSpec(operv1.NetworkTypeOVNKubernetes).Build()
+	if o.config != "" {
+		data, err := ioutil.ReadFile(o.config)
+		if err != nil {
+			return err
+		}
+		conf := &operv1.Network{}
+		if err := yaml.Unmarshal(data, conf); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", o.config, err)
+		}
+		spec = &conf.Spec
+	}
+	network.FillDefaultsWithMTU(spec, nil, o.mtu)
+	if err := network.Validate(spec); err != nil {
+		return err
+	}
+
+	bootstrapResult := testsupport.NewBootstrapResult().WithInfra(testsupport.NewInfra(configv1.PlatformType(o.platform)))
+	from, err := network.Render(spec.DeepCopy(), bootstrapResult.Build(), o.fromManifests)
+	if err != nil {
+		return fmt.Errorf("failed to render the manifests of %s: %w", o.fromManifests, err)
+	}
+	to, err := network.Render(spec.DeepCopy(), bootstrapResult.Build(), o.toManifests)
+	if err != nil {
+		return fmt.Errorf("failed to render the manifests of %s: %w", o.toManifests, err)
+	}
+
+	diffs := manifestdiff.Diff(from, to)
+	if o.output == "json" {
+		out, err := json.MarshalIndent(diffs, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(os.Stdout, string(out))
+		return err
+	}
+	return manifestdiff.WriteText(os.Stdout, diffs)
+}
@@ -4,8 +4,20 @@ import (
 	"github.com/openshift/cluster-network-operator/pkg/controller/clusterconfig"
 	configmapcainjector "github.com/openshift/cluster-network-operator/pkg/controller/configmap_ca_injector"
 	"github.com/openshift/cluster-network-operator/pkg/controller/egress_router"
+	"github.com/openshift/cluster-network-operator/pkg/controller/egressipcapacity"
+	"github.com/openshift/cluster-network-operator/pkg/controller/flowcollectors"
 	"github.com/openshift/cluster-network-operator/pkg/controller/ingressconfig"
+	"github.com/openshift/cluster-network-operator/pkg/controller/machineconfigrollout"
+	"github.com/openshift/cluster-network-operator/pkg/controller/mtuprobe"
+	"github.com/openshift/cluster-network-operator/pkg/controller/nodesubnets"
 	"github.com/openshift/cluster-network-operator/pkg/controller/operconfig"
+	"github.com/openshift/cluster-network-operator/pkg/controller/ovncrashforensics"
+	"github.com/openshift/cluster-network-operator/pkg/controller/ovnlboptions"
+	"github.com/openshift/cluster-network-operator/pkg/controller/ovnloglevel"
+	"github.com/openshift/cluster-network-operator/pkg/controller/ovnnodeidentity"
+	"github.com/openshift/cluster-network-operator/pkg/controller/ovnnodeupgrade"
+	"github.com/openshift/cluster-network-operator/pkg/controller/ovntopology"
+	"github.com/openshift/cluster-network-operator/pkg/controller/ovsflowsconfig"
 	"github.com/openshift/cluster-network-operator/pkg/controller/pki"
 	"github.com/openshift/cluster-network-operator/pkg/controller/proxyconfig"
 	signer "github.com/openshift/cluster-network-operator/pkg/controller/signer"
@@ -22,5 +34,17 @@ func init() {
 		configmapcainjector.Add,
 		signer.Add,
 		ingressconfig.Add,
+		ovnnodeupgrade.Add,
+		ovnloglevel.Add,
+		egressipcapacity.Add,
+		ovncrashforensics.Add,
+		nodesubnets.Add,
+		flowcollectors.Add,
+		mtuprobe.Add,
+		ovntopology.Add,
+		machineconfigrollout.Add,
+		ovsflowsconfig.Add,
+		ovnnodeidentity.Add,
+		ovnlboptions.Add,
 	)
 }
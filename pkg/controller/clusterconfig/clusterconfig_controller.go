@@ -11,12 +11,16 @@ import (
 	"github.com/openshift/cluster-network-operator/pkg/names"
 	"github.com/openshift/cluster-network-operator/pkg/network"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -45,9 +49,35 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Watch for node subnet allocations, to keep the cluster network usage up to date
+	err = c.Watch(&source.Kind{Type: &corev1.Node{}}, handler.EnqueueRequestsFromMapFunc(func(client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: names.CLUSTER_CONFIG}}}
+	}), predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool { return hasNodeSubnets(e.Object) },
+		UpdateFunc: func(e event.UpdateEvent) bool { return nodeSubnetsChanged(e.ObjectOld, e.ObjectNew) },
+		DeleteFunc: func(e event.DeleteEvent) bool { return hasNodeSubnets(e.Object) },
+	})
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// hasNodeSubnets returns whether ovn-kubernetes allocated subnets to a node
+func hasNodeSubnets(node client.Object) bool {
+	_, ok := node.GetAnnotations()[names.OVNNodeSubnetsAnnotation]
+	return ok
+}
+
+// nodeSubnetsChanged returns whether the subnets ovn-kubernetes allocated to a node changed, rather
+// than any of the annotations of the node, which change far more often
+func nodeSubnetsChanged(old, new client.Object) bool {
+	oldSubnets, oldOK := old.GetAnnotations()[names.OVNNodeSubnetsAnnotation]
+	newSubnets, newOK := new.GetAnnotations()[names.OVNNodeSubnetsAnnotation]
+	return oldOK != newOK || oldSubnets != newSubnets
+}
+
 var _ reconcile.Reconciler = &ReconcileClusterConfig{}
 
 // ReconcileClusterConfig reconciles a cluster Network object
@@ -70,6 +100,10 @@ func (r *ReconcileClusterConfig) Reconcile(ctx context.Context, request reconcil
 		log.Printf("Ignoring Network without default name " + names.CLUSTER_CONFIG)
 		return reconcile.Result{}, nil
 	}
+	if !r.status.IsManaged() {
+		log.Printf("Operator configuration is %s, not reconciling Network.config.openshift.io %s", r.status.ManagementState(), request.Name)
+		return reconcile.Result{RequeueAfter: statusmanager.UnmanagedRetryInterval}, nil
+	}
 
 	// Fetch the cluster config
 	clusterConfig := &configv1.Network{}
@@ -112,6 +146,11 @@ func (r *ReconcileClusterConfig) Reconcile(ctx context.Context, request reconcil
 		}
 	}
 
+	if err := r.UpdateClusterNetworkUsage(ctx, clusterConfig); err != nil {
+		// this is informational only, so don't degrade the operator for it
+		log.Printf("Could not update cluster network usage: %v", err)
+	}
+
 	r.status.SetNotDegraded(statusmanager.ClusterConfig)
 	return reconcile.Result{}, nil
 }
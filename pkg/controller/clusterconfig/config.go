@@ -8,13 +8,16 @@ import (
 
 	configv1 "github.com/openshift/api/config/v1"
 	operv1 "github.com/openshift/api/operator/v1"
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
 	"github.com/openshift/cluster-network-operator/pkg/names"
 	"github.com/openshift/cluster-network-operator/pkg/network"
 	k8sutil "github.com/openshift/cluster-network-operator/pkg/util/k8s"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -44,3 +47,78 @@ func (r *ReconcileClusterConfig) UpdateOperatorConfig(ctx context.Context, clust
 
 	return k8sutil.ToUnstructured(newOperConfig)
 }
+
+// UpdateClusterNetworkUsage records, in the status of the ClusterNetworkUsage "cluster", how
+// many node subnets are allocated out of each cluster network. It is created if it does not
+// exist. Only the network types that allocate node subnets out of the cluster networks are
+// supported.
+func (r *ReconcileClusterConfig) UpdateClusterNetworkUsage(ctx context.Context, clusterConfig *configv1.Network) error {
+	var nodeSubnets []string
+	var err error
+	switch clusterConfig.Status.NetworkType {
+	case string(operv1.NetworkTypeOVNKubernetes):
+		nodeSubnets, err = r.ovnNodeSubnets(ctx)
+	case string(operv1.NetworkTypeOpenShiftSDN):
+		nodeSubnets, err = r.sdnNodeSubnets(ctx)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	status := netopv1.ClusterNetworkUsageStatus{
+		ClusterNetworks: network.ClusterNetworkUsageFromSubnets(clusterConfig.Status.ClusterNetwork, nodeSubnets),
+	}
+
+	usage := &netopv1.ClusterNetworkUsage{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: names.OPERATOR_CONFIG}, usage); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrap(err, "could not retrieve cluster network usage")
+		}
+		usage = &netopv1.ClusterNetworkUsage{ObjectMeta: metav1.ObjectMeta{Name: names.OPERATOR_CONFIG}}
+		if err := r.client.Create(ctx, usage); err != nil {
+			return errors.Wrap(err, "could not create cluster network usage")
+		}
+	}
+	if reflect.DeepEqual(usage.Status, status) {
+		return nil
+	}
+	usage.Status = status
+	return r.client.Status().Update(ctx, usage)
+}
+
+// ovnNodeSubnets returns the node subnets allocated by ovn-kubernetes.
+func (r *ReconcileClusterConfig) ovnNodeSubnets(ctx context.Context) ([]string, error) {
+	nodes := &corev1.NodeList{}
+	if err := r.client.List(ctx, nodes); err != nil {
+		return nil, errors.Wrap(err, "could not list nodes")
+	}
+
+	subnets := []string{}
+	for i := range nodes.Items {
+		nodeSubnets, err := network.OVNNodeSubnets(&nodes.Items[i])
+		if err != nil {
+			continue
+		}
+		subnets = append(subnets, nodeSubnets...)
+	}
+	return subnets, nil
+}
+
+// sdnNodeSubnets returns the node subnets allocated by openshift-sdn.
+func (r *ReconcileClusterConfig) sdnNodeSubnets(ctx context.Context) ([]string, error) {
+	hostSubnets := &uns.UnstructuredList{}
+	hostSubnets.SetGroupVersionKind(schema.GroupVersionKind{Group: "network.openshift.io", Version: "v1", Kind: "HostSubnetList"})
+	if err := r.client.List(ctx, hostSubnets); err != nil {
+		return nil, errors.Wrap(err, "could not list hostsubnets")
+	}
+
+	subnets := []string{}
+	for _, hostSubnet := range hostSubnets.Items {
+		if subnet, ok, _ := uns.NestedString(hostSubnet.Object, "subnet"); ok {
+			subnets = append(subnets, subnet)
+		}
+	}
+	return subnets, nil
+}
@@ -0,0 +1,87 @@
+package clusterconfig
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	operv1 "github.com/openshift/api/operator/v1"
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestUpdateClusterNetworkUsage(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	g.Expect(configv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(netopv1.Install(scheme)).To(Succeed())
+
+	node := func(name, subnets string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{names.OVNNodeSubnetsAnnotation: subnets},
+		}}
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		node("node-0", `{"default":"10.0.0.0/24"}`),
+		node("node-1", `{"default":["10.0.1.0/24","fd01:0:0:1::/64"]}`),
+		node("node-2", `invalid`),
+	).Build()
+	r := &ReconcileClusterConfig{client: client}
+
+	clusterConfig := &configv1.Network{
+		ObjectMeta: metav1.ObjectMeta{Name: names.CLUSTER_CONFIG},
+		Status: configv1.NetworkStatus{
+			NetworkType: string(operv1.NetworkTypeOVNKubernetes),
+			ClusterNetwork: []configv1.ClusterNetworkEntry{
+				{CIDR: "10.0.0.0/22", HostPrefix: 24},
+				{CIDR: "fd01::/48", HostPrefix: 64},
+			},
+		},
+	}
+	g.Expect(r.UpdateClusterNetworkUsage(context.TODO(), clusterConfig)).To(Succeed())
+
+	usage := &netopv1.ClusterNetworkUsage{}
+	g.Expect(client.Get(context.TODO(), types.NamespacedName{Name: names.OPERATOR_CONFIG}, usage)).To(Succeed())
+	g.Expect(usage.Status.ClusterNetworks).To(Equal([]netopv1.ClusterNetworkEntryUsage{
+		{CIDR: "10.0.0.0/22", HostPrefix: 24, AllocatedSubnets: 2, TotalSubnets: 4, UtilizationPercent: 50},
+		{CIDR: "fd01::/48", HostPrefix: 64, AllocatedSubnets: 1, TotalSubnets: 65536, UtilizationPercent: 0},
+	}))
+
+	// the network types that do not allocate node subnets are not supported
+	clusterConfig.Status.NetworkType = "None"
+	clusterConfig.Status.ClusterNetwork = clusterConfig.Status.ClusterNetwork[:1]
+	g.Expect(r.UpdateClusterNetworkUsage(context.TODO(), clusterConfig)).To(Succeed())
+	g.Expect(client.Get(context.TODO(), types.NamespacedName{Name: names.OPERATOR_CONFIG}, usage)).To(Succeed())
+	g.Expect(usage.Status.ClusterNetworks).To(HaveLen(2))
+}
+
+func TestNodeSubnetsChanged(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	node := func(annotations map[string]string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node", Annotations: annotations}}
+	}
+	subnets := map[string]string{names.OVNNodeSubnetsAnnotation: `{"default":"10.128.0.0/23"}`}
+	g.Expect(hasNodeSubnets(node(subnets))).To(BeTrue())
+	g.Expect(hasNodeSubnets(node(nil))).To(BeFalse())
+
+	g.Expect(nodeSubnetsChanged(node(nil), node(subnets))).To(BeTrue())
+	g.Expect(nodeSubnetsChanged(node(subnets), node(nil))).To(BeTrue())
+	g.Expect(nodeSubnetsChanged(node(subnets), node(map[string]string{names.OVNNodeSubnetsAnnotation: `{"default":"10.128.2.0/23"}`}))).To(BeTrue())
+
+	// the other annotations do not change the usage
+	g.Expect(nodeSubnetsChanged(node(subnets), node(map[string]string{
+		names.OVNNodeSubnetsAnnotation: `{"default":"10.128.0.0/23"}`,
+		"k8s.ovn.org/host-addresses":   `["10.0.0.1"]`,
+	}))).To(BeFalse())
+}
@@ -87,6 +87,10 @@ type ReconcileConfigMapInjector struct {
 // entry in the configmap named trusted-ca-bundle in namespace openshift-config-managed.
 func (r *ReconcileConfigMapInjector) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
 	log.Printf("Reconciling configmap from  %s/%s\n", request.Namespace, request.Name)
+	if !r.status.IsManaged() {
+		log.Printf("Operator configuration is %s, not injecting the trusted CA bundle", r.status.ManagementState())
+		return reconcile.Result{RequeueAfter: statusmanager.UnmanagedRetryInterval}, nil
+	}
 
 	trustedCAbundleConfigMap := &corev1.ConfigMap{}
 	trustedCAbundleConfigMapName := types.NamespacedName{
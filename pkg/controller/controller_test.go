@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// readOnlyReconcilers are the reconcilers which only report the state of the cluster, so that they
+// keep running whatever the management state of the operator configuration
+var readOnlyReconcilers = map[string]string{
+	"ReconcilePods":              "reports the status of the DaemonSets and Deployments",
+	"ReconcileEgressIPCapacity":  "reports the egress IPs which cannot be assigned",
+	"ReconcileFlowCollectors":    "reports the unreachable flow collectors",
+	"ReconcileMTUProbe":          "reports the MTU black holes",
+	"ReconcileOVNCrashForensics": "reports the OVN crash reports",
+	"ReconcileCSR":               "signs the certificates requested by the nodes, which are not operands",
+}
+
+// TestReconcilersCheckManagementState checks that every controller changing the cluster leaves it as it
+// is while the operator configuration is not Managed.
+func TestReconcilersCheckManagementState(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	reconcilers := map[string]bool{}
+	dirs, err := os.ReadDir(".")
+	g.Expect(err).NotTo(HaveOccurred())
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		pkgs, err := parser.ParseDir(token.NewFileSet(), dir.Name(), func(fi os.FileInfo) bool {
+			return !strings.HasSuffix(fi.Name(), "_test.go")
+		}, 0)
+		g.Expect(err).NotTo(HaveOccurred())
+		for _, pkg := range pkgs {
+			for _, file := range pkg.Files {
+				for _, decl := range file.Decls {
+					fn, ok := decl.(*ast.FuncDecl)
+					if !ok || fn.Recv == nil || fn.Name.Name != "Reconcile" {
+						continue
+					}
+					reconcilers[receiverName(fn)] = callsIsManaged(fn)
+				}
+			}
+		}
+	}
+
+	g.Expect(reconcilers).To(HaveKey("ReconcileOperConfig"))
+	for name, checked := range reconcilers {
+		if reason, ok := readOnlyReconcilers[name]; ok {
+			g.Expect(checked).To(BeFalse(), "%s %s, but checks the management state", name, reason)
+			continue
+		}
+		g.Expect(checked).To(BeTrue(), "%s does not check the management state of the operator configuration", name)
+	}
+	for name := range readOnlyReconcilers {
+		g.Expect(reconcilers).To(HaveKey(name), "unknown reconciler %s", name)
+	}
+}
+
+// receiverName returns the name of the type of the receiver of a method
+func receiverName(fn *ast.FuncDecl) string {
+	typ := fn.Recv.List[0].Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	return typ.(*ast.Ident).Name
+}
+
+// callsIsManaged returns whether the function calls IsManaged
+func callsIsManaged(fn *ast.FuncDecl) bool {
+	found := false
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok && sel.Sel.Name == "IsManaged" {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
@@ -112,6 +112,11 @@ func (r EgressRouterReconciler) Reconcile(ctx context.Context, request reconcile
 		}
 	}
 
+	if !r.status.IsManaged() {
+		klog.Infof("Operator configuration is %s, not reconciling Egress Router %s", r.status.ManagementState(), request.NamespacedName)
+		return reconcile.Result{RequeueAfter: ResyncPeriod}, nil
+	}
+
 	if existing == nil {
 		klog.Infof("Creating a new Egress Router")
 		// Set owner reference to the controller
@@ -223,11 +228,11 @@ func (r *EgressRouterReconciler) ensureEgressRouter(manifestDir string, namespac
 	for _, obj := range out {
 		klog.Infof("Assigning owner references")
 		obj.SetOwnerReferences(EgressRouterOwnerReferences)
-		klog.Infof("Applying manifest")
-		if err := apply.ApplyObject(context.TODO(), r.client, obj); err != nil {
-			klog.Infof("could not apply egress router object: %v", err)
-			return err
-		}
+	}
+	klog.Infof("Applying manifests")
+	if err := apply.ApplyObjects(context.TODO(), r.client, out); err != nil {
+		klog.Infof("could not apply egress router object: %v", err)
+		return err
 	}
 
 	return nil
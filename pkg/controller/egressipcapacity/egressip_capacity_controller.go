@@ -0,0 +1,172 @@
+package egressipcapacity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/controller/statusmanager"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/openshift/cluster-network-operator/pkg/platform"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// egressAssignableLabel marks the nodes that can host egress IPs
+	egressAssignableLabel = "k8s.ovn.org/egress-assignable"
+	// egressIPConfigAnnotation is set on the nodes of the cloud platforms by the
+	// cloud-network-config-controller, with the number of egress IPs each interface can host
+	egressIPConfigAnnotation = "cloud.network.openshift.io/egress-ipconfig"
+)
+
+var egressIPListGVK = schema.GroupVersionKind{Group: "k8s.ovn.org", Version: "v1", Kind: "EgressIPList"}
+
+// The periodic resync interval.
+// We will re-run the reconciliation logic, even if the network configuration
+// hasn't changed.
+var ResyncPeriod = 3 * time.Minute
+
+// Add creates a new egress IP capacity controller and adds it to the Manager. The Manager will set fields on the
+// Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager, status *statusmanager.StatusManager) error {
+	return add(mgr, &ReconcileEgressIPCapacity{client: mgr.GetClient(), status: status})
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileEgressIPCapacity) error {
+	c, err := controller.New("egressip-capacity-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	// Watch the operator configuration, the capacity is then checked periodically
+	return c.Watch(&source.Kind{Type: &operv1.Network{}}, &handler.EnqueueRequestForObject{})
+}
+
+var _ reconcile.Reconciler = &ReconcileEgressIPCapacity{}
+
+// ReconcileEgressIPCapacity compares the egress IPs requested by the EgressIP objects of ovn-kubernetes
+// with the number of egress IPs the egress-assignable nodes can host, and reports the utilization on
+// the operator configuration, along with whether some of them cannot be assigned.
+type ReconcileEgressIPCapacity struct {
+	client client.Client
+	status *statusmanager.StatusManager
+}
+
+// egressIPCapacity is the utilization of the egress IP capacity of the cluster
+type egressIPCapacity struct {
+	// Requested and Assigned are the numbers of egress IPs requested by the EgressIP objects, and assigned to a node
+	Requested int
+	Assigned  int
+	// Capacity is the number of egress IPs the Nodes egress-assignable nodes can host, unless Unlimited
+	Capacity  int
+	Nodes     int
+	Unlimited bool
+}
+
+// Reconcile reports the egress IP capacity of the cluster
+func (r *ReconcileEgressIPCapacity) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	if request.Name != names.OPERATOR_CONFIG {
+		return reconcile.Result{}, nil
+	}
+	operConfig := &operv1.Network{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: names.OPERATOR_CONFIG}, operConfig); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		log.Printf("Unable to retrieve Network.operator.openshift.io object: %v", err)
+		return reconcile.Result{}, err
+	}
+	if operConfig.Spec.DefaultNetwork.Type != operv1.NetworkTypeOVNKubernetes {
+		return reconcile.Result{}, nil
+	}
+
+	egressIPs := &uns.UnstructuredList{}
+	egressIPs.SetGroupVersionKind(egressIPListGVK)
+	if err := r.client.List(ctx, egressIPs); err != nil {
+		if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+			// ovn-kubernetes has not registered the EgressIP CRD yet
+			return reconcile.Result{RequeueAfter: ResyncPeriod}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	nodes := &corev1.NodeList{}
+	if err := r.client.List(ctx, nodes, client.HasLabels{egressAssignableLabel}); err != nil {
+		return reconcile.Result{}, err
+	}
+	infra, err := platform.BootstrapInfra(r.client)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	capacity := computeEgressIPCapacity(infra.EgressIPCapacityLimited, nodes.Items, egressIPs.Items)
+	unassignable, reason, message := capacity.report()
+	r.status.SetEgressIPCapacity(unassignable, reason, message)
+	return reconcile.Result{RequeueAfter: ResyncPeriod}, nil
+}
+
+// computeEgressIPCapacity computes the utilization of the egress IP capacity. On the platforms where it is
+// limited, the capacity of a node is the sum of the capacities of its interfaces.
+func computeEgressIPCapacity(limited bool, nodes []corev1.Node, egressIPs []uns.Unstructured) egressIPCapacity {
+	capacity := egressIPCapacity{Nodes: len(nodes), Unlimited: !limited}
+	for _, egressIP := range egressIPs {
+		requested, _, _ := uns.NestedStringSlice(egressIP.Object, "spec", "egressIPs")
+		capacity.Requested += len(requested)
+		assigned, _, _ := uns.NestedSlice(egressIP.Object, "status", "items")
+		capacity.Assigned += len(assigned)
+	}
+	if !limited {
+		return capacity
+	}
+	for _, node := range nodes {
+		config, ok := node.Annotations[egressIPConfigAnnotation]
+		if !ok {
+			continue
+		}
+		interfaces := []struct {
+			Capacity struct {
+				IPv4 int `json:"ipv4"`
+				IPv6 int `json:"ipv6"`
+				IP   int `json:"ip"`
+			} `json:"capacity"`
+		}{}
+		if err := json.Unmarshal([]byte(config), &interfaces); err != nil {
+			log.Printf("Ignoring invalid %s annotation of node %s: %v", egressIPConfigAnnotation, node.Name, err)
+			continue
+		}
+		for _, iface := range interfaces {
+			capacity.Capacity += iface.Capacity.IPv4 + iface.Capacity.IPv6 + iface.Capacity.IP
+		}
+	}
+	return capacity
+}
+
+// report returns whether some egress IPs cannot be assigned, and the reason and message of the condition
+func (c egressIPCapacity) report() (bool, string, string) {
+	message := fmt.Sprintf("%d of %d egress IPs assigned, on %d egress-assignable nodes", c.Assigned, c.Requested, c.Nodes)
+	if !c.Unlimited {
+		message = fmt.Sprintf("%d of %d egress IPs assigned, on %d egress-assignable nodes with a capacity of %d egress IPs",
+			c.Assigned, c.Requested, c.Nodes, c.Capacity)
+	}
+	switch {
+	case c.Requested > 0 && c.Nodes == 0:
+		return true, "NoEgressAssignableNodes", message + fmt.Sprintf("; label nodes with %s to host egress IPs", egressAssignableLabel)
+	case !c.Unlimited && c.Requested > c.Capacity:
+		return true, "EgressIPCapacityExceeded", message
+	}
+	return false, "EgressIPsAssignable", message
+}
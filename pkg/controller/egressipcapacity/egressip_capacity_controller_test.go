@@ -0,0 +1,72 @@
+package egressipcapacity
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestComputeEgressIPCapacity(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	egressIP := func(requested []interface{}, assigned int) uns.Unstructured {
+		items := []interface{}{}
+		for i := 0; i < assigned; i++ {
+			items = append(items, map[string]interface{}{"node": "node", "egressIP": requested[i]})
+		}
+		return uns.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "k8s.ovn.org/v1",
+			"kind":       "EgressIP",
+			"spec":       map[string]interface{}{"egressIPs": requested},
+			"status":     map[string]interface{}{"items": items},
+		}}
+	}
+	egressIPs := []uns.Unstructured{
+		egressIP([]interface{}{"10.0.0.10", "10.0.0.11"}, 2),
+		egressIP([]interface{}{"10.0.0.12", "10.0.0.13"}, 0),
+	}
+	node := func(name, config string) corev1.Node {
+		n := corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{}}}
+		if config != "" {
+			n.Annotations[egressIPConfigAnnotation] = config
+		}
+		return n
+	}
+	nodes := []corev1.Node{
+		node("aws", `[{"interface":"eni-1","ifaddr":{"ipv4":"10.0.128.0/18"},"capacity":{"ipv4":1,"ipv6":1}}]`),
+		node("gcp", `[{"interface":"nic0","ifaddr":{"ipv4":"10.0.128.0/18"},"capacity":{"ip":1}}]`),
+		node("invalid", `not json`),
+		node("missing", ""),
+	}
+
+	// the capacity of the nodes is unlimited on the other platforms
+	capacity := computeEgressIPCapacity(false, nodes, egressIPs)
+	g.Expect(capacity).To(Equal(egressIPCapacity{Requested: 4, Assigned: 2, Nodes: 4, Unlimited: true}))
+	unassignable, reason, message := capacity.report()
+	g.Expect(unassignable).To(BeFalse())
+	g.Expect(reason).To(Equal("EgressIPsAssignable"))
+	g.Expect(message).To(Equal("2 of 4 egress IPs assigned, on 4 egress-assignable nodes"))
+
+	capacity = computeEgressIPCapacity(true, nodes, egressIPs)
+	g.Expect(capacity).To(Equal(egressIPCapacity{Requested: 4, Assigned: 2, Capacity: 3, Nodes: 4}))
+	unassignable, reason, message = capacity.report()
+	g.Expect(unassignable).To(BeTrue())
+	g.Expect(reason).To(Equal("EgressIPCapacityExceeded"))
+	g.Expect(message).To(Equal("2 of 4 egress IPs assigned, on 4 egress-assignable nodes with a capacity of 3 egress IPs"))
+
+	capacity = computeEgressIPCapacity(true, nodes[:2], egressIPs[:1])
+	unassignable, _, _ = capacity.report()
+	g.Expect(unassignable).To(BeFalse())
+
+	capacity = computeEgressIPCapacity(false, nil, egressIPs)
+	unassignable, reason, _ = capacity.report()
+	g.Expect(unassignable).To(BeTrue())
+	g.Expect(reason).To(Equal("NoEgressAssignableNodes"))
+
+	// no egress IPs, nothing to assign
+	unassignable, _, _ = computeEgressIPCapacity(true, nil, nil).report()
+	g.Expect(unassignable).To(BeFalse())
+}
@@ -0,0 +1,217 @@
+package flowcollectors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/controller/statusmanager"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/openshift/cluster-network-operator/pkg/network"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// probeTimeout bounds the resolution of a collector, and how long an answer to its probe is waited for
+	probeTimeout = 3 * time.Second
+	// maxListedCollectors is the number of unreachable collectors listed in the condition
+	maxListedCollectors = 5
+)
+
+// The periodic resync interval.
+// We will re-run the reconciliation logic, even if the network configuration
+// hasn't changed.
+var ResyncPeriod = 3 * time.Minute
+
+// Add creates a new flow collectors controller and adds it to the Manager. The Manager will set fields on the
+// Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager, status *statusmanager.StatusManager) error {
+	return add(mgr, &ReconcileFlowCollectors{client: mgr.GetClient(), status: status, probe: probeCollector})
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileFlowCollectors) error {
+	c, err := controller.New("flow-collectors-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	// Watch the operator configuration, the collectors are then probed periodically
+	return c.Watch(&source.Kind{Type: &operv1.Network{}}, &handler.EnqueueRequestForObject{})
+}
+
+var _ reconcile.Reconciler = &ReconcileFlowCollectors{}
+
+// ReconcileFlowCollectors probes, when requested on the operator configuration, the NetFlow, sFlow and
+// IPFIX collectors the flows are exported to, and reports the unreachable ones, whose flow records
+// would otherwise be dropped silently.
+type ReconcileFlowCollectors struct {
+	client client.Client
+	status *statusmanager.StatusManager
+	// probe returns why the collector, as "<host>:<port>", cannot be reached, if it cannot
+	probe func(ctx context.Context, collector string) error
+}
+
+// flowCollector is a collector the flows are exported to, with the protocol it receives them with
+type flowCollector struct {
+	Protocol string
+	Address  string
+}
+
+func (c flowCollector) String() string {
+	return c.Protocol + " collector " + c.Address
+}
+
+// Reconcile probes the flow collectors
+func (r *ReconcileFlowCollectors) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	if request.Name != names.OPERATOR_CONFIG {
+		return reconcile.Result{}, nil
+	}
+	operConfig := &operv1.Network{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: names.OPERATOR_CONFIG}, operConfig); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		log.Printf("Unable to retrieve Network.operator.openshift.io object: %v", err)
+		return reconcile.Result{}, err
+	}
+	if operConfig.Annotations[names.VerifyFlowCollectorsAnnotation] != "true" {
+		r.status.SetFlowCollectors(false, "NotVerified", "The flow collectors are not verified")
+		return reconcile.Result{}, nil
+	}
+
+	collectors := specFlowCollectors(&operConfig.Spec)
+	shared, err := r.sharedFlowCollector(ctx)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if shared != nil {
+		collectors = append(collectors, *shared)
+	}
+
+	failures := map[flowCollector]error{}
+	for _, collector := range collectors {
+		if err := r.probe(ctx, collector.Address); err != nil {
+			failures[collector] = err
+		}
+	}
+	unreachable, reason, message := report(collectors, failures)
+	r.status.SetFlowCollectors(unreachable, reason, message)
+	return reconcile.Result{RequeueAfter: ResyncPeriod}, nil
+}
+
+// specFlowCollectors returns the collectors of the exportNetworkFlows of the operator configuration
+func specFlowCollectors(spec *operv1.NetworkSpec) []flowCollector {
+	collectors := []flowCollector{}
+	flows := spec.ExportNetworkFlows
+	if flows == nil {
+		return collectors
+	}
+	add := func(protocol string, ipPorts []operv1.IPPort) {
+		for _, ipPort := range ipPorts {
+			collectors = append(collectors, flowCollector{Protocol: protocol, Address: string(ipPort)})
+		}
+	}
+	if flows.NetFlow != nil {
+		add("NetFlow", flows.NetFlow.Collectors)
+	}
+	if flows.SFlow != nil {
+		add("sFlow", flows.SFlow.Collectors)
+	}
+	if flows.IPFIX != nil {
+		add("IPFIX", flows.IPFIX.Collectors)
+	}
+	return collectors
+}
+
+// sharedFlowCollector returns the IPFIX collector shared by all the nodes of the ovs-flows-config OVSFlowsConfig,
+// or legacy ConfigMap, if any. The collectors listening on the node port of each node, or over TLS, are not probed.
+func (r *ReconcileFlowCollectors) sharedFlowCollector(ctx context.Context) (*flowCollector, error) {
+	spec, err := network.GetOVSFlowsConfigSpec(ctx, r.client)
+	if err != nil || spec == nil || spec.SharedTarget == "" {
+		return nil, err
+	}
+	// the collectors over TLS are reached through the relay on each node, over TCP, and are not probed
+	if spec.TLS {
+		return nil, nil
+	}
+	return &flowCollector{Protocol: "IPFIX", Address: spec.SharedTarget}, nil
+}
+
+// report returns the FlowCollectorsUnreachable condition of the probed collectors
+func report(collectors []flowCollector, failures map[flowCollector]error) (bool, string, string) {
+	if len(failures) == 0 {
+		return false, "AsExpected", fmt.Sprintf("The %d flow collectors are reachable", len(collectors))
+	}
+	unreachable := []string{}
+	for collector, err := range failures {
+		unreachable = append(unreachable, fmt.Sprintf("%s: %v", collector, err))
+	}
+	sort.Strings(unreachable)
+	listed := unreachable
+	if len(listed) > maxListedCollectors {
+		listed = listed[:maxListedCollectors]
+	}
+	message := fmt.Sprintf("%d of the %d flow collectors are unreachable, their flow records are dropped: %s",
+		len(unreachable), len(collectors), strings.Join(listed, "; "))
+	if len(unreachable) > len(listed) {
+		message += fmt.Sprintf(" and %d more", len(unreachable)-len(listed))
+	}
+	return true, "FlowCollectorsUnreachable", message
+}
+
+// probeCollector resolves the collector, then sends it an empty UDP datagram, which NetFlow, sFlow and IPFIX
+// are exported over. As a collector does not answer, the collector is only reported unreachable when the
+// datagram is refused, typically with an ICMP port unreachable: a filtered collector cannot be told apart
+// from a reachable one.
+func probeCollector(ctx context.Context, collector string) error {
+	host, port, err := net.SplitHostPort(collector)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return fmt.Errorf("cannot resolve %s: %w", host, err)
+	}
+
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "udp", net.JoinHostPort(addrs[0], port))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(probeTimeout)); err != nil {
+		return err
+	}
+	if _, err := conn.Write([]byte{}); err != nil {
+		return err
+	}
+	// the refusal of the datagram is only reported on the socket by the next read
+	if _, err := conn.Read(make([]byte, 1)); err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return nil
+		}
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			return fmt.Errorf("%s refused the flow records", net.JoinHostPort(addrs[0], port))
+		}
+		return err
+	}
+	return nil
+}
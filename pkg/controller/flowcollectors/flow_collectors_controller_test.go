@@ -0,0 +1,120 @@
+package flowcollectors
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/controller/statusmanager"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/openshift/cluster-network-operator/pkg/network"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcileFlowCollectors(t *testing.T) {
+	g := NewGomegaWithT(t)
+	g.Expect(operv1.AddToScheme(scheme.Scheme)).To(Succeed())
+	g.Expect(configv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	operConfig := &operv1.Network{
+		ObjectMeta: metav1.ObjectMeta{Name: names.OPERATOR_CONFIG},
+		Spec: operv1.NetworkSpec{ExportNetworkFlows: &operv1.ExportNetworkFlows{
+			NetFlow: &operv1.NetFlowConfig{Collectors: []operv1.IPPort{"10.0.0.1:2056"}},
+			IPFIX:   &operv1.IPFIXConfig{Collectors: []operv1.IPPort{"10.0.0.2:4739"}},
+		}},
+	}
+	flowsConfig := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: network.OVSFlowsConfigNamespace, Name: network.OVSFlowsConfigMapName},
+		Data:       map[string]string{"sharedTarget": "collector.example.com:4739"},
+	}
+	probed := []string{}
+	client := fake.NewClientBuilder().WithObjects(operConfig, flowsConfig).Build()
+	r := &ReconcileFlowCollectors{
+		client: client,
+		status: statusmanager.New(client, nil, "testing"),
+		probe: func(_ context.Context, collector string) error {
+			probed = append(probed, collector)
+			return nil
+		},
+	}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: names.OPERATOR_CONFIG}}
+
+	// the collectors are only probed when requested
+	result, err := r.Reconcile(context.TODO(), request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(BeZero())
+	g.Expect(probed).To(BeEmpty())
+
+	g.Expect(client.Get(context.TODO(), types.NamespacedName{Name: names.OPERATOR_CONFIG}, operConfig)).To(Succeed())
+	operConfig.Annotations = map[string]string{names.VerifyFlowCollectorsAnnotation: "true"}
+	g.Expect(client.Update(context.TODO(), operConfig)).To(Succeed())
+	result, err = r.Reconcile(context.TODO(), request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(ResyncPeriod))
+	g.Expect(probed).To(Equal([]string{"10.0.0.1:2056", "10.0.0.2:4739", "collector.example.com:4739"}))
+
+	r.probe = func(_ context.Context, collector string) error {
+		if collector == "10.0.0.2:4739" {
+			return errors.New("10.0.0.2:4739 refused the flow records")
+		}
+		return nil
+	}
+	_, err = r.Reconcile(context.TODO(), request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(client.Get(context.TODO(), types.NamespacedName{Name: names.OPERATOR_CONFIG}, operConfig)).To(Succeed())
+	cond := v1helpers.FindOperatorCondition(operConfig.Status.Conditions, statusmanager.OperatorStatusTypeFlowCollectorsUnreachable)
+	g.Expect(cond).NotTo(BeNil())
+	g.Expect(cond.Status).To(Equal(operv1.ConditionTrue))
+	g.Expect(cond.Message).To(HaveSuffix("IPFIX collector 10.0.0.2:4739: 10.0.0.2:4739 refused the flow records"))
+}
+
+func TestReport(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	netflow := flowCollector{Protocol: "NetFlow", Address: "10.0.0.1:2056"}
+	ipfix := flowCollector{Protocol: "IPFIX", Address: "collector.example.com:4739"}
+	collectors := []flowCollector{netflow, ipfix}
+
+	unreachable, reason, message := report(collectors, map[flowCollector]error{})
+	g.Expect(unreachable).To(BeFalse())
+	g.Expect(reason).To(Equal("AsExpected"))
+	g.Expect(message).To(Equal("The 2 flow collectors are reachable"))
+
+	unreachable, reason, message = report(collectors, map[flowCollector]error{
+		netflow: errors.New("10.0.0.1:2056 refused the flow records"),
+		ipfix:   errors.New("cannot resolve collector.example.com: no such host"),
+	})
+	g.Expect(unreachable).To(BeTrue())
+	g.Expect(reason).To(Equal("FlowCollectorsUnreachable"))
+	g.Expect(message).To(Equal("2 of the 2 flow collectors are unreachable, their flow records are dropped: " +
+		"IPFIX collector collector.example.com:4739: cannot resolve collector.example.com: no such host; " +
+		"NetFlow collector 10.0.0.1:2056: 10.0.0.1:2056 refused the flow records"))
+}
+
+func TestProbeCollector(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer listener.Close()
+	g.Expect(probeCollector(context.TODO(), listener.LocalAddr().String())).To(Succeed())
+
+	// nothing listens on the port anymore, the datagram is refused
+	closed, err := net.ListenPacket("udp", "127.0.0.1:0")
+	g.Expect(err).NotTo(HaveOccurred())
+	address := closed.LocalAddr().String()
+	closed.Close()
+	g.Expect(probeCollector(context.TODO(), address)).To(MatchError(address + " refused the flow records"))
+
+	g.Expect(probeCollector(context.TODO(), "10.0.0.1")).NotTo(Succeed())
+}
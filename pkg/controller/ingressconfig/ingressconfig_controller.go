@@ -32,12 +32,12 @@ var ManifestPath = "./bindata"
 // Add creates a new ingressConfig controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager, status *statusmanager.StatusManager) error {
-	return add(mgr, newIngressConfigReconciler(mgr.GetClient()))
+	return add(mgr, newIngressConfigReconciler(mgr.GetClient(), status))
 }
 
 // newIngressConfigReconciler returns a new reconcile.Reconciler
-func newIngressConfigReconciler(client client.Client) *ReconcileIngressConfigs {
-	return &ReconcileIngressConfigs{client: client}
+func newIngressConfigReconciler(client client.Client, status *statusmanager.StatusManager) *ReconcileIngressConfigs {
+	return &ReconcileIngressConfigs{client: client, status: status}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
@@ -60,6 +60,7 @@ var _ reconcile.Reconciler = &ReconcileIngressConfigs{}
 // and sets the network policy related labels on the openshift-host-network namespace
 type ReconcileIngressConfigs struct {
 	client client.Client
+	status *statusmanager.StatusManager
 }
 
 // Reconcile sets the openshift-host-network namespaces' labels as per the
@@ -76,6 +77,10 @@ func (r *ReconcileIngressConfigs) Reconcile(ctx context.Context, request reconci
 		return reconcile.Result{}, nil
 	}
 	log.Printf("Reconciling update to IngressController %s/%s\n", request.Namespace, request.Name)
+	if !r.status.IsManaged() {
+		log.Printf("Operator configuration is %s, not labeling namespace %s", r.status.ManagementState(), names.HostNetworkNamespace)
+		return reconcile.Result{RequeueAfter: ResyncPeriod}, nil
+	}
 	ingressControllerConfig := &operv1.IngressController{TypeMeta: metav1.TypeMeta{APIVersion: operv1.GroupVersion.String(), Kind: "IngressController"}}
 	err := r.client.Get(ctx, request.NamespacedName, ingressControllerConfig)
 	if err != nil {
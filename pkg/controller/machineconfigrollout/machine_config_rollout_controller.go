@@ -0,0 +1,168 @@
+package machineconfigrollout
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/controller/statusmanager"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/openshift/cluster-network-operator/pkg/network"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var machineConfigListGVK = schema.GroupVersionKind{Group: "machineconfiguration.openshift.io", Version: "v1", Kind: "MachineConfigList"}
+
+// The periodic resync interval.
+// We will re-check the rollout of the MachineConfigs, even if the network configuration
+// hasn't changed.
+var ResyncPeriod = 2 * time.Minute
+
+// Add creates a new machine-config-rollout controller and adds it to the Manager. The Manager will set fields on
+// the Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager, status *statusmanager.StatusManager) error {
+	return add(mgr, &ReconcileMachineConfigRollout{client: mgr.GetClient(), status: status})
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileMachineConfigRollout) error {
+	c, err := controller.New("machine-config-rollout-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	// Watch the operator configuration, the pools are then checked periodically
+	return c.Watch(&source.Kind{Type: &operv1.Network{}}, &handler.EnqueueRequestForObject{})
+}
+
+var _ reconcile.Reconciler = &ReconcileMachineConfigRollout{}
+
+// ReconcileMachineConfigRollout follows the rollout, by the machine-config-operator, of the MachineConfigs
+// holding the prerequisites of the network on the nodes, and reports the pools of nodes they are not yet
+// rolled out on.
+type ReconcileMachineConfigRollout struct {
+	client client.Client
+	status *statusmanager.StatusManager
+}
+
+// Reconcile checks the rollout of the MachineConfigs generated by the operator
+func (r *ReconcileMachineConfigRollout) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	if request.Name != names.OPERATOR_CONFIG {
+		return reconcile.Result{}, nil
+	}
+	if !r.status.IsManaged() {
+		log.Printf("Operator configuration is %s, not rolling out the MachineConfigs", r.status.ManagementState())
+		return reconcile.Result{RequeueAfter: ResyncPeriod}, nil
+	}
+	operConfig := &operv1.Network{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: names.OPERATOR_CONFIG}, operConfig); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		log.Printf("Unable to retrieve Network.operator.openshift.io object: %v", err)
+		return reconcile.Result{}, err
+	}
+	if operConfig.Annotations[names.MachineConfigAnnotation] != "true" {
+		r.status.SetMachineConfigRollout(false, "NotRequested", "The MachineConfigs of the network are not generated")
+		return reconcile.Result{}, nil
+	}
+
+	machineConfigs := &uns.UnstructuredList{}
+	machineConfigs.SetGroupVersionKind(machineConfigListGVK)
+	if err := r.client.List(ctx, machineConfigs, client.HasLabels{network.MachineConfigLabel}); err != nil {
+		if meta.IsNoMatchError(err) {
+			r.status.SetMachineConfigRollout(false, "NotManaged", "The nodes are not managed by the machine-config-operator")
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	pools := &uns.UnstructuredList{}
+	pools.SetGroupVersionKind(network.MachineConfigPoolListGVK)
+	if err := r.client.List(ctx, pools); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	pending := []string{}
+	for _, pool := range pools.Items {
+		rolledOut, err := isRolledOut(&pool, machineConfigs.Items)
+		if err != nil {
+			log.Printf("Unable to check the rollout of MachineConfigPool %s: %v", pool.GetName(), err)
+			continue
+		}
+		if !rolledOut {
+			pending = append(pending, pool.GetName())
+		}
+	}
+	sort.Strings(pending)
+	if len(pending) > 0 {
+		r.status.SetMachineConfigRollout(true, "RollingOut",
+			fmt.Sprintf("The MachineConfigs of the network are rolling out on the pools %s", strings.Join(pending, ", ")))
+	} else {
+		r.status.SetMachineConfigRollout(false, "AsExpected",
+			fmt.Sprintf("The %d MachineConfigs of the network are rolled out", len(machineConfigs.Items)))
+	}
+	return reconcile.Result{RequeueAfter: ResyncPeriod}, nil
+}
+
+// isRolledOut returns whether the MachineConfigs selected by the pool are part of its current configuration,
+// and all of its machines are updated to it
+func isRolledOut(pool *uns.Unstructured, machineConfigs []uns.Unstructured) (bool, error) {
+	selector, err := machineConfigSelector(pool)
+	if err != nil {
+		return false, err
+	}
+	rendered := map[string]bool{}
+	sources, _, err := uns.NestedSlice(pool.Object, "status", "configuration", "source")
+	if err != nil {
+		return false, err
+	}
+	for _, source := range sources {
+		if ref, ok := source.(map[string]interface{}); ok {
+			if name, ok := ref["name"].(string); ok {
+				rendered[name] = true
+			}
+		}
+	}
+	for _, machineConfig := range machineConfigs {
+		if selector.Matches(labels.Set(machineConfig.GetLabels())) && !rendered[machineConfig.GetName()] {
+			return false, nil
+		}
+	}
+
+	// the machines are updated to the rendered configuration
+	spec, _, _ := uns.NestedString(pool.Object, "spec", "configuration", "name")
+	current, _, _ := uns.NestedString(pool.Object, "status", "configuration", "name")
+	machines, _, _ := uns.NestedInt64(pool.Object, "status", "machineCount")
+	updated, _, _ := uns.NestedInt64(pool.Object, "status", "updatedMachineCount")
+	return spec == current && updated == machines, nil
+}
+
+// machineConfigSelector returns the selector of the MachineConfigs of the pool
+func machineConfigSelector(pool *uns.Unstructured) (labels.Selector, error) {
+	obj, found, err := uns.NestedMap(pool.Object, "spec", "machineConfigSelector")
+	if err != nil || !found {
+		return labels.Nothing(), err
+	}
+	selector := &metav1.LabelSelector{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj, selector); err != nil {
+		return nil, err
+	}
+	return metav1.LabelSelectorAsSelector(selector)
+}
@@ -0,0 +1,137 @@
+package machineconfigrollout
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/controller/statusmanager"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/openshift/cluster-network-operator/pkg/network"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func machineConfig(name, role string) *uns.Unstructured {
+	return &uns.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "machineconfiguration.openshift.io/v1",
+		"kind":       "MachineConfig",
+		"metadata": map[string]interface{}{
+			"name":   name,
+			"labels": map[string]interface{}{"machineconfiguration.openshift.io/role": role, network.MachineConfigLabel: ""},
+		},
+	}}
+}
+
+func machineConfigPool(name string, sources []string, machines, updated int64) *uns.Unstructured {
+	source := []interface{}{}
+	for _, s := range sources {
+		source = append(source, map[string]interface{}{"kind": "MachineConfig", "name": s})
+	}
+	return &uns.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "machineconfiguration.openshift.io/v1",
+		"kind":       "MachineConfigPool",
+		"metadata":   map[string]interface{}{"name": name},
+		"spec": map[string]interface{}{
+			"configuration": map[string]interface{}{"name": "rendered-" + name},
+			"machineConfigSelector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{"machineconfiguration.openshift.io/role": name},
+			},
+		},
+		"status": map[string]interface{}{
+			"configuration":       map[string]interface{}{"name": "rendered-" + name, "source": source},
+			"machineCount":        machines,
+			"updatedMachineCount": updated,
+		},
+	}}
+}
+
+func TestReconcileMachineConfigRollout(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// MachineConfigs and MachineConfigPools are only known as unstructured objects
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	g.Expect(operv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(configv1.AddToScheme(scheme)).To(Succeed())
+	for _, gvk := range []struct{ kind, list string }{{"MachineConfig", "MachineConfigList"}, {"MachineConfigPool", "MachineConfigPoolList"}} {
+		gv := network.MachineConfigPoolListGVK.GroupVersion()
+		scheme.AddKnownTypeWithName(gv.WithKind(gvk.kind), &uns.Unstructured{})
+		scheme.AddKnownTypeWithName(gv.WithKind(gvk.list), &uns.UnstructuredList{})
+	}
+
+	operConfig := &operv1.Network{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        names.OPERATOR_CONFIG,
+			Annotations: map[string]string{names.MachineConfigAnnotation: "true"},
+		},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		operConfig,
+		machineConfig("50-master-network-operator", "master"),
+		machineConfig("50-worker-network-operator", "worker"),
+		machineConfigPool("master", []string{"00-master", "50-master-network-operator"}, 3, 3),
+		// the MachineConfig is rendered, but not all of the machines are updated
+		machineConfigPool("worker", []string{"00-worker", "50-worker-network-operator"}, 3, 2),
+		// the MachineConfig is not rendered yet
+		machineConfigPool("infra", []string{"00-worker"}, 2, 2),
+	).Build()
+	// the infra pool inherits the MachineConfigs of the worker role
+	pool := &uns.Unstructured{}
+	pool.SetGroupVersionKind(network.MachineConfigPoolListGVK.GroupVersion().WithKind("MachineConfigPool"))
+	g.Expect(client.Get(context.TODO(), types.NamespacedName{Name: "infra"}, pool)).To(Succeed())
+	g.Expect(uns.SetNestedField(pool.Object, map[string]interface{}{
+		"matchExpressions": []interface{}{map[string]interface{}{
+			"key": "machineconfiguration.openshift.io/role", "operator": "In", "values": []interface{}{"worker", "infra"},
+		}},
+	}, "spec", "machineConfigSelector")).To(Succeed())
+	g.Expect(client.Update(context.TODO(), pool)).To(Succeed())
+
+	r := &ReconcileMachineConfigRollout{client: client, status: statusmanager.New(client, nil, "testing")}
+	r.status.SetManagementState(operv1.Managed)
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: names.OPERATOR_CONFIG}}
+	condition := func() *operv1.OperatorCondition {
+		g.Expect(client.Get(context.TODO(), types.NamespacedName{Name: names.OPERATOR_CONFIG}, operConfig)).To(Succeed())
+		return v1helpers.FindOperatorCondition(operConfig.Status.Conditions, statusmanager.OperatorStatusTypeMachineConfigsRollingOut)
+	}
+
+	result, err := r.Reconcile(context.TODO(), request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(ResyncPeriod))
+	cond := condition()
+	g.Expect(cond).NotTo(BeNil())
+	g.Expect(cond.Status).To(Equal(operv1.ConditionTrue))
+	g.Expect(cond.Message).To(HaveSuffix("rolling out on the pools infra, worker"))
+
+	// the rollout completes
+	for _, name := range []string{"worker", "infra"} {
+		g.Expect(client.Get(context.TODO(), types.NamespacedName{Name: name}, pool)).To(Succeed())
+		g.Expect(uns.SetNestedSlice(pool.Object, []interface{}{
+			map[string]interface{}{"kind": "MachineConfig", "name": "50-worker-network-operator"},
+		}, "status", "configuration", "source")).To(Succeed())
+		g.Expect(uns.SetNestedField(pool.Object, int64(2), "status", "machineCount")).To(Succeed())
+		g.Expect(uns.SetNestedField(pool.Object, int64(2), "status", "updatedMachineCount")).To(Succeed())
+		g.Expect(client.Update(context.TODO(), pool)).To(Succeed())
+	}
+	_, err = r.Reconcile(context.TODO(), request)
+	g.Expect(err).NotTo(HaveOccurred())
+	cond = condition()
+	g.Expect(cond.Status).To(Equal(operv1.ConditionFalse))
+	g.Expect(cond.Reason).To(Equal("AsExpected"))
+
+	// nothing is followed unless the MachineConfigs are requested
+	operConfig.Annotations = nil
+	g.Expect(client.Update(context.TODO(), operConfig)).To(Succeed())
+	result, err = r.Reconcile(context.TODO(), request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(BeZero())
+	g.Expect(condition().Reason).To(Equal("NotRequested"))
+}
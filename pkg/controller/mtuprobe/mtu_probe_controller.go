@@ -0,0 +1,154 @@
+package mtuprobe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/controller/statusmanager"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// probeNamespace and probeApp select the pods of the network-check-mtu daemonset
+	probeNamespace = "openshift-network-diagnostics"
+	probeApp       = "network-check-mtu"
+	// maxListedPairs is the number of node pairs listed in the condition
+	maxListedPairs = 5
+)
+
+// The periodic resync interval.
+// We will re-run the reconciliation logic, even if the network configuration
+// hasn't changed.
+var ResyncPeriod = 3 * time.Minute
+
+// Add creates a new MTU probe controller and adds it to the Manager. The Manager will set fields on the
+// Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager, status *statusmanager.StatusManager) error {
+	return add(mgr, &ReconcileMTUProbe{client: mgr.GetClient(), status: status})
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileMTUProbe) error {
+	c, err := controller.New("mtu-probe-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	// Watch the operator configuration, the results of the probes are then collected periodically
+	return c.Watch(&source.Kind{Type: &operv1.Network{}}, &handler.EnqueueRequestForObject{})
+}
+
+var _ reconcile.Reconciler = &ReconcileMTUProbe{}
+
+// ReconcileMTUProbe collects, when requested on the operator configuration, the results of the
+// network-check-mtu pods pinging the other nodes at the MTU of the overlay, and reports the node pairs
+// whose path MTU is too low. The packets between their pods that exceed the path MTU are dropped,
+// while the smaller ones get through, which is hard to tell apart from other failures.
+type ReconcileMTUProbe struct {
+	client client.Client
+	status *statusmanager.StatusManager
+}
+
+// probeResult is the result of the last probe of a network-check-mtu pod, as recorded on the pod
+type probeResult struct {
+	// MTU is the probed MTU
+	MTU int `json:"mtu"`
+	// BlackHoles are the peer nodes replying to small pings, but not to the pings of the MTU
+	BlackHoles []string `json:"blackHoles"`
+}
+
+// nodeResult is the result of the probe from a node
+type nodeResult struct {
+	Node string
+	probeResult
+}
+
+// Reconcile reports the node pairs whose path MTU is below the MTU of the overlay
+func (r *ReconcileMTUProbe) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	if request.Name != names.OPERATOR_CONFIG {
+		return reconcile.Result{}, nil
+	}
+	operConfig := &operv1.Network{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: names.OPERATOR_CONFIG}, operConfig); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		log.Printf("Unable to retrieve Network.operator.openshift.io object: %v", err)
+		return reconcile.Result{}, err
+	}
+	if operConfig.Annotations[names.MTUProbeAnnotation] != "true" || operConfig.Spec.DisableNetworkDiagnostics {
+		r.status.SetMTUBlackHoles(false, "NotProbed", "The path MTU between the nodes is not probed")
+		return reconcile.Result{}, nil
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.client.List(ctx, pods, client.InNamespace(probeNamespace), client.MatchingLabels{"app": probeApp}); err != nil {
+		return reconcile.Result{}, err
+	}
+	results := []nodeResult{}
+	for _, pod := range pods.Items {
+		result, ok := pod.Annotations[names.MTUProbeResultAnnotation]
+		if !ok || pod.Spec.NodeName == "" {
+			continue
+		}
+		res := nodeResult{Node: pod.Spec.NodeName}
+		if err := json.Unmarshal([]byte(result), &res.probeResult); err != nil {
+			log.Printf("Ignoring invalid %s annotation of pod %s/%s: %v", names.MTUProbeResultAnnotation, pod.Namespace, pod.Name, err)
+			continue
+		}
+		results = append(results, res)
+	}
+	blackHoles, reason, message := report(results)
+	r.status.SetMTUBlackHoles(blackHoles, reason, message)
+	return reconcile.Result{RequeueAfter: ResyncPeriod}, nil
+}
+
+// report returns the MTUBlackHoles condition of the results of the probes
+func report(results []nodeResult) (bool, string, string) {
+	if len(results) == 0 {
+		return false, "NoResults", "No node has probed the path MTU to the other nodes yet"
+	}
+	pairs := []string{}
+	mtus := map[int]bool{}
+	for _, result := range results {
+		mtus[result.MTU] = true
+		for _, peer := range result.BlackHoles {
+			pairs = append(pairs, fmt.Sprintf("%s -> %s", result.Node, peer))
+		}
+	}
+	probed := []string{}
+	for mtu := range mtus {
+		probed = append(probed, fmt.Sprint(mtu))
+	}
+	sort.Strings(probed)
+	if len(pairs) == 0 {
+		return false, "AsExpected", fmt.Sprintf("The path MTU from the %d probed nodes to the other nodes is at least %s",
+			len(results), strings.Join(probed, ", "))
+	}
+	sort.Strings(pairs)
+	listed := pairs
+	if len(listed) > maxListedPairs {
+		listed = listed[:maxListedPairs]
+	}
+	message := fmt.Sprintf("The path MTU between %d node pairs is below the MTU %s of the overlay, the larger packets between their pods are dropped: %s",
+		len(pairs), strings.Join(probed, ", "), strings.Join(listed, ", "))
+	if len(pairs) > len(listed) {
+		message += fmt.Sprintf(" and %d more", len(pairs)-len(listed))
+	}
+	return true, "PathMTUTooLow", message
+}
@@ -0,0 +1,95 @@
+package mtuprobe
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/controller/statusmanager"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcileMTUProbe(t *testing.T) {
+	g := NewGomegaWithT(t)
+	g.Expect(operv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	probePod := func(node, result string) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: probeNamespace,
+				Name:      "network-check-mtu-" + node,
+				Labels:    map[string]string{"app": probeApp},
+			},
+			Spec: corev1.PodSpec{NodeName: node},
+		}
+		if result != "" {
+			pod.Annotations = map[string]string{names.MTUProbeResultAnnotation: result}
+		}
+		return pod
+	}
+	operConfig := &operv1.Network{ObjectMeta: metav1.ObjectMeta{Name: names.OPERATOR_CONFIG}}
+	client := fake.NewClientBuilder().WithObjects(
+		operConfig,
+		probePod("node-a", `{"mtu":1500,"blackHoles":["node-c"]}`),
+		probePod("node-b", `{"mtu":1500,"blackHoles":[]}`),
+		probePod("node-c", `{"mtu":1500,"blackHoles":["node-a","node-b"]}`),
+		probePod("node-d", ""),
+		probePod("node-e", "invalid"),
+	).Build()
+	r := &ReconcileMTUProbe{client: client, status: statusmanager.New(client, nil, "testing")}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: names.OPERATOR_CONFIG}}
+	condition := func() *operv1.OperatorCondition {
+		g.Expect(client.Get(context.TODO(), types.NamespacedName{Name: names.OPERATOR_CONFIG}, operConfig)).To(Succeed())
+		return v1helpers.FindOperatorCondition(operConfig.Status.Conditions, statusmanager.OperatorStatusTypeMTUBlackHoles)
+	}
+
+	// the results are only collected when requested
+	result, err := r.Reconcile(context.TODO(), request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(BeZero())
+	cond := condition()
+	g.Expect(cond).NotTo(BeNil())
+	g.Expect(cond.Status).To(Equal(operv1.ConditionFalse))
+	g.Expect(cond.Reason).To(Equal("NotProbed"))
+
+	operConfig.Annotations = map[string]string{names.MTUProbeAnnotation: "true"}
+	g.Expect(client.Update(context.TODO(), operConfig)).To(Succeed())
+	result, err = r.Reconcile(context.TODO(), request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(ResyncPeriod))
+	cond = condition()
+	g.Expect(cond).NotTo(BeNil())
+	g.Expect(cond.Status).To(Equal(operv1.ConditionTrue))
+	g.Expect(cond.Reason).To(Equal("PathMTUTooLow"))
+	g.Expect(cond.Message).To(Equal("The path MTU between 3 node pairs is below the MTU 1500 of the overlay, " +
+		"the larger packets between their pods are dropped: node-a -> node-c, node-c -> node-a, node-c -> node-b"))
+}
+
+func TestReport(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	blackHoles, reason, _ := report(nil)
+	g.Expect(blackHoles).To(BeFalse())
+	g.Expect(reason).To(Equal("NoResults"))
+
+	blackHoles, reason, message := report([]nodeResult{
+		{Node: "node-a", probeResult: probeResult{MTU: 1500}},
+		{Node: "node-b", probeResult: probeResult{MTU: 1500}},
+	})
+	g.Expect(blackHoles).To(BeFalse())
+	g.Expect(reason).To(Equal("AsExpected"))
+	g.Expect(message).To(Equal("The path MTU from the 2 probed nodes to the other nodes is at least 1500"))
+
+	peers := []string{"node-b", "node-c", "node-d", "node-e", "node-f", "node-g"}
+	blackHoles, _, message = report([]nodeResult{{Node: "node-a", probeResult: probeResult{MTU: 9000, BlackHoles: peers}}})
+	g.Expect(blackHoles).To(BeTrue())
+	g.Expect(message).To(HaveSuffix("node-a -> node-f and 1 more"))
+}
@@ -0,0 +1,298 @@
+package nodesubnets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/controller/statusmanager"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/openshift/cluster-network-operator/pkg/network"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilnet "k8s.io/utils/net"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// exhaustedPercent is the utilization from which the pod subnet of a node is reported as exhausted
+	exhaustedPercent = 90
+	// maxListedNodes is the number of nodes listed in the condition
+	maxListedNodes = 5
+)
+
+var hostSubnetListGVK = schema.GroupVersionKind{Group: "network.openshift.io", Version: "v1", Kind: "HostSubnetList"}
+
+// The periodic resync interval.
+// We will re-run the reconciliation logic, even if the network configuration
+// hasn't changed.
+var ResyncPeriod = 3 * time.Minute
+
+// Add creates a new node subnets controller and adds it to the Manager. The Manager will set fields on the
+// Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager, status *statusmanager.StatusManager) error {
+	// the pods are read from the API server, rather than caching all the pods of the cluster
+	return add(mgr, &ReconcileNodeSubnets{client: mgr.GetClient(), podReader: mgr.GetAPIReader(), status: status})
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileNodeSubnets) error {
+	c, err := controller.New("node-subnets-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	// Watch the operator configuration, the allocations are then checked periodically
+	return c.Watch(&source.Kind{Type: &operv1.Network{}}, &handler.EnqueueRequestForObject{})
+}
+
+var _ reconcile.Reconciler = &ReconcileNodeSubnets{}
+
+// ReconcileNodeSubnets publishes the pod subnets allocated to each node, with the number of pod IPs
+// used out of them, and reports on the operator configuration the nodes which subnets are nearly exhausted.
+type ReconcileNodeSubnets struct {
+	client    client.Client
+	podReader client.Reader
+	status    *statusmanager.StatusManager
+}
+
+// subnetUsage is the utilization of a pod subnet of a node
+type subnetUsage struct {
+	CIDR string `json:"cidr"`
+	// Allocated is the number of pod IPs in use. Capacity is the number of pod IPs the subnet
+	// can hand out, unless the subnet is too large for it to matter.
+	Allocated          int `json:"allocated"`
+	Capacity           int `json:"capacity,omitempty"`
+	UtilizationPercent int `json:"utilizationPercent,omitempty"`
+}
+
+// nodeSubnets are the pod subnets allocated to a node
+type nodeSubnets struct {
+	Node    string
+	Subnets []subnetUsage
+}
+
+// Reconcile publishes the pod subnets of the nodes and their utilization
+func (r *ReconcileNodeSubnets) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	if request.Name != names.OPERATOR_CONFIG {
+		return reconcile.Result{}, nil
+	}
+	if !r.status.IsManaged() {
+		log.Printf("Operator configuration is %s, not reserving the node subnets", r.status.ManagementState())
+		return reconcile.Result{RequeueAfter: ResyncPeriod}, nil
+	}
+	operConfig := &operv1.Network{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: names.OPERATOR_CONFIG}, operConfig); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		log.Printf("Unable to retrieve Network.operator.openshift.io object: %v", err)
+		return reconcile.Result{}, err
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := r.client.List(ctx, nodes); err != nil {
+		return reconcile.Result{}, err
+	}
+	var subnets map[string][]string
+	var reserved func(ipv6 bool) int
+	switch operConfig.Spec.DefaultNetwork.Type {
+	case operv1.NetworkTypeOVNKubernetes:
+		subnets = ovnNodeSubnets(nodes.Items)
+		reserved = ovnReservedIPs
+	case operv1.NetworkTypeOpenShiftSDN:
+		hostSubnets := &uns.UnstructuredList{}
+		hostSubnets.SetGroupVersionKind(hostSubnetListGVK)
+		if err := r.client.List(ctx, hostSubnets); err != nil {
+			if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+				// openshift-sdn has not registered the HostSubnet CRD yet
+				return reconcile.Result{RequeueAfter: ResyncPeriod}, nil
+			}
+			return reconcile.Result{}, err
+		}
+		subnets = sdnNodeSubnets(hostSubnets.Items)
+		reserved = sdnReservedIPs
+	default:
+		return reconcile.Result{}, nil
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.podReader.List(ctx, pods); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	usage := computeNodeSubnets(nodes.Items, subnets, pods.Items, reserved)
+	if err := r.publish(ctx, usage); err != nil {
+		log.Printf("Failed to publish the node subnets: %v", err)
+		return reconcile.Result{}, err
+	}
+	exhausted, reason, message := report(usage)
+	r.status.SetNodeSubnets(exhausted, reason, message)
+	return reconcile.Result{RequeueAfter: ResyncPeriod}, nil
+}
+
+// publish writes the pod subnets of the nodes to the node-subnets ConfigMap, one key per node
+func (r *ReconcileNodeSubnets) publish(ctx context.Context, usage []nodeSubnets) error {
+	data := map[string]string{}
+	for _, node := range usage {
+		subnets, err := json.Marshal(node.Subnets)
+		if err != nil {
+			return err
+		}
+		data[node.Node] = string(subnets)
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err := r.client.Get(ctx, types.NamespacedName{Namespace: names.APPLIED_NAMESPACE, Name: names.NodeSubnetsConfigMap}, configMap)
+	if apierrors.IsNotFound(err) {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: names.APPLIED_NAMESPACE, Name: names.NodeSubnetsConfigMap},
+			Data:       data,
+		}
+		return r.client.Create(ctx, configMap)
+	} else if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(configMap.Data, data) || (len(configMap.Data) == 0 && len(data) == 0) {
+		return nil
+	}
+	configMap.Data = data
+	return r.client.Update(ctx, configMap)
+}
+
+// ovnNodeSubnets returns the pod subnets ovn-kubernetes allocated to each node
+func ovnNodeSubnets(nodes []corev1.Node) map[string][]string {
+	subnets := map[string][]string{}
+	for i := range nodes {
+		nodeSubnets, err := network.OVNNodeSubnets(&nodes[i])
+		if err != nil {
+			log.Printf("Ignoring invalid %s annotation of node %s: %v", names.OVNNodeSubnetsAnnotation, nodes[i].Name, err)
+			continue
+		}
+		if nodeSubnets != nil {
+			subnets[nodes[i].Name] = nodeSubnets
+		}
+	}
+	return subnets
+}
+
+// sdnNodeSubnets returns the pod subnets openshift-sdn allocated to each node
+func sdnNodeSubnets(hostSubnets []uns.Unstructured) map[string][]string {
+	subnets := map[string][]string{}
+	for _, hostSubnet := range hostSubnets {
+		host, _, _ := uns.NestedString(hostSubnet.Object, "host")
+		subnet, _, _ := uns.NestedString(hostSubnet.Object, "subnet")
+		if host != "" && subnet != "" {
+			subnets[host] = append(subnets[host], subnet)
+		}
+	}
+	return subnets
+}
+
+// ovnReservedIPs is the number of addresses of a node subnet ovn-kubernetes does not hand out to pods:
+// the network address, the gateway and the management port, and the broadcast address in IPv4.
+func ovnReservedIPs(ipv6 bool) int {
+	if ipv6 {
+		return 3
+	}
+	return 4
+}
+
+// sdnReservedIPs is the number of addresses of a node subnet openshift-sdn does not hand out to pods:
+// the network address, the gateway and the broadcast address.
+func sdnReservedIPs(bool) int {
+	return 3
+}
+
+// computeNodeSubnets computes the utilization of the pod subnets of every node, from the pod IPs
+// of the running pods of the node. The nodes without a subnet are listed with none.
+func computeNodeSubnets(nodes []corev1.Node, subnets map[string][]string, pods []corev1.Pod, reserved func(ipv6 bool) int) []nodeSubnets {
+	podIPs := map[string][]net.IP{}
+	for _, pod := range pods {
+		if pod.Spec.HostNetwork || pod.Spec.NodeName == "" ||
+			pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, podIP := range pod.Status.PodIPs {
+			if ip := net.ParseIP(podIP.IP); ip != nil {
+				podIPs[pod.Spec.NodeName] = append(podIPs[pod.Spec.NodeName], ip)
+			}
+		}
+	}
+
+	usage := make([]nodeSubnets, 0, len(nodes))
+	for _, node := range nodes {
+		n := nodeSubnets{Node: node.Name, Subnets: []subnetUsage{}}
+		for _, subnet := range subnets[node.Name] {
+			_, cidr, err := net.ParseCIDR(subnet)
+			if err != nil {
+				continue
+			}
+			u := subnetUsage{CIDR: cidr.String()}
+			for _, ip := range podIPs[node.Name] {
+				if cidr.Contains(ip) {
+					u.Allocated++
+				}
+			}
+			ones, bits := cidr.Mask.Size()
+			if hostBits := bits - ones; hostBits < 31 {
+				u.Capacity = 1<<uint(hostBits) - reserved(utilnet.IsIPv6CIDR(cidr))
+			}
+			if u.Capacity > 0 {
+				u.UtilizationPercent = u.Allocated * 100 / u.Capacity
+			}
+			n.Subnets = append(n.Subnets, u)
+		}
+		usage = append(usage, n)
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Node < usage[j].Node })
+	return usage
+}
+
+// report returns whether the pod subnets of some nodes are nearly exhausted, and the reason and
+// message of the condition
+func report(usage []nodeSubnets) (bool, string, string) {
+	exhausted := []string{}
+	withoutSubnet := 0
+	for _, node := range usage {
+		if len(node.Subnets) == 0 {
+			withoutSubnet++
+		}
+		for _, subnet := range node.Subnets {
+			if subnet.Capacity > 0 && subnet.UtilizationPercent >= exhaustedPercent {
+				exhausted = append(exhausted, fmt.Sprintf("%s (%s: %d of %d pod IPs)", node.Node, subnet.CIDR, subnet.Allocated, subnet.Capacity))
+			}
+		}
+	}
+
+	suffix := ""
+	if withoutSubnet > 0 {
+		suffix = fmt.Sprintf("; %d nodes have no pod subnet", withoutSubnet)
+	}
+	if len(exhausted) == 0 {
+		return false, "PodSubnetsAvailable", fmt.Sprintf("The pod subnets of %d nodes are less than %d%% used", len(usage)-withoutSubnet, exhaustedPercent) + suffix
+	}
+	listed := exhausted
+	if len(listed) > maxListedNodes {
+		listed = append(listed[:maxListedNodes:maxListedNodes], fmt.Sprintf("and %d more", len(exhausted)-maxListedNodes))
+	}
+	return true, "PodSubnetsExhausted", fmt.Sprintf("%d pod subnets are at least %d%% used: %s",
+		len(exhausted), exhaustedPercent, strings.Join(listed, ", ")) + suffix
+}
@@ -0,0 +1,100 @@
+package nodesubnets
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestNodeSubnets(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	node := func(name, subnets string) corev1.Node {
+		n := corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{}}}
+		if subnets != "" {
+			n.Annotations[names.OVNNodeSubnetsAnnotation] = subnets
+		}
+		return n
+	}
+	nodes := []corev1.Node{
+		node("single", `{"default":"10.128.0.0/28"}`),
+		node("dual", `{"default":["10.128.1.0/24","fd01:0:0:1::/64"]}`),
+		node("invalid", `not json`),
+		node("new", ""),
+	}
+	g.Expect(ovnNodeSubnets(nodes)).To(Equal(map[string][]string{
+		"single": {"10.128.0.0/28"},
+		"dual":   {"10.128.1.0/24", "fd01:0:0:1::/64"},
+	}))
+
+	hostSubnet := func(host, subnet string) uns.Unstructured {
+		return uns.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "network.openshift.io/v1",
+			"kind":       "HostSubnet",
+			"host":       host,
+			"subnet":     subnet,
+		}}
+	}
+	g.Expect(sdnNodeSubnets([]uns.Unstructured{hostSubnet("a", "10.128.0.0/23"), hostSubnet("b", "")})).To(Equal(map[string][]string{
+		"a": {"10.128.0.0/23"},
+	}))
+
+	pod := func(node string, hostNetwork bool, phase corev1.PodPhase, ips ...string) corev1.Pod {
+		p := corev1.Pod{
+			Spec:   corev1.PodSpec{NodeName: node, HostNetwork: hostNetwork},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+		for _, ip := range ips {
+			p.Status.PodIPs = append(p.Status.PodIPs, corev1.PodIP{IP: ip})
+		}
+		return p
+	}
+	pods := []corev1.Pod{
+		pod("dual", false, corev1.PodRunning, "10.128.1.5", "fd01:0:0:1::5"),
+		pod("dual", true, corev1.PodRunning, "10.0.0.5"),
+		pod("dual", false, corev1.PodSucceeded, "10.128.1.6"),
+		pod("dual", false, corev1.PodPending),
+	}
+	for i := 0; i < 11; i++ {
+		pods = append(pods, pod("single", false, corev1.PodRunning, "10.128.0.10"))
+	}
+
+	usage := computeNodeSubnets(nodes, ovnNodeSubnets(nodes), pods, ovnReservedIPs)
+	g.Expect(usage).To(Equal([]nodeSubnets{
+		{Node: "dual", Subnets: []subnetUsage{
+			{CIDR: "10.128.1.0/24", Allocated: 1, Capacity: 252},
+			{CIDR: "fd01:0:0:1::/64", Allocated: 1},
+		}},
+		{Node: "invalid", Subnets: []subnetUsage{}},
+		{Node: "new", Subnets: []subnetUsage{}},
+		{Node: "single", Subnets: []subnetUsage{
+			{CIDR: "10.128.0.0/28", Allocated: 11, Capacity: 12, UtilizationPercent: 91},
+		}},
+	}))
+	exhausted, reason, message := report(usage)
+	g.Expect(exhausted).To(BeTrue())
+	g.Expect(reason).To(Equal("PodSubnetsExhausted"))
+	g.Expect(message).To(Equal("1 pod subnets are at least 90% used: single (10.128.0.0/28: 11 of 12 pod IPs); 2 nodes have no pod subnet"))
+
+	exhausted, reason, message = report(usage[:1])
+	g.Expect(exhausted).To(BeFalse())
+	g.Expect(reason).To(Equal("PodSubnetsAvailable"))
+	g.Expect(message).To(Equal("The pod subnets of 1 nodes are less than 90% used"))
+
+	// openshift-sdn also hands out the address reserved for the management port
+	usage = computeNodeSubnets(nodes[:1], ovnNodeSubnets(nodes), pods, sdnReservedIPs)
+	g.Expect(usage[0].Subnets[0].Capacity).To(Equal(13))
+
+	// the list of exhausted subnets is truncated
+	usage = nil
+	for i := 0; i < maxListedNodes+2; i++ {
+		usage = append(usage, nodeSubnets{Node: "n", Subnets: []subnetUsage{{CIDR: "10.128.0.0/28", Allocated: 12, Capacity: 12, UtilizationPercent: 100}}})
+	}
+	_, _, message = report(usage)
+	g.Expect(message).To(HavePrefix("7 pod subnets are at least 90% used: "))
+	g.Expect(message).To(HaveSuffix(", and 2 more"))
+}
@@ -11,10 +11,14 @@ import (
 
 	configv1 "github.com/openshift/api/config/v1"
 	operv1 "github.com/openshift/api/operator/v1"
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
 	"github.com/openshift/cluster-network-operator/pkg/apply"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
 	"github.com/openshift/cluster-network-operator/pkg/controller/statusmanager"
 	"github.com/openshift/cluster-network-operator/pkg/names"
 	"github.com/openshift/cluster-network-operator/pkg/network"
+	"github.com/openshift/cluster-network-operator/pkg/platform"
+	"github.com/openshift/cluster-network-operator/pkg/util/logging"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -24,9 +28,11 @@ import (
 	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -36,13 +42,18 @@ import (
 
 // The periodic resync interval.
 // We will re-run the reconciliation logic, even if the network configuration
-// hasn't changed.
-var ResyncPeriod = 3 * time.Minute
+// hasn't changed. The operator configuration itself is resynced at the period
+// set in the network-operator-config ConfigMap.
+var ResyncPeriod = network.DefaultResyncPeriod
 
 // ManifestPaths is the path to the manifest templates
 // bad, but there's no way to pass configuration to the reconciler right now
 var ManifestPath = "./bindata"
 
+// transientApplyTimeout is how long the transient failures to apply the rendered objects are
+// retried before they degrade the operator
+const transientApplyTimeout = 5 * time.Minute
+
 // Add creates a new OperConfig Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager, status *statusmanager.StatusManager) error {
@@ -56,6 +67,7 @@ func newReconciler(mgr manager.Manager, status *statusmanager.StatusManager) *Re
 		scheme:        mgr.GetScheme(),
 		status:        status,
 		mapper:        mgr.GetRESTMapper(),
+		recorder:      mgr.GetEventRecorderFor("cluster-network-operator"),
 		podReconciler: newPodReconciler(status),
 	}
 }
@@ -74,10 +86,117 @@ func add(mgr manager.Manager, r *ReconcileOperConfig) error {
 		return err
 	}
 
-	// watch for changes in the ovs-flows-config map
-	if err = c.Watch(&source.Kind{Type: &corev1.ConfigMap{}},
+	// watch for changes in the ovs-flows-config, ovn-rollout-hooks, kube-proxy-config, network-operator-config,
+	// conntrack-tuning and ovn-gateway-next-hops maps
+	for _, mapFunc := range []handler.MapFunc{
+		reconcileOvsFlowsConfig,
+		reconcileOVNRolloutHooks,
+		reconcileKubeProxyConfig,
+		reconcileOperatorTuning,
+		reconcileConntrackTuning,
+		reconcileOVNGatewayNextHops,
+	} {
+		if err = c.Watch(&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(mapFunc),
+			predicate.ResourceVersionChangedPredicate{},
+		); err != nil {
+			return err
+		}
+	}
+
+	// and in the OVSFlowsConfig superseding the ovs-flows-config map
+	if err = c.Watch(&source.Kind{Type: &netopv1.OVSFlowsConfig{}},
 		handler.EnqueueRequestsFromMapFunc(reconcileOvsFlowsConfig),
-		predicate.ResourceVersionChangedPredicate{},
+		predicate.GenerationChangedPredicate{},
+	); err != nil {
+		return err
+	}
+
+	// and in the EgressSNATPools
+	if err = c.Watch(&source.Kind{Type: &netopv1.EgressSNATPool{}},
+		handler.EnqueueRequestsFromMapFunc(reconcileEgressSNATPool),
+		predicate.GenerationChangedPredicate{},
+	); err != nil {
+		return err
+	}
+
+	// and in the OVNProbeProfiles
+	if err = c.Watch(&source.Kind{Type: &netopv1.OVNProbeProfile{}},
+		handler.EnqueueRequestsFromMapFunc(reconcileOVNProbeProfile),
+		predicate.GenerationChangedPredicate{},
+	); err != nil {
+		return err
+	}
+
+	// and in the IPsecExternalGateways
+	if err = c.Watch(&source.Kind{Type: &netopv1.IPsecExternalGateway{}},
+		handler.EnqueueRequestsFromMapFunc(reconcileIPsecExternalGateway),
+		predicate.GenerationChangedPredicate{},
+	); err != nil {
+		return err
+	}
+
+	// and in the OVNRouteAdvertisements
+	if err = c.Watch(&source.Kind{Type: &netopv1.OVNRouteAdvertisement{}},
+		handler.EnqueueRequestsFromMapFunc(reconcileOVNRouteAdvertisement),
+		predicate.GenerationChangedPredicate{},
+	); err != nil {
+		return err
+	}
+
+	// and in the OVNNodePools
+	if err = c.Watch(&source.Kind{Type: &netopv1.OVNNodePool{}},
+		handler.EnqueueRequestsFromMapFunc(reconcileOVNNodePool),
+		predicate.GenerationChangedPredicate{},
+	); err != nil {
+		return err
+	}
+
+	// and for the nodes joining the cluster, which may start a scale-up the adaptive tuning of OVNKubernetes
+	// relaxes the control plane for. It ends on a periodic reconciliation. The relabeled nodes may move to
+	// another MachineConfigPool, and so to another OVNNodePool.
+	if err = c.Watch(&source.Kind{Type: &corev1.Node{}},
+		handler.EnqueueRequestsFromMapFunc(reconcileNode),
+		predicate.Funcs{
+			CreateFunc: func(event.CreateEvent) bool { return true },
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				old, okOld := e.ObjectOld.(*corev1.Node)
+				new, okNew := e.ObjectNew.(*corev1.Node)
+				return okOld && okNew && network.NodePoolLabelsChanged(old, new)
+			},
+			DeleteFunc:  func(event.DeleteEvent) bool { return false },
+			GenericFunc: func(event.GenericEvent) bool { return false },
+		},
+	); err != nil {
+		return err
+	}
+
+	// watch for changes of the topology of the cluster, which selects the components to render
+	if err = c.Watch(&source.Kind{Type: &configv1.Infrastructure{}},
+		handler.EnqueueRequestsFromMapFunc(reconcileInfrastructure),
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				old, okOld := e.ObjectOld.(*configv1.Infrastructure)
+				new, okNew := e.ObjectNew.(*configv1.Infrastructure)
+				return okOld && okNew && platform.TopologyChanged(old, new)
+			},
+		},
+	); err != nil {
+		return err
+	}
+
+	// and of the feature gates, which select the gated features to render
+	if err = c.Watch(&source.Kind{Type: &configv1.FeatureGate{}},
+		handler.EnqueueRequestsFromMapFunc(reconcileFeatureGate),
+		predicate.GenerationChangedPredicate{},
+	); err != nil {
+		return err
+	}
+
+	// and of the TLS security profile of the API server, which the TLS endpoints of the components follow
+	if err = c.Watch(&source.Kind{Type: &configv1.APIServer{}},
+		handler.EnqueueRequestsFromMapFunc(reconcileAPIServer),
+		predicate.GenerationChangedPredicate{},
 	); err != nil {
 		return err
 	}
@@ -109,7 +228,20 @@ type ReconcileOperConfig struct {
 	scheme        *runtime.Scheme
 	status        *statusmanager.StatusManager
 	mapper        meta.RESTMapper
+	recorder      record.EventRecorder
 	podReconciler *ReconcilePods
+
+	// lastApplied are the objects applied by the last successful reconciliation, as returned
+	// by the apiserver, against which the drift of the live objects is detected
+	lastApplied []*uns.Unstructured
+
+	// transientApplyFailureSince is when the rendered objects started failing to apply with
+	// transient errors only, or zero if the last attempt succeeded or failed permanently
+	transientApplyFailureSince time.Time
+
+	// lastEvents are the Events of the decisions of the last reconciliation, which are not
+	// recorded again while the decisions stand
+	lastEvents map[bootstrap.Event]bool
 }
 
 // Reconcile updates the state of the cluster to match that which is desired
@@ -142,9 +274,14 @@ func (r *ReconcileOperConfig) Reconcile(ctx context.Context, request reconcile.R
 		return reconcile.Result{}, err
 	}
 
-	if operConfig.Spec.ManagementState == operv1.Unmanaged {
+	r.status.SetManagementState(operConfig.Spec.ManagementState)
+	// Report the objects changed by something else since they were applied, before reverting them
+	r.reportDrift(ctx)
+	if !r.status.IsManaged() {
 		log.Printf("Operator configuration state is %s - skipping operconfig reconciliation", operConfig.Spec.ManagementState)
-		return reconcile.Result{}, nil
+		// Keep reporting the status of the operands, which may be edited manually meanwhile
+		r.status.SetFromPods()
+		return reconcile.Result{RequeueAfter: ResyncPeriod}, nil
 	}
 
 	// Merge in the cluster configuration, in case the administrator has updated some "downstream" fields
@@ -174,6 +311,11 @@ func (r *ReconcileOperConfig) Reconcile(ctx context.Context, request reconcile.R
 		// FIXME: operator status?
 		return reconcile.Result{}, err
 	}
+	forcedChange, err := GetAppliedForcedChange(ctx, r.client, operConfig.ObjectMeta.Name)
+	if err != nil {
+		log.Printf("Failed to retrieve the last forced change: %v", err)
+		return reconcile.Result{}, err
+	}
 	// up-convert Prev by filling defaults
 	if prev != nil {
 		network.FillDefaults(prev, prev)
@@ -184,18 +326,37 @@ func (r *ReconcileOperConfig) Reconcile(ctx context.Context, request reconcile.R
 
 	// Compare against previous applied configuration to see if this change
 	// is safe.
+	forcing := false
 	if prev != nil {
 		// We may need to fill defaults here -- sort of as a poor-man's
 		// upconversion scheme -- if we add additional fields to the config.
 		err = network.IsChangeSafe(prev, &operConfig.Spec)
 		if err != nil {
-			log.Printf("Not applying unsafe change: %v", err)
-			r.status.SetDegraded(statusmanager.OperatorConfig, "InvalidOperatorConfig",
-				fmt.Sprintf("Not applying unsafe configuration change: %v. Use 'oc edit network.operator.openshift.io cluster' to undo the change.", err))
-			return reconcile.Result{}, err
+			// a new request of the ForceUnsafeChangeAnnotation forces the changes that can be rolled out
+			// by restarting the pods
+			request := network.ForceUnsafeChangeRequest(operConfig)
+			if request == "" || request == forcedChange || !network.IsChangeForcible(prev, &operConfig.Spec) {
+				log.Printf("Not applying unsafe change: %v", err)
+				r.status.SetDegraded(statusmanager.OperatorConfig, "InvalidOperatorConfig",
+					fmt.Sprintf("Not applying unsafe configuration change: %v. Use 'oc edit network.operator.openshift.io cluster' to undo the change.", err))
+				return reconcile.Result{}, err
+			}
+			log.Printf("Forcing unsafe change, as requested by %s=%s: %v", names.ForceUnsafeChangeAnnotation, request, err)
+			r.recorder.Event(operConfig, corev1.EventTypeWarning, "ForcedUnsafeChange",
+				fmt.Sprintf("Forcing unsafe configuration change, all of the ovn-kubernetes pods are restarted: %v", err))
+			forcedChange = request
+			forcing = true
 		}
 	}
 
+	// Check that the cluster can run a dual-stack service network before starting the conversion
+	if err := network.CheckDualStackConversion(ctx, r.client, operConfig, prev); err != nil {
+		log.Printf("Not converting to dual-stack: %v", err)
+		r.status.SetDegraded(statusmanager.OperatorConfig, "DualStackPreconditionFailed",
+			fmt.Sprintf("Not applying the dual-stack configuration: %v. Fix the preconditions, or undo the change with 'oc edit network.config.openshift.io cluster'.", err))
+		return reconcile.Result{}, err
+	}
+
 	newOperConfig := operConfig.DeepCopy()
 
 	// Bootstrap any resources
@@ -206,6 +367,13 @@ func (r *ReconcileOperConfig) Reconcile(ctx context.Context, request reconcile.R
 			fmt.Sprintf("Internal error while reconciling platform networking resources: %v", err))
 		return reconcile.Result{}, err
 	}
+	if bootstrapResult.OVN.OVNKubernetesConfig != nil {
+		bootstrapResult.OVN.OVNKubernetesConfig.ForceUnsafeChangeRequest = forcedChange
+	}
+	// the log format and the verbosity of the modules follow the network-operator-config ConfigMap
+	if err := logging.Configure(bootstrapResult.Tuning.LogFormat, bootstrapResult.Tuning.LogLevels); err != nil {
+		log.Printf("Failed to configure the logging: %v", err)
+	}
 
 	if !reflect.DeepEqual(operConfig, newOperConfig) {
 		if err := r.UpdateOperConfig(newOperConfig); err != nil {
@@ -216,6 +384,21 @@ func (r *ReconcileOperConfig) Reconcile(ctx context.Context, request reconcile.R
 		}
 	}
 
+	// Refuse the cryptography that is not FIPS-approved on the clusters installed in FIPS mode
+	if err := network.ValidateFIPS(bootstrapResult); err != nil {
+		log.Printf("Failed to validate the cryptography of Network.operator.openshift.io: %v", err)
+		r.status.SetDegraded(statusmanager.OperatorConfig, "InvalidOperatorConfig",
+			fmt.Sprintf("The operator configuration is invalid (%v). Use 'oc edit network.operator.openshift.io cluster' to fix.", err))
+		return reconcile.Result{}, err
+	}
+
+	// Report the settings that are valid but likely not intended, without blocking the reconciliation
+	warnings := network.ValidationWarnings(&operConfig.Spec, bootstrapResult)
+	for _, warning := range warnings {
+		log.Printf("WARNING: Network.operator.openshift.io.Spec: %s", warning)
+	}
+	r.status.SetConfigurationWarnings(warnings)
+
 	// Generate the objects
 	objs, err := network.Render(&operConfig.Spec, bootstrapResult, ManifestPath)
 	if err != nil {
@@ -225,8 +408,11 @@ func (r *ReconcileOperConfig) Reconcile(ctx context.Context, request reconcile.R
 		return reconcile.Result{}, err
 	}
 
+	// Report the decisions taken while bootstrapping and rendering, so they are part of the audit trail
+	r.recordEvents(operConfig, bootstrapResult.Events)
+
 	// The first object we create should be the record of our applied configuration. The last object we create is config.openshift.io/v1/Network.Status
-	app, err := AppliedConfiguration(operConfig)
+	app, err := AppliedConfiguration(operConfig, forcedChange)
 	if err != nil {
 		log.Printf("Failed to render applied: %v", err)
 		r.status.SetDegraded(statusmanager.OperatorConfig, "RenderError",
@@ -297,12 +483,21 @@ func (r *ReconcileOperConfig) Reconcile(ctx context.Context, request reconcile.R
 		Name:     "openshift-cloud-network-config-controller",
 	})
 
+	// The DaemonSet of a third-party default network is watched for its readiness, but it is
+	// not a related object: those that are not rendered get deleted.
+	externalDaemonSets := []types.NamespacedName{}
+	if bootstrapResult.ThirdPartyCNI.DaemonSet != nil {
+		externalDaemonSets = append(externalDaemonSets, *bootstrapResult.ThirdPartyCNI.DaemonSet)
+	}
+
 	r.status.SetDaemonSets(daemonSets)
+	r.status.SetExternalDaemonSets(externalDaemonSets)
 	r.status.SetDeployments(deployments)
 	r.status.SetRelatedObjects(relatedObjects)
 
 	allResources := []types.NamespacedName{}
 	allResources = append(allResources, daemonSets...)
+	allResources = append(allResources, externalDaemonSets...)
 	allResources = append(allResources, deployments...)
 	r.podReconciler.SetResources(allResources)
 
@@ -316,24 +511,41 @@ func (r *ReconcileOperConfig) Reconcile(ctx context.Context, request reconcile.R
 				fmt.Sprintf("Internal error while updating operator configuration: %v", err))
 			return reconcile.Result{}, err
 		}
+	}
 
-		// Open question: should an error here indicate we will never retry?
-		if err := apply.ApplyObject(ctx, r.client, obj); err != nil {
-			err = errors.Wrapf(err, "could not apply (%s) %s/%s", obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
-			log.Println(err)
-
-			// Ignore errors if we've asked to do so.
-			anno := obj.GetAnnotations()
-			if anno != nil {
-				if _, ok := anno[names.IgnoreObjectErrorAnnotation]; ok {
-					log.Println("Object has ignore-errors annotation set, continuing")
-					continue
-				}
-			}
+	// The record of the applied configuration is applied on its own, before the operands, so that no
+	// operand is updated without it. The other objects are then applied tier by tier.
+	err = apply.ApplyObject(ctx, r.client, app)
+	if err != nil {
+		err = errors.Wrapf(err, "could not apply (%s) %s/%s", app.GroupVersionKind(), app.GetNamespace(), app.GetName())
+	} else {
+		err = apply.ApplyObjects(ctx, r.client, objs[1:])
+	}
+	// Transient failures are requeued without degrading the operator, unless they persist
+	if err != nil {
+		if !apply.IsTransientError(err) {
+			r.transientApplyFailureSince = time.Time{}
 			r.status.SetDegraded(statusmanager.OperatorConfig, "ApplyOperatorConfig",
 				fmt.Sprintf("Error while updating operator configuration: %v", err))
 			return reconcile.Result{}, err
 		}
+		if r.transientApplyFailureSince.IsZero() {
+			r.transientApplyFailureSince = time.Now()
+		}
+		if time.Since(r.transientApplyFailureSince) < transientApplyTimeout {
+			log.Printf("Transient error while updating operator configuration, will retry: %v", err)
+			return reconcile.Result{}, err
+		}
+		r.status.SetDegraded(statusmanager.OperatorConfig, "ApplyOperatorConfigTransient",
+			fmt.Sprintf("Transient error while updating operator configuration for %v: %v",
+				time.Since(r.transientApplyFailureSince).Round(time.Second), err))
+		return reconcile.Result{}, err
+	}
+	r.transientApplyFailureSince = time.Time{}
+
+	r.lastApplied = objs
+	if forcing {
+		r.status.SetForcedChange(forcedChange)
 	}
 
 	// Run a pod status check just to clear any initial inconsitencies at startup of the CNO
@@ -361,22 +573,188 @@ func (r *ReconcileOperConfig) Reconcile(ctx context.Context, request reconcile.R
 
 	r.status.SetNotDegraded(statusmanager.OperatorConfig)
 
-	// All was successful. Request that this be re-triggered after ResyncPeriod,
+	// All was successful. Request that this be re-triggered after the resync period,
 	// so we can reconcile state again.
-	return reconcile.Result{RequeueAfter: ResyncPeriod}, nil
+	return reconcile.Result{RequeueAfter: bootstrapResult.Tuning.ResyncPeriod}, nil
+}
+
+// reportDrift compares the live objects with the objects applied by the last reconciliation, and
+// reports those that were changed or removed meanwhile in the Drifted condition
+func (r *ReconcileOperConfig) reportDrift(ctx context.Context) {
+	if r.lastApplied == nil {
+		return
+	}
+	drifts, err := apply.DetectDrift(ctx, r.client, r.lastApplied)
+	if err != nil {
+		log.Printf("Failed to detect the drift of the applied objects: %v", err)
+		return
+	}
+	drifted := []string{}
+	for _, drift := range drifts {
+		log.Printf("Applied object drifted: %s", drift)
+		drifted = append(drifted, drift.String())
+	}
+	r.status.SetDrifted(drifted)
 }
 
 // reconcileOvsFlowsConfig filters non-ovs-flows-config events and forwards a request to the
 // openshift-network-operator/cluster operator
 func reconcileOvsFlowsConfig(object client.Object) []reconcile.Request {
-	n := object.GetName()
-	ns := object.GetNamespace()
-	if n != network.OVSFlowsConfigMapName || ns != network.OVSFlowsConfigNamespace {
+	return reconcileConfigMap(object, network.OVSFlowsConfigMapName, network.OVSFlowsConfigNamespace)
+}
+
+// reconcileOVNRolloutHooks filters non-ovn-rollout-hooks events and forwards a request to the
+// openshift-network-operator/cluster operator
+func reconcileOVNRolloutHooks(object client.Object) []reconcile.Request {
+	return reconcileConfigMap(object, network.OVNRolloutHooksConfigMapName, network.OVNRolloutHooksConfigMapNamespace)
+}
+
+// reconcileKubeProxyConfig filters non-kube-proxy-config events and forwards a request to the
+// openshift-network-operator/cluster operator
+func reconcileKubeProxyConfig(object client.Object) []reconcile.Request {
+	return reconcileConfigMap(object, network.KubeProxyConfigMapName, network.KubeProxyConfigMapNamespace)
+}
+
+// reconcileOperatorTuning filters non-network-operator-config events and forwards a request to the
+// openshift-network-operator/cluster operator
+func reconcileOperatorTuning(object client.Object) []reconcile.Request {
+	return reconcileConfigMap(object, network.OperatorTuningConfigMapName, network.OperatorTuningConfigMapNamespace)
+}
+
+// reconcileConntrackTuning filters non-conntrack-tuning events and forwards a request to the
+// openshift-network-operator/cluster operator
+func reconcileConntrackTuning(object client.Object) []reconcile.Request {
+	return reconcileConfigMap(object, network.ConntrackTuningConfigMapName, network.ConntrackTuningConfigMapNamespace)
+}
+
+// reconcileOVNGatewayNextHops filters non-ovn-gateway-next-hops events and forwards a request to the
+// openshift-network-operator/cluster operator
+func reconcileOVNGatewayNextHops(object client.Object) []reconcile.Request {
+	return reconcileConfigMap(object, network.OVNGatewayNextHopsConfigMapName, network.OVNGatewayNextHopsConfigMapNamespace)
+}
+
+// reconcileConfigMap forwards a change of the given object, if it is named name in namespace, to the
+// openshift-network-operator/cluster operator
+func reconcileConfigMap(object client.Object, name, namespace string) []reconcile.Request {
+	if object.GetName() != name || object.GetNamespace() != namespace {
+		return nil
+	}
+	log.Println(name + ": enqueuing operator reconcile request from configmap")
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{
+		Name:      names.OPERATOR_CONFIG,
+		Namespace: names.APPLIED_NAMESPACE,
+	}}}
+}
+
+// reconcileEgressSNATPool forwards a change of an EgressSNATPool to the
+// openshift-network-operator/cluster operator
+func reconcileEgressSNATPool(object client.Object) []reconcile.Request {
+	log.Println(object.GetName() + ": enqueuing operator reconcile request from EgressSNATPool")
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{
+		Name:      names.OPERATOR_CONFIG,
+		Namespace: names.APPLIED_NAMESPACE,
+	}}}
+}
+
+// reconcileOVNProbeProfile forwards a change of an OVNProbeProfile to the
+// openshift-network-operator/cluster operator
+func reconcileOVNProbeProfile(object client.Object) []reconcile.Request {
+	log.Println(object.GetName() + ": enqueuing operator reconcile request from OVNProbeProfile")
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{
+		Name:      names.OPERATOR_CONFIG,
+		Namespace: names.APPLIED_NAMESPACE,
+	}}}
+}
+
+// reconcileIPsecExternalGateway forwards a change of an IPsecExternalGateway to the
+// openshift-network-operator/cluster operator
+func reconcileIPsecExternalGateway(object client.Object) []reconcile.Request {
+	log.Println(object.GetName() + ": enqueuing operator reconcile request from IPsecExternalGateway")
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{
+		Name:      names.OPERATOR_CONFIG,
+		Namespace: names.APPLIED_NAMESPACE,
+	}}}
+}
+
+// reconcileNode forwards a node joining the cluster, or relabeled, to the
+// openshift-network-operator/cluster operator. It is not logged, as all the
+// nodes are listed when the operator starts.
+func reconcileNode(object client.Object) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{
+		Name:      names.OPERATOR_CONFIG,
+		Namespace: names.APPLIED_NAMESPACE,
+	}}}
+}
+
+// reconcileOVNRouteAdvertisement forwards a change of an OVNRouteAdvertisement to the
+// openshift-network-operator/cluster operator
+func reconcileOVNRouteAdvertisement(object client.Object) []reconcile.Request {
+	log.Println(object.GetName() + ": enqueuing operator reconcile request from OVNRouteAdvertisement")
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{
+		Name:      names.OPERATOR_CONFIG,
+		Namespace: names.APPLIED_NAMESPACE,
+	}}}
+}
+
+// reconcileOVNNodePool forwards a change of an OVNNodePool to the
+// openshift-network-operator/cluster operator
+func reconcileOVNNodePool(object client.Object) []reconcile.Request {
+	log.Println(object.GetName() + ": enqueuing operator reconcile request from OVNNodePool")
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{
+		Name:      names.OPERATOR_CONFIG,
+		Namespace: names.APPLIED_NAMESPACE,
+	}}}
+}
+
+// reconcileInfrastructure forwards a change of the topology of the cluster to the
+// openshift-network-operator/cluster operator
+func reconcileInfrastructure(object client.Object) []reconcile.Request {
+	if object.GetName() != "cluster" {
 		return nil
 	}
-	log.Println(network.OVSFlowsConfigMapName + ": enqueuing operator reconcile request from configmap")
+	log.Println("infrastructure topology changed: enqueuing operator reconcile request")
 	return []reconcile.Request{{NamespacedName: types.NamespacedName{
 		Name:      names.OPERATOR_CONFIG,
 		Namespace: names.APPLIED_NAMESPACE,
 	}}}
 }
+
+// reconcileFeatureGate forwards a change of the feature gates of the cluster to the
+// openshift-network-operator/cluster operator
+func reconcileFeatureGate(object client.Object) []reconcile.Request {
+	if object.GetName() != "cluster" {
+		return nil
+	}
+	log.Println("feature gates changed: enqueuing operator reconcile request")
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{
+		Name:      names.OPERATOR_CONFIG,
+		Namespace: names.APPLIED_NAMESPACE,
+	}}}
+}
+
+// reconcileAPIServer forwards a change of the TLS security profile of the cluster to the
+// openshift-network-operator/cluster operator
+func reconcileAPIServer(object client.Object) []reconcile.Request {
+	if object.GetName() != "cluster" {
+		return nil
+	}
+	log.Println("apiserver configuration changed: enqueuing operator reconcile request")
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{
+		Name:      names.OPERATOR_CONFIG,
+		Namespace: names.APPLIED_NAMESPACE,
+	}}}
+}
+
+// recordEvents records the Events of the decisions taken by a reconciliation which were not taken by the
+// previous one, so that a decision is recorded once when it is taken, and again only once it was
+// reverted, rather than on every reconciliation.
+func (r *ReconcileOperConfig) recordEvents(operConfig *operv1.Network, events []bootstrap.Event) {
+	current := make(map[bootstrap.Event]bool, len(events))
+	for _, event := range events {
+		if !r.lastEvents[event] && !current[event] {
+			r.recorder.Event(operConfig, event.Type, event.Reason, event.Message)
+		}
+		current[event] = true
+	}
+	r.lastEvents = current
+}
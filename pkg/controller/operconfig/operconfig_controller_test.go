@@ -0,0 +1,42 @@
+package operconfig
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestRecordEvents(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileOperConfig{recorder: recorder}
+	operConfig := &operv1.Network{}
+	deferred := bootstrap.Event{Type: corev1.EventTypeNormal, Reason: "IPFamilyRolloutDeferred", Message: "deferred"}
+	prePuller := bootstrap.Event{Type: corev1.EventTypeNormal, Reason: "PrePullerStarted", Message: "started"}
+	recorded := func() []string {
+		events := []string{}
+		for len(recorder.Events) > 0 {
+			events = append(events, <-recorder.Events)
+		}
+		return events
+	}
+
+	r.recordEvents(operConfig, []bootstrap.Event{deferred, deferred})
+	g.Expect(recorded()).To(Equal([]string{"Normal IPFamilyRolloutDeferred deferred"}))
+
+	// the decisions that stand are not recorded again
+	r.recordEvents(operConfig, []bootstrap.Event{deferred, prePuller})
+	g.Expect(recorded()).To(Equal([]string{"Normal PrePullerStarted started"}))
+	r.recordEvents(operConfig, []bootstrap.Event{deferred, prePuller})
+	g.Expect(recorded()).To(BeEmpty())
+
+	// unless they were reverted meanwhile
+	r.recordEvents(operConfig, nil)
+	r.recordEvents(operConfig, []bootstrap.Event{deferred})
+	g.Expect(recorded()).To(Equal([]string{"Normal IPFamilyRolloutDeferred deferred"}))
+}
@@ -35,9 +35,21 @@ func GetAppliedConfiguration(ctx context.Context, client k8sclient.Client, name
 	return spec, nil
 }
 
+// GetAppliedForcedChange retrieves the last request that forced an unsafe change, if any
+func GetAppliedForcedChange(ctx context.Context, client k8sclient.Client, name string) (string, error) {
+	cm := &corev1.ConfigMap{}
+	err := client.Get(ctx, types.NamespacedName{Namespace: names.APPLIED_NAMESPACE, Name: names.APPLIED_PREFIX + name}, cm)
+	if err != nil && apierrors.IsNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return cm.Data["forcedChange"], nil
+}
+
 // AppliedConfiguration renders the ConfigMap in which we store the configuration
-// we've applied.
-func AppliedConfiguration(applied *operv1.Network) (*uns.Unstructured, error) {
+// we've applied, along with the last request that forced an unsafe change, if any.
+func AppliedConfiguration(applied *operv1.Network, forcedChange string) (*uns.Unstructured, error) {
 	app, err := json.Marshal(applied.Spec)
 	if err != nil {
 		return nil, err
@@ -55,6 +67,9 @@ func AppliedConfiguration(applied *operv1.Network) (*uns.Unstructured, error) {
 			"applied": string(app),
 		},
 	}
+	if forcedChange != "" {
+		cm.Data["forcedChange"] = forcedChange
+	}
 
 	// transmute to unstructured
 	return k8sutil.ToUnstructured(cm)
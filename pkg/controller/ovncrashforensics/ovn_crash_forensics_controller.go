@@ -0,0 +1,138 @@
+package ovncrashforensics
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/openshift/cluster-network-operator/pkg/controller/statusmanager"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// the namespace the crash reports are published in by the ovnkube-node pods
+const crashForensicsNamespace = "openshift-ovn-kubernetes"
+
+// maxListedNodes is the number of nodes listed in the condition
+const maxListedNodes = 5
+
+// Add creates a new crash forensics controller and adds it to the Manager. The Manager will set fields on the
+// Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager, status *statusmanager.StatusManager) error {
+	return add(mgr, &ReconcileOVNCrashForensics{client: mgr.GetClient(), status: status})
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileOVNCrashForensics) error {
+	c, err := controller.New("ovn-crash-forensics-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	// Watch the ConfigMaps holding the crash reports of the nodes, all of them are summarized at once
+	return c.Watch(&source.Kind{Type: &corev1.ConfigMap{}},
+		handler.EnqueueRequestsFromMapFunc(func(client.Object) []reconcile.Request {
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: names.OPERATOR_CONFIG}}}
+		}),
+		predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			_, ok := obj.GetLabels()[names.OVNCrashForensicsLabel]
+			return ok && obj.GetNamespace() == crashForensicsNamespace
+		}),
+	)
+}
+
+var _ reconcile.Reconciler = &ReconcileOVNCrashForensics{}
+
+// ReconcileOVNCrashForensics reports on the operator configuration the nodes where crash reports
+// of ovn-kubernetes, OVS or OVN were collected.
+type ReconcileOVNCrashForensics struct {
+	client client.Client
+	status *statusmanager.StatusManager
+}
+
+// nodeCrashes are the crash reports collected on a node
+type nodeCrashes struct {
+	Node    string
+	Reports int
+	// Latest is the name of the most recent report, and Crashed what crashed then
+	Latest  string
+	Crashed []string
+}
+
+// Reconcile summarizes the crash reports of the nodes
+func (r *ReconcileOVNCrashForensics) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	configMaps := &corev1.ConfigMapList{}
+	if err := r.client.List(ctx, configMaps, client.InNamespace(crashForensicsNamespace), client.HasLabels{names.OVNCrashForensicsLabel}); err != nil {
+		log.Printf("Failed to list the crash reports: %v", err)
+		return reconcile.Result{}, err
+	}
+	collected, reason, message := report(summarizeCrashes(configMaps.Items))
+	r.status.SetCrashForensics(collected, reason, message)
+	return reconcile.Result{}, nil
+}
+
+// summarizeCrashes returns the crash reports of each node, sorted by node
+func summarizeCrashes(configMaps []corev1.ConfigMap) []nodeCrashes {
+	crashes := []nodeCrashes{}
+	for _, cm := range configMaps {
+		if len(cm.Data) == 0 {
+			continue
+		}
+		node := cm.Annotations[names.NodeAnnotation]
+		if node == "" {
+			node = strings.TrimPrefix(cm.Name, "ovn-crash-forensics-")
+		}
+		reports := make([]string, 0, len(cm.Data))
+		for key := range cm.Data {
+			reports = append(reports, key)
+		}
+		// the reports are named after the time they were collected at
+		sort.Strings(reports)
+		latest := reports[len(reports)-1]
+		// the first line of a report lists what crashed
+		firstLine := strings.SplitN(cm.Data[latest], "\n", 2)[0]
+		crashes = append(crashes, nodeCrashes{
+			Node:    node,
+			Reports: len(reports),
+			Latest:  strings.TrimSuffix(latest, ".txt"),
+			Crashed: strings.Fields(strings.TrimPrefix(firstLine, "crashed:")),
+		})
+	}
+	sort.Slice(crashes, func(i, j int) bool { return crashes[i].Node < crashes[j].Node })
+	return crashes
+}
+
+// report returns the status, reason and message of the condition reporting the crashes
+func report(crashes []nodeCrashes) (bool, string, string) {
+	if len(crashes) == 0 {
+		return false, "NoCrashReports", "No crash report was collected"
+	}
+	listed := crashes
+	if len(listed) > maxListedNodes {
+		listed = listed[:maxListedNodes]
+	}
+	descriptions := []string{}
+	for _, c := range listed {
+		crashed := "unknown"
+		if len(c.Crashed) > 0 {
+			crashed = strings.Join(c.Crashed, ", ")
+		}
+		descriptions = append(descriptions, fmt.Sprintf("%s (%d reports, latest %s: %s)", c.Node, c.Reports, c.Latest, crashed))
+	}
+	message := fmt.Sprintf("Crash reports were collected on %d nodes: %s", len(crashes), strings.Join(descriptions, "; "))
+	if len(crashes) > len(listed) {
+		message += fmt.Sprintf(" and %d more", len(crashes)-len(listed))
+	}
+	message += fmt.Sprintf(". They are in the ConfigMaps labeled %s in the %s namespace", names.OVNCrashForensicsLabel, crashForensicsNamespace)
+	return true, "CrashReportsCollected", message
+}
@@ -0,0 +1,62 @@
+package ovncrashforensics
+
+import (
+	"testing"
+
+	"github.com/openshift/cluster-network-operator/pkg/names"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSummarizeCrashes(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	configMap := func(node string, data map[string]string) corev1.ConfigMap {
+		return corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "ovn-crash-forensics-" + node,
+				Namespace:   crashForensicsNamespace,
+				Labels:      map[string]string{names.OVNCrashForensicsLabel: ""},
+				Annotations: map[string]string{names.NodeAnnotation: node},
+			},
+			Data: data,
+		}
+	}
+
+	collected, reason, message := report(summarizeCrashes(nil))
+	g.Expect(collected).To(BeFalse())
+	g.Expect(reason).To(Equal("NoCrashReports"))
+	g.Expect(message).To(Equal("No crash report was collected"))
+
+	crashes := summarizeCrashes([]corev1.ConfigMap{
+		configMap("worker-1", map[string]string{
+			"20261017T101500Z.txt": "crashed: ovnkube-node\n=== log ===\n",
+			"20261017T103000Z.txt": "crashed:  ovs-vswitchd\n=== log ===\n",
+		}),
+		configMap("worker-0", map[string]string{
+			"20261016T080000Z.txt": "crashed: ovn-controller ovnkube-node\n",
+		}),
+		configMap("worker-2", map[string]string{}),
+	})
+	g.Expect(crashes).To(Equal([]nodeCrashes{
+		{Node: "worker-0", Reports: 1, Latest: "20261016T080000Z", Crashed: []string{"ovn-controller", "ovnkube-node"}},
+		{Node: "worker-1", Reports: 2, Latest: "20261017T103000Z", Crashed: []string{"ovs-vswitchd"}},
+	}))
+	collected, reason, message = report(crashes)
+	g.Expect(collected).To(BeTrue())
+	g.Expect(reason).To(Equal("CrashReportsCollected"))
+	g.Expect(message).To(Equal("Crash reports were collected on 2 nodes: " +
+		"worker-0 (1 reports, latest 20261016T080000Z: ovn-controller, ovnkube-node); " +
+		"worker-1 (2 reports, latest 20261017T103000Z: ovs-vswitchd). " +
+		"They are in the ConfigMaps labeled network.operator.openshift.io/ovn-crash-forensics in the openshift-ovn-kubernetes namespace"))
+
+	// only the first nodes are listed
+	many := []nodeCrashes{}
+	for _, node := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		many = append(many, nodeCrashes{Node: node, Reports: 1, Latest: "20261017T103000Z"})
+	}
+	_, _, message = report(many)
+	g.Expect(message).To(ContainSubstring("e (1 reports, latest 20261017T103000Z: unknown) and 2 more."))
+}
@@ -0,0 +1,435 @@
+package ovnlboptions
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/controller/statusmanager"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	k8sutil "github.com/openshift/cluster-network-operator/pkg/util/k8s"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	ovnNamespace = "openshift-ovn-kubernetes"
+	// the nbdb container of ovnkube-master serves the NB database on a local socket
+	nbdbContainer = "nbdb"
+	// maxAffinityTimeout is the longest session affinity of the OVN load balancers, in seconds
+	maxAffinityTimeout = 86400
+)
+
+// The periodic resync interval. ovnkube-master sets the options of the load balancers of a service again
+// when the service or its endpoints change, so the requested options are set again periodically, for all
+// the services at once.
+var ResyncPeriod = time.Minute
+
+// lbOptionsRequest is the single request of the controller: the options of the load balancers of all the
+// services are set in one transaction, rather than one per service
+var lbOptionsRequest = reconcile.Request{NamespacedName: types.NamespacedName{Name: "ovn-lb-options"}}
+
+// lbOption translates an annotation of the services into an option of their OVN load balancers
+type lbOption struct {
+	annotation string
+	option     string
+	// parse validates the value of the annotation, and returns the value of the option
+	parse func(string) (string, error)
+}
+
+// lbOptions are the supported annotations, by option. The options of newer OVN releases are supported by
+// adding them here, rather than waiting for ovn-kubernetes to set them.
+var lbOptions = []lbOption{
+	{annotation: names.OVNLBAffinityTimeoutServiceAnnotation, option: "affinity_timeout", parse: parseAffinityTimeout},
+	{annotation: names.OVNLBHairpinSNATIPServiceAnnotation, option: "hairpin_snat_ip", parse: parseHairpinSNATIP},
+	{annotation: names.OVNLBSkipSNATServiceAnnotation, option: "skip_snat", parse: parseBool},
+}
+
+// Add creates a new ovn-lb-options controller and adds it to the Manager. The Manager will set fields on the
+// Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager, status *statusmanager.StatusManager) error {
+	// We need a clientset in order to exec into pods, the controller-runtime client does not
+	// support the exec subresource
+	clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+	r := &ReconcileOVNLBOptions{
+		client:   mgr.GetClient(),
+		status:   status,
+		recorder: mgr.GetEventRecorderFor("cluster-network-operator"),
+	}
+	r.exec = func(ctx context.Context, pod *corev1.Pod, container string, command []string) (string, error) {
+		return k8sutil.ExecInPod(mgr.GetConfig(), clientset, pod, container, command)
+	}
+	return add(mgr, r)
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileOVNLBOptions) error {
+	c, err := controller.New("ovn-lb-options-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	enqueue := handler.EnqueueRequestsFromMapFunc(func(client.Object) []reconcile.Request {
+		return []reconcile.Request{lbOptionsRequest}
+	})
+	// Watch the operator configuration, which lists the namespaces allowed to set the options
+	if err := c.Watch(&source.Kind{Type: &operv1.Network{}}, enqueue,
+		predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			return obj.GetName() == names.OPERATOR_CONFIG
+		})); err != nil {
+		return err
+	}
+	// Watch the services whose load balancer options are or were set
+	return c.Watch(&source.Kind{Type: &corev1.Service{}}, enqueue,
+		predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			annotations := obj.GetAnnotations()
+			if _, ok := annotations[names.OVNLBOptionsAppliedServiceAnnotation]; ok {
+				return true
+			}
+			for _, o := range lbOptions {
+				if _, ok := annotations[o.annotation]; ok {
+					return true
+				}
+			}
+			return false
+		}))
+}
+
+var _ reconcile.Reconciler = &ReconcileOVNLBOptions{}
+
+// ReconcileOVNLBOptions translates the supported annotations of the services into the options of their OVN
+// load balancers, which it sets in the NB database through an ovnkube-master pod. Only the services of the
+// namespaces listed by the OVNLBOptionsNamespacesAnnotation of the operator configuration can set options,
+// as they change how the traffic of the services is SNATed. The invalid annotations, and the annotations of
+// the other namespaces, are reported as events on the services.
+type ReconcileOVNLBOptions struct {
+	client   client.Client
+	status   *statusmanager.StatusManager
+	recorder record.EventRecorder
+	// exec runs a command in a container of a pod, and returns its output
+	exec func(ctx context.Context, pod *corev1.Pod, container string, command []string) (string, error)
+	// warnings are the last warnings reported on each service, which are only reported again once changed
+	warnings map[types.NamespacedName]string
+}
+
+// serviceLBOptionsUpdate is the change of the options of the load balancers of a service
+type serviceLBOptionsUpdate struct {
+	svc     *corev1.Service
+	options map[string]string
+	removed []string
+}
+
+// Reconcile sets the options requested by the annotations of the services on their load balancers, and
+// removes the options it set that are no longer requested, in a single transaction
+func (r *ReconcileOVNLBOptions) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	if !r.status.IsManaged() {
+		log.Printf("Operator configuration is %s, not setting the load balancer options of the services", r.status.ManagementState())
+		return reconcile.Result{RequeueAfter: ResyncPeriod}, nil
+	}
+	operConfig := &operv1.Network{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: names.OPERATOR_CONFIG}, operConfig); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		log.Printf("Unable to retrieve Network.operator.openshift.io object: %v", err)
+		return reconcile.Result{}, err
+	}
+	if operConfig.Spec.DefaultNetwork.Type != operv1.NetworkTypeOVNKubernetes {
+		return reconcile.Result{}, nil
+	}
+
+	allowed := allowedNamespaces(operConfig)
+
+	services := &corev1.ServiceList{}
+	if err := r.client.List(ctx, services); err != nil {
+		return reconcile.Result{}, err
+	}
+	updates := []serviceLBOptionsUpdate{}
+	warned := map[types.NamespacedName]bool{}
+	for i := range services.Items {
+		svc := &services.Items[i]
+		options, errs := serviceLBOptions(svc)
+		if len(options) > 0 && !allowed.Has(svc.Namespace) {
+			options = map[string]string{}
+			errs = []error{fmt.Errorf("namespace %s is not allowed to set the options of the OVN load balancers by the %s annotation of the operator configuration",
+				svc.Namespace, names.OVNLBOptionsNamespacesAnnotation)}
+		}
+		if len(errs) > 0 {
+			warned[client.ObjectKeyFromObject(svc)] = true
+			r.warn(svc, errs)
+		}
+		removed := []string{}
+		for _, option := range strings.Split(svc.Annotations[names.OVNLBOptionsAppliedServiceAnnotation], ",") {
+			if _, ok := options[option]; option != "" && !ok {
+				removed = append(removed, option)
+			}
+		}
+		if len(options) > 0 || len(removed) > 0 {
+			updates = append(updates, serviceLBOptionsUpdate{svc: svc, options: options, removed: removed})
+		}
+	}
+	for nsn := range r.warnings {
+		if !warned[nsn] {
+			delete(r.warnings, nsn)
+		}
+	}
+	if len(updates) == 0 {
+		return reconcile.Result{}, nil
+	}
+
+	pod, err := r.nbdbPod(ctx)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if pod == nil {
+		log.Printf("No ready ovnkube-master pod, retrying to set the load balancer options of the services")
+		return reconcile.Result{RequeueAfter: ResyncPeriod}, nil
+	}
+	out, err := r.exec(ctx, pod, nbdbContainer, []string{"ovn-nbctl", "--no-leader-only", "--format=csv", "--no-headings",
+		"--data=bare", "--columns=_uuid,external_ids", "find", "Load_Balancer", `external_ids:"k8s.ovn.org/kind"="Service"`})
+	if err != nil {
+		log.Printf("Failed to find the load balancers of the services: %v", err)
+		return reconcile.Result{}, err
+	}
+	lbs := serviceLoadBalancers(out)
+
+	commands := [][]string{}
+	updated := []serviceLBOptionsUpdate{}
+	requeue := false
+	for _, update := range updates {
+		owner := client.ObjectKeyFromObject(update.svc).String()
+		if len(lbs[owner]) == 0 {
+			// the service has no cluster IP, or ovnkube-master has not created its load balancers yet
+			requeue = true
+			continue
+		}
+		commands = append(commands, optionsCommands(lbs[owner], update.options, update.removed)...)
+		updated = append(updated, update)
+		requeue = requeue || len(update.options) > 0
+	}
+	if len(commands) > 0 {
+		if _, err := r.exec(ctx, pod, nbdbContainer, transaction(commands)); err != nil {
+			log.Printf("Failed to set the load balancer options of the services: %v", err)
+			return reconcile.Result{}, err
+		}
+	}
+
+	for _, update := range updated {
+		applied := make([]string, 0, len(update.options))
+		for option := range update.options {
+			applied = append(applied, option)
+		}
+		sort.Strings(applied)
+		if err := r.setApplied(ctx, update.svc, strings.Join(applied, ",")); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	if !requeue {
+		return reconcile.Result{}, nil
+	}
+	return reconcile.Result{RequeueAfter: ResyncPeriod}, nil
+}
+
+// allowedNamespaces returns the namespaces whose services can set the options of their load balancers
+func allowedNamespaces(operConfig *operv1.Network) sets.String {
+	allowed := sets.NewString()
+	v, ok := operConfig.Annotations[names.OVNLBOptionsNamespacesAnnotation]
+	if !ok {
+		return allowed
+	}
+	for _, namespace := range strings.Split(v, ",") {
+		namespace = strings.TrimSpace(namespace)
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			log.Printf("Ignoring namespace %q of %s: %s", namespace, names.OVNLBOptionsNamespacesAnnotation, strings.Join(errs, ", "))
+			continue
+		}
+		allowed.Insert(namespace)
+	}
+	return allowed
+}
+
+// warn reports the invalid annotations of a service as an event, unless they were already reported
+func (r *ReconcileOVNLBOptions) warn(svc *corev1.Service, errs []error) {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	message := strings.Join(messages, ", ")
+	nsn := client.ObjectKeyFromObject(svc)
+	if r.warnings == nil {
+		r.warnings = map[types.NamespacedName]string{}
+	}
+	if r.warnings[nsn] == message {
+		return
+	}
+	r.warnings[nsn] = message
+	for _, err := range errs {
+		r.recorder.Eventf(svc, corev1.EventTypeWarning, "InvalidOVNLBOption", "%v. Ignoring it", err)
+	}
+}
+
+// serviceLoadBalancers parses the load balancers found with their external_ids, and returns their UUIDs by
+// the namespace/name of the service that owns them
+func serviceLoadBalancers(out string) map[string][]string {
+	lbs := map[string][]string{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, ",", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		for _, id := range strings.Fields(strings.Trim(fields[1], `"`)) {
+			if owner := strings.TrimPrefix(id, "k8s.ovn.org/owner="); owner != id {
+				lbs[owner] = append(lbs[owner], strings.TrimSpace(fields[0]))
+			}
+		}
+	}
+	return lbs
+}
+
+// serviceLBOptions returns the load balancer options requested by the annotations of a service, and the errors
+// of its invalid annotations
+func serviceLBOptions(svc *corev1.Service) (map[string]string, []error) {
+	options := map[string]string{}
+	errs := []error{}
+	for _, o := range lbOptions {
+		v, ok := svc.Annotations[o.annotation]
+		if !ok {
+			continue
+		}
+		value, err := o.parse(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %v", o.annotation, err))
+			continue
+		}
+		options[o.option] = value
+	}
+	return options, errs
+}
+
+// optionsCommands returns the ovn-nbctl commands setting the options of the load balancers, and removing the
+// unrequested ones
+func optionsCommands(lbs []string, options map[string]string, removed []string) [][]string {
+	keys := make([]string, 0, len(options))
+	for option := range options {
+		keys = append(keys, option)
+	}
+	sort.Strings(keys)
+	sort.Strings(removed)
+	commands := [][]string{}
+	for _, lb := range lbs {
+		for _, key := range keys {
+			commands = append(commands, []string{"set", "Load_Balancer", lb, fmt.Sprintf(`options:%s="%s"`, key, options[key])})
+		}
+		for _, key := range removed {
+			commands = append(commands, []string{"remove", "Load_Balancer", lb, "options", key})
+		}
+	}
+	return commands
+}
+
+// transaction returns the ovn-nbctl command running the commands in a single transaction
+func transaction(commands [][]string) []string {
+	command := []string{"ovn-nbctl", "--no-leader-only"}
+	for i, c := range commands {
+		if i > 0 {
+			command = append(command, "--")
+		}
+		command = append(command, c...)
+	}
+	return command
+}
+
+// setApplied records the options set on the load balancers of a service
+func (r *ReconcileOVNLBOptions) setApplied(ctx context.Context, svc *corev1.Service, applied string) error {
+	current, ok := svc.Annotations[names.OVNLBOptionsAppliedServiceAnnotation]
+	if (applied == "" && !ok) || (applied != "" && current == applied) {
+		return nil
+	}
+	if applied == "" {
+		delete(svc.Annotations, names.OVNLBOptionsAppliedServiceAnnotation)
+	} else {
+		svc.Annotations[names.OVNLBOptionsAppliedServiceAnnotation] = applied
+	}
+	return r.client.Update(ctx, svc)
+}
+
+// parseAffinityTimeout validates a session affinity timeout, in seconds
+func parseAffinityTimeout(v string) (string, error) {
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > maxAffinityTimeout {
+		return "", fmt.Errorf("must be a number of seconds between 1 and %d, is: %q", maxAffinityTimeout, v)
+	}
+	return strconv.Itoa(n), nil
+}
+
+// parseHairpinSNATIP validates the comma or space-separated hairpin SNAT addresses, at most one per IP family
+func parseHairpinSNATIP(v string) (string, error) {
+	addresses := strings.FieldsFunc(v, func(c rune) bool { return c == ',' || c == ' ' })
+	if len(addresses) == 0 || len(addresses) > 2 {
+		return "", fmt.Errorf("must be one IP address per IP family, is: %q", v)
+	}
+	families := map[bool]bool{}
+	ips := []string{}
+	for _, address := range addresses {
+		ip := net.ParseIP(address)
+		if ip == nil {
+			return "", fmt.Errorf("must be one IP address per IP family, %q is not an IP address", address)
+		}
+		v4 := ip.To4() != nil
+		if families[v4] {
+			return "", fmt.Errorf("must be one IP address per IP family, is: %q", v)
+		}
+		families[v4] = true
+		ips = append(ips, ip.String())
+	}
+	return strings.Join(ips, " "), nil
+}
+
+// parseBool validates a boolean
+func parseBool(v string) (string, error) {
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return "", fmt.Errorf("must be a boolean, is: %q", v)
+	}
+	return strconv.FormatBool(b), nil
+}
+
+// nbdbPod returns a ready ovnkube-master pod whose nbdb container is ready, if any
+func (r *ReconcileOVNLBOptions) nbdbPod(ctx context.Context) (*corev1.Pod, error) {
+	pods := &corev1.PodList{}
+	if err := r.client.List(ctx, pods, client.InNamespace(ovnNamespace), client.MatchingLabels{"app": "ovnkube-master"}); err != nil {
+		return nil, err
+	}
+	for i, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name == nbdbContainer && status.Ready {
+				return &pods.Items[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
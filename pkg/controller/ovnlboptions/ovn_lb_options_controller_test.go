@@ -0,0 +1,197 @@
+package ovnlboptions
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/controller/statusmanager"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestServiceLBOptions(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		names.OVNLBAffinityTimeoutServiceAnnotation: "600",
+		names.OVNLBHairpinSNATIPServiceAnnotation:   "169.254.169.5, fd69::5",
+		names.OVNLBSkipSNATServiceAnnotation:        "1",
+	}}}
+	options, errs := serviceLBOptions(svc)
+	g.Expect(errs).To(BeEmpty())
+	g.Expect(options).To(Equal(map[string]string{
+		"affinity_timeout": "600",
+		"hairpin_snat_ip":  "169.254.169.5 fd69::5",
+		"skip_snat":        "true",
+	}))
+
+	for _, v := range []string{"0", "86401", "10m"} {
+		svc.Annotations[names.OVNLBAffinityTimeoutServiceAnnotation] = v
+		options, errs = serviceLBOptions(svc)
+		g.Expect(errs).To(HaveLen(1))
+		g.Expect(errs[0]).To(MatchError(HavePrefix(names.OVNLBAffinityTimeoutServiceAnnotation + " must be")))
+		g.Expect(options).NotTo(HaveKey("affinity_timeout"))
+	}
+	for _, v := range []string{"", "169.254.169.5,169.254.169.6", "fd69::5 fd69::6", "169.254.169.5/32", "1.2.3.4,fd69::5,fd69::6"} {
+		svc.Annotations[names.OVNLBHairpinSNATIPServiceAnnotation] = v
+		_, errs = serviceLBOptions(svc)
+		g.Expect(errs).To(HaveLen(2), v)
+	}
+	svc.Annotations[names.OVNLBSkipSNATServiceAnnotation] = "yes"
+	_, errs = serviceLBOptions(svc)
+	g.Expect(errs).To(HaveLen(3))
+}
+
+func TestOptionsCommands(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(optionsCommands([]string{"lb1"}, map[string]string{}, nil)).To(BeEmpty())
+	commands := optionsCommands([]string{"lb1", "lb2"}, map[string]string{"skip_snat": "true", "affinity_timeout": "600"}, []string{"hairpin_snat_ip"})
+	g.Expect(strings.Join(transaction(commands), " ")).To(Equal(
+		`ovn-nbctl --no-leader-only set Load_Balancer lb1 options:affinity_timeout="600" -- set Load_Balancer lb1 options:skip_snat="true" -- ` +
+			`remove Load_Balancer lb1 options hairpin_snat_ip -- ` +
+			`set Load_Balancer lb2 options:affinity_timeout="600" -- set Load_Balancer lb2 options:skip_snat="true" -- ` +
+			`remove Load_Balancer lb2 options hairpin_snat_ip`))
+}
+
+func TestServiceLoadBalancers(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(serviceLoadBalancers("lb1,k8s.ovn.org/kind=Service k8s.ovn.org/owner=ns/web\n" +
+		"lb2,\"k8s.ovn.org/kind=Service k8s.ovn.org/owner=ns/web\"\n" +
+		"lb3,k8s.ovn.org/kind=Service k8s.ovn.org/owner=other/db\n" +
+		"lb4,k8s.ovn.org/kind=Service\n")).To(Equal(map[string][]string{
+		"ns/web":   {"lb1", "lb2"},
+		"other/db": {"lb3"},
+	}))
+}
+
+func TestAllowedNamespaces(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	operConfig := &operv1.Network{}
+	g.Expect(allowedNamespaces(operConfig).List()).To(BeEmpty())
+	operConfig.Annotations = map[string]string{names.OVNLBOptionsNamespacesAnnotation: "ns, other,Invalid_NS,"}
+	g.Expect(allowedNamespaces(operConfig).List()).To(Equal([]string{"ns", "other"}))
+}
+
+func TestReconcileOVNLBOptions(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	g.Expect(operv1.AddToScheme(scheme)).To(Succeed())
+
+	operConfig := &operv1.Network{
+		ObjectMeta: metav1.ObjectMeta{Name: names.OPERATOR_CONFIG, Annotations: map[string]string{
+			names.OVNLBOptionsNamespacesAnnotation: "ns",
+		}},
+		Spec: operv1.NetworkSpec{DefaultNetwork: operv1.DefaultNetworkDefinition{Type: operv1.NetworkTypeOVNKubernetes}},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ovnNamespace, Name: "ovnkube-master-abcde", Labels: map[string]string{"app": "ovnkube-master"}},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{Name: nbdbContainer, Ready: true}},
+		},
+	}
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "web", Annotations: map[string]string{
+		names.OVNLBAffinityTimeoutServiceAnnotation: "600",
+		names.OVNLBSkipSNATServiceAnnotation:        "maybe",
+	}}}
+	db := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "db", Annotations: map[string]string{
+		names.OVNLBSkipSNATServiceAnnotation: "true",
+	}}}
+	tenant := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "tenant", Name: "web", Annotations: map[string]string{
+		names.OVNLBHairpinSNATIPServiceAnnotation: "1.2.3.4",
+	}}}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(operConfig, pod, svc, db, tenant).Build()
+	recorder := record.NewFakeRecorder(10)
+	commands := []string{}
+	r := &ReconcileOVNLBOptions{
+		client:   client,
+		status:   statusmanager.New(nil, nil, "testing"),
+		recorder: recorder,
+		exec: func(_ context.Context, p *corev1.Pod, container string, command []string) (string, error) {
+			g.Expect(p.Name).To(Equal(pod.Name))
+			g.Expect(container).To(Equal(nbdbContainer))
+			if command[len(command)-2] == "Load_Balancer" {
+				// the load balancers of ns/db are not created yet
+				return "lb1,k8s.ovn.org/kind=Service k8s.ovn.org/owner=ns/web\nlb2,k8s.ovn.org/kind=Service k8s.ovn.org/owner=ns/web\n" +
+					"lb3,k8s.ovn.org/kind=Service k8s.ovn.org/owner=tenant/web\n", nil
+			}
+			commands = append(commands, strings.Join(command, " "))
+			return "", nil
+		},
+	}
+	r.status.SetManagementState(operv1.Managed)
+
+	result, err := r.Reconcile(context.TODO(), lbOptionsRequest)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(ResyncPeriod))
+	// the options of every service are set in one transaction, and those of the namespaces that are
+	// not allowed are ignored
+	g.Expect(commands).To(Equal([]string{
+		`ovn-nbctl --no-leader-only set Load_Balancer lb1 options:affinity_timeout="600" -- set Load_Balancer lb2 options:affinity_timeout="600"`,
+	}))
+	events := []string{}
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	g.Expect(events).To(ConsistOf(
+		`Warning InvalidOVNLBOption `+names.OVNLBSkipSNATServiceAnnotation+` must be a boolean, is: "maybe". Ignoring it`,
+		`Warning InvalidOVNLBOption namespace tenant is not allowed to set the options of the OVN load balancers by the `+
+			names.OVNLBOptionsNamespacesAnnotation+` annotation of the operator configuration. Ignoring it`,
+	))
+	g.Expect(client.Get(context.TODO(), types.NamespacedName{Namespace: "ns", Name: "web"}, svc)).To(Succeed())
+	g.Expect(svc.Annotations).To(HaveKeyWithValue(names.OVNLBOptionsAppliedServiceAnnotation, "affinity_timeout"))
+	g.Expect(client.Get(context.TODO(), types.NamespacedName{Namespace: "ns", Name: "db"}, db)).To(Succeed())
+	g.Expect(db.Annotations).NotTo(HaveKey(names.OVNLBOptionsAppliedServiceAnnotation))
+
+	// the warnings are not reported again while unchanged
+	_, err = r.Reconcile(context.TODO(), lbOptionsRequest)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	// the options that are no longer requested, or no longer allowed, are removed
+	g.Expect(client.Delete(context.TODO(), db)).To(Succeed())
+	delete(svc.Annotations, names.OVNLBAffinityTimeoutServiceAnnotation)
+	delete(svc.Annotations, names.OVNLBSkipSNATServiceAnnotation)
+	svc.Annotations[names.OVNLBHairpinSNATIPServiceAnnotation] = "1.2.3.4"
+	g.Expect(client.Update(context.TODO(), svc)).To(Succeed())
+	commands = []string{}
+	result, err = r.Reconcile(context.TODO(), lbOptionsRequest)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(ResyncPeriod))
+	g.Expect(commands).To(Equal([]string{
+		`ovn-nbctl --no-leader-only set Load_Balancer lb1 options:hairpin_snat_ip="1.2.3.4" -- remove Load_Balancer lb1 options affinity_timeout -- ` +
+			`set Load_Balancer lb2 options:hairpin_snat_ip="1.2.3.4" -- remove Load_Balancer lb2 options affinity_timeout`,
+	}))
+
+	delete(operConfig.Annotations, names.OVNLBOptionsNamespacesAnnotation)
+	g.Expect(client.Update(context.TODO(), operConfig)).To(Succeed())
+	commands = []string{}
+	result, err = r.Reconcile(context.TODO(), lbOptionsRequest)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(BeZero())
+	g.Expect(commands).To(Equal([]string{
+		`ovn-nbctl --no-leader-only remove Load_Balancer lb1 options hairpin_snat_ip -- remove Load_Balancer lb2 options hairpin_snat_ip`,
+	}))
+	svc = &corev1.Service{}
+	g.Expect(client.Get(context.TODO(), types.NamespacedName{Namespace: "ns", Name: "web"}, svc)).To(Succeed())
+	g.Expect(svc.Annotations).NotTo(HaveKey(names.OVNLBOptionsAppliedServiceAnnotation))
+
+	// nothing is done once they are removed
+	commands = []string{}
+	_, err = r.Reconcile(context.TODO(), lbOptionsRequest)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(commands).To(BeEmpty())
+}
@@ -0,0 +1,249 @@
+package ovnloglevel
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/cluster-network-operator/pkg/controller/statusmanager"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	k8sutil "github.com/openshift/cluster-network-operator/pkg/util/k8s"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	ovnNamespace = "openshift-ovn-kubernetes"
+	// ovnkube-node runs ovn-controller, which also has access to the control socket of ovs-vswitchd
+	ovnNodeContainer = "ovn-controller"
+	// defaultLogLevel is the level the daemons are set back to
+	defaultLogLevel = "info"
+)
+
+// logTargets are the commands run to set the log level of each daemon
+var logTargets = map[string]string{
+	"ovn-controller": "ovn-appctl -t /var/run/ovn/ovn-controller.$(cat /var/run/ovn/ovn-controller.pid).ctl vlog/set %s",
+	"ovs-vswitchd":   "ovs-appctl -t /var/run/openvswitch/ovs-vswitchd.$(cat /var/run/openvswitch/ovs-vswitchd.pid).ctl vlog/set %s",
+}
+
+// logLevelRegexp matches the levels that are safe to pass to vlog/set, like "dbg" or "file:dbg"
+var logLevelRegexp = regexp.MustCompile(`^([a-z]+:)?(emer|err|warn|info|dbg|off)$`)
+
+// The interval at which the log levels of a node are retried while its ovnkube-node pod is not ready
+var retryInterval = 30 * time.Second
+
+// Add creates a new ovn-log-level controller and adds it to the Manager. The Manager will set fields on the
+// Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager, status *statusmanager.StatusManager) error {
+	// We need a clientset in order to exec into pods, the controller-runtime client does not
+	// support the exec subresource
+	clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+	r := &ReconcileOVNLogLevel{clientset: clientset, status: status}
+	r.exec = func(ctx context.Context, pod *corev1.Pod, container string, command []string) error {
+		_, err := k8sutil.ExecInPod(mgr.GetConfig(), clientset, pod, container, command)
+		return err
+	}
+	return add(mgr, r)
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileOVNLogLevel) error {
+	c, err := controller.New("ovn-log-level-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	// Watch the nodes whose log levels are or were adjusted
+	if err := c.Watch(&source.Kind{Type: &corev1.Node{}}, &handler.EnqueueRequestForObject{},
+		predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			annotations := obj.GetAnnotations()
+			_, desired := annotations[names.OVNLogLevelNodeAnnotation]
+			_, applied := annotations[names.OVNLogLevelAppliedNodeAnnotation]
+			return desired || applied
+		})); err != nil {
+		return err
+	}
+	// and the ovnkube-node pods, whose replacements start with the default log levels, and which
+	// become ready after they are created
+	return c.Watch(&source.Kind{Type: &corev1.Pod{}}, handler.EnqueueRequestsFromMapFunc(reconcileOVNNodePod),
+		predicate.Funcs{
+			CreateFunc:  func(e event.CreateEvent) bool { return isOVNNodePod(e.Object) },
+			UpdateFunc:  func(e event.UpdateEvent) bool { return isOVNNodePod(e.ObjectNew) },
+			DeleteFunc:  func(event.DeleteEvent) bool { return false },
+			GenericFunc: func(event.GenericEvent) bool { return false },
+		})
+}
+
+// isOVNNodePod returns whether a pod is an ovnkube-node pod
+func isOVNNodePod(obj client.Object) bool {
+	return obj.GetNamespace() == ovnNamespace && obj.GetLabels()["app"] == "ovnkube-node"
+}
+
+// reconcileOVNNodePod forwards a change of an ovnkube-node pod to its node
+func reconcileOVNNodePod(obj client.Object) []reconcile.Request {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok || pod.Spec.NodeName == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: pod.Spec.NodeName}}}
+}
+
+var _ reconcile.Reconciler = &ReconcileOVNLogLevel{}
+
+// ReconcileOVNLogLevel sets the log levels requested by the OVNLogLevelNodeAnnotation on the OVS and
+// OVN daemons of a node, through its ovnkube-node pod, so that debug logs can be enabled on selected
+// nodes during an incident without rolling out ovnkube-node. The levels are set again when the
+// ovnkube-node pod is replaced, which the pods watched are.
+type ReconcileOVNLogLevel struct {
+	clientset kubernetes.Interface
+	status    *statusmanager.StatusManager
+	// exec runs a command in a container of a pod
+	exec func(ctx context.Context, pod *corev1.Pod, container string, command []string) error
+}
+
+// Reconcile sets the requested log levels of a node, unless they were already set through its
+// current ovnkube-node pod
+func (r *ReconcileOVNLogLevel) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	if !r.status.IsManaged() {
+		log.Printf("Operator configuration is %s, not setting the log levels of node %s", r.status.ManagementState(), request.Name)
+		return reconcile.Result{RequeueAfter: retryInterval}, nil
+	}
+	node, err := r.clientset.CoreV1().Nodes().Get(ctx, request.Name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		log.Printf("Unable to retrieve Node %s: %v", request.Name, err)
+		return reconcile.Result{}, err
+	}
+	desired, ok := node.Annotations[names.OVNLogLevelNodeAnnotation]
+	applied, wasApplied := node.Annotations[names.OVNLogLevelAppliedNodeAnnotation]
+	if !ok && !wasApplied {
+		return reconcile.Result{}, nil
+	}
+
+	levels, err := parseLogLevels(desired)
+	if err != nil {
+		log.Printf("Ignoring %s of node %s: %v", names.OVNLogLevelNodeAnnotation, node.Name, err)
+		return reconcile.Result{}, nil
+	}
+	// the daemons whose level was set, but no longer requested, are set back to the default level
+	_, appliedSpec := splitApplied(applied)
+	appliedLevels, _ := parseLogLevels(appliedSpec)
+	for target := range appliedLevels {
+		if _, ok := levels[target]; !ok {
+			levels[target] = defaultLogLevel
+		}
+	}
+
+	pod, err := r.ovnNodePod(ctx, node.Name)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if pod == nil {
+		log.Printf("No ready ovnkube-node pod on node %s, retrying to set its log levels", node.Name)
+		return reconcile.Result{RequeueAfter: retryInterval}, nil
+	}
+	if ok && applied == pod.Name+":"+desired {
+		return reconcile.Result{}, nil
+	}
+
+	targets := []string{}
+	for target := range levels {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	for _, target := range targets {
+		command := []string{"/bin/bash", "-c", fmt.Sprintf(logTargets[target], levels[target])}
+		if err := r.exec(ctx, pod, ovnNodeContainer, command); err != nil {
+			log.Printf("Failed to set the log level of %s on node %s: %v", target, node.Name, err)
+			return reconcile.Result{}, err
+		}
+		log.Printf("Set the log level of %s on node %s to %s", target, node.Name, levels[target])
+	}
+
+	if ok {
+		node.Annotations[names.OVNLogLevelAppliedNodeAnnotation] = pod.Name + ":" + desired
+	} else {
+		delete(node.Annotations, names.OVNLogLevelAppliedNodeAnnotation)
+	}
+	if _, err := r.clientset.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{}); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+
+// splitApplied splits the value of the OVNLogLevelAppliedNodeAnnotation into the name of the
+// ovnkube-node pod and the log levels
+func splitApplied(applied string) (string, string) {
+	parts := strings.SplitN(applied, ":", 2)
+	if len(parts) != 2 {
+		return "", ""
+	}
+	return parts[0], parts[1]
+}
+
+// parseLogLevels parses comma-separated "<daemon>=<level>" pairs
+func parseLogLevels(spec string) (map[string]string, error) {
+	levels := map[string]string{}
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%q is not a <daemon>=<level> pair", pair)
+		}
+		target, level := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if _, ok := logTargets[target]; !ok {
+			return nil, fmt.Errorf("unknown daemon %q, must be ovn-controller or ovs-vswitchd", target)
+		}
+		if !logLevelRegexp.MatchString(level) {
+			return nil, fmt.Errorf("invalid log level %q of %s", level, target)
+		}
+		levels[target] = level
+	}
+	return levels, nil
+}
+
+// ovnNodePod returns the ready ovnkube-node pod of a node, if any
+func (r *ReconcileOVNLogLevel) ovnNodePod(ctx context.Context, nodeName string) (*corev1.Pod, error) {
+	pods, err := r.clientset.CoreV1().Pods(ovnNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "app=ovnkube-node",
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, pod := range pods.Items {
+		if pod.Spec.NodeName != nodeName || pod.DeletionTimestamp != nil {
+			continue
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name == ovnNodeContainer && status.Ready {
+				return &pods.Items[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,150 @@
+package ovnloglevel
+
+import (
+	"context"
+	"testing"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/controller/statusmanager"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestParseLogLevels(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	levels, err := parseLogLevels("ovn-controller=dbg, ovs-vswitchd=file:info,")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(levels).To(Equal(map[string]string{"ovn-controller": "dbg", "ovs-vswitchd": "file:info"}))
+
+	levels, err = parseLogLevels("")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(levels).To(BeEmpty())
+
+	_, err = parseLogLevels("ovn-northd=dbg")
+	g.Expect(err).To(MatchError(ContainSubstring("unknown daemon")))
+	_, err = parseLogLevels("ovn-controller")
+	g.Expect(err).To(MatchError(ContainSubstring("is not a <daemon>=<level> pair")))
+	_, err = parseLogLevels("ovn-controller=dbg;reboot")
+	g.Expect(err).To(MatchError(ContainSubstring("invalid log level")))
+}
+
+func TestReconcileOVNLogLevel(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:        "node",
+		Annotations: map[string]string{names.OVNLogLevelNodeAnnotation: "ovn-controller=dbg,ovs-vswitchd=dbg"},
+	}}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ovnNamespace, Name: "ovnkube-node-abcde", Labels: map[string]string{"app": "ovnkube-node"}},
+		Spec:       corev1.PodSpec{NodeName: "node"},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{Name: ovnNodeContainer, Ready: false}},
+		},
+	}
+	clientset := fake.NewSimpleClientset(node, pod)
+	commands := []string{}
+	status := statusmanager.New(nil, nil, "testing")
+	status.SetManagementState(operv1.Managed)
+	r := &ReconcileOVNLogLevel{
+		clientset: clientset,
+		status:    status,
+		exec: func(_ context.Context, p *corev1.Pod, container string, command []string) error {
+			g.Expect(p.Name).To(Equal(pod.Name))
+			g.Expect(container).To(Equal(ovnNodeContainer))
+			commands = append(commands, command[2])
+			return nil
+		},
+	}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "node"}}
+	getNode := func() *corev1.Node {
+		n, err := clientset.CoreV1().Nodes().Get(context.TODO(), "node", metav1.GetOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+		return n
+	}
+
+	// the levels are set once the ovnkube-node pod is ready
+	result, err := r.Reconcile(context.TODO(), request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(retryInterval))
+	g.Expect(commands).To(BeEmpty())
+
+	pod.Status.ContainerStatuses[0].Ready = true
+	_, err = clientset.CoreV1().Pods(ovnNamespace).Update(context.TODO(), pod, metav1.UpdateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// nothing is changed while the operator is Unmanaged
+	status.SetManagementState(operv1.Unmanaged)
+	result, err = r.Reconcile(context.TODO(), request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(retryInterval))
+	g.Expect(commands).To(BeEmpty())
+	status.SetManagementState(operv1.Managed)
+
+	_, err = r.Reconcile(context.TODO(), request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(commands).To(Equal([]string{
+		"ovn-appctl -t /var/run/ovn/ovn-controller.$(cat /var/run/ovn/ovn-controller.pid).ctl vlog/set dbg",
+		"ovs-appctl -t /var/run/openvswitch/ovs-vswitchd.$(cat /var/run/openvswitch/ovs-vswitchd.pid).ctl vlog/set dbg",
+	}))
+	g.Expect(getNode().Annotations).To(HaveKeyWithValue(names.OVNLogLevelAppliedNodeAnnotation, "ovnkube-node-abcde:ovn-controller=dbg,ovs-vswitchd=dbg"))
+
+	// they are not set again through the same pod
+	commands = nil
+	_, err = r.Reconcile(context.TODO(), request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(commands).To(BeEmpty())
+
+	// the daemons that are no longer listed are set back to info
+	n := getNode()
+	n.Annotations[names.OVNLogLevelNodeAnnotation] = "ovn-controller=dbg"
+	_, err = clientset.CoreV1().Nodes().Update(context.TODO(), n, metav1.UpdateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = r.Reconcile(context.TODO(), request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(commands).To(Equal([]string{
+		"ovn-appctl -t /var/run/ovn/ovn-controller.$(cat /var/run/ovn/ovn-controller.pid).ctl vlog/set dbg",
+		"ovs-appctl -t /var/run/openvswitch/ovs-vswitchd.$(cat /var/run/openvswitch/ovs-vswitchd.pid).ctl vlog/set info",
+	}))
+
+	// and all of them when the annotation is removed
+	commands = nil
+	n = getNode()
+	delete(n.Annotations, names.OVNLogLevelNodeAnnotation)
+	_, err = clientset.CoreV1().Nodes().Update(context.TODO(), n, metav1.UpdateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = r.Reconcile(context.TODO(), request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(commands).To(Equal([]string{
+		"ovn-appctl -t /var/run/ovn/ovn-controller.$(cat /var/run/ovn/ovn-controller.pid).ctl vlog/set info",
+	}))
+	g.Expect(getNode().Annotations).NotTo(HaveKey(names.OVNLogLevelAppliedNodeAnnotation))
+}
+
+func TestReconcileOVNNodePod(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ovnNamespace, Name: "ovnkube-node-abcde", Labels: map[string]string{"app": "ovnkube-node"}},
+		Spec:       corev1.PodSpec{NodeName: "node"},
+	}
+	g.Expect(isOVNNodePod(pod)).To(BeTrue())
+	g.Expect(reconcileOVNNodePod(pod)).To(Equal([]reconcile.Request{{NamespacedName: types.NamespacedName{Name: "node"}}}))
+
+	// the pods not scheduled yet have no node to set the levels of
+	pod.Spec.NodeName = ""
+	g.Expect(reconcileOVNNodePod(pod)).To(BeEmpty())
+
+	pod.Labels["app"] = "ovnkube-master"
+	g.Expect(isOVNNodePod(pod)).To(BeFalse())
+	pod.Labels["app"] = "ovnkube-node"
+	pod.Namespace = "default"
+	g.Expect(isOVNNodePod(pod)).To(BeFalse())
+}
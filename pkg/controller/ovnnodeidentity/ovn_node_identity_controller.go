@@ -0,0 +1,252 @@
+package ovnnodeidentity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/controller/signer"
+	"github.com/openshift/cluster-network-operator/pkg/controller/statusmanager"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	ovnNamespace = "openshift-ovn-kubernetes"
+
+	// NodeIdentityLabel labels the Secrets of the pre-provisioned node identities with the name of their node
+	NodeIdentityLabel = "network.operator.openshift.io/ovn-node-identity"
+)
+
+// the files of the identity of a node, as read by OVS and the ovn-ipsec daemonset
+const (
+	systemIDPath   = "/etc/openvswitch/system-id.conf"
+	privateKeyPath = "/etc/openvswitch/keys/ipsec-privkey.pem"
+	certPath       = "/etc/openvswitch/keys/ipsec-cert.pem"
+	caCertPath     = "/etc/openvswitch/keys/ipsec-cacert.pem"
+)
+
+// The interval at which the identities are retried when the signer CA is not created yet
+var retryInterval = 30 * time.Second
+
+// Add creates a new OVN node identity controller and adds it to the Manager. The Manager will set fields on the
+// Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager, status *statusmanager.StatusManager) error {
+	return add(mgr, &ReconcileOVNNodeIdentity{client: mgr.GetClient(), status: status})
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileOVNNodeIdentity) error {
+	c, err := controller.New("ovn-node-identity-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	// Watch the operator configuration
+	if err := c.Watch(&source.Kind{Type: &operv1.Network{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+	// and the Secrets of the identities, so that a removed one is generated again
+	return c.Watch(&source.Kind{Type: &corev1.Secret{}},
+		handler.EnqueueRequestsFromMapFunc(func(client.Object) []reconcile.Request {
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: names.OPERATOR_CONFIG}}}
+		}),
+		predicate.NewPredicateFuncs(func(object client.Object) bool {
+			_, ok := object.GetLabels()[NodeIdentityLabel]
+			return object.GetNamespace() == ovnNamespace && ok
+		}),
+	)
+}
+
+var _ reconcile.Reconciler = &ReconcileOVNNodeIdentity{}
+
+// ReconcileOVNNodeIdentity generates the OVN identities of the nodes listed in the OVNPreprovisionedNodesAnnotation,
+// so that edge nodes with intermittent connectivity join the overlay without waiting for the in-cluster signer:
+// a chassis ID, and an IPsec certificate of it signed by the signer CA. Each identity is generated once, in the
+// ovn-node-identity-<node> Secret, along with an ignition config writing it on the node. The Secrets of the nodes
+// no longer listed are removed.
+type ReconcileOVNNodeIdentity struct {
+	client client.Client
+	status *statusmanager.StatusManager
+}
+
+// Reconcile generates the missing node identities, and removes those no longer requested
+func (r *ReconcileOVNNodeIdentity) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	if request.Name != names.OPERATOR_CONFIG {
+		return reconcile.Result{}, nil
+	}
+	if !r.status.IsManaged() {
+		log.Printf("Operator configuration is %s, not issuing the node identities", r.status.ManagementState())
+		return reconcile.Result{RequeueAfter: retryInterval}, nil
+	}
+	operConfig := &operv1.Network{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: names.OPERATOR_CONFIG}, operConfig); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		log.Printf("Unable to retrieve Network.operator.openshift.io object: %v", err)
+		return reconcile.Result{}, err
+	}
+	nodes := preprovisionedNodes(operConfig)
+
+	secrets := &corev1.SecretList{}
+	if err := r.client.List(ctx, secrets, client.InNamespace(ovnNamespace), client.HasLabels{NodeIdentityLabel}); err != nil {
+		return reconcile.Result{}, err
+	}
+	existing := sets.NewString()
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		node := secret.Labels[NodeIdentityLabel]
+		if nodes.Has(node) {
+			existing.Insert(node)
+			continue
+		}
+		if err := r.client.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		log.Printf("Removed the pre-provisioned OVN identity of node %s", node)
+	}
+
+	missing := nodes.Difference(existing)
+	if missing.Len() == 0 {
+		r.status.SetNotDegraded(statusmanager.NodeIdentity)
+		return reconcile.Result{}, nil
+	}
+	ca := &corev1.Secret{}
+	if err := r.client.Get(ctx, types.NamespacedName{Namespace: signer.CASecretNamespace, Name: signer.CASecretName}, ca); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Printf("Waiting for the signer CA to pre-provision the OVN identities of nodes %s", strings.Join(missing.List(), ", "))
+			return reconcile.Result{RequeueAfter: retryInterval}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	for _, node := range missing.List() {
+		secret, err := nodeIdentitySecret(node, ca)
+		if err != nil {
+			r.status.SetDegraded(statusmanager.NodeIdentity, "NodeIdentityFailure",
+				fmt.Sprintf("Failed to pre-provision the OVN identity of node %s: %v", node, err))
+			return reconcile.Result{}, err
+		}
+		if err := r.client.Create(ctx, secret); err != nil && !apierrors.IsAlreadyExists(err) {
+			return reconcile.Result{}, err
+		}
+		log.Printf("Pre-provisioned the OVN identity of node %s, chassis ID %s", node, secret.Data["chassis-id"])
+	}
+	r.status.SetNotDegraded(statusmanager.NodeIdentity)
+	return reconcile.Result{}, nil
+}
+
+// preprovisionedNodes returns the nodes listed in the OVNPreprovisionedNodesAnnotation, when the default network is
+// OVNKubernetes. Invalid node names are ignored.
+func preprovisionedNodes(operConfig *operv1.Network) sets.String {
+	nodes := sets.NewString()
+	v, ok := operConfig.Annotations[names.OVNPreprovisionedNodesAnnotation]
+	if !ok {
+		return nodes
+	}
+	if operConfig.Spec.DefaultNetwork.Type != operv1.NetworkTypeOVNKubernetes {
+		log.Printf("%s is only supported with OVNKubernetes. Ignoring it", names.OVNPreprovisionedNodesAnnotation)
+		return nodes
+	}
+	for _, node := range strings.Split(v, ",") {
+		node = strings.TrimSpace(node)
+		if node == "" {
+			continue
+		}
+		if errs := validation.IsDNS1123Subdomain(secretName(node)); len(errs) > 0 {
+			log.Printf("%s: %q is not a valid node name. Ignoring it: %s", names.OVNPreprovisionedNodesAnnotation, node, strings.Join(errs, ", "))
+			continue
+		}
+		nodes.Insert(node)
+	}
+	return nodes
+}
+
+// secretName returns the name of the Secret of the identity of a node
+func secretName(node string) string {
+	return "ovn-node-identity-" + node
+}
+
+// ignitionConfig is the ignition config writing the identity on its node
+type ignitionConfig struct {
+	Ignition struct {
+		Version string `json:"version"`
+	} `json:"ignition"`
+	Storage struct {
+		Files []ignitionFile `json:"files"`
+	} `json:"storage"`
+}
+
+// ignitionFile is a file of an ignition config, with its contents as a data URL
+type ignitionFile struct {
+	Path      string `json:"path"`
+	Mode      int    `json:"mode"`
+	Overwrite bool   `json:"overwrite"`
+	Contents  struct {
+		Source string `json:"source"`
+	} `json:"contents"`
+}
+
+// nodeIdentitySecret generates the identity of a node: a random chassis ID, and a certificate of it signed by the
+// signer CA, which the ovn-ipsec daemonset then uses instead of requesting one
+func nodeIdentitySecret(node string, ca *corev1.Secret) (*corev1.Secret, error) {
+	chassisID := string(uuid.NewUUID())
+	cert, key, err := signer.IssueCertificate(ca.Data["tls.crt"], ca.Data["tls.key"], chassisID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue the certificate: %w", err)
+	}
+
+	ignition := ignitionConfig{}
+	ignition.Ignition.Version = "3.2.0"
+	for _, f := range []struct {
+		path     string
+		mode     int
+		contents string
+	}{
+		{systemIDPath, 0644, chassisID + "\n"},
+		{privateKeyPath, 0600, string(key)},
+		{certPath, 0644, string(cert)},
+		{caCertPath, 0644, string(ca.Data["tls.crt"])},
+	} {
+		file := ignitionFile{Path: f.path, Mode: f.mode, Overwrite: true}
+		file.Contents.Source = "data:," + url.PathEscape(f.contents)
+		ignition.Storage.Files = append(ignition.Storage.Files, file)
+	}
+	ignitionJSON, err := json.Marshal(ignition)
+	if err != nil {
+		return nil, err
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ovnNamespace,
+			Name:      secretName(node),
+			Labels:    map[string]string{NodeIdentityLabel: node},
+		},
+		Data: map[string][]byte{
+			"chassis-id":    []byte(chassisID),
+			"tls.crt":       cert,
+			"tls.key":       key,
+			"ca-bundle.crt": ca.Data["tls.crt"],
+			"ignition.json": ignitionJSON,
+		},
+	}, nil
+}
@@ -0,0 +1,136 @@
+package ovnnodeidentity
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/controller/signer"
+	"github.com/openshift/cluster-network-operator/pkg/controller/statusmanager"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/openshift/library-go/pkg/crypto"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcileOVNNodeIdentity(t *testing.T) {
+	g := NewGomegaWithT(t)
+	g.Expect(operv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	operConfig := &operv1.Network{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        names.OPERATOR_CONFIG,
+			Annotations: map[string]string{names.OVNPreprovisionedNodesAnnotation: "edge-1, edge-2,Invalid_Name"},
+		},
+		Spec: operv1.NetworkSpec{DefaultNetwork: operv1.DefaultNetworkDefinition{Type: operv1.NetworkTypeOVNKubernetes}},
+	}
+	cl := fake.NewClientBuilder().WithObjects(operConfig).Build()
+	r := &ReconcileOVNNodeIdentity{client: cl, status: statusmanager.New(cl, nil, "testing")}
+	r.status.SetManagementState(operv1.Managed)
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: names.OPERATOR_CONFIG}}
+	identities := func() map[string]*corev1.Secret {
+		secrets := &corev1.SecretList{}
+		g.Expect(cl.List(context.TODO(), secrets, client.HasLabels{NodeIdentityLabel})).To(Succeed())
+		res := map[string]*corev1.Secret{}
+		for i := range secrets.Items {
+			res[secrets.Items[i].Labels[NodeIdentityLabel]] = &secrets.Items[i]
+		}
+		return res
+	}
+
+	// the identities wait for the signer CA
+	result, err := r.Reconcile(context.TODO(), request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(retryInterval))
+	g.Expect(identities()).To(BeEmpty())
+
+	ca, err := crypto.MakeSelfSignedCAConfigForDuration("ovn-kubernetes-signer", time.Hour)
+	g.Expect(err).NotTo(HaveOccurred())
+	caCert, caKey, err := ca.GetPEMBytes()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cl.Create(context.TODO(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: signer.CASecretNamespace, Name: signer.CASecretName},
+		Data:       map[string][]byte{"tls.crt": caCert, "tls.key": caKey},
+	})).To(Succeed())
+	roots := x509.NewCertPool()
+	g.Expect(roots.AppendCertsFromPEM(caCert)).To(BeTrue())
+
+	// the identities of the valid node names are generated
+	result, err = r.Reconcile(context.TODO(), request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(BeZero())
+	secrets := identities()
+	g.Expect(secrets).To(HaveLen(2))
+	g.Expect(secrets).To(HaveKey("edge-1"))
+	g.Expect(secrets).To(HaveKey("edge-2"))
+
+	secret := secrets["edge-1"]
+	g.Expect(secret.Name).To(Equal("ovn-node-identity-edge-1"))
+	g.Expect(secret.Namespace).To(Equal("openshift-ovn-kubernetes"))
+	chassisID := string(secret.Data["chassis-id"])
+	g.Expect(chassisID).NotTo(BeEmpty())
+	g.Expect(chassisID).NotTo(Equal(string(secrets["edge-2"].Data["chassis-id"])))
+	block, _ := pem.Decode(secret.Data["tls.crt"])
+	g.Expect(block).NotTo(BeNil())
+	cert, err := x509.ParseCertificate(block.Bytes)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cert.Subject.CommonName).To(Equal(chassisID))
+	_, err = cert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(secret.Data["ca-bundle.crt"]).To(Equal(caCert))
+
+	ignition := &ignitionConfig{}
+	g.Expect(json.Unmarshal(secret.Data["ignition.json"], ignition)).To(Succeed())
+	g.Expect(ignition.Ignition.Version).To(Equal("3.2.0"))
+	files := map[string]string{}
+	for _, f := range ignition.Storage.Files {
+		files[f.Path] = f.Contents.Source
+	}
+	g.Expect(files).To(HaveLen(4))
+	g.Expect(files).To(HaveKeyWithValue("/etc/openvswitch/system-id.conf", "data:,"+chassisID+"%0A"))
+	g.Expect(files).To(HaveKey("/etc/openvswitch/keys/ipsec-privkey.pem"))
+	g.Expect(files).To(HaveKey("/etc/openvswitch/keys/ipsec-cert.pem"))
+	g.Expect(files).To(HaveKey("/etc/openvswitch/keys/ipsec-cacert.pem"))
+
+	// the identities are only generated once
+	_, err = r.Reconcile(context.TODO(), request)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(identities()["edge-1"].Data["chassis-id"]).To(Equal([]byte(chassisID)))
+
+	// the identities of the nodes no longer listed are removed
+	g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: names.OPERATOR_CONFIG}, operConfig)).To(Succeed())
+	operConfig.Annotations[names.OVNPreprovisionedNodesAnnotation] = "edge-1"
+	g.Expect(cl.Update(context.TODO(), operConfig)).To(Succeed())
+	_, err = r.Reconcile(context.TODO(), request)
+	g.Expect(err).NotTo(HaveOccurred())
+	secrets = identities()
+	g.Expect(secrets).To(HaveLen(1))
+	g.Expect(secrets).To(HaveKey("edge-1"))
+}
+
+func TestPreprovisionedNodes(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	operConfig := &operv1.Network{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        names.OPERATOR_CONFIG,
+			Annotations: map[string]string{names.OVNPreprovisionedNodesAnnotation: "edge-1,,edge-2.example.com, edge-1"},
+		},
+		Spec: operv1.NetworkSpec{DefaultNetwork: operv1.DefaultNetworkDefinition{Type: operv1.NetworkTypeOVNKubernetes}},
+	}
+	g.Expect(preprovisionedNodes(operConfig).List()).To(Equal([]string{"edge-1", "edge-2.example.com"}))
+
+	// only supported with OVNKubernetes
+	operConfig.Spec.DefaultNetwork.Type = operv1.NetworkTypeOpenShiftSDN
+	g.Expect(preprovisionedNodes(operConfig).List()).To(BeEmpty())
+}
@@ -0,0 +1,336 @@
+package ovnnodeupgrade
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/cluster-network-operator/pkg/controller/statusmanager"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const ovnNamespace = "openshift-ovn-kubernetes"
+
+// isOVNNodeDaemonSet returns true for the daemonsets of ovnkube-node that may be upgraded conservatively:
+// the one of the full node mode, and those of the pools of dpu-host nodes, suffixed with the name of the pool
+func isOVNNodeDaemonSet(name string) bool {
+	return name == "ovnkube-node" || name == "ovnkube-node-dpu-host" || strings.HasPrefix(name, "ovnkube-node-dpu-host-")
+}
+
+// The interval at which a conservative upgrade in progress is checked
+var pollInterval = 10 * time.Second
+
+// Add creates a new ovn-node-upgrade controller and adds it to the Manager. The Manager will set fields on the
+// Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager, status *statusmanager.StatusManager) error {
+	// We need a clientset in order to evict pods, the controller-runtime client does not
+	// support the eviction subresource
+	clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+	return add(mgr, &ReconcileOVNNodeUpgrade{clientset: clientset, status: status, untainted: map[string]bool{}})
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileOVNNodeUpgrade) error {
+	c, err := controller.New("ovn-node-upgrade-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	// Watch the ovnkube-node daemonsets
+	return c.Watch(&source.Kind{Type: &appsv1.DaemonSet{}}, &handler.EnqueueRequestForObject{},
+		predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			return obj.GetNamespace() == ovnNamespace && isOVNNodeDaemonSet(obj.GetName())
+		}))
+}
+
+var _ reconcile.Reconciler = &ReconcileOVNNodeUpgrade{}
+
+// ReconcileOVNNodeUpgrade drives the conservative upgrades of ovnkube-node. When the
+// OVNNodeUpgradeModeAnnotation selects them, ovnkube-node is rendered with the OnDelete update
+// strategy, and its outdated pods are replaced one node at a time: the node is tainted so that no
+// new pod is scheduled on it, drained with evictions, which honor the pod disruption budgets, and
+// the ovnkube-node pod is only deleted once the evicted pods are gone. The taint is removed when
+// the new ovnkube-node pod is ready.
+type ReconcileOVNNodeUpgrade struct {
+	clientset kubernetes.Interface
+	status    *statusmanager.StatusManager
+
+	// untainted records the daemonsets, not upgraded conservatively, whose leftover upgrade taints
+	// were removed since the operator started or since they were last upgraded conservatively.
+	// The controller runs a single worker.
+	untainted map[string]bool
+}
+
+// Reconcile upgrades the next node of an ovnkube-node daemonset, if its update strategy is OnDelete
+func (r *ReconcileOVNNodeUpgrade) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	if request.Namespace != ovnNamespace || !isOVNNodeDaemonSet(request.Name) {
+		return reconcile.Result{}, nil
+	}
+	if !r.status.IsManaged() {
+		// the upgrade resumes, where it was left, once the operator is managed again
+		log.Printf("Operator configuration is %s, not upgrading DaemonSet %s", r.status.ManagementState(), request.NamespacedName)
+		return reconcile.Result{RequeueAfter: pollInterval}, nil
+	}
+	ds, err := r.clientset.AppsV1().DaemonSets(request.Namespace).Get(ctx, request.Name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		log.Printf("Unable to retrieve DaemonSet %s: %v", request.NamespacedName, err)
+		return reconcile.Result{}, err
+	}
+	if ds.Spec.UpdateStrategy.Type != appsv1.OnDeleteDaemonSetStrategyType {
+		// an interrupted conservative upgrade must not leave its node tainted. This is only checked
+		// once, not on every event of the daemonset.
+		if r.untainted[ds.Name] {
+			return reconcile.Result{}, nil
+		}
+		if err := r.removeUpgradeTaints(ctx); err != nil {
+			return reconcile.Result{}, err
+		}
+		r.untainted[ds.Name] = true
+		return reconcile.Result{}, nil
+	}
+	delete(r.untainted, ds.Name)
+
+	selector, err := metav1.LabelSelectorAsSelector(ds.Spec.Selector)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	pods, err := r.clientset.CoreV1().Pods(ds.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	revision, err := r.currentRevision(ctx, ds)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	inProgress, err := r.upgrade(ctx, ds, pods.Items, revision)
+	if err != nil {
+		log.Printf("Failed to upgrade DaemonSet %s: %v", request.NamespacedName, err)
+		return reconcile.Result{}, err
+	}
+	if inProgress {
+		return reconcile.Result{RequeueAfter: pollInterval}, nil
+	}
+	return reconcile.Result{}, nil
+}
+
+// currentRevision returns the hash of the latest revision of the daemonset template, as set in
+// the controller-revision-hash label of its pods
+func (r *ReconcileOVNNodeUpgrade) currentRevision(ctx context.Context, ds *appsv1.DaemonSet) (string, error) {
+	selector, err := metav1.LabelSelectorAsSelector(ds.Spec.Selector)
+	if err != nil {
+		return "", err
+	}
+	revisions, err := r.clientset.AppsV1().ControllerRevisions(ds.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return "", err
+	}
+	var latest *appsv1.ControllerRevision
+	for i, revision := range revisions.Items {
+		if !metav1.IsControlledBy(&revisions.Items[i], ds) {
+			continue
+		}
+		if latest == nil || revision.Revision > latest.Revision {
+			latest = &revisions.Items[i]
+		}
+	}
+	if latest == nil {
+		return "", nil
+	}
+	return latest.Labels[appsv1.DefaultDaemonSetUniqueLabelKey], nil
+}
+
+// upgrade moves the conservative upgrade of a daemonset one step forward, and reports whether
+// it is still in progress. Only one node is upgraded at a time, across the daemonsets.
+func (r *ReconcileOVNNodeUpgrade) upgrade(ctx context.Context, ds *appsv1.DaemonSet, pods []corev1.Pod, revision string) (bool, error) {
+	if revision == "" {
+		// the daemonset controller has not recorded the revision yet
+		return true, nil
+	}
+	nodes, err := r.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return false, err
+	}
+	podsByNode := map[string]*corev1.Pod{}
+	for i := range pods {
+		podsByNode[pods[i].Spec.NodeName] = &pods[i]
+	}
+	outdated := func(pod *corev1.Pod) bool {
+		return pod.Labels[appsv1.DefaultDaemonSetUniqueLabelKey] != revision
+	}
+
+	// finish upgrading the tainted node, if any, before moving to the next one
+	for _, node := range nodes.Items {
+		if !hasUpgradeTaint(&node) {
+			continue
+		}
+		pod, ok := podsByNode[node.Name]
+		if !ok {
+			// the node is being upgraded for another daemonset, or its pod is being recreated
+			return true, nil
+		}
+		if pod.DeletionTimestamp != nil {
+			return true, nil
+		}
+		if outdated(pod) {
+			drained, err := r.drainNode(ctx, node.Name)
+			if err != nil || !drained {
+				return true, err
+			}
+			log.Printf("Node %s is drained, restarting %s/%s", node.Name, pod.Namespace, pod.Name)
+			if err := r.clientset.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+				return true, err
+			}
+			return true, nil
+		}
+		if !isPodReady(pod) {
+			return true, nil
+		}
+		log.Printf("%s/%s is upgraded, uncordoning node %s", pod.Namespace, pod.Name, node.Name)
+		if err := r.setUpgradeTaint(ctx, node.Name, false); err != nil {
+			return true, err
+		}
+	}
+
+	// then taint the next node with an outdated pod
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Spec.NodeName < pods[j].Spec.NodeName })
+	for i := range pods {
+		if outdated(&pods[i]) {
+			log.Printf("Upgrading %s/%s conservatively, cordoning node %s", ds.Namespace, ds.Name, pods[i].Spec.NodeName)
+			return true, r.setUpgradeTaint(ctx, pods[i].Spec.NodeName, true)
+		}
+	}
+	return false, nil
+}
+
+// drainNode evicts the pods of a node, except the pods of daemonsets, the static pods and the pods
+// no controller owns, which would be lost, and reports whether the evicted pods are gone. Evictions blocked by a pod disruption budget are retried
+// on the next reconciliation.
+func (r *ReconcileOVNNodeUpgrade) drainNode(ctx context.Context, nodeName string) (bool, error) {
+	pods, err := r.clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+	})
+	if err != nil {
+		return false, err
+	}
+	drained := true
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != nodeName || !isEvictable(&pod) {
+			continue
+		}
+		drained = false
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		err := r.clientset.CoreV1().Pods(pod.Namespace).EvictV1(ctx, &policyv1.Eviction{
+			ObjectMeta: metav1.ObjectMeta{Namespace: pod.Namespace, Name: pod.Name},
+		})
+		if apierrors.IsTooManyRequests(err) {
+			log.Printf("Eviction of %s/%s is blocked by a disruption budget, retrying", pod.Namespace, pod.Name)
+		} else if err != nil && !apierrors.IsNotFound(err) {
+			return false, err
+		}
+	}
+	return drained, nil
+}
+
+// isEvictable returns true if a pod is drained from its node: it is running, and owned by a
+// controller other than a daemonset, which recreates it elsewhere. Like kubectl drain without
+// --force, the pods no controller owns are left on the node, as nothing would recreate them.
+func isEvictable(pod *corev1.Pod) bool {
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return false
+	}
+	if _, ok := pod.Annotations[corev1.MirrorPodAnnotationKey]; ok {
+		return false
+	}
+	owner := metav1.GetControllerOf(pod)
+	return owner != nil && owner.Kind != "DaemonSet"
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func hasUpgradeTaint(node *corev1.Node) bool {
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == names.OVNNodeUpgradeTaint {
+			return true
+		}
+	}
+	return false
+}
+
+// removeUpgradeTaints removes the upgrade taint of all the nodes
+func (r *ReconcileOVNNodeUpgrade) removeUpgradeTaints(ctx context.Context) error {
+	nodes, err := r.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range nodes.Items {
+		if hasUpgradeTaint(&nodes.Items[i]) {
+			log.Printf("Removing the leftover upgrade taint of node %s", nodes.Items[i].Name)
+			if err := r.setUpgradeTaint(ctx, nodes.Items[i].Name, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setUpgradeTaint adds or removes the upgrade taint of a node
+func (r *ReconcileOVNNodeUpgrade) setUpgradeTaint(ctx context.Context, nodeName string, taint bool) error {
+	node, err := r.clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if hasUpgradeTaint(node) == taint {
+		return nil
+	}
+	if taint {
+		node.Spec.Taints = append(node.Spec.Taints, corev1.Taint{
+			Key:    names.OVNNodeUpgradeTaint,
+			Effect: corev1.TaintEffectNoSchedule,
+		})
+	} else {
+		taints := []corev1.Taint{}
+		for _, t := range node.Spec.Taints {
+			if t.Key != names.OVNNodeUpgradeTaint {
+				taints = append(taints, t)
+			}
+		}
+		node.Spec.Taints = taints
+	}
+	_, err = r.clientset.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
+	return err
+}
@@ -0,0 +1,187 @@
+package ovnnodeupgrade
+
+import (
+	"context"
+	"testing"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/controller/statusmanager"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcileOVNNodeUpgrade(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ovnNamespace, Name: "ovnkube-node", UID: "ds-uid"},
+		Spec: appsv1.DaemonSetSpec{
+			Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": "ovnkube-node"}},
+			UpdateStrategy: appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType},
+		},
+	}
+	isController := true
+	dsOwner := []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "DaemonSet", Name: ds.Name, UID: ds.UID, Controller: &isController}}
+	revision := func(name string, rev int64) *appsv1.ControllerRevision {
+		return &appsv1.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       ovnNamespace,
+				Name:            name,
+				Labels:          map[string]string{"app": "ovnkube-node", appsv1.DefaultDaemonSetUniqueLabelKey: name},
+				OwnerReferences: dsOwner,
+			},
+			Revision: rev,
+		}
+	}
+	ovnPod := func(node, hash string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       ovnNamespace,
+				Name:            "ovnkube-node-" + node,
+				Labels:          map[string]string{"app": "ovnkube-node", appsv1.DefaultDaemonSetUniqueLabelKey: hash},
+				OwnerReferences: dsOwner,
+			},
+			Spec: corev1.PodSpec{NodeName: node},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+	workload := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "workload", OwnerReferences: []metav1.OwnerReference{
+			{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "workload", UID: "rs-uid", Controller: &isController},
+		}},
+		Spec: corev1.PodSpec{NodeName: "node-a"},
+	}
+	bare := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "bare"},
+		Spec:       corev1.PodSpec{NodeName: "node-a"},
+	}
+	static := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "static", Annotations: map[string]string{corev1.MirrorPodAnnotationKey: ""}},
+		Spec:       corev1.PodSpec{NodeName: "node-a"},
+	}
+
+	clientset := fake.NewSimpleClientset(ds,
+		revision("old", 1), revision("new", 2),
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-b"}},
+		ovnPod("node-a", "old"), ovnPod("node-b", "old"),
+		workload, static, bare,
+	)
+	status := statusmanager.New(nil, nil, "testing")
+	r := &ReconcileOVNNodeUpgrade{clientset: clientset, status: status, untainted: map[string]bool{}}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: ovnNamespace, Name: "ovnkube-node"}}
+	reconcileStep := func() reconcile.Result {
+		t.Helper()
+		result, err := r.Reconcile(context.TODO(), request)
+		g.Expect(err).NotTo(HaveOccurred())
+		return result
+	}
+	tainted := func() []string {
+		t.Helper()
+		nodes, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+		out := []string{}
+		for i := range nodes.Items {
+			if hasUpgradeTaint(&nodes.Items[i]) {
+				out = append(out, nodes.Items[i].Name)
+			}
+		}
+		return out
+	}
+	evictions := func() []string {
+		out := []string{}
+		for _, action := range clientset.Actions() {
+			if action.GetVerb() == "create" && action.GetSubresource() == "eviction" {
+				out = append(out, action.(clienttesting.CreateAction).GetObject().(metav1.Object).GetName())
+			}
+		}
+		return out
+	}
+
+	// nothing is changed while the operator is Unmanaged
+	status.SetManagementState(operv1.Unmanaged)
+	g.Expect(reconcileStep().RequeueAfter).To(Equal(pollInterval))
+	g.Expect(tainted()).To(BeEmpty())
+	status.SetManagementState(operv1.Managed)
+
+	// the first node is tainted
+	g.Expect(reconcileStep().RequeueAfter).To(Equal(pollInterval))
+	g.Expect(tainted()).To(Equal([]string{"node-a"}))
+
+	// then drained, except for the static pods and the pods no controller owns; the ovnkube-node pod is kept until the workload is gone
+	reconcileStep()
+	g.Expect(evictions()).To(Equal([]string{"workload"}))
+	_, err := clientset.CoreV1().Pods(ovnNamespace).Get(context.TODO(), "ovnkube-node-node-a", metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// once drained, the ovnkube-node pod is restarted
+	g.Expect(clientset.CoreV1().Pods("app").Delete(context.TODO(), "workload", metav1.DeleteOptions{})).To(Succeed())
+	reconcileStep()
+	_, err = clientset.CoreV1().Pods(ovnNamespace).Get(context.TODO(), "ovnkube-node-node-a", metav1.GetOptions{})
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(tainted()).To(Equal([]string{"node-a"}))
+
+	// the new pod is not ready yet
+	newPod := ovnPod("node-a", "new")
+	newPod.Status.Conditions = nil
+	newPod, err = clientset.CoreV1().Pods(ovnNamespace).Create(context.TODO(), newPod, metav1.CreateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	reconcileStep()
+	g.Expect(tainted()).To(Equal([]string{"node-a"}))
+
+	// once it is, the next node is upgraded
+	newPod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	_, err = clientset.CoreV1().Pods(ovnNamespace).Update(context.TODO(), newPod, metav1.UpdateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	reconcileStep()
+	g.Expect(tainted()).To(Equal([]string{"node-b"}))
+
+	// switching back to rolling updates removes the taint
+	ds.Spec.UpdateStrategy.Type = appsv1.RollingUpdateDaemonSetStrategyType
+	_, err = clientset.AppsV1().DaemonSets(ovnNamespace).Update(context.TODO(), ds, metav1.UpdateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(reconcileStep()).To(Equal(reconcile.Result{}))
+	g.Expect(tainted()).To(BeEmpty())
+
+	// which is only checked once
+	clientset.ClearActions()
+	g.Expect(reconcileStep()).To(Equal(reconcile.Result{}))
+	for _, action := range clientset.Actions() {
+		g.Expect(action.GetResource().Resource).NotTo(Equal("nodes"))
+	}
+}
+
+func TestSetUpgradeTaint(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	other := corev1.Taint{Key: "other", Effect: corev1.TaintEffectNoExecute}
+	clientset := fake.NewSimpleClientset(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node"},
+		Spec:       corev1.NodeSpec{Taints: []corev1.Taint{other}},
+	})
+	r := &ReconcileOVNNodeUpgrade{clientset: clientset, status: statusmanager.New(nil, nil, "testing")}
+
+	g.Expect(r.setUpgradeTaint(context.TODO(), "node", true)).To(Succeed())
+	g.Expect(r.setUpgradeTaint(context.TODO(), "node", true)).To(Succeed())
+	node, err := clientset.CoreV1().Nodes().Get(context.TODO(), "node", metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(node.Spec.Taints).To(Equal([]corev1.Taint{other, {Key: names.OVNNodeUpgradeTaint, Effect: corev1.TaintEffectNoSchedule}}))
+
+	g.Expect(r.setUpgradeTaint(context.TODO(), "node", false)).To(Succeed())
+	node, err = clientset.CoreV1().Nodes().Get(context.TODO(), "node", metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(node.Spec.Taints).To(Equal([]corev1.Taint{other}))
+
+	// missing nodes are ignored
+	g.Expect(r.setUpgradeTaint(context.TODO(), "missing", true)).To(Succeed())
+}
@@ -0,0 +1,226 @@
+package ovntopology
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
+	"github.com/openshift/cluster-network-operator/pkg/controller/statusmanager"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	k8sutil "github.com/openshift/cluster-network-operator/pkg/util/k8s"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	ovnNamespace = "openshift-ovn-kubernetes"
+	// the nbdb container of ovnkube-master serves the NB database on a local socket
+	nbdbContainer = "nbdb"
+)
+
+// The periodic resync interval.
+// We will re-summarize the topology, even if the network configuration
+// hasn't changed.
+var ResyncPeriod = 5 * time.Minute
+
+// Add creates a new ovn-topology controller and adds it to the Manager. The Manager will set fields on the
+// Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager, status *statusmanager.StatusManager) error {
+	// We need a clientset in order to exec into pods, the controller-runtime client does not
+	// support the exec subresource
+	clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+	r := &ReconcileOVNTopology{client: mgr.GetClient(), status: status}
+	r.exec = func(ctx context.Context, pod *corev1.Pod, container string, command []string) (string, error) {
+		return k8sutil.ExecInPod(mgr.GetConfig(), clientset, pod, container, command)
+	}
+	return add(mgr, r)
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileOVNTopology) error {
+	c, err := controller.New("ovn-topology-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	// Watch the operator configuration, the topology is then summarized periodically
+	return c.Watch(&source.Kind{Type: &operv1.Network{}}, &handler.EnqueueRequestForObject{})
+}
+
+var _ reconcile.Reconciler = &ReconcileOVNTopology{}
+
+// ReconcileOVNTopology periodically summarizes the logical topology of the OVN NB database into the
+// status of the NetworkTopology "cluster", so that the scale drivers of the OVN control plane can be
+// seen without running ovn-nbctl in the ovnkube-master pods.
+type ReconcileOVNTopology struct {
+	client client.Client
+	status *statusmanager.StatusManager
+	// exec runs a command in a container of a pod, and returns its output
+	exec func(ctx context.Context, pod *corev1.Pod, container string, command []string) (string, error)
+}
+
+// Reconcile summarizes the OVN logical topology
+func (r *ReconcileOVNTopology) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	if request.Name != names.OPERATOR_CONFIG {
+		return reconcile.Result{}, nil
+	}
+	if !r.status.IsManaged() {
+		log.Printf("Operator configuration is %s, not updating the NetworkTopology", r.status.ManagementState())
+		return reconcile.Result{RequeueAfter: ResyncPeriod}, nil
+	}
+	operConfig := &operv1.Network{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: names.OPERATOR_CONFIG}, operConfig); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		log.Printf("Unable to retrieve Network.operator.openshift.io object: %v", err)
+		return reconcile.Result{}, err
+	}
+	if operConfig.Spec.DefaultNetwork.Type != operv1.NetworkTypeOVNKubernetes {
+		return reconcile.Result{}, nil
+	}
+
+	pod, err := r.nbdbPod(ctx)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if pod == nil {
+		log.Printf("No ready ovnkube-master pod, retrying to summarize the OVN topology")
+		return reconcile.Result{RequeueAfter: ResyncPeriod}, nil
+	}
+	nodes := &corev1.NodeList{}
+	if err := r.client.List(ctx, nodes); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	topology, err := r.summarize(ctx, pod, nodes.Items)
+	if err != nil {
+		log.Printf("Failed to summarize the OVN topology through pod %s: %v", pod.Name, err)
+		return reconcile.Result{}, err
+	}
+	if err := r.updateTopology(ctx, topology); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{RequeueAfter: ResyncPeriod}, nil
+}
+
+// nbdbPod returns a ready ovnkube-master pod whose nbdb container is ready, if any
+func (r *ReconcileOVNTopology) nbdbPod(ctx context.Context) (*corev1.Pod, error) {
+	pods := &corev1.PodList{}
+	if err := r.client.List(ctx, pods, client.InNamespace(ovnNamespace), client.MatchingLabels{"app": "ovnkube-master"}); err != nil {
+		return nil, err
+	}
+	for i, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name == nbdbContainer && status.Ready {
+				return &pods.Items[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// listCommand returns the command listing a column of the rows of a table of the NB database, one row per line
+func listCommand(table string, columns string) []string {
+	return []string{"ovn-nbctl", "--no-leader-only", "--format=csv", "--no-headings", "--data=bare",
+		"--columns=" + columns, "list", table}
+}
+
+// summarize counts the objects of the NB database. The logical switch of a node is named after it.
+func (r *ReconcileOVNTopology) summarize(ctx context.Context, pod *corev1.Pod, nodes []corev1.Node) (*netopv1.NetworkTopologyStatus, error) {
+	topology := &netopv1.NetworkTopologyStatus{}
+	for _, count := range []struct {
+		table string
+		into  *int32
+	}{
+		{"Logical_Router", &topology.LogicalRouters},
+		{"Load_Balancer", &topology.LoadBalancers},
+		{"ACL", &topology.ACLs},
+		{"Logical_Switch_Port", &topology.LogicalSwitchPorts},
+	} {
+		out, err := r.exec(ctx, pod, nbdbContainer, listCommand(count.table, "_uuid"))
+		if err != nil {
+			return nil, err
+		}
+		*count.into = int32(len(splitRows(out)))
+	}
+
+	out, err := r.exec(ctx, pod, nbdbContainer, listCommand("Logical_Switch", "name,ports"))
+	if err != nil {
+		return nil, err
+	}
+	switchPorts, err := parseSwitchPorts(out)
+	if err != nil {
+		return nil, err
+	}
+	topology.LogicalSwitches = int32(len(switchPorts))
+	for _, node := range nodes {
+		if ports, ok := switchPorts[node.Name]; ok {
+			topology.Nodes = append(topology.Nodes, netopv1.NodeTopology{Name: node.Name, LogicalSwitchPorts: int32(ports)})
+		}
+	}
+	sort.Slice(topology.Nodes, func(i, j int) bool { return topology.Nodes[i].Name < topology.Nodes[j].Name })
+	return topology, nil
+}
+
+// splitRows returns the non-empty lines of the output of ovn-nbctl
+func splitRows(out string) []string {
+	rows := []string{}
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			rows = append(rows, line)
+		}
+	}
+	return rows
+}
+
+// parseSwitchPorts parses the "<name>,<port uuid> <port uuid>..." rows of the logical switches into their
+// number of ports, by name
+func parseSwitchPorts(out string) (map[string]int, error) {
+	switchPorts := map[string]int{}
+	for _, row := range splitRows(out) {
+		parts := strings.SplitN(row, ",", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("unexpected logical switch row %q", row)
+		}
+		switchPorts[strings.Trim(parts[0], `"`)] = len(strings.Fields(strings.Trim(parts[1], `"`)))
+	}
+	return switchPorts, nil
+}
+
+// updateTopology sets the status of the NetworkTopology "cluster", which is created if it does not exist
+func (r *ReconcileOVNTopology) updateTopology(ctx context.Context, topology *netopv1.NetworkTopologyStatus) error {
+	obj := &netopv1.NetworkTopology{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: names.OPERATOR_CONFIG}, obj); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		obj = &netopv1.NetworkTopology{ObjectMeta: metav1.ObjectMeta{Name: names.OPERATOR_CONFIG}}
+		if err := r.client.Create(ctx, obj); err != nil {
+			return err
+		}
+	}
+	topology.LastUpdateTime = metav1.Now()
+	obj.Status = *topology
+	return r.client.Status().Update(ctx, obj)
+}
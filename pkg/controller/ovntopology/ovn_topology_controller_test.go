@@ -0,0 +1,103 @@
+package ovntopology
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	operv1 "github.com/openshift/api/operator/v1"
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
+	"github.com/openshift/cluster-network-operator/pkg/controller/statusmanager"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestParseSwitchPorts(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	switchPorts, err := parseSwitchPorts("node-1,\"a8d4 77e1 0c3f\"\njoin,1b2c\n\next_node-1,\"\"\n")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(switchPorts).To(Equal(map[string]int{"node-1": 3, "join": 1, "ext_node-1": 0}))
+
+	_, err = parseSwitchPorts("node-1")
+	g.Expect(err).To(MatchError(ContainSubstring("unexpected logical switch row")))
+}
+
+func TestReconcileOVNTopology(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	g.Expect(operv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(netopv1.Install(scheme)).To(Succeed())
+
+	operConfig := &operv1.Network{
+		ObjectMeta: metav1.ObjectMeta{Name: names.OPERATOR_CONFIG},
+		Spec:       operv1.NetworkSpec{DefaultNetwork: operv1.DefaultNetworkDefinition{Type: operv1.NetworkTypeOVNKubernetes}},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ovnNamespace, Name: "ovnkube-master-abcde", Labels: map[string]string{"app": "ovnkube-master"}},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{Name: nbdbContainer, Ready: true}},
+		},
+	}
+	nodes := []*corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(operConfig, pod, nodes[0], nodes[1]).Build()
+	tables := map[string]string{
+		"Logical_Router":      "r1\n",
+		"Load_Balancer":       "lb1\nlb2\nlb3\n",
+		"ACL":                 "",
+		"Logical_Switch_Port": "p1\np2\np3\np4\np5\n",
+		"Logical_Switch":      "node-1,\"p1 p2\"\nnode-2,p3\njoin,\"p4 p5\"\n",
+	}
+	r := &ReconcileOVNTopology{
+		client: client,
+		status: statusmanager.New(nil, nil, "testing"),
+		exec: func(_ context.Context, p *corev1.Pod, container string, command []string) (string, error) {
+			g.Expect(p.Name).To(Equal(pod.Name))
+			g.Expect(container).To(Equal(nbdbContainer))
+			g.Expect(strings.Join(command, " ")).To(HavePrefix("ovn-nbctl --no-leader-only"))
+			return tables[command[len(command)-1]], nil
+		},
+	}
+	r.status.SetManagementState(operv1.Managed)
+
+	result, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: names.OPERATOR_CONFIG}})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(ResyncPeriod))
+
+	topology := &netopv1.NetworkTopology{}
+	g.Expect(client.Get(context.TODO(), types.NamespacedName{Name: names.OPERATOR_CONFIG}, topology)).To(Succeed())
+	g.Expect(topology.Status.LogicalSwitches).To(BeEquivalentTo(3))
+	g.Expect(topology.Status.LogicalSwitchPorts).To(BeEquivalentTo(5))
+	g.Expect(topology.Status.LogicalRouters).To(BeEquivalentTo(1))
+	g.Expect(topology.Status.LoadBalancers).To(BeEquivalentTo(3))
+	g.Expect(topology.Status.ACLs).To(BeZero())
+	g.Expect(topology.Status.Nodes).To(Equal([]netopv1.NodeTopology{
+		{Name: "node-1", LogicalSwitchPorts: 2},
+		{Name: "node-2", LogicalSwitchPorts: 1},
+	}))
+	g.Expect(topology.Status.LastUpdateTime.IsZero()).To(BeFalse())
+
+	// the topology is only summarized with OVNKubernetes
+	g.Expect(client.Get(context.TODO(), types.NamespacedName{Name: names.OPERATOR_CONFIG}, operConfig)).To(Succeed())
+	operConfig.Spec.DefaultNetwork.Type = operv1.NetworkTypeOpenShiftSDN
+	g.Expect(client.Update(context.TODO(), operConfig)).To(Succeed())
+	r.exec = func(context.Context, *corev1.Pod, string, []string) (string, error) {
+		t.Fatal("unexpected exec")
+		return "", nil
+	}
+	result, err = r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: names.OPERATOR_CONFIG}})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(BeZero())
+}
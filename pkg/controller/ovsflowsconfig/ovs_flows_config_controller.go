@@ -0,0 +1,100 @@
+package ovsflowsconfig
+
+import (
+	"context"
+	"log"
+
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
+	"github.com/openshift/cluster-network-operator/pkg/controller/statusmanager"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/openshift/cluster-network-operator/pkg/network"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// Add creates a new OVS flows config controller and adds it to the Manager. The Manager will set fields on the
+// Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager, status *statusmanager.StatusManager) error {
+	return add(mgr, &ReconcileOVSFlowsConfig{client: mgr.GetClient(), status: status})
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileOVSFlowsConfig) error {
+	c, err := controller.New("ovs-flows-config-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	// Watch the legacy ovs-flows-config ConfigMap
+	return c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestForObject{},
+		predicate.NewPredicateFuncs(func(object client.Object) bool {
+			return object.GetName() == network.OVSFlowsConfigMapName && object.GetNamespace() == network.OVSFlowsConfigNamespace
+		}),
+	)
+}
+
+var _ reconcile.Reconciler = &ReconcileOVSFlowsConfig{}
+
+// ReconcileOVSFlowsConfig converts the legacy ovs-flows-config ConfigMap to the OVSFlowsConfig superseding it,
+// once, and marks the ConfigMap as converted.
+type ReconcileOVSFlowsConfig struct {
+	client client.Client
+	status *statusmanager.StatusManager
+}
+
+// Reconcile converts the ovs-flows-config ConfigMap
+func (r *ReconcileOVSFlowsConfig) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	if !r.status.IsManaged() {
+		log.Printf("Operator configuration is %s, not converting ConfigMap %s", r.status.ManagementState(), request.NamespacedName)
+		return reconcile.Result{RequeueAfter: statusmanager.UnmanagedRetryInterval}, nil
+	}
+	cm := &corev1.ConfigMap{}
+	if err := r.client.Get(ctx, request.NamespacedName, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	if cm.Annotations[names.OVSFlowsConfigConvertedAnnotation] == "true" {
+		return reconcile.Result{}, nil
+	}
+
+	fc := &netopv1.OVSFlowsConfig{}
+	err := r.client.Get(ctx, request.NamespacedName, fc)
+	switch {
+	case err == nil:
+		log.Printf("OVSFlowsConfig %s already exists, the %s ConfigMap is superseded", request.NamespacedName, request.Name)
+	case apierrors.IsNotFound(err):
+		spec := network.OVSFlowsConfigSpecFromConfigMap(cm)
+		if spec == nil {
+			// wait for the ConfigMap to be fixed
+			log.Printf("Not converting the invalid %s ConfigMap to an OVSFlowsConfig", request.NamespacedName)
+			return reconcile.Result{}, nil
+		}
+		fc = &netopv1.OVSFlowsConfig{
+			ObjectMeta: metav1.ObjectMeta{Namespace: cm.Namespace, Name: cm.Name},
+			Spec:       *spec,
+		}
+		if err := r.client.Create(ctx, fc); err != nil && !apierrors.IsAlreadyExists(err) {
+			return reconcile.Result{}, err
+		}
+		log.Printf("Converted the %s ConfigMap to an OVSFlowsConfig", request.NamespacedName)
+	default:
+		return reconcile.Result{}, err
+	}
+
+	patch := client.MergeFrom(cm.DeepCopy())
+	if cm.Annotations == nil {
+		cm.Annotations = map[string]string{}
+	}
+	cm.Annotations[names.OVSFlowsConfigConvertedAnnotation] = "true"
+	return reconcile.Result{}, r.client.Patch(ctx, cm, patch)
+}
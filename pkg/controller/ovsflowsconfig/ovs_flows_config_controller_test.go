@@ -0,0 +1,73 @@
+package ovsflowsconfig
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	operv1 "github.com/openshift/api/operator/v1"
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
+	"github.com/openshift/cluster-network-operator/pkg/controller/statusmanager"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/openshift/cluster-network-operator/pkg/network"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcileOVSFlowsConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	g.Expect(netopv1.Install(scheme)).To(Succeed())
+
+	nsn := types.NamespacedName{Namespace: network.OVSFlowsConfigNamespace, Name: network.OVSFlowsConfigMapName}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: nsn.Namespace, Name: nsn.Name},
+		Data:       map[string]string{"nodePort": "http"},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cm).Build()
+	status := statusmanager.New(nil, nil, "testing")
+	r := &ReconcileOVSFlowsConfig{client: client, status: status}
+
+	// an invalid ConfigMap is not converted
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: nsn})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(apierrors.IsNotFound(client.Get(context.TODO(), nsn, &netopv1.OVSFlowsConfig{}))).To(BeTrue())
+
+	// until it is fixed
+	g.Expect(client.Get(context.TODO(), nsn, cm)).To(Succeed())
+	cm.Data = map[string]string{"sharedTarget": "1.2.3.4:3030", "sampling": "100"}
+	g.Expect(client.Update(context.TODO(), cm)).To(Succeed())
+
+	// while the operator is Unmanaged, the ConfigMap is left as it is
+	status.SetManagementState(operv1.Unmanaged)
+	_, err = r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: nsn})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(apierrors.IsNotFound(client.Get(context.TODO(), nsn, &netopv1.OVSFlowsConfig{}))).To(BeTrue())
+	status.SetManagementState(operv1.Managed)
+
+	_, err = r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: nsn})
+	g.Expect(err).NotTo(HaveOccurred())
+	fc := &netopv1.OVSFlowsConfig{}
+	g.Expect(client.Get(context.TODO(), nsn, fc)).To(Succeed())
+	g.Expect(fc.Spec.SharedTarget).To(Equal("1.2.3.4:3030"))
+	g.Expect(*fc.Spec.Sampling).To(BeEquivalentTo(100))
+	g.Expect(client.Get(context.TODO(), nsn, cm)).To(Succeed())
+	g.Expect(cm.Annotations).To(HaveKeyWithValue(names.OVSFlowsConfigConvertedAnnotation, "true"))
+
+	// the converted ConfigMap no longer applies, nor is converted again
+	g.Expect(client.Delete(context.TODO(), fc)).To(Succeed())
+	_, err = r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: nsn})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(apierrors.IsNotFound(client.Get(context.TODO(), nsn, &netopv1.OVSFlowsConfig{}))).To(BeTrue())
+	spec, err := network.GetOVSFlowsConfigSpec(context.TODO(), client)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(spec).To(BeNil())
+}
@@ -101,6 +101,10 @@ func newPKIReconciler(mgr manager.Manager, status *statusmanager.StatusManager)
 // Reconcile configures a CertRotationController from a PKI object
 func (r *PKIReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
 	log.Printf("Reconciling pki.network.operator.openshift.io %s\n", request.NamespacedName)
+	if !r.status.IsManaged() {
+		log.Printf("Operator configuration is %s, not rotating the certificates of %s", r.status.ManagementState(), request.NamespacedName)
+		return reconcile.Result{RequeueAfter: ResyncPeriod}, nil
+	}
 
 	obj := &netopv1.OperatorPKI{}
 	err := r.mgr.GetClient().Get(ctx, request.NamespacedName, obj)
@@ -147,6 +151,15 @@ func (r *PKIReconciler) Reconcile(ctx context.Context, request reconcile.Request
 	log.Println("successful reconciliation")
 	delete(r.pkiErrs, request.NamespacedName)
 	r.setStatus()
+
+	// the pods started with a previous certificate are restarted one at a time
+	pending, err := restartStalePods(ctx, r.clientset, obj)
+	if err != nil {
+		log.Printf("Failed to restart the pods using the certificate of PKI %s: %v", request.NamespacedName, err)
+	}
+	if pending {
+		return reconcile.Result{RequeueAfter: RestartInterval}, nil
+	}
 	return reconcile.Result{RequeueAfter: ResyncPeriod}, nil
 }
 
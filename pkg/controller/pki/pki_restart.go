@@ -0,0 +1,103 @@
+package pki
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
+
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// The interval between two restarts of the pods using a rotated certificate.
+// A single pod is restarted at a time, once the pods using the certificate are
+// all ready again, so that the OVN raft clusters keep their quorum.
+var RestartInterval = 30 * time.Second
+
+// restartSkew covers the truncation of the NotBefore annotation to the second,
+// and the second library-go backdates the certificates by
+const restartSkew = 2 * time.Second
+
+// restartStalePods restarts a pod mounting the certificate or the CA bundle of the
+// PKI that was created before the certificate was last issued, as some components,
+// like ovn-controller, only load their certificates when they start. It returns
+// whether pods are left to restart.
+func restartStalePods(ctx context.Context, clientset kubernetes.Interface, config *netopv1.OperatorPKI) (bool, error) {
+	certName := config.Name + "-cert"
+	caName := config.Name + "-ca"
+
+	secret, err := clientset.CoreV1().Secrets(config.Namespace).Get(ctx, certName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	notBefore, err := time.Parse(time.RFC3339, secret.Annotations[certrotation.CertificateNotBeforeAnnotation])
+	if err != nil {
+		// not issued by the PKI
+		return false, nil
+	}
+
+	pods, err := clientset.CoreV1().Pods(config.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return false, err
+	}
+	stale := []corev1.Pod{}
+	for _, pod := range pods.Items {
+		if !mountsPKI(&pod, certName, caName) {
+			continue
+		}
+		if pod.DeletionTimestamp != nil || !podReady(&pod) {
+			// wait for the previous restart to complete
+			return true, nil
+		}
+		if pod.CreationTimestamp.Time.Before(notBefore.Add(restartSkew)) {
+			stale = append(stale, pod)
+		}
+	}
+	if len(stale) == 0 {
+		return false, nil
+	}
+
+	sort.Slice(stale, func(i, j int) bool {
+		return stale[i].CreationTimestamp.Before(&stale[j].CreationTimestamp)
+	})
+	pod := stale[0]
+	log.Printf("Restarting pod %s/%s to load the certificate %s issued at %s (%d pods left)",
+		pod.Namespace, pod.Name, certName, notBefore.Format(time.RFC3339), len(stale)-1)
+	if err := clientset.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return true, err
+	}
+	return true, nil
+}
+
+// mountsPKI returns whether the pod mounts the certificate Secret or the CA bundle ConfigMap
+func mountsPKI(pod *corev1.Pod, certName, caName string) bool {
+	for _, v := range pod.Spec.Volumes {
+		if v.Secret != nil && v.Secret.SecretName == certName {
+			return true
+		}
+		if v.ConfigMap != nil && v.ConfigMap.Name == caName {
+			return true
+		}
+	}
+	return false
+}
+
+// podReady returns whether the pod is running and ready
+func podReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
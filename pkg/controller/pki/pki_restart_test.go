@@ -0,0 +1,86 @@
+package pki
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
+	"github.com/openshift/library-go/pkg/operator/certrotation"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func pkiPod(name string, created time.Time, ready bool, volume corev1.VolumeSource) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ovn-kubernetes", Name: name, CreationTimestamp: metav1.NewTime(created)},
+		Spec:       corev1.PodSpec{Volumes: []corev1.Volume{{Name: "pki", VolumeSource: volume}}},
+		Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}}},
+	}
+}
+
+func TestRestartStalePods(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	rotated := time.Now().Add(-time.Hour).Truncate(time.Second)
+	config := &netopv1.OperatorPKI{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ovn-kubernetes", Name: "ovn"}}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "openshift-ovn-kubernetes",
+		Name:        "ovn-cert",
+		Annotations: map[string]string{certrotation.CertificateNotBeforeAnnotation: rotated.Format(time.RFC3339)},
+	}}
+	cert := corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "ovn-cert"}}
+	ca := corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "ovn-ca"}}}
+	other := corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}
+
+	clientset := fake.NewSimpleClientset(
+		secret,
+		pkiPod("ovnkube-node-a", rotated.Add(-2*time.Hour), true, cert),
+		pkiPod("ovnkube-master-a", rotated.Add(-3*time.Hour), true, ca),
+		pkiPod("ovnkube-node-b", rotated.Add(time.Minute), true, cert),
+		pkiPod("unrelated", rotated.Add(-time.Hour), true, other),
+	)
+	podNames := func() []string {
+		pods, err := clientset.CoreV1().Pods("openshift-ovn-kubernetes").List(context.TODO(), metav1.ListOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+		names := []string{}
+		for _, pod := range pods.Items {
+			names = append(names, pod.Name)
+		}
+		return names
+	}
+
+	// the oldest pod started with the previous certificate is restarted first
+	pending, err := restartStalePods(context.TODO(), clientset, config)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pending).To(BeTrue())
+	g.Expect(podNames()).To(ConsistOf("ovnkube-node-a", "ovnkube-node-b", "unrelated"))
+
+	// then the next one, once its replacement is ready
+	replacement := pkiPod("ovnkube-master-b", time.Now(), false, ca)
+	_, err = clientset.CoreV1().Pods("openshift-ovn-kubernetes").Create(context.TODO(), replacement, metav1.CreateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	pending, err = restartStalePods(context.TODO(), clientset, config)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pending).To(BeTrue())
+	g.Expect(podNames()).To(ContainElement("ovnkube-node-a"))
+
+	replacement.Status.Conditions[0].Status = corev1.ConditionTrue
+	_, err = clientset.CoreV1().Pods("openshift-ovn-kubernetes").UpdateStatus(context.TODO(), replacement, metav1.UpdateOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	pending, err = restartStalePods(context.TODO(), clientset, config)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pending).To(BeTrue())
+	g.Expect(podNames()).To(ConsistOf("ovnkube-master-b", "ovnkube-node-b", "unrelated"))
+
+	// nothing is left to restart
+	pending, err = restartStalePods(context.TODO(), clientset, config)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pending).To(BeFalse())
+}
@@ -89,6 +89,10 @@ type ReconcileProxyConfig struct {
 // named "cluster" or a configmap object in namespace "openshift-config"
 // and will ensure either object is in the desired state.
 func (r *ReconcileProxyConfig) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	if !r.status.IsManaged() {
+		log.Printf("Operator configuration is %s, not reconciling %s", r.status.ManagementState(), request.NamespacedName)
+		return reconcile.Result{RequeueAfter: statusmanager.UnmanagedRetryInterval}, nil
+	}
 	validate := true
 	trustBundle := &corev1.ConfigMap{}
 
@@ -27,6 +27,12 @@ import (
 
 const signerName = "network.openshift.io/signer"
 
+// The Secret of the CA the certificate signing requests are signed with, created by the signer OperatorPKI
+const (
+	CASecretNamespace = "openshift-ovn-kubernetes"
+	CASecretName      = "signer-ca"
+)
+
 // Add controller and start it when the Manager is started.
 func Add(mgr manager.Manager, status *statusmanager.StatusManager) error {
 	reconciler, err := newReconciler(mgr, status)
@@ -149,7 +155,7 @@ func (r *ReconcileCSR) Reconcile(ctx context.Context, request reconcile.Request)
 
 	// Get our CA that was created by the operatorpki.
 	caSecret := &corev1.Secret{}
-	err = r.client.Get(ctx, types.NamespacedName{Namespace: "openshift-ovn-kubernetes", Name: "signer-ca"}, caSecret)
+	err = r.client.Get(ctx, types.NamespacedName{Namespace: CASecretNamespace, Name: CASecretName}, caSecret)
 	if err != nil {
 		signerFailure(r, csr, "CAFailure",
 			fmt.Sprintf("Could not get CA certificate and key: %v", err))
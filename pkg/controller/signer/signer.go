@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/pem"
 	"errors"
 	"fmt"
@@ -83,3 +84,28 @@ func decodePrivateKey(pemBytes []byte) (*rsa.PrivateKey, error) {
 
 	return x509.ParsePKCS1PrivateKey(block.Bytes)
 }
+
+// IssueCertificate generates a private key, and a certificate of it with the common name, signed by the CA
+// like the certificate signing requests of the signer are. They are returned in PEM format.
+func IssueCertificate(caCertPEM, caKeyPEM []byte, commonName string) (certPEM []byte, keyPEM []byte, err error) {
+	caCert, err := decodeCertificate(caCertPEM)
+	if err != nil {
+		return nil, nil, err
+	}
+	caKey, err := decodePrivateKey(caKeyPEM)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, nil, err
+	}
+	template := newCertificateTemplate(&x509.CertificateRequest{Subject: pkix.Name{CommonName: commonName}})
+	cert, err := signCSR(template, key.Public(), caCert, caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return certPEM, keyPEM, nil
+}
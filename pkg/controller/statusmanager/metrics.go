@@ -0,0 +1,74 @@
+package statusmanager
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+// daemonSetLabels are the labels of the rollout metrics of the managed DaemonSets
+var daemonSetLabels = []string{"namespace", "daemonset"}
+
+// The rollout metrics of the managed DaemonSets, as observed by SetFromPods, so that their
+// rollouts can be graphed over time
+var (
+	daemonSetDesiredNodes = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Name: "network_operator_daemonset_desired_nodes",
+		Help: "The number of nodes a managed DaemonSet should run on.",
+	}, daemonSetLabels)
+
+	daemonSetUpdatedNodes = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Name: "network_operator_daemonset_updated_nodes",
+		Help: "The number of nodes running the updated pod of a managed DaemonSet.",
+	}, daemonSetLabels)
+
+	daemonSetReadyNodes = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Name: "network_operator_daemonset_ready_nodes",
+		Help: "The number of nodes running a ready pod of a managed DaemonSet.",
+	}, daemonSetLabels)
+
+	daemonSetUnavailableNodes = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Name: "network_operator_daemonset_unavailable_nodes",
+		Help: "The number of nodes that should run an available pod of a managed DaemonSet and do not.",
+	}, daemonSetLabels)
+
+	daemonSetProgressing = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Name: "network_operator_daemonset_progressing",
+		Help: "1 when a managed DaemonSet is rolling out, 0 otherwise.",
+	}, daemonSetLabels)
+
+	daemonSetGauges = []*metrics.GaugeVec{
+		daemonSetDesiredNodes,
+		daemonSetUpdatedNodes,
+		daemonSetReadyNodes,
+		daemonSetUnavailableNodes,
+		daemonSetProgressing,
+	}
+)
+
+func init() {
+	for _, gauge := range daemonSetGauges {
+		legacyregistry.MustRegister(gauge)
+	}
+}
+
+// recordDaemonSetMetrics records the rollout of a managed DaemonSet
+func recordDaemonSetMetrics(ds *appsv1.DaemonSet, progressing bool) {
+	daemonSetDesiredNodes.WithLabelValues(ds.Namespace, ds.Name).Set(float64(ds.Status.DesiredNumberScheduled))
+	daemonSetUpdatedNodes.WithLabelValues(ds.Namespace, ds.Name).Set(float64(ds.Status.UpdatedNumberScheduled))
+	daemonSetReadyNodes.WithLabelValues(ds.Namespace, ds.Name).Set(float64(ds.Status.NumberReady))
+	daemonSetUnavailableNodes.WithLabelValues(ds.Namespace, ds.Name).Set(float64(ds.Status.NumberUnavailable))
+	value := 0.0
+	if progressing {
+		value = 1
+	}
+	daemonSetProgressing.WithLabelValues(ds.Namespace, ds.Name).Set(value)
+}
+
+// deleteDaemonSetMetrics removes the rollout metrics of a DaemonSet that is no longer managed
+func deleteDaemonSetMetrics(dsName types.NamespacedName) {
+	for _, gauge := range daemonSetGauges {
+		gauge.Delete(map[string]string{"namespace": dsName.Namespace, "daemonset": dsName.Name})
+	}
+}
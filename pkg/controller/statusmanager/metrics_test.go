@@ -0,0 +1,87 @@
+package statusmanager
+
+import (
+	"context"
+	"testing"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/testutil"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestStatusManagerDaemonSetMetrics(t *testing.T) {
+	client := fake.NewClientBuilder().Build()
+	status := New(client, &fakeRESTMapper{}, "testing")
+	no := &operv1.Network{ObjectMeta: metav1.ObjectMeta{Name: names.OPERATOR_CONFIG}}
+	if err := client.Create(context.TODO(), no); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "one", Name: "metrics"},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "metrics"}},
+		},
+		Status: appsv1.DaemonSetStatus{
+			DesiredNumberScheduled: 5,
+			UpdatedNumberScheduled: 3,
+			NumberReady:            4,
+			NumberAvailable:        4,
+			NumberUnavailable:      1,
+		},
+	}
+	if err := client.Create(context.TODO(), ds); err != nil {
+		t.Fatalf("error creating DaemonSet: %v", err)
+	}
+	dsName := types.NamespacedName{Namespace: "one", Name: "metrics"}
+	status.SetDaemonSets([]types.NamespacedName{dsName})
+
+	expectGauge := func(gauge *metrics.GaugeVec, expected float64) {
+		t.Helper()
+		value, err := testutil.GetGaugeMetricValue(gauge.WithLabelValues(dsName.Namespace, dsName.Name))
+		if err != nil {
+			t.Fatalf("error reading the metric: %v", err)
+		}
+		if value != expected {
+			t.Fatalf("unexpected metric value: expected %v, got %v", expected, value)
+		}
+	}
+
+	// the DaemonSet is rolling out
+	status.SetFromPods()
+	expectGauge(daemonSetDesiredNodes, 5)
+	expectGauge(daemonSetUpdatedNodes, 3)
+	expectGauge(daemonSetReadyNodes, 4)
+	expectGauge(daemonSetUnavailableNodes, 1)
+	expectGauge(daemonSetProgressing, 1)
+
+	// the rollout is done
+	ds.Status = appsv1.DaemonSetStatus{
+		DesiredNumberScheduled: 5,
+		UpdatedNumberScheduled: 5,
+		NumberReady:            5,
+		NumberAvailable:        5,
+	}
+	if err := client.Update(context.TODO(), ds); err != nil {
+		t.Fatalf("error updating DaemonSet: %v", err)
+	}
+	status.SetFromPods()
+	expectGauge(daemonSetUpdatedNodes, 5)
+	expectGauge(daemonSetUnavailableNodes, 0)
+	expectGauge(daemonSetProgressing, 0)
+
+	// the metrics of the DaemonSets no longer managed are removed
+	status.SetDaemonSets(nil)
+	for _, gauge := range daemonSetGauges {
+		if gauge.Delete(map[string]string{"namespace": dsName.Namespace, "daemonset": dsName.Name}) {
+			t.Fatalf("the metrics of %s were not removed", dsName)
+		}
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"reflect"
 	"strings"
@@ -74,7 +73,7 @@ func (status *StatusManager) SetFromPods() {
 	for _, dsName := range status.daemonSets {
 		ds := &appsv1.DaemonSet{}
 		if err := status.client.Get(context.TODO(), dsName, ds); err != nil {
-			log.Printf("Error getting DaemonSet %q: %v", dsName.String(), err)
+			log.Error(err, "Error getting DaemonSet", "daemonset", dsName.String())
 			progressing = append(progressing, fmt.Sprintf("Waiting for DaemonSet %q to be created", dsName.String()))
 			reachedAvailableLevel = false
 			// Assume the OperConfig Controller is in the process of reconciling
@@ -83,27 +82,15 @@ func (status *StatusManager) SetFromPods() {
 		}
 
 		dsProgressing := false
-
-		if isNonCritical(ds) && ds.Status.NumberReady == 0 && !status.installComplete {
-			progressing = append(progressing, fmt.Sprintf("DaemonSet %q is waiting for other operators to become ready", dsName.String()))
-			dsProgressing = true
-		} else if ds.Status.UpdatedNumberScheduled < ds.Status.DesiredNumberScheduled {
-			progressing = append(progressing, fmt.Sprintf("DaemonSet %q update is rolling out (%d out of %d updated)", dsName.String(), ds.Status.UpdatedNumberScheduled, ds.Status.DesiredNumberScheduled))
-			dsProgressing = true
-		} else if ds.Status.NumberUnavailable > 0 {
-			progressing = append(progressing, fmt.Sprintf("DaemonSet %q is not available (awaiting %d nodes)", dsName.String(), ds.Status.NumberUnavailable))
+		if msg, unavailable := DaemonSetProgress(ds, status.installComplete); msg != "" {
+			progressing = append(progressing, msg)
 			dsProgressing = true
 			// Check for any pods in CrashLoopBackOff state and mark the operator as degraded if so.
-			if !isNonCritical(ds) {
+			if unavailable && !isNonCritical(ds) {
 				hung = append(hung, status.CheckCrashLoopBackOffPods(dsName, ds.Spec.Selector.MatchLabels, "DaemonSet")...)
 			}
-		} else if ds.Status.NumberAvailable == 0 { // NOTE: update this if we ever expect empty (unscheduled) daemonsets ~cdc
-			progressing = append(progressing, fmt.Sprintf("DaemonSet %q is not yet scheduled on any nodes", dsName.String()))
-			dsProgressing = true
-		} else if ds.Generation > ds.Status.ObservedGeneration {
-			progressing = append(progressing, fmt.Sprintf("DaemonSet %q update is being processed (generation %d, observed generation %d)", dsName.String(), ds.Generation, ds.Status.ObservedGeneration))
-			dsProgressing = true
 		}
+		recordDaemonSetMetrics(ds, dsProgressing)
 
 		if ds.Annotations["release.openshift.io/version"] != targetLevel {
 			reachedAvailableLevel = false
@@ -131,14 +118,14 @@ func (status *StatusManager) SetFromPods() {
 			delete(daemonsetStates, dsName)
 		}
 		if err := status.setDSAnnotation(ds, names.RolloutHungAnnotation, dsHung); err != nil {
-			log.Printf("Error setting DaemonSet %q annotation: %v", dsName, err)
+			log.Error(err, "Error setting DaemonSet annotation", "daemonset", dsName.String())
 		}
 	}
 
 	for _, depName := range status.deployments {
 		dep := &appsv1.Deployment{}
 		if err := status.client.Get(context.TODO(), depName, dep); err != nil {
-			log.Printf("Error getting Deployment %q: %v", depName.String(), err)
+			log.Error(err, "Error getting Deployment", "deployment", depName.String())
 			progressing = append(progressing, fmt.Sprintf("Waiting for Deployment %q to be created", depName.String()))
 			reachedAvailableLevel = false
 			// Assume the OperConfig Controller is in the process of reconciling
@@ -147,23 +134,13 @@ func (status *StatusManager) SetFromPods() {
 		}
 
 		depProgressing := false
-
-		if isNonCritical(dep) && dep.Status.UnavailableReplicas > 0 && !status.installComplete {
-			progressing = append(progressing, fmt.Sprintf("Deployment %q is waiting for other operators to become ready", depName.String()))
-			depProgressing = true
-		} else if dep.Status.UnavailableReplicas > 0 {
-			progressing = append(progressing, fmt.Sprintf("Deployment %q is not available (awaiting %d nodes)", depName.String(), dep.Status.UnavailableReplicas))
+		if msg, unavailable := DeploymentProgress(dep, status.installComplete); msg != "" {
+			progressing = append(progressing, msg)
 			depProgressing = true
 			// Check for any pods in CrashLoopBackOff state and mark the operator as degraded if so.
-			if !isNonCritical(dep) {
+			if unavailable && !isNonCritical(dep) {
 				hung = append(hung, status.CheckCrashLoopBackOffPods(depName, dep.Spec.Selector.MatchLabels, "Deployment")...)
 			}
-		} else if dep.Status.AvailableReplicas == 0 {
-			progressing = append(progressing, fmt.Sprintf("Deployment %q is not yet scheduled on any nodes", depName.String()))
-			depProgressing = true
-		} else if dep.Status.ObservedGeneration < dep.Generation {
-			progressing = append(progressing, fmt.Sprintf("Deployment %q update is being processed (generation %d, observed generation %d)", depName.String(), dep.Generation, dep.Status.ObservedGeneration))
-			depProgressing = true
 		}
 
 		if dep.Annotations["release.openshift.io/version"] != targetLevel {
@@ -192,13 +169,36 @@ func (status *StatusManager) SetFromPods() {
 			delete(deploymentStates, depName)
 		}
 		if err := status.setDepAnnotation(dep, names.RolloutHungAnnotation, depHung); err != nil {
-			log.Printf("Error setting Deployment %q annotation: %v", depName, err)
+			log.Error(err, "Error setting Deployment annotation", "deployment", depName.String())
+		}
+	}
+
+	// The DaemonSets of a third-party default network are not ours to annotate, and are not
+	// versioned with the release; only their readiness is reported.
+	for _, dsName := range status.externalDaemonSets {
+		ds := &appsv1.DaemonSet{}
+		if err := status.client.Get(context.TODO(), dsName, ds); err != nil {
+			log.Error(err, "Error getting DaemonSet", "daemonset", dsName.String())
+			progressing = append(progressing, fmt.Sprintf("Waiting for the third-party DaemonSet %q to be created", dsName.String()))
+			reachedAvailableLevel = false
+			continue
+		}
+
+		if ds.Status.UpdatedNumberScheduled < ds.Status.DesiredNumberScheduled {
+			progressing = append(progressing, fmt.Sprintf("Third-party DaemonSet %q update is rolling out (%d out of %d updated)", dsName.String(), ds.Status.UpdatedNumberScheduled, ds.Status.DesiredNumberScheduled))
+		} else if ds.Status.NumberUnavailable > 0 {
+			progressing = append(progressing, fmt.Sprintf("Third-party DaemonSet %q is not available (awaiting %d nodes)", dsName.String(), ds.Status.NumberUnavailable))
+		} else if ds.Status.NumberAvailable == 0 {
+			progressing = append(progressing, fmt.Sprintf("Third-party DaemonSet %q is not yet scheduled on any nodes", dsName.String()))
+		}
+		if ds.Status.NumberAvailable == 0 {
+			reachedAvailableLevel = false
 		}
 	}
 
 	status.setNotDegraded(PodDeployment)
 	if err := status.setLastPodState(daemonsetStates, deploymentStates); err != nil {
-		log.Printf("Failed to set pod state (continuing): %+v\n", err)
+		log.Error(err, "Failed to set pod state (continuing)")
 	}
 
 	conditions := make([]operv1.OperatorCondition, 0, 2)
@@ -232,6 +232,12 @@ func (status *StatusManager) SetFromPods() {
 		status.installComplete = true
 	}
 
+	if status.forcedChange != "" && len(progressing) == 0 {
+		log.Info("Forced unsafe change rolled out", "request", status.forcedChange)
+		status.forcedChange = ""
+		status.setNotDegraded(ForcedChange)
+	}
+
 	status.set(reachedAvailableLevel, conditions...)
 	if len(hung) > 0 {
 		status.setDegraded(RolloutHung, "RolloutHung", strings.Join(hung, "\n"))
@@ -252,7 +258,7 @@ func (status *StatusManager) getLastPodState() (map[types.NamespacedName]daemons
 	co := &configv1.ClusterOperator{ObjectMeta: metav1.ObjectMeta{Name: status.name}}
 	err := status.client.Get(context.TODO(), types.NamespacedName{Name: status.name}, co)
 	if err != nil {
-		log.Printf("Failed to get ClusterOperator: %v", err)
+		log.Error(err, "Failed to get ClusterOperator")
 		return daemonsetStates, deploymentStates
 	}
 
@@ -265,7 +271,7 @@ func (status *StatusManager) getLastPodState() (map[types.NamespacedName]daemons
 	err = json.Unmarshal([]byte(lsbytes), &out)
 	if err != nil {
 		// No need to return error; just move on
-		log.Printf("failed to unmashal last-seen-status: %v", err)
+		log.Error(err, "failed to unmarshal last-seen-status")
 		return daemonsetStates, deploymentStates
 	}
 
@@ -321,16 +327,59 @@ func (status *StatusManager) setLastPodState(
 	})
 }
 
+// DaemonSetProgress returns why the DaemonSet is progressing, or "" when its rollout is done.
+// unavailable is set when all the pods are updated but some of them are not available, which is
+// when pods in CrashLoopBackOff are looked for. installComplete is whether the operator has
+// already been available once.
+func DaemonSetProgress(ds *appsv1.DaemonSet, installComplete bool) (msg string, unavailable bool) {
+	dsName := types.NamespacedName{Namespace: ds.Namespace, Name: ds.Name}
+	if isNonCritical(ds) && ds.Status.NumberReady == 0 && !installComplete {
+		return fmt.Sprintf("DaemonSet %q is waiting for other operators to become ready", dsName.String()), false
+	} else if ds.Status.UpdatedNumberScheduled < ds.Status.DesiredNumberScheduled {
+		return fmt.Sprintf("DaemonSet %q update is rolling out (%d out of %d updated)", dsName.String(), ds.Status.UpdatedNumberScheduled, ds.Status.DesiredNumberScheduled), false
+	} else if ds.Status.NumberUnavailable > 0 {
+		return fmt.Sprintf("DaemonSet %q is not available (awaiting %d nodes)", dsName.String(), ds.Status.NumberUnavailable), true
+	} else if ds.Status.NumberAvailable == 0 { // NOTE: update this if we ever expect empty (unscheduled) daemonsets ~cdc
+		return fmt.Sprintf("DaemonSet %q is not yet scheduled on any nodes", dsName.String()), false
+	} else if ds.Generation > ds.Status.ObservedGeneration {
+		return fmt.Sprintf("DaemonSet %q update is being processed (generation %d, observed generation %d)", dsName.String(), ds.Generation, ds.Status.ObservedGeneration), false
+	}
+	return "", false
+}
+
+// DeploymentProgress is the same as DaemonSetProgress.. but for deployments!
+func DeploymentProgress(dep *appsv1.Deployment, installComplete bool) (msg string, unavailable bool) {
+	depName := types.NamespacedName{Namespace: dep.Namespace, Name: dep.Name}
+	if isNonCritical(dep) && dep.Status.UnavailableReplicas > 0 && !installComplete {
+		return fmt.Sprintf("Deployment %q is waiting for other operators to become ready", depName.String()), false
+	} else if dep.Status.UnavailableReplicas > 0 {
+		return fmt.Sprintf("Deployment %q is not available (awaiting %d nodes)", depName.String(), dep.Status.UnavailableReplicas), true
+	} else if dep.Status.AvailableReplicas == 0 {
+		return fmt.Sprintf("Deployment %q is not yet scheduled on any nodes", depName.String()), false
+	} else if dep.Status.ObservedGeneration < dep.Generation {
+		return fmt.Sprintf("Deployment %q update is being processed (generation %d, observed generation %d)", depName.String(), dep.Generation, dep.Status.ObservedGeneration), false
+	}
+	return "", false
+}
+
 // CheckCrashLoopBackOffPods checks for pods (matching the label selector) with
 // any containers in the CrashLoopBackoff state. It returns a human-readable string
 // for any pod in such a state.
 // dName should be the name of a DaemonSet or Deployment.
 func (status *StatusManager) CheckCrashLoopBackOffPods(dName types.NamespacedName, selector map[string]string, kind string) []string {
+	hung, err := CrashLoopBackOffPods(context.TODO(), status.client, dName, selector, kind)
+	if err != nil {
+		log.Error(err, "Error getting pods", "kind", kind, "name", dName.String())
+	}
+	return hung
+}
+
+// CrashLoopBackOffPods is CheckCrashLoopBackOffPods with any client.
+func CrashLoopBackOffPods(ctx context.Context, cl client.Reader, dName types.NamespacedName, selector map[string]string, kind string) ([]string, error) {
 	hung := []string{}
 	pods := &v1.PodList{}
-	err := status.client.List(context.TODO(), pods, client.InNamespace(dName.Namespace), client.MatchingLabels(selector))
-	if err != nil {
-		log.Printf("Error getting pods from %s %q: %v", kind, dName.String(), err)
+	if err := cl.List(ctx, pods, client.InNamespace(dName.Namespace), client.MatchingLabels(selector)); err != nil {
+		return hung, err
 	}
 	for _, pod := range pods.Items {
 		for _, container := range pod.Status.ContainerStatuses {
@@ -343,7 +392,7 @@ func (status *StatusManager) CheckCrashLoopBackOffPods(dName types.NamespacedNam
 			}
 		}
 	}
-	return hung
+	return hung, nil
 }
 
 func isNonCritical(obj metav1.Object) bool {
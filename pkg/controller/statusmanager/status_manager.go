@@ -3,16 +3,18 @@ package statusmanager
 import (
 	"context"
 	"fmt"
-	"log"
 	"os"
 	"reflect"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/ghodss/yaml"
 
 	configv1 "github.com/openshift/api/config/v1"
 	operv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/openshift/cluster-network-operator/pkg/util/logging"
 	cohelpers "github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
 	operstatus "github.com/openshift/library-go/pkg/operator/status"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
@@ -29,6 +31,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+var log = logging.Logger(logging.Status)
+
 type StatusLevel int
 
 const (
@@ -41,6 +45,8 @@ const (
 	EgressRouterConfig
 	RolloutHung
 	CertificateSigner
+	ForcedChange
+	NodeIdentity
 	maxStatusLevel
 )
 
@@ -54,10 +60,19 @@ type StatusManager struct {
 
 	failing         [maxStatusLevel]*operv1.OperatorCondition
 	installComplete bool
+	// managementState is the management state of the operator configuration, so that the
+	// controllers stop changing the operands unless it is Managed. It is empty until the
+	// operator configuration is read.
+	managementState operv1.ManagementState
+	// forcedChange is the request of the forced unsafe change being rolled out, if any
+	forcedChange string
 
 	daemonSets     []types.NamespacedName
 	deployments    []types.NamespacedName
 	relatedObjects []configv1.ObjectReference
+
+	// the DaemonSets of a third-party default network, which the operator does not manage
+	externalDaemonSets []types.NamespacedName
 }
 
 func New(client client.Client, mapper meta.RESTMapper, name string) *StatusManager {
@@ -86,29 +101,29 @@ func (status *StatusManager) deleteRelatedObjectsNotRendered(co *configv1.Cluste
 			}
 			gvk, err := status.mapper.KindFor(gvr)
 			if err != nil {
-				log.Printf("Error getting GVK of object for deletion: %v", err)
+				log.Error(err, "Error getting GVK of object for deletion")
 				status.relatedObjects = append(status.relatedObjects, currentObj)
 				continue
 			}
 			if gvk.Kind == "Namespace" && gvk.Group == "" {
 				// BZ 1820472: During SDN migration, deleting a namespace object may get stuck in 'Terminating' forever if the cluster network doesn't working as expected.
 				// We choose to not delete the namespace here but to ask user do it manually after the cluster is back to normal state.
-				log.Printf("Object Kind is Namespace, skip")
+				log.V(2).Info("Object Kind is Namespace, skip")
 				continue
 			}
 			// @aconstan: remove this after having the PR implementing this change, integrated.
 			if gvk.Kind == "Network" && gvk.Group == "operator.openshift.io" {
-				log.Printf("Object Kind is network.operator.openshift.io, skip")
+				log.V(2).Info("Object Kind is network.operator.openshift.io, skip")
 				continue
 			}
-			log.Printf("Detected related object with GVK %+v, namespace %v and name %v not rendered by manifests, deleting...", gvk, currentObj.Namespace, currentObj.Name)
+			log.Info("Detected related object not rendered by manifests, deleting...", "gvk", gvk.String(), "namespace", currentObj.Namespace, "name", currentObj.Name)
 			objToDelete := &uns.Unstructured{}
 			objToDelete.SetName(currentObj.Name)
 			objToDelete.SetNamespace(currentObj.Namespace)
 			objToDelete.SetGroupVersionKind(gvk)
 			err = status.client.Delete(context.TODO(), objToDelete, client.PropagationPolicy("Background"))
 			if err != nil {
-				log.Printf("Error deleting related object: %v", err)
+				log.Error(err, "Error deleting related object")
 				if !errors.IsNotFound(err) {
 					status.relatedObjects = append(status.relatedObjects, currentObj)
 				}
@@ -143,7 +158,7 @@ func (status *StatusManager) set(reachedAvailableLevel bool, conditions ...operv
 			co := &configv1.ClusterOperator{ObjectMeta: metav1.ObjectMeta{Name: status.name}}
 			err := status.client.Get(context.TODO(), types.NamespacedName{Name: status.name}, co)
 			if err != nil {
-				log.Printf("failed to retrieve ClusterOperator object: %v - continuing", err)
+				log.Error(err, "failed to retrieve ClusterOperator object - continuing")
 			}
 
 			for _, condition := range co.Status.Conditions {
@@ -195,12 +210,12 @@ func (status *StatusManager) set(reachedAvailableLevel bool, conditions ...operv
 		if err := status.client.Update(context.TODO(), oc); err != nil {
 			return err
 		}
-		log.Printf("Set operator conditions:\n%s", buf)
+		log.V(1).Info("Set operator conditions", "conditions", "\n"+string(buf))
 
 		return nil
 	})
 	if err != nil {
-		log.Printf("Failed to set operator status: %v", err)
+		log.Error(err, "Failed to set operator status")
 	}
 
 	// Set status conditions on the network clusteroperator object.
@@ -235,6 +250,9 @@ func (status *StatusManager) set(reachedAvailableLevel bool, conditions ...operv
 			}
 
 			for _, cond := range operStatus.Conditions {
+				if operatorOnlyConditions[cond.Type] {
+					continue
+				}
 				cohelpers.SetStatusCondition(&co.Status.Conditions, operstatus.OperatorConditionToClusterOperatorCondition(cond))
 			}
 		}
@@ -252,17 +270,17 @@ func (status *StatusManager) set(reachedAvailableLevel bool, conditions ...operv
 			if err := status.client.Create(context.TODO(), co); err != nil {
 				return err
 			}
-			log.Printf("Set ClusterOperator conditions:\n%s", buf)
+			log.V(1).Info("Set ClusterOperator conditions", "conditions", "\n"+string(buf))
 			return nil
 		}
 		if err := status.client.Status().Update(context.TODO(), co); err != nil {
 			return err
 		}
-		log.Printf("Set ClusterOperator conditions:\n%s", buf)
+		log.V(1).Info("Set ClusterOperator conditions", "conditions", "\n"+string(buf))
 		return nil
 	})
 	if err != nil {
-		log.Printf("Failed to set ClusterOperator: %v", err)
+		log.Error(err, "Failed to set ClusterOperator")
 	}
 }
 
@@ -312,9 +330,260 @@ func (status *StatusManager) SetNotDegraded(statusLevel StatusLevel) {
 	status.setNotDegraded(statusLevel)
 }
 
+// ManagementStateUnknown is the management state reported until the operator configuration is read
+const ManagementStateUnknown operv1.ManagementState = "Unknown"
+
+// UnmanagedRetryInterval is how often the controllers without a resync period retry the changes
+// they skipped while the operator configuration is not Managed
+var UnmanagedRetryInterval = time.Minute
+
+// SetManagementState records the management state of the operator configuration. Unless it is
+// Managed, the controllers leave the operands as they are, so that they can be edited manually,
+// and only keep reporting their status. An empty state is the default one, Managed.
+func (status *StatusManager) SetManagementState(state operv1.ManagementState) {
+	status.Lock()
+	defer status.Unlock()
+	if state == "" {
+		state = operv1.Managed
+	}
+	status.managementState = state
+}
+
+// ManagementState returns the management state of the operator configuration, which is
+// ManagementStateUnknown until it is read
+func (status *StatusManager) ManagementState() operv1.ManagementState {
+	status.Lock()
+	defer status.Unlock()
+	if status.managementState == "" {
+		return ManagementStateUnknown
+	}
+	return status.managementState
+}
+
+// IsManaged returns true if the operator configuration is Managed, or Force, so that the
+// controllers may change the operands. Unmanaged and Removed both leave the operands as they are,
+// as does an unknown state, so that the controllers started before the operator configuration is
+// read do not change the operands of an Unmanaged cluster.
+func (status *StatusManager) IsManaged() bool {
+	switch status.ManagementState() {
+	case operv1.Managed, operv1.Force:
+		return true
+	}
+	return false
+}
+
+// OperatorStatusTypeEgressIPsUnassignable is true when more egress IPs are requested than the
+// egress-assignable nodes can host
+const OperatorStatusTypeEgressIPsUnassignable = "EgressIPsUnassignable"
+
+// OperatorStatusTypeDrifted is true when objects rendered by the operator were changed or removed
+// by something else
+const OperatorStatusTypeDrifted = "Drifted"
+
+// OperatorStatusTypeOVNCrashReports is true when crash reports of ovn-kubernetes, OVS or OVN
+// were collected on some nodes
+const OperatorStatusTypeOVNCrashReports = "OVNCrashReports"
+
+// OperatorStatusTypePodSubnetsExhausted is true when the pod subnets of some nodes are nearly
+// full, or when some nodes have no pod subnet
+const OperatorStatusTypePodSubnetsExhausted = "PodSubnetsExhausted"
+
+// OperatorStatusTypeFlowCollectorsUnreachable is true when some of the collectors the flows
+// are exported to cannot be resolved or reached
+const OperatorStatusTypeFlowCollectorsUnreachable = "FlowCollectorsUnreachable"
+
+// OperatorStatusTypeMachineConfigsRollingOut is true while the MachineConfigs generated by the
+// operator are not yet rolled out on some pools of nodes
+const OperatorStatusTypeMachineConfigsRollingOut = "MachineConfigsRollingOut"
+
+// OperatorStatusTypeMTUBlackHoles is true when the path MTU between some pairs of nodes is below
+// the MTU the overlay needs, so that the largest packets between their pods are dropped
+const OperatorStatusTypeMTUBlackHoles = "MTUBlackHoles"
+
+// OperatorStatusTypeConfigurationWarnings is true when the operator configuration is valid, but is
+// likely not what was intended. Unlike an invalid configuration, it does not block the reconciliation.
+const OperatorStatusTypeConfigurationWarnings = "ConfigurationWarnings"
+
+// operatorOnlyConditions are only reported on the operator configuration, and not on the ClusterOperator
+var operatorOnlyConditions = map[string]bool{
+	OperatorStatusTypeEgressIPsUnassignable:     true,
+	OperatorStatusTypeDrifted:                   true,
+	OperatorStatusTypeOVNCrashReports:           true,
+	OperatorStatusTypePodSubnetsExhausted:       true,
+	OperatorStatusTypeFlowCollectorsUnreachable: true,
+	OperatorStatusTypeMachineConfigsRollingOut:  true,
+	OperatorStatusTypeMTUBlackHoles:             true,
+	OperatorStatusTypeConfigurationWarnings:     true,
+}
+
+// maxDriftedObjects is the number of drifted objects listed in the Drifted condition
+const maxDriftedObjects = 10
+
+// SetDrifted reports the objects rendered by the operator whose live state differs from the
+// rendered one, each described with the fields that differ
+func (status *StatusManager) SetDrifted(drifted []string) {
+	status.Lock()
+	defer status.Unlock()
+	condition := operv1.OperatorCondition{
+		Type:   OperatorStatusTypeDrifted,
+		Status: operv1.ConditionFalse,
+		Reason: "AsExpected",
+	}
+	if len(drifted) > 0 {
+		condition.Status = operv1.ConditionTrue
+		condition.Reason = "ObjectsDrifted"
+		listed := drifted
+		if len(listed) > maxDriftedObjects {
+			listed = listed[:maxDriftedObjects]
+		}
+		condition.Message = fmt.Sprintf("%d objects differ from their rendered state: %s", len(drifted), strings.Join(listed, ", "))
+		if len(drifted) > len(listed) {
+			condition.Message += fmt.Sprintf(" and %d more", len(drifted)-len(listed))
+		}
+	}
+	status.set(false, condition)
+}
+
+// SetConfigurationWarnings reports the warnings of the validation of the operator configuration, the
+// advice on the settings that are valid but likely not what was intended
+func (status *StatusManager) SetConfigurationWarnings(warnings []string) {
+	status.Lock()
+	defer status.Unlock()
+	condition := operv1.OperatorCondition{
+		Type:   OperatorStatusTypeConfigurationWarnings,
+		Status: operv1.ConditionFalse,
+		Reason: "AsExpected",
+	}
+	if len(warnings) > 0 {
+		condition.Status = operv1.ConditionTrue
+		condition.Reason = "ValidationWarnings"
+		condition.Message = fmt.Sprintf("The operator configuration is applied, but %d settings are likely not what was intended: %s",
+			len(warnings), strings.Join(warnings, "; "))
+	}
+	status.set(false, condition)
+}
+
+// SetEgressIPCapacity reports the utilization of the egress IP capacity of the cluster, and whether
+// some of the requested egress IPs cannot be assigned to a node
+func (status *StatusManager) SetEgressIPCapacity(unassignable bool, reason, message string) {
+	status.Lock()
+	defer status.Unlock()
+	condition := operv1.OperatorCondition{
+		Type:    OperatorStatusTypeEgressIPsUnassignable,
+		Status:  operv1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
+	}
+	if unassignable {
+		condition.Status = operv1.ConditionTrue
+	}
+	status.set(false, condition)
+}
+
+// SetNodeSubnets reports whether the pod subnets of some nodes are nearly exhausted
+func (status *StatusManager) SetNodeSubnets(exhausted bool, reason, message string) {
+	status.Lock()
+	defer status.Unlock()
+	condition := operv1.OperatorCondition{
+		Type:    OperatorStatusTypePodSubnetsExhausted,
+		Status:  operv1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
+	}
+	if exhausted {
+		condition.Status = operv1.ConditionTrue
+	}
+	status.set(false, condition)
+}
+
+// SetFlowCollectors reports whether some of the flow collectors are unreachable
+func (status *StatusManager) SetFlowCollectors(unreachable bool, reason, message string) {
+	status.Lock()
+	defer status.Unlock()
+	condition := operv1.OperatorCondition{
+		Type:    OperatorStatusTypeFlowCollectorsUnreachable,
+		Status:  operv1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
+	}
+	if unreachable {
+		condition.Status = operv1.ConditionTrue
+	}
+	status.set(false, condition)
+}
+
+// SetMachineConfigRollout reports whether the MachineConfigs generated by the operator are still rolling out
+func (status *StatusManager) SetMachineConfigRollout(rollingOut bool, reason, message string) {
+	status.Lock()
+	defer status.Unlock()
+	condition := operv1.OperatorCondition{
+		Type:    OperatorStatusTypeMachineConfigsRollingOut,
+		Status:  operv1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
+	}
+	if rollingOut {
+		condition.Status = operv1.ConditionTrue
+	}
+	status.set(false, condition)
+}
+
+// SetMTUBlackHoles reports whether the path MTU between some pairs of nodes is below the MTU of the overlay
+func (status *StatusManager) SetMTUBlackHoles(blackHoles bool, reason, message string) {
+	status.Lock()
+	defer status.Unlock()
+	condition := operv1.OperatorCondition{
+		Type:    OperatorStatusTypeMTUBlackHoles,
+		Status:  operv1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
+	}
+	if blackHoles {
+		condition.Status = operv1.ConditionTrue
+	}
+	status.set(false, condition)
+}
+
+// SetCrashForensics reports whether crash reports were collected on some nodes, and where
+func (status *StatusManager) SetCrashForensics(collected bool, reason, message string) {
+	status.Lock()
+	defer status.Unlock()
+	condition := operv1.OperatorCondition{
+		Type:    OperatorStatusTypeOVNCrashReports,
+		Status:  operv1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
+	}
+	if collected {
+		condition.Status = operv1.ConditionTrue
+	}
+	status.set(false, condition)
+}
+
+// SetForcedChange reports the operator Degraded while the unsafe change forced by the request is rolled
+// out, which disrupts the pod network until all of the ovn-kubernetes pods are restarted. The condition
+// is cleared once the DaemonSets and Deployments are no longer progressing.
+func (status *StatusManager) SetForcedChange(request string) {
+	status.Lock()
+	defer status.Unlock()
+	status.forcedChange = request
+	status.setDegraded(ForcedChange, "ForcedUnsafeChange",
+		fmt.Sprintf("Rolling out the unsafe configuration change forced by %s=%s, the pod network is disrupted until all of the ovn-kubernetes pods are restarted",
+			names.ForceUnsafeChangeAnnotation, request))
+}
+
 func (status *StatusManager) SetDaemonSets(daemonSets []types.NamespacedName) {
 	status.Lock()
 	defer status.Unlock()
+	managed := map[types.NamespacedName]bool{}
+	for _, dsName := range daemonSets {
+		managed[dsName] = true
+	}
+	for _, dsName := range status.daemonSets {
+		if !managed[dsName] {
+			deleteDaemonSetMetrics(dsName)
+		}
+	}
 	status.daemonSets = daemonSets
 }
 
@@ -324,6 +593,14 @@ func (status *StatusManager) SetDeployments(deployments []types.NamespacedName)
 	status.deployments = deployments
 }
 
+// SetExternalDaemonSets sets the DaemonSets of a third-party default network, whose readiness is
+// reported along with the operator's own DaemonSets.
+func (status *StatusManager) SetExternalDaemonSets(daemonSets []types.NamespacedName) {
+	status.Lock()
+	defer status.Unlock()
+	status.externalDaemonSets = daemonSets
+}
+
 func (status *StatusManager) SetRelatedObjects(relatedObjects []configv1.ObjectReference) {
 	status.Lock()
 	defer status.Unlock()
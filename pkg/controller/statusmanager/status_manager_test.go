@@ -4,7 +4,9 @@ package statusmanager
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -362,6 +364,311 @@ func TestStatusManagerSetDegraded(t *testing.T) {
 	}
 }
 
+func TestStatusManagerManagementState(t *testing.T) {
+	status := New(nil, nil, "testing")
+
+	// the operator is not managed until the operator configuration is read
+	if status.IsManaged() || status.ManagementState() != ManagementStateUnknown {
+		t.Fatalf("unexpected management state %q", status.ManagementState())
+	}
+	for state, managed := range map[operv1.ManagementState]bool{
+		"":                 true,
+		operv1.Managed:     true,
+		operv1.Force:       true,
+		operv1.Unmanaged:   false,
+		operv1.Removed:     false,
+		"SomethingUnknown": false,
+	} {
+		status.SetManagementState(state)
+		if status.IsManaged() != managed {
+			t.Fatalf("expected IsManaged() to be %t for management state %q", managed, state)
+		}
+	}
+}
+
+func TestStatusManagerSetEgressIPCapacity(t *testing.T) {
+	client := fake.NewClientBuilder().WithRuntimeObjects().Build()
+	mapper := &fakeRESTMapper{}
+	status := New(client, mapper, "testing")
+
+	no := &operv1.Network{ObjectMeta: metav1.ObjectMeta{Name: names.OPERATOR_CONFIG}}
+	if err := client.Create(context.TODO(), no); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	status.SetEgressIPCapacity(true, "EgressIPCapacityExceeded", "1 of 3 egress IPs assigned")
+
+	co, oc, err := getStatuses(client, "testing")
+	if err != nil {
+		t.Fatalf("error getting network.operator: %v", err)
+	}
+	cond := v1helpers.FindOperatorCondition(oc.Status.Conditions, OperatorStatusTypeEgressIPsUnassignable)
+	if cond == nil || cond.Status != operv1.ConditionTrue || cond.Reason != "EgressIPCapacityExceeded" || cond.Message != "1 of 3 egress IPs assigned" {
+		t.Fatalf("unexpected %s condition: %#v", OperatorStatusTypeEgressIPsUnassignable, cond)
+	}
+	// the condition is not reported on the ClusterOperator
+	for _, cond := range co.Status.Conditions {
+		if string(cond.Type) == OperatorStatusTypeEgressIPsUnassignable {
+			t.Fatalf("unexpected ClusterOperator condition: %#v", cond)
+		}
+	}
+
+	status.SetEgressIPCapacity(false, "EgressIPsAssignable", "3 of 3 egress IPs assigned")
+	_, oc, err = getStatuses(client, "testing")
+	if err != nil {
+		t.Fatalf("error getting network.operator: %v", err)
+	}
+	cond = v1helpers.FindOperatorCondition(oc.Status.Conditions, OperatorStatusTypeEgressIPsUnassignable)
+	if cond == nil || cond.Status != operv1.ConditionFalse {
+		t.Fatalf("unexpected %s condition: %#v", OperatorStatusTypeEgressIPsUnassignable, cond)
+	}
+}
+
+func TestStatusManagerSetCrashForensics(t *testing.T) {
+	client := fake.NewClientBuilder().WithRuntimeObjects().Build()
+	mapper := &fakeRESTMapper{}
+	status := New(client, mapper, "testing")
+
+	no := &operv1.Network{ObjectMeta: metav1.ObjectMeta{Name: names.OPERATOR_CONFIG}}
+	if err := client.Create(context.TODO(), no); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	status.SetCrashForensics(true, "CrashReportsCollected", "Crash reports were collected on 1 nodes")
+
+	co, oc, err := getStatuses(client, "testing")
+	if err != nil {
+		t.Fatalf("error getting network.operator: %v", err)
+	}
+	cond := v1helpers.FindOperatorCondition(oc.Status.Conditions, OperatorStatusTypeOVNCrashReports)
+	if cond == nil || cond.Status != operv1.ConditionTrue || cond.Reason != "CrashReportsCollected" || cond.Message != "Crash reports were collected on 1 nodes" {
+		t.Fatalf("unexpected %s condition: %#v", OperatorStatusTypeOVNCrashReports, cond)
+	}
+	// the condition is not reported on the ClusterOperator
+	for _, cond := range co.Status.Conditions {
+		if string(cond.Type) == OperatorStatusTypeOVNCrashReports {
+			t.Fatalf("unexpected ClusterOperator condition: %#v", cond)
+		}
+	}
+
+	status.SetCrashForensics(false, "NoCrashReports", "No crash report was collected")
+	_, oc, err = getStatuses(client, "testing")
+	if err != nil {
+		t.Fatalf("error getting network.operator: %v", err)
+	}
+	cond = v1helpers.FindOperatorCondition(oc.Status.Conditions, OperatorStatusTypeOVNCrashReports)
+	if cond == nil || cond.Status != operv1.ConditionFalse {
+		t.Fatalf("unexpected %s condition: %#v", OperatorStatusTypeOVNCrashReports, cond)
+	}
+}
+
+func TestStatusManagerSetNodeSubnets(t *testing.T) {
+	client := fake.NewClientBuilder().WithRuntimeObjects().Build()
+	mapper := &fakeRESTMapper{}
+	status := New(client, mapper, "testing")
+
+	no := &operv1.Network{ObjectMeta: metav1.ObjectMeta{Name: names.OPERATOR_CONFIG}}
+	if err := client.Create(context.TODO(), no); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	status.SetNodeSubnets(true, "PodSubnetsExhausted", "1 pod subnets are at least 90% used")
+
+	co, oc, err := getStatuses(client, "testing")
+	if err != nil {
+		t.Fatalf("error getting network.operator: %v", err)
+	}
+	cond := v1helpers.FindOperatorCondition(oc.Status.Conditions, OperatorStatusTypePodSubnetsExhausted)
+	if cond == nil || cond.Status != operv1.ConditionTrue || cond.Reason != "PodSubnetsExhausted" || cond.Message != "1 pod subnets are at least 90% used" {
+		t.Fatalf("unexpected %s condition: %#v", OperatorStatusTypePodSubnetsExhausted, cond)
+	}
+	// the condition is not reported on the ClusterOperator
+	for _, cond := range co.Status.Conditions {
+		if string(cond.Type) == OperatorStatusTypePodSubnetsExhausted {
+			t.Fatalf("unexpected ClusterOperator condition: %#v", cond)
+		}
+	}
+
+	status.SetNodeSubnets(false, "PodSubnetsAvailable", "The pod subnets of 3 nodes are less than 90% used")
+	_, oc, err = getStatuses(client, "testing")
+	if err != nil {
+		t.Fatalf("error getting network.operator: %v", err)
+	}
+	cond = v1helpers.FindOperatorCondition(oc.Status.Conditions, OperatorStatusTypePodSubnetsExhausted)
+	if cond == nil || cond.Status != operv1.ConditionFalse {
+		t.Fatalf("unexpected %s condition: %#v", OperatorStatusTypePodSubnetsExhausted, cond)
+	}
+}
+
+func TestStatusManagerSetFlowCollectors(t *testing.T) {
+	client := fake.NewClientBuilder().WithRuntimeObjects().Build()
+	mapper := &fakeRESTMapper{}
+	status := New(client, mapper, "testing")
+
+	no := &operv1.Network{ObjectMeta: metav1.ObjectMeta{Name: names.OPERATOR_CONFIG}}
+	if err := client.Create(context.TODO(), no); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	status.SetFlowCollectors(true, "FlowCollectorsUnreachable", "1 of the 2 flow collectors are unreachable")
+
+	co, oc, err := getStatuses(client, "testing")
+	if err != nil {
+		t.Fatalf("error getting network.operator: %v", err)
+	}
+	cond := v1helpers.FindOperatorCondition(oc.Status.Conditions, OperatorStatusTypeFlowCollectorsUnreachable)
+	if cond == nil || cond.Status != operv1.ConditionTrue || cond.Reason != "FlowCollectorsUnreachable" || cond.Message != "1 of the 2 flow collectors are unreachable" {
+		t.Fatalf("unexpected %s condition: %#v", OperatorStatusTypeFlowCollectorsUnreachable, cond)
+	}
+	// the condition is not reported on the ClusterOperator
+	for _, cond := range co.Status.Conditions {
+		if string(cond.Type) == OperatorStatusTypeFlowCollectorsUnreachable {
+			t.Fatalf("unexpected ClusterOperator condition: %#v", cond)
+		}
+	}
+
+	status.SetFlowCollectors(false, "AsExpected", "The 2 flow collectors are reachable")
+	_, oc, err = getStatuses(client, "testing")
+	if err != nil {
+		t.Fatalf("error getting network.operator: %v", err)
+	}
+	cond = v1helpers.FindOperatorCondition(oc.Status.Conditions, OperatorStatusTypeFlowCollectorsUnreachable)
+	if cond == nil || cond.Status != operv1.ConditionFalse {
+		t.Fatalf("unexpected %s condition: %#v", OperatorStatusTypeFlowCollectorsUnreachable, cond)
+	}
+}
+
+func TestStatusManagerSetDrifted(t *testing.T) {
+	client := fake.NewClientBuilder().WithRuntimeObjects().Build()
+	mapper := &fakeRESTMapper{}
+	status := New(client, mapper, "testing")
+
+	no := &operv1.Network{ObjectMeta: metav1.ObjectMeta{Name: names.OPERATOR_CONFIG}}
+	if err := client.Create(context.TODO(), no); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	drifted := []string{}
+	for i := 0; i < 12; i++ {
+		drifted = append(drifted, fmt.Sprintf("ConfigMap ns/cm%d (missing)", i))
+	}
+	status.SetDrifted(drifted)
+
+	co, oc, err := getStatuses(client, "testing")
+	if err != nil {
+		t.Fatalf("error getting network.operator: %v", err)
+	}
+	cond := v1helpers.FindOperatorCondition(oc.Status.Conditions, OperatorStatusTypeDrifted)
+	if cond == nil || cond.Status != operv1.ConditionTrue || cond.Reason != "ObjectsDrifted" {
+		t.Fatalf("unexpected %s condition: %#v", OperatorStatusTypeDrifted, cond)
+	}
+	if !strings.HasPrefix(cond.Message, "12 objects differ from their rendered state: ConfigMap ns/cm0 (missing), ") ||
+		!strings.HasSuffix(cond.Message, "ConfigMap ns/cm9 (missing) and 2 more") {
+		t.Fatalf("unexpected %s message: %s", OperatorStatusTypeDrifted, cond.Message)
+	}
+	// the condition is not reported on the ClusterOperator
+	for _, cond := range co.Status.Conditions {
+		if string(cond.Type) == OperatorStatusTypeDrifted {
+			t.Fatalf("unexpected ClusterOperator condition: %#v", cond)
+		}
+	}
+
+	status.SetDrifted(nil)
+	_, oc, err = getStatuses(client, "testing")
+	if err != nil {
+		t.Fatalf("error getting network.operator: %v", err)
+	}
+	if !v1helpers.IsOperatorConditionFalse(oc.Status.Conditions, OperatorStatusTypeDrifted) {
+		t.Fatalf("unexpected Status.Conditions: %#v", oc.Status.Conditions)
+	}
+}
+
+func TestStatusManagerSetConfigurationWarnings(t *testing.T) {
+	client := fake.NewClientBuilder().WithRuntimeObjects().Build()
+	mapper := &fakeRESTMapper{}
+	status := New(client, mapper, "testing")
+
+	no := &operv1.Network{ObjectMeta: metav1.ObjectMeta{Name: names.OPERATOR_CONFIG}}
+	if err := client.Create(context.TODO(), no); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	status.SetConfigurationWarnings([]string{"warning one", "warning two"})
+	co, oc, err := getStatuses(client, "testing")
+	if err != nil {
+		t.Fatalf("error getting network.operator: %v", err)
+	}
+	cond := v1helpers.FindOperatorCondition(oc.Status.Conditions, OperatorStatusTypeConfigurationWarnings)
+	if cond == nil || cond.Status != operv1.ConditionTrue || cond.Reason != "ValidationWarnings" ||
+		!strings.HasSuffix(cond.Message, "2 settings are likely not what was intended: warning one; warning two") {
+		t.Fatalf("unexpected %s condition: %#v", OperatorStatusTypeConfigurationWarnings, cond)
+	}
+	// the warnings neither degrade the operator nor are reported on the ClusterOperator
+	for _, cond := range co.Status.Conditions {
+		if string(cond.Type) == OperatorStatusTypeConfigurationWarnings ||
+			(cond.Type == configv1.OperatorDegraded && cond.Status == configv1.ConditionTrue) {
+			t.Fatalf("unexpected ClusterOperator condition: %#v", cond)
+		}
+	}
+
+	status.SetConfigurationWarnings([]string{})
+	_, oc, err = getStatuses(client, "testing")
+	if err != nil {
+		t.Fatalf("error getting network.operator: %v", err)
+	}
+	if !v1helpers.IsOperatorConditionFalse(oc.Status.Conditions, OperatorStatusTypeConfigurationWarnings) {
+		t.Fatalf("unexpected Status.Conditions: %#v", oc.Status.Conditions)
+	}
+}
+
+func TestStatusManagerSetForcedChange(t *testing.T) {
+	client := fake.NewClientBuilder().WithRuntimeObjects().Build()
+	mapper := &fakeRESTMapper{}
+	status := New(client, mapper, "testing")
+	no := &operv1.Network{ObjectMeta: metav1.ObjectMeta{Name: names.OPERATOR_CONFIG}}
+	if err := client.Create(context.TODO(), no); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ovn-kubernetes", Name: "ovnkube-node", Generation: 2},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "ovnkube-node"}},
+		},
+		Status: appsv1.DaemonSetStatus{
+			ObservedGeneration: 1, DesiredNumberScheduled: 1, UpdatedNumberScheduled: 1, NumberAvailable: 1, NumberReady: 1,
+		},
+	}
+	if err := client.Create(context.TODO(), ds); err != nil {
+		t.Fatalf("error creating DaemonSet: %v", err)
+	}
+	status.SetDaemonSets([]types.NamespacedName{{Namespace: ds.Namespace, Name: ds.Name}})
+
+	// the operator is Degraded while the forced change is rolled out
+	status.SetForcedChange("2021-11-02")
+	status.SetFromPods()
+	oc, err := getOC(client)
+	if err != nil {
+		t.Fatalf("error getting ClusterOperator: %v", err)
+	}
+	degraded := v1helpers.FindOperatorCondition(oc.Status.Conditions, operv1.OperatorStatusTypeDegraded)
+	if degraded == nil || degraded.Status != operv1.ConditionTrue || degraded.Reason != "ForcedUnsafeChange" {
+		t.Fatalf("unexpected Degraded condition: %#v", degraded)
+	}
+
+	ds.Status.ObservedGeneration = 2
+	if err := client.Status().Update(context.TODO(), ds); err != nil {
+		t.Fatalf("error updating DaemonSet: %v", err)
+	}
+	status.SetFromPods()
+	oc, err = getOC(client)
+	if err != nil {
+		t.Fatalf("error getting ClusterOperator: %v", err)
+	}
+	if !v1helpers.IsOperatorConditionFalse(oc.Status.Conditions, operv1.OperatorStatusTypeDegraded) {
+		t.Fatalf("unexpected Status.Conditions: %#v", oc.Status.Conditions)
+	}
+}
+
 func TestStatusManagerSetFromDaemonSets(t *testing.T) {
 	client := fake.NewClientBuilder().WithRuntimeObjects().Build()
 	mapper := &fakeRESTMapper{}
@@ -1038,6 +1345,95 @@ func TestStatusManagerSetFromDaemonSets(t *testing.T) {
 	}
 }
 
+func TestStatusManagerSetFromExternalDaemonSets(t *testing.T) {
+	client := fake.NewClientBuilder().WithRuntimeObjects().Build()
+	mapper := &fakeRESTMapper{}
+	status := New(client, mapper, "testing")
+	no := &operv1.Network{ObjectMeta: metav1.ObjectMeta{Name: names.OPERATOR_CONFIG}}
+	if err := client.Create(context.TODO(), no); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	dsA := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "one", Name: "alpha"},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": "alpha"},
+			},
+		},
+		Status: appsv1.DaemonSetStatus{
+			DesiredNumberScheduled: 1,
+			UpdatedNumberScheduled: 1,
+			NumberAvailable:        1,
+		},
+	}
+	if err := client.Create(context.TODO(), dsA); err != nil {
+		t.Fatalf("error creating DaemonSet: %v", err)
+	}
+	status.SetDaemonSets([]types.NamespacedName{{Namespace: "one", Name: "alpha"}})
+	status.SetExternalDaemonSets([]types.NamespacedName{{Namespace: "vendor", Name: "cni"}})
+
+	// The third-party DaemonSet is missing
+	status.SetFromPods()
+	_, oc, err := getStatuses(client, "testing")
+	if err != nil {
+		t.Fatalf("error getting network.operator: %v", err)
+	}
+	if !conditionsInclude(oc.Status.Conditions, []operv1.OperatorCondition{
+		{
+			Type:   operv1.OperatorStatusTypeProgressing,
+			Status: operv1.ConditionTrue,
+			Reason: "Deploying",
+		},
+	}) {
+		t.Fatalf("unexpected Status.Conditions: %#v", oc.Status.Conditions)
+	}
+	if v1helpers.IsOperatorConditionTrue(oc.Status.Conditions, operv1.OperatorStatusTypeAvailable) {
+		t.Fatalf("unexpected Status.Conditions: %#v", oc.Status.Conditions)
+	}
+
+	// The third-party DaemonSet is ready; it is neither versioned nor annotated by the operator
+	dsB := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "vendor", Name: "cni"},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": "cni"},
+			},
+		},
+		Status: appsv1.DaemonSetStatus{
+			DesiredNumberScheduled: 1,
+			UpdatedNumberScheduled: 1,
+			NumberAvailable:        1,
+		},
+	}
+	if err := client.Create(context.TODO(), dsB); err != nil {
+		t.Fatalf("error creating DaemonSet: %v", err)
+	}
+	status.SetFromPods()
+	_, oc, err = getStatuses(client, "testing")
+	if err != nil {
+		t.Fatalf("error getting network.operator: %v", err)
+	}
+	if !conditionsInclude(oc.Status.Conditions, []operv1.OperatorCondition{
+		{
+			Type:   operv1.OperatorStatusTypeProgressing,
+			Status: operv1.ConditionFalse,
+		},
+		{
+			Type:   operv1.OperatorStatusTypeAvailable,
+			Status: operv1.ConditionTrue,
+		},
+	}) {
+		t.Fatalf("unexpected Status.Conditions: %#v", oc.Status.Conditions)
+	}
+	if err := client.Get(context.TODO(), types.NamespacedName{Namespace: "vendor", Name: "cni"}, dsB); err != nil {
+		t.Fatalf("error getting DaemonSet: %v", err)
+	}
+	if len(dsB.Annotations) > 0 {
+		t.Fatalf("unexpected DaemonSet annotations: %#v", dsB.Annotations)
+	}
+}
+
 func TestStatusManagerSetFromDeployments(t *testing.T) {
 	client := fake.NewClientBuilder().WithRuntimeObjects().Build()
 	mapper := &fakeRESTMapper{}
@@ -0,0 +1,146 @@
+// Package featuregates reads the feature gates of the cluster, and exposes the state of the
+// gates of the network features to the bootstrap and render phases of the operator.
+package featuregates
+
+import (
+	"context"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-network-operator/pkg/render"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The feature gates of the network features
+const (
+	// AdminNetworkPolicy enables the AdminNetworkPolicy support of ovn-kubernetes
+	AdminNetworkPolicy = "AdminNetworkPolicy"
+	// IPsecV2 enables the second iteration of the IPsec support of ovn-kubernetes
+	IPsecV2 = "IPsecV2"
+	// DPU enables the dpu and dpu-host node modes of ovn-kubernetes, selected by the OVNNodePools
+	DPU = "DPU"
+)
+
+var (
+	// gates are all the feature gates of the network features
+	gates = []string{AdminNetworkPolicy, IPsecV2, DPU}
+
+	// defaultEnabled are the gates enabled by every feature set, unless disabled
+	defaultEnabled = sets.NewString()
+
+	// techPreviewEnabled are the gates enabled by the TechPreviewNoUpgrade feature set
+	techPreviewEnabled = sets.NewString(AdminNetworkPolicy, IPsecV2, DPU)
+)
+
+// FeatureGates is the state of the feature gates of the cluster. The zero value is the state of
+// the default feature set.
+type FeatureGates struct {
+	featureSet configv1.FeatureSet
+	// enabled and disabled are the gates of the CustomNoUpgrade feature set
+	enabled  sets.String
+	disabled sets.String
+}
+
+// New returns the state of the feature gates selected by the FeatureGate. A nil FeatureGate
+// selects the default feature set.
+func New(featureGate *configv1.FeatureGate) FeatureGates {
+	f := FeatureGates{}
+	if featureGate == nil {
+		return f
+	}
+	f.featureSet = featureGate.Spec.FeatureSet
+	if custom := featureGate.Spec.CustomNoUpgrade; f.featureSet == configv1.CustomNoUpgrade && custom != nil {
+		f.enabled = sets.NewString(custom.Enabled...)
+		f.disabled = sets.NewString(custom.Disabled...)
+	}
+	return f
+}
+
+// Get reads the feature gates of the cluster, from the "cluster" FeatureGate.
+func Get(ctx context.Context, cl client.Reader) (FeatureGates, error) {
+	featureGate := &configv1.FeatureGate{}
+	if err := cl.Get(ctx, types.NamespacedName{Name: "cluster"}, featureGate); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return FeatureGates{}, fmt.Errorf("failed to retrieve the feature gates: %w", err)
+		}
+		return New(nil), nil
+	}
+	return New(featureGate), nil
+}
+
+// FeatureSet returns the feature set of the cluster
+func (f FeatureGates) FeatureSet() configv1.FeatureSet {
+	return f.featureSet
+}
+
+// Enabled returns whether the gate is enabled, by the feature set of the cluster or by default.
+func (f FeatureGates) Enabled(name string) bool {
+	if f.featureSet == configv1.CustomNoUpgrade {
+		if f.disabled.Has(name) {
+			return false
+		}
+		if f.enabled.Has(name) {
+			return true
+		}
+	} else if fs, ok := configv1.FeatureSets[f.featureSet]; ok {
+		if sets.NewString(fs.Disabled...).Has(name) {
+			return false
+		}
+		if sets.NewString(fs.Enabled...).Has(name) {
+			return true
+		}
+	}
+	if f.featureSet == configv1.TechPreviewNoUpgrade && techPreviewEnabled.Has(name) {
+		return true
+	}
+	return defaultEnabled.Has(name)
+}
+
+// Disabled returns whether the gate is explicitly disabled by the feature set of the cluster,
+// rather than merely not enabled.
+func (f FeatureGates) Disabled(name string) bool {
+	if f.featureSet == configv1.CustomNoUpgrade {
+		return f.disabled.Has(name)
+	}
+	if fs, ok := configv1.FeatureSets[f.featureSet]; ok {
+		return sets.NewString(fs.Disabled...).Has(name)
+	}
+	return false
+}
+
+// AdminNetworkPolicy returns whether the AdminNetworkPolicy gate is enabled
+func (f FeatureGates) AdminNetworkPolicy() bool {
+	return f.Enabled(AdminNetworkPolicy)
+}
+
+// IPsecV2 returns whether the IPsecV2 gate is enabled
+func (f FeatureGates) IPsecV2() bool {
+	return f.Enabled(IPsecV2)
+}
+
+// DPU returns whether the DPU gate is enabled
+func (f FeatureGates) DPU() bool {
+	return f.Enabled(DPU)
+}
+
+// Inject adds the state of the gates of the network features to the render data: as the
+// FeatureGates map of gate names to their state, and as the featureGate function, which
+// rejects the names of other gates.
+func (f FeatureGates) Inject(data *render.RenderData) {
+	states := make(map[string]bool, len(gates))
+	for _, name := range gates {
+		states[name] = f.Enabled(name)
+	}
+	data.Data["FeatureGates"] = states
+	data.Funcs["featureGate"] = func(name string) (bool, error) {
+		enabled, ok := states[name]
+		if !ok {
+			return false, fmt.Errorf("unknown feature gate %q", name)
+		}
+		return enabled, nil
+	}
+}
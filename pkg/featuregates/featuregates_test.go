@@ -0,0 +1,108 @@
+package featuregates
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-network-operator/pkg/render"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func featureGate(featureSet configv1.FeatureSet, enabled, disabled []string) *configv1.FeatureGate {
+	fg := &configv1.FeatureGate{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: configv1.FeatureGateSpec{FeatureGateSelection: configv1.FeatureGateSelection{
+			FeatureSet: featureSet,
+		}},
+	}
+	if featureSet == configv1.CustomNoUpgrade {
+		fg.Spec.CustomNoUpgrade = &configv1.CustomFeatureGates{Enabled: enabled, Disabled: disabled}
+	}
+	return fg
+}
+
+func TestEnabled(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	testCases := []struct {
+		featureGate *configv1.FeatureGate
+		enabled     []string
+		disabled    []string
+	}{
+		{nil, []string{"APIPriorityAndFairness"}, []string{AdminNetworkPolicy, IPsecV2, DPU, "LegacyNodeRoleBehavior"}},
+		{featureGate(configv1.Default, nil, nil), []string{}, []string{AdminNetworkPolicy, IPsecV2, DPU}},
+		{featureGate(configv1.TechPreviewNoUpgrade, nil, nil), []string{DPU, AdminNetworkPolicy, IPsecV2, "CSIDriverAzureDisk"}, []string{}},
+		{featureGate(configv1.IPv6DualStackNoUpgrade, nil, nil), []string{"IPv6DualStack"}, []string{AdminNetworkPolicy, DPU}},
+		{featureGate(configv1.CustomNoUpgrade, []string{AdminNetworkPolicy, DPU}, nil), []string{AdminNetworkPolicy, DPU}, []string{IPsecV2}},
+		// unknown feature sets are the default one
+		{featureGate("Unknown", nil, nil), []string{}, []string{AdminNetworkPolicy, DPU}},
+	}
+	for i, tc := range testCases {
+		f := New(tc.featureGate)
+		for _, name := range tc.enabled {
+			g.Expect(f.Enabled(name)).To(BeTrue(), "case %d: %s", i, name)
+		}
+		for _, name := range tc.disabled {
+			g.Expect(f.Enabled(name)).To(BeFalse(), "case %d: %s", i, name)
+		}
+	}
+
+	// the zero value is the default feature set
+	g.Expect(FeatureGates{}).To(Equal(New(nil)))
+	g.Expect(FeatureGates{}.DPU()).To(BeFalse())
+	g.Expect(FeatureGates{}.AdminNetworkPolicy()).To(BeFalse())
+	g.Expect(FeatureGates{}.IPsecV2()).To(BeFalse())
+}
+
+func TestDisabled(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// gates are only disabled explicitly
+	g.Expect(New(nil).Disabled("IPv6DualStack")).To(BeFalse())
+	g.Expect(New(nil).Disabled("LegacyNodeRoleBehavior")).To(BeTrue())
+	g.Expect(New(featureGate(configv1.CustomNoUpgrade, nil, []string{"IPv6DualStack"})).Disabled("IPv6DualStack")).To(BeTrue())
+	g.Expect(New(featureGate(configv1.CustomNoUpgrade, nil, nil)).Disabled("LegacyNodeRoleBehavior")).To(BeFalse())
+}
+
+func TestGet(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(configv1.Install(scheme)).To(Succeed())
+
+	// no FeatureGate is the default feature set
+	f, err := Get(context.TODO(), fake.NewClientBuilder().WithScheme(scheme).Build())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(f.FeatureSet()).To(Equal(configv1.Default))
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(featureGate(configv1.TechPreviewNoUpgrade, nil, nil)).Build()
+	f, err = Get(context.TODO(), cl)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(f.FeatureSet()).To(Equal(configv1.TechPreviewNoUpgrade))
+	g.Expect(f.AdminNetworkPolicy()).To(BeTrue())
+
+	// other errors are returned
+	_, err = Get(context.TODO(), fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build())
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestInject(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	data := render.MakeRenderData()
+	New(featureGate(configv1.TechPreviewNoUpgrade, nil, nil)).Inject(&data)
+	g.Expect(data.Data["FeatureGates"]).To(Equal(map[string]bool{AdminNetworkPolicy: true, IPsecV2: true, DPU: true}))
+
+	featureGate := data.Funcs["featureGate"].(func(string) (bool, error))
+	enabled, err := featureGate(AdminNetworkPolicy)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(enabled).To(BeTrue())
+
+	// the gates of other components are unknown, so that a misspelled gate fails the render
+	_, err = featureGate("APIPriorityAndFairness")
+	g.Expect(err).To(MatchError(`unknown feature gate "APIPriorityAndFairness"`))
+}
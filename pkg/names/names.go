@@ -37,6 +37,10 @@ const NonCriticalAnnotation = "networkoperator.openshift.io/non-critical"
 // tells the CNO reconciliaton engine to ignore this object if it already exists.
 const CreateOnlyAnnotation = "networkoperator.openshift.io/create-only"
 
+// ApplyRetriesAnnotation is an annotation on rendered objects to set the number of times the
+// transient failures to apply them are retried before the reconciliation fails. 0 disables retries.
+const ApplyRetriesAnnotation = "networkoperator.openshift.io/apply-retries"
+
 // NetworkMigrationAnnotation is an annotation on the networks.operator.openshift.io CR to indicate
 // that executing network migration (switching the default network type of the cluster) is allowed.
 const NetworkMigrationAnnotation = "networkoperator.openshift.io/network-migration"
@@ -45,10 +49,415 @@ const NetworkMigrationAnnotation = "networkoperator.openshift.io/network-migrati
 // to indicate the current IP Family mode of the cluster: "single-stack" or "dual-stack"
 const NetworkIPFamilyModeAnnotation = "networkoperator.openshift.io/ip-family-mode"
 
+// ConfigHashAnnotation is an annotation on the pod templates of the rendered workloads with a hash
+// of the rendered ConfigMaps and Secrets their pods use, so that their pods are rolled out when
+// the configuration changes
+const ConfigHashAnnotation = "networkoperator.openshift.io/config-hash"
+
+// HotReloadAnnotation is an annotation on the rendered ConfigMaps and Secrets the pods reload in
+// place. Set to "true", they are left out of the config-hash, so that changing them does not roll
+// out the pods.
+const HotReloadAnnotation = "networkoperator.openshift.io/hot-reload"
+
 // OVNRaftClusterInitiator is an annotation on the networks.operator.openshift.io CR to indicate
 // which node IP was the raft cluster initiator. The NB and SB DB will be initialized by the same member.
 const OVNRaftClusterInitiator = "networkoperator.openshift.io/ovn-cluster-initiator"
 
+// OVNDBMembersAnnotation is an annotation on the networks.operator.openshift.io CR with the comma-separated
+// master IPs that the OVN NB and SB raft clusters were last bootstrapped with.
+const OVNDBMembersAnnotation = "networkoperator.openshift.io/ovn-db-members"
+
+// OVNExternalAnnotation is an annotation on the networks.operator.openshift.io CR set when the nodes
+// were attached, at install time, to an external OVN deployment rather than to ovnkube-master.
+const OVNExternalAnnotation = "networkoperator.openshift.io/ovn-external"
+
+// OVNDBRemovedMembersAnnotation is an annotation on the networks.operator.openshift.io CR, and on the
+// ovnkube-db-scale-down job, with the master IPs that left the cluster and still have to be removed
+// from the OVN NB and SB raft clusters.
+const OVNDBRemovedMembersAnnotation = "networkoperator.openshift.io/ovn-db-removed-members"
+
+// OVNPrePullerModeAnnotation is an annotation on the networks.operator.openshift.io CR to select
+// how the ovn-kubernetes image is pre-pulled on nodes before upgrading ovnkube-node.
+// Valid values are "DaemonSet" (the default), "Job" and "Disabled".
+const OVNPrePullerModeAnnotation = "networkoperator.openshift.io/ovn-prepuller-mode"
+
+// OVNResourceProfileAnnotation is an annotation on the networks.operator.openshift.io CR to select
+// the resource profile of ovn-kubernetes. Valid values are "Default" and "SingleNode", which reduces
+// the footprint of OVN on single-node clusters.
+const OVNResourceProfileAnnotation = "networkoperator.openshift.io/ovn-resource-profile"
+
+// OVNNodeUpgradeModeAnnotation is an annotation on the networks.operator.openshift.io CR to select
+// how ovnkube-node is rolled out. Valid values are "Default", a rolling update, and "Conservative",
+// where the nodes are tainted and drained one at a time before their ovnkube-node pod is restarted.
+const OVNNodeUpgradeModeAnnotation = "networkoperator.openshift.io/ovn-node-upgrade-mode"
+
+// OVNNodeUpgradeTaint is the NoSchedule taint set on the node whose ovnkube-node pod is being
+// restarted by a conservative upgrade.
+const OVNNodeUpgradeTaint = "network.operator.openshift.io/ovn-node-upgrade"
+
+// OVNDisabledComponentsAnnotation is an annotation on the networks.operator.openshift.io CR with a
+// comma-separated list of OVN-Kubernetes components that are not rendered, as an emergency break-glass.
+// Only the non-critical components ("ipsec", "metrics" and "prepuller") can be disabled.
+const OVNDisabledComponentsAnnotation = "networkoperator.openshift.io/ovn-disabled-components"
+
+// OVNPolicyAuditMaxLogFilesAnnotation is an annotation on the networks.operator.openshift.io CR to set
+// how many rotated OVN ACL audit log files are kept on each node. Defaults to 5.
+const OVNPolicyAuditMaxLogFilesAnnotation = "networkoperator.openshift.io/ovn-acl-audit-max-log-files"
+
+// OVNPolicyAuditMaxLogAgeAnnotation is an annotation on the networks.operator.openshift.io CR to set
+// after how many days rotated OVN ACL audit log files are removed from the nodes. Unset keeps them
+// until they are pruned by OVNPolicyAuditMaxLogFilesAnnotation.
+const OVNPolicyAuditMaxLogAgeAnnotation = "networkoperator.openshift.io/ovn-acl-audit-max-log-age"
+
+// OVNPolicyAuditForwardingAnnotation is an annotation on the networks.operator.openshift.io CR to forward
+// the ACL audit logs of the nodes over TLS, to Kafka as "kafka://<broker>[,<broker>...]/<topic>" or to
+// an HTTP endpoint as "https://<host>[:<port>]/<path>". The forwarder runs the vector image set by
+// OVNPolicyAuditForwarderImageAnnotation.
+const OVNPolicyAuditForwardingAnnotation = "networkoperator.openshift.io/ovn-acl-audit-forwarding"
+
+// OVNPolicyAuditForwarderImageAnnotation is an annotation on the networks.operator.openshift.io CR with the
+// vector image forwarding the ACL audit logs, which the release does not ship.
+const OVNPolicyAuditForwarderImageAnnotation = "networkoperator.openshift.io/ovn-acl-audit-forwarder-image"
+
+// OVNLBAffinityTimeoutAnnotation is an annotation on the networks.operator.openshift.io CR to set the
+// maximum session affinity timeout, in seconds, of the OVN load balancers of the services with
+// ClientIP session affinity. Unset uses the ovn-kubernetes default.
+const OVNLBAffinityTimeoutAnnotation = "networkoperator.openshift.io/ovn-lb-affinity-timeout"
+
+// OVNLBIdleTimeoutAnnotation is an annotation on the networks.operator.openshift.io CR to set after how
+// many seconds without traffic the connections through the OVN load balancers are expired. Unset uses
+// the ovn-kubernetes default.
+const OVNLBIdleTimeoutAnnotation = "networkoperator.openshift.io/ovn-lb-idle-timeout"
+
+// OVNEmptyLBEventsAnnotation is an annotation on the networks.operator.openshift.io CR that, when set to
+// "false", stops the OVN load balancers without backends from raising the events that unidle the idled
+// services. Unset keeps the idling support enabled.
+const OVNEmptyLBEventsAnnotation = "networkoperator.openshift.io/ovn-empty-lb-events"
+
+// OVNDBClientReconnectBackoffAnnotation is an annotation on the networks.operator.openshift.io CR with the
+// maximum backoff, in milliseconds, of ovnkube-master between two attempts to reconnect to the OVN NB and
+// SB databases. Unset uses the ovn-kubernetes default.
+const OVNDBClientReconnectBackoffAnnotation = "networkoperator.openshift.io/ovn-db-client-reconnect-backoff"
+
+// OVNDBClientMaxInflightTxnsAnnotation is an annotation on the networks.operator.openshift.io CR with the
+// maximum number of transactions ovnkube-master has in flight on each of the OVN NB and SB databases.
+// Unset uses the ovn-kubernetes default.
+const OVNDBClientMaxInflightTxnsAnnotation = "networkoperator.openshift.io/ovn-db-client-max-inflight-txns"
+
+// OVNEgressFirewallDNSRefreshIntervalAnnotation is an annotation on the networks.operator.openshift.io CR with
+// the interval, in seconds, at which ovnkube-master resolves again the DNS names of the EgressFirewall rules,
+// whatever the TTL of their records. Unset uses the ovn-kubernetes default.
+const OVNEgressFirewallDNSRefreshIntervalAnnotation = "networkoperator.openshift.io/ovn-egress-firewall-dns-refresh-interval"
+
+// OVNEgressFirewallDNSResolverAnnotation is an annotation on the networks.operator.openshift.io CR with the
+// upstream resolver, an IP address with an optional port, ovnkube-master resolves the DNS names of the
+// EgressFirewall rules with. Unset uses the resolvers of the master nodes.
+const OVNEgressFirewallDNSResolverAnnotation = "networkoperator.openshift.io/ovn-egress-firewall-dns-resolver"
+
+// OVNOVSDBModeAnnotation is an annotation on the networks.operator.openshift.io CR with the way
+// ovnkube-master reaches the OVN NB database: "legacy", through an ovn-nbctl daemon, which is the
+// default, or "libovsdb", with its native OVSDB client.
+const OVNOVSDBModeAnnotation = "networkoperator.openshift.io/ovn-ovsdb-mode"
+
+// OVNTxnBatchSizeAnnotation is an annotation on the networks.operator.openshift.io CR with the maximum
+// number of operations ovnkube-master batches in a single transaction on the OVN databases. Unset uses
+// the ovn-kubernetes default.
+const OVNTxnBatchSizeAnnotation = "networkoperator.openshift.io/ovn-txn-batch-size"
+
+// OVNLflowCacheLimitAnnotation is an annotation on the networks.operator.openshift.io CR with the maximum
+// number of entries of the logical flow cache of ovn-controller on each node. Unset uses the OVN default.
+const OVNLflowCacheLimitAnnotation = "networkoperator.openshift.io/ovn-lflow-cache-limit"
+
+// OVNV4InternalSubnetAnnotation and OVNV6InternalSubnetAnnotation are annotations on the
+// networks.operator.openshift.io CR with the join subnets of ovn-kubernetes, connecting the gateway
+// routers of the nodes to the cluster router, in place of 100.64.0.0/16 and fd98::/64, when the
+// infrastructure of the cluster already uses them. Like the masquerade subnets, they should be set at
+// installation.
+const OVNV4InternalSubnetAnnotation = "networkoperator.openshift.io/ovn-v4-internal-subnet"
+const OVNV6InternalSubnetAnnotation = "networkoperator.openshift.io/ovn-v6-internal-subnet"
+
+// OVNV4MasqueradeSubnetAnnotation and OVNV6MasqueradeSubnetAnnotation are annotations on the
+// networks.operator.openshift.io CR with the internal subnets ovn-kubernetes masquerades the host
+// traffic of the services with, in place of 169.254.169.0/29 and fd69::/125, when those collide with
+// the networks of the cluster, e.g. a metadata service. They should be set at installation: the
+// subnets are configured on every node.
+const OVNV4MasqueradeSubnetAnnotation = "networkoperator.openshift.io/ovn-v4-masquerade-subnet"
+const OVNV6MasqueradeSubnetAnnotation = "networkoperator.openshift.io/ovn-v6-masquerade-subnet"
+
+// OVNNodeLocalDNSAnnotation is an annotation on the networks.operator.openshift.io CR with the link-local
+// IPv4 address a node-local DNS cache listens on, on every node, or "true" for 169.254.20.10. The pods
+// then reach the cache on the host, instead of having their DNS queries SNATed out of the node.
+const OVNNodeLocalDNSAnnotation = "networkoperator.openshift.io/ovn-node-local-dns"
+
+// OVNDBMaintenanceScheduleAnnotation is an annotation on the networks.operator.openshift.io CR with the
+// cron schedule of the maintenance window, e.g. "0 3 * * 6", during which the OVN NB and SB databases
+// are compacted on every master.
+const OVNDBMaintenanceScheduleAnnotation = "networkoperator.openshift.io/ovn-db-maintenance-schedule"
+
+// OVNDBMaintenanceSnapshotAnnotation is an annotation on the networks.operator.openshift.io CR that, when
+// set to "true", keeps a snapshot of the compacted OVN databases on the masters during the maintenance
+// window, and has ovsdb-server release the memory freed by the compaction.
+const OVNDBMaintenanceSnapshotAnnotation = "networkoperator.openshift.io/ovn-db-maintenance-snapshot"
+
+// OVNMinimalRBACAnnotation is an annotation on the networks.operator.openshift.io CR that, when set
+// to "true", renders a ServiceAccount with narrowly scoped permissions for each of the auxiliary
+// ovn-kubernetes workloads, and drops the permissions ovnkube-master and ovnkube-node do not need
+// from their ClusterRoles.
+const OVNMinimalRBACAnnotation = "networkoperator.openshift.io/ovn-minimal-rbac"
+
+// OVNUpgradeHoldAnnotation is an annotation on the networks.operator.openshift.io CR that, when set
+// to "true", keeps ovnkube-master and ovnkube-node at their current release when the operator is
+// upgraded, until it is removed, so that the rollout of the dataplane can wait for a maintenance window.
+const OVNUpgradeHoldAnnotation = "networkoperator.openshift.io/ovn-upgrade-hold"
+
+// OVNControlPlaneOnlyWindowsAnnotation is an annotation on the networks.operator.openshift.io CR with the
+// semicolon-separated weekly windows, in UTC, like "Mon-Fri 08:00-18:00", during which only the control plane
+// of ovn-kubernetes is updated: the ovnkube-node rollouts are deferred until the window ends.
+const OVNControlPlaneOnlyWindowsAnnotation = "networkoperator.openshift.io/ovn-control-plane-only-windows"
+
+// OVNAdaptiveTuningAnnotation is an annotation on the networks.operator.openshift.io CR that, when set to
+// "true", relaxes the database client limits of ovnkube-master and the inactivity probes of the OVN databases
+// while many nodes are joining the cluster, as on a scale-up of the cluster autoscaler, and reverts them once
+// the nodes settled.
+const OVNAdaptiveTuningAnnotation = "networkoperator.openshift.io/ovn-adaptive-tuning"
+
+// OVNNamespaceHardeningAnnotation is an annotation on the networks.operator.openshift.io CR that, when set
+// to "true", renders NetworkPolicies denying the ingress traffic to the pods of the openshift-ovn-kubernetes
+// namespace, except to the OVN database, RAFT and metrics ports from their expected peers.
+const OVNNamespaceHardeningAnnotation = "networkoperator.openshift.io/ovn-namespace-hardening"
+
+// OVNDBEndpointNameAnnotation is an annotation on the networks.operator.openshift.io CR with a DNS name,
+// resolving to the masters, that the nodes reach the OVN databases on instead of the master IPs.
+const OVNDBEndpointNameAnnotation = "networkoperator.openshift.io/ovn-db-endpoint-name"
+
+// OVNReservedNodeSubnetsAnnotation is an annotation on the networks.operator.openshift.io CR with the
+// comma-separated ranges of the cluster networks that ovnkube-master allocates no node subnet from, to keep
+// them for future node pools.
+const OVNReservedNodeSubnetsAnnotation = "networkoperator.openshift.io/ovn-reserved-node-subnets"
+
+// OVNCrashForensicsAnnotation is an annotation on the networks.operator.openshift.io CR with the number
+// of crash reports, between 1 and 10, kept on each node when an ovnkube container crash-loops or an OVS
+// or OVN daemon dumps core. Unset disables the collection of the crash reports.
+const OVNCrashForensicsAnnotation = "networkoperator.openshift.io/ovn-crash-forensics"
+
+// OVSDatapathMetricsAnnotation is an annotation on the networks.operator.openshift.io CR with the number
+// of datapath flows of a node, between 1 and 200000, above which the OVSDatapathFlowsCeilingExceeded alert
+// fires. Setting it exports the datapath flow counts and the megaflow cache hit rates of OVS on each node,
+// with the alerts on them. Unset disables their collection.
+const OVSDatapathMetricsAnnotation = "networkoperator.openshift.io/ovs-datapath-metrics"
+
+// IPsecModeAnnotation is an annotation on the networks.operator.openshift.io CR with the IPsec mode of
+// OVNKubernetes, when IPsec is enabled: "Internal", the default, encrypts the traffic between the nodes,
+// and "Full" also encrypts the traffic of the nodes to the IPsecExternalGateways.
+const IPsecModeAnnotation = "networkoperator.openshift.io/ipsec-mode"
+
+// IPsecIKEAnnotation and IPsecESPAnnotation are annotations on the networks.operator.openshift.io CR with the
+// comma-separated libreswan proposals, like "aes_gcm256-sha2_256-dh19", of the IKEv2 and ESP negotiations
+// with the IPsecExternalGateways. Only FIPS-approved algorithms are accepted on FIPS clusters.
+const (
+	IPsecIKEAnnotation = "networkoperator.openshift.io/ipsec-ike"
+	IPsecESPAnnotation = "networkoperator.openshift.io/ipsec-esp"
+)
+
+// IPsecIKELifetimeAnnotation and IPsecSALifetimeAnnotation are annotations on the networks.operator.openshift.io
+// CR with the rekey intervals, in seconds between 60 and 86400, of the IKE and IPsec SAs with the
+// IPsecExternalGateways.
+const (
+	IPsecIKELifetimeAnnotation = "networkoperator.openshift.io/ipsec-ike-lifetime"
+	IPsecSALifetimeAnnotation  = "networkoperator.openshift.io/ipsec-sa-lifetime"
+)
+
+// IPsecEncapsulationAnnotation is an annotation on the networks.operator.openshift.io CR with the UDP
+// encapsulation of the ESP packets: "Auto", the default, encapsulates them when a NAT is detected, and
+// "Always" forces it, for the networks dropping ESP.
+const IPsecEncapsulationAnnotation = "networkoperator.openshift.io/ipsec-encapsulation"
+
+// OVNCrashForensicsLabel is the label of the ConfigMaps holding the crash reports of each node, whose
+// NodeAnnotation is the node the reports were collected on.
+const OVNCrashForensicsLabel = "network.operator.openshift.io/ovn-crash-forensics"
+
+// NodeAnnotation is an annotation with the name of the node an object is about
+const NodeAnnotation = "network.operator.openshift.io/node"
+
+// CNILatencyProbeAnnotation is an annotation on the networks.operator.openshift.io CR that, when set to
+// "true", deploys the cni-latency-probe daemonset, which exports histograms of the latency of the CNI
+// ADD and DEL commands on each node.
+const CNILatencyProbeAnnotation = "networkoperator.openshift.io/cni-latency-probe"
+
+// NodeMaxUnavailableAnnotation and NodeMaxSurgeAnnotation are annotations on the networks.operator.openshift.io
+// CR with the maximum number, or percentage, of nodes whose pods of the ovnkube-node and multus DaemonSets are
+// respectively unavailable and surged during a rollout. ovnkube-node, which binds host ports, is not surged.
+const (
+	NodeMaxUnavailableAnnotation = "networkoperator.openshift.io/node-max-unavailable"
+	NodeMaxSurgeAnnotation       = "networkoperator.openshift.io/node-max-surge"
+)
+
+// NetworkTypeAnnotation is an annotation on the networks.operator.openshift.io CR recording the default
+// network type last deployed on the nodes, so that a change of the network type is noticed.
+const NetworkTypeAnnotation = "networkoperator.openshift.io/network-type"
+
+// NetworkCleanupAnnotation is an annotation on the networks.operator.openshift.io CR, and on the
+// network-cleanup daemonset, with the former default network type whose state is cleaned up on the nodes.
+const NetworkCleanupAnnotation = "networkoperator.openshift.io/network-cleanup"
+
+// ExcludedObjectsAnnotation is an annotation on the networks.operator.openshift.io CR with a comma-separated
+// list of "<kind>[.<group>]/[<namespace>/]<name>" objects that are left out of the rendered manifests, and
+// so removed from the cluster. The objects the pod network requires cannot be excluded.
+const ExcludedObjectsAnnotation = "networkoperator.openshift.io/excluded-objects"
+
+// OVSFlowsConfigConvertedAnnotation is an annotation on the openshift-network-operator/ovs-flows-config ConfigMap,
+// set to "true" once it is converted to the OVSFlowsConfig superseding it. The ConfigMap is then ignored.
+const OVSFlowsConfigConvertedAnnotation = "networkoperator.openshift.io/ovs-flows-config-converted"
+
+// ThirdPartyCNIDaemonSetAnnotation is an annotation on the networks.operator.openshift.io CR with the
+// "<namespace>/<name>" of the DaemonSet of a third-party default network, whose readiness is reported
+// in the operator status.
+const ThirdPartyCNIDaemonSetAnnotation = "networkoperator.openshift.io/third-party-cni-daemonset"
+
+// ThirdPartyCNIConfigFileAnnotation is an annotation on the networks.operator.openshift.io CR with the
+// name of the CNI configuration file a third-party default network writes in the Multus configuration
+// directory once it is ready on a node. Multus waits for it before serving pods.
+const ThirdPartyCNIConfigFileAnnotation = "networkoperator.openshift.io/third-party-cni-config-file"
+
+// OVNDebugAnnotation is an annotation on the networks.operator.openshift.io CR that, when set to "true",
+// deploys the ovnkube-debug pod, with ovn-nbctl and ovn-sbctl preconfigured to reach the OVN databases.
+const OVNDebugAnnotation = "networkoperator.openshift.io/ovn-debug"
+
+// OVNDebugDumpAnnotation is an annotation on the networks.operator.openshift.io CR requesting a dump of
+// the OVN NB and SB databases into the ovn-db-dump ConfigMap. Changing its value requests a new dump.
+// It implies OVNDebugAnnotation.
+const OVNDebugDumpAnnotation = "networkoperator.openshift.io/ovn-debug-dump"
+
+// OVNToolsAnnotation is an annotation on the networks.operator.openshift.io CR that, when set to "true",
+// deploys the ovn-tools DaemonSet, with ovnkube-trace, tcpdump and the OVS and OVN ctl commands
+// preconfigured on every node.
+const OVNToolsAnnotation = "networkoperator.openshift.io/ovn-tools"
+
+// ForceUnsafeChangeAnnotation is an annotation on the networks.operator.openshift.io CR that forces
+// the unsafe changes of the genevePort and of the hybrid overlay of ovn-kubernetes, with a restart of
+// all of the ovn-kubernetes pods. Its value identifies the request: each value forces a single change.
+const ForceUnsafeChangeAnnotation = "networkoperator.openshift.io/force-unsafe-change"
+
+// MultitenantIsolationAnnotation is an annotation on the networks.operator.openshift.io CR that, when
+// set to "true" on a cluster migrated from the Multitenant mode of openshift-sdn, preserves the
+// isolation of its namespaces with NetworkPolicies.
+const MultitenantIsolationAnnotation = "networkoperator.openshift.io/migration-multitenant-isolation"
+
+// MachineConfigAnnotation is an annotation on the networks.operator.openshift.io CR that, when set to
+// "true", has the operator generate the MachineConfigs holding the prerequisites of the network on the
+// nodes, instead of them being written by hand.
+const MachineConfigAnnotation = "networkoperator.openshift.io/machine-config"
+
+// BrExInterfaceAnnotation is an annotation on the networks.operator.openshift.io CR naming the uplink
+// interface of the nodes that, with MachineConfigAnnotation, has the operator generate the NetworkManager
+// keyfiles of the br-ex bridge of ovn-kubernetes, in place of the configure-ovs script of the nodes.
+const BrExInterfaceAnnotation = "networkoperator.openshift.io/br-ex-interface"
+
+// NetworkingConsolePluginAnnotation is an annotation on the networks.operator.openshift.io CR that, when set
+// to "true", deploys the networking plugin of the OpenShift console, with the topology and traffic views of
+// the network.
+const NetworkingConsolePluginAnnotation = "networkoperator.openshift.io/networking-console-plugin"
+
+// OVNMultiExternalGatewayAnnotation is an annotation on the networks.operator.openshift.io CR that, when
+// set to "true", enables the multiple external gateways of ovn-kubernetes, configured by the admin with
+// AdminPolicyBasedExternalRoute resources.
+const OVNMultiExternalGatewayAnnotation = "networkoperator.openshift.io/ovn-multi-external-gateway"
+
+// OVNExternalGatewayBFDAnnotation is an annotation on the networks.operator.openshift.io CR that, when
+// set to "true", makes BFD the default of the next hops of the AdminPolicyBasedExternalRoute resources.
+// It requires OVNMultiExternalGatewayAnnotation.
+const OVNExternalGatewayBFDAnnotation = "networkoperator.openshift.io/ovn-external-gateway-bfd"
+
+// OVNMasterNodeSelectorAnnotation is an annotation on the networks.operator.openshift.io CR with the
+// equality-based label selector (e.g. "node-role.kubernetes.io/network=") of the nodes hosting the OVN
+// masters and databases. Defaults to the master nodes.
+const OVNMasterNodeSelectorAnnotation = "networkoperator.openshift.io/ovn-master-node-selector"
+
+// OVNMasterTolerationsAnnotation is an annotation on the networks.operator.openshift.io CR with the
+// comma-separated keys of the taints tolerated by the OVN masters and databases (e.g.
+// "node-role.kubernetes.io/network"). Defaults to the master taint.
+const OVNMasterTolerationsAnnotation = "networkoperator.openshift.io/ovn-master-tolerations"
+
+// OVNMasterTopologyKeyAnnotation is an annotation on the networks.operator.openshift.io CR with the node
+// label key (e.g. "topology.kubernetes.io/zone") of the topology domains the OVN masters and databases are
+// spread across, one per domain. It is ignored unless the masters found at bootstrap are each in a distinct domain.
+const OVNMasterTopologyKeyAnnotation = "networkoperator.openshift.io/ovn-master-topology-key"
+
+// OVNPreprovisionedNodesAnnotation is an annotation on the networks.operator.openshift.io CR with the
+// comma-separated names of the nodes whose OVN identity, their chassis ID and IPsec certificate, is generated
+// by the operator ahead of their installation, and delivered to them in an ignition config.
+const OVNPreprovisionedNodesAnnotation = "networkoperator.openshift.io/ovn-preprovisioned-nodes"
+
+// OVNManagementNetworkAnnotation is an annotation on the networks.operator.openshift.io CR that, when
+// set to "true", moves the OVN NB/SB DB and raft traffic onto the management network of the master nodes.
+// The management address of each master is read from the OVNManagementIPNodeAnnotation node annotation.
+const OVNManagementNetworkAnnotation = "networkoperator.openshift.io/ovn-management-network"
+
+// OVNManagementIPNodeAnnotation is an annotation on master nodes holding the IP address, on the
+// management VRF or interface, that the OVN databases of that node should be reached on.
+const OVNManagementIPNodeAnnotation = "network.operator.openshift.io/ovn-management-ip"
+
+// OVNLogLevelNodeAnnotation is an annotation on nodes with the log levels to set, without restarting
+// them, on the OVS and OVN daemons of the node, as comma-separated "<daemon>=<level>" pairs, for example
+// "ovn-controller=dbg,ovs-vswitchd=dbg". The daemons are set back to "info" when it is removed.
+const OVNLogLevelNodeAnnotation = "network.operator.openshift.io/ovn-log-level"
+
+// OVNLogLevelAppliedNodeAnnotation is an annotation on nodes recording the log levels last set on the node,
+// and the ovnkube-node pod they were set through.
+const OVNLogLevelAppliedNodeAnnotation = "network.operator.openshift.io/ovn-log-level-applied"
+
+// OVNLBHairpinSNATIPServiceAnnotation, OVNLBAffinityTimeoutServiceAnnotation and OVNLBSkipSNATServiceAnnotation
+// are annotations on services that set the hairpin_snat_ip, affinity_timeout and skip_snat options of their OVN
+// load balancers: the addresses, one per IP family, that the traffic of a backend to itself through the service
+// is SNATed to; the seconds the backend chosen for a client is kept; and, when "true", not SNATing the traffic
+// to the backends reached through the gateway router.
+const (
+	OVNLBHairpinSNATIPServiceAnnotation   = "network.operator.openshift.io/ovn-lb-hairpin-snat-ip"
+	OVNLBAffinityTimeoutServiceAnnotation = "network.operator.openshift.io/ovn-lb-affinity-timeout"
+	OVNLBSkipSNATServiceAnnotation        = "network.operator.openshift.io/ovn-lb-skip-snat"
+)
+
+// OVNLBOptionsAppliedServiceAnnotation is an annotation on services recording the options last set on
+// their OVN load balancers, as comma-separated option names, so that they are removed once unrequested.
+const OVNLBOptionsAppliedServiceAnnotation = "network.operator.openshift.io/ovn-lb-options-applied"
+
+// OVNLBOptionsNamespacesAnnotation is an annotation on the networks.operator.openshift.io CR listing, comma-separated,
+// the namespaces whose services can set the options of their OVN load balancers. Unset, no namespace can.
+const OVNLBOptionsNamespacesAnnotation = "networkoperator.openshift.io/ovn-lb-options-namespaces"
+
+// UplinkMTUNodeAnnotation is an annotation on nodes with the MTU of their uplink, published by the
+// ovnkube-node pods. It is the lowest MTU of the interface of the default route and of the interfaces
+// under it, like the ports of the gateway bridge and the members of a bond or a team.
+const UplinkMTUNodeAnnotation = "network.operator.openshift.io/uplink-mtu"
+
+// PodNetworkCheckNodeAnnotation is an annotation on nodes with the result of the east-west check of the
+// pod network run by the ovnkube-node pod of the node once it starts, as "<passed|failed> <release>".
+// On upgrades, ovnkube-master is only updated once the nodes passed the check of the new release.
+const PodNetworkCheckNodeAnnotation = "network.operator.openshift.io/pod-network-check"
+
+// OVNNodeSubnetsAnnotation is set by ovnkube-master on every node with the subnet(s) allocated to it,
+// as {"default":"10.128.0.0/23"} or, on dual-stack clusters, {"default":["10.128.0.0/23","fd01::/64"]}.
+const OVNNodeSubnetsAnnotation = "k8s.ovn.org/node-subnets"
+
+// NodeSubnetsConfigMap is the ConfigMap, in the APPLIED_NAMESPACE, holding as JSON the pod subnets
+// allocated to each node, with the number of pod IPs in use out of them.
+const NodeSubnetsConfigMap = "node-subnets"
+
+// VerifyFlowCollectorsAnnotation is an annotation on the operator configuration which, when "true",
+// has the operator periodically resolve and probe the NetFlow, sFlow and IPFIX collectors the flows
+// are exported to, and report the unreachable ones in its status.
+const VerifyFlowCollectorsAnnotation = "networkoperator.openshift.io/verify-flow-collectors"
+
+// MTUProbeAnnotation is an annotation on the operator configuration which, when "true", deploys the
+// network-check-mtu daemonset of the network diagnostics, which pings the other nodes with the don't
+// fragment bit set at the MTU the overlay needs, and has the operator report the node pairs whose path
+// MTU is too low in its status.
+const MTUProbeAnnotation = "networkoperator.openshift.io/mtu-probe"
+
+// MTUProbeResultAnnotation is set by each network-check-mtu pod on itself with the result of its last
+// probe, as JSON: the probed MTU, and the peer nodes it cannot reach at that MTU.
+const MTUProbeResultAnnotation = "network.operator.openshift.io/mtu-probe-result"
+
 // RolloutHungAnnotation is set to "" if it is detected that a rollout
 // (i.e. DaemonSet or Deployment) is not making progress, unset otherwise.
 const RolloutHungAnnotation = "networkoperator.openshift.io/rollout-hung"
@@ -9,16 +9,17 @@ import (
 	cnitypes "github.com/containernetworking/cni/pkg/types"
 
 	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/featuregates"
 	"github.com/openshift/cluster-network-operator/pkg/render"
 	"github.com/pkg/errors"
 	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 // renderAdditionalNetworksCRD returns the manifests of the NetworkAttachmentDefinition.
-func renderAdditionalNetworksCRD(manifestDir string) ([]*uns.Unstructured, error) {
+func renderAdditionalNetworksCRD(manifestDir string, featureGates featuregates.FeatureGates) ([]*uns.Unstructured, error) {
 	objs := []*uns.Unstructured{}
 	// render the manifests on disk
-	data := render.MakeRenderData()
+	data := makeRenderData(featureGates)
 	manifests, err := render.RenderDir(filepath.Join(manifestDir, "network/additional-networks/crd"), &data)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to render additional network manifests")
@@ -28,11 +29,11 @@ func renderAdditionalNetworksCRD(manifestDir string) ([]*uns.Unstructured, error
 }
 
 // renderRawCNIConfig returns the RawCNIConfig manifests
-func renderRawCNIConfig(conf *operv1.AdditionalNetworkDefinition, manifestDir string) ([]*uns.Unstructured, error) {
+func renderRawCNIConfig(conf *operv1.AdditionalNetworkDefinition, manifestDir string, featureGates featuregates.FeatureGates) ([]*uns.Unstructured, error) {
 	var err error
 
 	// render RawCNIConfig manifests
-	data := render.MakeRenderData()
+	data := makeRenderData(featureGates)
 	data.Data["AdditionalNetworkName"] = conf.Name
 	data.Data["AdditionalNetworkNamespace"] = conf.Namespace
 	data.Data["AdditionalNetworkConfig"] = conf.RawCNIConfig
@@ -133,11 +134,11 @@ func getIPAMConfigJSON(conf *operv1.IPAMConfig) (string, error) {
 }
 
 // renderSimpleMacvlanConfig returns the SimpleMacvlanConfig manifests
-func renderSimpleMacvlanConfig(conf *operv1.AdditionalNetworkDefinition, manifestDir string) ([]*uns.Unstructured, error) {
+func renderSimpleMacvlanConfig(conf *operv1.AdditionalNetworkDefinition, manifestDir string, featureGates featuregates.FeatureGates) ([]*uns.Unstructured, error) {
 	var err error
 
 	// render SimpleMacvlanConfig manifests
-	data := render.MakeRenderData()
+	data := makeRenderData(featureGates)
 	data.Data["AdditionalNetworkName"] = conf.Name
 	data.Data["AdditionalNetworkNamespace"] = conf.Namespace
 
@@ -7,6 +7,7 @@ import (
 
 	. "github.com/onsi/gomega"
 	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/featuregates"
 )
 
 var NetworkAttachmentConfigRaw = operv1.Network{
@@ -74,7 +75,7 @@ var NilStaticIPAMConfig = operv1.IPAMConfig{
 func TestRenderAdditionalNetworksCRD(t *testing.T) {
 	g := NewGomegaWithT(t)
 
-	objs, err := renderAdditionalNetworksCRD(manifestDir)
+	objs, err := renderAdditionalNetworksCRD(manifestDir, featuregates.FeatureGates{})
 	g.Expect(err).NotTo(HaveOccurred())
 	g.Expect(objs).To(HaveLen(3))
 }
@@ -83,7 +84,7 @@ func TestRenderRawCNIConfig(t *testing.T) {
 	g := NewGomegaWithT(t)
 
 	for _, cfg := range NetworkAttachmentConfigRaw.Spec.AdditionalNetworks {
-		objs, err := renderRawCNIConfig(&cfg, manifestDir)
+		objs, err := renderRawCNIConfig(&cfg, manifestDir, featuregates.FeatureGates{})
 		g.Expect(err).NotTo(HaveOccurred())
 		g.Expect(objs).To(HaveLen(1))
 
@@ -125,7 +126,7 @@ func TestRenderSimpleMacvlanConfig(t *testing.T) {
 	g := NewGomegaWithT(t)
 
 	for _, cfg := range NetworkAttachmentConfigSimpleMacvlan.Spec.AdditionalNetworks {
-		objs, err := renderSimpleMacvlanConfig(&cfg, manifestDir)
+		objs, err := renderSimpleMacvlanConfig(&cfg, manifestDir, featuregates.FeatureGates{})
 		g.Expect(err).NotTo(HaveOccurred())
 		g.Expect(objs).To(HaveLen(1))
 		g.Expect(objs).To(
@@ -1,23 +1,91 @@
 package network
 
 import (
+	"strconv"
+
 	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/platform"
 	"github.com/openshift/cluster-network-operator/pkg/platform/openstack"
+	"github.com/openshift/cluster-network-operator/pkg/util/logging"
+	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	operv1 "github.com/openshift/api/operator/v1"
 )
 
+var bootstrapLog = logging.Logger(logging.Bootstrap)
+
 // Bootstrap creates resources required by SDN on the cloud.
 func Bootstrap(conf *operv1.Network, client client.Client) (*bootstrap.BootstrapResult, error) {
+	var res *bootstrap.BootstrapResult
+	bootstrapLog.V(1).Info("Starting bootstrap phase", "networkType", conf.Spec.DefaultNetwork.Type)
+	tuning, err := bootstrapOperatorTuning(client)
+	if err != nil {
+		return nil, err
+	}
 	switch conf.Spec.DefaultNetwork.Type {
 	case operv1.NetworkTypeKuryr:
-		return openstack.BootstrapKuryr(&conf.Spec, client)
+		res, err = openstack.BootstrapKuryr(&conf.Spec, client)
 	case operv1.NetworkTypeOpenShiftSDN:
-		return bootstrapSDN(conf, client)
+		res, err = bootstrapSDN(conf, client)
 	case operv1.NetworkTypeOVNKubernetes:
-		return bootstrapOVN(conf, client)
+		res, err = bootstrapOVN(conf, client, &tuning)
+	default:
+		// the components deployed whatever the default network, such as Multus, still follow the
+		// platform, the FIPS mode and the TLS security profile of the cluster
+		var infraRes *bootstrap.InfraBootstrapResult
+		if infraRes, err = platform.BootstrapInfra(client); err == nil {
+			res = &bootstrap.BootstrapResult{Infra: *infraRes}
+		}
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return &bootstrap.BootstrapResult{}, nil
+	res.Tuning = tuning
+	bootstrapUplinkMTU(res, client)
+	if err := bootstrapFeatureGates(client, res); err != nil {
+		return nil, err
+	}
+	res.CNILatencyProbe = bootstrapCNILatencyProbe(conf)
+	res.MTUProbe = bootstrapMTUProbe(conf)
+	res.NodeRollout = bootstrapNodeRollout(conf)
+	if res.ConntrackTuning, err = bootstrapConntrackTuning(client); err != nil {
+		return nil, err
+	}
+	if res.MachineConfigs, err = bootstrapMachineConfigs(conf, client); err != nil {
+		return nil, err
+	}
+	res.BrExInterface = bootstrapBrExInterface(conf)
+	if res.NetworkingConsolePlugin, err = bootstrapNetworkingConsolePlugin(conf, client); err != nil {
+		return nil, err
+	}
+	if err := bootstrapNetworkCleanup(conf, client, res); err != nil {
+		return nil, err
+	}
+	bootstrapThirdPartyCNI(conf, client, res)
+	res.ExcludedObjects = bootstrapExcludedObjects(conf, res)
+
+	if conf.Spec.DeployKubeProxy != nil && *conf.Spec.DeployKubeProxy {
+		if res.KubeProxy, err = bootstrapKubeProxy(client); err != nil {
+			return nil, err
+		}
+	}
+	bootstrapLog.V(1).Info("Bootstrap phase done", "networkType", conf.Spec.DefaultNetwork.Type)
+	return res, nil
+}
+
+// boolAnnotation returns the value of a boolean annotation on the operator configuration, or def when
+// it is unset or not a boolean.
+func boolAnnotation(conf *operv1.Network, key string, def bool) bool {
+	v, ok := conf.GetAnnotations()[key]
+	if !ok {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		klog.Warningf("%s must be a boolean, is: %q. Using: %t", key, v, def)
+		return def
+	}
+	return b
 }
@@ -0,0 +1,221 @@
+package network
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operv1 "github.com/openshift/api/operator/v1"
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	. "github.com/onsi/gomega"
+)
+
+// The envtest suite runs the Bootstrap stage against a real apiserver, with the CRDs the operator reads. It
+// needs the kube-apiserver and etcd binaries, in the KUBEBUILDER_ASSETS directory, and is skipped otherwise:
+//
+//   KUBEBUILDER_ASSETS=/usr/local/kubebuilder/bin go test ./pkg/network -run Envtest
+
+// envtestCRDs are the CRDs installed in the apiserver: those of the operator, and those of the
+// cluster configuration read while bootstrapping
+var envtestCRDs = []string{
+	"../../manifests",
+	"../../vendor/github.com/openshift/api/config/v1/0000_10_config-operator_01_infrastructure.crd.yaml",
+	"../../vendor/github.com/openshift/api/config/v1/0000_10_config-operator_01_network.crd.yaml",
+}
+
+// startEnvtest starts an apiserver, stopped when the test completes, and returns a client of it
+func startEnvtest(t *testing.T) crclient.Client {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		t.Skip("KUBEBUILDER_ASSETS is not set, skipping the envtest suite")
+	}
+	env := &envtest.Environment{
+		CRDInstallOptions: envtest.CRDInstallOptions{
+			Paths:              envtestCRDs,
+			ErrorIfPathMissing: true,
+		},
+	}
+	cfg, err := env.Start()
+	if err != nil {
+		t.Fatalf("failed to start the apiserver: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := env.Stop(); err != nil {
+			t.Errorf("failed to stop the apiserver: %v", err)
+		}
+	})
+
+	scheme := runtime.NewScheme()
+	for _, install := range []func(*runtime.Scheme) error{
+		clientgoscheme.AddToScheme, operv1.Install, configv1.Install, netopv1.Install,
+	} {
+		if err := install(scheme); err != nil {
+			t.Fatalf("failed to build the scheme: %v", err)
+		}
+	}
+	cl, err := crclient.New(cfg, crclient.Options{Scheme: scheme})
+	if err != nil {
+		t.Fatalf("failed to create the client: %v", err)
+	}
+	for _, ns := range []string{names.APPLIED_NAMESPACE, "openshift-ovn-kubernetes"} {
+		if err := cl.Create(context.TODO(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}}); err != nil {
+			t.Fatalf("failed to create namespace %s: %v", ns, err)
+		}
+	}
+	return cl
+}
+
+func TestEnvtestBootstrap(t *testing.T) {
+	cl := startEnvtest(t)
+
+	t.Run("OVN", func(t *testing.T) { testEnvtestBootstrapOVN(t, cl) })
+	t.Run("OVNGatewayConfig", func(t *testing.T) { testEnvtestBootstrapOVNGatewayConfig(t, cl) })
+	t.Run("FlowsConfig", func(t *testing.T) { testEnvtestBootstrapFlowsConfig(t, cl) })
+}
+
+func testEnvtestBootstrapOVN(t *testing.T, cl crclient.Client) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+	tuning := &bootstrap.OperatorTuning{MasterDiscoveryTimeout: 5, MasterDiscoveryPoll: 1, MasterDiscoveryBackoff: 1}
+	bootstrapOVNConf := func() (*operv1.Network, *bootstrap.BootstrapResult, error) {
+		conf := OVNKubernetesConfig.DeepCopy()
+		conf.Name = names.OPERATOR_CONFIG
+		res, err := bootstrapOVN(conf, cl, tuning)
+		return conf, res, err
+	}
+
+	// cluster-config-v1 is missing
+	_, _, err := bootstrapOVNConf()
+	g.Expect(err).To(MatchError(ContainSubstring("unable to retrieve cluster config")))
+
+	// the install-config is malformed
+	clusterConfig := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: CLUSTER_CONFIG_NAMESPACE, Name: CLUSTER_CONFIG_NAME},
+		Data:       map[string]string{"install-config": "controlPlane: [replicas"},
+	}
+	g.Expect(cl.Create(ctx, clusterConfig)).To(Succeed())
+	_, _, err = bootstrapOVNConf()
+	g.Expect(err).To(MatchError(ContainSubstring("unable to unmarshal install-config")))
+
+	// the infrastructure configuration is missing
+	clusterConfig.Data["install-config"] = "controlPlane:\n  replicas: \"1\"\nnetworking:\n  machineNetwork:\n  - cidr: 10.0.0.0/16\n"
+	g.Expect(cl.Update(ctx, clusterConfig)).To(Succeed())
+	master := &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:   "master-0",
+		Labels: map[string]string{"node-role.kubernetes.io/master": ""},
+	}}
+	g.Expect(cl.Create(ctx, master)).To(Succeed())
+	master.Status.Addresses = []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "10.0.0.10"}}
+	g.Expect(cl.Status().Update(ctx, master)).To(Succeed())
+	_, _, err = bootstrapOVNConf()
+	g.Expect(err).To(MatchError(ContainSubstring("failed to get infrastructure 'cluster'")))
+
+	infra := &configv1.Infrastructure{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}}
+	g.Expect(cl.Create(ctx, infra)).To(Succeed())
+	infra.Status = configv1.InfrastructureStatus{
+		PlatformStatus:         &configv1.PlatformStatus{Type: configv1.NonePlatformType},
+		ControlPlaneTopology:   configv1.HighlyAvailableTopologyMode,
+		InfrastructureTopology: configv1.HighlyAvailableTopologyMode,
+	}
+	g.Expect(cl.Status().Update(ctx, infra)).To(Succeed())
+
+	conf, res, err := bootstrapOVNConf()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(res.OVN.MasterIPs).To(Equal([]string{"10.0.0.10"}))
+	g.Expect(res.OVN.ClusterInitiator).To(Equal("10.0.0.10"))
+	g.Expect(conf.Annotations).To(HaveKeyWithValue(names.OVNRaftClusterInitiator, "10.0.0.10"))
+	g.Expect(res.OVN.ExistingMasterDaemonset).To(BeNil())
+	g.Expect(res.OVN.ExistingNodeDaemonset).To(BeNil())
+	g.Expect(res.OVN.FlowsConfig).To(BeNil())
+	g.Expect(res.Infra.PlatformType).To(Equal(configv1.NonePlatformType))
+	g.Expect(res.OVN.OVNKubernetesConfig.NodePortRange).To(Equal(OVN_NODE_PORT_RANGE))
+	g.Expect(conf.Spec.DefaultNetwork.OVNKubernetesConfig.GatewayConfig).To(Equal(&operv1.GatewayConfig{}))
+
+	// the discovery of the masters times out short of the replicas of the install-config
+	clusterConfig.Data["install-config"] = "controlPlane:\n  replicas: \"3\"\n"
+	g.Expect(cl.Update(ctx, clusterConfig)).To(Succeed())
+	tuning.MasterDiscoveryTimeout = 2
+	start := time.Now()
+	_, res, err = bootstrapOVNConf()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(time.Since(start)).To(BeNumerically(">=", time.Second))
+	g.Expect(res.OVN.MasterIPs).To(Equal([]string{"10.0.0.10"}))
+}
+
+func testEnvtestBootstrapOVNGatewayConfig(t *testing.T, cl crclient.Client) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+	gatewayConfig := func() *operv1.GatewayConfig {
+		conf := OVNKubernetesConfig.DeepCopy()
+		bootstrapOVNGatewayConfig(conf, cl)
+		return conf.Spec.DefaultNetwork.OVNKubernetesConfig.GatewayConfig
+	}
+
+	// shared gateway mode by default
+	g.Expect(gatewayConfig()).To(Equal(&operv1.GatewayConfig{RoutingViaHost: false}))
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: names.APPLIED_NAMESPACE, Name: "gateway-mode-config"},
+		Data:       map[string]string{"mode": OVN_LOCAL_GW_MODE},
+	}
+	g.Expect(cl.Create(ctx, cm)).To(Succeed())
+	g.Expect(gatewayConfig()).To(Equal(&operv1.GatewayConfig{RoutingViaHost: true}))
+
+	// an invalid mode falls back to the shared gateway mode
+	cm.Data["mode"] = "hybrid"
+	g.Expect(cl.Update(ctx, cm)).To(Succeed())
+	g.Expect(gatewayConfig()).To(Equal(&operv1.GatewayConfig{RoutingViaHost: false}))
+
+	g.Expect(cl.Delete(ctx, cm)).To(Succeed())
+}
+
+func testEnvtestBootstrapFlowsConfig(t *testing.T, cl crclient.Client) {
+	g := NewGomegaWithT(t)
+	ctx := context.TODO()
+
+	g.Expect(bootstrapFlowsConfig(cl)).To(BeNil())
+
+	// the legacy ConfigMap
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: OVSFlowsConfigNamespace, Name: OVSFlowsConfigMapName},
+		Data:       map[string]string{"nodePort": "3131", "cacheMaxFlows": "invalid int"},
+	}
+	g.Expect(cl.Create(ctx, cm)).To(Succeed())
+	fc := bootstrapFlowsConfig(cl)
+	g.Expect(fc).NotTo(BeNil())
+	g.Expect(fc.Target).To(Equal(":3131"))
+	g.Expect(fc.CacheMaxFlows).To(BeNil())
+
+	// the OVSFlowsConfig takes precedence over the ConfigMap
+	maxFlows := int32(100)
+	flows := &netopv1.OVSFlowsConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: OVSFlowsConfigNamespace, Name: OVSFlowsConfigMapName},
+		Spec: netopv1.OVSFlowsConfigSpec{
+			SharedTarget:       "1.2.3.4:3030",
+			CacheActiveTimeout: &metav1.Duration{Duration: 3200 * time.Millisecond},
+			CacheMaxFlows:      &maxFlows,
+		},
+	}
+	g.Expect(cl.Create(ctx, flows)).To(Succeed())
+	fc = bootstrapFlowsConfig(cl)
+	g.Expect(fc).NotTo(BeNil())
+	g.Expect(fc.Target).To(Equal("1.2.3.4:3030"))
+	g.Expect(*fc.CacheActiveTimeout).To(BeEquivalentTo(3))
+	g.Expect(*fc.CacheMaxFlows).To(BeEquivalentTo(100))
+
+	// the ConfigMap converted to the OVSFlowsConfig no longer applies once it is deleted
+	cm.Annotations = map[string]string{names.OVSFlowsConfigConvertedAnnotation: "true"}
+	g.Expect(cl.Update(ctx, cm)).To(Succeed())
+	g.Expect(cl.Delete(ctx, flows)).To(Succeed())
+	g.Expect(bootstrapFlowsConfig(cl)).To(BeNil())
+}
@@ -0,0 +1,26 @@
+package network
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	operv1 "github.com/openshift/api/operator/v1"
+)
+
+func TestBoolAnnotation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	conf := &operv1.Network{}
+	g.Expect(boolAnnotation(conf, "example.com/flag", false)).To(BeFalse())
+	g.Expect(boolAnnotation(conf, "example.com/flag", true)).To(BeTrue())
+
+	conf.Annotations = map[string]string{"example.com/flag": "true"}
+	g.Expect(boolAnnotation(conf, "example.com/flag", false)).To(BeTrue())
+	conf.Annotations["example.com/flag"] = "0"
+	g.Expect(boolAnnotation(conf, "example.com/flag", true)).To(BeFalse())
+
+	// an invalid value is ignored
+	conf.Annotations["example.com/flag"] = "yes"
+	g.Expect(boolAnnotation(conf, "example.com/flag", false)).To(BeFalse())
+	g.Expect(boolAnnotation(conf, "example.com/flag", true)).To(BeTrue())
+}
@@ -9,6 +9,7 @@ import (
 	v1 "github.com/openshift/api/config/v1"
 	operv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/featuregates"
 	"github.com/openshift/cluster-network-operator/pkg/render"
 	k8sutil "github.com/openshift/cluster-network-operator/pkg/util/k8s"
 
@@ -18,12 +19,12 @@ import (
 )
 
 // renderCloudNetworkConfigController renders the cloud network config controller
-func renderCloudNetworkConfigController(conf *operv1.NetworkSpec, cloudBootstrapResult bootstrap.InfraBootstrapResult, manifestDir string) ([]*uns.Unstructured, error) {
+func renderCloudNetworkConfigController(conf *operv1.NetworkSpec, cloudBootstrapResult bootstrap.InfraBootstrapResult, manifestDir string, featureGates featuregates.FeatureGates) ([]*uns.Unstructured, error) {
 	pt := cloudBootstrapResult.PlatformType
 	if !(pt == v1.AWSPlatformType || pt == v1.AzurePlatformType || pt == v1.GCPPlatformType) {
 		return nil, nil
 	}
-	data := render.MakeRenderData()
+	data := makeRenderData(featureGates)
 	data.Data["ReleaseVersion"] = os.Getenv("RELEASE_VERSION")
 	data.Data["PlatformType"] = cloudBootstrapResult.PlatformType
 	data.Data["PlatformRegion"] = cloudBootstrapResult.PlatformRegion
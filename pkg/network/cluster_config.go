@@ -5,6 +5,7 @@ import (
 
 	configv1 "github.com/openshift/api/config/v1"
 	operv1 "github.com/openshift/api/operator/v1"
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
 	iputil "github.com/openshift/cluster-network-operator/pkg/util/ip"
 	"k8s.io/apimachinery/pkg/util/sets"
 	utilnet "k8s.io/utils/net"
@@ -180,3 +181,42 @@ func StatusFromOperatorConfig(operConf *operv1.NetworkSpec, oldStatus *configv1.
 	}
 	return &status
 }
+
+// ClusterNetworkUsageFromSubnets counts the node subnets allocated out of each
+// cluster network. Node subnets outside of every cluster network are ignored.
+func ClusterNetworkUsageFromSubnets(clusterNetworks []configv1.ClusterNetworkEntry, nodeSubnets []string) []netopv1.ClusterNetworkEntryUsage {
+	usage := make([]netopv1.ClusterNetworkEntryUsage, len(clusterNetworks))
+	cidrs := make([]*net.IPNet, len(clusterNetworks))
+	for i, cnet := range clusterNetworks {
+		usage[i] = netopv1.ClusterNetworkEntryUsage{CIDR: cnet.CIDR, HostPrefix: cnet.HostPrefix}
+		_, cidr, err := net.ParseCIDR(cnet.CIDR)
+		if err != nil {
+			continue
+		}
+		cidrs[i] = cidr
+		ones, _ := cidr.Mask.Size()
+		if bits := int(cnet.HostPrefix) - ones; bits >= 0 && bits < 63 {
+			usage[i].TotalSubnets = 1 << uint(bits)
+		}
+	}
+
+	for _, subnet := range nodeSubnets {
+		ip, _, err := net.ParseCIDR(subnet)
+		if err != nil {
+			continue
+		}
+		for i, cidr := range cidrs {
+			if cidr != nil && cidr.Contains(ip) {
+				usage[i].AllocatedSubnets++
+				break
+			}
+		}
+	}
+
+	for i := range usage {
+		if usage[i].TotalSubnets > 0 {
+			usage[i].UtilizationPercent = int32(int64(usage[i].AllocatedSubnets) * 100 / usage[i].TotalSubnets)
+		}
+	}
+	return usage
+}
@@ -5,6 +5,7 @@ import (
 
 	configv1 "github.com/openshift/api/config/v1"
 	operv1 "github.com/openshift/api/operator/v1"
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
 
 	. "github.com/onsi/gomega"
 )
@@ -295,3 +296,30 @@ func TestStatusFromConfigUnknown(t *testing.T) {
 		NetworkType: "None",
 	}))
 }
+
+func TestClusterNetworkUsageFromSubnets(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	clusterNetworks := []configv1.ClusterNetworkEntry{
+		{CIDR: "10.128.0.0/14", HostPrefix: 23},
+		{CIDR: "10.0.0.0/22", HostPrefix: 24},
+		{CIDR: "fd01::/48", HostPrefix: 64},
+	}
+	nodeSubnets := []string{
+		"10.128.0.0/23",
+		"10.128.2.0/23",
+		"10.129.0.0/23",
+		"10.0.1.0/24",
+		"fd01:0:0:1::/64",
+		// outside of any cluster network
+		"172.16.0.0/24",
+		"garbage",
+	}
+
+	usage := ClusterNetworkUsageFromSubnets(clusterNetworks, nodeSubnets)
+	g.Expect(usage).To(Equal([]netopv1.ClusterNetworkEntryUsage{
+		{CIDR: "10.128.0.0/14", HostPrefix: 23, AllocatedSubnets: 3, TotalSubnets: 512, UtilizationPercent: 0},
+		{CIDR: "10.0.0.0/22", HostPrefix: 24, AllocatedSubnets: 1, TotalSubnets: 4, UtilizationPercent: 25},
+		{CIDR: "fd01::/48", HostPrefix: 64, AllocatedSubnets: 1, TotalSubnets: 65536, UtilizationPercent: 0},
+	}))
+}
@@ -0,0 +1,48 @@
+package network
+
+import (
+	"os"
+	"path/filepath"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/openshift/cluster-network-operator/pkg/render"
+	"github.com/pkg/errors"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+)
+
+// bootstrapCNILatencyProbe returns whether the CNI latency probe is requested by an
+// annotation on the operator configuration
+func bootstrapCNILatencyProbe(conf *operv1.Network) bool {
+	return boolAnnotation(conf, names.CNILatencyProbeAnnotation, false)
+}
+
+// renderCNILatencyProbe generates the manifests of the CNI latency probe, which runs the CNI
+// plugins installed on each node against a dummy network namespace and exports the latency of
+// their ADD and DEL commands, so that the pod network setup time can be tracked against an SLO.
+func renderCNILatencyProbe(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult, manifestDir string) ([]*uns.Unstructured, error) {
+	if !bootstrapResult.CNILatencyProbe {
+		return nil, nil
+	}
+	// the probe is deployed next to multus, and scraped like the network metrics daemon
+	if *conf.DisableMultiNetwork {
+		klog.Warningf("The CNI latency probe requires Multus, not rendering it")
+		return nil, nil
+	}
+
+	data := makeRenderData(bootstrapResult.FeatureGates)
+	renderTLS(&data, &bootstrapResult.Infra)
+	data.Data["ReleaseVersion"] = os.Getenv("RELEASE_VERSION")
+	// the probe script needs bash, iproute and python3, which the OVN image ships
+	data.Data["CNILatencyProbeImage"] = os.Getenv("OVN_IMAGE")
+	data.Data["KubeRBACProxyImage"] = os.Getenv("KUBE_RBAC_PROXY_IMAGE")
+	data.Data["CNIBinDir"] = cniBinDir()
+
+	manifests, err := render.RenderDir(filepath.Join(manifestDir, "network/cni-latency-probe"), &data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render cni-latency-probe manifests")
+	}
+	return manifests, nil
+}
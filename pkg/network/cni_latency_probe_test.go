@@ -0,0 +1,59 @@
+package network
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+func TestBootstrapCNILatencyProbe(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	conf := &operv1.Network{}
+	g.Expect(bootstrapCNILatencyProbe(conf)).To(BeFalse())
+	conf.Annotations = map[string]string{names.CNILatencyProbeAnnotation: "true"}
+	g.Expect(bootstrapCNILatencyProbe(conf)).To(BeTrue())
+	conf.Annotations = map[string]string{names.CNILatencyProbeAnnotation: "yes"}
+	g.Expect(bootstrapCNILatencyProbe(conf)).To(BeFalse())
+}
+
+func TestRenderCNILatencyProbe(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := NetworkMetricsDaemonConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+	bootstrapResult := &bootstrap.BootstrapResult{}
+
+	objs, err := renderCNILatencyProbe(config, bootstrapResult, manifestDir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).To(BeEmpty())
+
+	bootstrapResult.CNILatencyProbe = true
+	objs, err = renderCNILatencyProbe(config, bootstrapResult, manifestDir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("DaemonSet", "openshift-multus", "cni-latency-probe")))
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("ServiceAccount", "openshift-multus", "cni-latency-probe")))
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("ClusterRole", "", "cni-latency-probe")))
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("ClusterRoleBinding", "", "cni-latency-probe")))
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("Service", "openshift-multus", "cni-latency-probe")))
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("ServiceMonitor", "openshift-multus", "monitor-cni-latency-probe")))
+
+	ds := &appsv1.DaemonSet{}
+	g.Expect(convert(findInObjs("apps", "DaemonSet", "cni-latency-probe", "openshift-multus", objs), ds)).To(Succeed())
+	probe := ds.Spec.Template.Spec.Containers[0]
+	g.Expect(probe.Command[2]).To(ContainSubstring("cni_latency_probe_duration_seconds"))
+	g.Expect(probe.Command[2]).To(ContainSubstring("\nEOC\n"))
+	g.Expect(ds.Spec.Template.Spec.Volumes[0].HostPath.Path).To(Equal(CNIBinDir))
+
+	// the probe is not rendered without multus
+	disabled := true
+	config.DisableMultiNetwork = &disabled
+	objs, err = renderCNILatencyProbe(config, bootstrapResult, manifestDir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).To(BeEmpty())
+}
@@ -0,0 +1,144 @@
+package network
+
+import (
+	"sort"
+
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	k8sutil "github.com/openshift/cluster-network-operator/pkg/util/k8s"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// configHashWorkloadKinds are the kinds of the workloads which pods are rolled out when the
+// ConfigMaps and Secrets they mount change. The pod templates of Jobs can't be updated.
+var configHashWorkloadKinds = map[string]bool{
+	"DaemonSet":   true,
+	"Deployment":  true,
+	"StatefulSet": true,
+}
+
+// setConfigHashAnnotations annotates the pod templates of the rendered workloads with a hash of
+// the rendered ConfigMaps and Secrets their pods mount or read environment variables from, so that
+// a change of the configuration alone rolls out the pods. The ConfigMaps and Secrets not rendered
+// by the operator, and the ones the pods reload in place, annotated with names.HotReloadAnnotation,
+// are not part of the hash. The workloads carried over from the cluster, like a daemonset which
+// rollout is deferred, are left as they are.
+func setConfigHashAnnotations(objs []*uns.Unstructured) error {
+	configs := map[string]interface{}{}
+	for _, obj := range objs {
+		if obj.GetAPIVersion() != "v1" || obj.GetAnnotations()[names.HotReloadAnnotation] == "true" {
+			continue
+		}
+		switch obj.GetKind() {
+		case "ConfigMap":
+			configs[configHashKey("ConfigMap", obj.GetNamespace(), obj.GetName())] =
+				[]interface{}{obj.Object["data"], obj.Object["binaryData"]}
+		case "Secret":
+			configs[configHashKey("Secret", obj.GetNamespace(), obj.GetName())] =
+				[]interface{}{obj.Object["data"], obj.Object["stringData"]}
+		}
+	}
+
+	for _, obj := range objs {
+		if !configHashWorkloadKinds[obj.GetKind()] || obj.GetResourceVersion() != "" {
+			continue
+		}
+		template, found, err := uns.NestedMap(obj.Object, "spec", "template")
+		if err != nil || !found {
+			continue
+		}
+		pod := &corev1.PodTemplateSpec{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(template, pod); err != nil {
+			return errors.Wrapf(err, "failed to parse the pod template of %s %s/%s", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+		}
+
+		keys := []string{}
+		for _, key := range podConfigReferences(obj.GetNamespace(), &pod.Spec) {
+			if _, ok := configs[key]; ok {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		sort.Strings(keys)
+		mounted := make([]interface{}, 0, 2*len(keys))
+		for _, key := range keys {
+			mounted = append(mounted, key, configs[key])
+		}
+		hash, err := k8sutil.CalculateHash(mounted)
+		if err != nil {
+			return errors.Wrapf(err, "failed to calculate the configuration hash of %s %s/%s", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+		}
+
+		anno, _, _ := uns.NestedStringMap(obj.Object, "spec", "template", "metadata", "annotations")
+		if anno == nil {
+			anno = map[string]string{}
+		}
+		anno[names.ConfigHashAnnotation] = hash
+		if err := uns.SetNestedStringMap(obj.Object, anno, "spec", "template", "metadata", "annotations"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func configHashKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+// podConfigReferences returns the keys of the ConfigMaps and Secrets a pod mounts as volumes or
+// reads environment variables from.
+func podConfigReferences(namespace string, spec *corev1.PodSpec) []string {
+	refs := map[string]bool{}
+	configMap := func(name string) { refs[configHashKey("ConfigMap", namespace, name)] = true }
+	secret := func(name string) { refs[configHashKey("Secret", namespace, name)] = true }
+
+	for _, v := range spec.Volumes {
+		switch {
+		case v.ConfigMap != nil:
+			configMap(v.ConfigMap.Name)
+		case v.Secret != nil:
+			secret(v.Secret.SecretName)
+		case v.Projected != nil:
+			for _, s := range v.Projected.Sources {
+				if s.ConfigMap != nil {
+					configMap(s.ConfigMap.Name)
+				}
+				if s.Secret != nil {
+					secret(s.Secret.Name)
+				}
+			}
+		}
+	}
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, c := range containers {
+		for _, e := range c.EnvFrom {
+			if e.ConfigMapRef != nil {
+				configMap(e.ConfigMapRef.Name)
+			}
+			if e.SecretRef != nil {
+				secret(e.SecretRef.Name)
+			}
+		}
+		for _, e := range c.Env {
+			if e.ValueFrom == nil {
+				continue
+			}
+			if e.ValueFrom.ConfigMapKeyRef != nil {
+				configMap(e.ValueFrom.ConfigMapKeyRef.Name)
+			}
+			if e.ValueFrom.SecretKeyRef != nil {
+				secret(e.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(refs))
+	for key := range refs {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,128 @@
+package network
+
+import (
+	"testing"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/openshift/cluster-network-operator/pkg/testsupport"
+	k8sutil "github.com/openshift/cluster-network-operator/pkg/util/k8s"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSetConfigHashAnnotations(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	toUns := func(obj interface{}) *uns.Unstructured {
+		u, err := k8sutil.ToUnstructured(obj)
+		g.Expect(err).NotTo(HaveOccurred())
+		return u
+	}
+	configMap := func(name, value string) *uns.Unstructured {
+		return toUns(&corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name},
+			Data:       map[string]string{"config": value},
+		})
+	}
+	daemonSet := func(name string, volumes []corev1.Volume, env []corev1.EnvVar) *uns.Unstructured {
+		return toUns(&appsv1.DaemonSet{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "DaemonSet"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name},
+			Spec: appsv1.DaemonSetSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Volumes:    volumes,
+						Containers: []corev1.Container{{Name: "c", Env: env}},
+					},
+				},
+			},
+		})
+	}
+	configMapVolume := func(name string) corev1.Volume {
+		return corev1.Volume{Name: name, VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: name}},
+		}}
+	}
+	configHash := func(objs []*uns.Unstructured, name string) string {
+		g.Expect(setConfigHashAnnotations(objs)).To(Succeed())
+		for _, obj := range objs {
+			if obj.GetKind() == "DaemonSet" && obj.GetName() == name {
+				anno, _, _ := uns.NestedStringMap(obj.Object, "spec", "template", "metadata", "annotations")
+				return anno[names.ConfigHashAnnotation]
+			}
+		}
+		return ""
+	}
+	render := func(config, env string) []*uns.Unstructured {
+		return []*uns.Unstructured{
+			configMap("config", config),
+			configMap("env", env),
+			daemonSet("mounts", []corev1.Volume{configMapVolume("config"), configMapVolume("external")}, nil),
+			daemonSet("reads-env", nil, []corev1.EnvVar{{Name: "E", ValueFrom: &corev1.EnvVarSource{
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "env"}, Key: "config"},
+			}}}),
+			daemonSet("external", []corev1.Volume{configMapVolume("external")}, nil),
+		}
+	}
+
+	mountsHash := configHash(render("a", "b"), "mounts")
+	envHash := configHash(render("a", "b"), "reads-env")
+	g.Expect(mountsHash).NotTo(BeEmpty())
+	g.Expect(envHash).NotTo(BeEmpty())
+	g.Expect(mountsHash).NotTo(Equal(envHash))
+
+	// the hash is stable, and changes with the configuration the pods use only
+	g.Expect(configHash(render("a", "b"), "mounts")).To(Equal(mountsHash))
+	g.Expect(configHash(render("a", "c"), "mounts")).To(Equal(mountsHash))
+	g.Expect(configHash(render("c", "b"), "mounts")).NotTo(Equal(mountsHash))
+	g.Expect(configHash(render("a", "c"), "reads-env")).NotTo(Equal(envHash))
+
+	// the ConfigMaps not rendered by the operator are not hashed
+	g.Expect(configHash(render("a", "b"), "external")).To(BeEmpty())
+
+	// a daemonset carried over from the cluster is left as it is
+	objs := render("c", "b")
+	objs[2].SetResourceVersion("1")
+	g.Expect(configHash(objs, "mounts")).To(BeEmpty())
+}
+
+func TestRenderConfigHashHotReload(t *testing.T) {
+	g := NewGomegaWithT(t)
+	defer setGoldenEnv()()
+
+	nodeTemplate := func(bootstrapResult *bootstrap.BootstrapResult) interface{} {
+		spec := testsupport.NewNetworkSpec(operv1.NetworkTypeOVNKubernetes).Build()
+		FillDefaultsWithMTU(spec, nil, 1500)
+		objs, err := Render(spec, bootstrapResult, manifestDir)
+		g.Expect(err).NotTo(HaveOccurred())
+		node := findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs)
+		g.Expect(node).NotTo(BeNil())
+		template, _, err := uns.NestedMap(node.Object, "spec", "template")
+		g.Expect(err).NotTo(HaveOccurred())
+		return template
+	}
+	sampling := func(v uint) *bootstrap.FlowsConfig {
+		return &bootstrap.FlowsConfig{Target: "1.2.3.4:2056", Sampling: &v}
+	}
+
+	bootstrapResult := testsupport.NewBootstrapResult().Build()
+	bootstrapResult.OVN.FlowsConfig = sampling(100)
+	template := nodeTemplate(bootstrapResult)
+
+	// the IPFIX parameters are reloaded in place by ovs-flows-reloader
+	bootstrapResult = testsupport.NewBootstrapResult().Build()
+	bootstrapResult.OVN.FlowsConfig = sampling(200)
+	g.Expect(nodeTemplate(bootstrapResult)).To(Equal(template))
+
+	// and the database endpoints by ovn-controller
+	bootstrapResult = testsupport.NewBootstrapResult().WithOVNMasterIPs("10.0.0.1", "10.0.0.2", "10.0.0.4").Build()
+	bootstrapResult.OVN.FlowsConfig = sampling(100)
+	g.Expect(nodeTemplate(bootstrapResult)).To(Equal(template))
+}
@@ -0,0 +1,133 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/openshift/cluster-network-operator/pkg/render"
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConntrackTuningConfigMapName is the ConfigMap which presence deploys the conntrack-tuning
+	// daemonset, and which data tunes the conntrack table of the nodes
+	ConntrackTuningConfigMapName      = "conntrack-tuning"
+	ConntrackTuningConfigMapNamespace = names.APPLIED_NAMESPACE
+
+	// CONNTRACK_MAX_PER_GIB and CONNTRACK_MIN size the conntrack table of the nodes when the
+	// ConfigMap does not: 16384 entries per GiB of memory, like the kernel, but at least as many
+	// as kube-proxy sets by default.
+	CONNTRACK_MAX_PER_GIB = 16384
+	CONNTRACK_MIN         = 131072
+)
+
+// the keys of the conntrack-tuning ConfigMap
+const (
+	conntrackMaxPerGiB             = "maxPerGiB"
+	conntrackMin                   = "min"
+	conntrackMax                   = "max"
+	conntrackTCPTimeoutEstablished = "tcpTimeoutEstablished"
+	conntrackTCPTimeoutCloseWait   = "tcpTimeoutCloseWait"
+	conntrackTCPTimeoutTimeWait    = "tcpTimeoutTimeWait"
+	conntrackUDPTimeout            = "udpTimeout"
+	conntrackUDPTimeoutStream      = "udpTimeoutStream"
+)
+
+// bootstrapConntrackTuning reads the conntrack tuning of the nodes from the conntrack-tuning
+// ConfigMap. It returns nil, and the nodes are left as they are, when the ConfigMap does not exist.
+func bootstrapConntrackTuning(kubeClient client.Reader) (*bootstrap.ConntrackTuning, error) {
+	if kubeClient == nil {
+		return nil, nil
+	}
+	cm := &corev1.ConfigMap{}
+	nsn := types.NamespacedName{Namespace: ConntrackTuningConfigMapNamespace, Name: ConntrackTuningConfigMapName}
+	if err := kubeClient.Get(context.TODO(), nsn, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Failed to retrieve the %s configmap: %w", ConntrackTuningConfigMapName, err)
+	}
+	return conntrackTuningFromData(cm.Data), nil
+}
+
+// conntrackTuningFromData parses the data of the conntrack-tuning ConfigMap. The entries are
+// positive integers, and the timeouts durations of at least a second. Invalid entries are ignored.
+func conntrackTuningFromData(data map[string]string) *bootstrap.ConntrackTuning {
+	positiveInt := func(key string, defaultValue int) int {
+		value, ok := data[key]
+		if !ok {
+			return defaultValue
+		}
+		i, err := strconv.Atoi(value)
+		if err != nil || i <= 0 {
+			klog.Warningf("%s: %s must be a positive integer, is: %q. Ignoring it", ConntrackTuningConfigMapName, key, value)
+			return defaultValue
+		}
+		return i
+	}
+	seconds := func(key string) int {
+		value, ok := data[key]
+		if !ok {
+			return 0
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d < time.Second {
+			klog.Warningf("%s: %s must be a duration of at least 1s, is: %q. Ignoring it", ConntrackTuningConfigMapName, key, value)
+			return 0
+		}
+		return int(d.Seconds())
+	}
+
+	return &bootstrap.ConntrackTuning{
+		MaxPerGiB:             positiveInt(conntrackMaxPerGiB, CONNTRACK_MAX_PER_GIB),
+		Min:                   positiveInt(conntrackMin, CONNTRACK_MIN),
+		Max:                   positiveInt(conntrackMax, 0),
+		TCPTimeoutEstablished: seconds(conntrackTCPTimeoutEstablished),
+		TCPTimeoutCloseWait:   seconds(conntrackTCPTimeoutCloseWait),
+		TCPTimeoutTimeWait:    seconds(conntrackTCPTimeoutTimeWait),
+		UDPTimeout:            seconds(conntrackUDPTimeout),
+		UDPTimeoutStream:      seconds(conntrackUDPTimeoutStream),
+	}
+}
+
+// renderConntrackTuning generates the manifests of the conntrack-tuning daemonset, which sizes the
+// conntrack table of each node from its memory, along with its hash table, and sets the conntrack
+// timeouts, so that the table does not overflow on the nodes handling many connections.
+func renderConntrackTuning(bootstrapResult *bootstrap.BootstrapResult, manifestDir string) ([]*uns.Unstructured, error) {
+	tuning := bootstrapResult.ConntrackTuning
+	if tuning == nil {
+		return nil, nil
+	}
+
+	data := makeRenderData(bootstrapResult.FeatureGates)
+	data.Data["ReleaseVersion"] = os.Getenv("RELEASE_VERSION")
+	// the tuning needs bash, which the OVN image ships
+	data.Data["ConntrackTuningImage"] = os.Getenv("OVN_IMAGE")
+	data.Data["ConntrackMaxPerGiB"] = tuning.MaxPerGiB
+	data.Data["ConntrackMin"] = tuning.Min
+	data.Data["ConntrackMax"] = tuning.Max
+	data.Data["ConntrackTCPTimeoutEstablished"] = tuning.TCPTimeoutEstablished
+	data.Data["ConntrackTCPTimeoutCloseWait"] = tuning.TCPTimeoutCloseWait
+	data.Data["ConntrackTCPTimeoutTimeWait"] = tuning.TCPTimeoutTimeWait
+	data.Data["ConntrackUDPTimeout"] = tuning.UDPTimeout
+	data.Data["ConntrackUDPTimeoutStream"] = tuning.UDPTimeoutStream
+
+	manifests, err := render.RenderDir(filepath.Join(manifestDir, "network/conntrack-tuning"), &data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render conntrack-tuning manifests")
+	}
+	return manifests, nil
+}
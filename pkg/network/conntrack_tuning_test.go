@@ -0,0 +1,69 @@
+package network
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBootstrapConntrackTuning(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// nothing is tuned without the ConfigMap
+	tuning, err := bootstrapConntrackTuning(fake.NewClientBuilder().Build())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(tuning).To(BeNil())
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ConntrackTuningConfigMapNamespace, Name: ConntrackTuningConfigMapName},
+	}
+	tuning, err = bootstrapConntrackTuning(fake.NewClientBuilder().WithObjects(cm).Build())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(tuning).To(Equal(&bootstrap.ConntrackTuning{MaxPerGiB: CONNTRACK_MAX_PER_GIB, Min: CONNTRACK_MIN}))
+
+	g.Expect(conntrackTuningFromData(map[string]string{
+		"maxPerGiB":             "65536",
+		"min":                   "-1",
+		"max":                   "many",
+		"tcpTimeoutEstablished": "2h",
+		"tcpTimeoutCloseWait":   "60s",
+		"tcpTimeoutTimeWait":    "500ms",
+		"udpTimeout":            "30",
+		"udpTimeoutStream":      "3m",
+	})).To(Equal(&bootstrap.ConntrackTuning{
+		MaxPerGiB:             65536,
+		Min:                   CONNTRACK_MIN,
+		TCPTimeoutEstablished: 7200,
+		TCPTimeoutCloseWait:   60,
+		UDPTimeoutStream:      180,
+	}))
+}
+
+func TestRenderConntrackTuning(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	bootstrapResult := &bootstrap.BootstrapResult{}
+	objs, err := renderConntrackTuning(bootstrapResult, manifestDir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).To(BeEmpty())
+
+	bootstrapResult.ConntrackTuning = &bootstrap.ConntrackTuning{MaxPerGiB: 65536, Min: CONNTRACK_MIN, TCPTimeoutEstablished: 7200}
+	objs, err = renderConntrackTuning(bootstrapResult, manifestDir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).To(HaveLen(1))
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("DaemonSet", "openshift-network-operator", "conntrack-tuning")))
+
+	ds := &appsv1.DaemonSet{}
+	g.Expect(convert(objs[0], ds)).To(Succeed())
+	g.Expect(ds.Spec.Template.Spec.HostNetwork).To(BeTrue())
+	script := ds.Spec.Template.Spec.Containers[0].Command[2]
+	g.Expect(script).To(ContainSubstring("local max=0\n"))
+	g.Expect(script).To(ContainSubstring("max=$(( mem_kib * 65536 / 1048576 ))"))
+	g.Expect(script).To(ContainSubstring("set_sysctl nf_conntrack_tcp_timeout_established 7200 &&"))
+	g.Expect(script).To(ContainSubstring("set_sysctl nf_conntrack_tcp_timeout_close_wait 0 &&"))
+}
@@ -0,0 +1,148 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/featuregates"
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// dualStackFeatureGate is the kube-apiserver feature gate of dual-stack Services
+	dualStackFeatureGate = "IPv6DualStack"
+	// dualStackMinKubeVersion is the first kube-apiserver version serving dual-stack Services by default
+	dualStackMinKubeVersion = "1.21.0"
+	// hybridOverlayNodeSubnetAnnotation is set by ovnkube-master on the Windows nodes with their hybrid overlay subnet
+	hybridOverlayNodeSubnetAnnotation = "k8s.ovn.org/hybrid-overlay-node-subnet"
+)
+
+// CheckDualStackConversion checks, when the service network is converted from single-stack to dual-stack,
+// that the cluster can run it: every master node has addresses of both IP families, the kube-apiserver
+// serves dual-stack Services, and the Windows nodes of the hybrid overlay of ovn-kubernetes are set up on
+// IPv4 hybrid cluster networks. It returns an error listing the unmet preconditions, so that the conversion
+// is not started, rather than left half-way.
+func CheckDualStackConversion(ctx context.Context, kubeClient client.Reader, conf *operv1.Network, prev *operv1.NetworkSpec) error {
+	if prev == nil || len(prev.ServiceNetwork) != 1 || len(conf.Spec.ServiceNetwork) != 2 {
+		return nil
+	}
+
+	failures := []string{}
+	masterSelector := map[string]string{"node-role.kubernetes.io/master": ""}
+	if conf.Spec.DefaultNetwork.Type == operv1.NetworkTypeOVNKubernetes {
+		masterSelector, _ = bootstrapOVNMasterNodeSelector(conf)
+	}
+	masters := &corev1.NodeList{}
+	if err := kubeClient.List(ctx, masters, client.MatchingLabels(masterSelector)); err != nil {
+		return errors.Wrap(err, "failed to list the master nodes")
+	}
+	if nodes := nodesMissingIPFamily(masters.Items); len(nodes) > 0 {
+		failures = append(failures, fmt.Sprintf("master nodes %s do not have both IPv4 and IPv6 addresses", strings.Join(nodes, ", ")))
+	}
+
+	if oc := conf.Spec.DefaultNetwork.OVNKubernetesConfig; conf.Spec.DefaultNetwork.Type == operv1.NetworkTypeOVNKubernetes &&
+		oc != nil && oc.HybridOverlayConfig != nil {
+		hybridFailures, err := checkHybridOverlayDualStackConversion(ctx, kubeClient, oc.HybridOverlayConfig)
+		if err != nil {
+			return err
+		}
+		failures = append(failures, hybridFailures...)
+	}
+
+	featureGates, err := featuregates.Get(ctx, kubeClient)
+	if err != nil {
+		return err
+	}
+	if featureGates.Disabled(dualStackFeatureGate) {
+		failures = append(failures, fmt.Sprintf("the %s feature gate is disabled", dualStackFeatureGate))
+	}
+
+	kubeAPIServer := &configv1.ClusterOperator{}
+	if err := kubeClient.Get(ctx, types.NamespacedName{Name: "kube-apiserver"}, kubeAPIServer); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrap(err, "failed to retrieve the kube-apiserver ClusterOperator")
+		}
+		klog.Infof("No kube-apiserver ClusterOperator, not checking its version before the dual-stack conversion")
+	} else {
+		for _, version := range kubeAPIServer.Status.Versions {
+			if version.Name != "kube-apiserver" {
+				continue
+			}
+			if change := compareVersions(version.Version, dualStackMinKubeVersion); change == versionUpgrade || change == versionUnknown {
+				failures = append(failures, fmt.Sprintf("kube-apiserver %q does not serve dual-stack Services, %s or later is required",
+					version.Version, dualStackMinKubeVersion))
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		return errors.Errorf("cannot convert the service network to dual-stack: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// checkHybridOverlayDualStackConversion returns the unmet preconditions of the hybrid overlay: the hybrid
+// overlay of the Windows nodes is IPv4-only, and the Windows nodes still joining the cluster could be set up
+// by ovnkube-master while it is rolled out
+func checkHybridOverlayDualStackConversion(ctx context.Context, kubeClient client.Reader, hybridOverlay *operv1.HybridOverlayConfig) ([]string, error) {
+	failures := []string{}
+	for _, hcn := range hybridOverlay.HybridClusterNetwork {
+		if ip, _, err := net.ParseCIDR(hcn.CIDR); err == nil && ip.To4() == nil {
+			failures = append(failures, fmt.Sprintf("hybridClusterNetwork %s is not IPv4", hcn.CIDR))
+		}
+	}
+
+	windowsNodes := &corev1.NodeList{}
+	if err := kubeClient.List(ctx, windowsNodes, client.MatchingLabels{"kubernetes.io/os": "windows"}); err != nil {
+		return nil, errors.Wrap(err, "failed to list the Windows nodes")
+	}
+	notSetUp := []string{}
+	for i := range windowsNodes.Items {
+		node := &windowsNodes.Items[i]
+		if _, ok := node.GetAnnotations()[hybridOverlayNodeSubnetAnnotation]; !ok || !isNodeReady(node) {
+			notSetUp = append(notSetUp, node.Name)
+		}
+	}
+	if len(notSetUp) > 0 {
+		sort.Strings(notSetUp)
+		failures = append(failures, fmt.Sprintf("Windows nodes %s are not Ready on the hybrid overlay", strings.Join(notSetUp, ", ")))
+	}
+	return failures, nil
+}
+
+// nodesMissingIPFamily returns the names of the nodes which internal addresses are not of both IP families
+func nodesMissingIPFamily(nodes []corev1.Node) []string {
+	missing := []string{}
+	for _, node := range nodes {
+		hasIPv4, hasIPv6 := false, false
+		for _, address := range node.Status.Addresses {
+			if address.Type != corev1.NodeInternalIP {
+				continue
+			}
+			ip := net.ParseIP(address.Address)
+			switch {
+			case ip == nil:
+			case ip.To4() != nil:
+				hasIPv4 = true
+			default:
+				hasIPv6 = true
+			}
+		}
+		if !hasIPv4 || !hasIPv6 {
+			missing = append(missing, node.Name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
@@ -0,0 +1,126 @@
+package network
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	operv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCheckDualStackConversion(t *testing.T) {
+	g := NewGomegaWithT(t)
+	g.Expect(configv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	master := func(name string, ips ...string) *corev1.Node {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{"node-role.kubernetes.io/master": ""},
+		}}
+		for _, ip := range ips {
+			node.Status.Addresses = append(node.Status.Addresses, corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: ip})
+		}
+		return node
+	}
+	kubeAPIServer := func(version string) *configv1.ClusterOperator {
+		return &configv1.ClusterOperator{
+			ObjectMeta: metav1.ObjectMeta{Name: "kube-apiserver"},
+			Status: configv1.ClusterOperatorStatus{Versions: []configv1.OperandVersion{
+				{Name: "operator", Version: "4.9.0"},
+				{Name: "kube-apiserver", Version: version},
+			}},
+		}
+	}
+	conf := &operv1.Network{Spec: operv1.NetworkSpec{
+		ServiceNetwork: []string{"172.30.0.0/16", "fd02::/112"},
+		DefaultNetwork: operv1.DefaultNetworkDefinition{Type: operv1.NetworkTypeOVNKubernetes},
+	}}
+	prev := &operv1.NetworkSpec{ServiceNetwork: []string{"172.30.0.0/16"}}
+	check := func(objs ...client.Object) error {
+		return CheckDualStackConversion(context.TODO(), fake.NewClientBuilder().WithObjects(objs...).Build(), conf, prev)
+	}
+
+	g.Expect(check(master("master-0", "10.0.0.1", "fd00::1"), kubeAPIServer("1.22.1"))).To(Succeed())
+	// the version of kube-apiserver is not checked without its ClusterOperator
+	g.Expect(check(master("master-0", "10.0.0.1", "fd00::1"))).To(Succeed())
+
+	err := check(master("master-0", "10.0.0.1", "fd00::1"), master("master-1", "10.0.0.2"), master("master-2", "fd00::3"), kubeAPIServer("1.20.4"))
+	g.Expect(err).To(MatchError("cannot convert the service network to dual-stack: " +
+		"master nodes master-1, master-2 do not have both IPv4 and IPv6 addresses; " +
+		"kube-apiserver \"1.20.4\" does not serve dual-stack Services, 1.21.0 or later is required"))
+
+	featureGate := &configv1.FeatureGate{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: configv1.FeatureGateSpec{FeatureGateSelection: configv1.FeatureGateSelection{
+			FeatureSet:      configv1.CustomNoUpgrade,
+			CustomNoUpgrade: &configv1.CustomFeatureGates{Disabled: []string{"IPv6DualStack"}},
+		}},
+	}
+	err = check(master("master-0", "10.0.0.1", "fd00::1"), featureGate)
+	g.Expect(err).To(MatchError("cannot convert the service network to dual-stack: the IPv6DualStack feature gate is disabled"))
+
+	// nothing is checked when the service network is not converted to dual-stack
+	prev.ServiceNetwork = conf.Spec.ServiceNetwork
+	g.Expect(check(master("master-1", "10.0.0.2"))).To(Succeed())
+	g.Expect(CheckDualStackConversion(context.TODO(), fake.NewClientBuilder().Build(), conf, nil)).To(Succeed())
+}
+
+func TestCheckDualStackConversionHybridOverlay(t *testing.T) {
+	g := NewGomegaWithT(t)
+	g.Expect(configv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	master := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "master-0", Labels: map[string]string{"node-role.kubernetes.io/master": ""}},
+		Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{
+			{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+			{Type: corev1.NodeInternalIP, Address: "fd00::1"},
+		}},
+	}
+	windows := func(name string, subnet string, ready bool) *corev1.Node {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"kubernetes.io/os": "windows"}}}
+		if subnet != "" {
+			node.Annotations = map[string]string{hybridOverlayNodeSubnetAnnotation: subnet}
+		}
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		node.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}}
+		return node
+	}
+	conf := &operv1.Network{Spec: operv1.NetworkSpec{
+		ServiceNetwork: []string{"172.30.0.0/16", "fd02::/112"},
+		DefaultNetwork: operv1.DefaultNetworkDefinition{
+			Type: operv1.NetworkTypeOVNKubernetes,
+			OVNKubernetesConfig: &operv1.OVNKubernetesConfig{
+				HybridOverlayConfig: &operv1.HybridOverlayConfig{HybridClusterNetwork: []operv1.ClusterNetworkEntry{
+					{CIDR: "10.132.0.0/14", HostPrefix: 23},
+				}},
+			},
+		},
+	}}
+	prev := &operv1.NetworkSpec{ServiceNetwork: []string{"172.30.0.0/16"}}
+	check := func(objs ...client.Object) error {
+		objs = append(objs, master)
+		return CheckDualStackConversion(context.TODO(), fake.NewClientBuilder().WithObjects(objs...).Build(), conf, prev)
+	}
+
+	g.Expect(check()).To(Succeed())
+	g.Expect(check(windows("windows-0", "10.132.0.0/23", true))).To(Succeed())
+
+	// the Windows nodes joining the cluster
+	err := check(windows("windows-0", "10.132.0.0/23", true), windows("windows-2", "", true), windows("windows-1", "10.132.2.0/23", false))
+	g.Expect(err).To(MatchError("cannot convert the service network to dual-stack: " +
+		"Windows nodes windows-1, windows-2 are not Ready on the hybrid overlay"))
+
+	hybridOverlay := conf.Spec.DefaultNetwork.OVNKubernetesConfig.HybridOverlayConfig
+	hybridOverlay.HybridClusterNetwork = append(hybridOverlay.HybridClusterNetwork, operv1.ClusterNetworkEntry{CIDR: "fd03::/48", HostPrefix: 64})
+	err = check(windows("windows-0", "10.132.0.0/23", true))
+	g.Expect(err).To(MatchError("cannot convert the service network to dual-stack: hybridClusterNetwork fd03::/48 is not IPv4"))
+}
@@ -0,0 +1,133 @@
+package network
+
+import (
+	"strings"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+
+	corev1 "k8s.io/api/core/v1"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+)
+
+// the kinds of objects that cannot be excluded, as the rest of the manifests depend on them
+var protectedKinds = sets.NewString(
+	"Namespace",
+	"CustomResourceDefinition.apiextensions.k8s.io",
+	"ServiceAccount",
+	"ClusterRole.rbac.authorization.k8s.io",
+	"ClusterRoleBinding.rbac.authorization.k8s.io",
+	"Role.rbac.authorization.k8s.io",
+	"RoleBinding.rbac.authorization.k8s.io",
+)
+
+// the workloads of the default networks and of Multus, which cannot be excluded either
+var protectedObjects = sets.NewString(
+	"DaemonSet.apps/openshift-multus/multus",
+	"DaemonSet.apps/openshift-ovn-kubernetes/ovnkube-master",
+	"DaemonSet.apps/openshift-ovn-kubernetes/ovnkube-node",
+	"DaemonSet.apps/openshift-sdn/sdn",
+	"DaemonSet.apps/openshift-sdn/sdn-controller",
+	"DaemonSet.apps/openshift-kuryr/kuryr-cni",
+	"Deployment.apps/openshift-kuryr/kuryr-controller",
+)
+
+// excludedObjectString returns the object as "<kind>[.<group>]/[<namespace>/]<name>"
+func excludedObjectString(o bootstrap.ExcludedObject) string {
+	out := o.Kind
+	if o.Group != "" {
+		out += "." + o.Group
+	}
+	if o.Namespace != "" {
+		out += "/" + o.Namespace
+	}
+	return out + "/" + o.Name
+}
+
+// bootstrapExcludedObjects returns the objects listed in the excluded objects annotation of the
+// operator configuration. The invalid entries, and the objects the pod network requires, are ignored.
+func bootstrapExcludedObjects(conf *operv1.Network, res *bootstrap.BootstrapResult) []bootstrap.ExcludedObject {
+	v, ok := conf.GetAnnotations()[names.ExcludedObjectsAnnotation]
+	if !ok {
+		return nil
+	}
+
+	out := []bootstrap.ExcludedObject{}
+	for _, s := range strings.Split(v, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		o, ok := parseExcludedObject(s)
+		if !ok {
+			klog.Warningf("%s must be a comma-separated list of \"<kind>[.<group>]/[<namespace>/]<name>\", %q is not. Ignoring it",
+				names.ExcludedObjectsAnnotation, s)
+			continue
+		}
+		groupKind := o.Kind
+		if o.Group != "" {
+			groupKind += "." + o.Group
+		}
+		if protectedKinds.Has(groupKind) || protectedObjects.Has(excludedObjectString(o)) {
+			res.RecordEvent(corev1.EventTypeWarning, "ObjectExclusionIgnored",
+				"%s is required by the pod network and cannot be excluded", excludedObjectString(o))
+			continue
+		}
+		out = append(out, o)
+	}
+	return out
+}
+
+// parseExcludedObject parses "<kind>[.<group>]/[<namespace>/]<name>"
+func parseExcludedObject(s string) (bootstrap.ExcludedObject, bool) {
+	o := bootstrap.ExcludedObject{}
+	parts := strings.Split(s, "/")
+	switch len(parts) {
+	case 2:
+		o.Name = parts[1]
+	case 3:
+		o.Namespace, o.Name = parts[1], parts[2]
+		if len(validation.IsDNS1123Label(o.Namespace)) > 0 {
+			return o, false
+		}
+	default:
+		return o, false
+	}
+	kindGroup := strings.SplitN(parts[0], ".", 2)
+	o.Kind = kindGroup[0]
+	if len(kindGroup) == 2 {
+		o.Group = kindGroup[1]
+	}
+	if o.Kind == "" || len(validation.IsDNS1123Subdomain(o.Name)) > 0 {
+		return o, false
+	}
+	return o, true
+}
+
+// excludeObjects removes the excluded objects from the rendered manifests
+func excludeObjects(objs []*uns.Unstructured, bootstrapResult *bootstrap.BootstrapResult) []*uns.Unstructured {
+	if len(bootstrapResult.ExcludedObjects) == 0 {
+		return objs
+	}
+	out := make([]*uns.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		excluded := false
+		gvk := obj.GroupVersionKind()
+		for _, o := range bootstrapResult.ExcludedObjects {
+			if o.Group == gvk.Group && o.Kind == gvk.Kind && o.Namespace == obj.GetNamespace() && o.Name == obj.GetName() {
+				excluded = true
+				break
+			}
+		}
+		if excluded {
+			renderLog.V(1).Info("Excluding object", "kind", gvk.Kind, "namespace", obj.GetNamespace(), "name", obj.GetName())
+			continue
+		}
+		out = append(out, obj)
+	}
+	return out
+}
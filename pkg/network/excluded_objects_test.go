@@ -0,0 +1,49 @@
+package network
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+)
+
+func TestBootstrapExcludedObjects(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := ThirdPartyCNIConfig.DeepCopy()
+	res := &bootstrap.BootstrapResult{}
+	g.Expect(bootstrapExcludedObjects(crd, res)).To(BeNil())
+
+	crd.Annotations = map[string]string{names.ExcludedObjectsAnnotation: "DaemonSet.apps/openshift-ovn-kubernetes/ovnkube-upgrades-prepuller, " +
+		"Service/openshift-ovn-kubernetes/ovn-kubernetes-master,ValidatingWebhookConfiguration.admissionregistration.k8s.io/multus.openshift.io," +
+		// invalid
+		"DaemonSet.apps,Service/a/b/c,/openshift-multus/multus,Service/openshift-multus/Multus," +
+		// protected
+		"DaemonSet.apps/openshift-ovn-kubernetes/ovnkube-node,Namespace/openshift-multus,ClusterRole.rbac.authorization.k8s.io/multus"}
+	g.Expect(bootstrapExcludedObjects(crd, res)).To(Equal([]bootstrap.ExcludedObject{
+		{Group: "apps", Kind: "DaemonSet", Namespace: "openshift-ovn-kubernetes", Name: "ovnkube-upgrades-prepuller"},
+		{Kind: "Service", Namespace: "openshift-ovn-kubernetes", Name: "ovn-kubernetes-master"},
+		{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration", Name: "multus.openshift.io"},
+	}))
+	g.Expect(res.Events).To(HaveLen(3))
+	g.Expect(res.Events[0].Reason).To(Equal("ObjectExclusionIgnored"))
+	g.Expect(res.Events[0].Message).To(ContainSubstring("DaemonSet.apps/openshift-ovn-kubernetes/ovnkube-node"))
+}
+
+func TestRenderExcludedObjects(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := ThirdPartyCNIConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+	crd.Annotations = map[string]string{names.ExcludedObjectsAnnotation: "DaemonSet.apps/openshift-multus/network-metrics-daemon"}
+
+	bootstrapResult, err := Bootstrap(crd, fakeInfraClient(g))
+	g.Expect(err).NotTo(HaveOccurred())
+	objs, err := Render(config, bootstrapResult, manifestDir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).NotTo(ContainElement(HaveKubernetesID("DaemonSet", "openshift-multus", "network-metrics-daemon")))
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("DaemonSet", "openshift-multus", "multus")))
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("ServiceAccount", "openshift-multus", "metrics-daemon-sa")))
+}
@@ -0,0 +1,39 @@
+package network
+
+import (
+	"context"
+
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/featuregates"
+	"github.com/openshift/cluster-network-operator/pkg/render"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// bootstrapFeatureGates reads the feature gates of the cluster, and falls back from the gated
+// features configured while their gate is disabled.
+func bootstrapFeatureGates(kubeClient client.Reader, res *bootstrap.BootstrapResult) error {
+	featureGates := featuregates.New(nil)
+	if kubeClient != nil {
+		var err error
+		if featureGates, err = featuregates.Get(context.TODO(), kubeClient); err != nil {
+			return err
+		}
+	}
+	res.FeatureGates = featureGates
+
+	if c := res.OVN.OVNKubernetesConfig; c != nil && len(c.NodePools) > 0 && !featureGates.DPU() {
+		res.RecordEvent(corev1.EventTypeWarning, "FeatureGateDisabled",
+			"The dpu-host and dpu node modes require the %s feature gate, which is disabled. Using the %s node mode on every node",
+			featuregates.DPU, OVN_NODE_MODE_FULL)
+		c.NodePools = nil
+	}
+	return nil
+}
+
+// makeRenderData returns the render data of a component, with the state of the feature gates
+func makeRenderData(featureGates featuregates.FeatureGates) render.RenderData {
+	data := render.MakeRenderData()
+	featureGates.Inject(&data)
+	return data
+}
@@ -0,0 +1,90 @@
+package network
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/featuregates"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBootstrapFeatureGates(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(configv1.Install(scheme)).To(Succeed())
+	featureGate := &configv1.FeatureGate{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: configv1.FeatureGateSpec{FeatureGateSelection: configv1.FeatureGateSelection{
+			FeatureSet: configv1.TechPreviewNoUpgrade,
+		}},
+	}
+
+	res := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{NodePools: []bootstrap.OVNNodePool{
+				{Name: "dpus", Mode: OVN_NODE_MODE_DPU, NodeSelector: []v1.NodeSelectorRequirement{
+					{Key: "node-role.kubernetes.io/dpu", Operator: v1.NodeSelectorOpExists},
+				}},
+			}},
+		},
+	}
+	g.Expect(bootstrapFeatureGates(fake.NewClientBuilder().WithScheme(scheme).WithObjects(featureGate).Build(), res)).To(Succeed())
+	g.Expect(res.FeatureGates.AdminNetworkPolicy()).To(BeTrue())
+	g.Expect(res.OVN.OVNKubernetesConfig.NodePools).To(HaveLen(1))
+	g.Expect(res.Events).To(BeEmpty())
+
+	// the dpu node modes fall back to full when the DPU gate is disabled
+	featureGate.Spec.FeatureSet = configv1.CustomNoUpgrade
+	featureGate.Spec.CustomNoUpgrade = &configv1.CustomFeatureGates{Disabled: []string{featuregates.DPU}}
+	g.Expect(bootstrapFeatureGates(fake.NewClientBuilder().WithScheme(scheme).WithObjects(featureGate).Build(), res)).To(Succeed())
+	g.Expect(res.FeatureGates.AdminNetworkPolicy()).To(BeFalse())
+	g.Expect(res.OVN.OVNKubernetesConfig.NodePools).To(BeEmpty())
+	g.Expect(res.Events).To(ConsistOf(bootstrap.Event{
+		Type:    v1.EventTypeWarning,
+		Reason:  "FeatureGateDisabled",
+		Message: "The dpu-host and dpu node modes require the DPU feature gate, which is disabled. Using the full node mode on every node",
+	}))
+}
+
+func TestRenderOVNKubernetesFeatureGates(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+	networkFeatures := func() map[string]string {
+		objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+		g.Expect(err).NotTo(HaveOccurred())
+		cm := &v1.ConfigMap{}
+		g.Expect(convert(findInObjs("", "ConfigMap", "openshift-network-features", "openshift-config-managed", objs), cm)).To(Succeed())
+		return cm.Data
+	}
+
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(extractOVNKubeConfig(g, objs)).NotTo(ContainSubstring("enable-admin-network-policy"))
+	g.Expect(networkFeatures()).NotTo(HaveKey("admin_network_policy"))
+
+	bootstrapResult.FeatureGates = featuregates.New(&configv1.FeatureGate{
+		Spec: configv1.FeatureGateSpec{FeatureGateSelection: configv1.FeatureGateSelection{
+			FeatureSet: configv1.TechPreviewNoUpgrade,
+		}},
+	})
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(extractOVNKubeConfig(g, objs)).To(ContainSubstring("enable-admin-network-policy=true\n"))
+	g.Expect(networkFeatures()).To(HaveKeyWithValue("admin_network_policy", "true"))
+}
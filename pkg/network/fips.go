@@ -0,0 +1,102 @@
+package network
+
+import (
+	"fmt"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/openshift/cluster-network-operator/pkg/render"
+	"github.com/openshift/library-go/pkg/crypto"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// the cipher suites the TLS endpoints keep in FIPS mode: the ECDHE key exchanges with AES-GCM
+var fipsTLSCipherSuites = []string{
+	"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+	"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+	"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+	"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+}
+
+// the OpenSSL protocols enabled by each minimal TLS version, for the listeners of the OVN databases
+var ovnDBSSLProtocols = map[configv1.TLSProtocolVersion]string{
+	configv1.VersionTLS10: "TLSv1,TLSv1.1,TLSv1.2,TLSv1.3",
+	configv1.VersionTLS11: "TLSv1.1,TLSv1.2,TLSv1.3",
+	configv1.VersionTLS12: "TLSv1.2,TLSv1.3",
+	configv1.VersionTLS13: "TLSv1.3",
+}
+
+// ValidateFIPS checks that the cryptography requested by the operator configuration is FIPS-approved, when
+// the cluster was installed in FIPS mode. This should be called after Bootstrap, before Render.
+func ValidateFIPS(bootstrapResult *bootstrap.BootstrapResult) error {
+	if !bootstrapResult.Infra.FIPS || bootstrapResult.OVN.OVNKubernetesConfig == nil {
+		return nil
+	}
+	c := bootstrapResult.OVN.OVNKubernetesConfig
+	errs := []string{}
+	for _, p := range []struct{ annotation, proposals string }{
+		{names.IPsecIKEAnnotation, c.IPsecIKE},
+		{names.IPsecESPAnnotation, c.IPsecESP},
+	} {
+		if algorithms := sets.NewString(nonFIPSIPsecAlgorithms(p.proposals)...); algorithms.Len() > 0 {
+			errs = append(errs, fmt.Sprintf("%s uses algorithms that are not FIPS-approved: %s",
+				p.annotation, strings.Join(algorithms.List(), ", ")))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Errorf("invalid configuration in FIPS mode: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// renderTLS sets the FIPS mode of the cluster in the render data, and the TLS settings the components serve
+// with, from the TLS security profile of the API server:
+// - TLSCipherSuites and TLSMinVersion, the flags of kube-rbac-proxy
+// - OVNDBSSLProtocols and OVNDBSSLCiphers, the SSL settings of the listeners of the OVN databases
+// In FIPS mode, the cipher suites that are not FIPS-approved are removed.
+func renderTLS(data *render.RenderData, infra *bootstrap.InfraBootstrapResult) {
+	intermediate := configv1.TLSProfiles[configv1.TLSProfileIntermediateType]
+	profile := infra.TLSProfile
+	if profile == nil {
+		profile = intermediate
+	}
+
+	ciphers, opensslCiphers := tlsCiphers(profile.Ciphers, infra.FIPS)
+	// the profiles of TLS 1.3 only have no configurable ciphers, which the components still expect
+	if len(ciphers) == 0 || len(opensslCiphers) == 0 {
+		ciphers, opensslCiphers = tlsCiphers(intermediate.Ciphers, infra.FIPS)
+	}
+	minVersion := profile.MinTLSVersion
+	if _, ok := ovnDBSSLProtocols[minVersion]; !ok {
+		minVersion = intermediate.MinTLSVersion
+	}
+
+	data.Data["FIPS"] = infra.FIPS
+	data.Data["TLSCipherSuites"] = strings.Join(ciphers, ",")
+	data.Data["TLSMinVersion"] = string(minVersion)
+	data.Data["OVNDBSSLProtocols"] = ovnDBSSLProtocols[minVersion]
+	data.Data["OVNDBSSLCiphers"] = strings.Join(opensslCiphers, ":")
+}
+
+// tlsCiphers returns the IANA names of the cipher suites of a profile, as Go programs expect, and their OpenSSL
+// names, as the OpenSSL programs expect. The TLS 1.3 cipher suites, which are not configurable, are left out.
+func tlsCiphers(profileCiphers []string, fips bool) ([]string, []string) {
+	fipsCiphers := sets.NewString(fipsTLSCipherSuites...)
+	ciphers := []string{}
+	opensslCiphers := []string{}
+	for _, c := range profileCiphers {
+		if strings.HasPrefix(c, "TLS_") {
+			continue
+		}
+		iana := crypto.OpenSSLToIANACipherSuites([]string{c})
+		if fips && (len(iana) == 0 || !fipsCiphers.Has(iana[0])) {
+			continue
+		}
+		ciphers = append(ciphers, iana...)
+		opensslCiphers = append(opensslCiphers, c)
+	}
+	return ciphers, opensslCiphers
+}
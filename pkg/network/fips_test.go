@@ -0,0 +1,135 @@
+package network
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/render"
+	appsv1 "k8s.io/api/apps/v1"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestValidateFIPS(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{
+				IPsecIKE: "aes_gcm256-sha2_512-dh20,chacha20_poly1305-sha2_256-dh31,aes256-sha1-dh31",
+				IPsecESP: "aes_gcm256",
+			},
+		},
+	}
+	g.Expect(ValidateFIPS(bootstrapResult)).To(Succeed())
+
+	bootstrapResult.Infra.FIPS = true
+	g.Expect(ValidateFIPS(bootstrapResult)).To(MatchError("invalid configuration in FIPS mode: " +
+		"networkoperator.openshift.io/ipsec-ike uses algorithms that are not FIPS-approved: chacha20_poly1305, dh31, sha1"))
+
+	bootstrapResult.OVN.OVNKubernetesConfig.IPsecIKE = "aes_gcm256-sha2_512-dh20"
+	g.Expect(ValidateFIPS(bootstrapResult)).To(Succeed())
+
+	// not OVNKubernetes
+	g.Expect(ValidateFIPS(&bootstrap.BootstrapResult{Infra: bootstrap.InfraBootstrapResult{FIPS: true}})).To(Succeed())
+}
+
+func TestRenderTLS(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	infra := &bootstrap.InfraBootstrapResult{}
+	data := render.MakeRenderData()
+	renderTLS(&data, infra)
+	g.Expect(data.Data).To(HaveKeyWithValue("TLSCipherSuites", "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,"+
+		"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,"+
+		"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256"))
+	g.Expect(data.Data).To(HaveKeyWithValue("TLSMinVersion", "VersionTLS12"))
+	g.Expect(data.Data).To(HaveKeyWithValue("OVNDBSSLProtocols", "TLSv1.2,TLSv1.3"))
+	g.Expect(data.Data).To(HaveKeyWithValue("OVNDBSSLCiphers", "ECDHE-ECDSA-AES128-GCM-SHA256:ECDHE-RSA-AES128-GCM-SHA256:"+
+		"ECDHE-ECDSA-AES256-GCM-SHA384:ECDHE-RSA-AES256-GCM-SHA384:ECDHE-ECDSA-CHACHA20-POLY1305:ECDHE-RSA-CHACHA20-POLY1305:"+
+		"DHE-RSA-AES128-GCM-SHA256:DHE-RSA-AES256-GCM-SHA384"))
+
+	// custom profile
+	infra.TLSProfile = &configv1.TLSProfileSpec{
+		Ciphers:       []string{"ECDHE-RSA-AES128-SHA256", "ECDHE-RSA-CHACHA20-POLY1305"},
+		MinTLSVersion: configv1.VersionTLS11,
+	}
+	data = render.MakeRenderData()
+	renderTLS(&data, infra)
+	g.Expect(data.Data).To(HaveKeyWithValue("TLSCipherSuites", "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256,TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256"))
+	g.Expect(data.Data).To(HaveKeyWithValue("TLSMinVersion", "VersionTLS11"))
+	g.Expect(data.Data).To(HaveKeyWithValue("OVNDBSSLProtocols", "TLSv1.1,TLSv1.2,TLSv1.3"))
+	g.Expect(data.Data).To(HaveKeyWithValue("OVNDBSSLCiphers", "ECDHE-RSA-AES128-SHA256:ECDHE-RSA-CHACHA20-POLY1305"))
+
+	// the Modern profile has no configurable ciphers
+	infra.TLSProfile = configv1.TLSProfiles[configv1.TLSProfileModernType]
+	data = render.MakeRenderData()
+	renderTLS(&data, infra)
+	g.Expect(data.Data["TLSCipherSuites"]).To(HavePrefix("TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,"))
+	g.Expect(data.Data).To(HaveKeyWithValue("TLSMinVersion", "VersionTLS13"))
+	g.Expect(data.Data).To(HaveKeyWithValue("OVNDBSSLProtocols", "TLSv1.3"))
+
+	// only the FIPS-approved ciphers are kept in FIPS mode
+	infra.TLSProfile = nil
+	infra.FIPS = true
+	data = render.MakeRenderData()
+	renderTLS(&data, infra)
+	g.Expect(data.Data).To(HaveKeyWithValue("FIPS", true))
+	g.Expect(data.Data).To(HaveKeyWithValue("TLSCipherSuites", "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,"+
+		"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"))
+	g.Expect(data.Data).To(HaveKeyWithValue("OVNDBSSLCiphers", "ECDHE-ECDSA-AES128-GCM-SHA256:ECDHE-RSA-AES128-GCM-SHA256:"+
+		"ECDHE-ECDSA-AES256-GCM-SHA384:ECDHE-RSA-AES256-GCM-SHA384"))
+}
+
+func TestRenderOVNKubernetesTLS(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+	bootstrapResult := &bootstrap.BootstrapResult{
+		Infra: bootstrap.InfraBootstrapResult{
+			TLSProfile: &configv1.TLSProfileSpec{
+				Ciphers:       []string{"ECDHE-RSA-AES128-SHA256", "ECDHE-RSA-AES128-GCM-SHA256"},
+				MinTLSVersion: configv1.VersionTLS11,
+			},
+		},
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	ds := &appsv1.DaemonSet{}
+	g.Expect(convert(findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+	cont, ok := findContainer(ds.Spec.Template.Spec.Containers, "kube-rbac-proxy")
+	g.Expect(ok).To(BeTrue())
+	script := cont.Command[len(cont.Command)-1]
+	g.Expect(script).To(ContainSubstring("--tls-cipher-suites=TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256,TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 "))
+	g.Expect(script).To(ContainSubstring("--tls-min-version=VersionTLS11 "))
+
+	ds = &appsv1.DaemonSet{}
+	g.Expect(convert(findInObjs("apps", "DaemonSet", "ovnkube-master", "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+	for _, name := range []string{"nbdb", "sbdb"} {
+		cont, ok = findContainer(ds.Spec.Template.Spec.Containers, name)
+		g.Expect(ok).To(BeTrue())
+		postStart := cont.Lifecycle.PostStart.Exec.Command
+		g.Expect(postStart[len(postStart)-1]).To(ContainSubstring(
+			`set-ssl /ovn-cert/tls.key /ovn-cert/tls.crt /ovn-ca/ca-bundle.crt "TLSv1.1,TLSv1.2,TLSv1.3" "ECDHE-RSA-AES128-SHA256:ECDHE-RSA-AES128-GCM-SHA256"`))
+	}
+
+	// in FIPS mode
+	bootstrapResult.Infra.FIPS = true
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	ds = &appsv1.DaemonSet{}
+	g.Expect(convert(findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+	cont, ok = findContainer(ds.Spec.Template.Spec.Containers, "kube-rbac-proxy")
+	g.Expect(ok).To(BeTrue())
+	script = cont.Command[len(cont.Command)-1]
+	g.Expect(script).To(ContainSubstring("--tls-cipher-suites=TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 "))
+	g.Expect(script).NotTo(ContainSubstring("CBC"))
+}
@@ -1,20 +1,36 @@
 package network
 
 import (
+	"context"
+	"fmt"
 	"net"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	operv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
 	"github.com/openshift/cluster-network-operator/pkg/render"
 	k8sutil "github.com/openshift/cluster-network-operator/pkg/util/k8s"
 )
 
+// The kube-proxy-config ConfigMap holds, in its config.yaml key, a KubeProxyConfiguration
+// for the standalone kube-proxy, replacing the proxyArguments of the kubeProxyConfig.
+const (
+	KubeProxyConfigMapName      = "kube-proxy-config"
+	KubeProxyConfigMapNamespace = names.APPLIED_NAMESPACE
+	KubeProxyConfigMapKey       = "config.yaml"
+)
+
 // kubeProxyConfiguration builds the (yaml text of) the kube-proxy config object
 // It merges multiple sources of arguments. The precedence order is:
 // - pluginDefaults
@@ -37,6 +53,58 @@ func kubeProxyConfiguration(pluginDefaults map[string]operv1.ProxyArgumentList,
 	return k8sutil.GenerateKubeProxyConfiguration(args)
 }
 
+// typedKubeProxyConfiguration builds the (yaml text of) the kube-proxy config object
+// from the KubeProxyConfiguration of the kube-proxy-config ConfigMap, which replaces
+// conf.KubeProxyConfig.ProxyArguments. The precedence order is:
+// - pluginDefaults
+// - the KubeProxyConfiguration
+// - conf.KubeProxyConfig.BindAddress and IptablesSyncPeriod
+// - pluginOverrides
+func typedKubeProxyConfiguration(pluginDefaults map[string]operv1.ProxyArgumentList, conf *operv1.NetworkSpec, config string, pluginOverrides map[string]operv1.ProxyArgumentList) (string, error) {
+	p := conf.KubeProxyConfig
+
+	kpc, err := k8sutil.ParseKubeProxyConfiguration(config)
+	if err != nil {
+		return "", err
+	}
+	// The ports and the feature gates are managed by the operator, as with the proxyArguments
+	if kpc.MetricsBindAddress != "" || kpc.HealthzBindAddress != "" {
+		return "", errors.Errorf("the metricsBindAddress and healthzBindAddress of kube-proxy cannot be overridden")
+	}
+	if len(kpc.FeatureGates) > 0 {
+		return "", errors.Errorf("the featureGates of kube-proxy cannot be overridden")
+	}
+	if len(p.ProxyArguments) > 0 {
+		klog.Warningf("%s: ignoring the proxyArguments of kubeProxyConfig, replaced by the KubeProxyConfiguration", KubeProxyConfigMapName)
+	}
+
+	args := map[string]operv1.ProxyArgumentList{}
+	args["bind-address"] = []string{p.BindAddress}
+	if len(conf.ClusterNetwork) == 1 && kpc.ClusterCIDR == "" {
+		args["cluster-cidr"] = []string{conf.ClusterNetwork[0].CIDR}
+	}
+	args["iptables-sync-period"] = []string{p.IptablesSyncPeriod}
+	args = k8sutil.MergeKubeProxyArguments(args, pluginOverrides)
+
+	return k8sutil.MergeKubeProxyConfiguration(pluginDefaults, config, args)
+}
+
+// bootstrapKubeProxy retrieves the KubeProxyConfiguration of the standalone kube-proxy
+// from the kube-proxy-config ConfigMap, if any.
+func bootstrapKubeProxy(kubeClient client.Reader) (bootstrap.KubeProxyBootstrapResult, error) {
+	res := bootstrap.KubeProxyBootstrapResult{}
+	cm := &corev1.ConfigMap{}
+	nsn := types.NamespacedName{Namespace: KubeProxyConfigMapNamespace, Name: KubeProxyConfigMapName}
+	if err := kubeClient.Get(context.TODO(), nsn, cm); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return res, fmt.Errorf("Failed to retrieve the %s configmap: %w", KubeProxyConfigMapName, err)
+		}
+		return res, nil
+	}
+	res.Config = cm.Data[KubeProxyConfigMapKey]
+	return res, nil
+}
+
 // acceptsKubeProxyConfig determines if the desired network type allows
 // conf.KubeProxyConfig to be set. OpenShiftSDN deploys its own kube-proxy.
 // OVNKubernetes and Kuryr do not allow Kubernetes to be used. All other
@@ -176,10 +244,16 @@ func isKubeProxyChangeSafe(prev, next *operv1.NetworkSpec) []error {
 }
 
 // renderStandaloneKubeProxy renders the standalone kube-proxy if installation was
-// requested.
+// requested, or on the nodes of the pools of dpu nodes, where ovnkube-node does not run.
 func renderStandaloneKubeProxy(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult, manifestDir string) ([]*uns.Unstructured, error) {
 	if !*conf.DeployKubeProxy {
-		return nil, nil
+		if len(ovnNodePools(bootstrapResult, OVN_NODE_MODE_DPU)) == 0 {
+			return nil, nil
+		}
+		conf = conf.DeepCopy()
+		v := true
+		conf.DeployKubeProxy = &v
+		fillKubeProxyDefaults(conf, nil)
 	}
 
 	metricsPort := "9102"
@@ -201,12 +275,25 @@ func renderStandaloneKubeProxy(conf *operv1.NetworkSpec, bootstrapResult *bootst
 	kpcOverrides := map[string]operv1.ProxyArgumentList{
 		"metrics-port": {"29102"},
 	}
-	kpc, err := kubeProxyConfiguration(kpcDefaults, conf, kpcOverrides)
+	var kpc string
+	var err error
+	if bootstrapResult.KubeProxy.Config != "" {
+		// The KubeProxyConfiguration only overrides the proxy mode of the defaults, the
+		// addresses and ports exposed by kube-proxy remain managed by the operator.
+		kpcOverrides["metrics-bind-address"] = kpcDefaults["metrics-bind-address"]
+		kpcOverrides["healthz-port"] = []string{healthzPort}
+		delete(kpcDefaults, "metrics-bind-address")
+		delete(kpcDefaults, "healthz-port")
+		kpc, err = typedKubeProxyConfiguration(kpcDefaults, conf, bootstrapResult.KubeProxy.Config, kpcOverrides)
+	} else {
+		kpc, err = kubeProxyConfiguration(kpcDefaults, conf, kpcOverrides)
+	}
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to generate kube-proxy configuration file")
 	}
 
-	data := render.MakeRenderData()
+	data := makeRenderData(bootstrapResult.FeatureGates)
+	renderTLS(&data, &bootstrapResult.Infra)
 	data.Data["ReleaseVersion"] = os.Getenv("RELEASE_VERSION")
 	data.Data["KubeProxyImage"] = os.Getenv("KUBE_PROXY_IMAGE")
 	data.Data["KubeRBACProxyImage"] = os.Getenv("KUBE_RBAC_PROXY_IMAGE")
@@ -215,16 +302,9 @@ func renderStandaloneKubeProxy(conf *operv1.NetworkSpec, bootstrapResult *bootst
 	data.Data["KubeProxyConfig"] = kpc
 	data.Data["MetricsPort"] = metricsPort
 	data.Data["HealthzPort"] = healthzPort
-	data.Data["KUBE_PROXY_NODE_SELECTOR"] = ""
-	// DPU_DEV_PREVIEW
-	// Node Mode is currently configured via a stand-alone configMap and stored
-	// in bootstrapResult. Once out of DevPreview, CNO API will be expanded to
-	// include Node Mode and it will be stored in conf (operv1.NetworkSpec) and
-	// this code will not need to access bootstrapResult.OVN.OVNKubernetesConfig.
-	if bootstrapResult != nil && bootstrapResult.OVN.OVNKubernetesConfig != nil {
-		if bootstrapResult.OVN.OVNKubernetesConfig.NodeMode == OVN_NODE_MODE_DPU {
-			data.Data["KUBE_PROXY_NODE_SELECTOR"] = OVN_NODE_SELECTOR_DPU
-		}
+	// with OVNKubernetes, kube-proxy only runs on the nodes of the pools of dpu nodes, if any
+	if data.Data["KubeProxyNodeSelectorTerms"], err = kubeProxyNodeSelectorTerms(bootstrapResult); err != nil {
+		return nil, err
 	}
 
 	manifests, err := render.RenderDir(filepath.Join(manifestDir, "kube-proxy"), &data)
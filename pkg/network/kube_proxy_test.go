@@ -5,6 +5,7 @@ import (
 
 	operv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	k8sutil "github.com/openshift/cluster-network-operator/pkg/util/k8s"
 	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	. "github.com/onsi/gomega"
@@ -345,9 +346,7 @@ func TestFillKubeProxyDefaults(t *testing.T) {
 
 var FakeKubeProxyBootstrapResult = bootstrap.BootstrapResult{
 	OVN: bootstrap.OVNBootstrapResult{
-		OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{
-			NodeMode: "full",
-		},
+		OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
 	},
 }
 
@@ -438,3 +437,63 @@ winkernel:
 	}
 	g.Expect(found).To(BeTrue())
 }
+
+func TestRenderKubeProxyTypedConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	c := &operv1.NetworkSpec{
+		ClusterNetwork: []operv1.ClusterNetworkEntry{
+			{
+				CIDR:       "192.168.0.0/14",
+				HostPrefix: 23,
+			},
+		},
+		DefaultNetwork: operv1.DefaultNetworkDefinition{Type: "Flannel"},
+		KubeProxyConfig: &operv1.ProxyConfig{
+			IptablesSyncPeriod: "42s",
+			ProxyArguments: map[string]operv1.ProxyArgumentList{
+				"proxy-mode": {"userspace"},
+			},
+		},
+	}
+	fillKubeProxyDefaults(c, nil)
+
+	bootstrapResult := FakeKubeProxyBootstrapResult
+	bootstrapResult.KubeProxy.Config = `
+mode: ipvs
+ipvs:
+  scheduler: wrr
+conntrack:
+  min: 262144
+nodePortAddresses:
+- 10.0.0.0/8
+`
+	objs, err := renderStandaloneKubeProxy(c, &bootstrapResult, manifestDir)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	cm := findInObjs("", "ConfigMap", "proxy-config", "openshift-kube-proxy", objs)
+	g.Expect(cm).NotTo(BeNil())
+	val, _, err := uns.NestedString(cm.Object, "data", "kube-proxy-config.yaml")
+	g.Expect(err).NotTo(HaveOccurred())
+	kpc, err := k8sutil.ParseKubeProxyConfiguration(val)
+	g.Expect(err).NotTo(HaveOccurred())
+	// the proxyArguments are replaced by the KubeProxyConfiguration
+	g.Expect(string(kpc.Mode)).To(Equal("ipvs"))
+	g.Expect(kpc.IPVS.Scheduler).To(Equal("wrr"))
+	g.Expect(*kpc.Conntrack.Min).To(BeEquivalentTo(262144))
+	g.Expect(kpc.NodePortAddresses).To(Equal([]string{"10.0.0.0/8"}))
+	// the operator-managed fields are still set
+	g.Expect(kpc.BindAddress).To(Equal("0.0.0.0"))
+	g.Expect(kpc.ClusterCIDR).To(Equal("192.168.0.0/14"))
+	g.Expect(kpc.IPTables.SyncPeriod.Duration.String()).To(Equal("42s"))
+	g.Expect(kpc.MetricsBindAddress).To(Equal("0.0.0.0:29102"))
+	g.Expect(kpc.HealthzBindAddress).To(Equal("0.0.0.0:10255"))
+
+	bootstrapResult.KubeProxy.Config = "metricsBindAddress: 0.0.0.0:9999\n"
+	_, err = renderStandaloneKubeProxy(c, &bootstrapResult, manifestDir)
+	g.Expect(err).To(HaveOccurred())
+
+	bootstrapResult.KubeProxy.Config = "featureGates:\n  Foo: true\n"
+	_, err = renderStandaloneKubeProxy(c, &bootstrapResult, manifestDir)
+	g.Expect(err).To(HaveOccurred())
+}
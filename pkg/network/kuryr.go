@@ -37,7 +37,7 @@ func renderKuryr(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapR
 
 	objs := []*uns.Unstructured{}
 
-	data := render.MakeRenderData()
+	data := makeRenderData(bootstrapResult.FeatureGates)
 	data.Data["ReleaseVersion"] = os.Getenv("RELEASE_VERSION")
 
 	// OpenStack cloud CA certificate provided by the user to the installer
@@ -101,7 +101,7 @@ func renderKuryr(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapR
 	data.Data["KUBERNETES_SERVICE_HOST"] = os.Getenv("KUBERNETES_SERVICE_HOST")
 	data.Data["KUBERNETES_SERVICE_PORT"] = os.Getenv("KUBERNETES_SERVICE_PORT")
 	data.Data["CNIConfDir"] = pluginCNIConfDir(conf)
-	data.Data["CNIBinDir"] = CNIBinDir
+	data.Data["CNIBinDir"] = cniBinDir()
 
 	// We use MD5 hash of the JSONfied config data to make pods restart when
 	// configuration was changed.
@@ -111,6 +111,23 @@ func renderKuryr(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapR
 	}
 	data.Data["ConfigMapHash"] = hash
 
+	// The port pools are only managed by kuryr-controller, so tuning them does not
+	// restart kuryr-daemon on every node.
+	daemonData := map[string]interface{}{}
+	for key, val := range data.Data {
+		if key != "ConfigMapHash" {
+			daemonData[key] = val
+		}
+	}
+	for _, key := range kuryrPortPoolsDataKeys {
+		delete(daemonData, key)
+	}
+	daemonHash, err := k8sutil.CalculateHash(daemonData)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to calculate checksum of Kuryr configuration")
+	}
+	data.Data["DaemonConfigMapHash"] = daemonHash
+
 	// DNS mutating webhook
 	data.Data["AdmissionControllerSecret"] = names.KURYR_ADMISSION_CONTROLLER_SECRET
 	data.Data["WebhookSecret"] = names.KURYR_WEBHOOK_SECRET
@@ -176,6 +193,10 @@ func validateKuryr(conf *operv1.NetworkSpec) []error {
 		octaviaServiceNet = &octaviaServiceNetObj
 	}
 
+	if kc != nil && kc.PoolMaxPorts > 0 && kc.PoolMinPorts > kc.PoolMaxPorts {
+		out = append(out, errors.Errorf("poolMinPorts cannot be set above poolMaxPorts"))
+	}
+
 	if kc != nil && kc.PoolBatchPorts != nil {
 		if *kc.PoolBatchPorts > 0 {
 			if kc.PoolMinPorts > 0 && *kc.PoolBatchPorts < kc.PoolMinPorts {
@@ -221,6 +242,9 @@ func validateKuryr(conf *operv1.NetworkSpec) []error {
 	return out
 }
 
+// kuryrPortPoolsDataKeys are the render data of the port pools options
+var kuryrPortPoolsDataKeys = []string{"EnablePortPoolsPrepopulation", "PoolMaxPorts", "PoolMinPorts", "PoolBatchPorts"}
+
 // isKuryrChangeSafe makes sure to only allow changes applied to kuryr.conf
 // and not to the resources created in the bootstrap process.
 func isKuryrChangeSafe(prev, next *operv1.NetworkSpec) []error {
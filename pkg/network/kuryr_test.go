@@ -8,6 +8,7 @@ import (
 
 	"github.com/gophercloud/utils/openstack/clientconfig"
 	. "github.com/onsi/gomega"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 var KuryrConfig = operv1.Network{
@@ -73,6 +74,37 @@ func TestRenderKuryr(t *testing.T) {
 	g.Expect(objs).To(ContainElement(HaveKubernetesID("CustomResourceDefinition", "", "kuryrloadbalancers.openstack.org")))
 }
 
+func TestRenderKuryrPortPools(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := KuryrConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	hashes := func() (string, string) {
+		t.Helper()
+		objs, err := renderKuryr(config, &FakeBootstrapResult, manifestDir)
+		g.Expect(err).NotTo(HaveOccurred())
+		controller := findInObjs("apps", "Deployment", "kuryr-controller", "openshift-kuryr", objs)
+		g.Expect(controller).NotTo(BeNil())
+		daemon := findInObjs("apps", "DaemonSet", "kuryr-cni", "openshift-kuryr", objs)
+		g.Expect(daemon).NotTo(BeNil())
+		controllerHash, _, _ := uns.NestedString(controller.Object, "spec", "template", "metadata", "labels", "configuration-hash")
+		daemonHash, _, _ := uns.NestedString(daemon.Object, "spec", "template", "metadata", "labels", "configuration-hash")
+		return controllerHash, daemonHash
+	}
+
+	controllerHash, daemonHash := hashes()
+	g.Expect(controllerHash).NotTo(BeEmpty())
+	g.Expect(daemonHash).NotTo(BeEmpty())
+
+	// Tuning the port pools only restarts kuryr-controller
+	config.DefaultNetwork.KuryrConfig.PoolMaxPorts = 10
+	newControllerHash, newDaemonHash := hashes()
+	g.Expect(newControllerHash).NotTo(Equal(controllerHash))
+	g.Expect(newDaemonHash).To(Equal(daemonHash))
+}
+
 func TestValidateKuryr(t *testing.T) {
 	g := NewGomegaWithT(t)
 
@@ -142,6 +174,10 @@ func TestValidateKuryr(t *testing.T) {
 	mtu := uint32(70000)
 	config.DefaultNetwork.KuryrConfig.MTU = &mtu
 	errExpect("invalid MTU 70000")
+
+	config.DefaultNetwork.KuryrConfig.PoolMaxPorts = 2
+	config.DefaultNetwork.KuryrConfig.PoolMinPorts = 3
+	errExpect("poolMinPorts cannot be set above poolMaxPorts")
 }
 
 func TestFillKuryrDefaults(t *testing.T) {
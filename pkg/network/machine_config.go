@@ -0,0 +1,204 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/openshift/cluster-network-operator/pkg/render"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	utilnet "k8s.io/utils/net"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MachineConfigLabel labels the MachineConfigs generated by the operator
+const MachineConfigLabel = "network.operator.openshift.io/prerequisites"
+
+// the files of the prerequisites of the network on the nodes
+const (
+	machineConfigSysctlPath        = "/etc/sysctl.d/99-network-operator.conf"
+	machineConfigKernelModulesPath = "/etc/modules-load.d/network-operator.conf"
+	machineConfigKeyfileDir        = "/etc/NetworkManager/system-connections"
+)
+
+// the modes of the files of the MachineConfigs. NetworkManager ignores the keyfiles other users can read.
+const (
+	machineConfigFileMode    = 0644
+	machineConfigKeyfileMode = 0600
+)
+
+// brExInterfaceRegexp matches the names of the network interfaces of Linux
+var brExInterfaceRegexp = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,15}$`)
+
+// machineConfigRoles are the roles of the nodes the MachineConfigs are generated for. The custom
+// pools inherit the MachineConfigs of the worker role.
+var machineConfigRoles = []string{"master", "worker"}
+
+// MachineConfigPoolListGVK is the kind of the lists of the pools of nodes of the machine-config-operator
+var MachineConfigPoolListGVK = schema.GroupVersionKind{Group: "machineconfiguration.openshift.io", Version: "v1", Kind: "MachineConfigPoolList"}
+
+// machineConfigFile is a file of a MachineConfig, with its contents as a data URL
+type machineConfigFile struct {
+	Path   string
+	Mode   int
+	Source string
+}
+
+// bootstrapMachineConfigs returns whether the MachineConfigs of the prerequisites of the network are requested
+// by an annotation on the operator configuration, and can be rolled out by the machine-config-operator.
+func bootstrapMachineConfigs(conf *operv1.Network, kubeClient client.Reader) (bool, error) {
+	if !boolAnnotation(conf, names.MachineConfigAnnotation, false) || kubeClient == nil {
+		return false, nil
+	}
+	pools := &uns.UnstructuredList{}
+	pools.SetGroupVersionKind(MachineConfigPoolListGVK)
+	if err := kubeClient.List(context.TODO(), pools, client.Limit(1)); err != nil {
+		if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+			klog.Warningf("%s is set, but the nodes are not managed by the machine-config-operator. Ignoring it",
+				names.MachineConfigAnnotation)
+			return false, nil
+		}
+		return false, errors.Wrap(err, "failed to list the MachineConfigPools")
+	}
+	return true, nil
+}
+
+// bootstrapBrExInterface returns the uplink interface of the nodes, from an annotation on the operator
+// configuration, which the MachineConfigs attach to the br-ex bridge. Invalid names are ignored.
+func bootstrapBrExInterface(conf *operv1.Network) string {
+	iface, ok := conf.Annotations[names.BrExInterfaceAnnotation]
+	if !ok {
+		return ""
+	}
+	if !brExInterfaceRegexp.MatchString(iface) || iface == "br-ex" {
+		klog.Warningf("%s must be the name of the uplink interface of the nodes, is: %q. Ignoring it",
+			names.BrExInterfaceAnnotation, iface)
+		return ""
+	}
+	return iface
+}
+
+// networkPrerequisites returns the sysctls, as "<key> = <value>" lines, and the kernel modules the
+// network needs on the nodes
+func networkPrerequisites(conf *operv1.NetworkSpec) ([]string, []string) {
+	sysctls := []string{}
+	modules := []string{}
+	if conf.DefaultNetwork.Type != operv1.NetworkTypeOVNKubernetes || conf.DefaultNetwork.OVNKubernetesConfig == nil {
+		return sysctls, modules
+	}
+	ipv6 := false
+	for _, cn := range conf.ClusterNetwork {
+		if utilnet.IsIPv6CIDRString(cn.CIDR) {
+			ipv6 = true
+		}
+	}
+
+	c := conf.DefaultNetwork.OVNKubernetesConfig
+	// in local gateway mode, the egress traffic of the pods is routed by the host
+	if c.GatewayConfig != nil && c.GatewayConfig.RoutingViaHost {
+		sysctls = append(sysctls, "net.ipv4.ip_forward = 1")
+		if ipv6 {
+			sysctls = append(sysctls, "net.ipv6.conf.all.forwarding = 1")
+		}
+	}
+	// the ESP transforms of the IPsec tunnels between the nodes, configured through netlink by pluto
+	if c.IPsecConfig != nil {
+		modules = append(modules, "esp4")
+		if ipv6 {
+			modules = append(modules, "esp6")
+		}
+		modules = append(modules, "xfrm_user")
+	}
+	return sysctls, modules
+}
+
+// brExKeyfiles returns the NetworkManager keyfiles of the br-ex bridge of ovn-kubernetes, with the uplink
+// interface as its port, as the configure-ovs script of the nodes would create them. The bridge gets its
+// addresses by DHCP, or by SLAAC and DHCPv6 when the cluster network has IPv6 subnets.
+func brExKeyfiles(conf *operv1.NetworkSpec, uplink string) map[string]string {
+	if uplink == "" || conf.DefaultNetwork.Type != operv1.NetworkTypeOVNKubernetes {
+		return nil
+	}
+	ipv6Method := "disabled"
+	for _, cn := range conf.ClusterNetwork {
+		if utilnet.IsIPv6CIDRString(cn.CIDR) {
+			ipv6Method = "auto"
+		}
+	}
+	return map[string]string{
+		"br-ex": "[connection]\nid=br-ex\ntype=ovs-bridge\ninterface-name=br-ex\nautoconnect-slaves=1\n\n" +
+			"[ovs-bridge]\n\n[ipv4]\nmethod=disabled\n\n[ipv6]\nmethod=disabled\n",
+		"ovs-port-br-ex": "[connection]\nid=ovs-port-br-ex\ntype=ovs-port\ninterface-name=br-ex\nmaster=br-ex\nslave-type=ovs-bridge\n",
+		"ovs-if-br-ex": "[connection]\nid=ovs-if-br-ex\ntype=ovs-interface\ninterface-name=br-ex\nmaster=ovs-port-br-ex\n" +
+			"slave-type=ovs-port\nautoconnect-priority=100\n\n[ovs-interface]\ntype=internal\n\n" +
+			"[ipv4]\nmethod=auto\nmay-fail=false\n\n[ipv6]\nmethod=" + ipv6Method + "\n",
+		"ovs-port-phys0": fmt.Sprintf("[connection]\nid=ovs-port-phys0\ntype=ovs-port\ninterface-name=%s\nmaster=br-ex\n"+
+			"slave-type=ovs-bridge\n", uplink),
+		"ovs-if-phys0": fmt.Sprintf("[connection]\nid=ovs-if-phys0\ntype=ethernet\ninterface-name=%s\nmaster=ovs-port-phys0\n"+
+			"slave-type=ovs-port\nautoconnect-priority=100\n", uplink),
+	}
+}
+
+// machineConfigFiles returns the files of the MachineConfigs holding the prerequisites of the network
+func machineConfigFiles(conf *operv1.NetworkSpec, brExInterface string) []machineConfigFile {
+	sysctls, modules := networkPrerequisites(conf)
+	files := []machineConfigFile{}
+	for _, f := range []struct {
+		path  string
+		lines []string
+	}{
+		{machineConfigSysctlPath, sysctls},
+		{machineConfigKernelModulesPath, modules},
+	} {
+		if len(f.lines) == 0 {
+			continue
+		}
+		contents := "# Generated by the cluster-network-operator, do not edit\n" + strings.Join(f.lines, "\n") + "\n"
+		files = append(files, machineConfigFile{Path: f.path, Mode: machineConfigFileMode, Source: "data:," + url.PathEscape(contents)})
+	}
+	keyfiles := brExKeyfiles(conf, brExInterface)
+	for _, id := range sets.StringKeySet(keyfiles).List() {
+		files = append(files, machineConfigFile{
+			Path:   filepath.Join(machineConfigKeyfileDir, id+".nmconnection"),
+			Mode:   machineConfigKeyfileMode,
+			Source: "data:," + url.PathEscape(keyfiles[id]),
+		})
+	}
+	return files
+}
+
+// renderMachineConfigs generates the MachineConfigs holding the sysctls, the kernel modules and the br-ex
+// bridge the network needs on the nodes, one per role, which the machine-config-operator rolls out. No MachineConfig is
+// generated when the network needs none of them.
+func renderMachineConfigs(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult, manifestDir string) ([]*uns.Unstructured, error) {
+	if !bootstrapResult.MachineConfigs {
+		return nil, nil
+	}
+	files := machineConfigFiles(conf, bootstrapResult.BrExInterface)
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	data := makeRenderData(bootstrapResult.FeatureGates)
+	data.Data["MachineConfigRoles"] = machineConfigRoles
+	data.Data["MachineConfigFiles"] = files
+	data.Data["MachineConfigLabel"] = MachineConfigLabel
+
+	manifests, err := render.RenderDir(filepath.Join(manifestDir, "network/machine-config"), &data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render machine-config manifests")
+	}
+	return manifests, nil
+}
@@ -0,0 +1,141 @@
+package network
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBootstrapMachineConfigs(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// MachineConfigPools are only known as unstructured objects
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	scheme.AddKnownTypeWithName(MachineConfigPoolListGVK.GroupVersion().WithKind("MachineConfigPool"), &uns.Unstructured{})
+	scheme.AddKnownTypeWithName(MachineConfigPoolListGVK, &uns.UnstructuredList{})
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	conf := &operv1.Network{}
+	g.Expect(bootstrapMachineConfigs(conf, kubeClient)).To(BeFalse())
+	conf.Annotations = map[string]string{names.MachineConfigAnnotation: "yes"}
+	g.Expect(bootstrapMachineConfigs(conf, kubeClient)).To(BeFalse())
+	conf.Annotations = map[string]string{names.MachineConfigAnnotation: "true"}
+	g.Expect(bootstrapMachineConfigs(conf, kubeClient)).To(BeTrue())
+	g.Expect(bootstrapMachineConfigs(conf, nil)).To(BeFalse())
+}
+
+func TestBootstrapBrExInterface(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	conf := &operv1.Network{}
+	g.Expect(bootstrapBrExInterface(conf)).To(BeEmpty())
+	conf.Annotations = map[string]string{names.BrExInterfaceAnnotation: "enp1s0"}
+	g.Expect(bootstrapBrExInterface(conf)).To(Equal("enp1s0"))
+	for _, invalid := range []string{"", "br-ex", "enp1s0 enp2s0", "../eth0", "a-very-long-interface"} {
+		conf.Annotations[names.BrExInterfaceAnnotation] = invalid
+		g.Expect(bootstrapBrExInterface(conf)).To(BeEmpty(), invalid)
+	}
+}
+
+func TestRenderMachineConfigs(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+	bootstrapResult := &bootstrap.BootstrapResult{MachineConfigs: true}
+
+	// shared gateway mode without IPsec needs nothing
+	objs, err := renderMachineConfigs(config, bootstrapResult, manifestDir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).To(BeEmpty())
+
+	config.DefaultNetwork.OVNKubernetesConfig.GatewayConfig = &operv1.GatewayConfig{RoutingViaHost: true}
+	config.DefaultNetwork.OVNKubernetesConfig.IPsecConfig = &operv1.IPsecConfig{}
+	objs, err = renderMachineConfigs(config, bootstrapResult, manifestDir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).To(HaveLen(2))
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("MachineConfig", "", "50-master-network-operator")))
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("MachineConfig", "", "50-worker-network-operator")))
+
+	mc := findInObjs("machineconfiguration.openshift.io", "MachineConfig", "50-worker-network-operator", "", objs)
+	g.Expect(mc.GetLabels()).To(HaveKeyWithValue("machineconfiguration.openshift.io/role", "worker"))
+	g.Expect(mc.GetLabels()).To(HaveKey(MachineConfigLabel))
+	files, _, err := uns.NestedSlice(mc.Object, "spec", "config", "storage", "files")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(files).To(HaveLen(2))
+	sysctls := files[0].(map[string]interface{})
+	g.Expect(sysctls["path"]).To(Equal(machineConfigSysctlPath))
+	g.Expect(sysctls["contents"]).To(HaveKeyWithValue("source", ContainSubstring("net.ipv4.ip_forward%20=%201")))
+	modules := files[1].(map[string]interface{})
+	g.Expect(modules["path"]).To(Equal(machineConfigKernelModulesPath))
+	g.Expect(modules["contents"]).To(HaveKeyWithValue("source", ContainSubstring("esp4%0Axfrm_user%0A")))
+
+	// the keyfiles of br-ex are only readable by root
+	bootstrapResult.BrExInterface = "enp1s0"
+	objs, err = renderMachineConfigs(config, bootstrapResult, manifestDir)
+	g.Expect(err).NotTo(HaveOccurred())
+	mc = findInObjs("machineconfiguration.openshift.io", "MachineConfig", "50-master-network-operator", "", objs)
+	files, _, err = uns.NestedSlice(mc.Object, "spec", "config", "storage", "files")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(files).To(HaveLen(7))
+	g.Expect(files[0].(map[string]interface{})["mode"]).To(BeEquivalentTo(0644))
+	uplink := files[4].(map[string]interface{})
+	g.Expect(uplink["path"]).To(Equal("/etc/NetworkManager/system-connections/ovs-if-phys0.nmconnection"))
+	g.Expect(uplink["mode"]).To(BeEquivalentTo(0600))
+	g.Expect(uplink["contents"]).To(HaveKeyWithValue("source", ContainSubstring("interface-name=enp1s0%0Amaster=ovs-port-phys0%0A")))
+
+	// nor are they rendered unless requested
+	objs, err = renderMachineConfigs(config, &bootstrap.BootstrapResult{}, manifestDir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).To(BeEmpty())
+}
+
+func TestNetworkPrerequisites(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	config.ClusterNetwork = append(config.ClusterNetwork, operv1.ClusterNetworkEntry{CIDR: "fd00:10:128::/56", HostPrefix: 64})
+	config.DefaultNetwork.OVNKubernetesConfig.GatewayConfig = &operv1.GatewayConfig{RoutingViaHost: true}
+	config.DefaultNetwork.OVNKubernetesConfig.IPsecConfig = &operv1.IPsecConfig{}
+
+	sysctls, modules := networkPrerequisites(config)
+	g.Expect(sysctls).To(Equal([]string{"net.ipv4.ip_forward = 1", "net.ipv6.conf.all.forwarding = 1"}))
+	g.Expect(modules).To(Equal([]string{"esp4", "esp6", "xfrm_user"}))
+
+	config.DefaultNetwork.Type = operv1.NetworkTypeOpenShiftSDN
+	sysctls, modules = networkPrerequisites(config)
+	g.Expect(sysctls).To(BeEmpty())
+	g.Expect(modules).To(BeEmpty())
+}
+
+func TestBrExKeyfiles(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	g.Expect(brExKeyfiles(config, "")).To(BeEmpty())
+
+	keyfiles := brExKeyfiles(config, "enp1s0")
+	g.Expect(keyfiles).To(HaveLen(5))
+	g.Expect(keyfiles).To(HaveKeyWithValue("br-ex", ContainSubstring("type=ovs-bridge\n")))
+	g.Expect(keyfiles).To(HaveKeyWithValue("ovs-port-phys0", ContainSubstring("interface-name=enp1s0\nmaster=br-ex\n")))
+	g.Expect(keyfiles).To(HaveKeyWithValue("ovs-if-phys0", ContainSubstring("type=ethernet\ninterface-name=enp1s0\n")))
+	g.Expect(keyfiles).To(HaveKeyWithValue("ovs-if-br-ex", HaveSuffix("[ipv4]\nmethod=auto\nmay-fail=false\n\n[ipv6]\nmethod=disabled\n")))
+
+	// IPv6 is configured on dual-stack clusters
+	config.ClusterNetwork = append(config.ClusterNetwork, operv1.ClusterNetworkEntry{CIDR: "fd00:10:128::/56", HostPrefix: 64})
+	g.Expect(brExKeyfiles(config, "enp1s0")).To(HaveKeyWithValue("ovs-if-br-ex", HaveSuffix("[ipv6]\nmethod=auto\n")))
+
+	config.DefaultNetwork.Type = operv1.NetworkTypeOpenShiftSDN
+	g.Expect(brExKeyfiles(config, "enp1s0")).To(BeEmpty())
+}
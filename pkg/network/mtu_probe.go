@@ -0,0 +1,34 @@
+package network
+
+import (
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"k8s.io/klog/v2"
+)
+
+// sdnOverhead is the overhead of the VXLAN header of openshift-sdn
+const sdnOverhead = 50
+
+// bootstrapMTUProbe returns whether the probe of the path MTU between the nodes is requested by an
+// annotation on the operator configuration
+func bootstrapMTUProbe(conf *operv1.Network) bool {
+	return boolAnnotation(conf, names.MTUProbeAnnotation, false)
+}
+
+// mtuProbeMTU returns the MTU the path between the nodes must carry for the overlay, the MTU of the pod
+// network with the encapsulation overhead, or 0 when the default network has no overlay whose MTU the
+// operator knows, in which case the probe is not rendered.
+func mtuProbeMTU(conf *operv1.NetworkSpec) uint32 {
+	switch conf.DefaultNetwork.Type {
+	case operv1.NetworkTypeOVNKubernetes:
+		if c := conf.DefaultNetwork.OVNKubernetesConfig; c != nil && c.MTU != nil {
+			return *c.MTU + getOVNEncapOverhead(conf)
+		}
+	case operv1.NetworkTypeOpenShiftSDN:
+		if c := conf.DefaultNetwork.OpenShiftSDNConfig; c != nil && c.MTU != nil {
+			return *c.MTU + sdnOverhead
+		}
+	}
+	klog.Warningf("The path MTU between the nodes can only be probed with the OVNKubernetes and OpenShiftSDN overlays, not rendering the probe")
+	return 0
+}
@@ -0,0 +1,70 @@
+package network
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+func TestBootstrapMTUProbe(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	conf := &operv1.Network{}
+	g.Expect(bootstrapMTUProbe(conf)).To(BeFalse())
+	conf.Annotations = map[string]string{names.MTUProbeAnnotation: "true"}
+	g.Expect(bootstrapMTUProbe(conf)).To(BeTrue())
+	conf.Annotations = map[string]string{names.MTUProbeAnnotation: "yes"}
+	g.Expect(bootstrapMTUProbe(conf)).To(BeFalse())
+}
+
+func TestRenderMTUProbe(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+	mtu := uint32(1400)
+	config.DefaultNetwork.OVNKubernetesConfig.MTU = &mtu
+	bootstrapResult := &bootstrap.BootstrapResult{}
+
+	objs, err := renderNetworkDiagnostics(config, bootstrapResult, manifestDir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("DaemonSet", "openshift-network-diagnostics", "network-check-target")))
+	g.Expect(objs).NotTo(ContainElement(HaveKubernetesID("DaemonSet", "openshift-network-diagnostics", "network-check-mtu")))
+
+	bootstrapResult.MTUProbe = true
+	objs, err = renderNetworkDiagnostics(config, bootstrapResult, manifestDir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("ServiceAccount", "openshift-network-diagnostics", "network-check-mtu")))
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("ClusterRole", "", "network-check-mtu")))
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("Role", "openshift-network-diagnostics", "network-check-mtu")))
+
+	ds := &appsv1.DaemonSet{}
+	g.Expect(convert(findInObjs("apps", "DaemonSet", "network-check-mtu", "openshift-network-diagnostics", objs), ds)).To(Succeed())
+	g.Expect(ds.Spec.Template.Spec.HostNetwork).To(BeTrue())
+	probe := ds.Spec.Template.Spec.Containers[0]
+	g.Expect(probe.Command[2]).To(ContainSubstring(`ping "${family}" -M do -s $((MTU - header))`))
+	// the MTU of the pod network with the geneve overhead
+	g.Expect(probe.Env[0].Name).To(Equal("MTU"))
+	g.Expect(probe.Env[0].Value).To(Equal("1500"))
+
+	// with IPsec
+	config.DefaultNetwork.OVNKubernetesConfig.IPsecConfig = &operv1.IPsecConfig{}
+	g.Expect(mtuProbeMTU(config)).To(Equal(uint32(1546)))
+
+	// the probe is not rendered without the network diagnostics, nor without a known overlay
+	config.DisableNetworkDiagnostics = true
+	objs, err = renderNetworkDiagnostics(config, bootstrapResult, manifestDir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).To(BeEmpty())
+
+	config.DisableNetworkDiagnostics = false
+	config.DefaultNetwork = operv1.DefaultNetworkDefinition{Type: "Calico"}
+	objs, err = renderNetworkDiagnostics(config, bootstrapResult, manifestDir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).NotTo(ContainElement(HaveKubernetesID("DaemonSet", "openshift-network-diagnostics", "network-check-mtu")))
+}
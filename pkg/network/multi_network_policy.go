@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 
 	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/featuregates"
 	"github.com/openshift/cluster-network-operator/pkg/render"
 	"github.com/pkg/errors"
 
@@ -12,11 +13,11 @@ import (
 )
 
 // renderMultiNetworkpolicyConfig returns the manifests of MultiNetworkPolicy
-func renderMultiNetworkpolicyConfig(manifestDir string) ([]*uns.Unstructured, error) {
+func renderMultiNetworkpolicyConfig(manifestDir string, featureGates featuregates.FeatureGates) ([]*uns.Unstructured, error) {
 	objs := []*uns.Unstructured{}
 
 	// render the manifests on disk
-	data := render.MakeRenderData()
+	data := makeRenderData(featureGates)
 	data.Data["ReleaseVersion"] = os.Getenv("RELEASE_VERSION")
 	data.Data["MultiNetworkPolicyImage"] = os.Getenv("MULTUS_NETWORKPOLICY_IMAGE")
 
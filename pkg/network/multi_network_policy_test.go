@@ -6,6 +6,7 @@ import (
 	. "github.com/onsi/gomega"
 	operv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/cluster-network-operator/pkg/apply"
+	"github.com/openshift/cluster-network-operator/pkg/featuregates"
 )
 
 var MultiNetworkPolicyConfig = operv1.Network{
@@ -37,14 +38,14 @@ func TestRenderMultiNetworkPolicy(t *testing.T) {
 	FillDefaults(config, nil)
 
 	// disable MultiNetworkPolicy
-	objs, err := renderMultiNetworkpolicy(config, manifestDir)
+	objs, err := renderMultiNetworkpolicy(config, manifestDir, featuregates.FeatureGates{})
 	g.Expect(err).NotTo(HaveOccurred())
 	g.Expect(objs).NotTo(ContainElement(HaveKubernetesID("DaemonSet", "openshift-multus", "multus-networkpolicy")))
 
 	// enable MultiNetworkPolicy
 	enabled := true
 	config.UseMultiNetworkPolicy = &enabled
-	objs, err = renderMultiNetworkpolicy(config, manifestDir)
+	objs, err = renderMultiNetworkpolicy(config, manifestDir, featuregates.FeatureGates{})
 	g.Expect(err).NotTo(HaveOccurred())
 	g.Expect(objs).To(ContainElement(HaveKubernetesID("DaemonSet", "openshift-multus", "multus-networkpolicy")))
 
@@ -1,15 +1,21 @@
 package network
 
 import (
+	"log"
 	"os"
 	"path/filepath"
 
 	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/featuregates"
 	"github.com/openshift/cluster-network-operator/pkg/render"
 	"github.com/pkg/errors"
 	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
+// Default locations of the CNI directories on the nodes. Distributions with a
+// read-only /etc or a kubelet configured with other paths can relocate them with
+// the SYSTEM_CNI_CONF_DIR, MULTUS_CNI_CONF_DIR and CNI_BIN_DIR environment variables.
 const (
 	SystemCNIConfDir = "/etc/kubernetes/cni/net.d"
 	MultusCNIConfDir = "/var/run/multus/cni/net.d"
@@ -17,7 +23,7 @@ const (
 )
 
 // renderMultus generates the manifests of Multus
-func renderMultus(conf *operv1.NetworkSpec, manifestDir string) ([]*uns.Unstructured, error) {
+func renderMultus(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult, manifestDir string) ([]*uns.Unstructured, error) {
 	if *conf.DisableMultiNetwork {
 		return nil, nil
 	}
@@ -25,20 +31,20 @@ func renderMultus(conf *operv1.NetworkSpec, manifestDir string) ([]*uns.Unstruct
 	out := []*uns.Unstructured{}
 
 	// enabling Multus always renders the CRD since Multus uses it
-	objs, err := renderAdditionalNetworksCRD(manifestDir)
+	objs, err := renderAdditionalNetworksCRD(manifestDir, bootstrapResult.FeatureGates)
 	if err != nil {
 		return nil, err
 	}
 	out = append(out, objs...)
 
 	usedhcp := useDHCP(conf)
-	objs, err = renderMultusConfig(manifestDir, string(conf.DefaultNetwork.Type), usedhcp)
+	objs, err = renderMultusConfig(manifestDir, string(conf.DefaultNetwork.Type), bootstrapResult.ThirdPartyCNI.ConfigFile, usedhcp, bootstrapResult.NodeRollout, bootstrapResult.FeatureGates)
 	if err != nil {
 		return nil, err
 	}
 	out = append(out, objs...)
 
-	objs, err = renderNetworkMetricsDaemon(manifestDir)
+	objs, err = renderNetworkMetricsDaemon(manifestDir, &bootstrapResult.Infra, bootstrapResult.FeatureGates)
 	if err != nil {
 		return nil, err
 	}
@@ -47,12 +53,14 @@ func renderMultus(conf *operv1.NetworkSpec, manifestDir string) ([]*uns.Unstruct
 	return out, nil
 }
 
-// renderMultusConfig returns the manifests of Multus
-func renderMultusConfig(manifestDir, defaultNetworkType string, useDHCP bool) ([]*uns.Unstructured, error) {
+// renderMultusConfig returns the manifests of Multus. thirdPartyConfigFile is the CNI configuration file
+// of a third-party default network, if any, which Multus waits for. nodeRollout is the rolling update of
+// its DaemonSets.
+func renderMultusConfig(manifestDir, defaultNetworkType, thirdPartyConfigFile string, useDHCP bool, nodeRollout bootstrap.NodeRollout, featureGates featuregates.FeatureGates) ([]*uns.Unstructured, error) {
 	objs := []*uns.Unstructured{}
 
 	// render the manifests on disk
-	data := render.MakeRenderData()
+	data := makeRenderData(featureGates)
 	data.Data["ReleaseVersion"] = os.Getenv("RELEASE_VERSION")
 	data.Data["MultusImage"] = os.Getenv("MULTUS_IMAGE")
 	data.Data["CNIPluginsImage"] = os.Getenv("CNI_PLUGINS_IMAGE")
@@ -63,10 +71,13 @@ func renderMultusConfig(manifestDir, defaultNetworkType string, useDHCP bool) ([
 	data.Data["KUBERNETES_SERVICE_HOST"] = os.Getenv("KUBERNETES_SERVICE_HOST")
 	data.Data["KUBERNETES_SERVICE_PORT"] = os.Getenv("KUBERNETES_SERVICE_PORT")
 	data.Data["RenderDHCP"] = useDHCP
-	data.Data["MultusCNIConfDir"] = MultusCNIConfDir
-	data.Data["SystemCNIConfDir"] = SystemCNIConfDir
+	data.Data["MultusCNIConfDir"] = multusCNIConfDir()
+	data.Data["SystemCNIConfDir"] = systemCNIConfDir()
 	data.Data["DefaultNetworkType"] = defaultNetworkType
-	data.Data["CNIBinDir"] = CNIBinDir
+	data.Data["ThirdPartyCNIConfigFile"] = thirdPartyConfigFile
+	data.Data["CNIBinDir"] = cniBinDir()
+	data.Data["NodeMaxUnavailable"] = nodeMaxUnavailable(nodeRollout)
+	data.Data["NodeMaxSurge"] = nodeRollout.MaxSurge
 
 	manifests, err := render.RenderDir(filepath.Join(manifestDir, "network/multus"), &data)
 	if err != nil {
@@ -77,12 +88,13 @@ func renderMultusConfig(manifestDir, defaultNetworkType string, useDHCP bool) ([
 }
 
 // renderNetworkMetricsDaemon returns the manifests of the Network Metrics Daemon
-func renderNetworkMetricsDaemon(manifestDir string) ([]*uns.Unstructured, error) {
+func renderNetworkMetricsDaemon(manifestDir string, infra *bootstrap.InfraBootstrapResult, featureGates featuregates.FeatureGates) ([]*uns.Unstructured, error) {
 
 	objs := []*uns.Unstructured{}
 
 	// render the manifests on disk
-	data := render.MakeRenderData()
+	data := makeRenderData(featureGates)
+	renderTLS(&data, infra)
 	data.Data["ReleaseVersion"] = os.Getenv("RELEASE_VERSION")
 	data.Data["NetworkMetricsImage"] = os.Getenv("NETWORK_METRICS_DAEMON_IMAGE")
 	data.Data["KubeRBACProxyImage"] = os.Getenv("KUBE_RBAC_PROXY_IMAGE")
@@ -100,7 +112,36 @@ func renderNetworkMetricsDaemon(manifestDir string) ([]*uns.Unstructured, error)
 // is disabled
 func pluginCNIConfDir(conf *operv1.NetworkSpec) string {
 	if *conf.DisableMultiNetwork {
-		return SystemCNIConfDir
+		return systemCNIConfDir()
 	}
-	return MultusCNIConfDir
+	return multusCNIConfDir()
+}
+
+// systemCNIConfDir is the directory where the kubelet looks for CNI configuration files
+func systemCNIConfDir() string {
+	return cniDirFromEnv("SYSTEM_CNI_CONF_DIR", SystemCNIConfDir)
+}
+
+// multusCNIConfDir is the directory where multus looks for the default network configuration file
+func multusCNIConfDir() string {
+	return cniDirFromEnv("MULTUS_CNI_CONF_DIR", MultusCNIConfDir)
+}
+
+// cniBinDir is the directory where the kubelet looks for CNI plugin binaries
+func cniBinDir() string {
+	return cniDirFromEnv("CNI_BIN_DIR", CNIBinDir)
+}
+
+// cniDirFromEnv returns the host directory set in the given environment variable,
+// or the default when it is unset or not an absolute path.
+func cniDirFromEnv(env, defaultDir string) string {
+	dir := os.Getenv(env)
+	if dir == "" {
+		return defaultDir
+	}
+	if !filepath.IsAbs(dir) {
+		log.Printf("WARNING: %s must be an absolute path, is: %q. Using: %s", env, dir, defaultDir)
+		return defaultDir
+	}
+	return filepath.Clean(dir)
 }
@@ -6,6 +6,8 @@ import (
 	. "github.com/onsi/gomega"
 	operv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/cluster-network-operator/pkg/apply"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/featuregates"
 	"github.com/openshift/cluster-network-operator/pkg/names"
 )
 
@@ -38,14 +40,14 @@ func TestRenderMultusAdmissionController(t *testing.T) {
 	FillDefaults(config, nil)
 
 	// disable MultusAdmissionController
-	objs, err := renderMultusAdmissionController(config, manifestDir, false)
+	objs, err := renderMultusAdmissionController(config, manifestDir, &bootstrap.InfraBootstrapResult{}, featuregates.FeatureGates{})
 	g.Expect(err).NotTo(HaveOccurred())
 	g.Expect(objs).NotTo(ContainElement(HaveKubernetesID("DaemonSet", "openshift-multus", "multus-admission-controller")))
 
 	// enable MultusAdmissionController
 	enabled := false
 	config.DisableMultiNetwork = &enabled
-	objs, err = renderMultusAdmissionController(config, manifestDir, false)
+	objs, err = renderMultusAdmissionController(config, manifestDir, &bootstrap.InfraBootstrapResult{}, featuregates.FeatureGates{})
 	g.Expect(err).NotTo(HaveOccurred())
 	g.Expect(objs).To(ContainElement(HaveKubernetesID("DaemonSet", "openshift-multus", "multus-admission-controller")))
 
@@ -1,10 +1,13 @@
 package network
 
 import (
+	"os"
 	"testing"
 
 	operv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/cluster-network-operator/pkg/apply"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	. "github.com/onsi/gomega"
 )
@@ -38,14 +41,14 @@ func TestRenderMultus(t *testing.T) {
 	FillDefaults(config, nil)
 
 	// disable Multus
-	objs, err := renderMultus(config, manifestDir)
+	objs, err := renderMultus(config, &bootstrap.BootstrapResult{}, manifestDir)
 	g.Expect(err).NotTo(HaveOccurred())
 	g.Expect(objs).NotTo(ContainElement(HaveKubernetesID("DaemonSet", "openshift-multus", "multus")))
 
 	// enable Multus
 	enabled := false
 	config.DisableMultiNetwork = &enabled
-	objs, err = renderMultus(config, manifestDir)
+	objs, err = renderMultus(config, &bootstrap.BootstrapResult{}, manifestDir)
 	g.Expect(err).NotTo(HaveOccurred())
 	g.Expect(objs).To(ContainElement(HaveKubernetesID("DaemonSet", "openshift-multus", "multus")))
 
@@ -74,3 +77,50 @@ func TestRenderMultus(t *testing.T) {
 		g.Expect(cur).To(Equal(upd))
 	}
 }
+
+// TestRenderMultusCNIDirs checks that the CNI directories on the nodes can be relocated
+func TestRenderMultusCNIDirs(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := MultusConfig.DeepCopy()
+	config := &crd.Spec
+	enabled := false
+	config.DisableMultiNetwork = &enabled
+	FillDefaults(config, nil)
+
+	os.Setenv("SYSTEM_CNI_CONF_DIR", "/var/kubernetes/cni/net.d/")
+	defer os.Unsetenv("SYSTEM_CNI_CONF_DIR")
+	os.Setenv("CNI_BIN_DIR", "/usr/local/cni/bin")
+	defer os.Unsetenv("CNI_BIN_DIR")
+	// relative paths are ignored
+	os.Setenv("MULTUS_CNI_CONF_DIR", "multus/net.d")
+	defer os.Unsetenv("MULTUS_CNI_CONF_DIR")
+
+	objs, err := renderMultus(config, &bootstrap.BootstrapResult{}, manifestDir)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	var ds *uns.Unstructured
+	for _, obj := range objs {
+		if obj.GetKind() == "DaemonSet" && obj.GetName() == "multus" {
+			ds = obj
+		}
+	}
+	g.Expect(ds).NotTo(BeNil())
+	volumes, _, err := uns.NestedSlice(ds.Object, "spec", "template", "spec", "volumes")
+	g.Expect(err).NotTo(HaveOccurred())
+	paths := map[string]interface{}{}
+	for _, v := range volumes {
+		volume := v.(map[string]interface{})
+		if hostPath, ok := volume["hostPath"].(map[string]interface{}); ok {
+			paths[volume["name"].(string)] = hostPath["path"]
+		}
+	}
+	g.Expect(paths).To(HaveKeyWithValue("system-cni-dir", "/var/kubernetes/cni/net.d"))
+	g.Expect(paths).To(HaveKeyWithValue("multus-cni-dir", MultusCNIConfDir))
+	g.Expect(paths).To(HaveKeyWithValue("cnibin", "/usr/local/cni/bin"))
+
+	g.Expect(pluginCNIConfDir(config)).To(Equal(MultusCNIConfDir))
+	disabled := true
+	config.DisableMultiNetwork = &disabled
+	g.Expect(pluginCNIConfDir(config)).To(Equal("/var/kubernetes/cni/net.d"))
+}
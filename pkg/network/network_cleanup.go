@@ -0,0 +1,111 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/openshift/cluster-network-operator/pkg/render"
+	"github.com/pkg/errors"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	NetworkCleanupDaemonSetName      = "network-cleanup"
+	NetworkCleanupDaemonSetNamespace = names.APPLIED_NAMESPACE
+)
+
+// the network types whose state the network-cleanup daemonset knows to clean up on the nodes
+var cleanableNetworkTypes = sets.NewString(string(operv1.NetworkTypeOpenShiftSDN), string(operv1.NetworkTypeOVNKubernetes))
+
+// bootstrapNetworkCleanup keeps track, in annotations on the operator configuration, of the default
+// network type deployed on the nodes. When it changes, the state left on the nodes by the former
+// network type is to be cleaned up, until the network-cleanup daemonset has run on every node.
+func bootstrapNetworkCleanup(conf *operv1.Network, kubeClient client.Reader, res *bootstrap.BootstrapResult) error {
+	annotations := conf.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	current := string(conf.Spec.DefaultNetwork.Type)
+	cleanup := annotations[names.NetworkCleanupAnnotation]
+	if previous, ok := annotations[names.NetworkTypeAnnotation]; ok && previous != current && cleanableNetworkTypes.Has(previous) {
+		res.RecordEvent(corev1.EventTypeNormal, "NetworkCleanupPending",
+			"The default network type changed from %s to %s, cleaning up the %s state of the nodes once the migration is over",
+			previous, current, previous)
+		cleanup = previous
+	}
+	if cleanup == current {
+		// migrated back before the cleanup ran
+		cleanup = ""
+	}
+
+	if cleanup != "" {
+		ds := &appsv1.DaemonSet{}
+		nsn := types.NamespacedName{Namespace: NetworkCleanupDaemonSetNamespace, Name: NetworkCleanupDaemonSetName}
+		if err := kubeClient.Get(context.TODO(), nsn, ds); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return fmt.Errorf("Failed to retrieve the %s daemonset: %w", NetworkCleanupDaemonSetName, err)
+			}
+			ds = nil
+		}
+		if ds != nil && ds.GetAnnotations()[names.NetworkCleanupAnnotation] == cleanup && !daemonSetProgressing(ds, false) {
+			res.RecordEvent(corev1.EventTypeNormal, "NetworkCleanupCompleted",
+				"The %s state was cleaned up on %d nodes", cleanup, ds.Status.DesiredNumberScheduled)
+			cleanup = ""
+		}
+	}
+
+	annotations[names.NetworkTypeAnnotation] = current
+	if cleanup != "" {
+		annotations[names.NetworkCleanupAnnotation] = cleanup
+	} else {
+		delete(annotations, names.NetworkCleanupAnnotation)
+	}
+	conf.SetAnnotations(annotations)
+
+	res.NetworkCleanup = cleanup
+	return nil
+}
+
+// renderNetworkCleanup generates the manifests of the network-cleanup daemonset, which removes the OVS
+// bridges, iptables chains and CNI configuration left on each node by the former default network type.
+// It only runs once the migration to the new network type is over, that is once the migration
+// field of the operator configuration is cleared, so that the nodes were rebooted onto the new network.
+func renderNetworkCleanup(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult, manifestDir string) ([]*uns.Unstructured, error) {
+	if bootstrapResult.NetworkCleanup == "" {
+		return nil, nil
+	}
+	if conf.Migration != nil && conf.Migration.NetworkType != "" {
+		klog.Infof("Waiting for the migration to %s to be over before cleaning up the %s state of the nodes",
+			conf.Migration.NetworkType, bootstrapResult.NetworkCleanup)
+		return nil, nil
+	}
+
+	data := makeRenderData(bootstrapResult.FeatureGates)
+	data.Data["ReleaseVersion"] = os.Getenv("RELEASE_VERSION")
+	// the cleanup needs bash, ovs-vsctl and iptables, which the OVN image ships
+	data.Data["NetworkCleanupImage"] = os.Getenv("OVN_IMAGE")
+	data.Data["NetworkCleanup"] = bootstrapResult.NetworkCleanup
+	data.Data["SystemCNIConfDir"] = systemCNIConfDir()
+	data.Data["MultusCNIConfDir"] = multusCNIConfDir()
+	data.Data["CNIBinDir"] = cniBinDir()
+
+	manifests, err := render.RenderDir(filepath.Join(manifestDir, "network/cleanup"), &data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render network-cleanup manifests")
+	}
+	return manifests, nil
+}
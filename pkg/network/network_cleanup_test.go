@@ -0,0 +1,107 @@
+package network
+
+import (
+	"testing"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBootstrapNetworkCleanup(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	client := fake.NewClientBuilder().Build()
+	crd := OVNKubernetesConfig.DeepCopy()
+
+	// the network type is recorded on the first install
+	res := &bootstrap.BootstrapResult{}
+	g.Expect(bootstrapNetworkCleanup(crd, client, res)).To(Succeed())
+	g.Expect(res.NetworkCleanup).To(BeEmpty())
+	g.Expect(res.Events).To(BeEmpty())
+	g.Expect(crd.Annotations).To(Equal(map[string]string{names.NetworkTypeAnnotation: "OVNKubernetes"}))
+
+	// the network type changed
+	crd.Annotations[names.NetworkTypeAnnotation] = "OpenShiftSDN"
+	g.Expect(bootstrapNetworkCleanup(crd, client, res)).To(Succeed())
+	g.Expect(res.NetworkCleanup).To(Equal("OpenShiftSDN"))
+	g.Expect(res.Events).To(Equal([]bootstrap.Event{{
+		Type:    corev1.EventTypeNormal,
+		Reason:  "NetworkCleanupPending",
+		Message: "The default network type changed from OpenShiftSDN to OVNKubernetes, cleaning up the OpenShiftSDN state of the nodes once the migration is over",
+	}}))
+	g.Expect(crd.Annotations).To(Equal(map[string]string{
+		names.NetworkTypeAnnotation:    "OVNKubernetes",
+		names.NetworkCleanupAnnotation: "OpenShiftSDN",
+	}))
+
+	// the cleanup is pending until the daemonset ran on every node
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        NetworkCleanupDaemonSetName,
+			Namespace:   NetworkCleanupDaemonSetNamespace,
+			Annotations: map[string]string{names.NetworkCleanupAnnotation: "OpenShiftSDN"},
+			Generation:  1,
+		},
+		Status: appsv1.DaemonSetStatus{
+			ObservedGeneration:     1,
+			DesiredNumberScheduled: 3,
+			UpdatedNumberScheduled: 3,
+			NumberAvailable:        2,
+			NumberUnavailable:      1,
+		},
+	}
+	client = fake.NewClientBuilder().WithObjects(ds).Build()
+	res = &bootstrap.BootstrapResult{}
+	g.Expect(bootstrapNetworkCleanup(crd, client, res)).To(Succeed())
+	g.Expect(res.NetworkCleanup).To(Equal("OpenShiftSDN"))
+	g.Expect(res.Events).To(BeEmpty())
+
+	ds.Status.NumberAvailable, ds.Status.NumberUnavailable = 3, 0
+	client = fake.NewClientBuilder().WithObjects(ds).Build()
+	g.Expect(bootstrapNetworkCleanup(crd, client, res)).To(Succeed())
+	g.Expect(res.NetworkCleanup).To(BeEmpty())
+	g.Expect(res.Events).To(Equal([]bootstrap.Event{{
+		Type:    corev1.EventTypeNormal,
+		Reason:  "NetworkCleanupCompleted",
+		Message: "The OpenShiftSDN state was cleaned up on 3 nodes",
+	}}))
+	g.Expect(crd.Annotations).To(Equal(map[string]string{names.NetworkTypeAnnotation: "OVNKubernetes"}))
+
+	// nothing is cleaned up after migrating back
+	crd.Annotations[names.NetworkCleanupAnnotation] = "OVNKubernetes"
+	res = &bootstrap.BootstrapResult{}
+	g.Expect(bootstrapNetworkCleanup(crd, client, res)).To(Succeed())
+	g.Expect(res.NetworkCleanup).To(BeEmpty())
+}
+
+func TestRenderNetworkCleanup(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	conf := &operv1.NetworkSpec{}
+	res := &bootstrap.BootstrapResult{}
+	objs, err := renderNetworkCleanup(conf, res, manifestDir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).To(BeEmpty())
+
+	// the cleanup waits for the migration to be over
+	res.NetworkCleanup = "OpenShiftSDN"
+	conf.Migration = &operv1.NetworkMigration{NetworkType: "OVNKubernetes"}
+	objs, err = renderNetworkCleanup(conf, res, manifestDir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).To(BeEmpty())
+
+	conf.Migration = nil
+	objs, err = renderNetworkCleanup(conf, res, manifestDir)
+	g.Expect(err).NotTo(HaveOccurred())
+	ds := &appsv1.DaemonSet{}
+	g.Expect(convert(findInObjs("apps", "DaemonSet", NetworkCleanupDaemonSetName, NetworkCleanupDaemonSetNamespace, objs), ds)).To(Succeed())
+	g.Expect(ds.Annotations[names.NetworkCleanupAnnotation]).To(Equal("OpenShiftSDN"))
+	g.Expect(ds.Spec.Template.Spec.Containers[0].Command[2]).To(ContainSubstring(`network_type="OpenShiftSDN"`))
+}
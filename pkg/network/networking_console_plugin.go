@@ -0,0 +1,65 @@
+package network
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/openshift/cluster-network-operator/pkg/render"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NETWORKING_CONSOLE_PLUGIN_PORT is the port the networking plugin serves the console on
+const NETWORKING_CONSOLE_PLUGIN_PORT = 9443
+
+// ConsolePluginListGVK is the kind of the lists of the plugins of the console
+var ConsolePluginListGVK = schema.GroupVersionKind{Group: "console.openshift.io", Version: "v1alpha1", Kind: "ConsolePluginList"}
+
+// bootstrapNetworkingConsolePlugin returns whether the networking plugin of the console is requested by an
+// annotation on the operator configuration, and the console of the cluster can load plugins.
+func bootstrapNetworkingConsolePlugin(conf *operv1.Network, kubeClient client.Reader) (bool, error) {
+	if !boolAnnotation(conf, names.NetworkingConsolePluginAnnotation, false) || kubeClient == nil {
+		return false, nil
+	}
+	plugins := &uns.UnstructuredList{}
+	plugins.SetGroupVersionKind(ConsolePluginListGVK)
+	if err := kubeClient.List(context.TODO(), plugins, client.Limit(1)); err != nil {
+		if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) || runtime.IsNotRegisteredError(err) {
+			klog.Warningf("%s is set, but the console of the cluster does not support plugins. Ignoring it",
+				names.NetworkingConsolePluginAnnotation)
+			return false, nil
+		}
+		return false, errors.Wrap(err, "failed to list the ConsolePlugins")
+	}
+	return true, nil
+}
+
+// renderNetworkingConsolePlugin generates the manifests of the networking plugin of the console: the
+// deployment serving its assets, and the ConsolePlugin registering it. The plugin still has to be enabled
+// in the console operator configuration to be loaded.
+func renderNetworkingConsolePlugin(bootstrapResult *bootstrap.BootstrapResult, manifestDir string) ([]*uns.Unstructured, error) {
+	if !bootstrapResult.NetworkingConsolePlugin {
+		return nil, nil
+	}
+
+	data := makeRenderData(bootstrapResult.FeatureGates)
+	data.Data["ReleaseVersion"] = os.Getenv("RELEASE_VERSION")
+	data.Data["NetworkingConsolePluginImage"] = os.Getenv("NETWORKING_CONSOLE_PLUGIN_IMAGE")
+	data.Data["NetworkingConsolePluginPort"] = NETWORKING_CONSOLE_PLUGIN_PORT
+
+	manifests, err := render.RenderDir(filepath.Join(manifestDir, "network/networking-console-plugin"), &data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to render networking-console-plugin manifests")
+	}
+	return manifests, nil
+}
@@ -0,0 +1,76 @@
+package network
+
+import (
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	appsv1 "k8s.io/api/apps/v1"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBootstrapNetworkingConsolePlugin(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// ConsolePlugins are only known as unstructured objects
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	scheme.AddKnownTypeWithName(ConsolePluginListGVK.GroupVersion().WithKind("ConsolePlugin"), &uns.Unstructured{})
+	scheme.AddKnownTypeWithName(ConsolePluginListGVK, &uns.UnstructuredList{})
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	conf := &operv1.Network{}
+	g.Expect(bootstrapNetworkingConsolePlugin(conf, kubeClient)).To(BeFalse())
+	conf.Annotations = map[string]string{names.NetworkingConsolePluginAnnotation: "yes"}
+	g.Expect(bootstrapNetworkingConsolePlugin(conf, kubeClient)).To(BeFalse())
+	conf.Annotations = map[string]string{names.NetworkingConsolePluginAnnotation: "false"}
+	g.Expect(bootstrapNetworkingConsolePlugin(conf, kubeClient)).To(BeFalse())
+	conf.Annotations = map[string]string{names.NetworkingConsolePluginAnnotation: "true"}
+	g.Expect(bootstrapNetworkingConsolePlugin(conf, kubeClient)).To(BeTrue())
+
+	// the console of the cluster does not support plugins
+	noConsoleScheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(noConsoleScheme)).To(Succeed())
+	g.Expect(bootstrapNetworkingConsolePlugin(conf, fake.NewClientBuilder().WithScheme(noConsoleScheme).Build())).To(BeFalse())
+}
+
+func TestRenderNetworkingConsolePlugin(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	defer os.Setenv("NETWORKING_CONSOLE_PLUGIN_IMAGE", os.Getenv("NETWORKING_CONSOLE_PLUGIN_IMAGE"))
+	os.Setenv("NETWORKING_CONSOLE_PLUGIN_IMAGE", "quay.io/openshift/origin-networking-console-plugin:latest")
+
+	objs, err := renderNetworkingConsolePlugin(&bootstrap.BootstrapResult{}, manifestDir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).To(BeEmpty())
+
+	objs, err = renderNetworkingConsolePlugin(&bootstrap.BootstrapResult{NetworkingConsolePlugin: true}, manifestDir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).To(HaveLen(5))
+	g.Expect(objs[0]).To(HaveKubernetesID("Namespace", "", "openshift-network-console"))
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("ConfigMap", "openshift-network-console", "networking-console-plugin")))
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("Service", "openshift-network-console", "networking-console-plugin")))
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("ConsolePlugin", "", "networking-console-plugin")))
+
+	deployment := &appsv1.Deployment{}
+	g.Expect(convert(findInObjs("apps", "Deployment", "networking-console-plugin", "openshift-network-console", objs), deployment)).To(Succeed())
+	g.Expect(deployment.Spec.Template.Spec.Containers).To(HaveLen(1))
+	g.Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("quay.io/openshift/origin-networking-console-plugin:latest"))
+	g.Expect(deployment.Spec.Template.Spec.Containers[0].Ports[0].ContainerPort).To(BeEquivalentTo(NETWORKING_CONSOLE_PLUGIN_PORT))
+
+	// the console reaches the plugin on the serving certificate of its Service
+	plugin := findInObjs("console.openshift.io", "ConsolePlugin", "networking-console-plugin", "", objs)
+	service, _, err := uns.NestedMap(plugin.Object, "spec", "service")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(service).To(HaveKeyWithValue("name", "networking-console-plugin"))
+	g.Expect(service).To(HaveKeyWithValue("namespace", "openshift-network-console"))
+	g.Expect(service).To(HaveKeyWithValue("port", BeEquivalentTo(NETWORKING_CONSOLE_PLUGIN_PORT)))
+	svc := findInObjs("", "Service", "networking-console-plugin", "openshift-network-console", objs)
+	g.Expect(svc.GetAnnotations()).To(HaveKeyWithValue("service.beta.openshift.io/serving-cert-secret-name", "networking-console-plugin-cert"))
+}
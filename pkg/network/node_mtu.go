@@ -0,0 +1,52 @@
+package network
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// minimum MTU of an IPv6 link, lower uplink MTUs are reported by broken nodes
+const minUplinkMTU = 1280
+
+// lowestUplinkMTU returns the lowest valid uplink MTU annotated on the nodes, and the node it is annotated on
+func lowestUplinkMTU(nodes []corev1.Node) (int, string) {
+	lowest, lowestNode := 0, ""
+	for _, node := range nodes {
+		value, ok := node.Annotations[names.UplinkMTUNodeAnnotation]
+		if !ok {
+			continue
+		}
+		mtu, err := strconv.Atoi(value)
+		if err != nil || mtu < minUplinkMTU || mtu > 65536 {
+			klog.Warningf("%s of node %s must be an MTU between %d and 65536, is: %q. Ignoring it",
+				names.UplinkMTUNodeAnnotation, node.Name, minUplinkMTU, value)
+			continue
+		}
+		if lowest == 0 || mtu < lowest || (mtu == lowest && node.Name < lowestNode) {
+			lowest, lowestNode = mtu, node.Name
+		}
+	}
+	return lowest, lowestNode
+}
+
+// bootstrapUplinkMTU fills the lowest uplink MTU reported by the nodes, and the node reporting it, or
+// zero if no node reported it yet, as on the first install. It is only used to warn about a misconfigured
+// MTU, so it does not fail the bootstrap.
+func bootstrapUplinkMTU(res *bootstrap.BootstrapResult, client client.Reader) {
+	if client == nil {
+		return
+	}
+	nodes := &corev1.NodeList{}
+	if err := client.List(context.TODO(), nodes); err != nil {
+		klog.Warningf("Failed to retrieve the uplink MTU of the nodes: %v", err)
+		return
+	}
+	res.UplinkMTU, res.UplinkMTUNode = lowestUplinkMTU(nodes.Items)
+}
@@ -0,0 +1,59 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBootstrapUplinkMTU(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	node := func(name, mtu string) *corev1.Node {
+		n := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{}}}
+		if mtu != "" {
+			n.Annotations[names.UplinkMTUNodeAnnotation] = mtu
+		}
+		return n
+	}
+
+	// nothing reported yet
+	res := &bootstrap.BootstrapResult{}
+	bootstrapUplinkMTU(res, fake.NewClientBuilder().WithObjects(node("a", "")).Build())
+	g.Expect(res.UplinkMTU).To(Equal(0))
+	g.Expect(res.UplinkMTUNode).To(BeEmpty())
+
+	// the bonded node has a lower MTU than the others, the invalid values are ignored
+	bootstrapUplinkMTU(res, fake.NewClientBuilder().WithObjects(
+		node("a", ""),
+		node("b", "9000"),
+		node("c", "1500"),
+		node("d", "1500"),
+		node("e", "576"),
+		node("f", "jumbo"),
+	).Build())
+	g.Expect(res.UplinkMTU).To(Equal(1500))
+	g.Expect(res.UplinkMTUNode).To(Equal("c"))
+}
+
+func TestFillDefaultsMTU(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	conf := &crd.Spec
+	conf.DefaultNetwork.OVNKubernetesConfig = nil
+	FillDefaultsWithMTU(conf, nil, 1500)
+	g.Expect(*conf.DefaultNetwork.OVNKubernetesConfig.MTU).To(Equal(uint32(1400)))
+
+	// the MTU never changes once applied
+	next := OVNKubernetesConfig.DeepCopy().Spec
+	next.DefaultNetwork.OVNKubernetesConfig = nil
+	FillDefaultsWithMTU(&next, conf, 9000)
+	g.Expect(*next.DefaultNetwork.OVNKubernetesConfig.MTU).To(Equal(uint32(1400)))
+}
@@ -0,0 +1,79 @@
+package network
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"k8s.io/klog/v2"
+)
+
+// NODE_ROLLOUT_MAX_UNAVAILABLE is the default maximum of the nodes whose pod of the ovnkube-node
+// and multus DaemonSets is unavailable during a rollout
+const NODE_ROLLOUT_MAX_UNAVAILABLE = "10%"
+
+var nodeRolloutRegexp = regexp.MustCompile(`^([0-9]+)(%?)$`)
+
+// bootstrapNodeRollout returns the rolling update of the ovnkube-node and multus DaemonSets set by
+// annotations on the operator configuration. Invalid values are ignored.
+func bootstrapNodeRollout(conf *operv1.Network) bootstrap.NodeRollout {
+	rollout := bootstrap.NodeRollout{}
+	annotations := conf.GetAnnotations()
+	if v, ok := annotations[names.NodeMaxUnavailableAnnotation]; ok {
+		if err := validateNodeRolloutValue(v); err != nil {
+			klog.Warningf("%s must be a number or a percentage of nodes, is: %q. Ignoring it: %v", names.NodeMaxUnavailableAnnotation, v, err)
+		} else {
+			rollout.MaxUnavailable = v
+		}
+	}
+	if v, ok := annotations[names.NodeMaxSurgeAnnotation]; ok {
+		if err := validateNodeRolloutValue(v); err != nil {
+			klog.Warningf("%s must be a number or a percentage of nodes, is: %q. Ignoring it: %v", names.NodeMaxSurgeAnnotation, v, err)
+		} else {
+			rollout.MaxSurge = v
+		}
+	}
+	// a rollout would never make progress
+	if isZeroNodeRolloutValue(rollout.MaxUnavailable) && (rollout.MaxSurge == "" || isZeroNodeRolloutValue(rollout.MaxSurge)) {
+		klog.Warningf("%s cannot be 0 when %s is 0 or unset. Ignoring them", names.NodeMaxUnavailableAnnotation, names.NodeMaxSurgeAnnotation)
+		return bootstrap.NodeRollout{}
+	}
+	return rollout
+}
+
+// nodeMaxUnavailable returns the maximum of the nodes whose pod is unavailable during a rollout
+func nodeMaxUnavailable(rollout bootstrap.NodeRollout) string {
+	if rollout.MaxUnavailable == "" {
+		return NODE_ROLLOUT_MAX_UNAVAILABLE
+	}
+	return rollout.MaxUnavailable
+}
+
+// validateNodeRolloutValue checks that v is a number of nodes, or a percentage of at most 100%
+func validateNodeRolloutValue(v string) error {
+	m := nodeRolloutRegexp.FindStringSubmatch(v)
+	if m == nil {
+		return fmt.Errorf("invalid value")
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil {
+		return err
+	}
+	if m[2] == "%" && n > 100 {
+		return fmt.Errorf("more than 100%%")
+	}
+	return nil
+}
+
+// isZeroNodeRolloutValue returns whether v, a valid rollout value, is 0 nodes
+func isZeroNodeRolloutValue(v string) bool {
+	m := nodeRolloutRegexp.FindStringSubmatch(v)
+	if m == nil {
+		return false
+	}
+	n, err := strconv.Atoi(m[1])
+	return err == nil && n == 0
+}
@@ -0,0 +1,113 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestBootstrapNodeRollout(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	rollout := func(annotations map[string]string) bootstrap.NodeRollout {
+		conf := OVNKubernetesConfig.DeepCopy()
+		conf.Annotations = annotations
+		return bootstrapNodeRollout(conf)
+	}
+
+	g.Expect(rollout(nil)).To(Equal(bootstrap.NodeRollout{}))
+	g.Expect(rollout(map[string]string{
+		names.NodeMaxUnavailableAnnotation: "33%",
+		names.NodeMaxSurgeAnnotation:       "5",
+	})).To(Equal(bootstrap.NodeRollout{MaxUnavailable: "33%", MaxSurge: "5"}))
+	g.Expect(rollout(map[string]string{
+		names.NodeMaxUnavailableAnnotation: "0",
+		names.NodeMaxSurgeAnnotation:       "100%",
+	})).To(Equal(bootstrap.NodeRollout{MaxUnavailable: "0", MaxSurge: "100%"}))
+
+	// invalid values
+	for _, v := range []string{"", "-1", "101%", "10 %", "ten", "1.5"} {
+		g.Expect(rollout(map[string]string{
+			names.NodeMaxUnavailableAnnotation: v,
+			names.NodeMaxSurgeAnnotation:       v,
+		})).To(Equal(bootstrap.NodeRollout{}), v)
+	}
+
+	// the rollout would never make progress
+	g.Expect(rollout(map[string]string{names.NodeMaxUnavailableAnnotation: "0%"})).To(Equal(bootstrap.NodeRollout{}))
+	g.Expect(rollout(map[string]string{
+		names.NodeMaxUnavailableAnnotation: "00",
+		names.NodeMaxSurgeAnnotation:       "0%",
+	})).To(Equal(bootstrap.NodeRollout{}))
+}
+
+func TestRenderNodeRollout(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	multusConfig := MultusConfig.DeepCopy()
+	FillDefaults(&multusConfig.Spec, nil)
+	ovnConfig := OVNKubernetesConfig.DeepCopy()
+	FillDefaults(&ovnConfig.Spec, nil)
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+	rollingUpdates := func() map[string]*appsv1.RollingUpdateDaemonSet {
+		multusObjs, err := renderMultus(&multusConfig.Spec, bootstrapResult, manifestDir)
+		g.Expect(err).NotTo(HaveOccurred())
+		ovnObjs, err := renderOVNKubernetes(&ovnConfig.Spec, bootstrapResult, manifestDirOvn)
+		g.Expect(err).NotTo(HaveOccurred())
+		updates := map[string]*appsv1.RollingUpdateDaemonSet{}
+		for _, ds := range []struct{ name, namespace string }{
+			{"multus", "openshift-multus"},
+			{"multus-additional-cni-plugins", "openshift-multus"},
+			{"ovnkube-node", "openshift-ovn-kubernetes"},
+		} {
+			obj := findInObjs("apps", "DaemonSet", ds.name, ds.namespace, append(multusObjs, ovnObjs...))
+			g.Expect(obj).NotTo(BeNil(), ds.name)
+			daemonSet := &appsv1.DaemonSet{}
+			g.Expect(convert(obj, daemonSet)).To(Succeed())
+			g.Expect(daemonSet.Spec.UpdateStrategy.Type).To(Equal(appsv1.RollingUpdateDaemonSetStrategyType))
+			updates[ds.name] = daemonSet.Spec.UpdateStrategy.RollingUpdate
+		}
+		return updates
+	}
+	percent := func(v string) *intstr.IntOrString {
+		p := intstr.FromString(v)
+		return &p
+	}
+	number := func(v int) *intstr.IntOrString {
+		n := intstr.FromInt(v)
+		return &n
+	}
+
+	for name, update := range rollingUpdates() {
+		g.Expect(update.MaxUnavailable).To(Equal(percent("10%")), name)
+		g.Expect(update.MaxSurge).To(BeNil(), name)
+	}
+
+	bootstrapResult.NodeRollout = bootstrap.NodeRollout{MaxUnavailable: "25%", MaxSurge: "3"}
+	for name, update := range rollingUpdates() {
+		g.Expect(update.MaxUnavailable).To(Equal(percent("25%")), name)
+		if name == "ovnkube-node" {
+			g.Expect(update.MaxSurge).To(BeNil(), name)
+		} else {
+			g.Expect(update.MaxSurge).To(Equal(number(3)), name)
+		}
+	}
+
+	// ovnkube-node is not surged, its pods are made unavailable
+	bootstrapResult.NodeRollout = bootstrap.NodeRollout{MaxUnavailable: "0", MaxSurge: "20%"}
+	updates := rollingUpdates()
+	g.Expect(updates["multus"].MaxUnavailable).To(Equal(number(0)))
+	g.Expect(updates["multus"].MaxSurge).To(Equal(percent("20%")))
+	g.Expect(updates["ovnkube-node"].MaxUnavailable).To(Equal(percent("10%")))
+	g.Expect(updates["ovnkube-node"].MaxSurge).To(BeNil())
+}
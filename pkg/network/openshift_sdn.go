@@ -34,7 +34,8 @@ func renderOpenShiftSDN(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.Boo
 
 	objs := []*uns.Unstructured{}
 
-	data := render.MakeRenderData()
+	data := makeRenderData(bootstrapResult.FeatureGates)
+	renderTLS(&data, &bootstrapResult.Infra)
 	data.Data["ReleaseVersion"] = os.Getenv("RELEASE_VERSION")
 	data.Data["SDNImage"] = os.Getenv("SDN_IMAGE")
 	data.Data["CNIPluginsImage"] = os.Getenv("CNI_PLUGINS_IMAGE")
@@ -43,7 +44,7 @@ func renderOpenShiftSDN(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.Boo
 	data.Data["KUBERNETES_SERVICE_PORT"] = os.Getenv("KUBERNETES_SERVICE_PORT")
 	data.Data["Mode"] = c.Mode
 	data.Data["CNIConfDir"] = pluginCNIConfDir(conf)
-	data.Data["CNIBinDir"] = CNIBinDir
+	data.Data["CNIBinDir"] = cniBinDir()
 	data.Data["PlatformType"] = bootstrapResult.Infra.PlatformType
 	if bootstrapResult.Infra.PlatformType == configv1.AzurePlatformType {
 		data.Data["SDNPlatformAzure"] = true
@@ -69,6 +70,7 @@ func renderOpenShiftSDN(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.Boo
 		//  2. CNO sets the MTU as applied
 		//  3. User can then set the MTU as configured
 		c.MTU = conf.Migration.MTU.Network.To
+		recordMTUMigrationEvent(conf, bootstrapResult)
 	}
 
 	clusterNetwork, err := clusterNetwork(conf)
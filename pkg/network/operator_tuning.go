@@ -0,0 +1,124 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/openshift/cluster-network-operator/pkg/util/logging"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	OperatorTuningConfigMapName      = "network-operator-config"
+	OperatorTuningConfigMapNamespace = names.APPLIED_NAMESPACE
+)
+
+// The keys of the network-operator-config ConfigMap, which are also the environment variables
+// they override.
+const (
+	tuningMasterDiscoveryTimeout    = "OVN_MASTER_DISCOVERY_TIMEOUT"
+	tuningMasterDiscoveryPoll       = "OVN_MASTER_DISCOVERY_POLL"
+	tuningMasterDiscoveryBackoff    = "OVN_MASTER_DISCOVERY_BACKOFF"
+	tuningNBRaftElectionTimer       = "OVN_NB_RAFT_ELECTION_TIMER"
+	tuningSBRaftElectionTimer       = "OVN_SB_RAFT_ELECTION_TIMER"
+	tuningControllerInactivityProbe = "OVN_CONTROLLER_INACTIVITY_PROBE"
+	tuningNBInactivityProbe         = "OVN_NB_INACTIVITY_PROBE"
+	tuningNorthdProbeInterval       = "OVN_NORTHD_PROBE_INTERVAL"
+	tuningResyncPeriod              = "RESYNC_PERIOD"
+	tuningLogFormat                 = "LOG_FORMAT"
+	tuningLogLevels                 = "LOG_LEVELS"
+)
+
+// DefaultResyncPeriod is the default interval of the periodic reconciliation of the operator configuration
+const DefaultResyncPeriod = 3 * time.Minute
+
+// bootstrapOperatorTuning reads the behavior knobs of the operator. They are read again on every
+// reconciliation, so that changes to the network-operator-config ConfigMap apply without restarting
+// the operator. Invalid values are ignored.
+func bootstrapOperatorTuning(kubeClient client.Reader) (bootstrap.OperatorTuning, error) {
+	data := map[string]string{}
+	if kubeClient != nil {
+		cm := &corev1.ConfigMap{}
+		nsn := types.NamespacedName{Namespace: OperatorTuningConfigMapNamespace, Name: OperatorTuningConfigMapName}
+		if err := kubeClient.Get(context.TODO(), nsn, cm); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return bootstrap.OperatorTuning{}, fmt.Errorf("Failed to retrieve the %s configmap: %w", OperatorTuningConfigMapName, err)
+			}
+		} else {
+			data = cm.Data
+		}
+	}
+	lookup := func(key string) string {
+		if value, ok := data[key]; ok {
+			return value
+		}
+		return os.Getenv(key)
+	}
+	positiveInt := func(key string, defaultValue int) int {
+		value := lookup(key)
+		if value == "" {
+			return defaultValue
+		}
+		i, err := strconv.Atoi(value)
+		if err != nil || i <= 0 {
+			klog.Warningf("%s must be a positive integer, is: %q. Ignoring it", key, value)
+			return defaultValue
+		}
+		return i
+	}
+	nonNegativeInt := func(key string) string {
+		value := lookup(key)
+		if value == "" {
+			return ""
+		}
+		if i, err := strconv.Atoi(value); err != nil || i < 0 {
+			klog.Warningf("%s must be a non-negative integer, is: %q. Ignoring it", key, value)
+			return ""
+		}
+		return value
+	}
+
+	tuning := bootstrap.OperatorTuning{
+		MasterDiscoveryTimeout:    positiveInt(tuningMasterDiscoveryTimeout, OVN_MASTER_DISCOVERY_TIMEOUT),
+		MasterDiscoveryPoll:       positiveInt(tuningMasterDiscoveryPoll, OVN_MASTER_DISCOVERY_POLL),
+		MasterDiscoveryBackoff:    positiveInt(tuningMasterDiscoveryBackoff, OVN_MASTER_DISCOVERY_BACKOFF),
+		NBRaftElectionTimer:       nonNegativeInt(tuningNBRaftElectionTimer),
+		SBRaftElectionTimer:       nonNegativeInt(tuningSBRaftElectionTimer),
+		ControllerInactivityProbe: nonNegativeInt(tuningControllerInactivityProbe),
+		NBInactivityProbe:         nonNegativeInt(tuningNBInactivityProbe),
+		NorthdProbeInterval:       nonNegativeInt(tuningNorthdProbeInterval),
+		ResyncPeriod:              DefaultResyncPeriod,
+	}
+	if value := lookup(tuningResyncPeriod); value != "" {
+		if period, err := time.ParseDuration(value); err != nil || period < 30*time.Second {
+			klog.Warningf("%s must be a duration of at least 30s, is: %q. Ignoring it", tuningResyncPeriod, value)
+		} else {
+			tuning.ResyncPeriod = period
+		}
+	}
+	if value := lookup(tuningLogFormat); value != "" {
+		if value != logging.FormatText && value != logging.FormatJSON {
+			klog.Warningf("%s must be %q or %q, is: %q. Ignoring it", tuningLogFormat, logging.FormatText, logging.FormatJSON, value)
+		} else {
+			tuning.LogFormat = value
+		}
+	}
+	if value := lookup(tuningLogLevels); value != "" {
+		if levels, err := logging.ParseLevels(value); err != nil {
+			klog.Warningf("%s must be <module>=<level>[,<module>=<level>...], is: %q. Ignoring it: %v", tuningLogLevels, value, err)
+		} else {
+			tuning.LogLevels = levels
+		}
+	}
+	return tuning, nil
+}
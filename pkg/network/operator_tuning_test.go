@@ -0,0 +1,75 @@
+package network
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBootstrapOperatorTuning(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	os.Setenv("OVN_NB_RAFT_ELECTION_TIMER", "10")
+	os.Setenv("OVN_CONTROLLER_INACTIVITY_PROBE", "180000")
+	defer os.Unsetenv("OVN_NB_RAFT_ELECTION_TIMER")
+	defer os.Unsetenv("OVN_CONTROLLER_INACTIVITY_PROBE")
+
+	// without the ConfigMap, the environment and the defaults are used
+	tuning, err := bootstrapOperatorTuning(fake.NewClientBuilder().Build())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(tuning).To(Equal(bootstrap.OperatorTuning{
+		MasterDiscoveryTimeout:    OVN_MASTER_DISCOVERY_TIMEOUT,
+		MasterDiscoveryPoll:       OVN_MASTER_DISCOVERY_POLL,
+		MasterDiscoveryBackoff:    OVN_MASTER_DISCOVERY_BACKOFF,
+		NBRaftElectionTimer:       "10",
+		ControllerInactivityProbe: "180000",
+		ResyncPeriod:              DefaultResyncPeriod,
+	}))
+
+	// the ConfigMap overrides the environment, the invalid values are ignored
+	client := fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: OperatorTuningConfigMapName, Namespace: OperatorTuningConfigMapNamespace},
+		Data: map[string]string{
+			"OVN_MASTER_DISCOVERY_TIMEOUT":    "60",
+			"OVN_MASTER_DISCOVERY_POLL":       "0",
+			"OVN_NB_RAFT_ELECTION_TIMER":      "ten",
+			"OVN_CONTROLLER_INACTIVITY_PROBE": "30000",
+			"OVN_NORTHD_PROBE_INTERVAL":       "10000",
+			"RESYNC_PERIOD":                   "10m",
+			"LOG_FORMAT":                      "json",
+			"LOG_LEVELS":                      "render=4,apply=0",
+		},
+	}).Build()
+	tuning, err = bootstrapOperatorTuning(client)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(tuning).To(Equal(bootstrap.OperatorTuning{
+		MasterDiscoveryTimeout:    60,
+		MasterDiscoveryPoll:       OVN_MASTER_DISCOVERY_POLL,
+		MasterDiscoveryBackoff:    OVN_MASTER_DISCOVERY_BACKOFF,
+		ControllerInactivityProbe: "30000",
+		NorthdProbeInterval:       "10000",
+		ResyncPeriod:              10 * time.Minute,
+		LogFormat:                 "json",
+		LogLevels:                 map[string]int{"render": 4, "apply": 0},
+	}))
+
+	// the unknown log formats and modules are ignored
+	client = fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: OperatorTuningConfigMapName, Namespace: OperatorTuningConfigMapNamespace},
+		Data: map[string]string{
+			"LOG_FORMAT": "yaml",
+			"LOG_LEVELS": "render=4,kube-proxy=2",
+		},
+	}).Build()
+	tuning, err = bootstrapOperatorTuning(client)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(tuning.LogFormat).To(BeEmpty())
+	g.Expect(tuning.LogLevels).To(BeNil())
+}
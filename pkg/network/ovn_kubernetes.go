@@ -5,9 +5,10 @@ import (
 	"fmt"
 	"math"
 	"net"
+	"net/url"
 	"os"
-	"path/filepath"
 	"reflect"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -20,13 +21,18 @@ import (
 	"github.com/openshift/cluster-network-operator/pkg/names"
 	"github.com/openshift/cluster-network-operator/pkg/platform"
 	"github.com/openshift/cluster-network-operator/pkg/render"
+	iputil "github.com/openshift/cluster-network-operator/pkg/util/ip"
 	"github.com/openshift/cluster-network-operator/pkg/util/k8s"
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	types "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
 	utilnet "k8s.io/utils/net"
@@ -39,22 +45,64 @@ const OVN_NB_RAFT_PORT = "9643"
 const OVN_SB_RAFT_PORT = "9644"
 const CLUSTER_CONFIG_NAME = "cluster-config-v1"
 const CLUSTER_CONFIG_NAMESPACE = "kube-system"
+const OVN_NODE_PORT_RANGE = "30000-32767"
 const OVN_CERT_CN = "ovn"
 const OVN_MASTER_DISCOVERY_POLL = 5
 const OVN_MASTER_DISCOVERY_BACKOFF = 120
+const OVN_MASTER_DISCOVERY_TIMEOUT = 250
 const OVN_LOCAL_GW_MODE = "local"
 const OVN_SHARED_GW_MODE = "shared"
 const OVN_LOG_PATTERN_CONSOLE = "%D{%Y-%m-%dT%H:%M:%S.###Z}|%05N|%c%T|%p|%m"
 const OVN_NODE_MODE_FULL = "full"
 const OVN_NODE_MODE_DPU_HOST = "dpu-host"
 const OVN_NODE_MODE_DPU = "dpu"
-const OVN_NODE_SELECTOR_DPU = "network.operator.openshift.io/dpu: ''"
-
-var OVN_MASTER_DISCOVERY_TIMEOUT = 250
+const OVN_PREPULLER_MODE_DAEMONSET = "DaemonSet"
+const OVN_PREPULLER_MODE_JOB = "Job"
+const OVN_PREPULLER_MODE_DISABLED = "Disabled"
+
+// OVN_PREPULLER_JOB_DEADLINE is how long the pre-puller job may run. A node whose pod can't be scheduled
+// or can't pull the image must not hold the upgrade of ovnkube-node forever.
+const OVN_PREPULLER_JOB_DEADLINE = 20 * time.Minute
+const OVN_RESOURCE_PROFILE_DEFAULT = "Default"
+const OVN_RESOURCE_PROFILE_SINGLE_NODE = "SingleNode"
+const OVN_NODE_UPGRADE_MODE_DEFAULT = "Default"
+const OVN_NODE_UPGRADE_MODE_CONSERVATIVE = "Conservative"
+const OVN_POLICY_AUDIT_MAX_LOG_FILES = 5
+
+// OVN_POLICY_AUDIT_FORWARDING_TLS_SECRET is the optional Secret, in openshift-ovn-kubernetes, with the CA
+// bundle and the client certificate the ACL audit logs are forwarded with
+const OVN_POLICY_AUDIT_FORWARDING_TLS_SECRET = "ovn-acl-audit-forwarding-tls"
+const OVN_LB_MAX_AFFINITY_TIMEOUT = 86400
+const OVN_CRASH_FORENSICS_MAX_RETENTION = 10
+
+// OVS_DATAPATH_MAX_FLOWS_CEILING is the default flow-limit of OVS, above which it evicts the datapath flows
+const OVS_DATAPATH_MAX_FLOWS_CEILING = 200000
+const OVN_DB_CLIENT_MIN_RECONNECT_BACKOFF = 1000
+const OVN_DB_CLIENT_MAX_RECONNECT_BACKOFF = 300000
+const OVN_DB_CLIENT_MAX_INFLIGHT_TXNS = 1000
+const OVN_EGRESS_FIREWALL_DNS_MIN_REFRESH_INTERVAL = 5
+const OVN_EGRESS_FIREWALL_DNS_MAX_REFRESH_INTERVAL = 86400
+const OVN_OVSDB_MODE_LEGACY = "legacy"
+const OVN_OVSDB_MODE_LIBOVSDB = "libovsdb"
+const OVN_MAX_TXN_BATCH_SIZE = 10000
+const OVN_MAX_LFLOW_CACHE_LIMIT = 10000000
+const OVN_V4_MASQUERADE_SUBNET = "169.254.169.0/29"
+const OVN_V6_MASQUERADE_SUBNET = "fd69::/125"
+const OVN_NODE_LOCAL_DNS_IP = "169.254.20.10"
+const OVN_V4_JOIN_SUBNET = "100.64.0.0/16"
+const OVN_V6_JOIN_SUBNET = "fd98::/64"
+const OVN_CNI_CACHE_DIR = "/var/lib/cni/networks/ovn-k8s-cni-overlay"
+
+// ovnMasterDiscoveryTimeout is the current timeout of the discovery of the masters, shortened each time the
+// discovery times out, and ovnMasterDiscoveryConfiguredTimeout the configured timeout it was shortened from
+var ovnMasterDiscoveryTimeout, ovnMasterDiscoveryConfiguredTimeout = OVN_MASTER_DISCOVERY_TIMEOUT, OVN_MASTER_DISCOVERY_TIMEOUT
 
 const (
+	// OVSFlowsConfigMapName is the name of both the OVSFlowsConfig and the legacy ConfigMap it supersedes
 	OVSFlowsConfigMapName   = "ovs-flows-config"
 	OVSFlowsConfigNamespace = names.APPLIED_NAMESPACE
+	// OVS_FLOWS_TLS_RELAY_PORT is the local port of the relay of the IPFIX records to a collector over TLS
+	OVS_FLOWS_TLS_RELAY_PORT = 29739
 )
 
 // renderOVNKubernetes returns the manifests for the ovn-kubernetes.
@@ -66,10 +114,10 @@ const (
 // and some other small things.
 func renderOVNKubernetes(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult, manifestDir string) ([]*uns.Unstructured, error) {
 
-	// TODO: Fix operator behavior when running in a cluster with an externalized control plane.
-	// For now, return an error since we don't have any master nodes to run the ovn-master daemonset.
-	if bootstrapResult.Infra.ExternalControlPlane {
-		return nil, fmt.Errorf("Unable to render OVN in a cluster with an external control plane")
+	// there are no master nodes to run the ovnkube-master daemonset on in a cluster with an externalized
+	// control plane, the nodes must be attached to an external OVN deployment
+	if bootstrapResult.Infra.ExternalControlPlane && bootstrapResult.OVN.External == nil {
+		return nil, fmt.Errorf("Unable to render OVN in a cluster with an external control plane without an external OVN deployment")
 	}
 
 	c := conf.DefaultNetwork.OVNKubernetesConfig
@@ -77,8 +125,10 @@ func renderOVNKubernetes(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.Bo
 	objs := []*uns.Unstructured{}
 
 	// render the manifests on disk
-	data := render.MakeRenderData()
+	data := makeRenderData(bootstrapResult.FeatureGates)
+	renderTLS(&data, &bootstrapResult.Infra)
 	data.Data["ReleaseVersion"] = os.Getenv("RELEASE_VERSION")
+	data.Data["OVNNodePreviousReleaseVersion"] = ovnNodePreviousReleaseVersion(bootstrapResult.OVN.ExistingNodeDaemonset, os.Getenv("RELEASE_VERSION"))
 	data.Data["OvnImage"] = os.Getenv("OVN_IMAGE")
 	data.Data["KubeRBACProxyImage"] = os.Getenv("KUBE_RBAC_PROXY_IMAGE")
 	data.Data["KUBERNETES_SERVICE_HOST"] = os.Getenv("KUBERNETES_SERVICE_HOST")
@@ -102,47 +152,130 @@ func renderOVNKubernetes(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.Bo
 		//  2. CNO sets the MTU as applied
 		//  3. User can then set the MTU as configured
 		c.MTU = conf.Migration.MTU.Network.To
+		recordMTUMigrationEvent(conf, bootstrapResult)
 	}
 	data.Data["GenevePort"] = c.GenevePort
 	data.Data["CNIConfDir"] = pluginCNIConfDir(conf)
-	data.Data["CNIBinDir"] = CNIBinDir
-	data.Data["OVN_NODE_MODE"] = OVN_NODE_MODE_FULL
+	data.Data["CNIBinDir"] = cniBinDir()
+	data.Data["OVNCNICacheDir"] = cniDirFromEnv("OVN_CNI_CACHE_DIR", OVN_CNI_CACHE_DIR)
 	data.Data["OVN_NB_PORT"] = OVN_NB_PORT
 	data.Data["OVN_SB_PORT"] = OVN_SB_PORT
 	data.Data["OVN_NB_RAFT_PORT"] = OVN_NB_RAFT_PORT
 	data.Data["OVN_SB_RAFT_PORT"] = OVN_SB_RAFT_PORT
-	data.Data["OVN_NB_RAFT_ELECTION_TIMER"] = os.Getenv("OVN_NB_RAFT_ELECTION_TIMER")
-	data.Data["OVN_SB_RAFT_ELECTION_TIMER"] = os.Getenv("OVN_SB_RAFT_ELECTION_TIMER")
-	data.Data["OVN_CONTROLLER_INACTIVITY_PROBE"] = os.Getenv("OVN_CONTROLLER_INACTIVITY_PROBE")
-	controller_inactivity_probe := os.Getenv("OVN_CONTROLLER_INACTIVITY_PROBE")
+	data.Data["OVN_NB_RAFT_ELECTION_TIMER"] = bootstrapResult.Tuning.NBRaftElectionTimer
+	data.Data["OVN_SB_RAFT_ELECTION_TIMER"] = bootstrapResult.Tuning.SBRaftElectionTimer
+	controller_inactivity_probe := bootstrapResult.Tuning.ControllerInactivityProbe
 	if len(controller_inactivity_probe) == 0 {
 		controller_inactivity_probe = "180000"
-		klog.Infof("OVN_CONTROLLER_INACTIVITY_PROBE env var is not defined. Using: %s", controller_inactivity_probe)
+		klog.Infof("OVN_CONTROLLER_INACTIVITY_PROBE is not defined. Using: %s", controller_inactivity_probe)
 	}
 	data.Data["OVN_CONTROLLER_INACTIVITY_PROBE"] = controller_inactivity_probe
-	nb_inactivity_probe := os.Getenv("OVN_NB_INACTIVITY_PROBE")
+	nb_inactivity_probe := bootstrapResult.Tuning.NBInactivityProbe
 	if len(nb_inactivity_probe) == 0 {
 		nb_inactivity_probe = "60000"
-		klog.Infof("OVN_NB_INACTIVITY_PROBE env var is not defined. Using: %s", nb_inactivity_probe)
+		klog.Infof("OVN_NB_INACTIVITY_PROBE is not defined. Using: %s", nb_inactivity_probe)
 	}
 	data.Data["OVN_NB_INACTIVITY_PROBE"] = nb_inactivity_probe
+	// the databases probe their clients at the interval the clients probe them
+	data.Data["OVN_SB_INACTIVITY_PROBE"] = controller_inactivity_probe
 	data.Data["OVN_NB_DB_LIST"] = dbList(bootstrapResult.OVN.MasterIPs, OVN_NB_PORT)
 	data.Data["OVN_SB_DB_LIST"] = dbList(bootstrapResult.OVN.MasterIPs, OVN_SB_PORT)
+	// the nodes reach the databases on the DNS name, when set, so that they do not depend on the master IPs
+	data.Data["OVN_NODE_NB_DB_LIST"] = data.Data["OVN_NB_DB_LIST"]
+	data.Data["OVN_NODE_SB_DB_LIST"] = data.Data["OVN_SB_DB_LIST"]
+	if name := bootstrapResult.OVN.OVNKubernetesConfig.DBEndpointName; name != "" {
+		data.Data["OVN_NODE_NB_DB_LIST"] = dbList([]string{name}, OVN_NB_PORT)
+		data.Data["OVN_NODE_SB_DB_LIST"] = dbList([]string{name}, OVN_SB_PORT)
+	}
+	// the nodes attached to an external OVN deployment reach its databases, and nothing else does
+	data.Data["OVNExternal"] = bootstrapResult.OVN.External != nil
+	if external := bootstrapResult.OVN.External; external != nil {
+		data.Data["OVN_NB_DB_LIST"] = external.NBDBList
+		data.Data["OVN_SB_DB_LIST"] = external.SBDBList
+		data.Data["OVN_NODE_NB_DB_LIST"] = external.NBDBList
+		data.Data["OVN_NODE_SB_DB_LIST"] = external.SBDBList
+		data.Data["OVNExternalCertHash"] = external.CertHash
+		data.Data["OVNExternalCABundle"] = external.CABundle
+	}
 	data.Data["OVN_DB_CLUSTER_INITIATOR"] = bootstrapResult.OVN.ClusterInitiator
+	data.Data["OVN_DB_REMOVED_MEMBERS"] = strings.Join(bootstrapResult.OVN.RemovedMasterIPs, ",")
+	data.Data["OVN_MANAGEMENT_IPS"] = bootstrapResult.OVN.ManagementIPs
+	data.Data["OVN_MASTER_NODE_SELECTOR"] = bootstrapResult.OVN.MasterNodeSelector
+	if len(bootstrapResult.OVN.MasterNodeSelector) == 0 {
+		data.Data["OVN_MASTER_NODE_SELECTOR"] = ovnDefaultMasterNodeSelector()
+	}
+	data.Data["OVN_MASTER_TOLERATIONS"] = bootstrapResult.OVN.MasterTolerations
+	if len(bootstrapResult.OVN.MasterTolerations) == 0 {
+		data.Data["OVN_MASTER_TOLERATIONS"] = ovnDefaultMasterTolerations()
+	}
+	data.Data["OVN_MASTER_TOPOLOGY_KEY"] = bootstrapResult.OVN.MasterTopologyKey
+	data.Data["OVN_MASTER_COUNT"] = len(bootstrapResult.OVN.MasterIPs)
+	data.Data["OVN_MASTER_IP_BLOCKS"] = hostCIDRs(bootstrapResult.OVN.MasterIPs)
 	data.Data["OVN_MIN_AVAILABLE"] = len(bootstrapResult.OVN.MasterIPs)/2 + 1
-	data.Data["LISTEN_DUAL_STACK"] = listenDualStack(bootstrapResult.OVN.MasterIPs[0])
+	data.Data["LISTEN_DUAL_STACK"] = false
+	if len(bootstrapResult.OVN.MasterIPs) > 0 {
+		data.Data["LISTEN_DUAL_STACK"] = listenDualStack(bootstrapResult.OVN.MasterIPs[0])
+	}
 	data.Data["OVN_CERT_CN"] = OVN_CERT_CN
-	data.Data["OVN_NORTHD_PROBE_INTERVAL"] = os.Getenv("OVN_NORTHD_PROBE_INTERVAL")
+	// the nodes attached to an external OVN deployment mount the certificate provided along with it
+	data.Data["OVNCertSecretName"] = "ovn-cert"
+	if bootstrapResult.OVN.External != nil {
+		data.Data["OVNCertSecretName"] = OVNExternalSecretName
+	}
+	data.Data["OVN_NORTHD_PROBE_INTERVAL"] = bootstrapResult.Tuning.NorthdProbeInterval
 	data.Data["NetFlowCollectors"] = ""
 	data.Data["SFlowCollectors"] = ""
 	data.Data["IPFIXCollectors"] = ""
 	data.Data["IPFIXCacheMaxFlows"] = ""
 	data.Data["IPFIXCacheActiveTimeout"] = ""
 	data.Data["IPFIXSampling"] = ""
+	data.Data["IPFIXTLSTarget"] = ""
+	data.Data["IPFIXTLSRelayPort"] = 0
+	data.Data["IPFIXTLSCABundle"] = ""
 	data.Data["OVNPolicyAuditRateLimit"] = c.PolicyAuditConfig.RateLimit
 	data.Data["OVNPolicyAuditMaxFileSize"] = c.PolicyAuditConfig.MaxFileSize
 	data.Data["OVNPolicyAuditDestination"] = c.PolicyAuditConfig.Destination
 	data.Data["OVNPolicyAuditSyslogFacility"] = c.PolicyAuditConfig.SyslogFacility
+	data.Data["OVNPolicyAuditMaxLogFiles"] = OVN_POLICY_AUDIT_MAX_LOG_FILES
+	if bootstrapResult.OVN.OVNKubernetesConfig.PolicyAuditMaxLogFiles > 0 {
+		data.Data["OVNPolicyAuditMaxLogFiles"] = bootstrapResult.OVN.OVNKubernetesConfig.PolicyAuditMaxLogFiles
+	}
+	data.Data["OVNPolicyAuditMaxLogAge"] = bootstrapResult.OVN.OVNKubernetesConfig.PolicyAuditMaxLogAge
+	data.Data["OVNPolicyAuditForwarding"] = bootstrapResult.OVN.OVNKubernetesConfig.PolicyAuditForwarding
+	data.Data["OVNPolicyAuditForwardingTLSSecret"] = OVN_POLICY_AUDIT_FORWARDING_TLS_SECRET
+	data.Data["OVNLBAffinityTimeout"] = bootstrapResult.OVN.OVNKubernetesConfig.LBAffinityTimeout
+	data.Data["OVNLBIdleTimeout"] = bootstrapResult.OVN.OVNKubernetesConfig.LBIdleTimeout
+	data.Data["OVNEmptyLBEvents"] = !bootstrapResult.OVN.OVNKubernetesConfig.DisableEmptyLBEvents
+	data.Data["OVNDBClientReconnectBackoff"] = bootstrapResult.OVN.OVNKubernetesConfig.DBClientReconnectBackoff
+	data.Data["OVNDBClientMaxInflightTxns"] = bootstrapResult.OVN.OVNKubernetesConfig.DBClientMaxInflightTxns
+	data.Data["OVNEgressFirewallDNSRefreshInterval"] = bootstrapResult.OVN.OVNKubernetesConfig.EgressFirewallDNSRefreshInterval
+	data.Data["OVNEgressFirewallDNSResolver"] = bootstrapResult.OVN.OVNKubernetesConfig.EgressFirewallDNSResolver
+	data.Data["OVNOVSDBLibovsdb"] = bootstrapResult.OVN.OVNKubernetesConfig.OVSDBMode == OVN_OVSDB_MODE_LIBOVSDB
+	data.Data["OVNTxnBatchSize"] = bootstrapResult.OVN.OVNKubernetesConfig.TxnBatchSize
+	renderOVNScaleUp(&data, bootstrapResult.OVN.OVNKubernetesConfig.ScaleUpNodes, bootstrapResult.OVN.OVNKubernetesConfig.DBClientMaxInflightTxns)
+	data.Data["OVNLflowCacheLimit"] = bootstrapResult.OVN.OVNKubernetesConfig.LflowCacheLimit
+	data.Data["OVNDBMaintenanceSchedule"] = bootstrapResult.OVN.OVNKubernetesConfig.DBMaintenanceSchedule
+	data.Data["OVNDBMaintenanceSnapshot"] = bootstrapResult.OVN.OVNKubernetesConfig.DBMaintenanceSnapshot
+	data.Data["OVNCrashForensicsRetention"] = bootstrapResult.OVN.OVNKubernetesConfig.CrashForensicsRetention
+	data.Data["OVSDatapathFlowsCeiling"] = bootstrapResult.OVN.OVNKubernetesConfig.DatapathFlowsCeiling
+	data.Data["OVNDebugDumpRequest"] = bootstrapResult.OVN.OVNKubernetesConfig.DebugDumpRequest
+	data.Data["OVNForceUnsafeChangeRequest"] = bootstrapResult.OVN.OVNKubernetesConfig.ForceUnsafeChangeRequest
+	data.Data["OVNMinimalRBAC"] = bootstrapResult.OVN.OVNKubernetesConfig.MinimalRBAC
+	data.Data["OVNMultiExternalGateway"] = bootstrapResult.OVN.OVNKubernetesConfig.MultiExternalGateway
+	data.Data["OVNExternalGatewayBFD"] = bootstrapResult.OVN.OVNKubernetesConfig.ExternalGatewayBFD
+	data.Data["OVNGatewayNextHops"] = bootstrapResult.OVN.OVNKubernetesConfig.GatewayNextHops
+	data.Data["OVNEgressSNATPools"] = bootstrapResult.OVN.OVNKubernetesConfig.EgressSNATPools
+	data.Data["IPsecExternalGateways"] = bootstrapResult.OVN.OVNKubernetesConfig.IPsecExternalGateways
+	data.Data["IPsecIKE"] = bootstrapResult.OVN.OVNKubernetesConfig.IPsecIKE
+	data.Data["IPsecESP"] = bootstrapResult.OVN.OVNKubernetesConfig.IPsecESP
+	data.Data["IPsecIKELifetime"] = bootstrapResult.OVN.OVNKubernetesConfig.IPsecIKELifetime
+	data.Data["IPsecSALifetime"] = bootstrapResult.OVN.OVNKubernetesConfig.IPsecSALifetime
+	data.Data["IPsecForceEncapsulation"] = bootstrapResult.OVN.OVNKubernetesConfig.IPsecForceEncapsulation
+	data.Data["OVNProbeProfiles"] = bootstrapResult.OVN.OVNKubernetesConfig.ProbeProfiles
+	data.Data["OVNRouteAdvertisements"] = bootstrapResult.OVN.OVNKubernetesConfig.RouteAdvertisements
+	data.Data["FRRImage"] = os.Getenv("FRR_IMAGE")
+	data.Data["OVNMultitenantNetIDs"] = bootstrapResult.OVN.OVNKubernetesConfig.MultitenantNetIDs
+	data.Data["OVNIsolatedNamespaces"] = bootstrapResult.OVN.OVNKubernetesConfig.IsolatedNamespaces
 	data.Data["OVN_LOG_PATTERN_CONSOLE"] = OVN_LOG_PATTERN_CONSOLE
 	data.Data["PlatformType"] = bootstrapResult.Infra.PlatformType
 	if bootstrapResult.Infra.PlatformType == configv1.AzurePlatformType {
@@ -151,23 +284,16 @@ func renderOVNKubernetes(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.Bo
 		data.Data["OVNPlatformAzure"] = false
 	}
 
-	var ippools string
-	for _, net := range conf.ClusterNetwork {
-		if len(ippools) != 0 {
-			ippools += ","
-		}
-		ippools += fmt.Sprintf("%s/%d", net.CIDR, net.HostPrefix)
-	}
-	data.Data["OVN_cidr"] = ippools
+	data.Data["OVN_cidr"] = ovnClusterSubnets(conf, bootstrapResult.OVN.OVNKubernetesConfig.ReservedNodeSubnets)
 
 	data.Data["OVN_service_cidr"] = strings.Join(conf.ServiceNetwork, ",")
 
 	if c.HybridOverlayConfig != nil {
-		if len(c.HybridOverlayConfig.HybridClusterNetwork) > 0 {
-			data.Data["OVNHybridOverlayNetCIDR"] = c.HybridOverlayConfig.HybridClusterNetwork[0].CIDR
-		} else {
-			data.Data["OVNHybridOverlayNetCIDR"] = ""
+		hybridCIDRs := []string{}
+		for _, hcn := range c.HybridOverlayConfig.HybridClusterNetwork {
+			hybridCIDRs = append(hybridCIDRs, hcn.CIDR)
 		}
+		data.Data["OVNHybridOverlayNetCIDR"] = strings.Join(hybridCIDRs, ",")
 		if c.HybridOverlayConfig.HybridOverlayVXLANPort != nil {
 			data.Data["OVNHybridOverlayVXLANPort"] = c.HybridOverlayConfig.HybridOverlayVXLANPort
 		} else {
@@ -191,6 +317,15 @@ func renderOVNKubernetes(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.Bo
 	} else {
 		data.Data["OVN_GATEWAY_MODE"] = OVN_SHARED_GW_MODE
 	}
+	// in shared gateway mode, the health checks of the cloud load balancers are let through to the
+	// health check node ports served by ovnkube-node
+	data.Data["OVNLBHealthCheckSources"] = strings.Join(bootstrapResult.Infra.LoadBalancerHealthCheckSources, " ")
+	data.Data["OVNNodePortRange"] = strings.Replace(bootstrapResult.OVN.OVNKubernetesConfig.NodePortRange, "-", ":", 1)
+	data.Data["OVNV4JoinSubnet"] = bootstrapResult.OVN.OVNKubernetesConfig.V4JoinSubnet
+	data.Data["OVNV6JoinSubnet"] = bootstrapResult.OVN.OVNKubernetesConfig.V6JoinSubnet
+	data.Data["OVNV4MasqueradeSubnet"] = bootstrapResult.OVN.OVNKubernetesConfig.V4MasqueradeSubnet
+	data.Data["OVNV6MasqueradeSubnet"] = bootstrapResult.OVN.OVNKubernetesConfig.V6MasqueradeSubnet
+	data.Data["OVNNodeLocalDNSIP"] = bootstrapResult.OVN.OVNKubernetesConfig.NodeLocalDNSIP
 
 	exportNetworkFlows := conf.ExportNetworkFlows
 	if exportNetworkFlows != nil {
@@ -223,44 +358,60 @@ func renderOVNKubernetes(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.Bo
 		data.Data["IsSNO"] = false
 	}
 
-	manifests, err := render.RenderDir(filepath.Join(manifestDir, "network/ovn-kubernetes"), &data)
+	// the single-node profile runs standalone databases, with lower requests and less frequent probes
+	data.Data["OVNSingleNodeProfile"] = false
+	data.Data["OVNMemoryRequest"] = "300Mi"
+	data.Data["OVNNodeReadinessPeriod"] = 5
+	if ovnSingleNodeProfile(bootstrapResult) {
+		data.Data["OVNSingleNodeProfile"] = true
+		data.Data["OVNMemoryRequest"] = "100Mi"
+		data.Data["OVNNodeReadinessPeriod"] = 30
+	} else if bootstrapResult.OVN.OVNKubernetesConfig.ResourceProfile == OVN_RESOURCE_PROFILE_SINGLE_NODE {
+		bootstrapResult.RecordEvent(corev1.EventTypeWarning, "ResourceProfileIgnored",
+			"The %s resource profile requires a single master, found %d. Using the default profile",
+			OVN_RESOURCE_PROFILE_SINGLE_NODE, len(bootstrapResult.OVN.MasterIPs))
+	}
+
+	prePullerMode := bootstrapResult.OVN.OVNKubernetesConfig.PrePullerMode
+	if prePullerMode == "" {
+		prePullerMode = OVN_PREPULLER_MODE_DAEMONSET
+	}
+	if ovnComponentForceDisabled(bootstrapResult, "prepuller") || ovnSingleNodeInfrastructure(bootstrapResult) {
+		prePullerMode = OVN_PREPULLER_MODE_DISABLED
+	}
+	data.Data["OVNPrePullerMode"] = prePullerMode
+	// in conservative mode, ovnkube-node pods are only replaced by the ovn-node-upgrade controller,
+	// once it has drained their node
+	data.Data["OVNNodeUpgradeConservative"] = bootstrapResult.OVN.OVNKubernetesConfig.NodeUpgradeMode == OVN_NODE_UPGRADE_MODE_CONSERVATIVE
+	// ovnkube-node binds host ports, its pods cannot be surged and must be made unavailable
+	nodeRolloutMaxUnavailable := nodeMaxUnavailable(bootstrapResult.NodeRollout)
+	if isZeroNodeRolloutValue(nodeRolloutMaxUnavailable) {
+		nodeRolloutMaxUnavailable = NODE_ROLLOUT_MAX_UNAVAILABLE
+	}
+	data.Data["NodeMaxUnavailable"] = nodeRolloutMaxUnavailable
+	// the pre-puller job runs one completion per node currently targeted by ovnkube-node
+	var prePullerJobCompletions int32 = 1
+	if bootstrapResult.OVN.ExistingNodeDaemonset != nil && bootstrapResult.OVN.ExistingNodeDaemonset.Status.DesiredNumberScheduled > 0 {
+		prePullerJobCompletions = bootstrapResult.OVN.ExistingNodeDaemonset.Status.DesiredNumberScheduled
+	}
+	data.Data["OVNPrePullerJobCompletions"] = prePullerJobCompletions
+	data.Data["OVNPrePullerJobDeadlineSeconds"] = int64(OVN_PREPULLER_JOB_DEADLINE.Seconds())
+
+	if err := fillOVNNodePoolsData(bootstrapResult, &data); err != nil {
+		return nil, err
+	}
+	manifests, err := renderOVNComponents(conf, bootstrapResult, manifestDir, &data)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to render manifests")
+		return nil, err
 	}
 	objs = append(objs, manifests...)
 
-	nodeMode := bootstrapResult.OVN.OVNKubernetesConfig.NodeMode
-	if nodeMode == OVN_NODE_MODE_DPU_HOST {
-		data.Data["OVN_NODE_MODE"] = nodeMode
-		manifests, err = render.RenderTemplate(filepath.Join(manifestDir, "network/ovn-kubernetes/ovnkube-node.yaml"), &data)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to render manifests")
-		}
-		objs = append(objs, manifests...)
-	} else if nodeMode == OVN_NODE_MODE_DPU {
-		// "OVN_NODE_MODE" not set when render.RenderDir() called above,
-		// so render just the error-cni.yaml with "OVN_NODE_MODE" set.
-		data.Data["OVN_NODE_MODE"] = nodeMode
-		manifests, err = render.RenderTemplate(filepath.Join(manifestDir, "network/ovn-kubernetes/error-cni.yaml"), &data)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to render manifests")
-		}
-		objs = append(objs, manifests...)
-
-		// Run KubeProxy on DPU
-		// DPU_DEV_PREVIEW
-		// Node Mode is currently configured via a stand-alone configMap and stored
-		// in bootstrapResult. Once out of DevPreview, CNO API will be expanded to
-		// include Node Mode and it will be stored in conf (operv1.NetworkSpec) and
-		// defaultDeployKubeProxy() will have access and this can be removed.
-		if conf.DeployKubeProxy == nil {
-			v := true
-			conf.DeployKubeProxy = &v
-		} else {
-			*conf.DeployKubeProxy = true
-		}
-		fillKubeProxyDefaults(conf, nil)
+	manifests, err = renderOVNNodePools(bootstrapResult, manifestDir, &data)
+	if err != nil {
+		return nil, err
 	}
+	objs = append(objs, manifests...)
+	recordDPUModeConfigEvent(bootstrapResult)
 
 	// obtain the current IP family mode.
 	ipFamilyMode := names.IPFamilySingleStack
@@ -269,21 +420,147 @@ func renderOVNKubernetes(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.Bo
 	}
 	// check if the IP family mode has changed and control the conversion process.
 	updateNode, updateMaster := shouldUpdateOVNKonIPFamilyChange(bootstrapResult.OVN.ExistingNodeDaemonset, bootstrapResult.OVN.ExistingMasterDaemonset, ipFamilyMode)
+	if !updateNode {
+		bootstrapResult.RecordEvent(corev1.EventTypeNormal, "IPFamilyRolloutDeferred",
+			"IP family mode changed to %s, deferring the ovnkube-node rollout until ovnkube-master is rolled out", ipFamilyMode)
+	}
+	// the changes to the rendered objects decided by the rollout gates below, applied once all are known
+	var mutations k8s.Mutations
 	// annotate the daemonset and the daemonset template with the current IP family mode,
 	// this triggers a daemonset restart if there are changes.
-	err = setOVNDaemonsetAnnotation(objs, names.NetworkIPFamilyModeAnnotation, ipFamilyMode)
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to set IP family %s annotation on daemonsets", ipFamilyMode)
-	}
+	mutations.Add(ovnDaemonsetAnnotations(names.NetworkIPFamilyModeAnnotation, ipFamilyMode)...)
 
 	// don't process upgrades if we are handling a dual-stack conversion.
 	if updateMaster && updateNode {
-		updateNode, updateMaster = shouldUpdateOVNKonUpgrade(bootstrapResult.OVN.ExistingNodeDaemonset, bootstrapResult.OVN.ExistingMasterDaemonset, os.Getenv("RELEASE_VERSION"))
+		hold := bootstrapResult.OVN.OVNKubernetesConfig.UpgradeHold
+		if bootstrapResult.OVN.External != nil {
+			// there is no ovnkube-master to order the upgrade of the nodes with
+			updateNode = shouldUpdateOVNKNodeOnUpgrade(bootstrapResult.OVN.ExistingNodeDaemonset, os.Getenv("RELEASE_VERSION"), hold)
+		} else {
+			updateNode, updateMaster = shouldUpdateOVNKonUpgrade(bootstrapResult.OVN.ExistingNodeDaemonset, bootstrapResult.OVN.ExistingMasterDaemonset, os.Getenv("RELEASE_VERSION"), hold)
+		}
+		if hold && !updateNode {
+			bootstrapResult.RecordEvent(corev1.EventTypeNormal, "UpgradeHeld",
+				"The rollout of OVN-Kubernetes release %s is on hold until the %s annotation is removed",
+				os.Getenv("RELEASE_VERSION"), names.OVNUpgradeHoldAnnotation)
+		}
+	}
+
+	// on upgrades, the master is only updated once the nodes checked the pod network of the new release
+	if updateMaster && ovnkNodeUpgradedBeforeMaster(bootstrapResult.OVN.ExistingNodeDaemonset, bootstrapResult.OVN.ExistingMasterDaemonset, os.Getenv("RELEASE_VERSION")) {
+		updateMaster = shouldUpdateOVNKonPodNetworkCheck(bootstrapResult.OVN.PodNetworkChecks, os.Getenv("RELEASE_VERSION"), bootstrapResult)
+	}
+
+	// on upgrades, the master is only updated once the databases are known to convert to the new schemas
+	renderDBSchemaCheck := false
+	if bootstrapResult.OVN.External == nil {
+		updateMasterOnCheck, renderCheck, failed := shouldUpdateOVNKonDBSchemaCheck(bootstrapResult.OVN.ExistingMasterDaemonset, bootstrapResult.OVN.DBSchemaCheckJob, os.Getenv("RELEASE_VERSION"))
+		renderDBSchemaCheck = renderCheck
+		if failed {
+			reason := bootstrapResult.OVN.DBSchemaCheckFailure
+			if reason == "" {
+				reason = "see the logs of job " + OVN_DB_SCHEMA_CHECK_JOB
+			}
+			bootstrapResult.RecordEvent(corev1.EventTypeWarning, "OVNDBSchemaIncompatible",
+				"The OVN databases cannot be upgraded to release %s, halting the ovnkube-master rollout until job %s is deleted: %s",
+				os.Getenv("RELEASE_VERSION"), OVN_DB_SCHEMA_CHECK_JOB, reason)
+		}
+		updateMaster = updateMaster && updateMasterOnCheck
 	}
 
+	// when masters left the cluster, they must be removed from the raft clusters before the remaining
+	// masters are rolled out, otherwise the databases could lose quorum during the rollout
+	if updateMaster && bootstrapResult.OVN.ExistingMasterDaemonset != nil && len(bootstrapResult.OVN.RemovedMasterIPs) > 0 {
+		updateMaster = shouldUpdateOVNKonDBScaleDown(bootstrapResult.OVN.DBScaleDownJob, bootstrapResult.OVN.RemovedMasterIPs)
+		if updateMaster {
+			bootstrapResult.RecordEvent(corev1.EventTypeWarning, "OVNDBScaleDownFailed",
+				"Failed to remove masters %v from the OVN databases, rolling out ovnkube-master anyway", bootstrapResult.OVN.RemovedMasterIPs)
+		} else {
+			bootstrapResult.RecordEvent(corev1.EventTypeNormal, "OVNDBScaleDownPending",
+				"Deferring the ovnkube-master rollout until masters %v are removed from the OVN databases", bootstrapResult.OVN.RemovedMasterIPs)
+		}
+		if !updateMaster && bootstrapResult.OVN.DBScaleDownJob != nil &&
+			bootstrapResult.OVN.DBScaleDownJob.GetAnnotations()[names.OVNDBRemovedMembersAnnotation] != data.Data["OVN_DB_REMOVED_MEMBERS"] {
+			// the job is for another set of masters, remove it so it is recreated
+			mutations.Add(k8s.Remove(k8s.ObjRef{Group: "batch", Kind: "Job", Namespace: "openshift-ovn-kubernetes", Name: "ovnkube-db-scale-down"}))
+		}
+	}
+
+	recordOVNDBEndpointsEvent(bootstrapResult)
+
 	renderPrePull := false
 	if updateNode {
-		updateNode, renderPrePull = shouldUpdateOVNKonPrepull(bootstrapResult.OVN.ExistingNodeDaemonset, bootstrapResult.OVN.PrePullerDaemonset, os.Getenv("RELEASE_VERSION"))
+		if prePullerMode == OVN_PREPULLER_MODE_JOB {
+			updateNode, renderPrePull = shouldUpdateOVNKonPrepullJob(bootstrapResult.OVN.ExistingNodeDaemonset, bootstrapResult.OVN.PrePullerJob, os.Getenv("RELEASE_VERSION"))
+		} else {
+			updateNode, renderPrePull = shouldUpdateOVNKonPrepull(bootstrapResult.OVN.ExistingNodeDaemonset, bootstrapResult.OVN.PrePullerDaemonset, prePullerMode, os.Getenv("RELEASE_VERSION"))
+		}
+	}
+	if renderPrePull && prePullerVersion(bootstrapResult, prePullerMode) != os.Getenv("RELEASE_VERSION") {
+		bootstrapResult.RecordEvent(corev1.EventTypeNormal, "PrePullerStarted",
+			"Pre-pulling the ovn-kubernetes image of release %s on every node before updating ovnkube-node", os.Getenv("RELEASE_VERSION"))
+	}
+
+	// during the control-plane-only windows, the nodes keep running the current ovnkube-node
+	if updateNode && bootstrapResult.OVN.OVNKubernetesConfig.ControlPlaneOnly && bootstrapResult.OVN.ExistingNodeDaemonset != nil {
+		klog.Infof("In a control-plane-only window, deferring the ovnkube-node rollout")
+		updateNode = false
+		if bootstrapResult.OVN.ExistingNodeDaemonset.GetAnnotations()["release.openshift.io/version"] != os.Getenv("RELEASE_VERSION") {
+			bootstrapResult.RecordEvent(corev1.EventTypeNormal, "NodeRolloutDeferred",
+				"Deferring the ovnkube-node rollout to release %s until the control-plane-only window set by %s ends",
+				os.Getenv("RELEASE_VERSION"), names.OVNControlPlaneOnlyWindowsAnnotation)
+		}
+	}
+
+	// run the hooks registered by the administrator around the node rollouts
+	if bootstrapResult.OVN.PreNodeRolloutHook != nil && updateNode {
+		renderHook, failed := false, false
+		updateNode, renderHook, failed = shouldUpdateOVNKonPreRolloutHook(bootstrapResult.OVN.ExistingNodeDaemonset, bootstrapResult.OVN.PreNodeRolloutJob, os.Getenv("RELEASE_VERSION"))
+		if !updateNode {
+			// keep the finished pre-puller, so that it does not run again
+			renderPrePull = true
+		}
+		if failed {
+			bootstrapResult.RecordEvent(corev1.EventTypeWarning, "NodeRolloutHookFailed",
+				"The %s hook failed, pausing the ovnkube-node rollout to release %s until job %s is deleted",
+				OVN_PRE_NODE_ROLLOUT_HOOK, os.Getenv("RELEASE_VERSION"), ovnRolloutHookJobName(OVN_PRE_NODE_ROLLOUT_HOOK))
+		}
+		if renderHook {
+			job, err := renderOVNRolloutHookJob(OVN_PRE_NODE_ROLLOUT_HOOK, bootstrapResult.OVN.PreNodeRolloutHook, os.Getenv("RELEASE_VERSION"))
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to render the %s hook", OVN_PRE_NODE_ROLLOUT_HOOK)
+			}
+			objs = append(objs, job)
+		}
+	}
+	if bootstrapResult.OVN.PostNodeRolloutHook != nil {
+		renderHook, succeeded, failed := shouldRenderOVNKPostRolloutHook(bootstrapResult.OVN.ExistingNodeDaemonset, bootstrapResult.OVN.PostNodeRolloutJob, os.Getenv("RELEASE_VERSION"))
+		if failed {
+			bootstrapResult.RecordEvent(corev1.EventTypeWarning, "NodeRolloutHookFailed",
+				"The %s hook failed, pausing the ovnkube-master rollout to release %s until job %s is deleted",
+				OVN_POST_NODE_ROLLOUT_HOOK, os.Getenv("RELEASE_VERSION"), ovnRolloutHookJobName(OVN_POST_NODE_ROLLOUT_HOOK))
+		}
+		if renderHook {
+			job, err := renderOVNRolloutHookJob(OVN_POST_NODE_ROLLOUT_HOOK, bootstrapResult.OVN.PostNodeRolloutHook, os.Getenv("RELEASE_VERSION"))
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to render the %s hook", OVN_POST_NODE_ROLLOUT_HOOK)
+			}
+			objs = append(objs, job)
+		}
+		// on upgrades, the master is updated after the node, once the post-node-rollout hook has succeeded
+		if updateMaster && !succeeded && ovnkNodeUpgradedBeforeMaster(bootstrapResult.OVN.ExistingNodeDaemonset, bootstrapResult.OVN.ExistingMasterDaemonset, os.Getenv("RELEASE_VERSION")) {
+			klog.Infof("Waiting for the %s hook to succeed before updating master", OVN_POST_NODE_ROLLOUT_HOOK)
+			updateMaster = false
+		}
+	}
+
+	// the existing daemonsets can't be carried over once critical objects were removed, roll out
+	// everything so that they are recreated consistently
+	if missing := bootstrapResult.OVN.MissingObjects; len(missing) > 0 && (!updateMaster || !updateNode) {
+		bootstrapResult.RecordEvent(corev1.EventTypeWarning, "OVNObjectsMissing",
+			"%s removed from the cluster, rolling out ovnkube-master and ovnkube-node regardless of the ongoing rollout",
+			strings.Join(missing, ", "))
+		updateMaster, updateNode = true, true
 	}
 
 	// If we need to delay master or node daemonset rollout, then we'll replace the new one with the existing one
@@ -292,22 +569,28 @@ func renderOVNKubernetes(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.Bo
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to transmute existing master daemonset")
 		}
-		objs = k8s.ReplaceObj(objs, us)
+		mutations.Add(k8s.Replace(us))
 	}
 	if !updateNode {
 		us, err := k8s.ToUnstructured(bootstrapResult.OVN.ExistingNodeDaemonset)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to transmute existing node daemonset")
 		}
-		objs = k8s.ReplaceObj(objs, us)
+		mutations.Add(k8s.Replace(us))
 	}
 
-	if !renderPrePull {
+	if !renderPrePull || prePullerMode != OVN_PREPULLER_MODE_DAEMONSET {
 		// remove prepull from the list of objects to render.
-		objs = k8s.RemoveObjByGroupKindName(objs, "apps", "DaemonSet", "openshift-ovn-kubernetes", "ovnkube-upgrades-prepuller")
+		mutations.Add(k8s.Remove(k8s.ObjRef{Group: "apps", Kind: "DaemonSet", Namespace: "openshift-ovn-kubernetes", Name: "ovnkube-upgrades-prepuller"}))
+	}
+	if !renderPrePull && prePullerMode == OVN_PREPULLER_MODE_JOB {
+		mutations.Add(k8s.Remove(k8s.ObjRef{Group: "batch", Kind: "Job", Namespace: "openshift-ovn-kubernetes", Name: "ovnkube-upgrades-prepuller"}))
+	}
+	if !renderDBSchemaCheck {
+		mutations.Add(k8s.Remove(k8s.ObjRef{Group: "batch", Kind: "Job", Namespace: "openshift-ovn-kubernetes", Name: OVN_DB_SCHEMA_CHECK_JOB}))
 	}
 
-	return objs, nil
+	return mutations.Apply(objs)
 }
 
 // renderOVNFlowsConfig renders the bootstrapped information from the ovs-flows-config ConfigMap
@@ -320,12 +603,21 @@ func renderOVNFlowsConfig(bootstrapResult *bootstrap.BootstrapResult, data *rend
 		klog.Warningf("ovs-flows-config configmap 'target' field can't be empty. Ignoring configuration: %+v", flows)
 		return
 	}
+	target := flows.Target
+	// OVS only exports IPFIX over UDP, the records are sent to a relay on the node which forwards them
+	// to the collector over TLS
+	if flows.TLS {
+		target = fmt.Sprintf("127.0.0.1:%d", OVS_FLOWS_TLS_RELAY_PORT)
+		data.Data["IPFIXTLSTarget"] = flows.Target
+		data.Data["IPFIXTLSRelayPort"] = OVS_FLOWS_TLS_RELAY_PORT
+		data.Data["IPFIXTLSCABundle"] = flows.CABundle
+	}
 	// if IPFIX collectors are provided by means of both the operator configuration and the
 	// ovs-flows-config ConfigMap, we will merge both targets
 	if colls, ok := data.Data["IPFIXCollectors"].(string); !ok || colls == "" {
-		data.Data["IPFIXCollectors"] = flows.Target
+		data.Data["IPFIXCollectors"] = target
 	} else {
-		data.Data["IPFIXCollectors"] = colls + "," + flows.Target
+		data.Data["IPFIXCollectors"] = colls + "," + target
 	}
 	if flows.CacheMaxFlows != nil {
 		data.Data["IPFIXCacheMaxFlows"] = *flows.CacheMaxFlows
@@ -338,34 +630,80 @@ func renderOVNFlowsConfig(bootstrapResult *bootstrap.BootstrapResult, data *rend
 	}
 }
 
-// bootstrapOVNConfig returns the value of mode found in the openshift-ovn-kubernetes/dpu-mode-config configMap
-// if it exists, otherwise returns default configuration for OCP clusters using OVN-Kubernetes
+// bootstrapOVNConfig returns the configuration of OVN-Kubernetes set by the annotations of the operator
+// configuration and the custom resources of the operator, such as the pools of nodes of the OVNNodePools
 func bootstrapOVNConfig(conf *operv1.Network, kubeClient client.Client) (*bootstrap.OVNConfigBoostrapResult, error) {
 	ovnConfigResult := &bootstrap.OVNConfigBoostrapResult{
-		NodeMode: OVN_NODE_MODE_FULL,
-	}
+		PrePullerMode:      bootstrapOVNPrePullerMode(conf),
+		DisabledComponents: bootstrapOVNDisabledComponents(conf),
+		ResourceProfile:    bootstrapOVNResourceProfile(conf),
+		NodeUpgradeMode:    bootstrapOVNNodeUpgradeMode(conf),
+	}
+	ovnConfigResult.PolicyAuditMaxLogFiles, ovnConfigResult.PolicyAuditMaxLogAge = bootstrapOVNPolicyAuditRetention(conf)
+	ovnConfigResult.PolicyAuditForwarding = bootstrapOVNPolicyAuditForwarding(conf, kubeClient)
+	ovnConfigResult.LBAffinityTimeout, ovnConfigResult.LBIdleTimeout = bootstrapOVNLoadBalancerTimeouts(conf)
+	ovnConfigResult.DisableEmptyLBEvents = bootstrapOVNDisableEmptyLBEvents(conf)
+	ovnConfigResult.DBClientReconnectBackoff, ovnConfigResult.DBClientMaxInflightTxns = bootstrapOVNDBClient(conf)
+	ovnConfigResult.EgressFirewallDNSRefreshInterval, ovnConfigResult.EgressFirewallDNSResolver = bootstrapOVNEgressFirewallDNS(conf)
+	ovnConfigResult.OVSDBMode, ovnConfigResult.TxnBatchSize, ovnConfigResult.LflowCacheLimit = bootstrapOVNPerformance(conf)
+	ovnConfigResult.DBMaintenanceSchedule, ovnConfigResult.DBMaintenanceSnapshot = bootstrapOVNDBMaintenance(conf)
+	ovnConfigResult.CrashForensicsRetention = bootstrapOVNCrashForensics(conf)
+	ovnConfigResult.DatapathFlowsCeiling = bootstrapOVSDatapathMetrics(conf)
+	ovnConfigResult.NamespaceHardening = bootstrapOVNNamespaceHardening(conf)
+	ovnConfigResult.UpgradeHold = bootstrapOVNUpgradeHold(conf)
+	ovnConfigResult.ControlPlaneOnly = bootstrapOVNControlPlaneOnly(conf, time.Now())
+	ovnConfigResult.MinimalRBAC = bootstrapOVNMinimalRBAC(conf)
+	ovnConfigResult.DBEndpointName = bootstrapOVNDBEndpointName(conf)
+	ovnConfigResult.NodePortRange = bootstrapOVNNodePortRange(kubeClient)
+	ovnConfigResult.Debug, ovnConfigResult.DebugDumpRequest = bootstrapOVNDebug(conf)
+	ovnConfigResult.Tools = bootstrapOVNTools(conf)
+	ovnConfigResult.MultiExternalGateway, ovnConfigResult.ExternalGatewayBFD = bootstrapOVNExternalGateways(conf)
 	if conf.Spec.DefaultNetwork.OVNKubernetesConfig.GatewayConfig == nil {
 		bootstrapOVNGatewayConfig(conf, kubeClient)
 	}
-	cm := &corev1.ConfigMap{}
-	dmc := types.NamespacedName{Namespace: "openshift-network-operator", Name: "dpu-mode-config"}
-	err := kubeClient.Get(context.TODO(), dmc, cm)
-
+	gatewayNextHops, err := bootstrapOVNGatewayNextHops(kubeClient)
 	if err != nil {
-		if apierrors.IsNotFound(err) {
-			klog.Infof("Did not find dpu-mode-config")
-		} else {
-			return nil, fmt.Errorf("Could not determine Node Mode: %w", err)
-		}
-	} else {
-		nodeModeOverride := cm.Data["mode"]
-		if nodeModeOverride != OVN_NODE_MODE_DPU_HOST && nodeModeOverride != OVN_NODE_MODE_DPU {
-			klog.Warningf("dpu-mode-config does not match %q or %q, is: %q. Using OVN configuration: %+v",
-				OVN_NODE_MODE_DPU_HOST, OVN_NODE_MODE_DPU, nodeModeOverride, ovnConfigResult)
-			return ovnConfigResult, nil
-		}
-		ovnConfigResult.NodeMode = nodeModeOverride
-		klog.Infof("Overriding OVN configuration to %+v", ovnConfigResult)
+		return nil, err
+	}
+	ovnConfigResult.GatewayNextHops = gatewayNextHops
+	ovnConfigResult.EgressSNATPools, err = bootstrapOVNEgressSNATPools(conf, kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	ovnConfigResult.IPsecMode = bootstrapIPsecMode(conf)
+	ovnConfigResult.IPsecIKE, ovnConfigResult.IPsecESP = bootstrapIPsecProposals(conf)
+	ovnConfigResult.IPsecIKELifetime, ovnConfigResult.IPsecSALifetime = bootstrapIPsecLifetimes(conf)
+	ovnConfigResult.IPsecForceEncapsulation = bootstrapIPsecEncapsulation(conf)
+	ovnConfigResult.IPsecExternalGateways, err = bootstrapIPsecExternalGateways(ovnConfigResult.IPsecMode, kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	ovnConfigResult.ProbeProfiles, err = bootstrapOVNProbeProfiles(kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	ovnConfigResult.RouteAdvertisements, err = bootstrapOVNRouteAdvertisements(kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	ovnConfigResult.ScaleUpNodes, err = bootstrapOVNScaleUp(conf, kubeClient, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	ovnConfigResult.ReservedNodeSubnets, err = bootstrapOVNReservedNodeSubnets(conf, kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	ovnConfigResult.MultitenantNetIDs, ovnConfigResult.IsolatedNamespaces, err = bootstrapOVNMultitenantIsolation(conf, kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	ovnConfigResult.NodePools, err = bootstrapOVNNodePools(kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	if err := bootstrapOVNNodePoolLabels(kubeClient, ovnConfigResult.NodePools); err != nil {
+		return nil, err
 	}
 	return ovnConfigResult, nil
 }
@@ -415,10 +753,44 @@ func validateOVNKubernetes(conf *operv1.NetworkSpec) []error {
 			out = append(out, errors.Errorf("invalid GenevePort %d", *oc.GenevePort))
 		}
 	}
+	if oc != nil && oc.HybridOverlayConfig != nil {
+		out = append(out, validateOVNHybridOverlay(conf)...)
+	}
 
 	return out
 }
 
+// validateOVNHybridOverlay checks that the hybrid cluster networks are valid CIDRs, which overlap
+// neither each other nor the cluster and service networks.
+func validateOVNHybridOverlay(conf *operv1.NetworkSpec) []error {
+	out := []error{}
+
+	// the cluster and service networks are validated on their own
+	pool := iputil.IPPool{}
+	for _, cn := range conf.ClusterNetwork {
+		if _, cidr, err := net.ParseCIDR(cn.CIDR); err == nil {
+			_ = pool.Add(*cidr)
+		}
+	}
+	for _, sn := range conf.ServiceNetwork {
+		if _, cidr, err := net.ParseCIDR(sn); err == nil {
+			_ = pool.Add(*cidr)
+		}
+	}
+
+	for _, hcn := range conf.DefaultNetwork.OVNKubernetesConfig.HybridOverlayConfig.HybridClusterNetwork {
+		_, cidr, err := net.ParseCIDR(hcn.CIDR)
+		if err != nil {
+			out = append(out, errors.Errorf("could not parse hybridClusterNetwork %s", hcn.CIDR))
+			continue
+		}
+		if err := pool.Add(*cidr); err != nil {
+			out = append(out, errors.Wrapf(err, "invalid hybridClusterNetwork %s", hcn.CIDR))
+		}
+	}
+	return out
+}
+
 func getOVNEncapOverhead(conf *operv1.NetworkSpec) uint32 {
 	const geneveOverhead = 100
 	const ipsecOverhead = 46 // Transport mode, AES-GCM
@@ -468,10 +840,14 @@ func isOVNKubernetesChangeSafe(prev, next *operv1.NetworkSpec) []error {
 	if pn.HybridOverlayConfig == nil && nn.HybridOverlayConfig != nil {
 		errs = append(errs, errors.Errorf("cannot start a hybrid overlay network after install time"))
 	}
-	if pn.HybridOverlayConfig != nil {
-		if !reflect.DeepEqual(pn.HybridOverlayConfig, nn.HybridOverlayConfig) {
-			errs = append(errs, errors.Errorf("cannot edit a running hybrid overlay network"))
-		}
+	if pn.HybridOverlayConfig != nil && !isOVNHybridOverlayChangeSafe(pn.HybridOverlayConfig, nn.HybridOverlayConfig) {
+		errs = append(errs, errors.Errorf("cannot edit a running hybrid overlay network"))
+	}
+	// the Windows nodes are not rolled out by the operator, the IP family of the cluster and the hybrid
+	// overlay networks are converted one at a time
+	if pn.HybridOverlayConfig != nil && len(prev.ServiceNetwork) != len(next.ServiceNetwork) &&
+		!reflect.DeepEqual(pn.HybridOverlayConfig, nn.HybridOverlayConfig) {
+		errs = append(errs, errors.Errorf("cannot edit the hybrid overlay network during an IP family conversion"))
 	}
 	if pn.IPsecConfig == nil && nn.IPsecConfig != nil {
 		errs = append(errs, errors.Errorf("cannot enable IPsec after install time"))
@@ -485,6 +861,34 @@ func isOVNKubernetesChangeSafe(prev, next *operv1.NetworkSpec) []error {
 	return errs
 }
 
+// forceOVNKubernetesChange sets the fields of prev whose changes can be forced to those of next
+func forceOVNKubernetesChange(prev, next *operv1.NetworkSpec) {
+	pn := prev.DefaultNetwork.OVNKubernetesConfig
+	nn := next.DefaultNetwork.OVNKubernetesConfig
+	if pn == nil || nn == nil {
+		return
+	}
+	pn.GenevePort = nn.GenevePort
+	pn.HybridOverlayConfig = nn.HybridOverlayConfig.DeepCopy()
+}
+
+// isOVNHybridOverlayChangeSafe returns true if a running hybrid overlay network is left unchanged,
+// or only gets new hybrid cluster networks appended, for instance for a new pool of Windows nodes.
+func isOVNHybridOverlayChangeSafe(prev, next *operv1.HybridOverlayConfig) bool {
+	if next == nil || !reflect.DeepEqual(prev.HybridOverlayVXLANPort, next.HybridOverlayVXLANPort) {
+		return false
+	}
+	if len(next.HybridClusterNetwork) < len(prev.HybridClusterNetwork) {
+		return false
+	}
+	for i := range prev.HybridClusterNetwork {
+		if prev.HybridClusterNetwork[i] != next.HybridClusterNetwork[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func fillOVNKubernetesDefaults(conf, previous *operv1.NetworkSpec, hostMTU int) {
 
 	if conf.DefaultNetwork.OVNKubernetesConfig == nil {
@@ -534,10 +938,672 @@ func fillOVNKubernetesDefaults(conf, previous *operv1.NetworkSpec, hostMTU int)
 
 }
 
+// bootstrapOVNPrePullerMode returns the pre-puller mode requested through the
+// networkoperator.openshift.io/ovn-prepuller-mode annotation, falling back to
+// the pre-puller daemonset when unset or invalid.
+func bootstrapOVNPrePullerMode(conf *operv1.Network) string {
+	mode, ok := conf.GetAnnotations()[names.OVNPrePullerModeAnnotation]
+	if !ok {
+		return OVN_PREPULLER_MODE_DAEMONSET
+	}
+	switch mode {
+	case OVN_PREPULLER_MODE_DAEMONSET, OVN_PREPULLER_MODE_JOB, OVN_PREPULLER_MODE_DISABLED:
+		klog.Infof("OVN-Kubernetes pre-puller mode is %s", mode)
+		return mode
+	default:
+		klog.Warningf("%s does not match %q, %q or %q, is: %q. Using default pre-puller mode: %s",
+			names.OVNPrePullerModeAnnotation, OVN_PREPULLER_MODE_DAEMONSET, OVN_PREPULLER_MODE_JOB,
+			OVN_PREPULLER_MODE_DISABLED, mode, OVN_PREPULLER_MODE_DAEMONSET)
+		return OVN_PREPULLER_MODE_DAEMONSET
+	}
+}
+
+// bootstrapOVNResourceProfile returns the resource profile requested through the
+// networkoperator.openshift.io/ovn-resource-profile annotation, falling back to
+// the default profile when unset or invalid.
+func bootstrapOVNResourceProfile(conf *operv1.Network) string {
+	profile, ok := conf.GetAnnotations()[names.OVNResourceProfileAnnotation]
+	if !ok {
+		return OVN_RESOURCE_PROFILE_DEFAULT
+	}
+	switch profile {
+	case OVN_RESOURCE_PROFILE_DEFAULT, OVN_RESOURCE_PROFILE_SINGLE_NODE:
+		klog.Infof("OVN-Kubernetes resource profile is %s", profile)
+		return profile
+	default:
+		klog.Warningf("%s does not match %q or %q, is: %q. Using default resource profile: %s",
+			names.OVNResourceProfileAnnotation, OVN_RESOURCE_PROFILE_DEFAULT, OVN_RESOURCE_PROFILE_SINGLE_NODE,
+			profile, OVN_RESOURCE_PROFILE_DEFAULT)
+		return OVN_RESOURCE_PROFILE_DEFAULT
+	}
+}
+
+// bootstrapOVNNodeUpgradeMode returns the ovnkube-node rollout mode requested through the
+// networkoperator.openshift.io/ovn-node-upgrade-mode annotation, falling back to the
+// default rolling update when unset or invalid.
+func bootstrapOVNNodeUpgradeMode(conf *operv1.Network) string {
+	mode, ok := conf.GetAnnotations()[names.OVNNodeUpgradeModeAnnotation]
+	if !ok {
+		return OVN_NODE_UPGRADE_MODE_DEFAULT
+	}
+	switch mode {
+	case OVN_NODE_UPGRADE_MODE_DEFAULT, OVN_NODE_UPGRADE_MODE_CONSERVATIVE:
+		klog.Infof("OVN-Kubernetes node upgrade mode is %s", mode)
+		return mode
+	default:
+		klog.Warningf("%s does not match %q or %q, is: %q. Using default node upgrade mode: %s",
+			names.OVNNodeUpgradeModeAnnotation, OVN_NODE_UPGRADE_MODE_DEFAULT, OVN_NODE_UPGRADE_MODE_CONSERVATIVE,
+			mode, OVN_NODE_UPGRADE_MODE_DEFAULT)
+		return OVN_NODE_UPGRADE_MODE_DEFAULT
+	}
+}
+
+// ovnSingleNodeProfile returns true if the single-node resource profile applies: it was requested
+// and the cluster has a single master. The profile does not apply once the cluster is scaled out,
+// as the OVN databases are then clustered again.
+func ovnSingleNodeProfile(bootstrapResult *bootstrap.BootstrapResult) bool {
+	return bootstrapResult.OVN.OVNKubernetesConfig.ResourceProfile == OVN_RESOURCE_PROFILE_SINGLE_NODE &&
+		len(bootstrapResult.OVN.MasterIPs) == 1
+}
+
+// ovnSingleNodeInfrastructure returns true if the single-node resource profile applies and the
+// cluster was not expanded with other nodes, which is reflected by its infrastructure topology
+func ovnSingleNodeInfrastructure(bootstrapResult *bootstrap.BootstrapResult) bool {
+	return ovnSingleNodeProfile(bootstrapResult) &&
+		bootstrapResult.Infra.InfrastructureTopology != configv1.HighlyAvailableTopologyMode
+}
+
+// bootstrapOVNMasterNodeSelector returns the selector of the nodes hosting the OVN masters, as set by
+// an annotation on the operator configuration, and whether it differs from the default.
+func bootstrapOVNMasterNodeSelector(conf *operv1.Network) (map[string]string, bool) {
+	annotation, ok := conf.GetAnnotations()[names.OVNMasterNodeSelectorAnnotation]
+	if !ok {
+		return ovnDefaultMasterNodeSelector(), false
+	}
+	selector, err := labels.ConvertSelectorToLabelsMap(annotation)
+	if err != nil || len(selector) == 0 {
+		klog.Warningf("%s must be a non-empty equality-based label selector, is: %q. Using the master nodes",
+			names.OVNMasterNodeSelectorAnnotation, annotation)
+		return ovnDefaultMasterNodeSelector(), false
+	}
+	klog.Infof("OVN-Kubernetes masters are hosted on nodes matching %s", selector)
+	return selector, true
+}
+
+// OVNMasterNodeSelector returns the selector of the nodes hosting the OVN masters.
+func OVNMasterNodeSelector(conf *operv1.Network) map[string]string {
+	selector, _ := bootstrapOVNMasterNodeSelector(conf)
+	return selector
+}
+
+// ovnDefaultMasterNodeSelector selects the master nodes
+func ovnDefaultMasterNodeSelector() map[string]string {
+	return map[string]string{"node-role.kubernetes.io/master": ""}
+}
+
+// bootstrapOVNMasterTolerations returns the keys of the taints tolerated by the OVN masters, as set by an
+// annotation on the operator configuration.
+func bootstrapOVNMasterTolerations(conf *operv1.Network) []string {
+	annotation, ok := conf.GetAnnotations()[names.OVNMasterTolerationsAnnotation]
+	if !ok {
+		return ovnDefaultMasterTolerations()
+	}
+	keys := []string{}
+	for _, key := range strings.Split(annotation, ",") {
+		key = strings.TrimSpace(key)
+		if errs := validation.IsQualifiedName(key); len(errs) != 0 {
+			klog.Warningf("%s must be a comma-separated list of taint keys, is: %q (%s). Tolerating the master taint",
+				names.OVNMasterTolerationsAnnotation, annotation, strings.Join(errs, ", "))
+			return ovnDefaultMasterTolerations()
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// ovnDefaultMasterTolerations tolerates the master taint
+func ovnDefaultMasterTolerations() []string {
+	return []string{"node-role.kubernetes.io/master"}
+}
+
+// ovnDebugDumpRequestRegexp matches the dump requests that are safe to template in the debug pod
+var ovnDebugDumpRequestRegexp = regexp.MustCompile(`^[A-Za-z0-9._:-]+$`)
+
+// bootstrapOVNDebug returns whether the ovnkube-debug pod is requested by annotations on the
+// operator configuration, along with the requested dump of the databases, if any.
+func bootstrapOVNDebug(conf *operv1.Network) (bool, string) {
+	annotations := conf.GetAnnotations()
+	dumpRequest := strings.TrimSpace(annotations[names.OVNDebugDumpAnnotation])
+	if dumpRequest != "" && !ovnDebugDumpRequestRegexp.MatchString(dumpRequest) {
+		klog.Warningf("%s must only contain alphanumerics, '-', '_', '.' and ':', is: %q. Ignoring it",
+			names.OVNDebugDumpAnnotation, dumpRequest)
+		dumpRequest = ""
+	}
+	debug := annotations[names.OVNDebugAnnotation] == "true" || dumpRequest != ""
+	if debug {
+		klog.Infof("OVN-Kubernetes debug pod requested, database dump request: %q", dumpRequest)
+	}
+	return debug, dumpRequest
+}
+
+// bootstrapOVNTools returns whether the ovn-tools DaemonSet is requested by an annotation on the
+// operator configuration
+func bootstrapOVNTools(conf *operv1.Network) bool {
+	tools := conf.GetAnnotations()[names.OVNToolsAnnotation] == "true"
+	if tools {
+		klog.Infof("OVN-Kubernetes tools DaemonSet requested")
+	}
+	return tools
+}
+
+// forceUnsafeChangeRequestRegexp matches the force requests that are safe to template in the pods
+var forceUnsafeChangeRequestRegexp = regexp.MustCompile(`^[A-Za-z0-9._:-]+$`)
+
+// ForceUnsafeChangeRequest returns the request to force an unsafe change, from the annotation on the
+// operator configuration, if any
+func ForceUnsafeChangeRequest(conf *operv1.Network) string {
+	request := strings.TrimSpace(conf.GetAnnotations()[names.ForceUnsafeChangeAnnotation])
+	if request != "" && !forceUnsafeChangeRequestRegexp.MatchString(request) {
+		klog.Warningf("%s must only contain alphanumerics, '-', '_', '.' and ':', is: %q. Ignoring it",
+			names.ForceUnsafeChangeAnnotation, request)
+		return ""
+	}
+	return request
+}
+
+// bootstrapOVNExternalGateways returns whether the multiple external gateways are enabled by annotations
+// on the operator configuration, and whether BFD is enabled by default on their next hops.
+func bootstrapOVNExternalGateways(conf *operv1.Network) (bool, bool) {
+	multiExternalGateway := boolAnnotation(conf, names.OVNMultiExternalGatewayAnnotation, false)
+	bfd := boolAnnotation(conf, names.OVNExternalGatewayBFDAnnotation, false)
+	if bfd && !multiExternalGateway {
+		klog.Warningf("%s requires %s. Ignoring it",
+			names.OVNExternalGatewayBFDAnnotation, names.OVNMultiExternalGatewayAnnotation)
+		bfd = false
+	}
+	if multiExternalGateway {
+		klog.Infof("OVN-Kubernetes multiple external gateways enabled, BFD by default: %t", bfd)
+	}
+	return multiExternalGateway, bfd
+}
+
+// bootstrapOVNPolicyAuditRetention returns the number of rotated ACL audit log files to keep
+// and their maximum age in days, as set by annotations on the operator configuration.
+func bootstrapOVNPolicyAuditRetention(conf *operv1.Network) (int, int) {
+	maxLogFiles := OVN_POLICY_AUDIT_MAX_LOG_FILES
+	maxLogAge := 0
+	annotations := conf.GetAnnotations()
+	if v, ok := annotations[names.OVNPolicyAuditMaxLogFilesAnnotation]; ok {
+		if n, err := strconv.Atoi(v); err != nil || n < 1 {
+			klog.Warningf("%s must be a positive integer, is: %q. Using default: %d",
+				names.OVNPolicyAuditMaxLogFilesAnnotation, v, OVN_POLICY_AUDIT_MAX_LOG_FILES)
+		} else {
+			maxLogFiles = n
+		}
+	}
+	if v, ok := annotations[names.OVNPolicyAuditMaxLogAgeAnnotation]; ok {
+		if n, err := strconv.Atoi(v); err != nil || n < 0 {
+			klog.Warningf("%s must be a non-negative number of days, is: %q. Ignoring it",
+				names.OVNPolicyAuditMaxLogAgeAnnotation, v)
+		} else {
+			maxLogAge = n
+		}
+	}
+	return maxLogFiles, maxLogAge
+}
+
+var (
+	kafkaBrokersRegexp = regexp.MustCompile(`^[A-Za-z0-9.\-\[\]:]+(,[A-Za-z0-9.\-\[\]:]+)*$`)
+	kafkaTopicRegexp   = regexp.MustCompile(`^[A-Za-z0-9._\-]{1,249}$`)
+)
+
+// bootstrapOVNPolicyAuditForwarding returns where the ACL audit logs are forwarded to, as set by annotations
+// on the operator configuration, along with the content of the TLS Secret of the forwarder. Invalid
+// destinations are ignored, and so is a destination without an image.
+func bootstrapOVNPolicyAuditForwarding(conf *operv1.Network, kubeClient client.Reader) *bootstrap.PolicyAuditForwarding {
+	annotations := conf.GetAnnotations()
+	destination, ok := annotations[names.OVNPolicyAuditForwardingAnnotation]
+	if !ok {
+		return nil
+	}
+	u, err := url.Parse(destination)
+	if err != nil {
+		klog.Warningf("%s must be a URL, is: %q. Ignoring it", names.OVNPolicyAuditForwardingAnnotation, destination)
+		return nil
+	}
+	forwarding := &bootstrap.PolicyAuditForwarding{Image: annotations[names.OVNPolicyAuditForwarderImageAnnotation]}
+	switch u.Scheme {
+	case "kafka":
+		forwarding.Type = "kafka"
+		forwarding.Brokers = u.Host
+		forwarding.Topic = strings.TrimPrefix(u.Path, "/")
+		if !kafkaBrokersRegexp.MatchString(forwarding.Brokers) || !kafkaTopicRegexp.MatchString(forwarding.Topic) {
+			klog.Warningf("%s must be kafka://<broker>[,<broker>...]/<topic>, is: %q. Ignoring it",
+				names.OVNPolicyAuditForwardingAnnotation, destination)
+			return nil
+		}
+	case "https":
+		forwarding.Type = "http"
+		forwarding.URL = u.String()
+		if u.Host == "" {
+			klog.Warningf("%s must be https://<host>[:<port>]/<path>, is: %q. Ignoring it",
+				names.OVNPolicyAuditForwardingAnnotation, destination)
+			return nil
+		}
+	default:
+		// the logs are only forwarded over TLS
+		klog.Warningf("%s must be a kafka:// or https:// URL, is: %q. Ignoring it",
+			names.OVNPolicyAuditForwardingAnnotation, destination)
+		return nil
+	}
+	if forwarding.Image == "" {
+		klog.Warningf("%s requires %s. Ignoring it",
+			names.OVNPolicyAuditForwardingAnnotation, names.OVNPolicyAuditForwarderImageAnnotation)
+		return nil
+	}
+
+	secret := &corev1.Secret{}
+	nsn := types.NamespacedName{Namespace: "openshift-ovn-kubernetes", Name: OVN_POLICY_AUDIT_FORWARDING_TLS_SECRET}
+	if err := kubeClient.Get(context.TODO(), nsn, secret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.Warningf("Failed to retrieve the %s secret, forwarding the ACL audit logs without it: %v",
+				OVN_POLICY_AUDIT_FORWARDING_TLS_SECRET, err)
+		}
+		return forwarding
+	}
+	_, forwarding.CABundle = secret.Data["ca-bundle.crt"]
+	_, hasCert := secret.Data[corev1.TLSCertKey]
+	_, hasKey := secret.Data[corev1.TLSPrivateKeyKey]
+	forwarding.ClientCert = hasCert && hasKey
+	return forwarding
+}
+
+// bootstrapOVNLoadBalancerTimeouts returns the session affinity and idle timeouts, in seconds, of the
+// OVN load balancers, as set by annotations on the operator configuration. Zero keeps the defaults.
+func bootstrapOVNLoadBalancerTimeouts(conf *operv1.Network) (int, int) {
+	affinityTimeout := 0
+	idleTimeout := 0
+	annotations := conf.GetAnnotations()
+	if v, ok := annotations[names.OVNLBAffinityTimeoutAnnotation]; ok {
+		// the session affinity timeout of a service cannot exceed one day either
+		if n, err := strconv.Atoi(v); err != nil || n < 1 || n > OVN_LB_MAX_AFFINITY_TIMEOUT {
+			klog.Warningf("%s must be a number of seconds between 1 and %d, is: %q. Ignoring it",
+				names.OVNLBAffinityTimeoutAnnotation, OVN_LB_MAX_AFFINITY_TIMEOUT, v)
+		} else {
+			affinityTimeout = n
+		}
+	}
+	if v, ok := annotations[names.OVNLBIdleTimeoutAnnotation]; ok {
+		if n, err := strconv.Atoi(v); err != nil || n < 1 {
+			klog.Warningf("%s must be a positive number of seconds, is: %q. Ignoring it",
+				names.OVNLBIdleTimeoutAnnotation, v)
+		} else {
+			idleTimeout = n
+		}
+	}
+	return affinityTimeout, idleTimeout
+}
+
+// bootstrapOVNDisableEmptyLBEvents returns whether the events of the load balancers without backends, which
+// unidle the idled services, are disabled by an annotation on the operator configuration. Clusters that do
+// not idle their services save the controller events raised for every connection to a service without
+// endpoints.
+func bootstrapOVNDisableEmptyLBEvents(conf *operv1.Network) bool {
+	return !boolAnnotation(conf, names.OVNEmptyLBEventsAnnotation, true)
+}
+
+// bootstrapOVNNamespaceHardening returns whether the ingress traffic to the pods of the
+// openshift-ovn-kubernetes namespace is restricted with NetworkPolicies
+func bootstrapOVNNamespaceHardening(conf *operv1.Network) bool {
+	return boolAnnotation(conf, names.OVNNamespaceHardeningAnnotation, false)
+}
+
+// bootstrapOVNUpgradeHold returns whether the rollout of a new release of ovn-kubernetes is held
+func bootstrapOVNUpgradeHold(conf *operv1.Network) bool {
+	return boolAnnotation(conf, names.OVNUpgradeHoldAnnotation, false)
+}
+
+// bootstrapOVNMinimalRBAC returns whether each ovn-kubernetes workload runs with narrowly scoped
+// permissions of its own
+func bootstrapOVNMinimalRBAC(conf *operv1.Network) bool {
+	return boolAnnotation(conf, names.OVNMinimalRBACAnnotation, false)
+}
+
+// bootstrapOVNDBEndpointName returns the DNS name the nodes reach the OVN databases on, if any.
+// It must be a fully qualified name, the nodes do not resolve the names of the cluster.
+func bootstrapOVNDBEndpointName(conf *operv1.Network) string {
+	v, ok := conf.GetAnnotations()[names.OVNDBEndpointNameAnnotation]
+	if !ok {
+		return ""
+	}
+	if errs := validation.IsDNS1123Subdomain(v); len(errs) > 0 || !strings.Contains(v, ".") {
+		klog.Warningf("%s must be a fully qualified DNS name, is: %q. Ignoring it", names.OVNDBEndpointNameAnnotation, v)
+		return ""
+	}
+	return v
+}
+
+var nodePortRangeRegexp = regexp.MustCompile(`^[0-9]+-[0-9]+$`)
+
+// bootstrapOVNNodePortRange returns the range of the node ports of the services, from the cluster
+// network configuration.
+func bootstrapOVNNodePortRange(kubeClient client.Reader) string {
+	clusterConfig := &configv1.Network{}
+	if err := kubeClient.Get(context.TODO(), types.NamespacedName{Name: names.CLUSTER_CONFIG}, clusterConfig); err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.Warningf("Failed to retrieve the cluster network configuration, using the default node port range %s: %v", OVN_NODE_PORT_RANGE, err)
+		}
+		return OVN_NODE_PORT_RANGE
+	}
+	v := clusterConfig.Spec.ServiceNodePortRange
+	if v == "" {
+		return OVN_NODE_PORT_RANGE
+	}
+	if !nodePortRangeRegexp.MatchString(v) {
+		klog.Warningf("serviceNodePortRange must be \"<first>-<last>\", is: %q. Using the default node port range %s", v, OVN_NODE_PORT_RANGE)
+		return OVN_NODE_PORT_RANGE
+	}
+	return v
+}
+
+// hostCIDRs returns the single-address CIDRs of the IPs
+func hostCIDRs(ips []string) []string {
+	cidrs := []string{}
+	for _, ip := range ips {
+		if utilnet.IsIPv6String(ip) {
+			cidrs = append(cidrs, ip+"/128")
+		} else {
+			cidrs = append(cidrs, ip+"/32")
+		}
+	}
+	return cidrs
+}
+
+// bootstrapOVNCrashForensics returns the number of crash reports to keep on each node, or zero
+// if they are not collected
+func bootstrapOVNCrashForensics(conf *operv1.Network) int {
+	v, ok := conf.GetAnnotations()[names.OVNCrashForensicsAnnotation]
+	if !ok {
+		return 0
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > OVN_CRASH_FORENSICS_MAX_RETENTION {
+		klog.Warningf("%s must be a number of crash reports between 1 and %d, is: %q. Ignoring it",
+			names.OVNCrashForensicsAnnotation, OVN_CRASH_FORENSICS_MAX_RETENTION, v)
+		return 0
+	}
+	return n
+}
+
+// bootstrapOVSDatapathMetrics returns the number of datapath flows of a node the alerts fire above, or zero
+// if the datapath metrics of OVS are not collected
+func bootstrapOVSDatapathMetrics(conf *operv1.Network) int {
+	v, ok := conf.GetAnnotations()[names.OVSDatapathMetricsAnnotation]
+	if !ok {
+		return 0
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > OVS_DATAPATH_MAX_FLOWS_CEILING {
+		klog.Warningf("%s must be a number of datapath flows between 1 and %d, is: %q. Ignoring it",
+			names.OVSDatapathMetricsAnnotation, OVS_DATAPATH_MAX_FLOWS_CEILING, v)
+		return 0
+	}
+	return n
+}
+
+// bootstrapOVNDBClient returns the maximum reconnection backoff, in milliseconds, and the maximum number of
+// in-flight transactions of ovnkube-master on the OVN databases, or zero to keep the ovn-kubernetes defaults
+func bootstrapOVNDBClient(conf *operv1.Network) (int, int) {
+	reconnectBackoff := 0
+	maxInflightTxns := 0
+	annotations := conf.GetAnnotations()
+	if v, ok := annotations[names.OVNDBClientReconnectBackoffAnnotation]; ok {
+		if n, err := strconv.Atoi(v); err != nil || n < OVN_DB_CLIENT_MIN_RECONNECT_BACKOFF || n > OVN_DB_CLIENT_MAX_RECONNECT_BACKOFF {
+			klog.Warningf("%s must be a number of milliseconds between %d and %d, is: %q. Ignoring it",
+				names.OVNDBClientReconnectBackoffAnnotation, OVN_DB_CLIENT_MIN_RECONNECT_BACKOFF, OVN_DB_CLIENT_MAX_RECONNECT_BACKOFF, v)
+		} else {
+			reconnectBackoff = n
+		}
+	}
+	if v, ok := annotations[names.OVNDBClientMaxInflightTxnsAnnotation]; ok {
+		if n, err := strconv.Atoi(v); err != nil || n < 1 || n > OVN_DB_CLIENT_MAX_INFLIGHT_TXNS {
+			klog.Warningf("%s must be a number of transactions between 1 and %d, is: %q. Ignoring it",
+				names.OVNDBClientMaxInflightTxnsAnnotation, OVN_DB_CLIENT_MAX_INFLIGHT_TXNS, v)
+		} else {
+			maxInflightTxns = n
+		}
+	}
+	return reconnectBackoff, maxInflightTxns
+}
+
+// bootstrapOVNEgressFirewallDNS returns the interval, in seconds, at which ovnkube-master resolves again the
+// DNS names of the EgressFirewall rules, and the host:port of the upstream resolver it resolves them with,
+// or zero values to keep the ovn-kubernetes defaults
+func bootstrapOVNEgressFirewallDNS(conf *operv1.Network) (int, string) {
+	refreshInterval := 0
+	resolver := ""
+	annotations := conf.GetAnnotations()
+	if v, ok := annotations[names.OVNEgressFirewallDNSRefreshIntervalAnnotation]; ok {
+		if n, err := strconv.Atoi(v); err != nil || n < OVN_EGRESS_FIREWALL_DNS_MIN_REFRESH_INTERVAL || n > OVN_EGRESS_FIREWALL_DNS_MAX_REFRESH_INTERVAL {
+			klog.Warningf("%s must be a number of seconds between %d and %d, is: %q. Ignoring it",
+				names.OVNEgressFirewallDNSRefreshIntervalAnnotation, OVN_EGRESS_FIREWALL_DNS_MIN_REFRESH_INTERVAL, OVN_EGRESS_FIREWALL_DNS_MAX_REFRESH_INTERVAL, v)
+		} else {
+			refreshInterval = n
+		}
+	}
+	if v, ok := annotations[names.OVNEgressFirewallDNSResolverAnnotation]; ok {
+		if r, err := parseDNSResolver(v); err != nil {
+			klog.Warningf("%s must be an IP address with an optional port, is: %q. Ignoring it",
+				names.OVNEgressFirewallDNSResolverAnnotation, v)
+		} else {
+			resolver = r
+		}
+	}
+	return refreshInterval, resolver
+}
+
+// parseDNSResolver returns the host:port of a DNS resolver given as an IP address, with an optional port
+// defaulting to 53. IPv6 addresses with a port are bracketed.
+func parseDNSResolver(v string) (string, error) {
+	if ip := net.ParseIP(v); ip != nil {
+		return net.JoinHostPort(ip.String(), "53"), nil
+	}
+	host, port, err := net.SplitHostPort(v)
+	if err != nil {
+		return "", err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return "", fmt.Errorf("%q is not an IP address", host)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return "", fmt.Errorf("%q is not a valid port", port)
+	}
+	return net.JoinHostPort(ip.String(), port), nil
+}
+
+// bootstrapOVNPerformance returns the OVSDB mode of ovnkube-master, the maximum number of operations it
+// batches in a transaction and the maximum number of entries of the logical flow cache of ovn-controller,
+// or zero to keep the defaults
+func bootstrapOVNPerformance(conf *operv1.Network) (string, int, int) {
+	ovsdbMode := OVN_OVSDB_MODE_LEGACY
+	txnBatchSize := 0
+	lflowCacheLimit := 0
+	annotations := conf.GetAnnotations()
+	if v, ok := annotations[names.OVNOVSDBModeAnnotation]; ok {
+		if v != OVN_OVSDB_MODE_LEGACY && v != OVN_OVSDB_MODE_LIBOVSDB {
+			klog.Warningf("%s must be %q or %q, is: %q. Ignoring it",
+				names.OVNOVSDBModeAnnotation, OVN_OVSDB_MODE_LEGACY, OVN_OVSDB_MODE_LIBOVSDB, v)
+		} else {
+			ovsdbMode = v
+		}
+	}
+	if v, ok := annotations[names.OVNTxnBatchSizeAnnotation]; ok {
+		if n, err := strconv.Atoi(v); err != nil || n < 1 || n > OVN_MAX_TXN_BATCH_SIZE {
+			klog.Warningf("%s must be a number of operations between 1 and %d, is: %q. Ignoring it",
+				names.OVNTxnBatchSizeAnnotation, OVN_MAX_TXN_BATCH_SIZE, v)
+		} else {
+			txnBatchSize = n
+		}
+	}
+	if v, ok := annotations[names.OVNLflowCacheLimitAnnotation]; ok {
+		if n, err := strconv.Atoi(v); err != nil || n < 1 || n > OVN_MAX_LFLOW_CACHE_LIMIT {
+			klog.Warningf("%s must be a number of entries between 1 and %d, is: %q. Ignoring it",
+				names.OVNLflowCacheLimitAnnotation, OVN_MAX_LFLOW_CACHE_LIMIT, v)
+		} else {
+			lflowCacheLimit = n
+		}
+	}
+	return ovsdbMode, txnBatchSize, lflowCacheLimit
+}
+
+// ovnNetworksInUse returns the cluster, hybrid overlay, service and machine networks, which the internal
+// subnets of ovn-kubernetes must not overlap
+func ovnNetworksInUse(conf *operv1.Network, machineNetworks []string) []string {
+	inUse := []string{}
+	for _, cn := range conf.Spec.ClusterNetwork {
+		inUse = append(inUse, cn.CIDR)
+	}
+	if oc := conf.Spec.DefaultNetwork.OVNKubernetesConfig; oc != nil && oc.HybridOverlayConfig != nil {
+		for _, hcn := range oc.HybridOverlayConfig.HybridClusterNetwork {
+			inUse = append(inUse, hcn.CIDR)
+		}
+	}
+	inUse = append(inUse, conf.Spec.ServiceNetwork...)
+	return append(inUse, machineNetworks...)
+}
+
+// bootstrapOVNSubnetAnnotation returns the subnet set by an annotation on the operator configuration,
+// or an empty string when it is unset or invalid: not of the given family, smaller than maxPrefix or
+// overlapping one of the networks in use.
+func bootstrapOVNSubnetAnnotation(conf *operv1.Network, annotation string, family utilnet.IPFamily, maxPrefix int, inUse []string) string {
+	v, ok := conf.GetAnnotations()[annotation]
+	if !ok {
+		return ""
+	}
+	ip, cidr, err := net.ParseCIDR(v)
+	if err != nil || utilnet.IsIPv6(ip) != (family == utilnet.IPv6) {
+		klog.Warningf("%s must be an IPv%s CIDR, is: %q. Ignoring it", annotation, family, v)
+		return ""
+	}
+	if ones, _ := cidr.Mask.Size(); ones > maxPrefix {
+		klog.Warningf("%s must be a /%d or larger subnet, is: %q. Ignoring it", annotation, maxPrefix, v)
+		return ""
+	}
+	for _, n := range inUse {
+		_, other, err := net.ParseCIDR(n)
+		if err == nil && iputil.NetsOverlap(*cidr, *other) {
+			klog.Warningf("%s must not overlap the network %s, is: %q. Ignoring it", annotation, n, v)
+			return ""
+		}
+	}
+	return cidr.String()
+}
+
+// bootstrapOVNJoinSubnets returns the IPv4 and IPv6 join subnets, connecting the gateway routers of the
+// nodes to the cluster router, set by annotations on the operator configuration, empty to keep the
+// ovn-kubernetes defaults. A subnet must hold an address for each node, and not overlap the networks in
+// use nor the default masquerade subnets.
+func bootstrapOVNJoinSubnets(conf *operv1.Network, inUse []string) (string, string) {
+	inUse = append(inUse, OVN_V4_MASQUERADE_SUBNET, OVN_V6_MASQUERADE_SUBNET)
+	return bootstrapOVNSubnetAnnotation(conf, names.OVNV4InternalSubnetAnnotation, utilnet.IPv4, 24, inUse),
+		bootstrapOVNSubnetAnnotation(conf, names.OVNV6InternalSubnetAnnotation, utilnet.IPv6, 120, inUse)
+}
+
+// bootstrapOVNMasqueradeSubnets returns the IPv4 and IPv6 masquerade subnets set by annotations on the
+// operator configuration, empty to keep the ovn-kubernetes defaults. A subnet must hold at least 8
+// addresses, and not overlap the networks in use nor the given join subnets.
+func bootstrapOVNMasqueradeSubnets(conf *operv1.Network, inUse []string, v4JoinSubnet, v6JoinSubnet string) (string, string) {
+	if v4JoinSubnet == "" {
+		v4JoinSubnet = OVN_V4_JOIN_SUBNET
+	}
+	if v6JoinSubnet == "" {
+		v6JoinSubnet = OVN_V6_JOIN_SUBNET
+	}
+	inUse = append(inUse, v4JoinSubnet, v6JoinSubnet)
+	return bootstrapOVNSubnetAnnotation(conf, names.OVNV4MasqueradeSubnetAnnotation, utilnet.IPv4, 29, inUse),
+		bootstrapOVNSubnetAnnotation(conf, names.OVNV6MasqueradeSubnetAnnotation, utilnet.IPv6, 125, inUse)
+}
+
+// bootstrapOVNNodeLocalDNS returns the link-local IPv4 address of the node-local DNS cache set by an
+// annotation on the operator configuration, or an empty string when it is unset or invalid. The address
+// must not be in the given masquerade subnet, nor the default one when empty.
+func bootstrapOVNNodeLocalDNS(conf *operv1.Network, v4MasqueradeSubnet string) string {
+	v, ok := conf.GetAnnotations()[names.OVNNodeLocalDNSAnnotation]
+	if !ok {
+		return ""
+	}
+	if enabled, err := strconv.ParseBool(v); err == nil {
+		if !enabled {
+			return ""
+		}
+		v = OVN_NODE_LOCAL_DNS_IP
+	}
+	ip := net.ParseIP(v)
+	if ip == nil || ip.To4() == nil || !ip.IsLinkLocalUnicast() {
+		klog.Warningf("%s must be a boolean or a link-local IPv4 address, is: %q. Ignoring it", names.OVNNodeLocalDNSAnnotation, v)
+		return ""
+	}
+	if v4MasqueradeSubnet == "" {
+		v4MasqueradeSubnet = OVN_V4_MASQUERADE_SUBNET
+	}
+	if _, masquerade, err := net.ParseCIDR(v4MasqueradeSubnet); err == nil && masquerade.Contains(ip) {
+		klog.Warningf("%s must not be in the masquerade subnet %s, is: %q. Ignoring it", names.OVNNodeLocalDNSAnnotation, v4MasqueradeSubnet, v)
+		return ""
+	}
+	return ip.String()
+}
+
+// cronFieldRegexp matches a field of a cron schedule, like "*/15", "1-5" or "MON,WED"
+var cronFieldRegexp = regexp.MustCompile(`^[0-9A-Za-z*/,?-]+$`)
+
+// cronMacros are the predefined schedules supported by CronJobs
+var cronMacros = sets.NewString("@yearly", "@annually", "@monthly", "@weekly", "@daily", "@midnight", "@hourly")
+
+// bootstrapOVNDBMaintenance returns the cron schedule of the maintenance window of the OVN databases,
+// and whether a snapshot of the compacted databases is kept, as set by annotations on the operator
+// configuration. An empty schedule disables the scheduled compaction.
+func bootstrapOVNDBMaintenance(conf *operv1.Network) (string, bool) {
+	annotations := conf.GetAnnotations()
+	schedule := strings.TrimSpace(annotations[names.OVNDBMaintenanceScheduleAnnotation])
+	if schedule == "" {
+		return "", false
+	}
+	if !validCronSchedule(schedule) {
+		klog.Warningf("%s must be a cron schedule like \"0 3 * * 6\", is: %q. Ignoring it",
+			names.OVNDBMaintenanceScheduleAnnotation, schedule)
+		return "", false
+	}
+	return schedule, boolAnnotation(conf, names.OVNDBMaintenanceSnapshotAnnotation, false)
+}
+
+// validCronSchedule returns true if the schedule has the five fields of a cron schedule,
+// or is one of the predefined schedules
+func validCronSchedule(schedule string) bool {
+	if cronMacros.Has(schedule) {
+		return true
+	}
+	fields := strings.Fields(schedule)
+	if len(fields) != 5 {
+		return false
+	}
+	for _, field := range fields {
+		if !cronFieldRegexp.MatchString(field) {
+			return false
+		}
+	}
+	return true
+}
+
 type replicaCountDecoder struct {
 	ControlPlane struct {
 		Replicas string `json:"replicas"`
 	} `json:"controlPlane"`
+	Networking struct {
+		MachineNetwork []struct {
+			CIDR string `json:"cidr"`
+		} `json:"machineNetwork,omitempty"`
+	} `json:"networking"`
 }
 
 // bootstrapOVNGatewayConfig sets the Network.operator.openshift.io.Spec.DefaultNetwork.OVNKubernetesConfig.GatewayConfig value
@@ -570,7 +1636,7 @@ func bootstrapOVNGatewayConfig(conf *operv1.Network, kubeClient client.Client) {
 	klog.Infof("Gateway mode is %s", modeOverride)
 }
 
-func bootstrapOVN(conf *operv1.Network, kubeClient client.Client) (*bootstrap.BootstrapResult, error) {
+func bootstrapOVN(conf *operv1.Network, kubeClient client.Client, tuning *bootstrap.OperatorTuning) (*bootstrap.BootstrapResult, error) {
 	clusterConfig := &corev1.ConfigMap{}
 	clusterConfigLookup := types.NamespacedName{Name: CLUSTER_CONFIG_NAME, Namespace: CLUSTER_CONFIG_NAMESPACE}
 	masterNodeList := &corev1.NodeList{}
@@ -588,78 +1654,121 @@ func bootstrapOVN(conf *operv1.Network, kubeClient client.Client) (*bootstrap.Bo
 	if err != nil {
 		return nil, fmt.Errorf("Unable to bootstrap OVN config, err: %v", err)
 	}
+	machineNetworks := []string{}
+	for _, mn := range rcD.Networking.MachineNetwork {
+		machineNetworks = append(machineNetworks, mn.CIDR)
+	}
+	inUse := ovnNetworksInUse(conf, machineNetworks)
+	ovnConfigResult.V4JoinSubnet, ovnConfigResult.V6JoinSubnet = bootstrapOVNJoinSubnets(conf, inUse)
+	ovnConfigResult.V4MasqueradeSubnet, ovnConfigResult.V6MasqueradeSubnet = bootstrapOVNMasqueradeSubnets(conf, inUse,
+		ovnConfigResult.V4JoinSubnet, ovnConfigResult.V6JoinSubnet)
+	ovnConfigResult.NodeLocalDNSIP = bootstrapOVNNodeLocalDNS(conf, ovnConfigResult.V4MasqueradeSubnet)
 
 	controlPlaneReplicaCount, _ := strconv.Atoi(rcD.ControlPlane.Replicas)
 
-	var heartBeat int
-
-	err = wait.PollImmediate(OVN_MASTER_DISCOVERY_POLL*time.Second, time.Duration(OVN_MASTER_DISCOVERY_TIMEOUT)*time.Second, func() (bool, error) {
-		matchingLabels := &client.MatchingLabels{"node-role.kubernetes.io/master": ""}
-		if err := kubeClient.List(context.TODO(), masterNodeList, matchingLabels); err != nil {
-			return false, err
-		}
-		if len(masterNodeList.Items) != 0 && controlPlaneReplicaCount == len(masterNodeList.Items) {
-			return true, nil
-		}
-
-		heartBeat++
-		if heartBeat%3 == 0 {
-			klog.V(2).Infof("Waiting to complete OVN bootstrap: found (%d) master nodes out of (%d) expected: timing out in %d seconds",
-				len(masterNodeList.Items), controlPlaneReplicaCount, OVN_MASTER_DISCOVERY_TIMEOUT-OVN_MASTER_DISCOVERY_POLL*heartBeat)
-		}
-		return false, nil
-	})
-	if wait.ErrWaitTimeout == err {
-		klog.Warningf("Timeout exceeded while bootstraping OVN, expected amount of control plane nodes (%v) do not match found (%v): %s, continuing deployment with found replicas", controlPlaneReplicaCount, len(masterNodeList.Items))
-		// On certain types of cluster this condition will never be met (assisted installer, for example)
-		// As to not hold the reconciliation loop for too long on such clusters: dynamically modify the timeout
-		// to a shorter and shorter value. Never reach 0 however as that will result in a `PollInfinity`.
-		// Right now we'll do:
-		// - First reconciliation 250 second timeout
-		// - Second reconciliation 130 second timeout
-		// - >= Third reconciliation 10 second timeout
-		if OVN_MASTER_DISCOVERY_TIMEOUT-OVN_MASTER_DISCOVERY_BACKOFF > 0 {
-			OVN_MASTER_DISCOVERY_TIMEOUT = OVN_MASTER_DISCOVERY_TIMEOUT - OVN_MASTER_DISCOVERY_BACKOFF
-		}
-	} else if err != nil {
+	// the install-config only knows about the control plane nodes, use whatever nodes are selected by a
+	// custom selector
+	masterNodeSelector, customMasterNodeSelector := bootstrapOVNMasterNodeSelector(conf)
+
+	// the nodes attached to an external OVN deployment need neither the masters nor the raft clusters
+	external, err := bootstrapOVNExternal(conf, kubeClient)
+	if err != nil {
 		return nil, fmt.Errorf("Unable to bootstrap OVN, err: %v", err)
 	}
 
-	ovnMasterIPs := make([]string, len(masterNodeList.Items))
-	for i, masterNode := range masterNodeList.Items {
-		var ip string
-		for _, address := range masterNode.Status.Addresses {
-			if address.Type == corev1.NodeInternalIP {
-				ip = address.Address
-				break
+	var ovnMasterIPs, previousMasterIPs, removedMasterIPs []string
+	var managementIPs map[string]string
+	var clusterInitiator string
+	var dbScaleDownJob *batchv1.Job
+	discoveryTimeoutShortened := false
+	if external == nil {
+		var heartBeat int
+
+		// start over from the configured timeout when it changes
+		if tuning.MasterDiscoveryTimeout != ovnMasterDiscoveryConfiguredTimeout {
+			ovnMasterDiscoveryTimeout, ovnMasterDiscoveryConfiguredTimeout = tuning.MasterDiscoveryTimeout, tuning.MasterDiscoveryTimeout
+		}
+		discoveryPoll := tuning.MasterDiscoveryPoll
+
+		err = wait.PollImmediate(time.Duration(discoveryPoll)*time.Second, time.Duration(ovnMasterDiscoveryTimeout)*time.Second, func() (bool, error) {
+			matchingLabels := client.MatchingLabels(masterNodeSelector)
+			if err := kubeClient.List(context.TODO(), masterNodeList, matchingLabels); err != nil {
+				return false, err
+			}
+			if len(masterNodeList.Items) != 0 && (customMasterNodeSelector || controlPlaneReplicaCount == len(masterNodeList.Items)) {
+				return true, nil
 			}
+
+			heartBeat++
+			if heartBeat%3 == 0 {
+				klog.V(2).Infof("Waiting to complete OVN bootstrap: found (%d) master nodes out of (%d) expected: timing out in %d seconds",
+					len(masterNodeList.Items), controlPlaneReplicaCount, ovnMasterDiscoveryTimeout-discoveryPoll*heartBeat)
+			}
+			return false, nil
+		})
+		if wait.ErrWaitTimeout == err {
+			klog.Warningf("Timeout exceeded while bootstraping OVN, expected amount of control plane nodes (%v) do not match found (%v): %s, continuing deployment with found replicas", controlPlaneReplicaCount, len(masterNodeList.Items))
+			// On certain types of cluster this condition will never be met (assisted installer, for example)
+			// As to not hold the reconciliation loop for too long on such clusters: dynamically modify the timeout
+			// to a shorter and shorter value. Never reach 0 however as that will result in a `PollInfinity`.
+			// Right now we'll do:
+			// - First reconciliation 250 second timeout
+			// - Second reconciliation 130 second timeout
+			// - >= Third reconciliation 10 second timeout
+			if ovnMasterDiscoveryTimeout-tuning.MasterDiscoveryBackoff > 0 {
+				ovnMasterDiscoveryTimeout = ovnMasterDiscoveryTimeout - tuning.MasterDiscoveryBackoff
+				discoveryTimeoutShortened = true
+			}
+		} else if err != nil {
+			return nil, fmt.Errorf("Unable to bootstrap OVN, err: %v", err)
 		}
-		if ip == "" {
-			return nil, fmt.Errorf("No InternalIP found on master node '%s'", masterNode.Name)
+
+		useManagementNetwork := conf.GetAnnotations()[names.OVNManagementNetworkAnnotation] == "true"
+		if useManagementNetwork {
+			managementIPs = make(map[string]string, len(masterNodeList.Items))
 		}
-		ovnMasterIPs[i] = ip
-	}
 
-	sort.Strings(ovnMasterIPs)
+		// on dual-stack clusters, the databases use the primary IP family of the cluster network
+		preferIPv6 := len(conf.Spec.ClusterNetwork) > 0 && utilnet.IsIPv6CIDRString(conf.Spec.ClusterNetwork[0].CIDR)
 
-	// clusterInitiator is used to avoid a split-brain scenario for the OVN NB/SB DBs. We want to consistently initialize
-	// any OVN cluster which is bootstrapped here, to the same initiator (should it still exists), hence we annotate the
-	// network.operator.openshift.io CRD with this information and always try to re-use the same member for the OVN RAFT
-	// cluster initialization
-	var clusterInitiator string
-	currentAnnotation := conf.GetAnnotations()
-	if cInitiator, ok := currentAnnotation[names.OVNRaftClusterInitiator]; ok && currentInitiatorExists(ovnMasterIPs, cInitiator) {
-		clusterInitiator = cInitiator
-	} else {
-		clusterInitiator = ovnMasterIPs[0]
-		if currentAnnotation == nil {
-			currentAnnotation = map[string]string{
-				names.OVNRaftClusterInitiator: clusterInitiator,
+		ovnMasterIPs = make([]string, len(masterNodeList.Items))
+		for i, masterNode := range masterNodeList.Items {
+			ip, err := ovnMasterIP(&masterNode, useManagementNetwork, preferIPv6)
+			if err != nil {
+				return nil, err
 			}
+			ovnMasterIPs[i] = ip
+			if useManagementNetwork {
+				managementIPs[masterNode.Name] = ip
+			}
+		}
+
+		sort.Strings(ovnMasterIPs)
+
+		// clusterInitiator is used to avoid a split-brain scenario for the OVN NB/SB DBs. We want to consistently initialize
+		// any OVN cluster which is bootstrapped here, to the same initiator (should it still exists), hence we annotate the
+		// network.operator.openshift.io CRD with this information and always try to re-use the same member for the OVN RAFT
+		// cluster initialization
+		currentAnnotation := conf.GetAnnotations()
+		if cInitiator, ok := currentAnnotation[names.OVNRaftClusterInitiator]; ok && currentInitiatorExists(ovnMasterIPs, cInitiator) {
+			clusterInitiator = cInitiator
 		} else {
-			currentAnnotation[names.OVNRaftClusterInitiator] = clusterInitiator
+			clusterInitiator = ovnMasterIPs[0]
+			if currentAnnotation == nil {
+				currentAnnotation = map[string]string{
+					names.OVNRaftClusterInitiator: clusterInitiator,
+				}
+			} else {
+				currentAnnotation[names.OVNRaftClusterInitiator] = clusterInitiator
+			}
+			conf.SetAnnotations(currentAnnotation)
+		}
+
+		previousMasterIPs = splitOVNDBMembers(conf.GetAnnotations()[names.OVNDBMembersAnnotation])
+		removedMasterIPs, dbScaleDownJob, err = bootstrapOVNDBRemovedMembers(conf, kubeClient, ovnMasterIPs)
+		if err != nil {
+			return nil, err
 		}
-		conf.SetAnnotations(currentAnnotation)
 	}
 
 	// Retrieve existing daemonsets - used for deciding if upgrades should happen
@@ -693,6 +1802,24 @@ func bootstrapOVN(conf *operv1.Network, kubeClient client.Client) (*bootstrap.Bo
 		}
 	}
 
+	var prePullerJob *batchv1.Job
+	if ovnConfigResult.PrePullerMode == OVN_PREPULLER_MODE_JOB {
+		prePullerJob = &batchv1.Job{}
+		nsn = types.NamespacedName{Namespace: "openshift-ovn-kubernetes", Name: "ovnkube-upgrades-prepuller"}
+		if err := kubeClient.Get(context.TODO(), nsn, prePullerJob); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("Failed to retrieve existing prepuller Job: %w", err)
+			} else {
+				prePullerJob = nil
+			}
+		}
+	}
+
+	missingObjects, err := bootstrapOVNMissingObjects(kubeClient, masterDS, nodeDS, external != nil)
+	if err != nil {
+		return nil, err
+	}
+
 	infraRes, err := platform.BootstrapInfra(kubeClient)
 	if err != nil {
 		return nil, err
@@ -707,76 +1834,194 @@ func bootstrapOVN(conf *operv1.Network, kubeClient client.Client) (*bootstrap.Bo
 			ExistingNodeDaemonset:   nodeDS,
 			OVNKubernetesConfig:     ovnConfigResult,
 			PrePullerDaemonset:      prePullerDS,
+			PrePullerJob:            prePullerJob,
 			FlowsConfig:             bootstrapFlowsConfig(kubeClient),
+			ManagementIPs:           managementIPs,
+			MasterNodeSelector:      masterNodeSelector,
+			MasterTolerations:       bootstrapOVNMasterTolerations(conf),
+			RemovedMasterIPs:        removedMasterIPs,
+			PreviousMasterIPs:       previousMasterIPs,
+			DBScaleDownJob:          dbScaleDownJob,
+			MissingObjects:          missingObjects,
+			External:                external,
 		},
 	}
+	if err := bootstrapOVNRolloutHooks(kubeClient, &res.OVN); err != nil {
+		return nil, err
+	}
+	if err := bootstrapPodNetworkChecks(kubeClient, &res.OVN); err != nil {
+		return nil, err
+	}
+	if external == nil {
+		if err := bootstrapOVNDBSchemaCheck(kubeClient, &res.OVN); err != nil {
+			return nil, err
+		}
+	}
+	if external == nil {
+		bootstrapOVNMasterTopology(conf, masterNodeList.Items, &res)
+	}
+	if discoveryTimeoutShortened {
+		res.RecordEvent(corev1.EventTypeWarning, "MasterDiscoveryTimeoutShortened",
+			"Found %d master nodes out of %d expected, continuing with the masters found and waiting %d seconds for them next time",
+			len(masterNodeList.Items), controlPlaneReplicaCount, ovnMasterDiscoveryTimeout)
+	}
 	return &res, nil
 }
 
-// bootstrapFlowsConfig looks for the openshift-network-operator/ovs-flows-config configmap, and
-// returns it or returns nil if it does not exist (or can't be properly parsed).
-// Usually, the second argument will be net.LookupIP
-func bootstrapFlowsConfig(cl client.Reader) *bootstrap.FlowsConfig {
-	cm := corev1.ConfigMap{}
-	if err := cl.Get(context.TODO(), types.NamespacedName{
-		Name:      OVSFlowsConfigMapName,
-		Namespace: OVSFlowsConfigNamespace,
-	}, &cm); err != nil {
+// bootstrapOVNDBRemovedMembers keeps track, in annotations on the operator configuration, of the
+// masters that are members of the OVN databases raft clusters. It returns the IPs of the masters
+// that left the cluster and have not yet been removed from the raft clusters by the
+// ovnkube-db-scale-down job, along with that job if it exists. A master only leaves the cluster
+// once its node is deleted, rather than when it is missing from the masters, e.g. while relabeled.
+func bootstrapOVNDBRemovedMembers(conf *operv1.Network, kubeClient client.Reader, masterIPs []string) ([]string, *batchv1.Job, error) {
+	annotations := conf.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	current := sets.NewString(masterIPs...)
+	removed := sets.NewString(splitOVNDBMembers(annotations[names.OVNDBRemovedMembersAnnotation])...)
+	removed.Insert(splitOVNDBMembers(annotations[names.OVNDBMembersAnnotation])...)
+	// a master that comes back is a member again
+	removed = removed.Difference(current)
+
+	members := current
+	if removed.Len() > 0 {
+		existing, err := nodeAddresses(kubeClient)
+		if err != nil {
+			return nil, nil, err
+		}
+		if kept := removed.Intersection(existing); kept.Len() > 0 {
+			klog.Infof("Masters %v are missing, but their nodes were not deleted, keeping them in the OVN databases", kept.List())
+			members = current.Union(kept)
+			removed = removed.Difference(kept)
+		}
+	}
+
+	job := &batchv1.Job{}
+	nsn := types.NamespacedName{Namespace: "openshift-ovn-kubernetes", Name: "ovnkube-db-scale-down"}
+	if err := kubeClient.Get(context.TODO(), nsn, job); err != nil {
 		if !apierrors.IsNotFound(err) {
-			klog.Warningf("%s: error fetching configmap: %v", OVSFlowsConfigMapName, err)
+			return nil, nil, fmt.Errorf("Failed to retrieve existing OVN DB scale down Job: %w", err)
 		}
-		// ovs-flows-config is not defined. Ignoring from bootstrap
-		return nil
+		job = nil
 	}
-	fc := bootstrap.FlowsConfig{}
-	// fetching string fields and transforming them to OVS format
-	if st, ok := cm.Data["sharedTarget"]; ok {
-		fc.Target = st
-	} else if np, ok := cm.Data["nodePort"]; ok {
-		// empty host will be interpreted as Node IP by ovn-kubernetes
-		fc.Target = ":" + np
+
+	if removed.Len() > 0 {
+		if job != nil && job.GetAnnotations()[names.OVNDBRemovedMembersAnnotation] == strings.Join(removed.List(), ",") && jobSucceeded(job) {
+			klog.Infof("Masters %v were removed from the OVN databases", removed.List())
+			removed = sets.NewString()
+		} else {
+			klog.Infof("Masters %v left the cluster, removing them from the OVN databases", removed.List())
+		}
+	}
+
+	annotations[names.OVNDBMembersAnnotation] = strings.Join(members.List(), ",")
+	if removed.Len() > 0 {
+		annotations[names.OVNDBRemovedMembersAnnotation] = strings.Join(removed.List(), ",")
 	} else {
-		klog.Warningf("%s: wrong data section: either sharedTarget or nodePort sections are needed: %+v",
-			OVSFlowsConfigMapName, cm.Data)
-		return nil
+		delete(annotations, names.OVNDBRemovedMembersAnnotation)
 	}
+	conf.SetAnnotations(annotations)
 
-	if catStr, ok := cm.Data["cacheActiveTimeout"]; ok {
-		if catd, err := time.ParseDuration(catStr); err != nil {
-			klog.Warningf("%s: wrong cacheActiveTimeout value %s. Ignoring: %v",
-				OVSFlowsConfigMapName, catStr, err)
-		} else {
-			catf := catd.Seconds()
-			catu := uint(catf)
-			if catf != float64(catu) {
-				klog.Warningf("%s: cacheActiveTimeout %s will be truncated to %d seconds",
-					OVSFlowsConfigMapName, catStr, catu)
+	return removed.List(), job, nil
+}
+
+// nodeAddresses returns the addresses of all the nodes, whatever their role or state, including
+// their management IPs
+func nodeAddresses(kubeClient client.Reader) (sets.String, error) {
+	nodes := &corev1.NodeList{}
+	if err := kubeClient.List(context.TODO(), nodes); err != nil {
+		return nil, fmt.Errorf("Failed to list the nodes: %w", err)
+	}
+	addresses := sets.NewString()
+	for _, node := range nodes.Items {
+		for _, address := range node.Status.Addresses {
+			if ip := net.ParseIP(address.Address); ip != nil {
+				addresses.Insert(ip.String())
 			}
-			fc.CacheActiveTimeout = &catu
+		}
+		if ip := net.ParseIP(node.GetAnnotations()[names.OVNManagementIPNodeAnnotation]); ip != nil {
+			addresses.Insert(ip.String())
 		}
 	}
+	return addresses, nil
+}
 
-	if cmfStr, ok := cm.Data["cacheMaxFlows"]; ok {
-		if cmf, err := strconv.ParseUint(cmfStr, 10, 32); err != nil {
-			klog.Warningf("%s: wrong cacheMaxFlows value %s. Ignoring: %v",
-				OVSFlowsConfigMapName, cmfStr, err)
-		} else {
-			cmfu := uint(cmf)
-			fc.CacheMaxFlows = &cmfu
+// recordOVNDBEndpointsEvent reports a change of the master IPs. The new OVN database endpoints are
+// applied in place on the nodes, through the ovnkube-db-endpoints ConfigMap, rather than rolling out
+// ovnkube-node.
+func recordOVNDBEndpointsEvent(bootstrapResult *bootstrap.BootstrapResult) {
+	previous := bootstrapResult.OVN.PreviousMasterIPs
+	if c := bootstrapResult.OVN.OVNKubernetesConfig; c != nil && c.DBEndpointName != "" {
+		// the nodes do not depend on the master IPs
+		return
+	}
+	if bootstrapResult.OVN.ExistingNodeDaemonset == nil || len(previous) == 0 ||
+		sets.NewString(previous...).Equal(sets.NewString(bootstrapResult.OVN.MasterIPs...)) {
+		return
+	}
+	bootstrapResult.RecordEvent(corev1.EventTypeNormal, "OVNDBEndpointsChanged",
+		"The master IPs changed from %v to %v, updating the OVN database endpoints of the nodes in place",
+		previous, bootstrapResult.OVN.MasterIPs)
+}
+
+// splitOVNDBMembers splits a comma-separated list of OVN DB members
+func splitOVNDBMembers(members string) []string {
+	out := []string{}
+	for _, member := range strings.Split(members, ",") {
+		if member = strings.TrimSpace(member); member != "" {
+			out = append(out, member)
 		}
 	}
+	return out
+}
 
-	if sStr, ok := cm.Data["sampling"]; ok {
-		if sampling, err := strconv.ParseUint(sStr, 10, 32); err != nil {
-			klog.Warningf("%s: wrong sampling value %s. Ignoring: %v",
-				OVSFlowsConfigMapName, sStr, err)
-		} else {
-			su := uint(sampling)
-			fc.Sampling = &su
+// ovnMasterIP returns the address the OVN databases of the given master node are reached on.
+// This is the node InternalIP, or the IP found in the management IP node annotation when the
+// OVN control-plane traffic is isolated on a separate VRF or interface.
+// The address is returned in canonical form, as it is compared to the pods' host IP.
+func ovnMasterIP(node *corev1.Node, useManagementNetwork, preferIPv6 bool) (string, error) {
+	if useManagementNetwork {
+		annotation, ok := node.GetAnnotations()[names.OVNManagementIPNodeAnnotation]
+		if !ok {
+			return "", fmt.Errorf("No %s annotation found on master node '%s'", names.OVNManagementIPNodeAnnotation, node.Name)
+		}
+		ip := net.ParseIP(annotation)
+		if !usableOVNMasterIP(ip) {
+			return "", fmt.Errorf("Invalid %s annotation %q on master node '%s'", names.OVNManagementIPNodeAnnotation, annotation, node.Name)
+		}
+		return ip.String(), nil
+	}
+
+	var fallback net.IP
+	for _, address := range node.Status.Addresses {
+		if address.Type != corev1.NodeInternalIP {
+			continue
+		}
+		ip := net.ParseIP(address.Address)
+		if !usableOVNMasterIP(ip) {
+			continue
+		}
+		if (ip.To4() == nil) == preferIPv6 {
+			return ip.String(), nil
 		}
+		if fallback == nil {
+			fallback = ip
+		}
+	}
+	if fallback != nil {
+		klog.Warningf("No InternalIP of the cluster IP family found on master node '%s', using %s", node.Name, fallback)
+		return fallback.String(), nil
 	}
+	return "", fmt.Errorf("No InternalIP found on master node '%s'", node.Name)
+}
 
-	return &fc
+// usableOVNMasterIP returns true if the OVN databases can be reached on the given address. Link-local
+// addresses are excluded, as they are not routable and would need a zone to be used.
+func usableOVNMasterIP(ip net.IP) bool {
+	return ip != nil && !ip.IsUnspecified() && !ip.IsLoopback() && !ip.IsMulticast() &&
+		!ip.IsLinkLocalUnicast()
 }
 
 func currentInitiatorExists(ovnMasterIPs []string, configInitiator string) bool {
@@ -797,7 +2042,7 @@ func dbList(masterIPs []string, port string) string {
 }
 
 func listenDualStack(masterIP string) string {
-	if strings.Contains(masterIP, ":") {
+	if utilnet.IsIPv6String(masterIP) {
 		// IPv6 master, make the databases listen dual-stack
 		return ":[::]"
 	} else {
@@ -806,6 +2051,40 @@ func listenDualStack(masterIP string) string {
 	}
 }
 
+// ovnCriticalConfigMaps are the ConfigMaps, rendered by the operator, that both ovnkube-master
+// and ovnkube-node need to start
+var ovnCriticalConfigMaps = []string{"ovnkube-config"}
+
+// bootstrapOVNMissingObjects returns the critical objects of ovn-kubernetes that were removed from
+// the cluster while the other ones are still deployed. Nothing is missing on a fresh cluster, and
+// ovnkube-master is not deployed at all when the nodes are attached to an external OVN deployment.
+func bootstrapOVNMissingObjects(kubeClient client.Reader, masterDS, nodeDS *appsv1.DaemonSet, external bool) ([]string, error) {
+	if masterDS == nil && nodeDS == nil {
+		return nil, nil
+	}
+	missing := []string{}
+	if masterDS == nil && !external {
+		missing = append(missing, "DaemonSet openshift-ovn-kubernetes/ovnkube-master")
+	}
+	if nodeDS == nil {
+		missing = append(missing, "DaemonSet openshift-ovn-kubernetes/ovnkube-node")
+	}
+	for _, name := range ovnCriticalConfigMaps {
+		cm := &corev1.ConfigMap{}
+		nsn := types.NamespacedName{Namespace: "openshift-ovn-kubernetes", Name: name}
+		if err := kubeClient.Get(context.TODO(), nsn, cm); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("Failed to retrieve existing ConfigMap %s: %w", name, err)
+			}
+			missing = append(missing, "ConfigMap openshift-ovn-kubernetes/"+name)
+		}
+	}
+	if len(missing) > 0 {
+		klog.Warningf("OVN-Kubernetes critical objects are missing: %s", strings.Join(missing, ", "))
+	}
+	return missing, nil
+}
+
 // shouldUpdateOVNKonIPFamilyChange determines if we should roll out changes to
 // the master and node daemonsets on IP family configuration changes.
 // We rollout changes on masters first when there is a configuration change.
@@ -845,13 +2124,19 @@ func shouldUpdateOVNKonIPFamilyChange(existingNode, existingMaster *appsv1.Daemo
 // If the existing node daemonset has a different version then what we would like to apply, we first
 // roll out a no-op daemonset. Then, when that has rolled out to 100% of the cluster or has stopped
 // progressing, proceed with the node upgrade.
-func shouldUpdateOVNKonPrepull(existingNode, prePuller *appsv1.DaemonSet, releaseVersion string) (updateNode, renderPrepull bool) {
+// If the pre-puller has been disabled, the node upgrade always proceeds immediately.
+func shouldUpdateOVNKonPrepull(existingNode, prePuller *appsv1.DaemonSet, mode, releaseVersion string) (updateNode, renderPrepull bool) {
 	// Fresh cluster - full steam ahead! No need to wait for pre-puller.
 	if existingNode == nil {
 		klog.V(3).Infof("Fresh cluster, no need for prepuller")
 		return true, false
 	}
 
+	if mode == OVN_PREPULLER_MODE_DISABLED {
+		klog.V(3).Infof("OVN-Kubernetes prepuller is disabled")
+		return true, false
+	}
+
 	// if node is already upgraded, then no need to pre-pull
 	// Return true so that we reconcile any changes that somehow could have happened.
 	existingNodeVersion := existingNode.GetAnnotations()["release.openshift.io/version"]
@@ -883,10 +2168,138 @@ func shouldUpdateOVNKonPrepull(existingNode, prePuller *appsv1.DaemonSet, releas
 	return true, false
 }
 
+// prePullerVersion returns the release the existing pre-puller pulls the image of, if there is one
+func prePullerVersion(bootstrapResult *bootstrap.BootstrapResult, mode string) string {
+	if mode == OVN_PREPULLER_MODE_JOB {
+		if bootstrapResult.OVN.PrePullerJob == nil {
+			return ""
+		}
+		return bootstrapResult.OVN.PrePullerJob.GetAnnotations()["release.openshift.io/version"]
+	}
+	if bootstrapResult.OVN.PrePullerDaemonset == nil {
+		return ""
+	}
+	return bootstrapResult.OVN.PrePullerDaemonset.GetAnnotations()["release.openshift.io/version"]
+}
+
+// shouldUpdateOVNKonPrepullJob is the Job-based counterpart of shouldUpdateOVNKonPrepull.
+// Rather than a daemonset, a Job with one completion per node pulls the image. The Job is
+// create-only, so if one is left over from a different release we stop rendering it, let it
+// be garbage-collected, and render a fresh one on the next pass.
+func shouldUpdateOVNKonPrepullJob(existingNode *appsv1.DaemonSet, prePuller *batchv1.Job, releaseVersion string) (updateNode, renderPrepull bool) {
+	// Fresh cluster - full steam ahead! No need to wait for pre-puller.
+	if existingNode == nil {
+		klog.V(3).Infof("Fresh cluster, no need for prepuller")
+		return true, false
+	}
+
+	existingNodeVersion := existingNode.GetAnnotations()["release.openshift.io/version"]
+	if existingNodeVersion == releaseVersion {
+		klog.V(3).Infof("OVN-Kubernetes node is already in the expected release.")
+		return true, false
+	}
+
+	if prePuller == nil {
+		klog.Infof("Rolling out the no-op prepuller job...")
+		return false, true
+	}
+
+	existingPrePullerVersion := prePuller.GetAnnotations()["release.openshift.io/version"]
+	if existingPrePullerVersion != releaseVersion {
+		klog.Infof("Removing prepuller job for release %q before pulling %q", existingPrePullerVersion, releaseVersion)
+		return false, false
+	}
+
+	if jobSucceeded(prePuller) {
+		klog.Infof("OVN-Kube upgrades-prepuller job finished, now starting node rollouts")
+		return true, false
+	}
+	for _, cond := range prePuller.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+			klog.Warningf("OVN-Kube upgrades-prepuller job failed (%s: %s), starting node rollouts without waiting for the image to be pulled",
+				cond.Reason, cond.Message)
+			return true, false
+		}
+	}
+	// the job controller fails the job past its deadline, unless the job was created without one
+	if created := prePuller.GetCreationTimestamp(); !created.IsZero() && time.Since(created.Time) > OVN_PREPULLER_JOB_DEADLINE {
+		klog.Warningf("OVN-Kube upgrades-prepuller job did not finish within %v, starting node rollouts without waiting for the image to be pulled",
+			OVN_PREPULLER_JOB_DEADLINE)
+		return true, false
+	}
+
+	klog.Infof("Waiting for ovnkube-upgrades-prepuller job to finish pulling the image before updating node")
+	return false, true
+}
+
+// shouldUpdateOVNKonDBScaleDown determines if the master daemonset can be rolled out while masters
+// that left the cluster are still members of the OVN databases. That is only the case when they cannot
+// be removed because the job removing them has failed, so that the databases are not stuck forever.
+func shouldUpdateOVNKonDBScaleDown(scaleDownJob *batchv1.Job, removedMasterIPs []string) bool {
+	if scaleDownJob == nil {
+		klog.Infof("Waiting for the removal of masters %v from the OVN databases before updating master", removedMasterIPs)
+		return false
+	}
+	if jobFinished(scaleDownJob) && !jobSucceeded(scaleDownJob) {
+		klog.Warningf("Failed to remove masters %v from the OVN databases, they have to be removed manually with 'cluster/kick'", removedMasterIPs)
+		return true
+	}
+	klog.Infof("Waiting for ovnkube-db-scale-down job to remove masters %v from the OVN databases before updating master", removedMasterIPs)
+	return false
+}
+
+// jobSucceeded returns true if a job has completed.
+func jobSucceeded(job *batchv1.Job) bool {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobComplete && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// jobFinished returns true if a job has either completed or failed.
+func jobFinished(job *batchv1.Job) bool {
+	for _, cond := range job.Status.Conditions {
+		if (cond.Type == batchv1.JobComplete || cond.Type == batchv1.JobFailed) && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// ovnkNodeUpgradedBeforeMaster returns true when ovnkube-node is already at releaseVersion but
+// ovnkube-master is not, that is between the node and the master rollouts of an upgrade.
+func ovnkNodeUpgradedBeforeMaster(existingNode, existingMaster *appsv1.DaemonSet, releaseVersion string) bool {
+	return existingNode != nil && existingMaster != nil &&
+		existingNode.GetAnnotations()["release.openshift.io/version"] == releaseVersion &&
+		existingMaster.GetAnnotations()["release.openshift.io/version"] != releaseVersion
+}
+
+// shouldUpdateOVNKNodeOnUpgrade determines if we should roll out changes to the node daemonset
+// of nodes attached to an external OVN deployment, which are only gated by the upgrade hold.
+func shouldUpdateOVNKNodeOnUpgrade(existingNode *appsv1.DaemonSet, releaseVersion string, hold bool) bool {
+	// Fresh cluster - full steam ahead!
+	if existingNode == nil {
+		return true
+	}
+	nodeVersion := existingNode.GetAnnotations()["release.openshift.io/version"]
+	if nodeVersion == releaseVersion {
+		klog.V(2).Infof("OVN-Kubernetes node already at release version %s; no changes required", releaseVersion)
+		return true
+	}
+	if hold {
+		klog.Infof("OVN-Kubernetes upgrade to release version %s is on hold; keeping node at %s", releaseVersion, nodeVersion)
+		return false
+	}
+	return true
+}
+
 // shouldUpdateOVNKonUpgrade determines if we should roll out changes to
 // the master and node daemonsets on upgrades. We roll out nodes first,
-// then masters. Downgrades, we do the opposite.
-func shouldUpdateOVNKonUpgrade(existingNode, existingMaster *appsv1.DaemonSet, releaseVersion string) (updateNode, updateMaster bool) {
+// then masters. Downgrades, we do the opposite. When hold is set, neither
+// is rolled out until it is lifted.
+func shouldUpdateOVNKonUpgrade(existingNode, existingMaster *appsv1.DaemonSet, releaseVersion string, hold bool) (updateNode, updateMaster bool) {
 	// Fresh cluster - full steam ahead!
 	if existingNode == nil || existingMaster == nil {
 		return true, true
@@ -902,6 +2315,12 @@ func shouldUpdateOVNKonUpgrade(existingNode, existingMaster *appsv1.DaemonSet, r
 		return true, true
 	}
 
+	if hold {
+		klog.Infof("OVN-Kubernetes upgrade to release version %s is on hold; keeping node at %s and master at %s",
+			releaseVersion, nodeVersion, masterVersion)
+		return false, false
+	}
+
 	// compute version delta
 	// versionUpgrade means the existing daemonSet needs an upgrade.
 	masterDelta := compareVersions(masterVersion, releaseVersion)
@@ -1009,30 +2428,11 @@ func daemonSetProgressing(ds *appsv1.DaemonSet, allowHung bool) bool {
 	return true
 }
 
-// setOVNDaemonsetAnnotation annotates the OVNkube master and node daemonset
-// it also annotated the template with the provided key and value to force the rollout
-func setOVNDaemonsetAnnotation(objs []*uns.Unstructured, key, value string) error {
-	for _, obj := range objs {
-		if obj.GetAPIVersion() == "apps/v1" && obj.GetKind() == "DaemonSet" &&
-			(obj.GetName() == "ovnkube-master" || obj.GetName() == "ovnkube-node") {
-			// set daemonset annotation
-			anno := obj.GetAnnotations()
-			if anno == nil {
-				anno = map[string]string{}
-			}
-			anno[key] = value
-			obj.SetAnnotations(anno)
-
-			// set pod template annotation
-			anno, _, _ = uns.NestedStringMap(obj.Object, "spec", "template", "metadata", "annotations")
-			if anno == nil {
-				anno = map[string]string{}
-			}
-			anno[key] = value
-			if err := uns.SetNestedStringMap(obj.Object, anno, "spec", "template", "metadata", "annotations"); err != nil {
-				return err
-			}
-		}
+// ovnDaemonsetAnnotations annotates the OVNkube master and node daemonsets, and their pod templates to
+// force their rollout, with the provided key and value
+func ovnDaemonsetAnnotations(key, value string) []k8s.Mutation {
+	return []k8s.Mutation{
+		k8s.SetAnnotation(k8s.ObjRef{Group: "apps", Kind: "DaemonSet", Namespace: "openshift-ovn-kubernetes", Name: "ovnkube-master"}, key, value, true),
+		k8s.SetAnnotation(k8s.ObjRef{Group: "apps", Kind: "DaemonSet", Namespace: "openshift-ovn-kubernetes", Name: "ovnkube-node"}, key, value, true),
 	}
-	return nil
 }
@@ -0,0 +1,88 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/openshift/cluster-network-operator/pkg/render"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// A scale-up is in progress while at least OVN_SCALE_UP_NODES nodes are joining the cluster: the nodes
+// created in the last OVN_SCALE_UP_WINDOW, and those created in the last OVN_SCALE_UP_SETTLE_TIMEOUT
+// that are not Ready yet.
+const (
+	OVN_SCALE_UP_NODES          = 10
+	OVN_SCALE_UP_WINDOW         = 10 * time.Minute
+	OVN_SCALE_UP_SETTLE_TIMEOUT = time.Hour
+)
+
+// The settings of the OVN control plane during a scale-up: the inactivity probes of the OVN databases, in
+// milliseconds, and the maximum number of in-flight transactions of ovnkube-master. Longer settings are kept.
+const (
+	OVN_SCALE_UP_INACTIVITY_PROBE  = 300000
+	OVN_SCALE_UP_MAX_INFLIGHT_TXNS = 500
+)
+
+// bootstrapOVNScaleUp returns the number of nodes joining the cluster when the adaptive tuning is enabled
+// by an annotation on the operator configuration and a scale-up is in progress, zero otherwise
+func bootstrapOVNScaleUp(conf *operv1.Network, kubeClient client.Reader, now time.Time) (int, error) {
+	v, ok := conf.GetAnnotations()[names.OVNAdaptiveTuningAnnotation]
+	if !ok {
+		return 0, nil
+	}
+	if v != "true" {
+		klog.Warningf("%s must be \"true\", is: %q. Ignoring it", names.OVNAdaptiveTuningAnnotation, v)
+		return 0, nil
+	}
+	nodes := &corev1.NodeList{}
+	if err := kubeClient.List(context.TODO(), nodes); err != nil {
+		return 0, fmt.Errorf("Failed to list the nodes: %w", err)
+	}
+	joining := 0
+	for i := range nodes.Items {
+		age := now.Sub(nodes.Items[i].CreationTimestamp.Time)
+		if age < OVN_SCALE_UP_WINDOW || (age < OVN_SCALE_UP_SETTLE_TIMEOUT && !isNodeReady(&nodes.Items[i])) {
+			joining++
+		}
+	}
+	if joining < OVN_SCALE_UP_NODES {
+		return 0, nil
+	}
+	return joining, nil
+}
+
+// isNodeReady returns whether the node reports the Ready condition
+func isNodeReady(node *corev1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// renderOVNScaleUp relaxes the settings of the OVN control plane while nodes are joining the cluster, so that
+// the load of a scale-up does not disconnect the clients of the databases, nor throttle ovnkube-master
+func renderOVNScaleUp(data *render.RenderData, scaleUpNodes int, maxInflightTxns int) {
+	if scaleUpNodes == 0 {
+		return
+	}
+	klog.Infof("%d nodes are joining the cluster, relaxing the settings of the OVN control plane", scaleUpNodes)
+	for _, key := range []string{"OVN_NB_INACTIVITY_PROBE", "OVN_SB_INACTIVITY_PROBE"} {
+		// 0 disables the probe
+		if probe, err := strconv.Atoi(data.Data[key].(string)); err == nil && probe != 0 && probe < OVN_SCALE_UP_INACTIVITY_PROBE {
+			data.Data[key] = strconv.Itoa(OVN_SCALE_UP_INACTIVITY_PROBE)
+		}
+	}
+	// 0 keeps the lower default of ovn-kubernetes
+	if maxInflightTxns < OVN_SCALE_UP_MAX_INFLIGHT_TXNS {
+		data.Data["OVNDBClientMaxInflightTxns"] = OVN_SCALE_UP_MAX_INFLIGHT_TXNS
+	}
+}
@@ -0,0 +1,119 @@
+package network
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestBootstrapOVNScaleUp(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	node := func(name string, age time.Duration, ready bool) crclient.Object {
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(now.Add(-age))},
+			Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}}},
+		}
+	}
+	nodes := func(prefix string, n int, age time.Duration, ready bool) []crclient.Object {
+		objs := []crclient.Object{}
+		for i := 0; i < n; i++ {
+			objs = append(objs, node(fmt.Sprintf("%s-%d", prefix, i), age, ready))
+		}
+		return objs
+	}
+	conf := OVNKubernetesConfig.DeepCopy()
+	scaleUp := func(objs ...[]crclient.Object) int {
+		all := []crclient.Object{}
+		for _, o := range objs {
+			all = append(all, o...)
+		}
+		n, err := bootstrapOVNScaleUp(conf, fake.NewClientBuilder().WithObjects(all...).Build(), now)
+		g.Expect(err).NotTo(HaveOccurred())
+		return n
+	}
+	settled := nodes("settled", 20, 24*time.Hour, true)
+
+	// disabled
+	g.Expect(scaleUp(settled, nodes("new", 15, time.Minute, false))).To(BeZero())
+
+	conf.Annotations = map[string]string{names.OVNAdaptiveTuningAnnotation: "yes"}
+	g.Expect(scaleUp(settled, nodes("new", 15, time.Minute, false))).To(BeZero())
+
+	conf.Annotations[names.OVNAdaptiveTuningAnnotation] = "true"
+	// too few nodes joining
+	g.Expect(scaleUp(settled, nodes("new", 9, time.Minute, true))).To(BeZero())
+	// the nodes joined in the window
+	g.Expect(scaleUp(settled, nodes("new", 15, time.Minute, true))).To(Equal(15))
+	// the nodes that joined before the window are still settling
+	g.Expect(scaleUp(settled, nodes("new", 5, time.Minute, true), nodes("old", 10, 30*time.Minute, false))).To(Equal(15))
+	// the nodes settled
+	g.Expect(scaleUp(settled, nodes("new", 5, time.Minute, true), nodes("old", 10, 30*time.Minute, true))).To(BeZero())
+	// the nodes not Ready for too long are not waited for
+	g.Expect(scaleUp(settled, nodes("broken", 15, 2*time.Hour, false))).To(BeZero())
+}
+
+func TestRenderOVNKubernetesScaleUp(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+	masterScripts := func() string {
+		objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+		g.Expect(err).NotTo(HaveOccurred())
+		ds := &appsv1.DaemonSet{}
+		g.Expect(convert(findInObjs("apps", "DaemonSet", "ovnkube-master", "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+		scripts := ""
+		for _, c := range ds.Spec.Template.Spec.Containers {
+			if len(c.Command) > 0 {
+				scripts += c.Command[len(c.Command)-1]
+			}
+			if c.Lifecycle != nil && c.Lifecycle.PostStart != nil && c.Lifecycle.PostStart.Exec != nil {
+				cmd := c.Lifecycle.PostStart.Exec.Command
+				scripts += cmd[len(cmd)-1]
+			}
+		}
+		return scripts
+	}
+
+	scripts := masterScripts()
+	g.Expect(scripts).To(ContainSubstring("inactivity_probe=60000"))
+	g.Expect(scripts).To(ContainSubstring("inactivity_probe=180000"))
+	g.Expect(scripts).NotTo(ContainSubstring("--nb-max-inflight-txns"))
+
+	bootstrapResult.OVN.OVNKubernetesConfig.ScaleUpNodes = 50
+	scripts = masterScripts()
+	g.Expect(scripts).NotTo(ContainSubstring("inactivity_probe=60000"))
+	g.Expect(scripts).NotTo(ContainSubstring("inactivity_probe=180000"))
+	g.Expect(scripts).To(ContainSubstring("inactivity_probe=300000"))
+	g.Expect(scripts).To(ContainSubstring(`--nb-max-inflight-txns "500"`))
+
+	// longer settings are kept
+	bootstrapResult.OVN.OVNKubernetesConfig.DBClientMaxInflightTxns = 800
+	bootstrapResult.Tuning.NBInactivityProbe = "600000"
+	scripts = masterScripts()
+	g.Expect(scripts).To(ContainSubstring("inactivity_probe=600000"))
+	g.Expect(scripts).To(ContainSubstring(`--nb-max-inflight-txns "800"`))
+}
@@ -0,0 +1,278 @@
+package network
+
+import (
+	"path/filepath"
+	"strings"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/openshift/cluster-network-operator/pkg/render"
+	"github.com/pkg/errors"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+// ovnComponent is a group of ovn-kubernetes manifests that are rendered together.
+// New features should add their manifests to an existing component, or register
+// a new one in ovnComponents, rather than growing renderOVNKubernetes.
+type ovnComponent struct {
+	// name identifies the component in the OVNDisabledComponentsAnnotation
+	name string
+	// manifests are the templates of the component, relative to the
+	// ovn-kubernetes manifest directory
+	manifests []string
+	// critical components are required for a working pod network, they are
+	// always rendered and cannot be force-disabled
+	critical bool
+	// enabled, when set, reports whether the component is needed by the
+	// current configuration
+	enabled func(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult) bool
+}
+
+// ovnComponents are the ovn-kubernetes components, in the order they are rendered.
+var ovnComponents = []ovnComponent{
+	{
+		name: "common",
+		manifests: []string{
+			"000-ns.yaml",
+			"001-crd.yaml",
+			"002-rbac-minimal.yaml",
+			"002-rbac.yaml",
+			"003-rbac-controller.yaml",
+			"004-config.yaml",
+			"006-pki.yaml",
+			"006-signer-pki.yaml",
+			"007-flowschema.yaml",
+			"cni-features.yaml",
+			"openshift-host-network-ns.yaml",
+			"openshift-host-network-resourcequota.yaml",
+		},
+		critical: true,
+	},
+	{
+		// ovnkube-master runs the NB and SB databases next to the master components
+		name: "master",
+		manifests: []string{
+			"005-service.yaml",
+			"ovnkube-db-scale-down.yaml",
+			"ovnkube-db-schema-check.yaml",
+			"ovnkube-master.yaml",
+		},
+		critical: true,
+		enabled: func(_ *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult) bool {
+			return bootstrapResult.OVN.External == nil
+		},
+	},
+	{
+		// the certificate of the nodes and the CA of the databases of an external OVN deployment,
+		// in place of those the operator PKI issues
+		name: "external",
+		manifests: []string{
+			"ovnkube-external-pki.yaml",
+		},
+		critical: true,
+		enabled: func(_ *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult) bool {
+			return bootstrapResult.OVN.External != nil
+		},
+	},
+	{
+		name: "node",
+		manifests: []string{
+			"error-cni.yaml",
+			"ovnkube-acl-audit-forwarder.yaml",
+			"ovnkube-db-endpoints.yaml",
+			"ovnkube-ipfix-config.yaml",
+			"ovnkube-ipfix-tls.yaml",
+			"ovnkube-node.yaml",
+		},
+		critical: true,
+	},
+	{
+		// the service account of the Jobs of the rollout hooks, when registered
+		name: "rollout-hooks",
+		manifests: []string{
+			"ovnkube-rollout-hooks-rbac.yaml",
+		},
+		enabled: func(_ *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult) bool {
+			return bootstrapResult.OVN.PreNodeRolloutHook != nil || bootstrapResult.OVN.PostNodeRolloutHook != nil
+		},
+	},
+	{
+		name: "ipsec",
+		manifests: []string{
+			"ipsec-rbac.yaml",
+			"ipsec.yaml",
+		},
+		enabled: func(conf *operv1.NetworkSpec, _ *bootstrap.BootstrapResult) bool {
+			return conf.DefaultNetwork.OVNKubernetesConfig.IPsecConfig != nil
+		},
+	},
+	{
+		name: "metrics",
+		manifests: []string{
+			"alert-rules-control-plane.yaml",
+			"alert-rules.yaml",
+			"monitor.yaml",
+		},
+	},
+	{
+		// the alerts on the datapath flows of OVS, exported by ovnkube-node when a ceiling is set
+		name: "datapath-metrics",
+		manifests: []string{
+			"alert-rules-datapath.yaml",
+		},
+		enabled: func(_ *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult) bool {
+			return bootstrapResult.OVN.OVNKubernetesConfig.DatapathFlowsCeiling > 0
+		},
+	},
+	{
+		// removes the SB records of deleted nodes that ovnkube-master missed
+		name: "chassis-cleanup",
+		manifests: []string{
+			"ovnkube-chassis-cleanup-rbac.yaml",
+			"ovnkube-chassis-cleanup.yaml",
+		},
+	},
+	{
+		// compacts the databases during the maintenance window, when one is set
+		name: "db-maintenance",
+		manifests: []string{
+			"ovnkube-db-maintenance.yaml",
+		},
+		enabled: func(_ *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult) bool {
+			return bootstrapResult.OVN.OVNKubernetesConfig.DBMaintenanceSchedule != ""
+		},
+	},
+	{
+		// restricts the ingress traffic to the pods of the namespace, when hardened
+		name: "network-policies",
+		manifests: []string{
+			"network-policies.yaml",
+		},
+		enabled: func(_ *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult) bool {
+			return bootstrapResult.OVN.OVNKubernetesConfig.NamespaceHardening
+		},
+	},
+	{
+		// preserves the isolation of the namespaces of a cluster migrated from the Multitenant mode of openshift-sdn
+		name: "multitenant-isolation",
+		manifests: []string{
+			"multitenant-isolation.yaml",
+		},
+		enabled: func(_ *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult) bool {
+			return len(bootstrapResult.OVN.OVNKubernetesConfig.MultitenantNetIDs) > 0
+		},
+	},
+	{
+		// the FRR speakers advertising the pod subnets and egress IPs of the nodes to the routers of the fabric
+		name: "route-advertisements",
+		manifests: []string{
+			"ovnkube-route-advertisements.yaml",
+		},
+		enabled: func(_ *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult) bool {
+			return len(bootstrapResult.OVN.OVNKubernetesConfig.RouteAdvertisements) > 0
+		},
+	},
+	{
+		// ovnkube-debug is deployed on demand, to inspect and dump the databases
+		name: "debug",
+		manifests: []string{
+			"ovnkube-debug-rbac.yaml",
+			"ovnkube-debug.yaml",
+		},
+		enabled: func(_ *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult) bool {
+			return bootstrapResult.OVN.OVNKubernetesConfig.Debug
+		},
+	},
+	{
+		// ovn-tools is deployed on demand, to troubleshoot the nodes during incidents
+		name: "tools",
+		manifests: []string{
+			"ovnkube-tools-rbac.yaml",
+			"ovnkube-tools.yaml",
+		},
+		enabled: func(_ *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult) bool {
+			return bootstrapResult.OVN.OVNKubernetesConfig.Tools
+		},
+	},
+	{
+		name: "prepuller",
+		manifests: []string{
+			"pre-puller-job.yaml",
+			"pre-puller.yaml",
+		},
+		enabled: func(_ *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult) bool {
+			// a single node has no other node to pull the image on ahead of the rollout, until it is expanded
+			return bootstrapResult.OVN.OVNKubernetesConfig.PrePullerMode != OVN_PREPULLER_MODE_DISABLED &&
+				!ovnSingleNodeInfrastructure(bootstrapResult)
+		},
+	},
+}
+
+// renderOVNComponents renders the manifests of every ovn-kubernetes component that is
+// enabled and has not been force-disabled.
+func renderOVNComponents(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult, manifestDir string, data *render.RenderData) ([]*uns.Unstructured, error) {
+	objs := []*uns.Unstructured{}
+	for _, component := range ovnComponents {
+		if ovnComponentForceDisabled(bootstrapResult, component.name) {
+			klog.Warningf("OVN-Kubernetes component %s is force-disabled, not rendering it", component.name)
+			continue
+		}
+		if component.enabled != nil && !component.enabled(conf, bootstrapResult) {
+			continue
+		}
+		for _, manifest := range component.manifests {
+			manifests, err := render.RenderTemplate(filepath.Join(manifestDir, "network/ovn-kubernetes", manifest), data)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to render %s manifests", component.name)
+			}
+			objs = append(objs, manifests...)
+		}
+	}
+	return objs, nil
+}
+
+// ovnComponentForceDisabled returns true if the named component was force-disabled
+func ovnComponentForceDisabled(bootstrapResult *bootstrap.BootstrapResult, name string) bool {
+	for _, disabled := range bootstrapResult.OVN.OVNKubernetesConfig.DisabledComponents {
+		if disabled == name {
+			return true
+		}
+	}
+	return false
+}
+
+// bootstrapOVNDisabledComponents returns the components listed in the disabled components
+// annotation of the operator configuration. Unknown and critical components are ignored.
+func bootstrapOVNDisabledComponents(conf *operv1.Network) []string {
+	annotation, ok := conf.GetAnnotations()[names.OVNDisabledComponentsAnnotation]
+	if !ok {
+		return nil
+	}
+
+	known := map[string]ovnComponent{}
+	for _, component := range ovnComponents {
+		known[component.name] = component
+	}
+
+	disabled := sets.NewString()
+	for _, name := range strings.Split(annotation, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		component, ok := known[name]
+		if !ok {
+			klog.Warningf("%s: unknown OVN-Kubernetes component %q, ignoring it", names.OVNDisabledComponentsAnnotation, name)
+			continue
+		}
+		if component.critical {
+			klog.Warningf("%s: OVN-Kubernetes component %q is critical and cannot be disabled, ignoring it", names.OVNDisabledComponentsAnnotation, name)
+			continue
+		}
+		disabled.Insert(name)
+	}
+	return disabled.List()
+}
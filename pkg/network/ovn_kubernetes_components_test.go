@@ -0,0 +1,349 @@
+package network
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	. "github.com/onsi/gomega"
+)
+
+// TestOVNComponentsCoverManifests makes sure every ovn-kubernetes manifest belongs to
+// exactly one component, otherwise it would silently never be rendered.
+func TestOVNComponentsCoverManifests(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	files, err := ioutil.ReadDir(filepath.Join(manifestDirOvn, "network/ovn-kubernetes"))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	owners := map[string][]string{}
+	for _, component := range ovnComponents {
+		for _, manifest := range component.manifests {
+			owners[manifest] = append(owners[manifest], component.name)
+		}
+	}
+	for _, file := range files {
+		g.Expect(owners).To(HaveKeyWithValue(file.Name(), HaveLen(1)), "manifest %s must belong to exactly one component", file.Name())
+		delete(owners, file.Name())
+	}
+	g.Expect(owners).To(BeEmpty(), "components reference manifests that do not exist")
+}
+
+func TestBootstrapOVNDisabledComponents(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	conf := &operv1.Network{}
+	g.Expect(bootstrapOVNDisabledComponents(conf)).To(BeEmpty())
+
+	conf.Annotations = map[string]string{
+		names.OVNDisabledComponentsAnnotation: "prepuller, metrics,,node,bogus,metrics",
+	}
+	g.Expect(bootstrapOVNDisabledComponents(conf)).To(Equal([]string{"metrics", "prepuller"}))
+}
+
+func TestRenderOVNKubernetesDisabledComponents(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("ServiceMonitor", "openshift-ovn-kubernetes", "monitor-ovn-node")))
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("CronJob", "openshift-ovn-kubernetes", "ovnkube-chassis-cleanup")))
+	// ipsec is only rendered when configured
+	g.Expect(objs).NotTo(ContainElement(HaveKubernetesID("DaemonSet", "openshift-ovn-kubernetes", "ovn-ipsec")))
+
+	bootstrapResult.OVN.OVNKubernetesConfig.DisabledComponents = []string{"metrics", "chassis-cleanup"}
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).NotTo(ContainElement(HaveKubernetesID("CronJob", "openshift-ovn-kubernetes", "ovnkube-chassis-cleanup")))
+	g.Expect(objs).NotTo(ContainElement(HaveKubernetesID("ServiceMonitor", "openshift-ovn-kubernetes", "monitor-ovn-node")))
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("DaemonSet", "openshift-ovn-kubernetes", "ovnkube-master")))
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("DaemonSet", "openshift-ovn-kubernetes", "ovnkube-node")))
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("Namespace", "", "openshift-ovn-kubernetes")))
+
+	// a disabled component stays disabled even when it is needed by the configuration
+	config.DefaultNetwork.OVNKubernetesConfig.IPsecConfig = &operv1.IPsecConfig{}
+	bootstrapResult.OVN.OVNKubernetesConfig.DisabledComponents = nil
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("DaemonSet", "openshift-ovn-kubernetes", "ovn-ipsec")))
+	bootstrapResult.OVN.OVNKubernetesConfig.DisabledComponents = []string{"ipsec"}
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).NotTo(ContainElement(HaveKubernetesID("DaemonSet", "openshift-ovn-kubernetes", "ovn-ipsec")))
+}
+
+func TestBootstrapOVNDebug(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	conf := &operv1.Network{}
+	debug, dumpRequest := bootstrapOVNDebug(conf)
+	g.Expect(debug).To(BeFalse())
+	g.Expect(dumpRequest).To(BeEmpty())
+
+	conf.Annotations = map[string]string{names.OVNDebugAnnotation: "true"}
+	debug, dumpRequest = bootstrapOVNDebug(conf)
+	g.Expect(debug).To(BeTrue())
+	g.Expect(dumpRequest).To(BeEmpty())
+
+	// a dump request implies the debug pod
+	conf.Annotations = map[string]string{names.OVNDebugDumpAnnotation: "2021-11-02T10:00:00Z"}
+	debug, dumpRequest = bootstrapOVNDebug(conf)
+	g.Expect(debug).To(BeTrue())
+	g.Expect(dumpRequest).To(Equal("2021-11-02T10:00:00Z"))
+
+	// requests that cannot be templated safely are ignored
+	conf.Annotations = map[string]string{names.OVNDebugDumpAnnotation: `"; reboot`}
+	debug, dumpRequest = bootstrapOVNDebug(conf)
+	g.Expect(debug).To(BeFalse())
+	g.Expect(dumpRequest).To(BeEmpty())
+}
+
+func TestRenderOVNKubernetesDebug(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).NotTo(ContainElement(HaveKubernetesID("Deployment", "openshift-ovn-kubernetes", "ovnkube-debug")))
+
+	bootstrapResult.OVN.OVNKubernetesConfig.Debug = true
+	bootstrapResult.OVN.OVNKubernetesConfig.DebugDumpRequest = "1"
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	d := &appsv1.Deployment{}
+	g.Expect(convert(findInObjs("apps", "Deployment", "ovnkube-debug", "openshift-ovn-kubernetes", objs), d)).To(Succeed())
+	g.Expect(d.Spec.Template.Annotations).To(HaveKeyWithValue(names.OVNDebugDumpAnnotation, "1"))
+	container := d.Spec.Template.Spec.Containers[0]
+	g.Expect(container.Env).To(ContainElements(
+		corev1.EnvVar{Name: "OVN_NB_DB", Value: "ssl:1.2.3.4:9641,ssl:5.6.7.8:9641,ssl:9.10.11.12:9641"},
+		corev1.EnvVar{Name: "OVN_SB_DB", Value: "ssl:1.2.3.4:9642,ssl:5.6.7.8:9642,ssl:9.10.11.12:9642"},
+		corev1.EnvVar{Name: "OVN_DB_DUMP_REQUEST", Value: "1"},
+	))
+}
+
+func TestBootstrapOVNTools(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	conf := &operv1.Network{}
+	g.Expect(bootstrapOVNTools(conf)).To(BeFalse())
+	conf.Annotations = map[string]string{names.OVNToolsAnnotation: "true"}
+	g.Expect(bootstrapOVNTools(conf)).To(BeTrue())
+	conf.Annotations = map[string]string{names.OVNToolsAnnotation: "yes"}
+	g.Expect(bootstrapOVNTools(conf)).To(BeFalse())
+}
+
+func TestRenderOVNKubernetesTools(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).NotTo(ContainElement(HaveKubernetesID("DaemonSet", "openshift-ovn-kubernetes", "ovn-tools")))
+	g.Expect(objs).NotTo(ContainElement(HaveKubernetesID("ServiceAccount", "openshift-ovn-kubernetes", "ovn-kubernetes-tools")))
+
+	bootstrapResult.OVN.OVNKubernetesConfig.Tools = true
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("ClusterRole", "", "openshift-ovn-kubernetes-tools")))
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("Role", "openshift-ovn-kubernetes", "openshift-ovn-kubernetes-tools")))
+	ds := &appsv1.DaemonSet{}
+	g.Expect(convert(findInObjs("apps", "DaemonSet", "ovn-tools", "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+	g.Expect(ds.Spec.Template.Spec.ServiceAccountName).To(Equal("ovn-kubernetes-tools"))
+	g.Expect(ds.Spec.Template.Spec.HostNetwork).To(BeTrue())
+	container := ds.Spec.Template.Spec.Containers[0]
+	g.Expect(container.Env).To(ContainElements(
+		corev1.EnvVar{Name: "OVN_NB_DB", Value: "ssl:1.2.3.4:9641,ssl:5.6.7.8:9641,ssl:9.10.11.12:9641"},
+		corev1.EnvVar{Name: "OVN_SB_DB", Value: "ssl:1.2.3.4:9642,ssl:5.6.7.8:9642,ssl:9.10.11.12:9642"},
+	))
+	mounts := []string{}
+	for _, mount := range container.VolumeMounts {
+		mounts = append(mounts, mount.MountPath)
+	}
+	g.Expect(mounts).To(ContainElements("/run/openvswitch", "/run/ovn/", "/host", "/ovn-cert", "/ovn-ca"))
+}
+
+func TestBootstrapOVNNamespaceHardening(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	conf := &operv1.Network{}
+	g.Expect(bootstrapOVNNamespaceHardening(conf)).To(BeFalse())
+	conf.Annotations = map[string]string{names.OVNNamespaceHardeningAnnotation: "true"}
+	g.Expect(bootstrapOVNNamespaceHardening(conf)).To(BeTrue())
+	conf.Annotations = map[string]string{names.OVNNamespaceHardeningAnnotation: "yes"}
+	g.Expect(bootstrapOVNNamespaceHardening(conf)).To(BeFalse())
+}
+
+func TestRenderOVNKubernetesNamespaceHardening(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4", "5.6.7.8", "fd00::9"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).NotTo(ContainElement(HaveKubernetesID("NetworkPolicy", "openshift-ovn-kubernetes", "default-deny")))
+
+	bootstrapResult.OVN.OVNKubernetesConfig.NamespaceHardening = true
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	for _, name := range []string{"default-deny", "allow-ovn-db-clients", "allow-ovn-db-raft", "allow-metrics"} {
+		g.Expect(objs).To(ContainElement(HaveKubernetesID("NetworkPolicy", "openshift-ovn-kubernetes", name)))
+	}
+	raft := &networkingv1.NetworkPolicy{}
+	g.Expect(convert(findInObjs("networking.k8s.io", "NetworkPolicy", "allow-ovn-db-raft", "openshift-ovn-kubernetes", objs), raft)).To(Succeed())
+	g.Expect(raft.Spec.Ingress).To(HaveLen(1))
+	cidrs := []string{}
+	for _, peer := range raft.Spec.Ingress[0].From {
+		cidrs = append(cidrs, peer.IPBlock.CIDR)
+	}
+	g.Expect(cidrs).To(Equal([]string{"1.2.3.4/32", "5.6.7.8/32", "fd00::9/128"}))
+	ports := []string{}
+	for _, port := range raft.Spec.Ingress[0].Ports {
+		ports = append(ports, port.Port.String())
+	}
+	g.Expect(ports).To(Equal([]string{"9643", "9644"}))
+}
+
+func TestRenderOVNKubernetesMinimalRBAC(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	config.DefaultNetwork.OVNKubernetesConfig.IPsecConfig = &operv1.IPsecConfig{}
+	FillDefaults(config, nil)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs: []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{
+				Debug:                 true,
+				DBMaintenanceSchedule: "0 3 * * 6",
+			},
+		},
+	}
+
+	serviceAccount := func(objs []*uns.Unstructured, kind, name string) string {
+		obj := findInObjs("", kind, name, "openshift-ovn-kubernetes", objs)
+		if obj == nil {
+			obj = findInObjs("apps", kind, name, "openshift-ovn-kubernetes", objs)
+		}
+		if obj == nil {
+			obj = findInObjs("batch", kind, name, "openshift-ovn-kubernetes", objs)
+		}
+		g.Expect(obj).NotTo(BeNil(), "%s %s", kind, name)
+		path := []string{"spec", "template", "spec", "serviceAccountName"}
+		if kind == "CronJob" {
+			path = []string{"spec", "jobTemplate", "spec", "template", "spec", "serviceAccountName"}
+		}
+		sa, _, err := uns.NestedString(obj.Object, path...)
+		g.Expect(err).NotTo(HaveOccurred())
+		return sa
+	}
+	clusterRole := func(objs []*uns.Unstructured, name string) *rbacv1.ClusterRole {
+		role := &rbacv1.ClusterRole{}
+		g.Expect(convert(findInObjs("rbac.authorization.k8s.io", "ClusterRole", name, "", objs), role)).To(Succeed())
+		return role
+	}
+	allows := func(role *rbacv1.ClusterRole, resource, verb string) bool {
+		for _, rule := range role.Rules {
+			if sets.NewString(rule.Resources...).Has(resource) && sets.NewString(rule.Verbs...).Has(verb) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// by default, the auxiliary workloads share the ServiceAccounts of ovnkube-master and ovnkube-node
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(serviceAccount(objs, "DaemonSet", "ovn-ipsec")).To(Equal("ovn-kubernetes-node"))
+	g.Expect(serviceAccount(objs, "CronJob", "ovnkube-chassis-cleanup")).To(Equal("ovn-kubernetes-controller"))
+	g.Expect(serviceAccount(objs, "Deployment", "ovnkube-debug")).To(Equal("ovn-kubernetes-controller"))
+	g.Expect(allows(clusterRole(objs, "openshift-ovn-kubernetes-node"), "certificatesigningrequests", "delete")).To(BeTrue())
+	g.Expect(allows(clusterRole(objs, "openshift-ovn-kubernetes-controller"), "configmaps", "update")).To(BeTrue())
+	g.Expect(objs).NotTo(ContainElement(HaveKubernetesID("ServiceAccount", "openshift-ovn-kubernetes", "ovn-kubernetes-jobs")))
+
+	bootstrapResult.OVN.OVNKubernetesConfig.MinimalRBAC = true
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(serviceAccount(objs, "DaemonSet", "ovn-ipsec")).To(Equal("ovn-kubernetes-ipsec"))
+	g.Expect(serviceAccount(objs, "CronJob", "ovnkube-chassis-cleanup")).To(Equal("ovn-kubernetes-chassis-cleanup"))
+	g.Expect(serviceAccount(objs, "CronJob", "ovnkube-db-maintenance")).To(Equal("ovn-kubernetes-jobs"))
+	g.Expect(serviceAccount(objs, "Deployment", "ovnkube-debug")).To(Equal("ovn-kubernetes-debug"))
+	for _, sa := range []string{"ovn-kubernetes-ipsec", "ovn-kubernetes-chassis-cleanup", "ovn-kubernetes-debug", "ovn-kubernetes-jobs"} {
+		g.Expect(objs).To(ContainElement(HaveKubernetesID("ServiceAccount", "openshift-ovn-kubernetes", sa)))
+	}
+	// the chassis cleanup records the unmatched chassis in its ConfigMap
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("Role", "openshift-ovn-kubernetes", "openshift-ovn-kubernetes-chassis-cleanup")))
+
+	node := clusterRole(objs, "openshift-ovn-kubernetes-node")
+	g.Expect(allows(node, "certificatesigningrequests", "create")).To(BeFalse())
+	g.Expect(allows(node, "nodes", "update")).To(BeFalse())
+	g.Expect(allows(node, "nodes", "patch")).To(BeTrue())
+	g.Expect(allows(node, "services", "patch")).To(BeFalse())
+	g.Expect(allows(node, "pods", "patch")).To(BeTrue())
+	g.Expect(allows(clusterRole(objs, "openshift-ovn-kubernetes-ipsec"), "certificatesigningrequests", "delete")).To(BeTrue())
+	g.Expect(allows(clusterRole(objs, "openshift-ovn-kubernetes-controller"), "configmaps", "update")).To(BeFalse())
+
+	// the namespaced permissions of ovnkube-master are applied before its ClusterRole is narrowed down
+	roleIndex, clusterRoleIndex := -1, -1
+	for i, obj := range objs {
+		switch {
+		case obj.GetKind() == "Role" && obj.GetName() == "openshift-ovn-kubernetes-controller":
+			roleIndex = i
+		case obj.GetKind() == "ClusterRole" && obj.GetName() == "openshift-ovn-kubernetes-controller":
+			clusterRoleIndex = i
+		}
+	}
+	g.Expect(roleIndex).To(BeNumerically(">=", 0))
+	g.Expect(roleIndex).To(BeNumerically("<", clusterRoleIndex))
+}
@@ -0,0 +1,135 @@
+package network
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"k8s.io/klog/v2"
+)
+
+// ovnControlPlaneWindow is a weekly window, in UTC, during which only the control plane of
+// ovn-kubernetes is updated. A window ending before it starts ends on the next day.
+type ovnControlPlaneWindow struct {
+	days       [7]bool
+	start, end time.Duration
+}
+
+var ovnWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// bootstrapOVNControlPlaneOnly returns whether now is in one of the control-plane-only windows
+// set by an annotation on the operator configuration, during which the ovnkube-node rollouts
+// are deferred.
+func bootstrapOVNControlPlaneOnly(conf *operv1.Network, now time.Time) bool {
+	v, ok := conf.GetAnnotations()[names.OVNControlPlaneOnlyWindowsAnnotation]
+	if !ok {
+		return false
+	}
+	windows, err := parseOVNControlPlaneWindows(v)
+	if err != nil {
+		klog.Warningf("%s must be a semicolon-separated list of windows like \"Mon-Fri 08:00-18:00\", is: %q (%v). Ignoring it",
+			names.OVNControlPlaneOnlyWindowsAnnotation, v, err)
+		return false
+	}
+	for _, w := range windows {
+		if w.contains(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseOVNControlPlaneWindows parses "[<days>] <HH:MM>-<HH:MM>" windows separated by semicolons,
+// the days being a comma-separated list of days or ranges of days, like "Mon-Fri,Sun"
+func parseOVNControlPlaneWindows(v string) ([]ovnControlPlaneWindow, error) {
+	out := []ovnControlPlaneWindow{}
+	for _, s := range strings.Split(v, ";") {
+		fields := strings.Fields(s)
+		if len(fields) == 0 {
+			continue
+		}
+		w := ovnControlPlaneWindow{}
+		switch len(fields) {
+		case 1:
+			for i := range w.days {
+				w.days[i] = true
+			}
+		case 2:
+			if err := parseOVNWeekdays(fields[0], &w.days); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("invalid window %q", strings.TrimSpace(s))
+		}
+		times := strings.Split(fields[len(fields)-1], "-")
+		if len(times) != 2 {
+			return nil, fmt.Errorf("invalid time range %q", fields[len(fields)-1])
+		}
+		var err error
+		if w.start, err = parseOVNTimeOfDay(times[0]); err != nil {
+			return nil, err
+		}
+		if w.end, err = parseOVNTimeOfDay(times[1]); err != nil {
+			return nil, err
+		}
+		if w.start == w.end {
+			return nil, fmt.Errorf("empty time range %q", fields[len(fields)-1])
+		}
+		out = append(out, w)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no window")
+	}
+	return out, nil
+}
+
+func parseOVNWeekdays(s string, days *[7]bool) error {
+	for _, r := range strings.Split(strings.ToLower(s), ",") {
+		bounds := strings.Split(r, "-")
+		if len(bounds) > 2 {
+			return fmt.Errorf("invalid days %q", r)
+		}
+		first, ok := ovnWeekdays[bounds[0]]
+		if !ok {
+			return fmt.Errorf("invalid day %q", bounds[0])
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, ok = ovnWeekdays[bounds[1]]; !ok {
+				return fmt.Errorf("invalid day %q", bounds[1])
+			}
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func parseOVNTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// contains returns whether t is in the window
+func (w ovnControlPlaneWindow) contains(t time.Time) bool {
+	t = t.UTC()
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	tod := t.Sub(midnight)
+	if w.start < w.end {
+		return w.days[t.Weekday()] && tod >= w.start && tod < w.end
+	}
+	// the window started on the day, or on the day before
+	return (w.days[t.Weekday()] && tod >= w.start) || (w.days[(t.Weekday()+6)%7] && tod < w.end)
+}
@@ -0,0 +1,120 @@
+package network
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestBootstrapOVNControlPlaneOnly(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// 2021-09-06 is a Monday
+	monday := func(hhmm string) time.Time {
+		t, err := time.Parse("2006-01-02 15:04", "2021-09-06 "+hhmm)
+		g.Expect(err).NotTo(HaveOccurred())
+		return t
+	}
+	testCases := []struct {
+		windows  string
+		now      time.Time
+		expected bool
+	}{
+		{"08:00-18:00", monday("08:00"), true},
+		{"08:00-18:00", monday("18:00"), false},
+		{"Mon-Fri 08:00-18:00", monday("12:00"), true},
+		{"Tue-Fri 08:00-18:00", monday("12:00"), false},
+		{"Sat,Sun 00:00-23:59; Fri-Mon 09:00-10:00", monday("09:30"), true},
+		// the windows wrap around the week, and midnight
+		{"Sat-Mon 22:00-02:00", monday("23:00"), true},
+		{"Sun 22:00-02:00", monday("01:00"), true},
+		{"Mon 22:00-02:00", monday("01:00"), false},
+		// the windows are in UTC
+		{"Mon 08:00-09:00", monday("08:30").In(time.FixedZone("UTC+2", 2*3600)), true},
+		// invalid values are ignored
+		{"Mon-Fri", monday("12:00"), false},
+		{"Mon-Fri 08:00-08:00", monday("08:00"), false},
+		{"Monday 08:00-18:00", monday("12:00"), false},
+		{"Mon 8am-6pm", monday("12:00"), false},
+		{"", monday("12:00"), false},
+	}
+	for _, tc := range testCases {
+		conf := OVNKubernetesConfig.DeepCopy()
+		conf.Annotations = map[string]string{names.OVNControlPlaneOnlyWindowsAnnotation: tc.windows}
+		g.Expect(bootstrapOVNControlPlaneOnly(conf, tc.now)).To(Equal(tc.expected), "%q at %s", tc.windows, tc.now)
+	}
+	g.Expect(bootstrapOVNControlPlaneOnly(OVNKubernetesConfig.DeepCopy(), monday("12:00"))).To(BeFalse())
+}
+
+func TestRenderOVNKubernetesControlPlaneOnly(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+	os.Setenv("RELEASE_VERSION", "2.0.0")
+
+	node := &appsv1.DaemonSet{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "DaemonSet"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "ovnkube-node",
+			Namespace:   "openshift-ovn-kubernetes",
+			Annotations: map[string]string{"release.openshift.io/version": "2.0.0", "test/existing": "true"},
+		},
+	}
+	master := node.DeepCopy()
+	master.Name = "ovnkube-master"
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:               []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			ExistingNodeDaemonset:   node,
+			ExistingMasterDaemonset: master,
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{
+				PrePullerMode:    OVN_PREPULLER_MODE_DISABLED,
+				ControlPlaneOnly: true,
+			},
+		},
+	}
+	rendered := func(objs []*uns.Unstructured, name string) bool {
+		ds := findInObjs("apps", "DaemonSet", name, "openshift-ovn-kubernetes", objs)
+		g.Expect(ds).NotTo(BeNil())
+		return ds.GetAnnotations()["test/existing"] == ""
+	}
+
+	// the configuration changes are only rolled out to the masters
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(rendered(objs, "ovnkube-node")).To(BeFalse())
+	g.Expect(rendered(objs, "ovnkube-master")).To(BeTrue())
+	g.Expect(bootstrapResult.Events).To(BeEmpty())
+
+	// the upgrades wait for the window to end, as the nodes are upgraded first
+	node.Annotations["release.openshift.io/version"] = "1.9.9"
+	master.Annotations["release.openshift.io/version"] = "1.9.9"
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(rendered(objs, "ovnkube-node")).To(BeFalse())
+	g.Expect(rendered(objs, "ovnkube-master")).To(BeFalse())
+	g.Expect(bootstrapResult.Events).To(ConsistOf(bootstrap.Event{
+		Type:    v1.EventTypeNormal,
+		Reason:  "NodeRolloutDeferred",
+		Message: "Deferring the ovnkube-node rollout to release 2.0.0 until the control-plane-only window set by networkoperator.openshift.io/ovn-control-plane-only-windows ends",
+	}))
+
+	// and resume once it is over
+	bootstrapResult.Events = nil
+	bootstrapResult.OVN.OVNKubernetesConfig.ControlPlaneOnly = false
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(rendered(objs, "ovnkube-node")).To(BeTrue())
+	g.Expect(rendered(objs, "ovnkube-master")).To(BeFalse())
+	g.Expect(bootstrapResult.Events).To(BeEmpty())
+}
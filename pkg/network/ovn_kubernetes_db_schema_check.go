@@ -0,0 +1,93 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// OVN_DB_SCHEMA_CHECK_JOB is the job checking, before ovnkube-master is upgraded, that the OVN
+// databases can be converted to the schemas of the new release
+const OVN_DB_SCHEMA_CHECK_JOB = "ovnkube-db-schema-check"
+
+// bootstrapOVNDBSchemaCheck fills in the existing schema check job and, when it failed, the reason
+// reported by its pods
+func bootstrapOVNDBSchemaCheck(kubeClient client.Reader, res *bootstrap.OVNBootstrapResult) error {
+	job := &batchv1.Job{}
+	nsn := types.NamespacedName{Namespace: "openshift-ovn-kubernetes", Name: OVN_DB_SCHEMA_CHECK_JOB}
+	if err := kubeClient.Get(context.TODO(), nsn, job); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("Failed to retrieve existing OVN DB schema check Job: %w", err)
+		}
+		return nil
+	}
+	res.DBSchemaCheckJob = job
+	if !jobFinished(job) || jobSucceeded(job) {
+		return nil
+	}
+
+	pods := &corev1.PodList{}
+	if err := kubeClient.List(context.TODO(), pods, client.InNamespace(nsn.Namespace), client.MatchingLabels{"job-name": nsn.Name}); err != nil {
+		return fmt.Errorf("Failed to list the pods of the OVN DB schema check Job: %w", err)
+	}
+	// the reason of the last attempt
+	var finished *corev1.ContainerStateTerminated
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			terminated := status.State.Terminated
+			if terminated == nil || terminated.ExitCode == 0 || terminated.Message == "" {
+				continue
+			}
+			if finished == nil || finished.FinishedAt.Before(&terminated.FinishedAt) {
+				finished = terminated
+			}
+		}
+	}
+	if finished != nil {
+		res.DBSchemaCheckFailure = strings.TrimSpace(finished.Message)
+	}
+	return nil
+}
+
+// shouldUpdateOVNKonDBSchemaCheck determines if the master daemonset can be upgraded to a new release,
+// which is the case once the schema check job of that release has succeeded. A failed job halts the
+// upgrade of the masters until it is deleted, to run it again, so that the databases are not left
+// crash-looping on schemas they cannot be converted to.
+func shouldUpdateOVNKonDBSchemaCheck(existingMaster *appsv1.DaemonSet, check *batchv1.Job, releaseVersion string) (updateMaster, renderCheck, failed bool) {
+	// Fresh cluster, or no new release to roll out
+	if existingMaster == nil || existingMaster.GetAnnotations()["release.openshift.io/version"] == releaseVersion {
+		return true, false, false
+	}
+
+	if check == nil {
+		klog.Infof("Checking the schemas of the OVN databases before updating master")
+		return false, true, false
+	}
+
+	checkVersion := check.GetAnnotations()["release.openshift.io/version"]
+	if checkVersion != releaseVersion {
+		klog.Infof("Removing OVN DB schema check job for release %q before checking %q", checkVersion, releaseVersion)
+		return false, false, false
+	}
+
+	if jobSucceeded(check) {
+		klog.Infof("The OVN databases can be upgraded to release %s", releaseVersion)
+		return true, true, false
+	}
+	if jobFinished(check) {
+		klog.Warningf("The OVN databases cannot be upgraded to release %s, halting master rollout", releaseVersion)
+		return false, true, true
+	}
+
+	klog.Infof("Waiting for the %s job to check the schemas of the OVN databases before updating master", OVN_DB_SCHEMA_CHECK_JOB)
+	return false, true, false
+}
@@ -0,0 +1,163 @@
+package network
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/onsi/gomega"
+)
+
+// ovnDBSchemaCheckJob returns a schema check job of the given release, finished with the given
+// condition, if any
+func ovnDBSchemaCheckJob(releaseVersion string, condition batchv1.JobConditionType) *batchv1.Job {
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+		Name:        OVN_DB_SCHEMA_CHECK_JOB,
+		Namespace:   "openshift-ovn-kubernetes",
+		Annotations: map[string]string{"release.openshift.io/version": releaseVersion},
+	}}
+	if condition != "" {
+		job.Status.Conditions = []batchv1.JobCondition{{Type: condition, Status: corev1.ConditionTrue}}
+	}
+	return job
+}
+
+func TestBootstrapOVNDBSchemaCheck(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	res := &bootstrap.OVNBootstrapResult{}
+	g.Expect(bootstrapOVNDBSchemaCheck(fake.NewClientBuilder().Build(), res)).To(Succeed())
+	g.Expect(res.DBSchemaCheckJob).To(BeNil())
+
+	pod := func(name string, finishedAt time.Time, exitCode int32, message string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "openshift-ovn-kubernetes",
+				Labels:    map[string]string{"job-name": OVN_DB_SCHEMA_CHECK_JOB},
+			},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "check",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					ExitCode:   exitCode,
+					Message:    message,
+					FinishedAt: metav1.NewTime(finishedAt),
+				}},
+			}}},
+		}
+	}
+	now := time.Now()
+	cl := fake.NewClientBuilder().WithObjects(
+		ovnDBSchemaCheckJob("2.0.0", batchv1.JobFailed),
+		pod("check-a", now.Add(-2*time.Minute), 1, "cannot read the schema version of the OVN_Northbound database\n"),
+		pod("check-b", now.Add(-time.Minute), 1, "the OVN_Southbound database has schema version 20.21.0, newer than 20.17.0 of the new image: schemas cannot be downgraded\n"),
+		pod("check-c", now, 0, ""),
+	).Build()
+	res = &bootstrap.OVNBootstrapResult{}
+	g.Expect(bootstrapOVNDBSchemaCheck(cl, res)).To(Succeed())
+	g.Expect(res.DBSchemaCheckJob).NotTo(BeNil())
+	g.Expect(res.DBSchemaCheckFailure).To(Equal("the OVN_Southbound database has schema version 20.21.0, newer than 20.17.0 of the new image: schemas cannot be downgraded"))
+
+	// the reason is only looked up when the job failed
+	cl = fake.NewClientBuilder().WithObjects(
+		ovnDBSchemaCheckJob("2.0.0", ""),
+		pod("check-a", now, 1, "cannot read the schema version of the OVN_Northbound database"),
+	).Build()
+	res = &bootstrap.OVNBootstrapResult{}
+	g.Expect(bootstrapOVNDBSchemaCheck(cl, res)).To(Succeed())
+	g.Expect(res.DBSchemaCheckJob).NotTo(BeNil())
+	g.Expect(res.DBSchemaCheckFailure).To(BeEmpty())
+}
+
+func TestRenderOVNKubernetesDBSchemaCheck(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	defer os.Setenv("RELEASE_VERSION", os.Getenv("RELEASE_VERSION"))
+	os.Setenv("RELEASE_VERSION", "2.0.0")
+
+	daemonSet := func(name, version string) *appsv1.DaemonSet {
+		return &appsv1.DaemonSet{
+			TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "DaemonSet"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "openshift-ovn-kubernetes",
+				Annotations: map[string]string{"release.openshift.io/version": version},
+			},
+			Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberAvailable: 3},
+		}
+	}
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:               []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			ExistingMasterDaemonset: daemonSet("ovnkube-master", "1.9.9"),
+			ExistingNodeDaemonset:   daemonSet("ovnkube-node", "2.0.0"),
+			OVNKubernetesConfig:     &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+	rendered := func() (bool, *batchv1.Job) {
+		bootstrapResult.Events = nil
+		objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+		g.Expect(err).NotTo(HaveOccurred())
+		ds := &appsv1.DaemonSet{}
+		g.Expect(convert(findInObjs("apps", "DaemonSet", "ovnkube-master", "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+		obj := findInObjs("batch", "Job", OVN_DB_SCHEMA_CHECK_JOB, "openshift-ovn-kubernetes", objs)
+		if obj == nil {
+			return len(ds.Spec.Template.Spec.Containers) > 0, nil
+		}
+		job := &batchv1.Job{}
+		g.Expect(convert(obj, job)).To(Succeed())
+		return len(ds.Spec.Template.Spec.Containers) > 0, job
+	}
+
+	// the master is held until the check succeeds
+	updated, job := rendered()
+	g.Expect(updated).To(BeFalse())
+	g.Expect(job).NotTo(BeNil())
+	g.Expect(job.Annotations).To(HaveKeyWithValue("release.openshift.io/version", "2.0.0"))
+	g.Expect(job.Annotations).To(HaveKeyWithValue(names.CreateOnlyAnnotation, "true"))
+	g.Expect(job.Spec.Template.Spec.NodeSelector).To(HaveKey("node-role.kubernetes.io/master"))
+
+	bootstrapResult.OVN.DBSchemaCheckJob = ovnDBSchemaCheckJob("2.0.0", "")
+	updated, job = rendered()
+	g.Expect(updated).To(BeFalse())
+	g.Expect(job).NotTo(BeNil())
+
+	bootstrapResult.OVN.DBSchemaCheckJob = ovnDBSchemaCheckJob("2.0.0", batchv1.JobFailed)
+	bootstrapResult.OVN.DBSchemaCheckFailure = "the OVN_Northbound database has schema version 6.1.0, newer than 5.32.1 of the new image: schemas cannot be downgraded"
+	updated, job = rendered()
+	g.Expect(updated).To(BeFalse())
+	g.Expect(job).NotTo(BeNil())
+	g.Expect(bootstrapResult.Events).To(HaveLen(1))
+	g.Expect(bootstrapResult.Events[0].Type).To(Equal(corev1.EventTypeWarning))
+	g.Expect(bootstrapResult.Events[0].Reason).To(Equal("OVNDBSchemaIncompatible"))
+	g.Expect(bootstrapResult.Events[0].Message).To(HaveSuffix(bootstrapResult.OVN.DBSchemaCheckFailure))
+
+	// the job of another release is removed first
+	bootstrapResult.OVN.DBSchemaCheckJob = ovnDBSchemaCheckJob("1.9.9", batchv1.JobComplete)
+	updated, job = rendered()
+	g.Expect(updated).To(BeFalse())
+	g.Expect(job).To(BeNil())
+
+	bootstrapResult.OVN.DBSchemaCheckJob = ovnDBSchemaCheckJob("2.0.0", batchv1.JobComplete)
+	updated, job = rendered()
+	g.Expect(updated).To(BeTrue())
+	g.Expect(job).NotTo(BeNil())
+
+	// the job is no longer rendered once the master is upgraded
+	bootstrapResult.OVN.ExistingMasterDaemonset = daemonSet("ovnkube-master", "2.0.0")
+	updated, job = rendered()
+	g.Expect(updated).To(BeTrue())
+	g.Expect(job).To(BeNil())
+}
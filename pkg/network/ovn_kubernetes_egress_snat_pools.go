@@ -0,0 +1,129 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	iputil "github.com/openshift/cluster-network-operator/pkg/util/ip"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	utilnet "k8s.io/utils/net"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// bootstrapOVNEgressSNATPools returns the EgressSNATPools, sorted by name, which is the order they are matched
+// against the nodes in, with the CIDRs of each cluster network they apply to. Invalid pools are ignored.
+func bootstrapOVNEgressSNATPools(conf *operv1.Network, kubeClient client.Reader) ([]bootstrap.EgressSNATPool, error) {
+	pools := &netopv1.EgressSNATPoolList{}
+	if err := kubeClient.List(context.TODO(), pools); err != nil {
+		// the CRD may not be installed yet during upgrades
+		if meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Failed to list the EgressSNATPools: %w", err)
+	}
+
+	res := []bootstrap.EgressSNATPool{}
+	for _, pool := range pools.Items {
+		selector, err := metav1.LabelSelectorAsSelector(pool.Spec.NodeSelector)
+		if err != nil {
+			klog.Warningf("EgressSNATPool %s has an invalid nodeSelector. Ignoring it: %v", pool.Name, err)
+			continue
+		}
+		addresses, err := egressSNATPoolAddresses(&conf.Spec, &pool.Spec)
+		if err != nil {
+			klog.Warningf("EgressSNATPool %s is invalid. Ignoring it: %v", pool.Name, err)
+			continue
+		}
+		res = append(res, bootstrap.EgressSNATPool{
+			Name:         pool.Name,
+			NodeSelector: selector.String(),
+			Addresses:    addresses,
+		})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res, nil
+}
+
+// egressSNATPoolAddresses checks the addresses of the pool, and maps the cluster networks it applies to, to
+// the comma-separated CIDRs of their IP family
+func egressSNATPoolAddresses(conf *operv1.NetworkSpec, spec *netopv1.EgressSNATPoolSpec) (map[string]string, error) {
+	reserved := []string{}
+	for _, cn := range conf.ClusterNetwork {
+		reserved = append(reserved, cn.CIDR)
+	}
+	reserved = append(reserved, conf.ServiceNetwork...)
+
+	cidrs := map[utilnet.IPFamily][]string{}
+	for _, address := range spec.Addresses {
+		cidr, err := parseEgressSNATAddress(address)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range reserved {
+			_, rnet, err := net.ParseCIDR(r)
+			if err == nil && iputil.NetsOverlap(*cidr, *rnet) {
+				return nil, fmt.Errorf("%s overlaps the cluster or service network %s", address, r)
+			}
+		}
+		family := cidrFamily(cidr)
+		cidrs[family] = append(cidrs[family], cidr.String())
+	}
+
+	addresses := map[string]string{}
+	for _, cn := range conf.ClusterNetwork {
+		if spec.ClusterNetwork != "" && cn.CIDR != spec.ClusterNetwork {
+			continue
+		}
+		_, cnet, err := net.ParseCIDR(cn.CIDR)
+		if err != nil {
+			continue
+		}
+		family := cidrFamily(cnet)
+		if len(cidrs[family]) == 0 {
+			if spec.ClusterNetwork != "" {
+				return nil, fmt.Errorf("it has no IPv%s address for cluster network %s", family, cn.CIDR)
+			}
+			continue
+		}
+		addresses[cn.CIDR] = strings.Join(cidrs[family], ",")
+	}
+	if len(addresses) == 0 {
+		if spec.ClusterNetwork != "" {
+			return nil, fmt.Errorf("%s is not a cluster network", spec.ClusterNetwork)
+		}
+		return nil, fmt.Errorf("none of its addresses is of the IP family of a cluster network")
+	}
+	return addresses, nil
+}
+
+// parseEgressSNATAddress parses an IP address, as a single address CIDR, or a CIDR
+func parseEgressSNATAddress(address string) (*net.IPNet, error) {
+	if ip := net.ParseIP(address); ip != nil {
+		if ip.To4() != nil {
+			return &net.IPNet{IP: ip.To4(), Mask: net.CIDRMask(32, 32)}, nil
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+	ip, cidr, err := net.ParseCIDR(address)
+	if err != nil || !ip.Equal(cidr.IP) {
+		return nil, fmt.Errorf("%q is neither an IP address nor a CIDR", address)
+	}
+	return cidr, nil
+}
+
+// cidrFamily returns the IP family of a CIDR
+func cidrFamily(cidr *net.IPNet) utilnet.IPFamily {
+	if utilnet.IsIPv6CIDR(cidr) {
+		return utilnet.IPv6
+	}
+	return utilnet.IPv4
+}
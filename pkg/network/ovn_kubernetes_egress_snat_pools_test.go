@@ -0,0 +1,121 @@
+package network
+
+import (
+	"testing"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestBootstrapOVNEgressSNATPools(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	conf := OVNKubernetesConfig.DeepCopy()
+	conf.Spec.ClusterNetwork = []operv1.ClusterNetworkEntry{
+		{CIDR: "10.128.0.0/15", HostPrefix: 23},
+		{CIDR: "10.0.0.0/14", HostPrefix: 24},
+		{CIDR: "fd01::/48", HostPrefix: 64},
+	}
+	conf.Spec.ServiceNetwork = []string{"172.30.0.0/16", "fd02::/112"}
+
+	// the CRD is not installed
+	pools, err := bootstrapOVNEgressSNATPools(conf, fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pools).To(BeEmpty())
+
+	pool := func(name string, spec netopv1.EgressSNATPoolSpec) *netopv1.EgressSNATPool {
+		return &netopv1.EgressSNATPool{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec}
+	}
+	rackA := &metav1.LabelSelector{MatchLabels: map[string]string{"topology.example.com/rack": "a"}}
+	scheme := runtime.NewScheme()
+	g.Expect(netopv1.Install(scheme)).To(Succeed())
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		pool("rack-a", netopv1.EgressSNATPoolSpec{
+			Addresses:      []string{"192.0.2.0/28"},
+			ClusterNetwork: "10.128.0.0/15",
+			NodeSelector:   rackA,
+		}),
+		pool("default", netopv1.EgressSNATPoolSpec{Addresses: []string{"198.51.100.7", "198.51.100.16/28", "2001:db8::1"}}),
+		// not a cluster network
+		pool("other-network", netopv1.EgressSNATPoolSpec{Addresses: []string{"192.0.2.16/28"}, ClusterNetwork: "10.200.0.0/16"}),
+		// no address of the family of the cluster network
+		pool("wrong-family", netopv1.EgressSNATPoolSpec{Addresses: []string{"2001:db8::2"}, ClusterNetwork: "10.0.0.0/14"}),
+		// overlaps the service network
+		pool("overlap", netopv1.EgressSNATPoolSpec{Addresses: []string{"172.30.0.0/24"}}),
+		pool("invalid-address", netopv1.EgressSNATPoolSpec{Addresses: []string{"192.0.2.1/24"}}),
+		pool("invalid-selector", netopv1.EgressSNATPoolSpec{
+			Addresses: []string{"192.0.2.32/28"},
+			NodeSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "topology.example.com/rack", Operator: "Near"},
+			}},
+		}),
+	).Build()
+	pools, err = bootstrapOVNEgressSNATPools(conf, cl)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pools).To(Equal([]bootstrap.EgressSNATPool{
+		{
+			Name: "default",
+			Addresses: map[string]string{
+				"10.128.0.0/15": "198.51.100.7/32,198.51.100.16/28",
+				"10.0.0.0/14":   "198.51.100.7/32,198.51.100.16/28",
+				"fd01::/48":     "2001:db8::1/128",
+			},
+		},
+		{
+			Name:         "rack-a",
+			NodeSelector: "topology.example.com/rack=a",
+			Addresses:    map[string]string{"10.128.0.0/15": "192.0.2.0/28"},
+		},
+	}))
+}
+
+func TestRenderOVNKubernetesEgressSNATPools(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+	nodeScript := func() string {
+		objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+		g.Expect(err).NotTo(HaveOccurred())
+		ds := &appsv1.DaemonSet{}
+		g.Expect(convert(findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+		cont, ok := findContainer(ds.Spec.Template.Spec.Containers, "ovnkube-node")
+		g.Expect(ok).To(BeTrue())
+		return cont.Command[len(cont.Command)-1]
+	}
+
+	g.Expect(nodeScript()).NotTo(ContainSubstring("egress_snat_pool"))
+
+	bootstrapResult.OVN.OVNKubernetesConfig.EgressSNATPools = []bootstrap.EgressSNATPool{
+		{
+			Name:      "default",
+			Addresses: map[string]string{"10.128.0.0/14": "198.51.100.7/32", "fd01::/48": "2001:db8::1/128"},
+		},
+		{
+			Name:         "rack-a",
+			NodeSelector: "topology.example.com/rack=a",
+			Addresses:    map[string]string{"10.128.0.0/14": "192.0.2.0/28"},
+		},
+	}
+	script := nodeScript()
+	g.Expect(script).To(ContainSubstring("matched=all\n"))
+	g.Expect(script).To(ContainSubstring(`kubectl get node "${K8S_NODE}" -l 'topology.example.com/rack=a' -o name`))
+	g.Expect(script).To(ContainSubstring(`egress_snat_pools["10.128.0.0/14"]="198.51.100.7/32"`))
+	g.Expect(script).To(ContainSubstring(`egress_snat_pools["fd01::/48"]="2001:db8::1/128"`))
+	g.Expect(script).To(ContainSubstring(`egress_snat_pools["10.128.0.0/14"]="192.0.2.0/28"`))
+	g.Expect(script).To(ContainSubstring("${egress_snat_pool_flags} \\\n"))
+}
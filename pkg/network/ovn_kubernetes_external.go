@@ -0,0 +1,134 @@
+package network
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The external-ovn-config ConfigMap holds the "nbAddress" and "sbAddress" endpoints of an external OVN
+// deployment, and the external-ovn-cert Secret the client certificate and key of the nodes, along with
+// the CA bundle the databases are verified with. When the ConfigMap exists at install time, the nodes are
+// attached to that deployment, and neither ovnkube-master nor its raft clusters are deployed. The Secret
+// is in the namespace of ovnkube-node, which mounts it as it is.
+const (
+	OVNExternalConfigMapName   = "external-ovn-config"
+	OVNExternalNamespace       = names.APPLIED_NAMESPACE
+	OVNExternalSecretName      = "external-ovn-cert"
+	OVNExternalSecretNamespace = "openshift-ovn-kubernetes"
+)
+
+// bootstrapOVNExternal returns the external OVN deployment the nodes are attached to, if any. The mode
+// is only chosen at install time: it is ignored on a cluster running ovnkube-master, and it can't be
+// left once adopted. An invalid configuration is an error, rather than falling back to ovnkube-master.
+func bootstrapOVNExternal(conf *operv1.Network, kubeClient client.Reader) (*bootstrap.ExternalOVN, error) {
+	adopted := conf.GetAnnotations()[names.OVNExternalAnnotation] == "true"
+
+	cm := &corev1.ConfigMap{}
+	nsn := types.NamespacedName{Namespace: OVNExternalNamespace, Name: OVNExternalConfigMapName}
+	if err := kubeClient.Get(context.TODO(), nsn, cm); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("Failed to retrieve the %s ConfigMap: %w", OVNExternalConfigMapName, err)
+		}
+		if adopted {
+			return nil, fmt.Errorf("The nodes are attached to an external OVN deployment, but the %s ConfigMap was removed", OVNExternalConfigMapName)
+		}
+		return nil, nil
+	}
+
+	if !adopted {
+		exists, err := ovnMasterDaemonSetExists(kubeClient)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			klog.Warningf("%s is only honored at install time, and ovnkube-master is already deployed. Ignoring it", OVNExternalConfigMapName)
+			return nil, nil
+		}
+	}
+
+	external := &bootstrap.ExternalOVN{}
+	var err error
+	if external.NBDBList, err = validateOVNExternalAddress(cm.Data["nbAddress"]); err != nil {
+		return nil, fmt.Errorf("Invalid nbAddress in the %s ConfigMap: %w", OVNExternalConfigMapName, err)
+	}
+	if external.SBDBList, err = validateOVNExternalAddress(cm.Data["sbAddress"]); err != nil {
+		return nil, fmt.Errorf("Invalid sbAddress in the %s ConfigMap: %w", OVNExternalConfigMapName, err)
+	}
+
+	secret := &corev1.Secret{}
+	nsn = types.NamespacedName{Namespace: OVNExternalSecretNamespace, Name: OVNExternalSecretName}
+	if err := kubeClient.Get(context.TODO(), nsn, secret); err != nil {
+		return nil, fmt.Errorf("Failed to retrieve the %s Secret of the external OVN deployment: %w", OVNExternalSecretName, err)
+	}
+	cert := secret.Data[corev1.TLSCertKey]
+	external.CertHash = fmt.Sprintf("%x", sha256.Sum256(cert))
+	external.CABundle = string(secret.Data["ca-bundle.crt"])
+	if _, err := tls.X509KeyPair(cert, secret.Data[corev1.TLSPrivateKeyKey]); err != nil {
+		return nil, fmt.Errorf("Invalid client certificate in the %s Secret: %w", OVNExternalSecretName, err)
+	}
+	if _, err := certutil.ParseCertsPEM([]byte(external.CABundle)); err != nil {
+		return nil, fmt.Errorf("Invalid ca-bundle.crt in the %s Secret: %w", OVNExternalSecretName, err)
+	}
+
+	if !adopted {
+		annotations := conf.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[names.OVNExternalAnnotation] = "true"
+		conf.SetAnnotations(annotations)
+	}
+	return external, nil
+}
+
+// validateOVNExternalAddress checks that address is a comma-separated list of "ssl:<host>:<port>"
+// endpoints, and returns it without spaces. The nodes only reach an external deployment over TLS.
+func validateOVNExternalAddress(address string) (string, error) {
+	endpoints := []string{}
+	for _, endpoint := range strings.Split(address, ",") {
+		endpoint = strings.TrimSpace(endpoint)
+		if endpoint == "" {
+			continue
+		}
+		hostPort := strings.TrimPrefix(endpoint, "ssl:")
+		if hostPort == endpoint {
+			return "", fmt.Errorf("%q must be ssl:<host>:<port>", endpoint)
+		}
+		if host, port, err := net.SplitHostPort(hostPort); err != nil || host == "" || port == "" {
+			return "", fmt.Errorf("%q must be ssl:<host>:<port>", endpoint)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	if len(endpoints) == 0 {
+		return "", fmt.Errorf("no endpoint")
+	}
+	return strings.Join(endpoints, ","), nil
+}
+
+// ovnMasterDaemonSetExists returns whether ovnkube-master is deployed
+func ovnMasterDaemonSetExists(kubeClient client.Reader) (bool, error) {
+	ds := &appsv1.DaemonSet{}
+	nsn := types.NamespacedName{Namespace: "openshift-ovn-kubernetes", Name: "ovnkube-master"}
+	if err := kubeClient.Get(context.TODO(), nsn, ds); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("Failed to retrieve existing master DaemonSet: %w", err)
+	}
+	return true, nil
+}
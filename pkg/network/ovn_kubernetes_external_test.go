@@ -0,0 +1,197 @@
+package network
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	certutil "k8s.io/client-go/util/cert"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestValidateOVNExternalAddress(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	for address, expected := range map[string]string{
+		"ssl:10.0.0.1:6641": "ssl:10.0.0.1:6641",
+		"ssl:ovn-1.example.com:6641, ssl:[fd00::1]:6641": "ssl:ovn-1.example.com:6641,ssl:[fd00::1]:6641",
+		"tcp:10.0.0.1:6641": "",
+		"ssl:10.0.0.1":      "",
+		"":                  "",
+	} {
+		list, err := validateOVNExternalAddress(address)
+		if expected == "" {
+			g.Expect(err).To(HaveOccurred(), address)
+		} else {
+			g.Expect(err).NotTo(HaveOccurred(), address)
+			g.Expect(list).To(Equal(expected))
+		}
+	}
+}
+
+func TestBootstrapOVNExternal(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	cert, key, err := certutil.GenerateSelfSignedCertKey("ovn.example.com", nil, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: OVNExternalNamespace, Name: OVNExternalConfigMapName},
+		Data:       map[string]string{"nbAddress": "ssl:10.0.0.1:6641", "sbAddress": "ssl:10.0.0.1:6642"},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: OVNExternalSecretNamespace, Name: OVNExternalSecretName},
+		Data:       map[string][]byte{"tls.crt": cert, "tls.key": key, "ca-bundle.crt": cert},
+	}
+	masterDS := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ovn-kubernetes", Name: "ovnkube-master"}}
+
+	// ovnkube-master is deployed unless requested otherwise
+	conf := &operv1.Network{}
+	external, err := bootstrapOVNExternal(conf, fake.NewClientBuilder().Build())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(external).To(BeNil())
+
+	// the external deployment is adopted at install time
+	external, err = bootstrapOVNExternal(conf, fake.NewClientBuilder().WithObjects(cm, secret).Build())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(external).To(Equal(&bootstrap.ExternalOVN{
+		NBDBList: "ssl:10.0.0.1:6641",
+		SBDBList: "ssl:10.0.0.1:6642",
+		CertHash: fmt.Sprintf("%x", sha256.Sum256(cert)),
+		CABundle: string(cert),
+	}))
+	g.Expect(conf.Annotations).To(HaveKeyWithValue(names.OVNExternalAnnotation, "true"))
+
+	// and can't be left once adopted
+	_, err = bootstrapOVNExternal(conf, fake.NewClientBuilder().Build())
+	g.Expect(err).To(MatchError(ContainSubstring("ConfigMap was removed")))
+
+	// but not on a cluster running ovnkube-master
+	conf = &operv1.Network{}
+	external, err = bootstrapOVNExternal(conf, fake.NewClientBuilder().WithObjects(cm, secret, masterDS).Build())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(external).To(BeNil())
+	g.Expect(conf.Annotations).NotTo(HaveKey(names.OVNExternalAnnotation))
+
+	// an invalid configuration does not fall back to ovnkube-master
+	for _, objs := range [][]client.Object{
+		{cm},
+		{func() *corev1.ConfigMap { c := cm.DeepCopy(); c.Data["sbAddress"] = "tcp:10.0.0.1:6642"; return c }(), secret},
+		{cm, func() *corev1.Secret { s := secret.DeepCopy(); s.Data["tls.key"] = []byte("garbage"); return s }()},
+		{cm, func() *corev1.Secret { s := secret.DeepCopy(); delete(s.Data, "ca-bundle.crt"); return s }()},
+	} {
+		_, err = bootstrapOVNExternal(&operv1.Network{}, fake.NewClientBuilder().WithObjects(objs...).Build())
+		g.Expect(err).To(HaveOccurred())
+	}
+}
+
+func TestRenderOVNKubernetesExternal(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+			External: &bootstrap.ExternalOVN{
+				NBDBList: "ssl:10.0.0.1:6641",
+				SBDBList: "ssl:10.0.0.1:6642",
+				CertHash: "0123abcd",
+				CABundle: "ca",
+			},
+		},
+	}
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// only the nodes are deployed
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("DaemonSet", "openshift-ovn-kubernetes", "ovnkube-node")))
+	g.Expect(objs).NotTo(ContainElement(HaveKubernetesID("DaemonSet", "openshift-ovn-kubernetes", "ovnkube-master")))
+	g.Expect(objs).NotTo(ContainElement(HaveKubernetesID("OperatorPKI", "openshift-ovn-kubernetes", "ovn")))
+	g.Expect(objs).NotTo(ContainElement(HaveKubernetesID("PrometheusRule", "openshift-ovn-kubernetes", "master-rules")))
+	g.Expect(objs).NotTo(ContainElement(HaveKubernetesID("Service", "openshift-ovn-kubernetes", "ovn-kubernetes-master")))
+
+	// and attached to the external databases, with the certificate provided, which is not copied
+	endpoints := findInObjs("", "ConfigMap", "ovnkube-db-endpoints", "openshift-ovn-kubernetes", objs)
+	g.Expect(endpoints.Object["data"]).To(Equal(map[string]interface{}{"nb": "ssl:10.0.0.1:6641", "sb": "ssl:10.0.0.1:6642"}))
+	for _, obj := range objs {
+		g.Expect(obj.GetKind()).NotTo(Equal("Secret"), obj.GetName())
+	}
+	node := &appsv1.DaemonSet{}
+	g.Expect(convert(findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs), node)).To(Succeed())
+	g.Expect(node.Spec.Template.Annotations).To(HaveKeyWithValue("networkoperator.openshift.io/external-ovn-cert-hash", "0123abcd"))
+	certVolume := false
+	for _, v := range node.Spec.Template.Spec.Volumes {
+		if v.Name == "ovn-cert" {
+			g.Expect(v.Secret.SecretName).To(Equal(OVNExternalSecretName))
+			certVolume = true
+		}
+	}
+	g.Expect(certVolume).To(BeTrue())
+	ca := findInObjs("", "ConfigMap", "ovn-ca", "openshift-ovn-kubernetes", objs)
+	g.Expect(ca.Object["data"]).To(HaveKeyWithValue("ca-bundle.crt", "ca\n"))
+
+	// nor does it need masters in a cluster with an externalized control plane
+	bootstrapResult.Infra.ExternalControlPlane = true
+	_, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	bootstrapResult.OVN.External = nil
+	_, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).To(MatchError(ContainSubstring("external control plane")))
+}
+
+func TestShouldUpdateOVNKNodeOnUpgrade(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	node := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"release.openshift.io/version": "4.8.0"}}}
+
+	// fresh install
+	g.Expect(shouldUpdateOVNKNodeOnUpgrade(nil, "4.9.0", true)).To(BeTrue())
+	// rolled out
+	g.Expect(shouldUpdateOVNKNodeOnUpgrade(node, "4.8.0", true)).To(BeTrue())
+	// the upgrade is only held, there is no master to wait for
+	g.Expect(shouldUpdateOVNKNodeOnUpgrade(node, "4.9.0", true)).To(BeFalse())
+	g.Expect(shouldUpdateOVNKNodeOnUpgrade(node, "4.9.0", false)).To(BeTrue())
+}
+
+func TestRenderOVNKubernetesExternalUpgradeHold(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	t.Setenv("RELEASE_VERSION", "4.9.0")
+	existing := &appsv1.DaemonSet{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "DaemonSet"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ovn-kubernetes", Name: "ovnkube-node",
+			Annotations: map[string]string{"release.openshift.io/version": "4.8.0"}},
+	}
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			OVNKubernetesConfig:   &bootstrap.OVNConfigBoostrapResult{UpgradeHold: true},
+			ExistingNodeDaemonset: existing,
+			External:              &bootstrap.ExternalOVN{NBDBList: "ssl:10.0.0.1:6641", SBDBList: "ssl:10.0.0.1:6642", CABundle: "ca"},
+		},
+	}
+
+	// the upgrade of the nodes is held, although there is no ovnkube-master
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	node := findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs)
+	g.Expect(node.GetAnnotations()).To(HaveKeyWithValue("release.openshift.io/version", "4.8.0"))
+	reasons := []string{}
+	for _, event := range bootstrapResult.Events {
+		reasons = append(reasons, event.Reason)
+	}
+	g.Expect(reasons).To(ContainElement("UpgradeHeld"))
+}
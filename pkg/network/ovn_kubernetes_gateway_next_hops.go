@@ -0,0 +1,101 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The ovn-gateway-next-hops ConfigMap sets the next hops of the external gateway of groups of nodes, for the
+// clusters which nodes do not share a gateway, such as leaf-spine datacenters with a gateway per rack. Each key
+// names a node group, and holds its nodeSelector and nextHops, in YAML.
+const (
+	OVNGatewayNextHopsConfigMapName      = "ovn-gateway-next-hops"
+	OVNGatewayNextHopsConfigMapNamespace = names.APPLIED_NAMESPACE
+)
+
+// gatewayNextHopsGroup is a node group of the ovn-gateway-next-hops ConfigMap
+type gatewayNextHopsGroup struct {
+	NodeSelector map[string]string `json:"nodeSelector"`
+	NextHops     []string          `json:"nextHops"`
+}
+
+// bootstrapOVNGatewayNextHops returns the node groups of the ovn-gateway-next-hops ConfigMap, sorted by name,
+// which is the order they are matched against the nodes in. Invalid groups are ignored.
+func bootstrapOVNGatewayNextHops(kubeClient client.Reader) ([]bootstrap.GatewayNextHops, error) {
+	cm := &corev1.ConfigMap{}
+	nsn := types.NamespacedName{Namespace: OVNGatewayNextHopsConfigMapNamespace, Name: OVNGatewayNextHopsConfigMapName}
+	if err := kubeClient.Get(context.TODO(), nsn, cm); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("Failed to retrieve the %s configmap: %w", OVNGatewayNextHopsConfigMapName, err)
+		}
+		return nil, nil
+	}
+
+	groups := []bootstrap.GatewayNextHops{}
+	for name, data := range cm.Data {
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			klog.Warningf("%s: %q is not a valid node group name. Ignoring it: %s",
+				OVNGatewayNextHopsConfigMapName, name, strings.Join(errs, ", "))
+			continue
+		}
+		group := &gatewayNextHopsGroup{}
+		if err := yaml.Unmarshal([]byte(data), group); err != nil {
+			klog.Warningf("%s: %s is not a valid node group. Ignoring it: %v", OVNGatewayNextHopsConfigMapName, name, err)
+			continue
+		}
+		if len(group.NodeSelector) == 0 {
+			klog.Warningf("%s: %s has no nodeSelector. Ignoring it", OVNGatewayNextHopsConfigMapName, name)
+			continue
+		}
+		selector, err := labels.ValidatedSelectorFromSet(group.NodeSelector)
+		if err != nil {
+			klog.Warningf("%s: %s has an invalid nodeSelector. Ignoring it: %v", OVNGatewayNextHopsConfigMapName, name, err)
+			continue
+		}
+		if err := validateGatewayNextHops(group.NextHops); err != nil {
+			klog.Warningf("%s: %s has invalid nextHops. Ignoring it: %v", OVNGatewayNextHopsConfigMapName, name, err)
+			continue
+		}
+		groups = append(groups, bootstrap.GatewayNextHops{
+			Name:         name,
+			NodeSelector: selector.String(),
+			NextHops:     strings.Join(group.NextHops, ","),
+		})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups, nil
+}
+
+// validateGatewayNextHops checks that the next hops are IP addresses, one per IP family
+func validateGatewayNextHops(nextHops []string) error {
+	if len(nextHops) == 0 || len(nextHops) > 2 {
+		return fmt.Errorf("expected one next hop per IP family, got %d", len(nextHops))
+	}
+	families := map[bool]bool{}
+	for _, nextHop := range nextHops {
+		ip := net.ParseIP(nextHop)
+		if ip == nil {
+			return fmt.Errorf("%q is not an IP address", nextHop)
+		}
+		isIPv4 := ip.To4() != nil
+		if families[isIPv4] {
+			return fmt.Errorf("expected one next hop per IP family, got %s", strings.Join(nextHops, ", "))
+		}
+		families[isIPv4] = true
+	}
+	return nil
+}
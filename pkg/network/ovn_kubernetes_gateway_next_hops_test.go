@@ -0,0 +1,86 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestBootstrapOVNGatewayNextHops(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	groups, err := bootstrapOVNGatewayNextHops(fake.NewClientBuilder().Build())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(groups).To(BeEmpty())
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: OVNGatewayNextHopsConfigMapName, Namespace: OVNGatewayNextHopsConfigMapNamespace},
+		Data: map[string]string{
+			"rack-b": `
+nodeSelector:
+  topology.example.com/rack: b
+nextHops: [10.0.2.1, "fd00:2::1"]
+`,
+			"rack-a": `
+nodeSelector:
+  topology.example.com/rack: a
+  node-role.kubernetes.io/worker: ""
+nextHops: [10.0.1.1]
+`,
+			// no nodeSelector
+			"rack-c": `nextHops: [10.0.3.1]`,
+			// two IPv4 next hops
+			"rack-d": `{"nodeSelector": {"topology.example.com/rack": "d"}, "nextHops": ["10.0.4.1", "10.0.4.2"]}`,
+			"rack-e": `{"nodeSelector": {"topology.example.com/rack": "e"}, "nextHops": ["gateway"]}`,
+			"rack-f": `{"nodeSelector": {"topology.example.com/rack": "not a label value"}, "nextHops": ["10.0.6.1"]}`,
+			"Rack_G": `{"nodeSelector": {"topology.example.com/rack": "g"}, "nextHops": ["10.0.7.1"]}`,
+		},
+	}
+	groups, err = bootstrapOVNGatewayNextHops(fake.NewClientBuilder().WithObjects(cm).Build())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(groups).To(Equal([]bootstrap.GatewayNextHops{
+		{Name: "rack-a", NodeSelector: "node-role.kubernetes.io/worker=,topology.example.com/rack=a", NextHops: "10.0.1.1"},
+		{Name: "rack-b", NodeSelector: "topology.example.com/rack=b", NextHops: "10.0.2.1,fd00:2::1"},
+	}))
+}
+
+func TestRenderOVNKubernetesGatewayNextHops(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+	nodeScript := func() string {
+		objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+		g.Expect(err).NotTo(HaveOccurred())
+		ds := &appsv1.DaemonSet{}
+		g.Expect(convert(findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+		cont, ok := findContainer(ds.Spec.Template.Spec.Containers, "ovnkube-node")
+		g.Expect(ok).To(BeTrue())
+		return cont.Command[len(cont.Command)-1]
+	}
+
+	g.Expect(nodeScript()).NotTo(ContainSubstring("--gateway-nexthop"))
+
+	bootstrapResult.OVN.OVNKubernetesConfig.GatewayNextHops = []bootstrap.GatewayNextHops{
+		{Name: "rack-a", NodeSelector: "topology.example.com/rack=a", NextHops: "10.0.1.1"},
+		{Name: "rack-b", NodeSelector: "topology.example.com/rack=b", NextHops: "10.0.2.1,fd00:2::1"},
+	}
+	script := nodeScript()
+	g.Expect(script).To(ContainSubstring(`kubectl get node "${K8S_NODE}" -l 'topology.example.com/rack=a' -o name`))
+	g.Expect(script).To(ContainSubstring(`gateway_nexthop_flag="--gateway-nexthop 10.0.1.1"`))
+	g.Expect(script).To(ContainSubstring(`gateway_nexthop_flag="--gateway-nexthop 10.0.2.1,fd00:2::1"`))
+	g.Expect(script).To(ContainSubstring("${gateway_nexthop_flag} \\\n"))
+}
@@ -0,0 +1,231 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+	utilnet "k8s.io/utils/net"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// the IPsec modes: Internal encrypts the traffic between the nodes, Full also encrypts the traffic of the nodes
+// to the IPsecExternalGateways
+const (
+	IPSEC_MODE_INTERNAL = "Internal"
+	IPSEC_MODE_FULL     = "Full"
+)
+
+// the UDP encapsulation of the ESP packets: Auto when a NAT is detected, Always to force it
+const (
+	IPSEC_ENCAPSULATION_AUTO   = "Auto"
+	IPSEC_ENCAPSULATION_ALWAYS = "Always"
+)
+
+// the bounds of the rekey intervals of the IKE and IPsec SAs, in seconds. libreswan caps both at a day.
+const (
+	IPSEC_MIN_LIFETIME = 60
+	IPSEC_MAX_LIFETIME = 86400
+)
+
+// the algorithms of the IPsec proposals, by whether they are FIPS-approved
+var (
+	ipsecEncryptionAlgorithms = map[string]bool{
+		"aes_gcm128": true, "aes_gcm192": true, "aes_gcm256": true,
+		"aes128": true, "aes192": true, "aes256": true,
+		"aes_ctr128": true, "aes_ctr192": true, "aes_ctr256": true,
+		"chacha20_poly1305": false,
+	}
+	ipsecIntegrityAlgorithms = map[string]bool{
+		"sha2_256": true, "sha2_384": true, "sha2_512": true,
+		"sha1": false,
+	}
+	ipsecDHGroups = map[string]bool{
+		"dh14": true, "dh15": true, "dh16": true, "dh18": true,
+		"dh19": true, "dh20": true, "dh21": true,
+		"dh31": false,
+	}
+)
+
+// bootstrapIPsecMode returns the requested IPsec mode, Internal by default
+func bootstrapIPsecMode(conf *operv1.Network) string {
+	mode, ok := conf.GetAnnotations()[names.IPsecModeAnnotation]
+	if !ok {
+		return IPSEC_MODE_INTERNAL
+	}
+	switch mode {
+	case IPSEC_MODE_INTERNAL, IPSEC_MODE_FULL:
+		if conf.Spec.DefaultNetwork.OVNKubernetesConfig.IPsecConfig == nil {
+			klog.Warningf("%s is set, but IPsec is not enabled. Ignoring it", names.IPsecModeAnnotation)
+			return IPSEC_MODE_INTERNAL
+		}
+		return mode
+	default:
+		klog.Warningf("%s does not match %q or %q, is: %q. Using IPsec mode: %s",
+			names.IPsecModeAnnotation, IPSEC_MODE_INTERNAL, IPSEC_MODE_FULL, mode, IPSEC_MODE_INTERNAL)
+		return IPSEC_MODE_INTERNAL
+	}
+}
+
+// bootstrapIPsecExternalGateways returns the IPsecExternalGateways, sorted by name, in the Full IPsec mode.
+// Invalid gateways are ignored.
+func bootstrapIPsecExternalGateways(mode string, kubeClient client.Reader) ([]bootstrap.IPsecExternalGateway, error) {
+	if mode != IPSEC_MODE_FULL {
+		return nil, nil
+	}
+	gateways := &netopv1.IPsecExternalGatewayList{}
+	if err := kubeClient.List(context.TODO(), gateways); err != nil {
+		// the CRD may not be installed yet during upgrades
+		if meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Failed to list the IPsecExternalGateways: %w", err)
+	}
+
+	res := []bootstrap.IPsecExternalGateway{}
+	for _, gateway := range gateways.Items {
+		if err := validateIPsecExternalGateway(&gateway.Spec); err != nil {
+			klog.Warningf("IPsecExternalGateway %s is invalid. Ignoring it: %v", gateway.Name, err)
+			continue
+		}
+		res = append(res, bootstrap.IPsecExternalGateway{
+			Name:          gateway.Name,
+			Address:       gateway.Spec.Address,
+			Subnets:       strings.Join(gateway.Spec.Subnets, " "),
+			PSKSecretName: gateway.Spec.PSKSecretName,
+		})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res, nil
+}
+
+// validateIPsecExternalGateway checks that the address and subnets of the gateway are of the same IP family,
+// and that its key is in a valid Secret name
+func validateIPsecExternalGateway(spec *netopv1.IPsecExternalGatewaySpec) error {
+	address := net.ParseIP(spec.Address)
+	if address == nil {
+		return fmt.Errorf("address %q is not an IP address", spec.Address)
+	}
+	for _, subnet := range spec.Subnets {
+		_, cidr, err := net.ParseCIDR(subnet)
+		if err != nil {
+			return fmt.Errorf("subnet %q is not a CIDR", subnet)
+		}
+		if utilnet.IsIPv6(address) != utilnet.IsIPv6CIDR(cidr) {
+			return fmt.Errorf("subnet %s is not of the IP family of the address %s", subnet, spec.Address)
+		}
+	}
+	if errs := validation.IsDNS1123Subdomain(spec.PSKSecretName); len(errs) > 0 {
+		return fmt.Errorf("pskSecretName %q is not a valid Secret name: %s", spec.PSKSecretName, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// bootstrapIPsecProposals returns the IKEv2 and ESP proposals of the connections to the IPsecExternalGateways,
+// or empty strings to keep the libreswan defaults. The proposals must only use FIPS-approved algorithms on FIPS
+// clusters, which ValidateFIPS checks.
+func bootstrapIPsecProposals(conf *operv1.Network) (string, string) {
+	proposals := func(annotation string) string {
+		v, ok := conf.GetAnnotations()[annotation]
+		if !ok {
+			return ""
+		}
+		if err := validateIPsecProposals(v); err != nil {
+			klog.Warningf("%s must be comma-separated proposals of libreswan, is: %q. Ignoring it: %v", annotation, v, err)
+			return ""
+		}
+		return v
+	}
+	return proposals(names.IPsecIKEAnnotation), proposals(names.IPsecESPAnnotation)
+}
+
+// validateIPsecProposals checks that each proposal is an encryption algorithm, optionally followed by an
+// integrity algorithm and a DH group
+func validateIPsecProposals(v string) error {
+	for _, proposal := range strings.Split(v, ",") {
+		parts := strings.Split(proposal, "-")
+		if _, ok := ipsecEncryptionAlgorithms[parts[0]]; !ok {
+			return fmt.Errorf("unsupported encryption algorithm %q", parts[0])
+		}
+		parts = parts[1:]
+		if len(parts) > 0 {
+			if _, ok := ipsecIntegrityAlgorithms[parts[0]]; ok {
+				parts = parts[1:]
+			}
+		}
+		if len(parts) > 0 {
+			if _, ok := ipsecDHGroups[parts[0]]; !ok {
+				return fmt.Errorf("unsupported integrity algorithm or DH group %q", parts[0])
+			}
+			parts = parts[1:]
+		}
+		if len(parts) > 0 {
+			return fmt.Errorf("unexpected %q in proposal %s", strings.Join(parts, "-"), proposal)
+		}
+	}
+	return nil
+}
+
+// nonFIPSIPsecAlgorithms returns the algorithms of valid proposals that are not FIPS-approved
+func nonFIPSIPsecAlgorithms(v string) []string {
+	res := []string{}
+	if v == "" {
+		return res
+	}
+	for _, proposal := range strings.Split(v, ",") {
+		for _, algorithm := range strings.Split(proposal, "-") {
+			if !ipsecEncryptionAlgorithms[algorithm] && !ipsecIntegrityAlgorithms[algorithm] && !ipsecDHGroups[algorithm] {
+				res = append(res, algorithm)
+			}
+		}
+	}
+	return res
+}
+
+// bootstrapIPsecLifetimes returns the rekey intervals, in seconds, of the IKE and IPsec SAs of the connections
+// to the IPsecExternalGateways, or zero to keep the libreswan defaults
+func bootstrapIPsecLifetimes(conf *operv1.Network) (int, int) {
+	lifetime := func(annotation string) int {
+		v, ok := conf.GetAnnotations()[annotation]
+		if !ok {
+			return 0
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < IPSEC_MIN_LIFETIME || n > IPSEC_MAX_LIFETIME {
+			klog.Warningf("%s must be a number of seconds between %d and %d, is: %q. Ignoring it",
+				annotation, IPSEC_MIN_LIFETIME, IPSEC_MAX_LIFETIME, v)
+			return 0
+		}
+		return n
+	}
+	return lifetime(names.IPsecIKELifetimeAnnotation), lifetime(names.IPsecSALifetimeAnnotation)
+}
+
+// bootstrapIPsecEncapsulation returns whether the UDP encapsulation of the ESP packets is forced
+func bootstrapIPsecEncapsulation(conf *operv1.Network) bool {
+	v, ok := conf.GetAnnotations()[names.IPsecEncapsulationAnnotation]
+	if !ok {
+		return false
+	}
+	switch v {
+	case IPSEC_ENCAPSULATION_AUTO:
+		return false
+	case IPSEC_ENCAPSULATION_ALWAYS:
+		return true
+	default:
+		klog.Warningf("%s does not match %q or %q, is: %q. Using encapsulation: %s",
+			names.IPsecEncapsulationAnnotation, IPSEC_ENCAPSULATION_AUTO, IPSEC_ENCAPSULATION_ALWAYS, v, IPSEC_ENCAPSULATION_AUTO)
+		return false
+	}
+}
@@ -0,0 +1,225 @@
+package network
+
+import (
+	"testing"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestBootstrapIPsecMode(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	conf := &operv1.Network{Spec: operv1.NetworkSpec{DefaultNetwork: operv1.DefaultNetworkDefinition{
+		OVNKubernetesConfig: &operv1.OVNKubernetesConfig{IPsecConfig: &operv1.IPsecConfig{}},
+	}}}
+	g.Expect(bootstrapIPsecMode(conf)).To(Equal(IPSEC_MODE_INTERNAL))
+	conf.Annotations = map[string]string{names.IPsecModeAnnotation: "Full"}
+	g.Expect(bootstrapIPsecMode(conf)).To(Equal(IPSEC_MODE_FULL))
+	conf.Annotations[names.IPsecModeAnnotation] = "full"
+	g.Expect(bootstrapIPsecMode(conf)).To(Equal(IPSEC_MODE_INTERNAL))
+
+	// IPsec is not enabled
+	conf.Annotations[names.IPsecModeAnnotation] = "Full"
+	conf.Spec.DefaultNetwork.OVNKubernetesConfig.IPsecConfig = nil
+	g.Expect(bootstrapIPsecMode(conf)).To(Equal(IPSEC_MODE_INTERNAL))
+}
+
+func TestBootstrapIPsecExternalGateways(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// the CRD is not installed
+	gateways, err := bootstrapIPsecExternalGateways(IPSEC_MODE_FULL, fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(gateways).To(BeEmpty())
+
+	gateway := func(name string, spec netopv1.IPsecExternalGatewaySpec) *netopv1.IPsecExternalGateway {
+		return &netopv1.IPsecExternalGateway{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec}
+	}
+	scheme := runtime.NewScheme()
+	g.Expect(netopv1.Install(scheme)).To(Succeed())
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		gateway("datacenter", netopv1.IPsecExternalGatewaySpec{
+			Address:       "192.0.2.1",
+			Subnets:       []string{"198.51.100.0/24", "203.0.113.0/24"},
+			PSKSecretName: "datacenter-psk",
+		}),
+		gateway("backup-server", netopv1.IPsecExternalGatewaySpec{Address: "2001:db8::10", PSKSecretName: "backup-psk"}),
+		gateway("bad-address", netopv1.IPsecExternalGatewaySpec{Address: "gw.example.com", PSKSecretName: "psk"}),
+		gateway("mixed-families", netopv1.IPsecExternalGatewaySpec{
+			Address:       "192.0.2.1",
+			Subnets:       []string{"2001:db8::/64"},
+			PSKSecretName: "psk",
+		}),
+		gateway("bad-secret", netopv1.IPsecExternalGatewaySpec{Address: "192.0.2.2", PSKSecretName: "My_PSK"}),
+	).Build()
+	gateways, err = bootstrapIPsecExternalGateways(IPSEC_MODE_FULL, cl)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(gateways).To(Equal([]bootstrap.IPsecExternalGateway{
+		{Name: "backup-server", Address: "2001:db8::10", PSKSecretName: "backup-psk"},
+		{Name: "datacenter", Address: "192.0.2.1", Subnets: "198.51.100.0/24 203.0.113.0/24", PSKSecretName: "datacenter-psk"},
+	}))
+
+	// the gateways are only used in the Full mode
+	gateways, err = bootstrapIPsecExternalGateways(IPSEC_MODE_INTERNAL, cl)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(gateways).To(BeEmpty())
+}
+
+func TestBootstrapIPsecProposals(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	conf := &operv1.Network{}
+	ike, esp := bootstrapIPsecProposals(conf)
+	g.Expect(ike).To(BeEmpty())
+	g.Expect(esp).To(BeEmpty())
+
+	conf.Annotations = map[string]string{
+		names.IPsecIKEAnnotation: "aes_gcm256-sha2_512-dh20,chacha20_poly1305-sha2_256-dh31",
+		names.IPsecESPAnnotation: "aes_gcm256,aes256-sha2_256-dh19",
+	}
+	ike, esp = bootstrapIPsecProposals(conf)
+	g.Expect(ike).To(Equal("aes_gcm256-sha2_512-dh20,chacha20_poly1305-sha2_256-dh31"))
+	g.Expect(esp).To(Equal("aes_gcm256,aes256-sha2_256-dh19"))
+
+	for _, v := range []string{"", "des", "aes256-md5", "aes256-dh2", "aes256-sha2_256-dh19-dh20", "aes256-dh19-sha2_256", "aes256,"} {
+		g.Expect(validateIPsecProposals(v)).NotTo(Succeed(), v)
+	}
+
+	// chacha20_poly1305 and curve25519 are not FIPS-approved
+	g.Expect(nonFIPSIPsecAlgorithms(ike)).To(Equal([]string{"chacha20_poly1305", "dh31"}))
+	g.Expect(nonFIPSIPsecAlgorithms(esp)).To(BeEmpty())
+	g.Expect(nonFIPSIPsecAlgorithms("")).To(BeEmpty())
+}
+
+func TestBootstrapIPsecLifetimesAndEncapsulation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	conf := &operv1.Network{}
+	ikeLifetime, saLifetime := bootstrapIPsecLifetimes(conf)
+	g.Expect(ikeLifetime).To(BeZero())
+	g.Expect(saLifetime).To(BeZero())
+	g.Expect(bootstrapIPsecEncapsulation(conf)).To(BeFalse())
+
+	conf.Annotations = map[string]string{
+		names.IPsecIKELifetimeAnnotation:   "28800",
+		names.IPsecSALifetimeAnnotation:    "3600",
+		names.IPsecEncapsulationAnnotation: "Always",
+	}
+	ikeLifetime, saLifetime = bootstrapIPsecLifetimes(conf)
+	g.Expect(ikeLifetime).To(Equal(28800))
+	g.Expect(saLifetime).To(Equal(3600))
+	g.Expect(bootstrapIPsecEncapsulation(conf)).To(BeTrue())
+
+	conf.Annotations = map[string]string{
+		names.IPsecIKELifetimeAnnotation:   "30",
+		names.IPsecSALifetimeAnnotation:    "1h",
+		names.IPsecEncapsulationAnnotation: "yes",
+	}
+	ikeLifetime, saLifetime = bootstrapIPsecLifetimes(conf)
+	g.Expect(ikeLifetime).To(BeZero())
+	g.Expect(saLifetime).To(BeZero())
+	g.Expect(bootstrapIPsecEncapsulation(conf)).To(BeFalse())
+}
+
+func TestRenderIPsecEncapsulation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	config.DefaultNetwork.OVNKubernetesConfig.IPsecConfig = &operv1.IPsecConfig{}
+	FillDefaults(config, nil)
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+	masterJSON := func() string {
+		objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+		g.Expect(err).NotTo(HaveOccurred())
+		j, err := findInObjs("apps", "DaemonSet", "ovnkube-master", "openshift-ovn-kubernetes", objs).MarshalJSON()
+		g.Expect(err).NotTo(HaveOccurred())
+		return string(j)
+	}
+
+	g.Expect(masterJSON()).To(ContainSubstring("remove nb_global . options ipsec_encapsulation"))
+	bootstrapResult.OVN.OVNKubernetesConfig.IPsecForceEncapsulation = true
+	g.Expect(masterJSON()).To(ContainSubstring("set nb_global . options:ipsec_encapsulation=true"))
+}
+
+func TestRenderIPsecExternalGateways(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	config.DefaultNetwork.OVNKubernetesConfig.IPsecConfig = &operv1.IPsecConfig{}
+	FillDefaults(config, nil)
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+	renderIPsec := func() *appsv1.DaemonSet {
+		objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+		g.Expect(err).NotTo(HaveOccurred())
+		ds := &appsv1.DaemonSet{}
+		g.Expect(convert(findInObjs("apps", "DaemonSet", "ovn-ipsec", "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+		return ds
+	}
+
+	ds := renderIPsec()
+	cont, ok := findContainer(ds.Spec.Template.Spec.Containers, "ovn-ipsec")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(cont.Command[len(cont.Command)-1]).NotTo(ContainSubstring("openshift-external"))
+
+	bootstrapResult.OVN.OVNKubernetesConfig.IPsecExternalGateways = []bootstrap.IPsecExternalGateway{
+		{Name: "backup-server", Address: "2001:db8::10", PSKSecretName: "backup-psk"},
+		{Name: "datacenter", Address: "192.0.2.1", Subnets: "198.51.100.0/24 203.0.113.0/24", PSKSecretName: "datacenter-psk"},
+	}
+	ds = renderIPsec()
+	cont, ok = findContainer(ds.Spec.Template.Spec.Containers, "ovn-ipsec")
+	g.Expect(ok).To(BeTrue())
+	script := cont.Command[len(cont.Command)-1]
+	g.Expect(script).To(ContainSubstring("conn external-backup-server\n    left=%defaultroute\n    right=2001:db8::10\n    type=transport\n"))
+	g.Expect(script).To(ContainSubstring("conn external-datacenter\n    left=%defaultroute\n    right=192.0.2.1\n    type=tunnel\n" +
+		"    rightsubnets={198.51.100.0/24 203.0.113.0/24}\n    authby=secret\n    ikev2=insist\n    auto=start\nEOF\n"))
+	g.Expect(script).To(ContainSubstring(`echo "%any 192.0.2.1 : PSK 0s$(base64 -w0 /etc/ipsec-external/1/psk)" >> /etc/ipsec.d/openshift-external.secrets`))
+	g.Expect(script).To(ContainSubstring("addconn --config /etc/ipsec.d/openshift-external.conf --autoall"))
+	g.Expect(script).NotTo(ContainSubstring("ike="))
+
+	bootstrapResult.OVN.OVNKubernetesConfig.IPsecIKE = "aes_gcm256-sha2_512-dh20"
+	bootstrapResult.OVN.OVNKubernetesConfig.IPsecESP = "aes_gcm256"
+	bootstrapResult.OVN.OVNKubernetesConfig.IPsecIKELifetime = 28800
+	bootstrapResult.OVN.OVNKubernetesConfig.IPsecSALifetime = 3600
+	bootstrapResult.OVN.OVNKubernetesConfig.IPsecForceEncapsulation = true
+	ds = renderIPsec()
+	cont, ok = findContainer(ds.Spec.Template.Spec.Containers, "ovn-ipsec")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(cont.Command[len(cont.Command)-1]).To(ContainSubstring("    authby=secret\n    ikev2=insist\n" +
+		"    ike=aes_gcm256-sha2_512-dh20\n    esp=aes_gcm256\n    ikelifetime=28800s\n    salifetime=3600s\n" +
+		"    encapsulation=yes\n    auto=start\n"))
+
+	g.Expect(cont.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+		Name:      "ipsec-external-0",
+		MountPath: "/etc/ipsec-external/0",
+		ReadOnly:  true,
+	}))
+	secrets := map[string]string{}
+	for _, volume := range ds.Spec.Template.Spec.Volumes {
+		if volume.Secret != nil {
+			secrets[volume.Name] = volume.Secret.SecretName
+		}
+	}
+	g.Expect(secrets).To(Equal(map[string]string{"ipsec-external-0": "backup-psk", "ipsec-external-1": "datacenter-psk"}))
+}
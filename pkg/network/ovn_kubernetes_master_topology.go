@@ -0,0 +1,61 @@
+package network
+
+import (
+	"sort"
+	"strings"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+)
+
+// bootstrapOVNMasterTopology validates the topology key the OVN masters are spread across, as set by
+// an annotation on the operator configuration, against the master nodes found. The masters are only
+// constrained to one per topology domain when each of them is labelled with the key, in a distinct
+// domain: otherwise the constraint would keep a member of the raft clusters from being scheduled.
+func bootstrapOVNMasterTopology(conf *operv1.Network, masterNodes []corev1.Node, res *bootstrap.BootstrapResult) {
+	key, ok := conf.GetAnnotations()[names.OVNMasterTopologyKeyAnnotation]
+	if !ok {
+		return
+	}
+	if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+		klog.Warningf("%s must be a node label key, is: %q. Ignoring it", names.OVNMasterTopologyKeyAnnotation, key)
+		return
+	}
+
+	unlabelled := []string{}
+	shared := map[string][]string{}
+	for _, node := range masterNodes {
+		domain, ok := node.GetLabels()[key]
+		if !ok {
+			unlabelled = append(unlabelled, node.Name)
+			continue
+		}
+		shared[domain] = append(shared[domain], node.Name)
+	}
+	if len(unlabelled) > 0 {
+		sort.Strings(unlabelled)
+		res.RecordEvent(corev1.EventTypeWarning, "MasterTopologyIgnored",
+			"The OVN masters are not spread across %s, master nodes %s have no such label", key, strings.Join(unlabelled, ", "))
+		return
+	}
+
+	domains := make([]string, 0, len(shared))
+	for domain := range shared {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+	for _, domain := range domains {
+		if nodes := shared[domain]; len(nodes) > 1 {
+			sort.Strings(nodes)
+			res.RecordEvent(corev1.EventTypeWarning, "MasterTopologyIgnored",
+				"The OVN masters are not spread across %s, master nodes %s are all in %s", key, strings.Join(nodes, ", "), domain)
+			return
+		}
+	}
+	klog.Infof("OVN-Kubernetes masters are spread across %s: %v", key, domains)
+	res.OVN.MasterTopologyKey = key
+}
@@ -0,0 +1,98 @@
+package network
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBootstrapOVNMasterTopology(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	masters := func(zones ...string) []v1.Node {
+		nodes := []v1.Node{}
+		for i, zone := range zones {
+			node := v1.Node{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("master-%d", i), Labels: map[string]string{}}}
+			if zone != "" {
+				node.Labels["topology.kubernetes.io/zone"] = zone
+			}
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+	testCases := []struct {
+		key      string
+		nodes    []v1.Node
+		expected string
+		event    string
+	}{
+		{"topology.kubernetes.io/zone", masters("a", "b", "c"), "topology.kubernetes.io/zone", ""},
+		{"topology.kubernetes.io/zone", masters("a"), "topology.kubernetes.io/zone", ""},
+		// the masters do not match the constraint
+		{"topology.kubernetes.io/zone", masters("a", "b", "a"), "",
+			"The OVN masters are not spread across topology.kubernetes.io/zone, master nodes master-0, master-2 are all in a"},
+		{"topology.kubernetes.io/zone", masters("a", "", "c"), "",
+			"The OVN masters are not spread across topology.kubernetes.io/zone, master nodes master-1 have no such label"},
+		// invalid keys are ignored
+		{"topology.kubernetes.io/zone=a", masters("a", "b", "c"), "", ""},
+		{"", masters("a", "b", "c"), "", ""},
+	}
+	for _, tc := range testCases {
+		conf := OVNKubernetesConfig.DeepCopy()
+		conf.Annotations = map[string]string{names.OVNMasterTopologyKeyAnnotation: tc.key}
+		res := &bootstrap.BootstrapResult{}
+		bootstrapOVNMasterTopology(conf, tc.nodes, res)
+		g.Expect(res.OVN.MasterTopologyKey).To(Equal(tc.expected), "%q", tc.key)
+		if tc.event == "" {
+			g.Expect(res.Events).To(BeEmpty())
+		} else {
+			g.Expect(res.Events).To(ConsistOf(bootstrap.Event{Type: v1.EventTypeWarning, Reason: "MasterTopologyIgnored", Message: tc.event}))
+		}
+	}
+
+	res := &bootstrap.BootstrapResult{}
+	bootstrapOVNMasterTopology(OVNKubernetesConfig.DeepCopy(), masters("a", "b", "c"), res)
+	g.Expect(res.OVN.MasterTopologyKey).To(BeEmpty())
+}
+
+func TestRenderOVNKubernetesMasterTopology(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+
+	renderedMaster := func() *appsv1.DaemonSet {
+		objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+		g.Expect(err).NotTo(HaveOccurred())
+		ds := &appsv1.DaemonSet{}
+		g.Expect(convert(findInObjs("apps", "DaemonSet", "ovnkube-master", "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+		return ds
+	}
+
+	g.Expect(renderedMaster().Spec.Template.Spec.Affinity).To(BeNil())
+
+	bootstrapResult.OVN.MasterTopologyKey = "topology.kubernetes.io/zone"
+	affinity := renderedMaster().Spec.Template.Spec.Affinity
+	g.Expect(affinity).NotTo(BeNil())
+	g.Expect(affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms).To(ConsistOf(v1.NodeSelectorTerm{
+		MatchExpressions: []v1.NodeSelectorRequirement{{Key: "topology.kubernetes.io/zone", Operator: v1.NodeSelectorOpExists}},
+	}))
+	g.Expect(affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution).To(ConsistOf(v1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "ovnkube-master"}},
+		TopologyKey:   "topology.kubernetes.io/zone",
+	}))
+}
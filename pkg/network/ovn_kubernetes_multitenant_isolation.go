@@ -0,0 +1,140 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The multitenant-isolation ConfigMap is the snapshot of the NetIDs of the NetNamespaces of openshift-sdn,
+// by namespace, taken when the multitenant isolation of a cluster migrated from the Multitenant mode is
+// enabled. The NetNamespaces are removed along with openshift-sdn, the snapshot is kept until the isolation
+// is disabled.
+const (
+	MultitenantIsolationConfigMapName      = "multitenant-isolation"
+	MultitenantIsolationConfigMapNamespace = names.APPLIED_NAMESPACE
+)
+
+// globalNetID is the NetID of the global namespaces of the Multitenant mode, which reach and are reached
+// by all the namespaces
+const globalNetID = 0
+
+var netNamespaceListGVK = schema.GroupVersionKind{Group: "network.openshift.io", Version: "v1", Kind: "NetNamespaceList"}
+
+// bootstrapOVNMultitenantIsolation returns, when requested by an annotation on the operator configuration,
+// the NetIDs of the namespaces of the snapshot, taken from the NetNamespaces if there is none yet, along
+// with the namespaces to isolate, sorted by name.
+func bootstrapOVNMultitenantIsolation(conf *operv1.Network, kubeClient client.Reader) (map[string]uint32, []bootstrap.IsolatedNamespace, error) {
+	if conf.GetAnnotations()[names.MultitenantIsolationAnnotation] != "true" {
+		return nil, nil, nil
+	}
+	netIDs, err := multitenantIsolationSnapshot(kubeClient)
+	if err != nil {
+		return nil, nil, err
+	}
+	if netIDs == nil {
+		netIDs, err = listNetIDs(kubeClient)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(netIDs) == 0 {
+		klog.Warningf("%s is set, but there are no NetNamespaces of the Multitenant mode of openshift-sdn. Ignoring it",
+			names.MultitenantIsolationAnnotation)
+		return nil, nil, nil
+	}
+	namespaces := &corev1.NamespaceList{}
+	if err := kubeClient.List(context.TODO(), namespaces); err != nil {
+		return nil, nil, fmt.Errorf("Failed to list the namespaces: %w", err)
+	}
+	existing := sets.NewString()
+	for _, namespace := range namespaces.Items {
+		existing.Insert(namespace.Name)
+	}
+	isolated := isolatedNamespaces(netIDs, existing)
+	klog.Infof("Isolating %d namespaces of the %d NetNamespaces of openshift-sdn", len(isolated), len(netIDs))
+	return netIDs, isolated, nil
+}
+
+// multitenantIsolationSnapshot returns the NetIDs of the snapshot, if it was taken
+func multitenantIsolationSnapshot(kubeClient client.Reader) (map[string]uint32, error) {
+	cm := &corev1.ConfigMap{}
+	nsn := types.NamespacedName{Namespace: MultitenantIsolationConfigMapNamespace, Name: MultitenantIsolationConfigMapName}
+	if err := kubeClient.Get(context.TODO(), nsn, cm); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("Failed to retrieve the %s configmap: %w", MultitenantIsolationConfigMapName, err)
+		}
+		return nil, nil
+	}
+	netIDs := map[string]uint32{}
+	for namespace, value := range cm.Data {
+		netID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			klog.Warningf("%s: the NetID of %s is not a number, is: %q. Ignoring it", MultitenantIsolationConfigMapName, namespace, value)
+			continue
+		}
+		netIDs[namespace] = uint32(netID)
+	}
+	return netIDs, nil
+}
+
+// listNetIDs returns the NetIDs of the NetNamespaces, by namespace
+func listNetIDs(kubeClient client.Reader) (map[string]uint32, error) {
+	netNamespaces := &uns.UnstructuredList{}
+	netNamespaces.SetGroupVersionKind(netNamespaceListGVK)
+	if err := kubeClient.List(context.TODO(), netNamespaces); err != nil {
+		if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+			// openshift-sdn is already removed
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Failed to list the NetNamespaces: %w", err)
+	}
+	netIDs := map[string]uint32{}
+	for _, netNamespace := range netNamespaces.Items {
+		netID, found, err := uns.NestedInt64(netNamespace.Object, "netid")
+		if err != nil || !found {
+			klog.Warningf("NetNamespace %s has no valid netid. Ignoring it", netNamespace.GetName())
+			continue
+		}
+		netIDs[netNamespace.GetName()] = uint32(netID)
+	}
+	return netIDs, nil
+}
+
+// isolatedNamespaces returns the existing namespaces that are not global, with the namespaces that reach them
+// in the Multitenant mode: those sharing their NetID, and the global ones.
+func isolatedNamespaces(netIDs map[string]uint32, existing sets.String) []bootstrap.IsolatedNamespace {
+	byNetID := map[uint32][]string{}
+	for namespace, netID := range netIDs {
+		byNetID[netID] = append(byNetID[netID], namespace)
+	}
+	for _, namespaces := range byNetID {
+		sort.Strings(namespaces)
+	}
+
+	isolated := []bootstrap.IsolatedNamespace{}
+	for namespace, netID := range netIDs {
+		if netID == globalNetID || !existing.Has(namespace) {
+			continue
+		}
+		peers := append(append([]string{}, byNetID[netID]...), byNetID[globalNetID]...)
+		sort.Strings(peers)
+		isolated = append(isolated, bootstrap.IsolatedNamespace{Name: namespace, Peers: peers})
+	}
+	sort.Slice(isolated, func(i, j int) bool { return isolated[i].Name < isolated[j].Name })
+	return isolated
+}
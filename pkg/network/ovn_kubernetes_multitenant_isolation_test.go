@@ -0,0 +1,137 @@
+package network
+
+import (
+	"testing"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestIsolatedNamespaces(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	netIDs := map[string]uint32{
+		"default":  0,
+		"ingress":  0,
+		"team-a":   10,
+		"team-a-2": 10,
+		"team-b":   11,
+		"deleted":  12,
+	}
+	isolated := isolatedNamespaces(netIDs, sets.NewString("default", "ingress", "team-a", "team-a-2", "team-b"))
+	g.Expect(isolated).To(Equal([]bootstrap.IsolatedNamespace{
+		{Name: "team-a", Peers: []string{"default", "ingress", "team-a", "team-a-2"}},
+		{Name: "team-a-2", Peers: []string{"default", "ingress", "team-a", "team-a-2"}},
+		{Name: "team-b", Peers: []string{"default", "ingress", "team-b"}},
+	}))
+}
+
+func TestBootstrapOVNMultitenantIsolation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	netNamespace := func(name string, netID int64) client.Object {
+		return &uns.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "network.openshift.io/v1",
+			"kind":       "NetNamespace",
+			"metadata":   map[string]interface{}{"name": name},
+			"netname":    name,
+			"netid":      netID,
+		}}
+	}
+	namespace := func(name string) client.Object {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+	// NetNamespaces are only known as unstructured objects
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	scheme.AddKnownTypeWithName(netNamespaceListGVK.GroupVersion().WithKind("NetNamespace"), &uns.Unstructured{})
+	scheme.AddKnownTypeWithName(netNamespaceListGVK, &uns.UnstructuredList{})
+
+	conf := &operv1.Network{}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		netNamespace("default", 0), netNamespace("team-a", 10), netNamespace("team-b", 11),
+		namespace("default"), namespace("team-a"), namespace("team-b"),
+	).Build()
+
+	// the isolation is only preserved when requested
+	netIDs, isolated, err := bootstrapOVNMultitenantIsolation(conf, kubeClient)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(netIDs).To(BeNil())
+	g.Expect(isolated).To(BeNil())
+
+	// the NetIDs are taken from the NetNamespaces
+	conf.Annotations = map[string]string{names.MultitenantIsolationAnnotation: "true"}
+	netIDs, isolated, err = bootstrapOVNMultitenantIsolation(conf, kubeClient)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(netIDs).To(Equal(map[string]uint32{"default": 0, "team-a": 10, "team-b": 11}))
+	g.Expect(isolated).To(HaveLen(2))
+
+	// then from the snapshot, once taken
+	kubeClient = fake.NewClientBuilder().WithObjects(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: MultitenantIsolationConfigMapName, Namespace: MultitenantIsolationConfigMapNamespace},
+			Data:       map[string]string{"default": "0", "team-a": "10", "team-c": "invalid"},
+		},
+		namespace("default"), namespace("team-a"),
+	).Build()
+	netIDs, isolated, err = bootstrapOVNMultitenantIsolation(conf, kubeClient)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(netIDs).To(Equal(map[string]uint32{"default": 0, "team-a": 10}))
+	g.Expect(isolated).To(Equal([]bootstrap.IsolatedNamespace{{Name: "team-a", Peers: []string{"default", "team-a"}}}))
+}
+
+func TestRenderOVNKubernetesMultitenantIsolation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).NotTo(ContainElement(HaveKubernetesID("ConfigMap", MultitenantIsolationConfigMapNamespace, MultitenantIsolationConfigMapName)))
+
+	bootstrapResult.OVN.OVNKubernetesConfig.MultitenantNetIDs = map[string]uint32{"default": 0, "team-a": 10, "team-b": 11}
+	bootstrapResult.OVN.OVNKubernetesConfig.IsolatedNamespaces = isolatedNamespaces(
+		bootstrapResult.OVN.OVNKubernetesConfig.MultitenantNetIDs, sets.NewString("default", "team-a", "team-b"))
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	cm := &corev1.ConfigMap{}
+	g.Expect(convert(findInObjs("", "ConfigMap", MultitenantIsolationConfigMapName, MultitenantIsolationConfigMapNamespace, objs), cm)).To(Succeed())
+	g.Expect(cm.Data).To(Equal(map[string]string{"default": "0", "team-a": "10", "team-b": "11"}))
+
+	g.Expect(objs).NotTo(ContainElement(HaveKubernetesID("NetworkPolicy", "default", "multitenant-isolation")))
+	policy := &networkingv1.NetworkPolicy{}
+	g.Expect(convert(findInObjs("networking.k8s.io", "NetworkPolicy", "multitenant-isolation", "team-a", objs), policy)).To(Succeed())
+	g.Expect(policy.Spec.PolicyTypes).To(Equal([]networkingv1.PolicyType{networkingv1.PolicyTypeIngress}))
+	g.Expect(policy.Spec.Ingress).To(HaveLen(1))
+	from := policy.Spec.Ingress[0].From
+	g.Expect(from).To(HaveLen(2))
+	g.Expect(from[0].NamespaceSelector.MatchExpressions).To(Equal([]metav1.LabelSelectorRequirement{{
+		Key:      "kubernetes.io/metadata.name",
+		Operator: metav1.LabelSelectorOpIn,
+		Values:   []string{"default", "team-a"},
+	}}))
+	g.Expect(from[1].NamespaceSelector.MatchLabels).To(HaveKeyWithValue("policy-group.network.openshift.io/host-network", ""))
+	g.Expect(objs).To(ContainElement(HaveKubernetesID("NetworkPolicy", "team-b", "multitenant-isolation")))
+}
@@ -0,0 +1,512 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/render"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// the labels of the nodes the deprecated dpu-mode-config ConfigMap applies its mode to
+	dpuHostNodeLabel = "network.operator.openshift.io/dpu-host"
+	dpuNodeLabel     = "network.operator.openshift.io/dpu"
+	// excludedNodeLabel labels the nodes carved out for maintenance, debugging or another use
+	excludedNodeLabel = "network.operator.openshift.io/exclude"
+	// ovnNodePoolLabel labels the nodes of the OVNNodePools with the name of their pool, so that the nodes
+	// of a pool, or of none of the pools, are selected by a single requirement
+	ovnNodePoolLabel = "network.operator.openshift.io/ovn-node-pool"
+
+	// the daemonsets of the nodes of the dpu-host and dpu pools, suffixed with the name of the pool
+	ovnNodeDPUHostDaemonSet = "ovnkube-node-dpu-host"
+	errorCNIDaemonSet       = "error-cni-plugin"
+)
+
+// dpuModeConfig is the deprecated ConfigMap setting the node mode of the labeled nodes
+var dpuModeConfig = types.NamespacedName{Namespace: "openshift-network-operator", Name: "dpu-mode-config"}
+
+var (
+	// ovnNodePoolImageRegexp matches the image references, by tag or by digest
+	ovnNodePoolImageRegexp = regexp.MustCompile(`^[a-z0-9]([a-z0-9._/:-]*[a-z0-9])?(@sha256:[a-f0-9]{64})?$`)
+	// ovnNodePoolArgRegexp matches the flags of ovnkube, whose values may be lists, addresses or paths
+	ovnNodePoolArgRegexp = regexp.MustCompile(`^--[a-z0-9][a-z0-9-]*(=[A-Za-z0-9_.,:/@%+=-]*)?$`)
+)
+
+// ovnNodeModes maps the modes of the OVNNodePools to the node modes of ovn-kubernetes
+var ovnNodeModes = map[netopv1.NodeMode]string{
+	netopv1.FullNodeMode:    OVN_NODE_MODE_FULL,
+	netopv1.DPUHostNodeMode: OVN_NODE_MODE_DPU_HOST,
+	netopv1.DPUNodeMode:     OVN_NODE_MODE_DPU,
+}
+
+// bootstrapOVNNodePools returns the pools of nodes not running in the full node mode, from the OVNNodePools
+// sorted by name, or else from the deprecated dpu-mode-config ConfigMap. Invalid pools are ignored.
+func bootstrapOVNNodePools(kubeClient client.Reader) ([]bootstrap.OVNNodePool, error) {
+	nodePools := &netopv1.OVNNodePoolList{}
+	if err := kubeClient.List(context.TODO(), nodePools); err != nil {
+		// the CRD may not be installed yet during upgrades
+		if !meta.IsNoMatchError(err) && !runtime.IsNotRegisteredError(err) {
+			return nil, fmt.Errorf("Failed to list the OVNNodePools: %w", err)
+		}
+		nodePools.Items = nil
+	}
+
+	legacy, err := bootstrapOVNDPUModeConfig(kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodePools.Items) == 0 {
+		if legacy != nil {
+			return []bootstrap.OVNNodePool{*legacy}, nil
+		}
+		return nil, nil
+	}
+	if legacy != nil {
+		klog.Warningf("The OVNNodePools replace the deprecated %s ConfigMap. Ignoring it", dpuModeConfig.Name)
+	}
+
+	machineConfigPools, err := listMachineConfigPools(kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(nodePools.Items, func(i, j int) bool { return nodePools.Items[i].Name < nodePools.Items[j].Name })
+	res := []bootstrap.OVNNodePool{}
+	pooled := map[string]string{}
+	for _, nodePool := range nodePools.Items {
+		mode, ok := ovnNodeModes[nodePool.Spec.Mode]
+		if !ok {
+			klog.Warningf("OVNNodePool %s has an invalid mode %q. Ignoring it", nodePool.Name, nodePool.Spec.Mode)
+			continue
+		}
+		// the nodes of no pool run in the full node mode
+		if mode == OVN_NODE_MODE_FULL {
+			continue
+		}
+		if errs := validation.IsDNS1123Label(ovnNodeDPUHostDaemonSet + "-" + nodePool.Name); len(errs) > 0 {
+			klog.Warningf("The name of OVNNodePool %s cannot suffix the name of its daemonset. Ignoring it: %s",
+				nodePool.Name, strings.Join(errs, ", "))
+			continue
+		}
+		mcp := nodePool.Spec.MachineConfigPool
+		if other, ok := pooled[mcp]; ok {
+			klog.Warningf("OVNNodePool %s selects MachineConfigPool %s, already selected by OVNNodePool %s. Ignoring it",
+				nodePool.Name, mcp, other)
+			continue
+		}
+		selector, ok := machineConfigPools[mcp]
+		if !ok {
+			klog.Warningf("OVNNodePool %s selects MachineConfigPool %s, which does not exist. Ignoring it", nodePool.Name, mcp)
+			continue
+		}
+		requirements, err := nodeSelectorRequirements(selector)
+		if err != nil {
+			klog.Warningf("OVNNodePool %s selects MachineConfigPool %s, whose nodeSelector is invalid. Ignoring it: %v",
+				nodePool.Name, mcp, err)
+			continue
+		}
+		if len(requirements) == 0 {
+			klog.Warningf("OVNNodePool %s selects MachineConfigPool %s, which selects every node. Ignoring it", nodePool.Name, mcp)
+			continue
+		}
+		pooled[mcp] = nodePool.Name
+		pool := bootstrap.OVNNodePool{Name: nodePool.Name, Mode: mode, NodeSelector: requirements}
+		if mode == OVN_NODE_MODE_DPU_HOST {
+			pool.Image, pool.ExtraArgs = ovnNodePoolOverrides(&nodePool)
+		} else if nodePool.Spec.Image != "" || len(nodePool.Spec.ExtraArgs) > 0 {
+			klog.Warningf("OVNNodePool %s sets the image or the flags of ovnkube, which does not run in the %s mode. Ignoring them",
+				nodePool.Name, nodePool.Spec.Mode)
+		}
+		res = append(res, pool)
+	}
+	return res, nil
+}
+
+// ovnNodePoolOverrides returns the valid image and flags of ovnkube of a pool of dpu-host nodes. The flags are
+// rendered as is in the command of ovnkube-node, so they are limited to the characters the shell does not
+// interpret.
+func ovnNodePoolOverrides(nodePool *netopv1.OVNNodePool) (string, []string) {
+	image := nodePool.Spec.Image
+	if image != "" && !ovnNodePoolImageRegexp.MatchString(image) {
+		klog.Warningf("OVNNodePool %s has an invalid image %q. Using the image of the release", nodePool.Name, image)
+		image = ""
+	}
+	args := []string{}
+	for _, arg := range nodePool.Spec.ExtraArgs {
+		if !ovnNodePoolArgRegexp.MatchString(arg) {
+			klog.Warningf("OVNNodePool %s has an invalid flag %q, it must be a single --flag or --flag=value. Ignoring it",
+				nodePool.Name, arg)
+			continue
+		}
+		args = append(args, arg)
+	}
+	if len(args) == 0 {
+		args = nil
+	}
+	return image, args
+}
+
+// bootstrapOVNDPUModeConfig converts the mode of the deprecated dpu-mode-config ConfigMap, if any, to the
+// pool of the nodes labeled for the mode
+func bootstrapOVNDPUModeConfig(kubeClient client.Reader) (*bootstrap.OVNNodePool, error) {
+	cm := &corev1.ConfigMap{}
+	if err := kubeClient.Get(context.TODO(), dpuModeConfig, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Could not determine Node Mode: %w", err)
+	}
+	label := ""
+	switch mode := cm.Data["mode"]; mode {
+	case OVN_NODE_MODE_DPU_HOST:
+		label = dpuHostNodeLabel
+	case OVN_NODE_MODE_DPU:
+		label = dpuNodeLabel
+	default:
+		klog.Warningf("%s does not match %q or %q, is: %q. Ignoring it",
+			dpuModeConfig.Name, OVN_NODE_MODE_DPU_HOST, OVN_NODE_MODE_DPU, mode)
+		return nil, nil
+	}
+	return &bootstrap.OVNNodePool{
+		Mode:         cm.Data["mode"],
+		NodeSelector: []corev1.NodeSelectorRequirement{{Key: label, Operator: corev1.NodeSelectorOpExists}},
+	}, nil
+}
+
+// listMachineConfigPools returns the nodeSelectors of the MachineConfigPools by name, none when the nodes
+// are not managed by the machine-config-operator
+func listMachineConfigPools(kubeClient client.Reader) (map[string]*metav1.LabelSelector, error) {
+	pools := &uns.UnstructuredList{}
+	pools.SetGroupVersionKind(MachineConfigPoolListGVK)
+	if err := kubeClient.List(context.TODO(), pools); err != nil {
+		if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) || runtime.IsNotRegisteredError(err) {
+			klog.Warningf("The OVNNodePools select MachineConfigPools, but the nodes are not managed by the machine-config-operator")
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to list the MachineConfigPools")
+	}
+	res := map[string]*metav1.LabelSelector{}
+	for _, pool := range pools.Items {
+		selector := &metav1.LabelSelector{}
+		if obj, ok, _ := uns.NestedMap(pool.Object, "spec", "nodeSelector"); ok {
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj, selector); err != nil {
+				klog.Warningf("MachineConfigPool %s has an invalid nodeSelector: %v", pool.GetName(), err)
+				continue
+			}
+		}
+		res[pool.GetName()] = selector
+	}
+	return res, nil
+}
+
+// nodeSelectorRequirements converts a label selector to the equivalent requirements of a node selector term
+func nodeSelectorRequirements(selector *metav1.LabelSelector) ([]corev1.NodeSelectorRequirement, error) {
+	if _, err := metav1.LabelSelectorAsSelector(selector); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(selector.MatchLabels))
+	for key := range selector.MatchLabels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	requirements := []corev1.NodeSelectorRequirement{}
+	for _, key := range keys {
+		requirements = append(requirements, corev1.NodeSelectorRequirement{
+			Key:      key,
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   []string{selector.MatchLabels[key]},
+		})
+	}
+	for _, expr := range selector.MatchExpressions {
+		requirements = append(requirements, corev1.NodeSelectorRequirement{
+			Key:      expr.Key,
+			Operator: corev1.NodeSelectorOperator(expr.Operator),
+			Values:   expr.Values,
+		})
+	}
+	return requirements, nil
+}
+
+// NodePoolLabelsChanged returns whether the labels of a node changed between two of its versions, other
+// than the label of its OVNNodePool: the MachineConfigPools, and so the OVNNodePools, select the nodes by
+// their labels, such as their roles.
+func NodePoolLabelsChanged(old, new *corev1.Node) bool {
+	for _, pair := range [][2]map[string]string{{old.Labels, new.Labels}, {new.Labels, old.Labels}} {
+		for k, v := range pair[0] {
+			if w, ok := pair[1][k]; k != ovnNodePoolLabel && (!ok || v != w) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// bootstrapOVNNodePoolLabels labels each node with the name of the first pool whose requirements it meets,
+// and removes the label of the nodes of none of the pools. The pool converted from the deprecated
+// dpu-mode-config ConfigMap already selects its nodes by a label.
+func bootstrapOVNNodePoolLabels(kubeClient client.Client, pools []bootstrap.OVNNodePool) error {
+	selectors := make([]labels.Selector, len(pools))
+	for i, pool := range pools {
+		if pool.Name == "" {
+			continue
+		}
+		selector, err := nodeSelectorRequirementsAsSelector(pool.NodeSelector)
+		if err != nil {
+			return errors.Wrapf(err, "invalid requirements of OVNNodePool %s", pool.Name)
+		}
+		selectors[i] = selector
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := kubeClient.List(context.TODO(), nodes); err != nil {
+		return errors.Wrap(err, "failed to list the nodes of the OVNNodePools")
+	}
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		name := ""
+		for j, selector := range selectors {
+			if selector != nil && selector.Matches(labels.Set(node.Labels)) {
+				name = pools[j].Name
+				break
+			}
+		}
+		current, labeled := node.Labels[ovnNodePoolLabel]
+		if current == name && labeled == (name != "") {
+			continue
+		}
+		patched := node.DeepCopy()
+		if name == "" {
+			delete(patched.Labels, ovnNodePoolLabel)
+		} else {
+			if patched.Labels == nil {
+				patched.Labels = map[string]string{}
+			}
+			patched.Labels[ovnNodePoolLabel] = name
+		}
+		klog.Infof("Moving node %s from OVNNodePool %q to %q", node.Name, current, name)
+		if err := kubeClient.Patch(context.TODO(), patched, client.MergeFrom(node)); err != nil {
+			return errors.Wrapf(err, "failed to label node %s with its OVNNodePool", node.Name)
+		}
+	}
+	return nil
+}
+
+// nodeSelectorRequirementsAsSelector converts the requirements of a node selector term to a label selector
+func nodeSelectorRequirementsAsSelector(requirements []corev1.NodeSelectorRequirement) (labels.Selector, error) {
+	selector := labels.NewSelector()
+	for _, req := range requirements {
+		var op selection.Operator
+		switch req.Operator {
+		case corev1.NodeSelectorOpIn:
+			op = selection.In
+		case corev1.NodeSelectorOpNotIn:
+			op = selection.NotIn
+		case corev1.NodeSelectorOpExists:
+			op = selection.Exists
+		case corev1.NodeSelectorOpDoesNotExist:
+			op = selection.DoesNotExist
+		case corev1.NodeSelectorOpGt:
+			op = selection.GreaterThan
+		case corev1.NodeSelectorOpLt:
+			op = selection.LessThan
+		default:
+			return nil, errors.Errorf("%q is not a valid node selector operator", req.Operator)
+		}
+		r, err := labels.NewRequirement(req.Key, op, req.Values)
+		if err != nil {
+			return nil, err
+		}
+		selector = selector.Add(*r)
+	}
+	return selector, nil
+}
+
+// ovnNodePoolRequirements returns the requirements met by the nodes of a pool: its label, or the labels of
+// the nodes of the pool converted from the deprecated dpu-mode-config ConfigMap
+func ovnNodePoolRequirements(pool bootstrap.OVNNodePool) []corev1.NodeSelectorRequirement {
+	if pool.Name == "" {
+		return pool.NodeSelector
+	}
+	return []corev1.NodeSelectorRequirement{{Key: ovnNodePoolLabel, Operator: corev1.NodeSelectorOpIn, Values: []string{pool.Name}}}
+}
+
+// ovnNodeSelectorTerms returns the node selector term of the nodes meeting the base requirements, outside
+// of the excluded pools, which are kept off by their label. The nodes of the pool converted from the
+// deprecated dpu-mode-config ConfigMap are labeled for its mode, which the base requirements keep off.
+func ovnNodeSelectorTerms(base []corev1.NodeSelectorRequirement, excluded []bootstrap.OVNNodePool) []corev1.NodeSelectorTerm {
+	term := append([]corev1.NodeSelectorRequirement{}, base...)
+	names := []string{}
+	for _, pool := range excluded {
+		if pool.Name != "" {
+			names = append(names, pool.Name)
+		}
+	}
+	if len(names) > 0 {
+		term = append(term, corev1.NodeSelectorRequirement{Key: ovnNodePoolLabel, Operator: corev1.NodeSelectorOpNotIn, Values: names})
+	}
+	return []corev1.NodeSelectorTerm{{MatchExpressions: term}}
+}
+
+// nodeSelectorTermsJSON returns the node selector terms as a JSON flow sequence, rendered as is in the
+// nodeSelectorTerms of the manifests
+func nodeSelectorTermsJSON(terms []corev1.NodeSelectorTerm) (string, error) {
+	b, err := json.Marshal(terms)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal the node selector terms")
+	}
+	return string(b), nil
+}
+
+// ovnNodePools returns the pools of the given node mode
+func ovnNodePools(bootstrapResult *bootstrap.BootstrapResult, mode string) []bootstrap.OVNNodePool {
+	res := []bootstrap.OVNNodePool{}
+	if bootstrapResult.OVN.OVNKubernetesConfig == nil {
+		return res
+	}
+	for _, pool := range bootstrapResult.OVN.OVNKubernetesConfig.NodePools {
+		if pool.Mode == mode {
+			res = append(res, pool)
+		}
+	}
+	return res
+}
+
+// ovnNodePoolDaemonSet returns the name of the daemonset of a pool of nodes, the unsuffixed name for the
+// pool converted from the deprecated dpu-mode-config ConfigMap
+func ovnNodePoolDaemonSet(name string, pool bootstrap.OVNNodePool) string {
+	if pool.Name == "" {
+		return name
+	}
+	return name + "-" + pool.Name
+}
+
+// errorCNIDaemonSetData is the render data of the error-cni daemonset of a pool of dpu nodes
+type errorCNIDaemonSetData struct {
+	Name              string
+	NodeSelectorTerms string
+}
+
+// fillOVNNodePoolsData sets the node selector terms of the full ovnkube-node and ipsec daemonsets, keeping
+// them off of the nodes of the dpu-host and dpu pools, and the error-cni daemonsets of the dpu pools
+func fillOVNNodePoolsData(bootstrapResult *bootstrap.BootstrapResult, data *render.RenderData) error {
+	excluded := []bootstrap.OVNNodePool{}
+	if bootstrapResult.OVN.OVNKubernetesConfig != nil {
+		excluded = bootstrapResult.OVN.OVNKubernetesConfig.NodePools
+	}
+	terms, err := nodeSelectorTermsJSON(ovnNodeSelectorTerms([]corev1.NodeSelectorRequirement{
+		{Key: dpuHostNodeLabel, Operator: corev1.NodeSelectorOpDoesNotExist},
+		{Key: dpuNodeLabel, Operator: corev1.NodeSelectorOpDoesNotExist},
+		{Key: excludedNodeLabel, Operator: corev1.NodeSelectorOpDoesNotExist},
+	}, excluded))
+	if err != nil {
+		return err
+	}
+	data.Data["OVN_NODE_MODE"] = OVN_NODE_MODE_FULL
+	data.Data["OVNNodeDaemonSet"] = "ovnkube-node"
+	data.Data["OVNNodeSelectorTerms"] = terms
+	data.Data["OVNNodeExtraArgs"] = nil
+
+	// IPsec runs on the dpu nodes, where OVS runs, but not on the dpu-host ones
+	dpuHostPools := ovnNodePools(bootstrapResult, OVN_NODE_MODE_DPU_HOST)
+	terms, err = nodeSelectorTermsJSON(ovnNodeSelectorTerms([]corev1.NodeSelectorRequirement{
+		{Key: dpuHostNodeLabel, Operator: corev1.NodeSelectorOpDoesNotExist},
+	}, dpuHostPools))
+	if err != nil {
+		return err
+	}
+	data.Data["IPsecNodeSelectorTerms"] = terms
+
+	errorCNIDaemonSets := []errorCNIDaemonSetData{}
+	for _, pool := range ovnNodePools(bootstrapResult, OVN_NODE_MODE_DPU) {
+		terms, err := nodeSelectorTermsJSON([]corev1.NodeSelectorTerm{{MatchExpressions: ovnNodePoolRequirements(pool)}})
+		if err != nil {
+			return err
+		}
+		errorCNIDaemonSets = append(errorCNIDaemonSets, errorCNIDaemonSetData{
+			Name:              ovnNodePoolDaemonSet(errorCNIDaemonSet, pool),
+			NodeSelectorTerms: terms,
+		})
+	}
+	data.Data["OVNErrorCNIDaemonSets"] = errorCNIDaemonSets
+	return nil
+}
+
+// renderOVNNodePools renders the ovnkube-node daemonset of each pool of dpu-host nodes, with the image and the
+// flags of the vendor of its DPUs
+func renderOVNNodePools(bootstrapResult *bootstrap.BootstrapResult, manifestDir string, data *render.RenderData) ([]*uns.Unstructured, error) {
+	objs := []*uns.Unstructured{}
+	releaseImage := data.Data["OvnImage"]
+	for _, pool := range ovnNodePools(bootstrapResult, OVN_NODE_MODE_DPU_HOST) {
+		terms, err := nodeSelectorTermsJSON(ovnNodeSelectorTerms(append(ovnNodePoolRequirements(pool),
+			corev1.NodeSelectorRequirement{Key: dpuNodeLabel, Operator: corev1.NodeSelectorOpDoesNotExist},
+			corev1.NodeSelectorRequirement{Key: excludedNodeLabel, Operator: corev1.NodeSelectorOpDoesNotExist},
+		), nil))
+		if err != nil {
+			return nil, err
+		}
+		data.Data["OVN_NODE_MODE"] = OVN_NODE_MODE_DPU_HOST
+		data.Data["OVNNodeDaemonSet"] = ovnNodePoolDaemonSet(ovnNodeDPUHostDaemonSet, pool)
+		data.Data["OVNNodeSelectorTerms"] = terms
+		data.Data["OVNNodeExtraArgs"] = pool.ExtraArgs
+		data.Data["OvnImage"] = releaseImage
+		if pool.Image != "" {
+			data.Data["OvnImage"] = pool.Image
+		}
+		manifests, err := render.RenderTemplate(filepath.Join(manifestDir, "network/ovn-kubernetes/ovnkube-node.yaml"), data)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to render manifests")
+		}
+		objs = append(objs, manifests...)
+	}
+	data.Data["OvnImage"] = releaseImage
+	data.Data["OVNNodeExtraArgs"] = nil
+	return objs, nil
+}
+
+// kubeProxyNodeSelectorTerms returns the node selector terms of the nodes of the pools of dpu nodes, which
+// kube-proxy runs on in place of ovnkube-node, or "" when there are none
+func kubeProxyNodeSelectorTerms(bootstrapResult *bootstrap.BootstrapResult) (string, error) {
+	terms := []corev1.NodeSelectorTerm{}
+	for _, pool := range ovnNodePools(bootstrapResult, OVN_NODE_MODE_DPU) {
+		terms = append(terms, corev1.NodeSelectorTerm{MatchExpressions: ovnNodePoolRequirements(pool)})
+	}
+	if len(terms) == 0 {
+		return "", nil
+	}
+	return nodeSelectorTermsJSON(terms)
+}
+
+// recordDPUModeConfigEvent warns that the deprecated dpu-mode-config ConfigMap is still used
+func recordDPUModeConfigEvent(bootstrapResult *bootstrap.BootstrapResult) {
+	for _, pool := range bootstrapResult.OVN.OVNKubernetesConfig.NodePools {
+		if pool.Name != "" {
+			continue
+		}
+		mode := netopv1.DPUHostNodeMode
+		if pool.Mode == OVN_NODE_MODE_DPU {
+			mode = netopv1.DPUNodeMode
+		}
+		bootstrapResult.RecordEvent(corev1.EventTypeWarning, "DPUModeConfigDeprecated",
+			"The %s ConfigMap is deprecated, create an OVNNodePool in the %s mode for the MachineConfigPool of the nodes labeled %s instead",
+			dpuModeConfig.Name, mode, pool.NodeSelector[0].Key)
+	}
+}
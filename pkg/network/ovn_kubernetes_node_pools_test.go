@@ -0,0 +1,330 @@
+package network
+
+import (
+	"context"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/featuregates"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestBootstrapOVNNodePools(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// MachineConfigPools are only known as unstructured objects
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	scheme.AddKnownTypeWithName(MachineConfigPoolListGVK.GroupVersion().WithKind("MachineConfigPool"), &uns.Unstructured{})
+	scheme.AddKnownTypeWithName(MachineConfigPoolListGVK, &uns.UnstructuredList{})
+
+	dpuModeConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: dpuModeConfig.Namespace, Name: dpuModeConfig.Name},
+		Data:       map[string]string{"mode": "dpu-host"},
+	}
+
+	// neither the CRD nor the ConfigMap
+	pools, err := bootstrapOVNNodePools(fake.NewClientBuilder().WithScheme(scheme).Build())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pools).To(BeEmpty())
+
+	// the deprecated ConfigMap is converted to the pool of the labeled nodes
+	pools, err = bootstrapOVNNodePools(fake.NewClientBuilder().WithScheme(scheme).WithObjects(dpuModeConfigMap).Build())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pools).To(Equal([]bootstrap.OVNNodePool{{
+		Mode:         OVN_NODE_MODE_DPU_HOST,
+		NodeSelector: []corev1.NodeSelectorRequirement{{Key: dpuHostNodeLabel, Operator: corev1.NodeSelectorOpExists}},
+	}}))
+	invalid := dpuModeConfigMap.DeepCopy()
+	invalid.Data["mode"] = "offload"
+	pools, err = bootstrapOVNNodePools(fake.NewClientBuilder().WithScheme(scheme).WithObjects(invalid).Build())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pools).To(BeEmpty())
+
+	g.Expect(netopv1.Install(scheme)).To(Succeed())
+	machineConfigPool := func(name string, nodeSelector map[string]interface{}) *uns.Unstructured {
+		pool := &uns.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "machineconfiguration.openshift.io/v1",
+			"kind":       "MachineConfigPool",
+			"metadata":   map[string]interface{}{"name": name},
+			"spec":       map[string]interface{}{},
+		}}
+		if nodeSelector != nil {
+			g.Expect(uns.SetNestedMap(pool.Object, nodeSelector, "spec", "nodeSelector")).To(Succeed())
+		}
+		return pool
+	}
+	nodePool := func(name, mcp string, mode netopv1.NodeMode) *netopv1.OVNNodePool {
+		return &netopv1.OVNNodePool{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       netopv1.OVNNodePoolSpec{MachineConfigPool: mcp, Mode: mode},
+		}
+	}
+	// the image and the flags of the vendor are only valid for the dpu-host pools
+	vendorPool := nodePool("dpu-hosts", "dpu-host", netopv1.DPUHostNodeMode)
+	vendorPool.Spec.Image = "quay.io/example/ovn-kubernetes:vendor-4.10"
+	vendorPool.Spec.ExtraArgs = []string{"--ovnkube-node-mgmt-port-netdev=enp3s0f0v0", "--mtu 1400", "--disable-pkt-mtu-check", "--x=$(reboot)"}
+	dpuPool := nodePool("dpus", "dpu", netopv1.DPUNodeMode)
+	dpuPool.Spec.Image = "quay.io/example/ovn-kubernetes:vendor-4.10"
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		dpuModeConfigMap,
+		machineConfigPool("dpu-host", map[string]interface{}{
+			"matchLabels": map[string]interface{}{"node-role.kubernetes.io/dpu-host": ""},
+		}),
+		machineConfigPool("dpu", map[string]interface{}{
+			"matchLabels": map[string]interface{}{"node-role.kubernetes.io/dpu": "", "example.com/rack": "1"},
+			"matchExpressions": []interface{}{
+				map[string]interface{}{"key": "example.com/zone", "operator": "NotIn", "values": []interface{}{"edge"}},
+			},
+		}),
+		machineConfigPool("worker", map[string]interface{}{
+			"matchLabels": map[string]interface{}{"node-role.kubernetes.io/worker": ""},
+		}),
+		machineConfigPool("everything", nil),
+		vendorPool,
+		dpuPool,
+		nodePool("duplicate", "dpu-host", netopv1.DPUHostNodeMode),
+		nodePool("workers", "worker", netopv1.FullNodeMode),
+		nodePool("everything", "everything", netopv1.DPUHostNodeMode),
+		nodePool("missing", "infra", netopv1.DPUHostNodeMode),
+		nodePool("a-name-too-long-to-suffix-the-name-of-the-daemonset", "worker", netopv1.DPUHostNodeMode),
+	).Build()
+	pools, err = bootstrapOVNNodePools(cl)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pools).To(Equal([]bootstrap.OVNNodePool{
+		{
+			Name: "dpu-hosts",
+			Mode: OVN_NODE_MODE_DPU_HOST,
+			NodeSelector: []corev1.NodeSelectorRequirement{
+				{Key: "node-role.kubernetes.io/dpu-host", Operator: corev1.NodeSelectorOpIn, Values: []string{""}},
+			},
+			Image:     "quay.io/example/ovn-kubernetes:vendor-4.10",
+			ExtraArgs: []string{"--ovnkube-node-mgmt-port-netdev=enp3s0f0v0", "--disable-pkt-mtu-check"},
+		},
+		{
+			Name: "dpus",
+			Mode: OVN_NODE_MODE_DPU,
+			NodeSelector: []corev1.NodeSelectorRequirement{
+				{Key: "example.com/rack", Operator: corev1.NodeSelectorOpIn, Values: []string{"1"}},
+				{Key: "node-role.kubernetes.io/dpu", Operator: corev1.NodeSelectorOpIn, Values: []string{""}},
+				{Key: "example.com/zone", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"edge"}},
+			},
+		},
+	}))
+}
+
+func TestOVNNodeSelectorTerms(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	base := []corev1.NodeSelectorRequirement{
+		{Key: dpuHostNodeLabel, Operator: corev1.NodeSelectorOpDoesNotExist},
+	}
+	g.Expect(ovnNodeSelectorTerms(base, nil)).To(Equal([]corev1.NodeSelectorTerm{{MatchExpressions: base}}))
+
+	// the nodes of the pools are kept off by their label, whatever the number of pools and of their
+	// requirements, and the pool of the deprecated ConfigMap by the base requirements
+	terms := ovnNodeSelectorTerms(base, []bootstrap.OVNNodePool{
+		{NodeSelector: []corev1.NodeSelectorRequirement{
+			{Key: dpuHostNodeLabel, Operator: corev1.NodeSelectorOpExists},
+		}},
+		{Name: "dpu-hosts", NodeSelector: []corev1.NodeSelectorRequirement{
+			{Key: "dpu-host", Operator: corev1.NodeSelectorOpExists},
+		}},
+		{Name: "dpus", NodeSelector: []corev1.NodeSelectorRequirement{
+			{Key: "rack", Operator: corev1.NodeSelectorOpIn, Values: []string{"1"}},
+			{Key: "dpu", Operator: corev1.NodeSelectorOpExists},
+		}},
+	})
+	g.Expect(terms).To(Equal([]corev1.NodeSelectorTerm{
+		{MatchExpressions: []corev1.NodeSelectorRequirement{
+			{Key: dpuHostNodeLabel, Operator: corev1.NodeSelectorOpDoesNotExist},
+			{Key: ovnNodePoolLabel, Operator: corev1.NodeSelectorOpNotIn, Values: []string{"dpu-hosts", "dpus"}},
+		}},
+	}))
+}
+
+func TestBootstrapOVNNodePoolLabels(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	node := func(name string, labels map[string]string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	cl := fake.NewClientBuilder().WithObjects(
+		node("dpu-host-0", map[string]string{"node-role.kubernetes.io/dpu-host": ""}),
+		node("dpu-0", map[string]string{"node-role.kubernetes.io/dpu": "", "example.com/zone": "core"}),
+		node("dpu-edge-0", map[string]string{"node-role.kubernetes.io/dpu": "", "example.com/zone": "edge", ovnNodePoolLabel: "dpus"}),
+		node("worker-0", map[string]string{"node-role.kubernetes.io/worker": "", ovnNodePoolLabel: "dpus"}),
+		node("worker-1", nil),
+		node("legacy-0", map[string]string{dpuHostNodeLabel: ""}),
+	).Build()
+	pools := []bootstrap.OVNNodePool{
+		{Mode: OVN_NODE_MODE_DPU_HOST, NodeSelector: []corev1.NodeSelectorRequirement{
+			{Key: dpuHostNodeLabel, Operator: corev1.NodeSelectorOpExists},
+		}},
+		{Name: "dpu-hosts", Mode: OVN_NODE_MODE_DPU_HOST, NodeSelector: []corev1.NodeSelectorRequirement{
+			{Key: "node-role.kubernetes.io/dpu-host", Operator: corev1.NodeSelectorOpIn, Values: []string{""}},
+		}},
+		{Name: "dpus", Mode: OVN_NODE_MODE_DPU, NodeSelector: []corev1.NodeSelectorRequirement{
+			{Key: "node-role.kubernetes.io/dpu", Operator: corev1.NodeSelectorOpIn, Values: []string{""}},
+			{Key: "example.com/zone", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"edge"}},
+		}},
+	}
+	g.Expect(bootstrapOVNNodePoolLabels(cl, pools)).To(Succeed())
+
+	nodes := &corev1.NodeList{}
+	g.Expect(cl.List(context.TODO(), nodes)).To(Succeed())
+	pooled := map[string]string{}
+	for _, node := range nodes.Items {
+		if pool, ok := node.Labels[ovnNodePoolLabel]; ok {
+			pooled[node.Name] = pool
+		}
+	}
+	g.Expect(pooled).To(Equal(map[string]string{
+		"dpu-host-0": "dpu-hosts",
+		"dpu-0":      "dpus",
+	}))
+}
+
+func TestRenderOVNKubernetesNodePools(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	dpuHosts := bootstrap.OVNNodePool{
+		Name: "dpu-hosts",
+		Mode: OVN_NODE_MODE_DPU_HOST,
+		NodeSelector: []corev1.NodeSelectorRequirement{
+			{Key: "node-role.kubernetes.io/dpu-host", Operator: corev1.NodeSelectorOpIn, Values: []string{""}},
+		},
+		Image:     "quay.io/example/ovn-kubernetes:vendor-4.10",
+		ExtraArgs: []string{"--ovnkube-node-mgmt-port-netdev=enp3s0f0v0"},
+	}
+	dpus := bootstrap.OVNNodePool{
+		Name: "dpus",
+		Mode: OVN_NODE_MODE_DPU,
+		NodeSelector: []corev1.NodeSelectorRequirement{
+			{Key: "node-role.kubernetes.io/dpu", Operator: corev1.NodeSelectorOpIn, Values: []string{""}},
+		},
+	}
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs: []string{"1.2.3.4"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{
+				NodePools: []bootstrap.OVNNodePool{dpuHosts, dpus},
+			},
+		},
+		// the node modes are gated by DPU, of the TechPreviewNoUpgrade feature set
+		FeatureGates: featuregates.New(&configv1.FeatureGate{
+			Spec: configv1.FeatureGateSpec{FeatureGateSelection: configv1.FeatureGateSelection{
+				FeatureSet: configv1.TechPreviewNoUpgrade,
+			}},
+		}),
+	}
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// the full ovnkube-node stays off of the nodes of the pools, and runs the image of the release
+	ds := &appsv1.DaemonSet{}
+	g.Expect(convert(findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+	for _, container := range ds.Spec.Template.Spec.Containers {
+		g.Expect(container.Image).NotTo(Equal(dpuHosts.Image))
+		g.Expect(container.Command).NotTo(ContainElement(ContainSubstring("--ovnkube-node-mgmt-port-netdev=")))
+	}
+	terms := ds.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	g.Expect(terms).To(Equal([]corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{
+		{Key: dpuHostNodeLabel, Operator: corev1.NodeSelectorOpDoesNotExist},
+		{Key: dpuNodeLabel, Operator: corev1.NodeSelectorOpDoesNotExist},
+		{Key: excludedNodeLabel, Operator: corev1.NodeSelectorOpDoesNotExist},
+		{Key: ovnNodePoolLabel, Operator: corev1.NodeSelectorOpNotIn, Values: []string{"dpu-hosts", "dpus"}},
+	}}}))
+	_, ok := findContainer(ds.Spec.Template.Spec.Containers, "ovn-controller")
+	g.Expect(ok).To(BeTrue())
+
+	// the dpu-host pool runs its own ovnkube-node, in the dpu-host mode, with the build and the flags of
+	// the vendor of its DPUs
+	ds = &appsv1.DaemonSet{}
+	g.Expect(convert(findInObjs("apps", "DaemonSet", "ovnkube-node-dpu-host-dpu-hosts", "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+	g.Expect(ds.Spec.Selector.MatchLabels).To(Equal(map[string]string{"app": "ovnkube-node-dpu-host-dpu-hosts"}))
+	terms = ds.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	g.Expect(terms).To(Equal([]corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{
+		{Key: ovnNodePoolLabel, Operator: corev1.NodeSelectorOpIn, Values: []string{"dpu-hosts"}},
+		{Key: dpuNodeLabel, Operator: corev1.NodeSelectorOpDoesNotExist},
+		{Key: excludedNodeLabel, Operator: corev1.NodeSelectorOpDoesNotExist},
+	}}}))
+	_, ok = findContainer(ds.Spec.Template.Spec.Containers, "ovn-controller")
+	g.Expect(ok).To(BeFalse())
+	ovnkubeNode, ok := findContainer(ds.Spec.Template.Spec.Containers, "ovnkube-node")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(ovnkubeNode.Command[2]).To(ContainSubstring("--ovnkube-node-mode dpu-host"))
+	g.Expect(ovnkubeNode.Command[2]).To(ContainSubstring("--ovnkube-node-mgmt-port-netdev=enp3s0f0v0 \\\n"))
+	g.Expect(ovnkubeNode.Image).To(Equal(dpuHosts.Image))
+
+	// the dpu pool runs the error CNI, and kube-proxy
+	ds = &appsv1.DaemonSet{}
+	g.Expect(convert(findInObjs("apps", "DaemonSet", "error-cni-plugin-dpus", "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+	terms = ds.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	dpusTerms := []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{
+		{Key: ovnNodePoolLabel, Operator: corev1.NodeSelectorOpIn, Values: []string{"dpus"}},
+	}}}
+	g.Expect(terms).To(Equal(dpusTerms))
+	g.Expect(findInObjs("", "ConfigMap", "error-cni-script", "openshift-ovn-kubernetes", objs)).NotTo(BeNil())
+	// the configuration is left as is
+	g.Expect(*config.DeployKubeProxy).To(BeFalse())
+
+	objs, err = renderStandaloneKubeProxy(config, bootstrapResult, manifestDir)
+	g.Expect(err).NotTo(HaveOccurred())
+	ds = &appsv1.DaemonSet{}
+	g.Expect(convert(findInObjs("apps", "DaemonSet", "openshift-kube-proxy", "openshift-kube-proxy", objs), ds)).To(Succeed())
+	terms = ds.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	g.Expect(terms).To(Equal(dpusTerms))
+	g.Expect(*config.DeployKubeProxy).To(BeFalse())
+
+	// the deprecated ConfigMap keeps the unsuffixed daemonsets, and is reported
+	bootstrapResult.OVN.OVNKubernetesConfig.NodePools = []bootstrap.OVNNodePool{{
+		Mode:         OVN_NODE_MODE_DPU_HOST,
+		NodeSelector: []corev1.NodeSelectorRequirement{{Key: dpuHostNodeLabel, Operator: corev1.NodeSelectorOpExists}},
+	}}
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(findInObjs("apps", "DaemonSet", "ovnkube-node-dpu-host", "openshift-ovn-kubernetes", objs)).NotTo(BeNil())
+	g.Expect(findInObjs("apps", "DaemonSet", "error-cni-plugin", "openshift-ovn-kubernetes", objs)).To(BeNil())
+	g.Expect(findInObjs("", "ConfigMap", "error-cni-script", "openshift-ovn-kubernetes", objs)).To(BeNil())
+	g.Expect(bootstrapResult.Events).To(ContainElement(bootstrap.Event{
+		Type:   corev1.EventTypeWarning,
+		Reason: "DPUModeConfigDeprecated",
+		Message: "The dpu-mode-config ConfigMap is deprecated, create an OVNNodePool in the DPUHost mode for the " +
+			"MachineConfigPool of the nodes labeled network.operator.openshift.io/dpu-host instead",
+	}))
+}
+
+func TestNodePoolLabelsChanged(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	node := func(labels map[string]string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "a", Labels: labels}}
+	}
+	worker := map[string]string{"node-role.kubernetes.io/worker": ""}
+	g.Expect(NodePoolLabelsChanged(node(worker), node(worker))).To(BeFalse())
+	g.Expect(NodePoolLabelsChanged(node(nil), node(worker))).To(BeTrue())
+	g.Expect(NodePoolLabelsChanged(node(worker), node(nil))).To(BeTrue())
+	g.Expect(NodePoolLabelsChanged(node(worker), node(map[string]string{"node-role.kubernetes.io/dpu-host": ""}))).To(BeTrue())
+	g.Expect(NodePoolLabelsChanged(node(worker), node(map[string]string{"node-role.kubernetes.io/worker": "x"}))).To(BeTrue())
+
+	// the operator labels the nodes of the pools itself
+	g.Expect(NodePoolLabelsChanged(node(worker), node(map[string]string{
+		"node-role.kubernetes.io/worker": "",
+		ovnNodePoolLabel:                 "dpu-hosts",
+	}))).To(BeFalse())
+}
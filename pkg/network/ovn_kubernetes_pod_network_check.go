@@ -0,0 +1,75 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	podNetworkCheckPassed = "passed"
+	podNetworkCheckFailed = "failed"
+)
+
+// bootstrapPodNetworkChecks returns the results of the checks of the pod network annotated on the nodes
+func bootstrapPodNetworkChecks(kubeClient client.Reader, res *bootstrap.OVNBootstrapResult) error {
+	nodes := &corev1.NodeList{}
+	if err := kubeClient.List(context.TODO(), nodes); err != nil {
+		return fmt.Errorf("Failed to list the nodes: %w", err)
+	}
+	res.PodNetworkChecks = map[string]string{}
+	for _, node := range nodes.Items {
+		if check, ok := node.Annotations[names.PodNetworkCheckNodeAnnotation]; ok {
+			res.PodNetworkChecks[node.Name] = check
+		}
+	}
+	return nil
+}
+
+// podNetworkCheckResults returns, sorted, the nodes that failed the check of the pod network of
+// releaseVersion and those that did not report it yet. The nodes that never reported a check,
+// like the nodes ovnkube-node does not run on, are not waited for.
+func podNetworkCheckResults(checks map[string]string, releaseVersion string) (failed, pending []string) {
+	for node, check := range checks {
+		fields := strings.Fields(check)
+		switch {
+		case len(fields) != 2 || (fields[0] != podNetworkCheckPassed && fields[0] != podNetworkCheckFailed):
+			klog.Warningf("%s of node %s must be \"<%s|%s> <release>\", is: %q. Ignoring it",
+				names.PodNetworkCheckNodeAnnotation, node, podNetworkCheckPassed, podNetworkCheckFailed, check)
+		case fields[1] != releaseVersion:
+			pending = append(pending, node)
+		case fields[0] == podNetworkCheckFailed:
+			failed = append(failed, node)
+		}
+	}
+	sort.Strings(failed)
+	sort.Strings(pending)
+	return failed, pending
+}
+
+// shouldUpdateOVNKonPodNetworkCheck determines, on upgrades, if ovnkube-master can be rolled out
+// after ovnkube-node. The upgrade is halted while the nodes are checking the pod network of the new
+// release, and for as long as a node failed the check, so that an upgrade that broke the dataplane
+// does not carry on with the masters.
+func shouldUpdateOVNKonPodNetworkCheck(checks map[string]string, releaseVersion string, bootstrapResult *bootstrap.BootstrapResult) bool {
+	failed, pending := podNetworkCheckResults(checks, releaseVersion)
+	if len(failed) > 0 {
+		bootstrapResult.RecordEvent(corev1.EventTypeWarning, "PodNetworkCheckFailed",
+			"The pod network check of release %s failed on nodes %v, halting the ovnkube-master rollout until they pass it",
+			releaseVersion, failed)
+		return false
+	}
+	if len(pending) > 0 {
+		klog.Infof("Waiting for nodes %v to check the pod network before updating master", pending)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBootstrapPodNetworkChecks(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	client := fake.NewClientBuilder().WithObjects(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "a", Annotations: map[string]string{names.PodNetworkCheckNodeAnnotation: "passed 2.0.0"}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+	).Build()
+	res := &bootstrap.OVNBootstrapResult{}
+	g.Expect(bootstrapPodNetworkChecks(client, res)).To(Succeed())
+	g.Expect(res.PodNetworkChecks).To(Equal(map[string]string{"a": "passed 2.0.0"}))
+}
+
+func TestPodNetworkCheckResults(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	failed, pending := podNetworkCheckResults(map[string]string{
+		"a": "passed 2.0.0",
+		"b": "failed 2.0.0",
+		"c": "passed 1.0.0",
+		"d": "failed 1.0.0",
+		"e": "unknown 2.0.0",
+		"f": "passed",
+	}, "2.0.0")
+	g.Expect(failed).To(Equal([]string{"b"}))
+	g.Expect(pending).To(Equal([]string{"c", "d"}))
+}
+
+func TestShouldUpdateOVNKonPodNetworkCheck(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	res := &bootstrap.BootstrapResult{}
+	g.Expect(shouldUpdateOVNKonPodNetworkCheck(map[string]string{"a": "passed 2.0.0"}, "2.0.0", res)).To(BeTrue())
+	g.Expect(shouldUpdateOVNKonPodNetworkCheck(nil, "2.0.0", res)).To(BeTrue())
+	g.Expect(res.Events).To(BeEmpty())
+
+	// a node is still checking the new release
+	g.Expect(shouldUpdateOVNKonPodNetworkCheck(map[string]string{"a": "passed 2.0.0", "b": "passed 1.0.0"}, "2.0.0", res)).To(BeFalse())
+	g.Expect(res.Events).To(BeEmpty())
+
+	// the upgrade broke the dataplane of a node
+	g.Expect(shouldUpdateOVNKonPodNetworkCheck(map[string]string{"a": "passed 2.0.0", "b": "failed 2.0.0"}, "2.0.0", res)).To(BeFalse())
+	g.Expect(res.Events).To(Equal([]bootstrap.Event{{
+		Type:    corev1.EventTypeWarning,
+		Reason:  "PodNetworkCheckFailed",
+		Message: "The pod network check of release 2.0.0 failed on nodes [b], halting the ovnkube-master rollout until they pass it",
+	}}))
+}
+
+func TestOVNKNodeUpgradedBeforeMaster(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	ds := func(version string) *appsv1.DaemonSet {
+		return &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"release.openshift.io/version": version}}}
+	}
+	g.Expect(ovnkNodeUpgradedBeforeMaster(ds("2.0.0"), ds("1.0.0"), "2.0.0")).To(BeTrue())
+	g.Expect(ovnkNodeUpgradedBeforeMaster(ds("1.0.0"), ds("1.0.0"), "2.0.0")).To(BeFalse())
+	g.Expect(ovnkNodeUpgradedBeforeMaster(ds("2.0.0"), ds("2.0.0"), "2.0.0")).To(BeFalse())
+	g.Expect(ovnkNodeUpgradedBeforeMaster(nil, nil, "2.0.0")).To(BeFalse())
+}
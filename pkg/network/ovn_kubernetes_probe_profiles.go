@@ -0,0 +1,81 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// the bounds of the probe intervals, beyond which a peer is no longer detected as gone in time.
+// ovsdb rejects remote probe intervals under a second, but 0, which disables the probe.
+const (
+	minOVNRemoteProbeInterval   = 1000
+	maxOVNRemoteProbeInterval   = 3600000
+	maxOVNOpenFlowProbeInterval = 3600
+)
+
+// bootstrapOVNProbeProfiles returns the OVNProbeProfiles, sorted by name, which is the order they are matched
+// against the nodes in. Invalid profiles are ignored.
+func bootstrapOVNProbeProfiles(kubeClient client.Reader) ([]bootstrap.OVNProbeProfile, error) {
+	profiles := &netopv1.OVNProbeProfileList{}
+	if err := kubeClient.List(context.TODO(), profiles); err != nil {
+		// the CRD may not be installed yet during upgrades
+		if meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Failed to list the OVNProbeProfiles: %w", err)
+	}
+
+	res := []bootstrap.OVNProbeProfile{}
+	for _, profile := range profiles.Items {
+		selector, err := metav1.LabelSelectorAsSelector(profile.Spec.NodeSelector)
+		if err != nil {
+			klog.Warningf("OVNProbeProfile %s has an invalid nodeSelector. Ignoring it: %v", profile.Name, err)
+			continue
+		}
+		if err := validateOVNProbeProfile(&profile.Spec); err != nil {
+			klog.Warningf("OVNProbeProfile %s is invalid. Ignoring it: %v", profile.Name, err)
+			continue
+		}
+		res = append(res, bootstrap.OVNProbeProfile{
+			Name:                  profile.Name,
+			NodeSelector:          selector.String(),
+			RemoteProbeInterval:   formatProbeInterval(profile.Spec.RemoteProbeInterval),
+			OpenFlowProbeInterval: formatProbeInterval(profile.Spec.OpenFlowProbeInterval),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res, nil
+}
+
+// validateOVNProbeProfile checks that the profile sets a probe interval, and that the intervals are in bounds
+func validateOVNProbeProfile(spec *netopv1.OVNProbeProfileSpec) error {
+	if spec.RemoteProbeInterval == nil && spec.OpenFlowProbeInterval == nil {
+		return fmt.Errorf("it sets neither remoteProbeInterval nor openflowProbeInterval")
+	}
+	if v := spec.RemoteProbeInterval; v != nil && *v != 0 && (*v < minOVNRemoteProbeInterval || *v > maxOVNRemoteProbeInterval) {
+		return fmt.Errorf("remoteProbeInterval must be 0 or between %d and %d milliseconds, got %d",
+			minOVNRemoteProbeInterval, maxOVNRemoteProbeInterval, *v)
+	}
+	if v := spec.OpenFlowProbeInterval; v != nil && (*v < 0 || *v > maxOVNOpenFlowProbeInterval) {
+		return fmt.Errorf("openflowProbeInterval must be between 0 and %d seconds, got %d", maxOVNOpenFlowProbeInterval, *v)
+	}
+	return nil
+}
+
+// formatProbeInterval returns the interval as rendered, empty when unset
+func formatProbeInterval(interval *int32) string {
+	if interval == nil {
+		return ""
+	}
+	return strconv.Itoa(int(*interval))
+}
@@ -0,0 +1,108 @@
+package network
+
+import (
+	"testing"
+
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestBootstrapOVNProbeProfiles(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// the CRD is not installed
+	profiles, err := bootstrapOVNProbeProfiles(fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(profiles).To(BeEmpty())
+
+	profile := func(name string, spec netopv1.OVNProbeProfileSpec) *netopv1.OVNProbeProfile {
+		return &netopv1.OVNProbeProfile{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec}
+	}
+	interval := func(v int32) *int32 { return &v }
+	scheme := runtime.NewScheme()
+	g.Expect(netopv1.Install(scheme)).To(Succeed())
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		profile("wan-edge", netopv1.OVNProbeProfileSpec{
+			NodeSelector:          &metav1.LabelSelector{MatchLabels: map[string]string{"node-role.kubernetes.io/edge": ""}},
+			RemoteProbeInterval:   interval(300000),
+			OpenFlowProbeInterval: interval(0),
+		}),
+		profile("default", netopv1.OVNProbeProfileSpec{OpenFlowProbeInterval: interval(60)}),
+		profile("disabled", netopv1.OVNProbeProfileSpec{
+			NodeSelector:        &metav1.LabelSelector{MatchLabels: map[string]string{"example.com/satellite": "true"}},
+			RemoteProbeInterval: interval(0),
+		}),
+		// no interval
+		profile("empty", netopv1.OVNProbeProfileSpec{}),
+		// under the minimum remote probe interval
+		profile("too-short", netopv1.OVNProbeProfileSpec{RemoteProbeInterval: interval(500)}),
+		profile("too-long", netopv1.OVNProbeProfileSpec{OpenFlowProbeInterval: interval(7200)}),
+		profile("invalid-selector", netopv1.OVNProbeProfileSpec{
+			OpenFlowProbeInterval: interval(30),
+			NodeSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "node-role.kubernetes.io/edge", Operator: "Near"},
+			}},
+		}),
+	).Build()
+	profiles, err = bootstrapOVNProbeProfiles(cl)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(profiles).To(Equal([]bootstrap.OVNProbeProfile{
+		{Name: "default", OpenFlowProbeInterval: "60"},
+		{Name: "disabled", NodeSelector: "example.com/satellite=true", RemoteProbeInterval: "0"},
+		{
+			Name:                  "wan-edge",
+			NodeSelector:          "node-role.kubernetes.io/edge=",
+			RemoteProbeInterval:   "300000",
+			OpenFlowProbeInterval: "0",
+		},
+	}))
+}
+
+func TestRenderOVNKubernetesProbeProfiles(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+	nodeScript := func() string {
+		objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+		g.Expect(err).NotTo(HaveOccurred())
+		ds := &appsv1.DaemonSet{}
+		g.Expect(convert(findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+		cont, ok := findContainer(ds.Spec.Template.Spec.Containers, "ovnkube-node")
+		g.Expect(ok).To(BeTrue())
+		return cont.Command[len(cont.Command)-1]
+	}
+
+	g.Expect(nodeScript()).NotTo(ContainSubstring("probe_profile"))
+
+	bootstrapResult.OVN.OVNKubernetesConfig.ProbeProfiles = []bootstrap.OVNProbeProfile{
+		{Name: "default", OpenFlowProbeInterval: "60"},
+		{
+			Name:                  "wan-edge",
+			NodeSelector:          "node-role.kubernetes.io/edge=",
+			RemoteProbeInterval:   "300000",
+			OpenFlowProbeInterval: "0",
+		},
+	}
+	script := nodeScript()
+	g.Expect(script).To(ContainSubstring("matched=all\n"))
+	g.Expect(script).To(ContainSubstring(`kubectl get node "${K8S_NODE}" -l 'node-role.kubernetes.io/edge=' -o name`))
+	g.Expect(script).To(ContainSubstring(`OVN_CONTROLLER_INACTIVITY_PROBE="300000"`))
+	g.Expect(script).To(ContainSubstring(`openflow_probe_flag="--openflow-probe 60"`))
+	g.Expect(script).To(ContainSubstring(`openflow_probe_flag="--openflow-probe 0"`))
+	g.Expect(script).To(ContainSubstring("probe profile default: remote probe interval default, OpenFlow probe interval 60"))
+	g.Expect(script).To(ContainSubstring("${openflow_probe_flag} \\\n"))
+}
@@ -0,0 +1,193 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	iputil "github.com/openshift/cluster-network-operator/pkg/util/ip"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// bootstrapOVNReservedNodeSubnets returns the ranges of the cluster networks that no node subnet is
+// allocated from, as set by an annotation on the operator configuration. A range must be made of whole
+// node subnets of a single cluster network, and leave some of it to allocate from. A range that node
+// subnets were already allocated from is ignored, as ovnkube-master would allocate them again.
+func bootstrapOVNReservedNodeSubnets(conf *operv1.Network, kubeClient client.Reader) ([]string, error) {
+	v, ok := conf.GetAnnotations()[names.OVNReservedNodeSubnetsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	allocated, err := ovnAllocatedNodeSubnets(kubeClient)
+	if err != nil {
+		return nil, err
+	}
+
+	reserved := []string{}
+	for _, s := range strings.Split(v, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		ip, cidr, err := net.ParseCIDR(s)
+		if err != nil || !ip.Equal(cidr.IP) {
+			klog.Warningf("%s must be a comma-separated list of CIDRs, %q is not. Ignoring it", names.OVNReservedNodeSubnetsAnnotation, s)
+			continue
+		}
+		cn := ovnContainingClusterNetwork(&conf.Spec, cidr)
+		if cn == nil {
+			klog.Warningf("%s: %s is not part of a cluster network. Ignoring it", names.OVNReservedNodeSubnetsAnnotation, s)
+			continue
+		}
+		ones, _ := cidr.Mask.Size()
+		if ones > int(cn.HostPrefix) {
+			klog.Warningf("%s: %s is smaller than the /%d node subnets of cluster network %s. Ignoring it",
+				names.OVNReservedNodeSubnetsAnnotation, s, cn.HostPrefix, cn.CIDR)
+			continue
+		}
+		conflict := ""
+		for node, subnets := range allocated {
+			for _, subnet := range subnets {
+				if iputil.NetsOverlap(*cidr, subnet) {
+					conflict = node
+				}
+			}
+		}
+		if conflict != "" {
+			klog.Warningf("%s: %s is already allocated from, to node %s among others. Ignoring it",
+				names.OVNReservedNodeSubnetsAnnotation, s, conflict)
+			continue
+		}
+		reserved = append(reserved, cidr.String())
+	}
+
+	// some of every cluster network is left to allocate from
+	for _, cn := range conf.Spec.ClusterNetwork {
+		_, cidr, err := net.ParseCIDR(cn.CIDR)
+		if err != nil {
+			continue
+		}
+		if len(subtractCIDRs(cidr, parseCIDRs(reserved))) == 0 {
+			klog.Warningf("%s reserves the whole cluster network %s. Ignoring it", names.OVNReservedNodeSubnetsAnnotation, cn.CIDR)
+			return nil, nil
+		}
+	}
+	return reserved, nil
+}
+
+// ovnAllocatedNodeSubnets returns the node subnets ovnkube-master allocated, by node
+func ovnAllocatedNodeSubnets(kubeClient client.Reader) (map[string][]net.IPNet, error) {
+	nodes := &corev1.NodeList{}
+	if err := kubeClient.List(context.TODO(), nodes); err != nil {
+		return nil, fmt.Errorf("Failed to list the nodes to check the reserved node subnets against: %w", err)
+	}
+	allocated := map[string][]net.IPNet{}
+	for i := range nodes.Items {
+		subnets, err := OVNNodeSubnets(&nodes.Items[i])
+		if err != nil {
+			klog.Warningf("Ignoring invalid %s annotation of node %s: %v", names.OVNNodeSubnetsAnnotation, nodes.Items[i].Name, err)
+			continue
+		}
+		for _, subnet := range parseCIDRs(subnets) {
+			allocated[nodes.Items[i].Name] = append(allocated[nodes.Items[i].Name], *subnet)
+		}
+	}
+	return allocated, nil
+}
+
+// OVNNodeSubnets returns the subnets of the default network ovnkube-master allocated to the node, if
+// any, from the node subnets annotation.
+func OVNNodeSubnets(node *corev1.Node) ([]string, error) {
+	annotation, ok := node.Annotations[names.OVNNodeSubnetsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	nodeSubnets := map[string]json.RawMessage{}
+	if err := json.Unmarshal([]byte(annotation), &nodeSubnets); err != nil {
+		return nil, err
+	}
+	subnets, ok := nodeSubnets["default"]
+	if !ok {
+		return nil, nil
+	}
+	var single string
+	var multiple []string
+	if err := json.Unmarshal(subnets, &single); err == nil {
+		return []string{single}, nil
+	}
+	if err := json.Unmarshal(subnets, &multiple); err != nil {
+		return nil, err
+	}
+	return multiple, nil
+}
+
+// ovnContainingClusterNetwork returns the cluster network cidr is part of, if any
+func ovnContainingClusterNetwork(conf *operv1.NetworkSpec, cidr *net.IPNet) *operv1.ClusterNetworkEntry {
+	for i, cn := range conf.ClusterNetwork {
+		_, cnCIDR, err := net.ParseCIDR(cn.CIDR)
+		if err == nil && iputil.NetIncludes(*cnCIDR, *cidr) {
+			return &conf.ClusterNetwork[i]
+		}
+	}
+	return nil
+}
+
+// ovnClusterSubnets returns the "<cidr>/<hostPrefix>" ranges the node subnets are allocated from: the
+// cluster networks, without the reserved ranges.
+func ovnClusterSubnets(conf *operv1.NetworkSpec, reserved []string) string {
+	reservedCIDRs := parseCIDRs(reserved)
+	ranges := []string{}
+	for _, cn := range conf.ClusterNetwork {
+		_, cidr, err := net.ParseCIDR(cn.CIDR)
+		if err != nil || len(reservedCIDRs) == 0 {
+			ranges = append(ranges, fmt.Sprintf("%s/%d", cn.CIDR, cn.HostPrefix))
+			continue
+		}
+		for _, r := range subtractCIDRs(cidr, reservedCIDRs) {
+			ranges = append(ranges, fmt.Sprintf("%s/%d", r.String(), cn.HostPrefix))
+		}
+	}
+	return strings.Join(ranges, ",")
+}
+
+// subtractCIDRs returns the smallest set of CIDRs covering cidr without the reserved ones, in order
+func subtractCIDRs(cidr *net.IPNet, reserved []*net.IPNet) []*net.IPNet {
+	overlaps := false
+	for _, r := range reserved {
+		if iputil.NetIncludes(*r, *cidr) {
+			return nil
+		}
+		if iputil.NetsOverlap(*r, *cidr) {
+			overlaps = true
+		}
+	}
+	if !overlaps {
+		return []*net.IPNet{cidr}
+	}
+	// split the CIDR in halves, the reserved CIDRs are at most as long as the node subnets
+	ones, bits := cidr.Mask.Size()
+	mask := net.CIDRMask(ones+1, bits)
+	low := &net.IPNet{IP: cidr.IP, Mask: mask}
+	high := &net.IPNet{IP: make(net.IP, len(cidr.IP)), Mask: mask}
+	copy(high.IP, cidr.IP)
+	high.IP[ones/8] |= 0x80 >> uint(ones%8)
+	return append(subtractCIDRs(low, reserved), subtractCIDRs(high, reserved)...)
+}
+
+// parseCIDRs parses the valid CIDRs of the list
+func parseCIDRs(cidrs []string) []*net.IPNet {
+	out := []*net.IPNet{}
+	for _, s := range cidrs {
+		if _, cidr, err := net.ParseCIDR(s); err == nil {
+			out = append(out, cidr)
+		}
+	}
+	return out
+}
@@ -0,0 +1,96 @@
+package network
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestOVNClusterSubnets(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	conf := &operv1.NetworkSpec{ClusterNetwork: []operv1.ClusterNetworkEntry{
+		{CIDR: "10.128.0.0/14", HostPrefix: 23},
+		{CIDR: "fd00:10:128::/56", HostPrefix: 64},
+	}}
+	g.Expect(ovnClusterSubnets(conf, nil)).To(Equal("10.128.0.0/14/23,fd00:10:128::/56/64"))
+
+	// the reserved ranges are carved out of their cluster network
+	g.Expect(ovnClusterSubnets(conf, []string{"10.130.0.0/15"})).To(Equal("10.128.0.0/15/23,fd00:10:128::/56/64"))
+	g.Expect(ovnClusterSubnets(conf, []string{"10.129.0.0/16", "fd00:10:128:80::/57"})).To(Equal(
+		"10.128.0.0/16/23,10.130.0.0/15/23,fd00:10:128::/57/64"))
+	g.Expect(ovnClusterSubnets(conf, []string{"10.128.0.0/23"})).To(Equal(
+		"10.128.2.0/23/23,10.128.4.0/22/23,10.128.8.0/21/23,10.128.16.0/20/23,10.128.32.0/19/23," +
+			"10.128.64.0/18/23,10.128.128.0/17/23,10.129.0.0/16/23,10.130.0.0/15/23,fd00:10:128::/56/64"))
+}
+
+func TestBootstrapOVNReservedNodeSubnets(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:        "worker-0",
+		Annotations: map[string]string{names.OVNNodeSubnetsAnnotation: `{"default":["10.128.2.0/23","fd00:10:128::/64"]}`},
+	}}
+	kubeClient := fake.NewClientBuilder().WithObjects(node).Build()
+	conf := &operv1.Network{Spec: operv1.NetworkSpec{ClusterNetwork: []operv1.ClusterNetworkEntry{
+		{CIDR: "10.128.0.0/14", HostPrefix: 23},
+		{CIDR: "fd00:10:128::/56", HostPrefix: 64},
+	}}}
+
+	reserved, err := bootstrapOVNReservedNodeSubnets(conf, kubeClient)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(reserved).To(BeEmpty())
+
+	for v, expected := range map[string][]string{
+		"10.130.0.0/15, fd00:10:128:80::/57": {"10.130.0.0/15", "fd00:10:128:80::/57"},
+		// not a network address, nor a CIDR
+		"10.130.0.1/15,10.130.0.0": {},
+		// outside of the cluster networks
+		"10.0.0.0/16,10.130.0.0/15": {"10.130.0.0/15"},
+		// smaller than a node subnet
+		"10.130.0.0/24": {},
+		// already allocated from
+		"10.128.0.0/16,fd00:10:128::/60": {},
+	} {
+		conf.Annotations = map[string]string{names.OVNReservedNodeSubnetsAnnotation: v}
+		reserved, err := bootstrapOVNReservedNodeSubnets(conf, kubeClient)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(reserved).To(ConsistOf(expected), v)
+	}
+
+	// something is left of every cluster network
+	conf.Annotations = map[string]string{names.OVNReservedNodeSubnetsAnnotation: "10.128.0.0/15,10.130.0.0/15"}
+	reserved, err = bootstrapOVNReservedNodeSubnets(conf, fake.NewClientBuilder().Build())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(reserved).To(BeNil())
+}
+
+func TestOVNNodeSubnets(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-0"}}
+	subnets, err := OVNNodeSubnets(node)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(subnets).To(BeNil())
+
+	node.Annotations = map[string]string{names.OVNNodeSubnetsAnnotation: `{"default":"10.128.2.0/23"}`}
+	subnets, err = OVNNodeSubnets(node)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(subnets).To(Equal([]string{"10.128.2.0/23"}))
+
+	node.Annotations[names.OVNNodeSubnetsAnnotation] = `{"default":["10.128.2.0/23","fd00:10:128::/64"]}`
+	subnets, err = OVNNodeSubnets(node)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(subnets).To(Equal([]string{"10.128.2.0/23", "fd00:10:128::/64"}))
+
+	for _, invalid := range []string{`10.128.2.0/23`, `{"default":1}`} {
+		node.Annotations[names.OVNNodeSubnetsAnnotation] = invalid
+		_, err = OVNNodeSubnets(node)
+		g.Expect(err).To(HaveOccurred(), invalid)
+	}
+}
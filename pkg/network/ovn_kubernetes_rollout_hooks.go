@@ -0,0 +1,253 @@
+package network
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/openshift/cluster-network-operator/pkg/util/k8s"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The ovn-rollout-hooks ConfigMap registers the Jobs run around the rollouts of ovnkube-node to a new
+// release. Its pre-node-rollout and post-node-rollout keys hold the specs of these Jobs, in YAML.
+const (
+	OVNRolloutHooksConfigMapName      = "ovn-rollout-hooks"
+	OVNRolloutHooksConfigMapNamespace = names.APPLIED_NAMESPACE
+
+	OVN_PRE_NODE_ROLLOUT_HOOK  = "pre-node-rollout"
+	OVN_POST_NODE_ROLLOUT_HOOK = "post-node-rollout"
+)
+
+// ovnRolloutHooksServiceAccount is the service account the Jobs of the hooks run with, which is granted
+// nothing
+const ovnRolloutHooksServiceAccount = "ovn-kubernetes-rollout-hooks"
+
+// ovnNodePreviousReleaseAnnotation is the annotation of the node daemonset with the release it was
+// last rolled out from. It is not set on fresh installs.
+const ovnNodePreviousReleaseAnnotation = "networkoperator.openshift.io/previous-release-version"
+
+// bootstrapOVNRolloutHooks fills in the specs of the pre and post node rollout hooks registered in the
+// ovn-rollout-hooks ConfigMap, along with their existing Jobs.
+func bootstrapOVNRolloutHooks(kubeClient client.Reader, res *bootstrap.OVNBootstrapResult) error {
+	cm := &corev1.ConfigMap{}
+	nsn := types.NamespacedName{Namespace: OVNRolloutHooksConfigMapNamespace, Name: OVNRolloutHooksConfigMapName}
+	if err := kubeClient.Get(context.TODO(), nsn, cm); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("Failed to retrieve the %s configmap: %w", OVNRolloutHooksConfigMapName, err)
+		}
+		return nil
+	}
+
+	var err error
+	res.PreNodeRolloutHook = parseOVNRolloutHook(cm, OVN_PRE_NODE_ROLLOUT_HOOK)
+	if res.PreNodeRolloutHook != nil {
+		if res.PreNodeRolloutJob, err = getOVNRolloutHookJob(kubeClient, OVN_PRE_NODE_ROLLOUT_HOOK); err != nil {
+			return err
+		}
+	}
+	res.PostNodeRolloutHook = parseOVNRolloutHook(cm, OVN_POST_NODE_ROLLOUT_HOOK)
+	if res.PostNodeRolloutHook != nil {
+		if res.PostNodeRolloutJob, err = getOVNRolloutHookJob(kubeClient, OVN_POST_NODE_ROLLOUT_HOOK); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseOVNRolloutHook returns the Job spec of the given hook, or nil if it is not registered or invalid
+func parseOVNRolloutHook(cm *corev1.ConfigMap, hook string) *batchv1.JobSpec {
+	data, ok := cm.Data[hook]
+	if !ok {
+		return nil
+	}
+	spec := &batchv1.JobSpec{}
+	if err := yaml.Unmarshal([]byte(data), spec); err != nil {
+		klog.Warningf("%s: %s is not a valid Job spec. Ignoring it: %v", OVNRolloutHooksConfigMapName, hook, err)
+		return nil
+	}
+	if len(spec.Template.Spec.Containers) == 0 {
+		klog.Warningf("%s: %s has no containers. Ignoring it", OVNRolloutHooksConfigMapName, hook)
+		return nil
+	}
+	if reason := ovnRolloutHookForbidden(&spec.Template.Spec); reason != "" {
+		klog.Warningf("%s: %s %s. Ignoring it", OVNRolloutHooksConfigMapName, hook, reason)
+		return nil
+	}
+	if spec.Template.Spec.RestartPolicy == "" {
+		spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+	}
+	// the hooks do not inherit the permissions of the service accounts of the namespace
+	spec.Template.Spec.ServiceAccountName = ovnRolloutHooksServiceAccount
+	spec.Template.Spec.DeprecatedServiceAccount = ""
+	automountToken := false
+	spec.Template.Spec.AutomountServiceAccountToken = &automountToken
+	return spec
+}
+
+// ovnRolloutHookForbidden returns why the pod spec of a hook cannot be run by the operator, if it
+// reaches into the hosts or runs with more privileges than a restricted pod: the hooks are registered
+// in a ConfigMap, which should not grant more than the pods its editors can run themselves.
+func ovnRolloutHookForbidden(spec *corev1.PodSpec) string {
+	if spec.HostNetwork || spec.HostPID || spec.HostIPC {
+		return "uses the host namespaces"
+	}
+	if sc := spec.SecurityContext; sc != nil && sc.RunAsUser != nil && *sc.RunAsUser == 0 {
+		return "runs as root"
+	}
+	for _, volume := range spec.Volumes {
+		if volume.HostPath != nil {
+			return fmt.Sprintf("mounts the host path %s", volume.HostPath.Path)
+		}
+	}
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for _, container := range containers {
+			sc := container.SecurityContext
+			if sc == nil {
+				continue
+			}
+			if sc.Privileged != nil && *sc.Privileged {
+				return fmt.Sprintf("runs the privileged container %s", container.Name)
+			}
+			if sc.Capabilities != nil && len(sc.Capabilities.Add) > 0 {
+				return fmt.Sprintf("adds capabilities to the container %s", container.Name)
+			}
+			if sc.RunAsUser != nil && *sc.RunAsUser == 0 {
+				return fmt.Sprintf("runs the container %s as root", container.Name)
+			}
+			if sc.AllowPrivilegeEscalation != nil && *sc.AllowPrivilegeEscalation {
+				return fmt.Sprintf("allows the privilege escalation of the container %s", container.Name)
+			}
+		}
+	}
+	return ""
+}
+
+// ovnNodePreviousReleaseVersion returns the release the node daemonset is rolled out from, which is
+// kept once it is rolled out, or "" on fresh installs.
+func ovnNodePreviousReleaseVersion(existingNode *appsv1.DaemonSet, releaseVersion string) string {
+	if existingNode == nil {
+		return ""
+	}
+	if version := existingNode.GetAnnotations()["release.openshift.io/version"]; version != releaseVersion {
+		return version
+	}
+	return existingNode.GetAnnotations()[ovnNodePreviousReleaseAnnotation]
+}
+
+// ovnRolloutHookJobName returns the name of the Job of the given hook
+func ovnRolloutHookJobName(hook string) string {
+	return "ovnkube-node-" + hook + "-hook"
+}
+
+func getOVNRolloutHookJob(kubeClient client.Reader, hook string) (*batchv1.Job, error) {
+	job := &batchv1.Job{}
+	nsn := types.NamespacedName{Namespace: "openshift-ovn-kubernetes", Name: ovnRolloutHookJobName(hook)}
+	if err := kubeClient.Get(context.TODO(), nsn, job); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("Failed to retrieve existing %s hook Job: %w", hook, err)
+		}
+		return nil, nil
+	}
+	return job, nil
+}
+
+// renderOVNRolloutHookJob renders the Job of a hook for the given release. Like the pre-puller Job,
+// it is create-only: a Job left over from another release is not rendered, so that it is
+// garbage-collected, and a fresh one is rendered on the next pass.
+func renderOVNRolloutHookJob(hook string, spec *batchv1.JobSpec, releaseVersion string) (*uns.Unstructured, error) {
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{APIVersion: "batch/v1", Kind: "Job"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ovnRolloutHookJobName(hook),
+			Namespace: "openshift-ovn-kubernetes",
+			Annotations: map[string]string{
+				"kubernetes.io/description":    fmt.Sprintf("This job runs the %s hook of the ovnkube-node rollouts.", hook),
+				"release.openshift.io/version": releaseVersion,
+				names.CreateOnlyAnnotation:     "true",
+			},
+		},
+		Spec: *spec.DeepCopy(),
+	}
+	return k8s.ToUnstructured(job)
+}
+
+// shouldUpdateOVNKonPreRolloutHook determines if the node daemonset can be rolled out to a new release,
+// which is the case once the pre-node-rollout hook Job of that release has succeeded. A failed Job pauses
+// the rollout until it is deleted, to run it again, or the hook is removed.
+func shouldUpdateOVNKonPreRolloutHook(existingNode *appsv1.DaemonSet, hook *batchv1.Job, releaseVersion string) (updateNode, renderHook, failed bool) {
+	// Fresh cluster, or no new release to roll out
+	if existingNode == nil || existingNode.GetAnnotations()["release.openshift.io/version"] == releaseVersion {
+		return true, false, false
+	}
+
+	if hook == nil {
+		klog.Infof("Running the %s hook before updating node", OVN_PRE_NODE_ROLLOUT_HOOK)
+		return false, true, false
+	}
+
+	hookVersion := hook.GetAnnotations()["release.openshift.io/version"]
+	if hookVersion != releaseVersion {
+		klog.Infof("Removing %s hook job for release %q before running it for %q", OVN_PRE_NODE_ROLLOUT_HOOK, hookVersion, releaseVersion)
+		return false, false, false
+	}
+
+	if jobSucceeded(hook) {
+		klog.Infof("OVN-Kube %s hook succeeded, now starting node rollouts", OVN_PRE_NODE_ROLLOUT_HOOK)
+		return true, true, false
+	}
+	if jobFinished(hook) {
+		klog.Warningf("OVN-Kube %s hook failed, pausing node rollouts", OVN_PRE_NODE_ROLLOUT_HOOK)
+		return false, true, true
+	}
+
+	klog.Infof("Waiting for the %s hook to complete before updating node", OVN_PRE_NODE_ROLLOUT_HOOK)
+	return false, true, false
+}
+
+// shouldRenderOVNKPostRolloutHook determines if the post-node-rollout hook Job should be rendered, which
+// is the case once the node daemonset has rolled out a release from a previous one, and if the hook has
+// succeeded for the release the node daemonset is at.
+func shouldRenderOVNKPostRolloutHook(existingNode *appsv1.DaemonSet, hook *batchv1.Job, releaseVersion string) (renderHook, succeeded, failed bool) {
+	if existingNode == nil || existingNode.GetAnnotations()["release.openshift.io/version"] != releaseVersion {
+		return false, false, false
+	}
+
+	// Fresh cluster, nothing was rolled out
+	if existingNode.GetAnnotations()[ovnNodePreviousReleaseAnnotation] == "" {
+		return false, false, false
+	}
+
+	if hook != nil && hook.GetAnnotations()["release.openshift.io/version"] != releaseVersion {
+		klog.Infof("Removing %s hook job for release %q", OVN_POST_NODE_ROLLOUT_HOOK, hook.GetAnnotations()["release.openshift.io/version"])
+		return false, false, false
+	}
+
+	if hook == nil {
+		if daemonSetProgressing(existingNode, true) {
+			klog.Infof("Waiting for OVN-Kubernetes node rollout before running the %s hook", OVN_POST_NODE_ROLLOUT_HOOK)
+			return false, false, false
+		}
+		klog.Infof("Running the %s hook", OVN_POST_NODE_ROLLOUT_HOOK)
+		return true, false, false
+	}
+
+	if jobSucceeded(hook) {
+		return true, true, false
+	}
+	if jobFinished(hook) {
+		klog.Warningf("OVN-Kube %s hook failed", OVN_POST_NODE_ROLLOUT_HOOK)
+		return true, false, true
+	}
+	return true, false, false
+}
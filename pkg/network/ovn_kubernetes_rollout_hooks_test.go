@@ -0,0 +1,284 @@
+package network
+
+import (
+	"os"
+	"testing"
+
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestBootstrapOVNRolloutHooks(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	res := &bootstrap.OVNBootstrapResult{}
+	g.Expect(bootstrapOVNRolloutHooks(fake.NewClientBuilder().Build(), res)).To(Succeed())
+	g.Expect(res.PreNodeRolloutHook).To(BeNil())
+	g.Expect(res.PostNodeRolloutHook).To(BeNil())
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: OVNRolloutHooksConfigMapName, Namespace: OVNRolloutHooksConfigMapNamespace},
+		Data: map[string]string{
+			OVN_PRE_NODE_ROLLOUT_HOOK: `
+template:
+  spec:
+    containers:
+    - name: smoke-test
+      image: quay.io/example/smoke-test
+`,
+			// no containers
+			OVN_POST_NODE_ROLLOUT_HOOK: `backoffLimit: 1`,
+		},
+	}
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "ovnkube-node-pre-node-rollout-hook", Namespace: "openshift-ovn-kubernetes"},
+	}
+	res = &bootstrap.OVNBootstrapResult{}
+	g.Expect(bootstrapOVNRolloutHooks(fake.NewClientBuilder().WithObjects(cm, job).Build(), res)).To(Succeed())
+	g.Expect(res.PreNodeRolloutHook).NotTo(BeNil())
+	g.Expect(res.PreNodeRolloutHook.Template.Spec.Containers[0].Image).To(Equal("quay.io/example/smoke-test"))
+	g.Expect(res.PreNodeRolloutHook.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+	g.Expect(res.PreNodeRolloutJob).NotTo(BeNil())
+	g.Expect(res.PostNodeRolloutHook).To(BeNil())
+	g.Expect(res.PostNodeRolloutJob).To(BeNil())
+}
+
+func TestRenderOVNKubernetesRolloutHooks(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+	os.Setenv("RELEASE_VERSION", "2.0.0")
+
+	node := &appsv1.DaemonSet{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "DaemonSet"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "ovnkube-node",
+			Namespace:   "openshift-ovn-kubernetes",
+			Annotations: map[string]string{"release.openshift.io/version": "1.9.9"},
+		},
+	}
+	master := node.DeepCopy()
+	master.Name = "ovnkube-master"
+	hook := &batchv1.JobSpec{
+		Template: corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers:    []corev1.Container{{Name: "smoke-test", Image: "quay.io/example/smoke-test"}},
+				RestartPolicy: corev1.RestartPolicyNever,
+			},
+		},
+	}
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:               []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			ExistingNodeDaemonset:   node,
+			ExistingMasterDaemonset: master,
+			PreNodeRolloutHook:      hook,
+			PostNodeRolloutHook:     hook,
+			DBSchemaCheckJob:        ovnDBSchemaCheckJob("2.0.0", batchv1.JobComplete),
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{
+				PrePullerMode: OVN_PREPULLER_MODE_DISABLED,
+			},
+		},
+	}
+	hookJob := func(condition batchv1.JobConditionType) *batchv1.Job {
+		job := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"release.openshift.io/version": "2.0.0"}},
+		}
+		if condition != "" {
+			job.Status.Conditions = []batchv1.JobCondition{{Type: condition, Status: corev1.ConditionTrue}}
+		}
+		return job
+	}
+	rendered := func(name string) (*appsv1.DaemonSet, []*uns.Unstructured) {
+		objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+		g.Expect(err).NotTo(HaveOccurred())
+		ds := &appsv1.DaemonSet{}
+		g.Expect(convert(findInObjs("apps", "DaemonSet", name, "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+		return ds, objs
+	}
+
+	// the node is held until the pre-node-rollout hook succeeds
+	ds, objs := rendered("ovnkube-node")
+	g.Expect(ds.Spec.Template.Spec.Containers).To(BeEmpty())
+	job := findInObjs("batch", "Job", "ovnkube-node-pre-node-rollout-hook", "openshift-ovn-kubernetes", objs)
+	g.Expect(job).NotTo(BeNil())
+	g.Expect(job.GetAnnotations()).To(HaveKeyWithValue(names.CreateOnlyAnnotation, "true"))
+	g.Expect(findInObjs("", "ServiceAccount", ovnRolloutHooksServiceAccount, "openshift-ovn-kubernetes", objs)).NotTo(BeNil())
+	g.Expect(findInObjs("batch", "Job", "ovnkube-node-post-node-rollout-hook", "openshift-ovn-kubernetes", objs)).To(BeNil())
+
+	bootstrapResult.OVN.PreNodeRolloutJob = hookJob(batchv1.JobFailed)
+	ds, _ = rendered("ovnkube-node")
+	g.Expect(ds.Spec.Template.Spec.Containers).To(BeEmpty())
+	g.Expect(bootstrapResult.Events).To(HaveLen(1))
+	g.Expect(bootstrapResult.Events[0].Reason).To(Equal("NodeRolloutHookFailed"))
+
+	bootstrapResult.OVN.PreNodeRolloutJob = hookJob(batchv1.JobComplete)
+	ds, _ = rendered("ovnkube-node")
+	g.Expect(ds.Spec.Template.Spec.Containers).NotTo(BeEmpty())
+	g.Expect(ds.Annotations).To(HaveKeyWithValue(ovnNodePreviousReleaseAnnotation, "1.9.9"))
+
+	// once the node is rolled out, the master is held until the post-node-rollout hook succeeds
+	node.Annotations = ds.Annotations
+	node.Status = appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberAvailable: 3}
+	bootstrapResult.OVN.PreNodeRolloutJob = nil
+	ds, objs = rendered("ovnkube-master")
+	g.Expect(ds.Spec.Template.Spec.Containers).To(BeEmpty())
+	g.Expect(findInObjs("batch", "Job", "ovnkube-node-pre-node-rollout-hook", "openshift-ovn-kubernetes", objs)).To(BeNil())
+	g.Expect(findInObjs("batch", "Job", "ovnkube-node-post-node-rollout-hook", "openshift-ovn-kubernetes", objs)).NotTo(BeNil())
+
+	bootstrapResult.OVN.PostNodeRolloutJob = hookJob("")
+	ds, _ = rendered("ovnkube-master")
+	g.Expect(ds.Spec.Template.Spec.Containers).To(BeEmpty())
+
+	bootstrapResult.OVN.PostNodeRolloutJob = hookJob(batchv1.JobComplete)
+	ds, objs = rendered("ovnkube-master")
+	g.Expect(ds.Spec.Template.Spec.Containers).NotTo(BeEmpty())
+	g.Expect(findInObjs("batch", "Job", "ovnkube-node-post-node-rollout-hook", "openshift-ovn-kubernetes", objs)).NotTo(BeNil())
+
+	// the previous release is kept once the node is rolled out
+	ds, _ = rendered("ovnkube-node")
+	g.Expect(ds.Annotations).To(HaveKeyWithValue(ovnNodePreviousReleaseAnnotation, "1.9.9"))
+
+	// but there is no post-node-rollout hook on fresh installs
+	delete(node.Annotations, ovnNodePreviousReleaseAnnotation)
+	bootstrapResult.OVN.PostNodeRolloutJob = nil
+	_, objs = rendered("ovnkube-master")
+	g.Expect(findInObjs("batch", "Job", "ovnkube-node-post-node-rollout-hook", "openshift-ovn-kubernetes", objs)).To(BeNil())
+}
+
+func TestParseOVNRolloutHookForbidden(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	hook := func(spec string) *batchv1.JobSpec {
+		cm := &corev1.ConfigMap{Data: map[string]string{OVN_PRE_NODE_ROLLOUT_HOOK: spec}}
+		return parseOVNRolloutHook(cm, OVN_PRE_NODE_ROLLOUT_HOOK)
+	}
+	spec := hook(`
+template:
+  spec:
+    serviceAccountName: ovn-kubernetes-controller
+    automountServiceAccountToken: true
+    containers:
+    - name: smoke-test
+      image: quay.io/example/smoke-test
+      securityContext:
+        privileged: false
+        allowPrivilegeEscalation: false
+        runAsUser: 1000
+        capabilities:
+          drop: [ALL]
+`)
+	g.Expect(spec).NotTo(BeNil())
+	// the hooks run with their own service account, without token
+	g.Expect(spec.Template.Spec.ServiceAccountName).To(Equal(ovnRolloutHooksServiceAccount))
+	g.Expect(*spec.Template.Spec.AutomountServiceAccountToken).To(BeFalse())
+
+	for _, forbidden := range []string{`
+template:
+  spec:
+    hostNetwork: true
+    containers:
+    - name: smoke-test
+      image: quay.io/example/smoke-test
+`, `
+template:
+  spec:
+    containers:
+    - name: smoke-test
+      image: quay.io/example/smoke-test
+    volumes:
+    - name: host
+      hostPath:
+        path: /
+`, `
+template:
+  spec:
+    initContainers:
+    - name: setup
+      image: quay.io/example/smoke-test
+      securityContext:
+        privileged: true
+    containers:
+    - name: smoke-test
+      image: quay.io/example/smoke-test
+`, `
+template:
+  spec:
+    containers:
+    - name: smoke-test
+      image: quay.io/example/smoke-test
+      securityContext:
+        capabilities:
+          add: [NET_ADMIN, SYS_ADMIN]
+`, `
+template:
+  spec:
+    initContainers:
+    - name: setup
+      image: quay.io/example/smoke-test
+      securityContext:
+        capabilities:
+          add: [NET_RAW]
+    containers:
+    - name: smoke-test
+      image: quay.io/example/smoke-test
+`, `
+template:
+  spec:
+    securityContext:
+      runAsUser: 0
+    containers:
+    - name: smoke-test
+      image: quay.io/example/smoke-test
+`, `
+template:
+  spec:
+    containers:
+    - name: smoke-test
+      image: quay.io/example/smoke-test
+      securityContext:
+        runAsUser: 0
+`, `
+template:
+  spec:
+    initContainers:
+    - name: setup
+      image: quay.io/example/smoke-test
+      securityContext:
+        runAsUser: 0
+    containers:
+    - name: smoke-test
+      image: quay.io/example/smoke-test
+`, `
+template:
+  spec:
+    containers:
+    - name: smoke-test
+      image: quay.io/example/smoke-test
+      securityContext:
+        allowPrivilegeEscalation: true
+`, `
+template:
+  spec:
+    initContainers:
+    - name: setup
+      image: quay.io/example/smoke-test
+      securityContext:
+        allowPrivilegeEscalation: true
+    containers:
+    - name: smoke-test
+      image: quay.io/example/smoke-test
+`} {
+		g.Expect(hook(forbidden)).To(BeNil(), forbidden)
+	}
+}
@@ -0,0 +1,99 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxBGPASN is the largest 4-byte autonomous system number
+const maxBGPASN = 4294967295
+
+// bootstrapOVNRouteAdvertisements returns the OVNRouteAdvertisements, sorted by name, which is the order they
+// are matched against the nodes in. Invalid advertisements are ignored.
+func bootstrapOVNRouteAdvertisements(kubeClient client.Reader) ([]bootstrap.OVNRouteAdvertisement, error) {
+	advertisements := &netopv1.OVNRouteAdvertisementList{}
+	if err := kubeClient.List(context.TODO(), advertisements); err != nil {
+		// the CRD may not be installed yet during upgrades
+		if meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Failed to list the OVNRouteAdvertisements: %w", err)
+	}
+
+	res := []bootstrap.OVNRouteAdvertisement{}
+	for _, advertisement := range advertisements.Items {
+		selector, err := metav1.LabelSelectorAsSelector(advertisement.Spec.NodeSelector)
+		if err != nil {
+			klog.Warningf("OVNRouteAdvertisement %s has an invalid nodeSelector. Ignoring it: %v", advertisement.Name, err)
+			continue
+		}
+		if err := validateOVNRouteAdvertisement(&advertisement.Spec); err != nil {
+			klog.Warningf("OVNRouteAdvertisement %s is invalid. Ignoring it: %v", advertisement.Name, err)
+			continue
+		}
+		peers := make([]string, 0, len(advertisement.Spec.Peers))
+		for _, peer := range advertisement.Spec.Peers {
+			peers = append(peers, fmt.Sprintf("%s,%d", net.ParseIP(peer.Address), peer.ASN))
+		}
+		res = append(res, bootstrap.OVNRouteAdvertisement{
+			Name:         advertisement.Name,
+			NodeSelector: selector.String(),
+			ASN:          advertisement.Spec.ASN,
+			Peers:        strings.Join(peers, " "),
+			PodNetwork:   hasAdvertisement(&advertisement.Spec, netopv1.PodNetworkAdvertisement),
+			EgressIP:     hasAdvertisement(&advertisement.Spec, netopv1.EgressIPAdvertisement),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res, nil
+}
+
+// validateOVNRouteAdvertisement checks the autonomous system numbers, the addresses of the peers, and
+// the advertised routes
+func validateOVNRouteAdvertisement(spec *netopv1.OVNRouteAdvertisementSpec) error {
+	if spec.ASN < 1 || spec.ASN > maxBGPASN {
+		return fmt.Errorf("asn must be between 1 and %d, got %d", maxBGPASN, spec.ASN)
+	}
+	if len(spec.Peers) == 0 {
+		return fmt.Errorf("it has no peers")
+	}
+	for _, peer := range spec.Peers {
+		if net.ParseIP(peer.Address) == nil {
+			return fmt.Errorf("the address of peer %q is not an IP address", peer.Address)
+		}
+		if peer.ASN < 1 || peer.ASN > maxBGPASN {
+			return fmt.Errorf("the asn of peer %s must be between 1 and %d, got %d", peer.Address, maxBGPASN, peer.ASN)
+		}
+	}
+	if len(spec.Advertisements) == 0 {
+		return fmt.Errorf("it advertises no routes")
+	}
+	for _, advertisement := range spec.Advertisements {
+		if advertisement != netopv1.PodNetworkAdvertisement && advertisement != netopv1.EgressIPAdvertisement {
+			return fmt.Errorf("advertisements must be %q or %q, got %q",
+				netopv1.PodNetworkAdvertisement, netopv1.EgressIPAdvertisement, advertisement)
+		}
+	}
+	return nil
+}
+
+// hasAdvertisement returns true if the routes of the given type are advertised
+func hasAdvertisement(spec *netopv1.OVNRouteAdvertisementSpec, advertisement netopv1.AdvertisementType) bool {
+	for _, a := range spec.Advertisements {
+		if a == advertisement {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package network
+
+import (
+	"os"
+	"testing"
+
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestBootstrapOVNRouteAdvertisements(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// the CRD is not installed
+	advertisements, err := bootstrapOVNRouteAdvertisements(fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(advertisements).To(BeEmpty())
+
+	advertisement := func(name string, spec netopv1.OVNRouteAdvertisementSpec) *netopv1.OVNRouteAdvertisement {
+		return &netopv1.OVNRouteAdvertisement{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec}
+	}
+	peers := []netopv1.BGPPeer{{Address: "192.168.1.1", ASN: 64512}, {Address: "fd00:0::1", ASN: 64512}}
+	scheme := runtime.NewScheme()
+	g.Expect(netopv1.Install(scheme)).To(Succeed())
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		advertisement("rack-1", netopv1.OVNRouteAdvertisementSpec{
+			NodeSelector:   &metav1.LabelSelector{MatchLabels: map[string]string{"topology.example.com/rack": "1"}},
+			ASN:            4200000001,
+			Peers:          peers,
+			Advertisements: []netopv1.AdvertisementType{netopv1.PodNetworkAdvertisement, netopv1.EgressIPAdvertisement},
+		}),
+		advertisement("default", netopv1.OVNRouteAdvertisementSpec{
+			ASN:            64513,
+			Peers:          []netopv1.BGPPeer{{Address: "192.168.0.1", ASN: 64512}},
+			Advertisements: []netopv1.AdvertisementType{netopv1.EgressIPAdvertisement},
+		}),
+		advertisement("no-peers", netopv1.OVNRouteAdvertisementSpec{
+			ASN:            64513,
+			Advertisements: []netopv1.AdvertisementType{netopv1.PodNetworkAdvertisement},
+		}),
+		advertisement("bad-peer", netopv1.OVNRouteAdvertisementSpec{
+			ASN:            64513,
+			Peers:          []netopv1.BGPPeer{{Address: "tor-1.example.com", ASN: 64512}},
+			Advertisements: []netopv1.AdvertisementType{netopv1.PodNetworkAdvertisement},
+		}),
+		advertisement("bad-asn", netopv1.OVNRouteAdvertisementSpec{
+			ASN:            4294967296,
+			Peers:          peers,
+			Advertisements: []netopv1.AdvertisementType{netopv1.PodNetworkAdvertisement},
+		}),
+		advertisement("nothing", netopv1.OVNRouteAdvertisementSpec{
+			ASN:   64513,
+			Peers: peers,
+		}),
+		advertisement("services", netopv1.OVNRouteAdvertisementSpec{
+			ASN:            64513,
+			Peers:          peers,
+			Advertisements: []netopv1.AdvertisementType{"Services"},
+		}),
+		advertisement("invalid-selector", netopv1.OVNRouteAdvertisementSpec{
+			NodeSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "topology.example.com/rack", Operator: "Near"},
+			}},
+			ASN:            64513,
+			Peers:          peers,
+			Advertisements: []netopv1.AdvertisementType{netopv1.PodNetworkAdvertisement},
+		}),
+	).Build()
+	advertisements, err = bootstrapOVNRouteAdvertisements(cl)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(advertisements).To(Equal([]bootstrap.OVNRouteAdvertisement{
+		{Name: "default", ASN: 64513, Peers: "192.168.0.1,64512", EgressIP: true},
+		{
+			Name:         "rack-1",
+			NodeSelector: "topology.example.com/rack=1",
+			ASN:          4200000001,
+			Peers:        "192.168.1.1,64512 fd00::1,64512",
+			PodNetwork:   true,
+			EgressIP:     true,
+		},
+	}))
+}
+
+func TestRenderOVNKubernetesRouteAdvertisements(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	defer os.Setenv("FRR_IMAGE", os.Getenv("FRR_IMAGE"))
+	os.Setenv("FRR_IMAGE", "quay.io/openshift/origin-metallb-frr:latest")
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(findInObjs("apps", "DaemonSet", "ovnkube-route-advertisements", "openshift-ovn-kubernetes", objs)).To(BeNil())
+
+	bootstrapResult.OVN.OVNKubernetesConfig.RouteAdvertisements = []bootstrap.OVNRouteAdvertisement{
+		{Name: "default", ASN: 64513, Peers: "192.168.0.1,64512", EgressIP: true},
+		{
+			Name:         "rack-1",
+			NodeSelector: "topology.example.com/rack=1",
+			ASN:          4200000001,
+			Peers:        "192.168.1.1,64512 fd00::1,64512",
+			PodNetwork:   true,
+			EgressIP:     true,
+		},
+	}
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	ds := &appsv1.DaemonSet{}
+	g.Expect(convert(findInObjs("apps", "DaemonSet", "ovnkube-route-advertisements", "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+	g.Expect(ds.Spec.Template.Spec.HostNetwork).To(BeTrue())
+
+	frr, ok := findContainer(ds.Spec.Template.Spec.Containers, "frr")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(frr.Image).To(Equal("quay.io/openshift/origin-metallb-frr:latest"))
+	speaker, ok := findContainer(ds.Spec.Template.Spec.Containers, "speaker")
+	g.Expect(ok).To(BeTrue())
+	script := speaker.Command[2]
+	g.Expect(script).To(ContainSubstring("matched=all\n"))
+	g.Expect(script).To(ContainSubstring(`kubectl get node "${K8S_NODE}" -l 'topology.example.com/rack=1' -o name`))
+	g.Expect(script).To(ContainSubstring(`asn="4200000001"`))
+	g.Expect(script).To(ContainSubstring(`peers="192.168.1.1,64512 fd00::1,64512"`))
+	g.Expect(script).To(ContainSubstring("advertise_pod_network=false\n"))
+	g.Expect(script).To(ContainSubstring("advertise_pod_network=true\n"))
+}
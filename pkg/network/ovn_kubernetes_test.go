@@ -10,20 +10,28 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ghodss/yaml"
 	. "github.com/onsi/gomega"
 	"github.com/stretchr/testify/assert"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	certutil "k8s.io/client-go/util/cert"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	configv1 "github.com/openshift/api/config/v1"
 	operv1 "github.com/openshift/api/operator/v1"
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
 	"github.com/openshift/cluster-network-operator/pkg/apply"
 	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
 	"github.com/openshift/cluster-network-operator/pkg/names"
@@ -67,10 +75,8 @@ func TestRenderOVNKubernetes(t *testing.T) {
 
 	bootstrapResult := &bootstrap.BootstrapResult{
 		OVN: bootstrap.OVNBootstrapResult{
-			MasterIPs: []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
-			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{
-				NodeMode: "full",
-			},
+			MasterIPs:           []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
 		},
 	}
 
@@ -133,10 +139,8 @@ func TestRenderOVNKubernetesIPv6(t *testing.T) {
 
 	bootstrapResult := &bootstrap.BootstrapResult{
 		OVN: bootstrap.OVNBootstrapResult{
-			MasterIPs: []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
-			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{
-				NodeMode: "full",
-			},
+			MasterIPs:           []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
 		},
 	}
 	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
@@ -149,10 +153,8 @@ func TestRenderOVNKubernetesIPv6(t *testing.T) {
 
 	bootstrapResult = &bootstrap.BootstrapResult{
 		OVN: bootstrap.OVNBootstrapResult{
-			MasterIPs: []string{"fd01::1", "fd01::2", "fd01::3"},
-			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{
-				NodeMode: "full",
-			},
+			MasterIPs:           []string{"fd01::1", "fd01::2", "fd01::3"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
 		},
 	}
 	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
@@ -280,6 +282,43 @@ hybrid-overlay-vxlan-port="9000"`,
 			},
 			masterIPs: []string{"1.2.3.4", "2.3.4.5"},
 		},
+		{
+			desc: "HybridOverlay with multiple ClusterNetworkEntries",
+			expected: `
+[default]
+mtu="1500"
+cluster-subnets="10.128.0.0/15/23,10.0.0.0/14/24"
+encap-port="8061"
+enable-lflow-cache=true
+lflow-cache-limit-kb=1048576
+
+[kubernetes]
+service-cidrs="172.30.0.0/16"
+ovn-config-namespace="openshift-ovn-kubernetes"
+apiserver="https://1.1.1.1:1111"
+host-network-namespace="openshift-host-network"
+no-hostsubnet-nodes="kubernetes.io/os=windows"
+platform-type=""
+
+[ovnkubernetesfeature]
+enable-egress-ip=true
+enable-egress-firewall=true
+
+[gateway]
+mode=shared
+nodeport=true
+
+[hybridoverlay]
+enabled=true
+cluster-subnets="10.132.0.0/14,10.140.0.0/16"`,
+			hybridOverlayConfig: &operv1.HybridOverlayConfig{
+				HybridClusterNetwork: []operv1.ClusterNetworkEntry{
+					{CIDR: "10.132.0.0/14", HostPrefix: 23},
+					{CIDR: "10.140.0.0/16", HostPrefix: 24},
+				},
+			},
+			masterIPs: []string{"1.2.3.4", "2.3.4.5"},
+		},
 		{
 			desc: "HybridOverlay enabled with no ClusterNetworkEntry",
 			expected: `
@@ -374,10 +413,8 @@ election-retry-period=26`,
 
 			bootstrapResult := &bootstrap.BootstrapResult{
 				OVN: bootstrap.OVNBootstrapResult{
-					MasterIPs: tc.masterIPs,
-					OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{
-						NodeMode: "full",
-					},
+					MasterIPs:           tc.masterIPs,
+					OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
 				},
 			}
 			objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
@@ -540,6 +577,28 @@ func TestValidateOVNKubernetes(t *testing.T) {
 	ovnConfig.GenevePort = ptrToUint32(70001)
 	errExpect("invalid GenevePort 70001")
 
+	ovnConfig.MTU = nil
+	ovnConfig.GenevePort = nil
+	ovnConfig.HybridOverlayConfig = &operv1.HybridOverlayConfig{
+		HybridClusterNetwork: []operv1.ClusterNetworkEntry{
+			{CIDR: "10.132.0.0/14", HostPrefix: 23},
+			{CIDR: "10.140.0.0/16", HostPrefix: 24},
+		},
+	}
+	g.Expect(validateOVNKubernetes(config)).To(BeEmpty())
+
+	ovnConfig.HybridOverlayConfig.HybridClusterNetwork = append(ovnConfig.HybridOverlayConfig.HybridClusterNetwork,
+		operv1.ClusterNetworkEntry{CIDR: "10.140.128.0/17", HostPrefix: 24},
+		operv1.ClusterNetworkEntry{CIDR: "10.129.0.0/16", HostPrefix: 24},
+		operv1.ClusterNetworkEntry{CIDR: "172.30.0.0/24", HostPrefix: 24},
+		operv1.ClusterNetworkEntry{CIDR: "10.150.0.0", HostPrefix: 24},
+	)
+	errExpect("invalid hybridClusterNetwork 10.140.128.0/17: CIDRs 10.140.0.0/16 and 10.140.128.0/17 overlap")
+	errExpect("invalid hybridClusterNetwork 10.129.0.0/16")
+	errExpect("invalid hybridClusterNetwork 172.30.0.0/24")
+	errExpect("could not parse hybridClusterNetwork 10.150.0.0")
+	ovnConfig.HybridOverlayConfig = nil
+
 	config.ClusterNetwork = nil
 	errExpect("ClusterNetwork cannot be empty")
 }
@@ -622,6 +681,39 @@ func TestOVNKubernetesIsSafe(t *testing.T) {
 	g.Expect(errs).To(HaveLen(1))
 	g.Expect(errs[0]).To(MatchError("cannot edit a running hybrid overlay network"))
 
+	// hybrid cluster networks can be added for new pools of Windows nodes
+	hybridOverlayConfigNext.HybridClusterNetwork = append(hybridOverlayConfigPrev.HybridClusterNetwork,
+		operv1.ClusterNetworkEntry{CIDR: "10.140.0.0/16", HostPrefix: 24})
+	errs = isOVNKubernetesChangeSafe(prev, next)
+	g.Expect(errs).To(BeEmpty())
+
+	// but not removed
+	prev.DefaultNetwork.OVNKubernetesConfig.HybridOverlayConfig, next.DefaultNetwork.OVNKubernetesConfig.HybridOverlayConfig =
+		next.DefaultNetwork.OVNKubernetesConfig.HybridOverlayConfig, prev.DefaultNetwork.OVNKubernetesConfig.HybridOverlayConfig
+	errs = isOVNKubernetesChangeSafe(prev, next)
+	g.Expect(errs).To(HaveLen(1))
+	g.Expect(errs[0]).To(MatchError("cannot edit a running hybrid overlay network"))
+
+	// and the VXLAN port cannot change
+	next.DefaultNetwork.OVNKubernetesConfig.HybridOverlayConfig = prev.DefaultNetwork.OVNKubernetesConfig.HybridOverlayConfig.DeepCopy()
+	next.DefaultNetwork.OVNKubernetesConfig.HybridOverlayConfig.HybridOverlayVXLANPort = ptrToUint32(9000)
+	errs = isOVNKubernetesChangeSafe(prev, next)
+	g.Expect(errs).To(HaveLen(1))
+	g.Expect(errs[0]).To(MatchError("cannot edit a running hybrid overlay network"))
+
+	// nor the hybrid cluster networks appended during an IP family conversion
+	next.DefaultNetwork.OVNKubernetesConfig.HybridOverlayConfig = prev.DefaultNetwork.OVNKubernetesConfig.HybridOverlayConfig.DeepCopy()
+	next.ServiceNetwork = append(next.ServiceNetwork, "fd02::/112")
+	errs = isOVNKubernetesChangeSafe(prev, next)
+	g.Expect(errs).To(BeEmpty())
+	next.DefaultNetwork.OVNKubernetesConfig.HybridOverlayConfig.HybridClusterNetwork = append(
+		next.DefaultNetwork.OVNKubernetesConfig.HybridOverlayConfig.HybridClusterNetwork,
+		operv1.ClusterNetworkEntry{CIDR: "10.150.0.0/16", HostPrefix: 24})
+	errs = isOVNKubernetesChangeSafe(prev, next)
+	g.Expect(errs).To(HaveLen(1))
+	g.Expect(errs[0]).To(MatchError("cannot edit the hybrid overlay network during an IP family conversion"))
+	next.ServiceNetwork = prev.ServiceNetwork
+
 	prev.DefaultNetwork.OVNKubernetesConfig.HybridOverlayConfig = nil
 	next.DefaultNetwork.OVNKubernetesConfig.HybridOverlayConfig = nil
 
@@ -676,6 +768,76 @@ func TestOVNKubernetesIsSafe(t *testing.T) {
 	g.Expect(errs[0]).To(MatchError(fmt.Sprintf("invalid Migration.MTU.Machine.To(%d), has to be at least %d", *next.Migration.MTU.Machine.To, *next.Migration.MTU.Network.To+getOVNEncapOverhead(next))))
 }
 
+func TestIsChangeForcible(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	prev := OVNKubernetesConfig.Spec.DeepCopy()
+	FillDefaults(prev, nil)
+	next := OVNKubernetesConfig.Spec.DeepCopy()
+	FillDefaults(next, nil)
+
+	// the genevePort and the hybrid overlay can be forced
+	next.DefaultNetwork.OVNKubernetesConfig.GenevePort = ptrToUint32(34001)
+	next.DefaultNetwork.OVNKubernetesConfig.HybridOverlayConfig = &operv1.HybridOverlayConfig{
+		HybridClusterNetwork: []operv1.ClusterNetworkEntry{{CIDR: "10.132.0.0/14", HostPrefix: 23}},
+	}
+	g.Expect(IsChangeSafe(prev, next)).NotTo(Succeed())
+	g.Expect(IsChangeForcible(prev, next)).To(BeTrue())
+	g.Expect(prev.DefaultNetwork.OVNKubernetesConfig.HybridOverlayConfig).To(BeNil())
+
+	// but not along with other unsafe changes
+	next.DefaultNetwork.OVNKubernetesConfig.MTU = ptrToUint32(*prev.DefaultNetwork.OVNKubernetesConfig.MTU - 100)
+	g.Expect(IsChangeForcible(prev, next)).To(BeFalse())
+	next.DefaultNetwork.OVNKubernetesConfig.MTU = prev.DefaultNetwork.OVNKubernetesConfig.MTU
+	next.DisableMultiNetwork = boolPtr(true)
+	g.Expect(IsChangeForcible(prev, next)).To(BeFalse())
+
+	g.Expect(IsChangeForcible(nil, next)).To(BeFalse())
+}
+
+func TestForceUnsafeChangeRequest(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	conf := &operv1.Network{}
+	g.Expect(ForceUnsafeChangeRequest(conf)).To(BeEmpty())
+	conf.Annotations = map[string]string{names.ForceUnsafeChangeAnnotation: " 2021-11-02T10:00:00Z "}
+	g.Expect(ForceUnsafeChangeRequest(conf)).To(Equal("2021-11-02T10:00:00Z"))
+	conf.Annotations = map[string]string{names.ForceUnsafeChangeAnnotation: `"; reboot`}
+	g.Expect(ForceUnsafeChangeRequest(conf)).To(BeEmpty())
+}
+
+func TestRenderOVNKubernetesForcedChange(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+	templateAnnotations := func(objs []*uns.Unstructured, name string) map[string]string {
+		ds := &appsv1.DaemonSet{}
+		g.Expect(convert(findInObjs("apps", "DaemonSet", name, "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+		return ds.Spec.Template.Annotations
+	}
+
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(templateAnnotations(objs, "ovnkube-master")).NotTo(HaveKey(names.ForceUnsafeChangeAnnotation))
+	g.Expect(templateAnnotations(objs, "ovnkube-node")).NotTo(HaveKey(names.ForceUnsafeChangeAnnotation))
+
+	// the pods are restarted by a forced change
+	bootstrapResult.OVN.OVNKubernetesConfig.ForceUnsafeChangeRequest = "1"
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(templateAnnotations(objs, "ovnkube-master")).To(HaveKeyWithValue(names.ForceUnsafeChangeAnnotation, "1"))
+	g.Expect(templateAnnotations(objs, "ovnkube-node")).To(HaveKeyWithValue(names.ForceUnsafeChangeAnnotation, "1"))
+}
+
 // TestOVNKubernetesShouldUpdateMasterOnUpgrade checks to see that
 func TestOVNKubernetestShouldUpdateMasterOnUpgrade(t *testing.T) {
 
@@ -1286,10 +1448,9 @@ metadata:
 					MasterIPs:               []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
 					ExistingMasterDaemonset: master,
 					ExistingNodeDaemonset:   node,
-					OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{
-						NodeMode: "full",
-					},
-					PrePullerDaemonset: prepuller,
+					OVNKubernetesConfig:     &bootstrap.OVNConfigBoostrapResult{},
+					PrePullerDaemonset:      prepuller,
+					DBSchemaCheckJob:        ovnDBSchemaCheckJob(tc.rv, batchv1.JobComplete),
 				},
 			}
 
@@ -1307,11 +1468,11 @@ metadata:
 			// if we expect a prepuller update, the original prepuller and the rendered one must be different
 			g.Expect(tc.expectPrePull).To(Equal(!reflect.DeepEqual(renderedPrePuller, usPrePuller)), "Check prepuller rendering")
 
-			updateNode, updateMaster := shouldUpdateOVNKonUpgrade(node, master, tc.rv)
+			updateNode, updateMaster := shouldUpdateOVNKonUpgrade(node, master, tc.rv, false)
 			g.Expect(updateMaster).To(Equal(tc.expectMaster), "Check master")
 			if updateNode {
 				var updatePrePuller bool
-				updateNode, updatePrePuller = shouldUpdateOVNKonPrepull(node, prepuller, tc.rv)
+				updateNode, updatePrePuller = shouldUpdateOVNKonPrepull(node, prepuller, OVN_PREPULLER_MODE_DAEMONSET, tc.rv)
 				g.Expect(updatePrePuller).To(Equal(tc.expectPrePull), "Check prepuller")
 			}
 			g.Expect(updateNode).To(Equal(tc.expectNode), "Check node")
@@ -1319,563 +1480,2812 @@ metadata:
 	}
 }
 
-func TestShouldUpdateOVNKonIPFamilyChange(t *testing.T) {
+func TestShouldUpdateOVNKonPrepullModes(t *testing.T) {
+	g := NewGomegaWithT(t)
 
-	for _, tc := range []struct {
-		name         string
-		node         *appsv1.DaemonSet
-		master       *appsv1.DaemonSet
-		ipFamilyMode string
-		expectNode   bool
-		expectMaster bool
-	}{
-		{
-			name:         "all empty",
-			node:         &appsv1.DaemonSet{},
-			master:       &appsv1.DaemonSet{},
-			expectNode:   true,
-			expectMaster: true,
-			ipFamilyMode: names.IPFamilySingleStack,
+	node := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "ovnkube-node",
+			Namespace:   "openshift-ovn-kubernetes",
+			Annotations: map[string]string{"release.openshift.io/version": "1.9.9"},
 		},
-		{
-			name:         "fresh cluster",
-			node:         &appsv1.DaemonSet{},
-			master:       &appsv1.DaemonSet{},
-			expectNode:   true,
-			expectMaster: true,
-			ipFamilyMode: names.IPFamilySingleStack,
+	}
+
+	// the default daemonset mode waits for the prepuller
+	updateNode, renderPrepull := shouldUpdateOVNKonPrepull(node, nil, OVN_PREPULLER_MODE_DAEMONSET, "2.0.0")
+	g.Expect(updateNode).To(BeFalse())
+	g.Expect(renderPrepull).To(BeTrue())
+
+	// disabling the prepuller lets the node upgrade immediately
+	updateNode, renderPrepull = shouldUpdateOVNKonPrepull(node, nil, OVN_PREPULLER_MODE_DISABLED, "2.0.0")
+	g.Expect(updateNode).To(BeTrue())
+	g.Expect(renderPrepull).To(BeFalse())
+
+	// job mode: no job yet, render it
+	updateNode, renderPrepull = shouldUpdateOVNKonPrepullJob(node, nil, "2.0.0")
+	g.Expect(updateNode).To(BeFalse())
+	g.Expect(renderPrepull).To(BeTrue())
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "ovnkube-upgrades-prepuller",
+			Namespace:   "openshift-ovn-kubernetes",
+			Annotations: map[string]string{"release.openshift.io/version": "2.0.0"},
 		},
-		{
-			name: "no configuration change",
-			node: &appsv1.DaemonSet{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "ovnkube-node",
-					Namespace: "openshift-ovn-kubernetes",
-					Annotations: map[string]string{
-						names.NetworkIPFamilyModeAnnotation: names.IPFamilySingleStack,
-					},
-				},
-			},
-			master: &appsv1.DaemonSet{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "ovnkube-master",
-					Namespace: "openshift-ovn-kubernetes",
-					Annotations: map[string]string{
-						names.NetworkIPFamilyModeAnnotation: names.IPFamilySingleStack,
-					},
-					Generation: 1,
-				},
-				Status: appsv1.DaemonSetStatus{
-					CurrentNumberScheduled: 3,
-					DesiredNumberScheduled: 3,
-					NumberAvailable:        3,
-					NumberMisscheduled:     0,
-					NumberReady:            3,
-					ObservedGeneration:     2,
-					UpdatedNumberScheduled: 3,
-				},
-			},
-			expectNode:   true,
-			expectMaster: true,
-			ipFamilyMode: names.IPFamilySingleStack,
+	}
+
+	// job mode: job still running
+	updateNode, renderPrepull = shouldUpdateOVNKonPrepullJob(node, job, "2.0.0")
+	g.Expect(updateNode).To(BeFalse())
+	g.Expect(renderPrepull).To(BeTrue())
+
+	// job mode: job left over from another release is dropped so that it gets recreated
+	updateNode, renderPrepull = shouldUpdateOVNKonPrepullJob(node, job, "2.0.1")
+	g.Expect(updateNode).To(BeFalse())
+	g.Expect(renderPrepull).To(BeFalse())
+
+	// job mode: job complete, proceed with the node
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+	updateNode, renderPrepull = shouldUpdateOVNKonPrepullJob(node, job, "2.0.0")
+	g.Expect(updateNode).To(BeTrue())
+	g.Expect(renderPrepull).To(BeFalse())
+
+	// job mode: job failed, e.g. past its deadline, proceed with the node anyway
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: v1.ConditionTrue, Reason: "DeadlineExceeded"}}
+	updateNode, renderPrepull = shouldUpdateOVNKonPrepullJob(node, job, "2.0.0")
+	g.Expect(updateNode).To(BeTrue())
+	g.Expect(renderPrepull).To(BeFalse())
+
+	// job mode: job running for longer than its deadline without failing, proceed with the node anyway
+	job.Status.Conditions = nil
+	job.CreationTimestamp = metav1.NewTime(time.Now().Add(-OVN_PREPULLER_JOB_DEADLINE - time.Minute))
+	updateNode, renderPrepull = shouldUpdateOVNKonPrepullJob(node, job, "2.0.0")
+	g.Expect(updateNode).To(BeTrue())
+	g.Expect(renderPrepull).To(BeFalse())
+}
+
+func TestRenderOVNKubernetesPrePullerJob(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+	os.Setenv("RELEASE_VERSION", "2.0.0")
+
+	node := &appsv1.DaemonSet{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "DaemonSet"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "ovnkube-node",
+			Namespace:   "openshift-ovn-kubernetes",
+			Annotations: map[string]string{"release.openshift.io/version": "1.9.9"},
 		},
-		{
-			name: "configuration changed",
-			node: &appsv1.DaemonSet{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "ovnkube-node",
-					Namespace: "openshift-ovn-kubernetes",
-					Annotations: map[string]string{
-						names.NetworkIPFamilyModeAnnotation: names.IPFamilySingleStack,
-					},
-				},
-			},
-			master: &appsv1.DaemonSet{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "ovnkube-master",
-					Namespace: "openshift-ovn-kubernetes",
-					Annotations: map[string]string{
-						names.NetworkIPFamilyModeAnnotation: names.IPFamilySingleStack,
-					},
-				},
+		Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 5},
+	}
+	master := node.DeepCopy()
+	master.Name = "ovnkube-master"
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:               []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			ExistingNodeDaemonset:   node,
+			ExistingMasterDaemonset: master,
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{
+				PrePullerMode: OVN_PREPULLER_MODE_JOB,
 			},
-			expectNode:   false,
-			expectMaster: true,
-			ipFamilyMode: names.IPFamilyDualStack,
 		},
-		{
-			name: "configuration changed, master updated and node remaining",
-			node: &appsv1.DaemonSet{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "ovnkube-node",
-					Namespace: "openshift-ovn-kubernetes",
-					Annotations: map[string]string{
-						names.NetworkIPFamilyModeAnnotation: names.IPFamilySingleStack,
-					},
-				},
-			},
-			master: &appsv1.DaemonSet{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "ovnkube-master",
-					Namespace: "openshift-ovn-kubernetes",
-					Annotations: map[string]string{
-						names.NetworkIPFamilyModeAnnotation: names.IPFamilyDualStack,
-					},
-					Generation: 1,
-				},
-				Status: appsv1.DaemonSetStatus{
-					CurrentNumberScheduled: 3,
-					DesiredNumberScheduled: 3,
-					NumberAvailable:        3,
-					NumberMisscheduled:     0,
-					NumberReady:            3,
-					ObservedGeneration:     2,
-					UpdatedNumberScheduled: 3,
-				},
+	}
+
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(findInObjs("apps", "DaemonSet", "ovnkube-upgrades-prepuller", "openshift-ovn-kubernetes", objs)).To(BeNil())
+	job := findInObjs("batch", "Job", "ovnkube-upgrades-prepuller", "openshift-ovn-kubernetes", objs)
+	g.Expect(job).NotTo(BeNil())
+	completions, _, err := uns.NestedInt64(job.Object, "spec", "completions")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(completions).To(BeEquivalentTo(5))
+	deadline, _, err := uns.NestedInt64(job.Object, "spec", "activeDeadlineSeconds")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(deadline).To(BeEquivalentTo(1200))
+	g.Expect(bootstrapResult.Events).To(ConsistOf(bootstrap.Event{
+		Type:    v1.EventTypeNormal,
+		Reason:  "PrePullerStarted",
+		Message: "Pre-pulling the ovn-kubernetes image of release 2.0.0 on every node before updating ovnkube-node",
+	}))
+
+	// with the prepuller disabled, nothing is rendered
+	bootstrapResult.OVN.OVNKubernetesConfig.PrePullerMode = OVN_PREPULLER_MODE_DISABLED
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(findInObjs("apps", "DaemonSet", "ovnkube-upgrades-prepuller", "openshift-ovn-kubernetes", objs)).To(BeNil())
+	g.Expect(findInObjs("batch", "Job", "ovnkube-upgrades-prepuller", "openshift-ovn-kubernetes", objs)).To(BeNil())
+}
+
+func TestRenderOVNKubernetesUpgradeHold(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+	os.Setenv("RELEASE_VERSION", "2.0.0")
+
+	node := &appsv1.DaemonSet{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "DaemonSet"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "ovnkube-node",
+			Namespace:   "openshift-ovn-kubernetes",
+			Annotations: map[string]string{"release.openshift.io/version": "1.9.9"},
+		},
+	}
+	master := node.DeepCopy()
+	master.Name = "ovnkube-master"
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:               []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			ExistingNodeDaemonset:   node,
+			ExistingMasterDaemonset: master,
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{
+				PrePullerMode: OVN_PREPULLER_MODE_DISABLED,
+				UpgradeHold:   true,
 			},
-			expectNode:   true,
-			expectMaster: true,
-			ipFamilyMode: names.IPFamilyDualStack,
 		},
-		{
-			name: "configuration changed, master updated and node remaining but still rolling out",
-			node: &appsv1.DaemonSet{
-				ObjectMeta: metav1.ObjectMeta{
+	}
+	version := func(objs []*uns.Unstructured, name string) string {
+		ds := findInObjs("apps", "DaemonSet", name, "openshift-ovn-kubernetes", objs)
+		g.Expect(ds).NotTo(BeNil())
+		return ds.GetAnnotations()["release.openshift.io/version"]
+	}
+
+	// both daemonsets are kept at their release
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(version(objs, "ovnkube-node")).To(Equal("1.9.9"))
+	g.Expect(version(objs, "ovnkube-master")).To(Equal("1.9.9"))
+	g.Expect(bootstrapResult.Events).To(ConsistOf(bootstrap.Event{
+		Type:    v1.EventTypeNormal,
+		Reason:  "UpgradeHeld",
+		Message: "The rollout of OVN-Kubernetes release 2.0.0 is on hold until the networkoperator.openshift.io/ovn-upgrade-hold annotation is removed",
+	}))
+
+	// once the hold is lifted, the nodes are upgraded first
+	bootstrapResult.Events = nil
+	bootstrapResult.OVN.OVNKubernetesConfig.UpgradeHold = false
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(version(objs, "ovnkube-node")).To(Equal("2.0.0"))
+	g.Expect(version(objs, "ovnkube-master")).To(Equal("1.9.9"))
+	g.Expect(bootstrapResult.Events).To(BeEmpty())
+
+	// the hold does not apply to the daemonsets already at the release
+	node.Annotations["release.openshift.io/version"] = "2.0.0"
+	master.Annotations["release.openshift.io/version"] = "2.0.0"
+	updateNode, updateMaster := shouldUpdateOVNKonUpgrade(node, master, "2.0.0", true)
+	g.Expect(updateNode).To(BeTrue())
+	g.Expect(updateMaster).To(BeTrue())
+}
+
+func TestRenderOVNKubernetesMissingObjects(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+	os.Setenv("RELEASE_VERSION", "2.0.0")
+
+	node := &appsv1.DaemonSet{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "DaemonSet"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "ovnkube-node",
+			Namespace:   "openshift-ovn-kubernetes",
+			Annotations: map[string]string{"release.openshift.io/version": "1.9.9"},
+		},
+	}
+	master := node.DeepCopy()
+	master.Name = "ovnkube-master"
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:               []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			ExistingNodeDaemonset:   node,
+			ExistingMasterDaemonset: master,
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{
+				PrePullerMode: OVN_PREPULLER_MODE_DISABLED,
+				UpgradeHold:   true,
+			},
+			MissingObjects: []string{"ConfigMap openshift-ovn-kubernetes/ovnkube-config"},
+		},
+	}
+	version := func(objs []*uns.Unstructured, name string) string {
+		ds := findInObjs("apps", "DaemonSet", name, "openshift-ovn-kubernetes", objs)
+		g.Expect(ds).NotTo(BeNil())
+		return ds.GetAnnotations()["release.openshift.io/version"]
+	}
+
+	// the held rollout is forced once a critical object was removed
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(version(objs, "ovnkube-node")).To(Equal("2.0.0"))
+	g.Expect(version(objs, "ovnkube-master")).To(Equal("2.0.0"))
+	g.Expect(findInObjs("", "ConfigMap", "ovnkube-config", "openshift-ovn-kubernetes", objs)).NotTo(BeNil())
+	g.Expect(bootstrapResult.Events).To(ContainElement(bootstrap.Event{
+		Type:   v1.EventTypeWarning,
+		Reason: "OVNObjectsMissing",
+		Message: "ConfigMap openshift-ovn-kubernetes/ovnkube-config removed from the cluster, " +
+			"rolling out ovnkube-master and ovnkube-node regardless of the ongoing rollout",
+	}))
+
+	// ovnkube-master is recreated while the nodes are upgraded first
+	bootstrapResult.Events = nil
+	bootstrapResult.OVN.OVNKubernetesConfig.UpgradeHold = false
+	bootstrapResult.OVN.ExistingMasterDaemonset = nil
+	bootstrapResult.OVN.MissingObjects = []string{"DaemonSet openshift-ovn-kubernetes/ovnkube-master"}
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(version(objs, "ovnkube-master")).To(Equal("2.0.0"))
+
+	// nothing is forced when every object is there
+	bootstrapResult.Events = nil
+	bootstrapResult.OVN.ExistingMasterDaemonset = master
+	bootstrapResult.OVN.MissingObjects = nil
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(version(objs, "ovnkube-master")).To(Equal("1.9.9"))
+	g.Expect(bootstrapResult.Events).To(BeEmpty())
+}
+
+func TestBootstrapOVNMissingObjects(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	ds := &appsv1.DaemonSet{}
+	config := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ovn-kubernetes", Name: "ovnkube-config"}}
+
+	// nothing is missing on a fresh cluster
+	missing, err := bootstrapOVNMissingObjects(fake.NewClientBuilder().Build(), nil, nil, false)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(missing).To(BeEmpty())
+
+	missing, err = bootstrapOVNMissingObjects(fake.NewClientBuilder().WithObjects(config.DeepCopy()).Build(), ds, ds, false)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(missing).To(BeEmpty())
+
+	missing, err = bootstrapOVNMissingObjects(fake.NewClientBuilder().Build(), nil, ds, false)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(missing).To(Equal([]string{
+		"DaemonSet openshift-ovn-kubernetes/ovnkube-master",
+		"ConfigMap openshift-ovn-kubernetes/ovnkube-config",
+	}))
+
+	// ovnkube-master is not deployed along with an external OVN deployment
+	missing, err = bootstrapOVNMissingObjects(fake.NewClientBuilder().WithObjects(config.DeepCopy()).Build(), nil, ds, true)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(missing).To(BeEmpty())
+}
+
+func TestBootstrapOVNUpgradeHold(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	for _, tc := range []struct {
+		annotations map[string]string
+		hold        bool
+	}{
+		{annotations: nil},
+		{annotations: map[string]string{names.OVNUpgradeHoldAnnotation: "true"}, hold: true},
+		{annotations: map[string]string{names.OVNUpgradeHoldAnnotation: "false"}},
+		{annotations: map[string]string{names.OVNUpgradeHoldAnnotation: "until-saturday"}},
+	} {
+		conf := &operv1.Network{}
+		conf.Annotations = tc.annotations
+		g.Expect(bootstrapOVNUpgradeHold(conf)).To(Equal(tc.hold), "%v", tc.annotations)
+	}
+}
+
+func TestBootstrapOVNMinimalRBAC(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	for _, tc := range []struct {
+		annotations map[string]string
+		minimal     bool
+	}{
+		{annotations: nil},
+		{annotations: map[string]string{names.OVNMinimalRBACAnnotation: "true"}, minimal: true},
+		{annotations: map[string]string{names.OVNMinimalRBACAnnotation: "yes please"}},
+	} {
+		conf := &operv1.Network{}
+		conf.Annotations = tc.annotations
+		g.Expect(bootstrapOVNMinimalRBAC(conf)).To(Equal(tc.minimal), "%v", tc.annotations)
+	}
+}
+
+func TestRenderOVNKubernetesManagementNetwork(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"192.168.100.1", "192.168.100.2", "192.168.100.3"},
+			ManagementIPs:       map[string]string{"master-0": "192.168.100.1", "master-1": "192.168.100.2", "master-2": "192.168.100.3"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	master := findInObjs("apps", "DaemonSet", "ovnkube-master", "openshift-ovn-kubernetes", objs)
+	g.Expect(master).NotTo(BeNil())
+	ds := &appsv1.DaemonSet{}
+	g.Expect(convert(master, ds)).To(Succeed())
+	for _, name := range []string{"nbdb", "sbdb"} {
+		cont, ok := findContainer(ds.Spec.Template.Spec.Containers, name)
+		g.Expect(ok).To(BeTrue())
+		script := cont.Command[len(cont.Command)-1]
+		g.Expect(script).To(ContainSubstring(`declare -A management_ips=(["master-0"]="192.168.100.1" ["master-1"]="192.168.100.2" ["master-2"]="192.168.100.3" )`))
+		g.Expect(script).To(ContainSubstring(`init_ip=${management_ips[${init_node}]}`))
+		g.Expect(cont.Lifecycle.PostStart.Exec.Command[2]).To(ContainSubstring(`K8S_NODE_IP=${management_ips[${K8S_NODE}]}`))
+	}
+
+	// without a management network the node IP is used
+	bootstrapResult.OVN.ManagementIPs = nil
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	master = findInObjs("apps", "DaemonSet", "ovnkube-master", "openshift-ovn-kubernetes", objs)
+	masterJSON, err := master.MarshalJSON()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(masterJSON)).NotTo(ContainSubstring("management_ips"))
+}
+
+func TestOVNPolicyAuditRetention(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	for _, tc := range []struct {
+		annotations map[string]string
+		maxLogFiles int
+		maxLogAge   int
+	}{
+		{
+			annotations: nil,
+			maxLogFiles: OVN_POLICY_AUDIT_MAX_LOG_FILES,
+		},
+		{
+			annotations: map[string]string{
+				names.OVNPolicyAuditMaxLogFilesAnnotation: "10",
+				names.OVNPolicyAuditMaxLogAgeAnnotation:   "7",
+			},
+			maxLogFiles: 10,
+			maxLogAge:   7,
+		},
+		{
+			annotations: map[string]string{
+				names.OVNPolicyAuditMaxLogFilesAnnotation: "0",
+				names.OVNPolicyAuditMaxLogAgeAnnotation:   "a week",
+			},
+			maxLogFiles: OVN_POLICY_AUDIT_MAX_LOG_FILES,
+		},
+	} {
+		conf := &operv1.Network{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+		maxLogFiles, maxLogAge := bootstrapOVNPolicyAuditRetention(conf)
+		g.Expect(maxLogFiles).To(Equal(tc.maxLogFiles))
+		g.Expect(maxLogAge).To(Equal(tc.maxLogAge))
+	}
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs: []string{"1.2.3.4"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{
+				PolicyAuditMaxLogFiles: 3,
+				PolicyAuditMaxLogAge:   7,
+			},
+		},
+	}
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	node := findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs)
+	g.Expect(node).NotTo(BeNil())
+	ds := &appsv1.DaemonSet{}
+	g.Expect(convert(node, ds)).To(Succeed())
+	cont, ok := findContainer(ds.Spec.Template.Spec.Containers, "ovn-acl-logging")
+	g.Expect(ok).To(BeTrue())
+	script := cont.Command[len(cont.Command)-1]
+	g.Expect(script).To(ContainSubstring("MAXLOGFILES=3\n"))
+	g.Expect(script).To(ContainSubstring("-mtime +7 -delete"))
+}
+
+func TestBootstrapOVNPolicyAuditForwarding(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	image := "quay.io/vector/vector:latest"
+	tlsSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ovn-kubernetes", Name: OVN_POLICY_AUDIT_FORWARDING_TLS_SECRET},
+		Data: map[string][]byte{
+			"ca-bundle.crt": []byte("ca"),
+			"tls.crt":       []byte("cert"),
+		},
+	}
+	for _, tc := range []struct {
+		destination string
+		image       string
+		forwarding  *bootstrap.PolicyAuditForwarding
+	}{
+		{
+			destination: "kafka://broker-0:9093,broker-1:9093/acl-audit",
+			image:       image,
+			forwarding: &bootstrap.PolicyAuditForwarding{
+				Type: "kafka", Brokers: "broker-0:9093,broker-1:9093", Topic: "acl-audit", Image: image, CABundle: true,
+			},
+		},
+		{
+			destination: "https://logs.example.com:8443/acl",
+			image:       image,
+			forwarding: &bootstrap.PolicyAuditForwarding{
+				Type: "http", URL: "https://logs.example.com:8443/acl", Image: image, CABundle: true,
+			},
+		},
+		// only TLS destinations are supported
+		{destination: "http://logs.example.com/acl", image: image},
+		{destination: "kafka://broker-0:9093/", image: image},
+		{destination: "kafka://broker-0:9093/acl/audit", image: image},
+		// there is no default image
+		{destination: "kafka://broker-0:9093/acl-audit"},
+	} {
+		conf := &operv1.Network{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			names.OVNPolicyAuditForwardingAnnotation: tc.destination,
+		}}}
+		if tc.image != "" {
+			conf.Annotations[names.OVNPolicyAuditForwarderImageAnnotation] = tc.image
+		}
+		forwarding := bootstrapOVNPolicyAuditForwarding(conf, fake.NewClientBuilder().WithObjects(tlsSecret).Build())
+		g.Expect(forwarding).To(Equal(tc.forwarding), tc.destination)
+	}
+
+	// the Secret is optional, and so is the client certificate
+	conf := &operv1.Network{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		names.OVNPolicyAuditForwardingAnnotation:     "https://logs.example.com/acl",
+		names.OVNPolicyAuditForwarderImageAnnotation: image,
+	}}}
+	forwarding := bootstrapOVNPolicyAuditForwarding(conf, fake.NewClientBuilder().Build())
+	g.Expect(forwarding.CABundle).To(BeFalse())
+	g.Expect(forwarding.ClientCert).To(BeFalse())
+	tlsSecret.Data["tls.key"] = []byte("key")
+	forwarding = bootstrapOVNPolicyAuditForwarding(conf, fake.NewClientBuilder().WithObjects(tlsSecret).Build())
+	g.Expect(forwarding.ClientCert).To(BeTrue())
+}
+
+func TestRenderOVNKubernetesPolicyAuditForwarding(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs: []string{"1.2.3.4"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{
+				PolicyAuditMaxLogFiles: OVN_POLICY_AUDIT_MAX_LOG_FILES,
+			},
+		},
+	}
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).NotTo(ContainElement(HaveKubernetesID("ConfigMap", "openshift-ovn-kubernetes", "ovn-acl-audit-forwarder")))
+	ds := &appsv1.DaemonSet{}
+	g.Expect(convert(findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+	_, ok := findContainer(ds.Spec.Template.Spec.Containers, "ovn-acl-audit-forwarder")
+	g.Expect(ok).To(BeFalse())
+
+	forwardingConfig := func(forwarding *bootstrap.PolicyAuditForwarding) (string, *appsv1.DaemonSet) {
+		bootstrapResult.OVN.OVNKubernetesConfig.PolicyAuditForwarding = forwarding
+		objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+		g.Expect(err).NotTo(HaveOccurred())
+		cm := &v1.ConfigMap{}
+		g.Expect(convert(findInObjs("", "ConfigMap", "ovn-acl-audit-forwarder", "openshift-ovn-kubernetes", objs), cm)).To(Succeed())
+		ds := &appsv1.DaemonSet{}
+		g.Expect(convert(findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+		return cm.Data["vector.toml"], ds
+	}
+
+	vector, ds := forwardingConfig(&bootstrap.PolicyAuditForwarding{
+		Type: "kafka", Brokers: "broker-0:9093,broker-1:9093", Topic: "acl-audit", Image: "vector", CABundle: true,
+	})
+	g.Expect(vector).To(ContainSubstring(`bootstrap_servers = "broker-0:9093,broker-1:9093"`))
+	g.Expect(vector).To(ContainSubstring(`topic = "acl-audit"`))
+	g.Expect(vector).To(ContainSubstring(`tls.ca_file = "/etc/acl-audit-forwarding-tls/ca-bundle.crt"`))
+	g.Expect(vector).NotTo(ContainSubstring("tls.crt_file"))
+	cont, ok := findContainer(ds.Spec.Template.Spec.Containers, "ovn-acl-audit-forwarder")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(cont.Image).To(Equal("vector"))
+
+	vector, _ = forwardingConfig(&bootstrap.PolicyAuditForwarding{
+		Type: "http", URL: "https://logs.example.com/acl", Image: "vector", ClientCert: true,
+	})
+	g.Expect(vector).To(ContainSubstring(`uri = "https://logs.example.com/acl"`))
+	g.Expect(vector).To(ContainSubstring(`tls.key_file = "/etc/acl-audit-forwarding-tls/tls.key"`))
+	g.Expect(vector).NotTo(ContainSubstring("tls.ca_file"))
+}
+
+func TestBootstrapOVNDBRemovedMembers(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	conf := &operv1.Network{}
+	noJob := fake.NewClientBuilder().Build()
+
+	// first bootstrap: the members are recorded
+	removed, job, err := bootstrapOVNDBRemovedMembers(conf, noJob, []string{"10.0.0.3", "10.0.0.1", "10.0.0.2"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(removed).To(BeEmpty())
+	g.Expect(job).To(BeNil())
+	g.Expect(conf.Annotations).To(HaveKeyWithValue(names.OVNDBMembersAnnotation, "10.0.0.1,10.0.0.2,10.0.0.3"))
+	g.Expect(conf.Annotations).NotTo(HaveKey(names.OVNDBRemovedMembersAnnotation))
+
+	// two masters leave
+	conf.Annotations[names.OVNDBMembersAnnotation] = "10.0.0.1,10.0.0.2,10.0.0.3,10.0.0.4,10.0.0.5"
+	removed, _, err = bootstrapOVNDBRemovedMembers(conf, noJob, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(removed).To(Equal([]string{"10.0.0.4", "10.0.0.5"}))
+	g.Expect(conf.Annotations).To(HaveKeyWithValue(names.OVNDBMembersAnnotation, "10.0.0.1,10.0.0.2,10.0.0.3"))
+	g.Expect(conf.Annotations).To(HaveKeyWithValue(names.OVNDBRemovedMembersAnnotation, "10.0.0.4,10.0.0.5"))
+
+	// they stay pending while the job is running
+	scaleDownJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "ovnkube-db-scale-down",
+			Namespace:   "openshift-ovn-kubernetes",
+			Annotations: map[string]string{names.OVNDBRemovedMembersAnnotation: "10.0.0.4,10.0.0.5"},
+		},
+	}
+	removed, job, err = bootstrapOVNDBRemovedMembers(conf, fake.NewClientBuilder().WithObjects(scaleDownJob.DeepCopy()).Build(), []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(removed).To(Equal([]string{"10.0.0.4", "10.0.0.5"}))
+	g.Expect(job).NotTo(BeNil())
+
+	// a master that comes back is not removed
+	removed, _, err = bootstrapOVNDBRemovedMembers(conf, noJob, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(removed).To(Equal([]string{"10.0.0.5"}))
+	g.Expect(conf.Annotations).To(HaveKeyWithValue(names.OVNDBRemovedMembersAnnotation, "10.0.0.5"))
+
+	// a master missing from the masters is kept while its node exists, e.g. while it is relabeled
+	conf.Annotations[names.OVNDBMembersAnnotation] = "10.0.0.1,10.0.0.2,10.0.0.3,10.0.0.4"
+	delete(conf.Annotations, names.OVNDBRemovedMembersAnnotation)
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "master-3"},
+		Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.0.4"}}},
+	}
+	removed, _, err = bootstrapOVNDBRemovedMembers(conf, fake.NewClientBuilder().WithObjects(node).Build(), []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(removed).To(BeEmpty())
+	g.Expect(conf.Annotations).To(HaveKeyWithValue(names.OVNDBMembersAnnotation, "10.0.0.1,10.0.0.2,10.0.0.3,10.0.0.4"))
+	g.Expect(conf.Annotations).NotTo(HaveKey(names.OVNDBRemovedMembersAnnotation))
+
+	// and removed once its node is deleted
+	removed, _, err = bootstrapOVNDBRemovedMembers(conf, noJob, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(removed).To(Equal([]string{"10.0.0.4"}))
+
+	// once the job succeeded, nothing is pending anymore
+	conf.Annotations[names.OVNDBMembersAnnotation] = "10.0.0.1,10.0.0.2,10.0.0.3"
+	conf.Annotations[names.OVNDBRemovedMembersAnnotation] = "10.0.0.4,10.0.0.5"
+	scaleDownJob.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+	removed, _, err = bootstrapOVNDBRemovedMembers(conf, fake.NewClientBuilder().WithObjects(scaleDownJob.DeepCopy()).Build(), []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(removed).To(BeEmpty())
+	g.Expect(conf.Annotations).NotTo(HaveKey(names.OVNDBRemovedMembersAnnotation))
+}
+
+func TestRenderOVNKubernetesDBScaleDown(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+	os.Setenv("RELEASE_VERSION", "2.0.0")
+
+	node := &appsv1.DaemonSet{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "DaemonSet"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "ovnkube-node",
+			Namespace:   "openshift-ovn-kubernetes",
+			Annotations: map[string]string{"release.openshift.io/version": "2.0.0"},
+		},
+	}
+	master := node.DeepCopy()
+	master.Name = "ovnkube-master"
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:               []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			RemovedMasterIPs:        []string{"13.14.15.16", "17.18.19.20"},
+			ExistingNodeDaemonset:   node,
+			ExistingMasterDaemonset: master,
+			OVNKubernetesConfig:     &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+
+	renderedMaster := func(objs []*uns.Unstructured) *appsv1.DaemonSet {
+		ds := &appsv1.DaemonSet{}
+		g.Expect(convert(findInObjs("apps", "DaemonSet", "ovnkube-master", "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+		return ds
+	}
+
+	// the master is held until the removed masters are kicked out of the databases
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(renderedMaster(objs).Spec.Template.Spec.Containers).To(BeEmpty())
+	g.Expect(bootstrapResult.Events).To(HaveLen(1))
+	g.Expect(bootstrapResult.Events[0].Reason).To(Equal("OVNDBScaleDownPending"))
+	job := findInObjs("batch", "Job", "ovnkube-db-scale-down", "openshift-ovn-kubernetes", objs)
+	g.Expect(job).NotTo(BeNil())
+	g.Expect(job.GetAnnotations()).To(HaveKeyWithValue(names.OVNDBRemovedMembersAnnotation, "13.14.15.16,17.18.19.20"))
+	nodeDS := &appsv1.DaemonSet{}
+	g.Expect(convert(findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs), nodeDS)).To(Succeed())
+	g.Expect(nodeDS.Spec.Template.Spec.Containers).NotTo(BeEmpty())
+
+	// a job for other masters is removed, so that it is recreated
+	bootstrapResult.OVN.DBScaleDownJob = &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{names.OVNDBRemovedMembersAnnotation: "13.14.15.16"}},
+	}
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(renderedMaster(objs).Spec.Template.Spec.Containers).To(BeEmpty())
+	g.Expect(findInObjs("batch", "Job", "ovnkube-db-scale-down", "openshift-ovn-kubernetes", objs)).To(BeNil())
+
+	// if the job failed, the master is rolled out anyway
+	bootstrapResult.OVN.DBScaleDownJob = &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{names.OVNDBRemovedMembersAnnotation: "13.14.15.16,17.18.19.20"}},
+		Status:     batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: v1.ConditionTrue}}},
+	}
+	bootstrapResult.Events = nil
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(renderedMaster(objs).Spec.Template.Spec.Containers).NotTo(BeEmpty())
+	g.Expect(bootstrapResult.Events).To(HaveLen(1))
+	g.Expect(bootstrapResult.Events[0].Type).To(Equal(v1.EventTypeWarning))
+	g.Expect(bootstrapResult.Events[0].Reason).To(Equal("OVNDBScaleDownFailed"))
+
+	// nothing to remove
+	bootstrapResult.OVN.RemovedMasterIPs = nil
+	bootstrapResult.OVN.DBScaleDownJob = nil
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(renderedMaster(objs).Spec.Template.Spec.Containers).NotTo(BeEmpty())
+	g.Expect(findInObjs("batch", "Job", "ovnkube-db-scale-down", "openshift-ovn-kubernetes", objs)).To(BeNil())
+}
+
+func TestBootstrapOVNMasterNodeSelector(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	conf := &operv1.Network{}
+	selector, custom := bootstrapOVNMasterNodeSelector(conf)
+	g.Expect(custom).To(BeFalse())
+	g.Expect(selector).To(Equal(map[string]string{"node-role.kubernetes.io/master": ""}))
+
+	conf.Annotations = map[string]string{names.OVNMasterNodeSelectorAnnotation: "node-role.kubernetes.io/network=,zone=a"}
+	selector, custom = bootstrapOVNMasterNodeSelector(conf)
+	g.Expect(custom).To(BeTrue())
+	g.Expect(selector).To(Equal(map[string]string{"node-role.kubernetes.io/network": "", "zone": "a"}))
+
+	// set-based selectors cannot be used as node selectors
+	for _, invalid := range []string{"", "zone in (a,b)", "!zone"} {
+		conf.Annotations[names.OVNMasterNodeSelectorAnnotation] = invalid
+		selector, custom = bootstrapOVNMasterNodeSelector(conf)
+		g.Expect(custom).To(BeFalse(), invalid)
+		g.Expect(selector).To(Equal(map[string]string{"node-role.kubernetes.io/master": ""}), invalid)
+	}
+}
+
+func TestRenderOVNKubernetesMasterNodeSelector(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+
+	renderedMaster := func() *appsv1.DaemonSet {
+		objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+		g.Expect(err).NotTo(HaveOccurred())
+		ds := &appsv1.DaemonSet{}
+		g.Expect(convert(findInObjs("apps", "DaemonSet", "ovnkube-master", "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+		return ds
+	}
+
+	ds := renderedMaster()
+	g.Expect(ds.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{
+		"node-role.kubernetes.io/master": "",
+		"beta.kubernetes.io/os":          "linux",
+	}))
+	g.Expect(ds.Spec.Template.Spec.Tolerations).To(ContainElement(v1.Toleration{Key: "node-role.kubernetes.io/master", Operator: v1.TolerationOpExists}))
+
+	bootstrapResult.OVN.MasterNodeSelector = map[string]string{"node-role.kubernetes.io/network": ""}
+	ds = renderedMaster()
+	g.Expect(ds.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{
+		"node-role.kubernetes.io/network": "",
+		"beta.kubernetes.io/os":           "linux",
+	}))
+	// the tolerations do not follow the selector
+	g.Expect(ds.Spec.Template.Spec.Tolerations).To(ContainElement(v1.Toleration{Key: "node-role.kubernetes.io/master", Operator: v1.TolerationOpExists}))
+	g.Expect(ds.Spec.Template.Spec.Tolerations).NotTo(ContainElement(v1.Toleration{Key: "node-role.kubernetes.io/network", Operator: v1.TolerationOpExists}))
+
+	bootstrapResult.OVN.MasterTolerations = []string{"node-role.kubernetes.io/network"}
+	ds = renderedMaster()
+	g.Expect(ds.Spec.Template.Spec.Tolerations).To(ContainElement(v1.Toleration{Key: "node-role.kubernetes.io/network", Operator: v1.TolerationOpExists}))
+	g.Expect(ds.Spec.Template.Spec.Tolerations).NotTo(ContainElement(v1.Toleration{Key: "node-role.kubernetes.io/master", Operator: v1.TolerationOpExists}))
+}
+
+func TestBootstrapOVNMasterTolerations(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	conf := &operv1.Network{}
+	g.Expect(bootstrapOVNMasterTolerations(conf)).To(Equal([]string{"node-role.kubernetes.io/master"}))
+
+	conf.Annotations = map[string]string{names.OVNMasterTolerationsAnnotation: "node-role.kubernetes.io/network, dedicated"}
+	g.Expect(bootstrapOVNMasterTolerations(conf)).To(Equal([]string{"node-role.kubernetes.io/network", "dedicated"}))
+
+	for _, invalid := range []string{"", "a,,b", "not a key"} {
+		conf.Annotations[names.OVNMasterTolerationsAnnotation] = invalid
+		g.Expect(bootstrapOVNMasterTolerations(conf)).To(Equal([]string{"node-role.kubernetes.io/master"}), invalid)
+	}
+}
+
+func TestOVNMasterIP(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "master-0"},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{Type: v1.NodeHostName, Address: "master-0"},
+				{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+			},
+		},
+	}
+
+	ip, err := ovnMasterIP(node, false, false)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ip).To(Equal("10.0.0.1"))
+
+	// the management IP annotation is required once the management network is used
+	_, err = ovnMasterIP(node, true, false)
+	g.Expect(err).To(HaveOccurred())
+
+	node.Annotations = map[string]string{names.OVNManagementIPNodeAnnotation: "fd00:10::1"}
+	ip, err = ovnMasterIP(node, true, false)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ip).To(Equal("fd00:10::1"))
+
+	node.Annotations[names.OVNManagementIPNodeAnnotation] = "eth1"
+	_, err = ovnMasterIP(node, true, false)
+	g.Expect(err).To(HaveOccurred())
+
+	node.Annotations[names.OVNManagementIPNodeAnnotation] = "fe80::1"
+	_, err = ovnMasterIP(node, true, false)
+	g.Expect(err).To(HaveOccurred())
+
+	node.Status.Addresses = nil
+	_, err = ovnMasterIP(node, false, false)
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestOVNMasterIPSelection(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		addresses  []v1.NodeAddress
+		preferIPv6 bool
+		expected   string
+	}{
+		{
+			name: "first InternalIP of the cluster IP family",
+			addresses: []v1.NodeAddress{
+				{Type: v1.NodeExternalIP, Address: "192.0.2.1"},
+				{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: v1.NodeInternalIP, Address: "10.0.0.2"},
+				{Type: v1.NodeInternalIP, Address: "fd00::1"},
+			},
+			expected: "10.0.0.1",
+		},
+		{
+			name: "IPv6 cluster on a dual-stack node",
+			addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: v1.NodeInternalIP, Address: "fd00::1"},
+			},
+			preferIPv6: true,
+			expected:   "fd00::1",
+		},
+		{
+			name: "link-local addresses are skipped",
+			addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "fe80::1"},
+				{Type: v1.NodeInternalIP, Address: "169.254.0.1"},
+				{Type: v1.NodeInternalIP, Address: "2001:db8::1"},
+			},
+			preferIPv6: true,
+			expected:   "2001:db8::1",
+		},
+		{
+			name: "loopback, unspecified and invalid addresses are skipped",
+			addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "::1"},
+				{Type: v1.NodeInternalIP, Address: "::"},
+				{Type: v1.NodeInternalIP, Address: "master-0"},
+				{Type: v1.NodeInternalIP, Address: "2001:db8::1"},
+			},
+			preferIPv6: true,
+			expected:   "2001:db8::1",
+		},
+		{
+			name: "addresses are returned in canonical form",
+			addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "2001:DB8:0:0::0001"},
+			},
+			preferIPv6: true,
+			expected:   "2001:db8::1",
+		},
+		{
+			name: "falls back to the other IP family",
+			addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "fe80::1"},
+				{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+			},
+			preferIPv6: true,
+			expected:   "10.0.0.1",
+		},
+		{
+			name: "only link-local addresses",
+			addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "fe80::1"},
+			},
+			preferIPv6: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			node := &v1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "master-0"},
+				Status:     v1.NodeStatus{Addresses: tc.addresses},
+			}
+			ip, err := ovnMasterIP(node, false, tc.preferIPv6)
+			if tc.expected == "" {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(ip).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestOVNDBEndpoints(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(dbList([]string{"2001:db8::1", "2001:db8::2"}, "9641")).To(Equal("ssl:[2001:db8::1]:9641,ssl:[2001:db8::2]:9641"))
+	g.Expect(dbList([]string{"10.0.0.1"}, "9641")).To(Equal("ssl:10.0.0.1:9641"))
+	g.Expect(listenDualStack("2001:db8::1")).To(Equal(":[::]"))
+	g.Expect(listenDualStack("10.0.0.1")).To(Equal(""))
+}
+
+func TestShouldUpdateOVNKonIPFamilyChange(t *testing.T) {
+
+	for _, tc := range []struct {
+		name         string
+		node         *appsv1.DaemonSet
+		master       *appsv1.DaemonSet
+		ipFamilyMode string
+		expectNode   bool
+		expectMaster bool
+	}{
+		{
+			name:         "all empty",
+			node:         &appsv1.DaemonSet{},
+			master:       &appsv1.DaemonSet{},
+			expectNode:   true,
+			expectMaster: true,
+			ipFamilyMode: names.IPFamilySingleStack,
+		},
+		{
+			name:         "fresh cluster",
+			node:         &appsv1.DaemonSet{},
+			master:       &appsv1.DaemonSet{},
+			expectNode:   true,
+			expectMaster: true,
+			ipFamilyMode: names.IPFamilySingleStack,
+		},
+		{
+			name: "no configuration change",
+			node: &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ovnkube-node",
+					Namespace: "openshift-ovn-kubernetes",
+					Annotations: map[string]string{
+						names.NetworkIPFamilyModeAnnotation: names.IPFamilySingleStack,
+					},
+				},
+			},
+			master: &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ovnkube-master",
+					Namespace: "openshift-ovn-kubernetes",
+					Annotations: map[string]string{
+						names.NetworkIPFamilyModeAnnotation: names.IPFamilySingleStack,
+					},
+					Generation: 1,
+				},
+				Status: appsv1.DaemonSetStatus{
+					CurrentNumberScheduled: 3,
+					DesiredNumberScheduled: 3,
+					NumberAvailable:        3,
+					NumberMisscheduled:     0,
+					NumberReady:            3,
+					ObservedGeneration:     2,
+					UpdatedNumberScheduled: 3,
+				},
+			},
+			expectNode:   true,
+			expectMaster: true,
+			ipFamilyMode: names.IPFamilySingleStack,
+		},
+		{
+			name: "configuration changed",
+			node: &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ovnkube-node",
+					Namespace: "openshift-ovn-kubernetes",
+					Annotations: map[string]string{
+						names.NetworkIPFamilyModeAnnotation: names.IPFamilySingleStack,
+					},
+				},
+			},
+			master: &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ovnkube-master",
+					Namespace: "openshift-ovn-kubernetes",
+					Annotations: map[string]string{
+						names.NetworkIPFamilyModeAnnotation: names.IPFamilySingleStack,
+					},
+				},
+			},
+			expectNode:   false,
+			expectMaster: true,
+			ipFamilyMode: names.IPFamilyDualStack,
+		},
+		{
+			name: "configuration changed, master updated and node remaining",
+			node: &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ovnkube-node",
+					Namespace: "openshift-ovn-kubernetes",
+					Annotations: map[string]string{
+						names.NetworkIPFamilyModeAnnotation: names.IPFamilySingleStack,
+					},
+				},
+			},
+			master: &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ovnkube-master",
+					Namespace: "openshift-ovn-kubernetes",
+					Annotations: map[string]string{
+						names.NetworkIPFamilyModeAnnotation: names.IPFamilyDualStack,
+					},
+					Generation: 1,
+				},
+				Status: appsv1.DaemonSetStatus{
+					CurrentNumberScheduled: 3,
+					DesiredNumberScheduled: 3,
+					NumberAvailable:        3,
+					NumberMisscheduled:     0,
+					NumberReady:            3,
+					ObservedGeneration:     2,
+					UpdatedNumberScheduled: 3,
+				},
+			},
+			expectNode:   true,
+			expectMaster: true,
+			ipFamilyMode: names.IPFamilyDualStack,
+		},
+		{
+			name: "configuration changed, master updated and node remaining but still rolling out",
+			node: &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ovnkube-node",
+					Namespace: "openshift-ovn-kubernetes",
+					Annotations: map[string]string{
+						names.NetworkIPFamilyModeAnnotation: names.IPFamilySingleStack,
+					},
+				},
+			},
+			master: &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ovnkube-master",
+					Namespace: "openshift-ovn-kubernetes",
+					Annotations: map[string]string{
+						names.NetworkIPFamilyModeAnnotation: names.IPFamilyDualStack,
+					},
+					Generation: 1,
+				},
+				Status: appsv1.DaemonSetStatus{
+					CurrentNumberScheduled: 3,
+					DesiredNumberScheduled: 3,
+					NumberAvailable:        2,
+					NumberUnavailable:      1,
+					NumberMisscheduled:     0,
+					NumberReady:            2,
+					ObservedGeneration:     2,
+					UpdatedNumberScheduled: 3,
+				},
+			},
+			expectNode:   false,
+			expectMaster: true,
+			ipFamilyMode: names.IPFamilyDualStack,
+		},
+		// this should not be possible, because configuration changes always update master first
+		{
+			name: "configuration changed, node updated and master remaining",
+			node: &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ovnkube-node",
+					Namespace: "openshift-ovn-kubernetes",
+					Annotations: map[string]string{
+						names.NetworkIPFamilyModeAnnotation: names.IPFamilyDualStack,
+					},
+				},
+			},
+			master: &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ovnkube-master",
+					Namespace: "openshift-ovn-kubernetes",
+					Annotations: map[string]string{
+						names.NetworkIPFamilyModeAnnotation: names.IPFamilySingleStack,
+					},
+					Generation: 2,
+				},
+				Status: appsv1.DaemonSetStatus{
+					CurrentNumberScheduled: 3,
+					DesiredNumberScheduled: 3,
+					NumberAvailable:        3,
+					NumberMisscheduled:     0,
+					NumberReady:            3,
+					ObservedGeneration:     2,
+					UpdatedNumberScheduled: 3,
+				},
+			},
+			expectNode:   false,
+			expectMaster: true,
+			ipFamilyMode: names.IPFamilyDualStack,
+		},
+	} {
+
+		t.Run(tc.name, func(t *testing.T) {
+			updateNode, updateMaster := shouldUpdateOVNKonIPFamilyChange(tc.node, tc.master, tc.ipFamilyMode)
+			if updateNode != tc.expectNode {
+				t.Errorf("Expected node update: %v received %v", tc.expectNode, updateNode)
+			}
+			if updateMaster != tc.expectMaster {
+				t.Errorf("Expected node update: %v received %v", tc.expectNode, updateNode)
+			}
+
+		})
+	}
+
+}
+
+func TestRenderOVNKubernetesDualStackPrecedenceOverUpgrade(t *testing.T) {
+	//cluster was in single-stack and receives a converts to dual-stack
+	config := &operv1.NetworkSpec{
+		ServiceNetwork: []string{"172.30.0.0/16", "fd00:3:2:1::/112"},
+		ClusterNetwork: []operv1.ClusterNetworkEntry{
+			{
+				CIDR:       "10.128.0.0/15",
+				HostPrefix: 23,
+			},
+			{
+				CIDR:       "fd00:1:2:3::/64",
+				HostPrefix: 56,
+			},
+		},
+		DefaultNetwork: operv1.DefaultNetworkDefinition{
+			Type: operv1.NetworkTypeOVNKubernetes,
+			OVNKubernetesConfig: &operv1.OVNKubernetesConfig{
+				GenevePort: ptrToUint32(8061),
+			},
+		},
+	}
+	errs := validateOVNKubernetes(config)
+	if len(errs) > 0 {
+		t.Errorf("Unexpected error: %v", errs)
+	}
+	FillDefaults(config, nil)
+
+	// at the same time we have an upgrade
+	os.Setenv("RELEASE_VERSION", "2.0.0")
+
+	// bootstrap also represents current status
+	// the current cluster is single-stack and has version 1.9.9
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs: []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			ExistingMasterDaemonset: &appsv1.DaemonSet{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "apps/v1",
+					Kind:       "DaemonSet",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ovnkube-master",
+					Namespace: "openshift-ovn-kubernetes",
+					Annotations: map[string]string{
+						names.NetworkIPFamilyModeAnnotation: names.IPFamilySingleStack,
+						"release.openshift.io/version":      "1.9.9",
+					},
+				},
+			},
+			ExistingNodeDaemonset: &appsv1.DaemonSet{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "apps/v1",
+					Kind:       "DaemonSet",
+				},
+				ObjectMeta: metav1.ObjectMeta{
 					Name:      "ovnkube-node",
 					Namespace: "openshift-ovn-kubernetes",
 					Annotations: map[string]string{
 						names.NetworkIPFamilyModeAnnotation: names.IPFamilySingleStack,
+						"release.openshift.io/version":      "1.9.9",
 					},
 				},
 			},
-			master: &appsv1.DaemonSet{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "ovnkube-master",
-					Namespace: "openshift-ovn-kubernetes",
-					Annotations: map[string]string{
-						names.NetworkIPFamilyModeAnnotation: names.IPFamilyDualStack,
-					},
-					Generation: 1,
-				},
-				Status: appsv1.DaemonSetStatus{
-					CurrentNumberScheduled: 3,
-					DesiredNumberScheduled: 3,
-					NumberAvailable:        2,
-					NumberUnavailable:      1,
-					NumberMisscheduled:     0,
-					NumberReady:            2,
-					ObservedGeneration:     2,
-					UpdatedNumberScheduled: 3,
-				},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+			DBSchemaCheckJob:    ovnDBSchemaCheckJob("2.0.0", batchv1.JobComplete),
+		},
+	}
+	usNode, err := k8s.ToUnstructured(bootstrapResult.OVN.ExistingNodeDaemonset)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	usMaster, err := k8s.ToUnstructured(bootstrapResult.OVN.ExistingMasterDaemonset)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	// the new rendered config should hold the node to do the dualstack conversion
+	// the upgrade code holds the masters to update the nodes first
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	renderedNode := findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs)
+	renderedMaster := findInObjs("apps", "DaemonSet", "ovnkube-master", "openshift-ovn-kubernetes", objs)
+
+	// the node has to be the same
+	if !reflect.DeepEqual(usNode, renderedNode) {
+		t.Errorf("node daemonset are not equal, dual-stack should upgrade masters first %+v", renderedNode)
+	}
+	// the master has to use the new annotations for dual-stack so it has to be mutated
+	if reflect.DeepEqual(usMaster, renderedMaster) {
+		t.Errorf("master daemonset are equal, dual-stack should modify masters")
+	}
+	if len(bootstrapResult.Events) != 1 || bootstrapResult.Events[0].Reason != "IPFamilyRolloutDeferred" {
+		t.Errorf("expected an IPFamilyRolloutDeferred event, got %+v", bootstrapResult.Events)
+	}
+}
+
+func TestRenderOVNKubernetesOVSFlowsConfigMap(t *testing.T) {
+	config := &operv1.NetworkSpec{
+		ServiceNetwork: []string{"172.30.0.0/16"},
+		ClusterNetwork: []operv1.ClusterNetworkEntry{
+			{CIDR: "10.128.0.0/15", HostPrefix: 23},
+		},
+		DefaultNetwork: operv1.DefaultNetworkDefinition{
+			Type: operv1.NetworkTypeOVNKubernetes,
+			OVNKubernetesConfig: &operv1.OVNKubernetesConfig{
+				GenevePort:        ptrToUint32(8061),
+				PolicyAuditConfig: &operv1.PolicyAuditConfig{},
+			},
+		},
+		DisableMultiNetwork: boolPtr(true),
+	}
+	testCases := []struct {
+		Description string
+		FlowsConfig *bootstrap.FlowsConfig
+		Expected    []v1.EnvVar
+		NotExpected []string
+		// ExpectedIPFIX is the IPFIX tuning, which is applied in place rather than through the environment
+		ExpectedIPFIX string
+		Reloader      bool
+		// TLSRelay is set when the records are relayed to the collector over TLS
+		TLSRelay bool
+	}{
+		{
+			Description: "No detected OVN flows config",
+			NotExpected: []string{"IPFIX_COLLECTORS", "IPFIX_CACHE_MAX_FLOWS",
+				"IPFIX_CACHE_ACTIVE_TIMEOUT", "IPFIX_SAMPLING"},
+		},
+		{
+			Description: "Only target is specified",
+			FlowsConfig: &bootstrap.FlowsConfig{
+				Target: "1.2.3.4:567",
+			},
+			Expected: []v1.EnvVar{{Name: "IPFIX_COLLECTORS", Value: "1.2.3.4:567"}},
+			NotExpected: []string{"IPFIX_CACHE_MAX_FLOWS",
+				"IPFIX_CACHE_ACTIVE_TIMEOUT", "IPFIX_SAMPLING"},
+			Reloader: true,
+		},
+		{
+			Description: "IPFIX performance variables are specified",
+			FlowsConfig: &bootstrap.FlowsConfig{
+				Target:             "7.8.9.10:1112",
+				CacheMaxFlows:      uintPtr(123),
+				CacheActiveTimeout: uintPtr(456),
+				Sampling:           uintPtr(789),
+			},
+			Expected: []v1.EnvVar{
+				{Name: "IPFIX_COLLECTORS", Value: "7.8.9.10:1112"},
+			},
+			NotExpected: []string{"IPFIX_CACHE_MAX_FLOWS",
+				"IPFIX_CACHE_ACTIVE_TIMEOUT", "IPFIX_SAMPLING"},
+			ExpectedIPFIX: "IPFIX_CACHE_MAX_FLOWS=\"123\"\nIPFIX_CACHE_ACTIVE_TIMEOUT=\"456\"\nIPFIX_SAMPLING=\"789\"\n",
+			Reloader:      true,
+		},
+		{
+			Description: "Target over TLS",
+			FlowsConfig: &bootstrap.FlowsConfig{
+				Target: "collector.example.com:4739",
+				TLS:    true,
+			},
+			Expected: []v1.EnvVar{{Name: "IPFIX_COLLECTORS", Value: "127.0.0.1:29739"}},
+			NotExpected: []string{"IPFIX_CACHE_MAX_FLOWS",
+				"IPFIX_CACHE_ACTIVE_TIMEOUT", "IPFIX_SAMPLING"},
+			Reloader: true,
+			TLSRelay: true,
+		},
+		{
+			Description: "Wrong configuration: target missing but performance variables present",
+			FlowsConfig: &bootstrap.FlowsConfig{
+				CacheMaxFlows:      uintPtr(123),
+				CacheActiveTimeout: uintPtr(456),
+				Sampling:           uintPtr(789),
+			},
+			NotExpected: []string{"IPFIX_COLLECTORS", "IPFIX_CACHE_MAX_FLOWS",
+				"IPFIX_CACHE_ACTIVE_TIMEOUT", "IPFIX_SAMPLING"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Description, func(t *testing.T) {
+			RegisterTestingT(t)
+			g := NewGomegaWithT(t)
+			bootstrapResult := &bootstrap.BootstrapResult{
+				OVN: bootstrap.OVNBootstrapResult{
+					MasterIPs: []string{"1.2.3.4"},
+					OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{
+						GatewayMode: "shared",
+					},
+					FlowsConfig: tc.FlowsConfig,
+				},
+			}
+			objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+			g.Expect(err).ToNot(HaveOccurred())
+			nodeDS := findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs)
+			ds := appsv1.DaemonSet{}
+			g.Expect(convert(nodeDS, &ds)).To(Succeed())
+			nodeCont, ok := findContainer(ds.Spec.Template.Spec.Containers, "ovnkube-node")
+			g.Expect(ok).To(BeTrue(), "expecting container named ovnkube-node in the DaemonSet")
+			g.Expect(nodeCont.Env).To(ContainElements(tc.Expected))
+			for _, ev := range nodeCont.Env {
+				Expect(tc.NotExpected).ToNot(ContainElement(ev.Name))
+			}
+			_, ok = findContainer(ds.Spec.Template.Spec.Containers, "ovs-flows-reloader")
+			g.Expect(ok).To(Equal(tc.Reloader))
+			relay, ok := findContainer(ds.Spec.Template.Spec.Containers, "ovs-flows-tls-relay")
+			g.Expect(ok).To(Equal(tc.TLSRelay))
+			if tc.TLSRelay {
+				g.Expect(relay.Command[2]).To(ContainSubstring("UDP4-RECV:29739,bind=127.0.0.1"))
+				g.Expect(relay.Command[2]).To(ContainSubstring("OPENSSL:collector.example.com:4739,cafile="))
+				// the cluster-wide trusted CA bundle is injected
+				ca := findInObjs("", "ConfigMap", "ovnkube-ipfix-trusted-ca", "openshift-ovn-kubernetes", objs)
+				g.Expect(ca).NotTo(BeNil())
+				g.Expect(ca.GetLabels()).To(HaveKeyWithValue(names.TRUSTED_CA_BUNDLE_CONFIGMAP_LABEL, "true"))
+			}
+
+			ipfixConfig := findInObjs("", "ConfigMap", "ovnkube-ipfix-config", "openshift-ovn-kubernetes", objs)
+			g.Expect(ipfixConfig).NotTo(BeNil())
+			ipfixEnv, _, err := uns.NestedString(ipfixConfig.Object, "data", "ipfix.env")
+			g.Expect(err).NotTo(HaveOccurred())
+			if tc.ExpectedIPFIX == "" {
+				tc.ExpectedIPFIX = "IPFIX_CACHE_MAX_FLOWS=\"\"\nIPFIX_CACHE_ACTIVE_TIMEOUT=\"\"\nIPFIX_SAMPLING=\"\"\n"
+			}
+			g.Expect(ipfixEnv).To(Equal(tc.ExpectedIPFIX))
+		})
+	}
+}
+
+func TestBootStrapOvsConfigMap_SharedTarget(t *testing.T) {
+	fc := bootstrapFlowsConfig(&fakeClientReader{
+		configMap: &v1.ConfigMap{
+			Data: map[string]string{
+				"sharedTarget":       "1.2.3.4:3030",
+				"cacheActiveTimeout": "3200ms",
+				"cacheMaxFlows":      "33",
+				"sampling":           "55",
+			},
+		},
+	})
+
+	assert.Equal(t, "1.2.3.4:3030", fc.Target)
+	// verify that the 200ms get truncated
+	assert.EqualValues(t, 3, *fc.CacheActiveTimeout)
+	assert.EqualValues(t, 33, *fc.CacheMaxFlows)
+	assert.EqualValues(t, 55, *fc.Sampling)
+}
+
+func TestBootStrapOvsConfigMap_NodePort(t *testing.T) {
+	fc := bootstrapFlowsConfig(&fakeClientReader{
+		configMap: &v1.ConfigMap{
+			Data: map[string]string{
+				"nodePort":           "3131",
+				"cacheActiveTimeout": "invalid timeout",
+				"cacheMaxFlows":      "invalid int",
+			},
+		},
+	})
+
+	assert.Equal(t, ":3131", fc.Target)
+	// verify that invalid or unspecified fields are ignored
+	assert.Nil(t, fc.CacheActiveTimeout)
+	assert.Nil(t, fc.CacheMaxFlows)
+	assert.Nil(t, fc.Sampling)
+}
+
+func TestBootStrapOvsConfigMap_TLS(t *testing.T) {
+	g := NewGomegaWithT(t)
+	caBundle, _, err := certutil.GenerateSelfSignedCertKey("collector.example.com", nil, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	fc := bootstrapFlowsConfig(&fakeClientReader{
+		configMap: &v1.ConfigMap{
+			Data: map[string]string{
+				"sharedTarget": "collector.example.com:4739",
+				"tls":          "true",
+				"caBundle":     string(caBundle),
+			},
+		},
+	})
+	g.Expect(fc.TLS).To(BeTrue())
+	g.Expect(fc.CABundle).To(Equal(string(caBundle)))
+
+	// an invalid CA bundle falls back to the cluster-wide trusted one
+	fc = bootstrapFlowsConfig(&fakeClientReader{
+		configMap: &v1.ConfigMap{
+			Data: map[string]string{
+				"sharedTarget": "collector.example.com:4739",
+				"tls":          "true",
+				"caBundle":     "invalid",
+			},
+		},
+	})
+	g.Expect(fc.TLS).To(BeTrue())
+	g.Expect(fc.CABundle).To(BeEmpty())
+
+	// TLS is not supported with the collectors on the node port of each node
+	fc = bootstrapFlowsConfig(&fakeClientReader{
+		configMap: &v1.ConfigMap{
+			Data: map[string]string{
+				"nodePort": "3131",
+				"tls":      "true",
+			},
+		},
+	})
+	g.Expect(fc.Target).To(Equal(":3131"))
+	g.Expect(fc.TLS).To(BeFalse())
+}
+
+func TestBootStrapOvsConfigMap_IncompleteMap(t *testing.T) {
+	fc := bootstrapFlowsConfig(&fakeClientReader{
+		configMap: &v1.ConfigMap{
+			Data: map[string]string{
+				"cacheActiveTimeout": "3200ms",
+				"cacheMaxFlows":      "33",
+				"sampling":           "55",
+			},
+		},
+	})
+
+	// without sharedTarget nor nodePort, flow collection can't be set
+	assert.Nil(t, fc)
+}
+
+func TestBootStrapOvsConfigMap_UnexistingMap(t *testing.T) {
+	fc := bootstrapFlowsConfig(&fakeClientReader{configMap: nil})
+
+	// without sharedTarget nor nodePort, flow collection can't be set
+	assert.Nil(t, fc)
+}
+
+type fakeClientReader struct {
+	configMap   *v1.ConfigMap
+	flowsConfig *netopv1.OVSFlowsConfig
+}
+
+func (f *fakeClientReader) Get(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+	if fcPtr, ok := obj.(*netopv1.OVSFlowsConfig); ok {
+		if f.flowsConfig == nil {
+			return &kapierrors.StatusError{ErrStatus: metav1.Status{
+				Reason: metav1.StatusReasonNotFound,
+			}}
+		}
+		*fcPtr = *f.flowsConfig
+		return nil
+	}
+	if cmPtr, ok := obj.(*v1.ConfigMap); !ok {
+		return fmt.Errorf("expecting *v1.ConfigMap, got %T", obj)
+	} else if f.configMap == nil {
+		return &kapierrors.StatusError{ErrStatus: metav1.Status{
+			Reason: metav1.StatusReasonNotFound,
+		}}
+	} else {
+		*cmPtr = *f.configMap
+	}
+	return nil
+}
+
+func (f *fakeClientReader) List(_ context.Context, _ client.ObjectList, _ ...client.ListOption) error {
+	return errors.New("unexpected invocation to List")
+}
+
+func findContainer(conts []v1.Container, name string) (v1.Container, bool) {
+	for _, cont := range conts {
+		if cont.Name == name {
+			return cont, true
+		}
+	}
+	return v1.Container{}, false
+}
+
+func convert(src *uns.Unstructured, dst metav1.Object) error {
+	j, err := src.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(j, dst)
+}
+
+func findInObjs(group, kind, name, namespace string, objs []*uns.Unstructured) *uns.Unstructured {
+	for _, obj := range objs {
+		if (obj.GroupVersionKind().GroupKind() == schema.GroupKind{Group: group, Kind: kind} &&
+			obj.GetNamespace() == namespace &&
+			obj.GetName() == name) {
+			return obj
+		}
+	}
+	return nil
+}
+
+func extractOVNKubeConfig(g *WithT, objs []*uns.Unstructured) string {
+	for _, obj := range objs {
+		if obj.GetKind() == "ConfigMap" && obj.GetName() == "ovnkube-config" {
+			val, ok, err := uns.NestedString(obj.Object, "data", "ovnkube.conf")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(ok).To(BeTrue())
+			return string(val)
+		}
+	}
+	return ""
+}
+
+// checkDaemonsetAnnotation check that all the daemonset have the annotation with the
+// same key and value
+func checkDaemonsetAnnotation(g *WithT, objs []*uns.Unstructured, key, value string) bool {
+	if key == "" || value == "" {
+		return false
+	}
+	foundMaster, foundNode := false, false
+	for _, obj := range objs {
+		if obj.GetAPIVersion() == "apps/v1" && obj.GetKind() == "DaemonSet" &&
+			(obj.GetName() == "ovnkube-master" || obj.GetName() == "ovnkube-node") {
+
+			// check daemonset annotation
+			anno := obj.GetAnnotations()
+			if anno == nil {
+				return false
+			}
+			v, ok := anno[key]
+			if !ok || v != value {
+				return false
+			}
+			// check template annotation
+			anno, _, _ = uns.NestedStringMap(obj.Object, "spec", "template", "metadata", "annotations")
+			if anno == nil {
+				return false
+			}
+			v, ok = anno[key]
+			if !ok || v != value {
+				return false
+			}
+			// record the daemonsets we have checked
+			if obj.GetName() == "ovnkube-master" {
+				foundMaster = true
+			} else {
+				foundNode = true
+			}
+		}
+	}
+	return foundMaster && foundNode
+}
+
+func ptrToUint32(x uint32) *uint32 {
+	return &x
+}
+
+func uintPtr(x uint) *uint {
+	return &x
+}
+
+func boolPtr(x bool) *bool {
+	return &x
+}
+
+func TestBootstrapOVNExternalGateways(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	for _, tc := range []struct {
+		annotations          map[string]string
+		multiExternalGateway bool
+		bfd                  bool
+	}{
+		{
+			annotations: nil,
+		},
+		{
+			annotations:          map[string]string{names.OVNMultiExternalGatewayAnnotation: "true"},
+			multiExternalGateway: true,
+		},
+		{
+			annotations: map[string]string{
+				names.OVNMultiExternalGatewayAnnotation: "true",
+				names.OVNExternalGatewayBFDAnnotation:   "true",
+			},
+			multiExternalGateway: true,
+			bfd:                  true,
+		},
+		{
+			// BFD requires the multiple external gateways
+			annotations: map[string]string{names.OVNExternalGatewayBFDAnnotation: "true"},
+		},
+		{
+			annotations: map[string]string{names.OVNMultiExternalGatewayAnnotation: "yes please"},
+		},
+	} {
+		conf := &operv1.Network{}
+		conf.Annotations = tc.annotations
+		multiExternalGateway, bfd := bootstrapOVNExternalGateways(conf)
+		g.Expect(multiExternalGateway).To(Equal(tc.multiExternalGateway), "%v", tc.annotations)
+		g.Expect(bfd).To(Equal(tc.bfd), "%v", tc.annotations)
+	}
+}
+
+func TestRenderOVNKubernetesExternalGateways(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+	bfdDefault := func(objs []*uns.Unstructured) interface{} {
+		crd := findInObjs("apiextensions.k8s.io", "CustomResourceDefinition", "adminpolicybasedexternalroutes.k8s.ovn.org", "", objs)
+		g.Expect(crd).NotTo(BeNil())
+		versions, _, err := uns.NestedSlice(crd.Object, "spec", "versions")
+		g.Expect(err).NotTo(HaveOccurred())
+		val, found, err := uns.NestedFieldNoCopy(versions[0].(map[string]interface{}),
+			"schema", "openAPIV3Schema", "properties", "spec", "properties", "nextHops", "properties",
+			"static", "items", "properties", "bfdEnabled", "default")
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(found).To(BeTrue())
+		return val
+	}
+
+	// the CRD is rendered even when the feature is disabled, to keep the resources
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(extractOVNKubeConfig(g, objs)).NotTo(ContainSubstring("enable-multi-external-gateway"))
+	g.Expect(bfdDefault(objs)).To(Equal(false))
+
+	bootstrapResult.OVN.OVNKubernetesConfig.MultiExternalGateway = true
+	bootstrapResult.OVN.OVNKubernetesConfig.ExternalGatewayBFD = true
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(extractOVNKubeConfig(g, objs)).To(ContainSubstring("enable-egress-firewall=true\nenable-multi-external-gateway=true\n"))
+	g.Expect(bfdDefault(objs)).To(Equal(true))
+}
+
+func TestBootstrapOVNLoadBalancerTimeouts(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	for _, tc := range []struct {
+		annotations     map[string]string
+		affinityTimeout int
+		idleTimeout     int
+	}{
+		{
+			annotations: nil,
+		},
+		{
+			annotations: map[string]string{
+				names.OVNLBAffinityTimeoutAnnotation: "21600",
+				names.OVNLBIdleTimeoutAnnotation:     "3600",
+			},
+			affinityTimeout: 21600,
+			idleTimeout:     3600,
+		},
+		{
+			annotations: map[string]string{
+				names.OVNLBAffinityTimeoutAnnotation: "86401",
+				names.OVNLBIdleTimeoutAnnotation:     "0",
+			},
+		},
+		{
+			annotations: map[string]string{
+				names.OVNLBAffinityTimeoutAnnotation: "1h",
+				names.OVNLBIdleTimeoutAnnotation:     "-1",
+			},
+		},
+	} {
+		conf := &operv1.Network{}
+		conf.Annotations = tc.annotations
+		affinityTimeout, idleTimeout := bootstrapOVNLoadBalancerTimeouts(conf)
+		g.Expect(affinityTimeout).To(Equal(tc.affinityTimeout), "%v", tc.annotations)
+		g.Expect(idleTimeout).To(Equal(tc.idleTimeout), "%v", tc.annotations)
+	}
+}
+
+func TestRenderOVNKubernetesLoadBalancerTimeouts(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+
+	// the ovn-kubernetes defaults are kept
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(extractOVNKubeConfig(g, objs)).NotTo(ContainSubstring("lb-affinity-timeout"))
+	g.Expect(extractOVNKubeConfig(g, objs)).NotTo(ContainSubstring("lb-idle-timeout"))
+	masterConfigHash := func(objs []*uns.Unstructured) string {
+		g.Expect(setConfigHashAnnotations(objs)).To(Succeed())
+		master := findInObjs("apps", "DaemonSet", "ovnkube-master", "openshift-ovn-kubernetes", objs)
+		g.Expect(master).NotTo(BeNil())
+		annotations, _, err := uns.NestedStringMap(master.Object, "spec", "template", "metadata", "annotations")
+		g.Expect(err).NotTo(HaveOccurred())
+		return annotations[names.ConfigHashAnnotation]
+	}
+	defaultHash := masterConfigHash(objs)
+
+	bootstrapResult.OVN.OVNKubernetesConfig.LBAffinityTimeout = 21600
+	bootstrapResult.OVN.OVNKubernetesConfig.LBIdleTimeout = 3600
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(extractOVNKubeConfig(g, objs)).To(ContainSubstring("host-network-namespace=\"openshift-host-network\"\nlb-affinity-timeout=21600\nlb-idle-timeout=3600\n"))
+	// the pods are rolled out, as they only read the timeouts on startup
+	g.Expect(masterConfigHash(objs)).NotTo(Equal(defaultHash))
+}
+
+func TestBootstrapOVNDisableEmptyLBEvents(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	conf := &operv1.Network{}
+	g.Expect(bootstrapOVNDisableEmptyLBEvents(conf)).To(BeFalse())
+	conf.Annotations = map[string]string{names.OVNEmptyLBEventsAnnotation: "false"}
+	g.Expect(bootstrapOVNDisableEmptyLBEvents(conf)).To(BeTrue())
+	conf.Annotations = map[string]string{names.OVNEmptyLBEventsAnnotation: "true"}
+	g.Expect(bootstrapOVNDisableEmptyLBEvents(conf)).To(BeFalse())
+	conf.Annotations = map[string]string{names.OVNEmptyLBEventsAnnotation: "off"}
+	g.Expect(bootstrapOVNDisableEmptyLBEvents(conf)).To(BeFalse())
+}
+
+func TestRenderOVNKubernetesEmptyLBEvents(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+	masterScript := func(objs []*uns.Unstructured) string {
+		ds := &appsv1.DaemonSet{}
+		g.Expect(convert(findInObjs("apps", "DaemonSet", "ovnkube-master", "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+		for _, c := range ds.Spec.Template.Spec.Containers {
+			if c.Name == "ovnkube-master" {
+				return strings.Join(c.Command, " ")
+			}
+		}
+		return ""
+	}
+
+	// the idling support is enabled by default
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(masterScript(objs)).To(ContainSubstring("--ovn-empty-lb-events"))
+
+	bootstrapResult.OVN.OVNKubernetesConfig.DisableEmptyLBEvents = true
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(masterScript(objs)).To(ContainSubstring("--init-master"))
+	g.Expect(masterScript(objs)).NotTo(ContainSubstring("--ovn-empty-lb-events"))
+}
+
+func TestBootstrapOVNDBMaintenance(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	for _, tc := range []struct {
+		annotations map[string]string
+		schedule    string
+		snapshot    bool
+	}{
+		{
+			annotations: nil,
+		},
+		{
+			annotations: map[string]string{names.OVNDBMaintenanceScheduleAnnotation: "0 3 * * SAT"},
+			schedule:    "0 3 * * SAT",
+		},
+		{
+			annotations: map[string]string{
+				names.OVNDBMaintenanceScheduleAnnotation: "@weekly",
+				names.OVNDBMaintenanceSnapshotAnnotation: "true",
+			},
+			schedule: "@weekly",
+			snapshot: true,
+		},
+		{
+			annotations: map[string]string{
+				names.OVNDBMaintenanceScheduleAnnotation: "*/30 1-4 * *",
+				names.OVNDBMaintenanceSnapshotAnnotation: "true",
 			},
-			expectNode:   false,
-			expectMaster: true,
-			ipFamilyMode: names.IPFamilyDualStack,
 		},
-		// this should not be possible, because configuration changes always update master first
 		{
-			name: "configuration changed, node updated and master remaining",
-			node: &appsv1.DaemonSet{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "ovnkube-node",
-					Namespace: "openshift-ovn-kubernetes",
-					Annotations: map[string]string{
-						names.NetworkIPFamilyModeAnnotation: names.IPFamilyDualStack,
-					},
-				},
+			annotations: map[string]string{names.OVNDBMaintenanceScheduleAnnotation: "0 3 * * 6; reboot"},
+		},
+		{
+			annotations: map[string]string{
+				names.OVNDBMaintenanceScheduleAnnotation: "0 3 * * 6",
+				names.OVNDBMaintenanceSnapshotAnnotation: "sure",
 			},
-			master: &appsv1.DaemonSet{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "ovnkube-master",
-					Namespace: "openshift-ovn-kubernetes",
-					Annotations: map[string]string{
-						names.NetworkIPFamilyModeAnnotation: names.IPFamilySingleStack,
-					},
-					Generation: 2,
-				},
-				Status: appsv1.DaemonSetStatus{
-					CurrentNumberScheduled: 3,
-					DesiredNumberScheduled: 3,
-					NumberAvailable:        3,
-					NumberMisscheduled:     0,
-					NumberReady:            3,
-					ObservedGeneration:     2,
-					UpdatedNumberScheduled: 3,
-				},
+			schedule: "0 3 * * 6",
+		},
+	} {
+		conf := &operv1.Network{}
+		conf.Annotations = tc.annotations
+		schedule, snapshot := bootstrapOVNDBMaintenance(conf)
+		g.Expect(schedule).To(Equal(tc.schedule), "%v", tc.annotations)
+		g.Expect(snapshot).To(Equal(tc.snapshot), "%v", tc.annotations)
+	}
+}
+
+func TestRenderOVNKubernetesDBMaintenance(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+
+	// no maintenance window, the databases compact themselves
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(findInObjs("batch", "CronJob", "ovnkube-db-maintenance", "openshift-ovn-kubernetes", objs)).To(BeNil())
+
+	bootstrapResult.OVN.OVNKubernetesConfig.DBMaintenanceSchedule = "0 3 * * 6"
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	obj := findInObjs("batch", "CronJob", "ovnkube-db-maintenance", "openshift-ovn-kubernetes", objs)
+	g.Expect(obj).NotTo(BeNil())
+	cronJob := &batchv1.CronJob{}
+	g.Expect(convert(obj, cronJob)).To(Succeed())
+	g.Expect(cronJob.Spec.Schedule).To(Equal("0 3 * * 6"))
+	g.Expect(*cronJob.Spec.JobTemplate.Spec.Completions).To(Equal(int32(3)))
+	g.Expect(*cronJob.Spec.JobTemplate.Spec.Parallelism).To(Equal(int32(3)))
+	podSpec := cronJob.Spec.JobTemplate.Spec.Template.Spec
+	g.Expect(podSpec.Containers[0].Command[2]).To(ContainSubstring("ovsdb-server/compact"))
+	g.Expect(podSpec.Containers[0].Command[2]).NotTo(ContainSubstring("snapshots"))
+	g.Expect(podSpec.Volumes).To(HaveLen(1))
+
+	bootstrapResult.OVN.OVNKubernetesConfig.DBMaintenanceSnapshot = true
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	obj = findInObjs("batch", "CronJob", "ovnkube-db-maintenance", "openshift-ovn-kubernetes", objs)
+	g.Expect(convert(obj, cronJob)).To(Succeed())
+	podSpec = cronJob.Spec.JobTemplate.Spec.Template.Spec
+	g.Expect(podSpec.Containers[0].Command[2]).To(ContainSubstring("ovsdb-server/memory-trim-on-compaction on"))
+	g.Expect(podSpec.Containers[0].Command[2]).To(ContainSubstring("/etc/ovn/snapshots"))
+	g.Expect(podSpec.Volumes).To(HaveLen(2))
+}
+
+func TestRenderOVNKubernetesNodeExclusion(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// the nodes labeled network.operator.openshift.io/exclude do not run ovnkube-node
+	ds := &appsv1.DaemonSet{}
+	g.Expect(convert(findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+	terms := ds.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	g.Expect(terms).To(HaveLen(1))
+	g.Expect(terms[0].MatchExpressions).To(ContainElement(v1.NodeSelectorRequirement{
+		Key:      "network.operator.openshift.io/exclude",
+		Operator: v1.NodeSelectorOpDoesNotExist,
+	}))
+}
+
+func TestBootstrapOVNResourceProfile(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	for _, tc := range []struct {
+		annotations map[string]string
+		profile     string
+	}{
+		{
+			annotations: nil,
+			profile:     OVN_RESOURCE_PROFILE_DEFAULT,
+		},
+		{
+			annotations: map[string]string{names.OVNResourceProfileAnnotation: "SingleNode"},
+			profile:     OVN_RESOURCE_PROFILE_SINGLE_NODE,
+		},
+		{
+			annotations: map[string]string{names.OVNResourceProfileAnnotation: "tiny"},
+			profile:     OVN_RESOURCE_PROFILE_DEFAULT,
+		},
+	} {
+		conf := &operv1.Network{}
+		conf.Annotations = tc.annotations
+		g.Expect(bootstrapOVNResourceProfile(conf)).To(Equal(tc.profile), "%v", tc.annotations)
+	}
+}
+
+func TestRenderOVNKubernetesSingleNodeProfile(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs: []string{"1.2.3.4"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{
+				ResourceProfile: OVN_RESOURCE_PROFILE_SINGLE_NODE,
 			},
-			expectNode:   false,
-			expectMaster: true,
-			ipFamilyMode: names.IPFamilyDualStack,
 		},
-	} {
+	}
+	containers := func(objs []*uns.Unstructured, name string) map[string]v1.Container {
+		ds := findInObjs("apps", "DaemonSet", name, "openshift-ovn-kubernetes", objs)
+		g.Expect(ds).NotTo(BeNil())
+		daemonSet := &appsv1.DaemonSet{}
+		convert(ds, daemonSet)
+		out := map[string]v1.Container{}
+		for _, container := range daemonSet.Spec.Template.Spec.Containers {
+			out[container.Name] = container
+		}
+		return out
+	}
+
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	master := containers(objs, "ovnkube-master")
+	g.Expect(master).NotTo(HaveKey("ovn-dbchecker"))
+	g.Expect(master["nbdb"].Command[2]).To(ContainSubstring("starting standalone nbdb"))
+	g.Expect(master["sbdb"].Command[2]).To(ContainSubstring("starting standalone sbdb"))
+	g.Expect(master["nbdb"].ReadinessProbe.Exec.Command).To(ContainElement("ovsdb-server/list-dbs"))
+	g.Expect(master["nbdb"].ReadinessProbe.PeriodSeconds).To(BeEquivalentTo(30))
+	g.Expect(master["ovnkube-master"].Resources.Requests[v1.ResourceMemory]).To(Equal(resource.MustParse("100Mi")))
+	node := containers(objs, "ovnkube-node")
+	g.Expect(node["ovnkube-node"].Resources.Requests[v1.ResourceMemory]).To(Equal(resource.MustParse("100Mi")))
+	g.Expect(node["ovnkube-node"].ReadinessProbe.PeriodSeconds).To(BeEquivalentTo(30))
+	g.Expect(bootstrapResult.Events).To(BeEmpty())
+	g.Expect(ovnSingleNodeInfrastructure(bootstrapResult)).To(BeTrue())
+
+	// the images are pre-pulled again once the single node is expanded with workers
+	bootstrapResult.Infra.InfrastructureTopology = configv1.HighlyAvailableTopologyMode
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(containers(objs, "ovnkube-master")["nbdb"].Command[2]).To(ContainSubstring("starting standalone nbdb"))
+	g.Expect(ovnSingleNodeInfrastructure(bootstrapResult)).To(BeFalse())
+
+	// the profile is ignored on clusters with several masters
+	bootstrapResult.OVN.MasterIPs = []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"}
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	master = containers(objs, "ovnkube-master")
+	g.Expect(master).To(HaveKey("ovn-dbchecker"))
+	g.Expect(master["nbdb"].Command[2]).NotTo(ContainSubstring("starting standalone nbdb"))
+	g.Expect(master["ovnkube-master"].Resources.Requests[v1.ResourceMemory]).To(Equal(resource.MustParse("300Mi")))
+	g.Expect(bootstrapResult.Events).To(HaveLen(1))
+	g.Expect(bootstrapResult.Events[0].Reason).To(Equal("ResourceProfileIgnored"))
+}
+
+func TestRenderOVNKubernetesNodeUpgradeMode(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	conf := &operv1.Network{}
+	conf.Annotations = map[string]string{names.OVNNodeUpgradeModeAnnotation: "Conservative"}
+	g.Expect(bootstrapOVNNodeUpgradeMode(conf)).To(Equal(OVN_NODE_UPGRADE_MODE_CONSERVATIVE))
+	conf.Annotations[names.OVNNodeUpgradeModeAnnotation] = "careful"
+	g.Expect(bootstrapOVNNodeUpgradeMode(conf)).To(Equal(OVN_NODE_UPGRADE_MODE_DEFAULT))
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+	updateStrategy := func() string {
+		objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+		g.Expect(err).NotTo(HaveOccurred())
+		node := findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs)
+		g.Expect(node).NotTo(BeNil())
+		strategy, _, err := uns.NestedString(node.Object, "spec", "updateStrategy", "type")
+		g.Expect(err).NotTo(HaveOccurred())
+		return strategy
+	}
+
+	g.Expect(updateStrategy()).To(Equal("RollingUpdate"))
+	bootstrapResult.OVN.OVNKubernetesConfig.NodeUpgradeMode = OVN_NODE_UPGRADE_MODE_CONSERVATIVE
+	g.Expect(updateStrategy()).To(Equal("OnDelete"))
+}
+
+func TestBootstrapOVNCrashForensics(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	for _, tc := range []struct {
+		annotation string
+		retention  int
+	}{
+		{annotation: "", retention: 0},
+		{annotation: "3", retention: 3},
+		{annotation: "10", retention: 10},
+		{annotation: "0", retention: 0},
+		{annotation: "11", retention: 0},
+		{annotation: "true", retention: 0},
+	} {
+		conf := &operv1.Network{}
+		if tc.annotation != "" {
+			conf.Annotations = map[string]string{names.OVNCrashForensicsAnnotation: tc.annotation}
+		}
+		g.Expect(bootstrapOVNCrashForensics(conf)).To(Equal(tc.retention), "%q", tc.annotation)
+	}
+}
+
+func TestRenderOVNKubernetesCrashForensics(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+
+	containerNames := func(objs []*uns.Unstructured) []string {
+		node := findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs)
+		g.Expect(node).NotTo(BeNil())
+		ds := &appsv1.DaemonSet{}
+		g.Expect(convert(node, ds)).To(Succeed())
+		containers := []string{}
+		for _, c := range ds.Spec.Template.Spec.Containers {
+			containers = append(containers, c.Name)
+		}
+		return containers
+	}
+
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(containerNames(objs)).NotTo(ContainElement("crash-forensics"))
+	g.Expect(findInObjs("rbac.authorization.k8s.io", "Role", "openshift-ovn-kubernetes-crash-forensics", "openshift-ovn-kubernetes", objs)).To(BeNil())
+
+	bootstrapResult.OVN.OVNKubernetesConfig.CrashForensicsRetention = 3
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(containerNames(objs)).To(ContainElement("crash-forensics"))
+	g.Expect(findInObjs("rbac.authorization.k8s.io", "Role", "openshift-ovn-kubernetes-crash-forensics", "openshift-ovn-kubernetes", objs)).NotTo(BeNil())
+	g.Expect(findInObjs("rbac.authorization.k8s.io", "RoleBinding", "openshift-ovn-kubernetes-crash-forensics", "openshift-ovn-kubernetes", objs)).NotTo(BeNil())
+
+	node := findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs)
+	ds := &appsv1.DaemonSet{}
+	g.Expect(convert(node, ds)).To(Succeed())
+	for _, c := range ds.Spec.Template.Spec.Containers {
+		if c.Name == "crash-forensics" {
+			g.Expect(c.Command[2]).To(ContainSubstring("RETENTION=3\n"))
+		}
+	}
+}
+
+func TestBootstrapOVSDatapathMetrics(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	for _, tc := range []struct {
+		annotation string
+		ceiling    int
+	}{
+		{annotation: "", ceiling: 0},
+		{annotation: "100000", ceiling: 100000},
+		{annotation: "200000", ceiling: 200000},
+		{annotation: "0", ceiling: 0},
+		{annotation: "200001", ceiling: 0},
+		{annotation: "true", ceiling: 0},
+	} {
+		conf := &operv1.Network{}
+		if tc.annotation != "" {
+			conf.Annotations = map[string]string{names.OVSDatapathMetricsAnnotation: tc.annotation}
+		}
+		g.Expect(bootstrapOVSDatapathMetrics(conf)).To(Equal(tc.ceiling), "%q", tc.annotation)
+	}
+}
+
+func TestRenderOVSDatapathMetrics(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+	ovnkubeNodeCommand := func(objs []*uns.Unstructured) string {
+		ds := &appsv1.DaemonSet{}
+		g.Expect(convert(findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+		c, ok := findContainer(ds.Spec.Template.Spec.Containers, "ovnkube-node")
+		g.Expect(ok).To(BeTrue())
+		return c.Command[2]
+	}
 
-		t.Run(tc.name, func(t *testing.T) {
-			updateNode, updateMaster := shouldUpdateOVNKonIPFamilyChange(tc.node, tc.master, tc.ipFamilyMode)
-			if updateNode != tc.expectNode {
-				t.Errorf("Expected node update: %v received %v", tc.expectNode, updateNode)
-			}
-			if updateMaster != tc.expectMaster {
-				t.Errorf("Expected node update: %v received %v", tc.expectNode, updateNode)
-			}
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ovnkubeNodeCommand(objs)).NotTo(ContainSubstring("--export-ovs-metrics"))
+	g.Expect(findInObjs("monitoring.coreos.com", "PrometheusRule", "ovs-datapath-rules", "openshift-ovn-kubernetes", objs)).To(BeNil())
 
-		})
+	bootstrapResult.OVN.OVNKubernetesConfig.DatapathFlowsCeiling = 150000
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ovnkubeNodeCommand(objs)).To(ContainSubstring("--export-ovs-metrics \\\n"))
+	rule := findInObjs("monitoring.coreos.com", "PrometheusRule", "ovs-datapath-rules", "openshift-ovn-kubernetes", objs)
+	g.Expect(rule).NotTo(BeNil())
+	groups, _, err := uns.NestedSlice(rule.Object, "spec", "groups")
+	g.Expect(err).NotTo(HaveOccurred())
+	rules := groups[0].(map[string]interface{})["rules"].([]interface{})
+	alerts := map[string]string{}
+	for _, r := range rules {
+		r := r.(map[string]interface{})
+		if alert, ok := r["alert"]; ok {
+			alerts[alert.(string)] = r["expr"].(string)
+		}
 	}
+	g.Expect(alerts).To(HaveKeyWithValue("OVSDatapathFlowsCeilingExceeded", "node:ovs_vswitchd_dp_flows:max > 150000\n"))
+	g.Expect(alerts).To(HaveKey("OVSDatapathMegaflowCacheHitRateLow"))
 
+	// the alerts can be force-disabled like the other components
+	bootstrapResult.OVN.OVNKubernetesConfig.DisabledComponents = []string{"datapath-metrics"}
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(findInObjs("monitoring.coreos.com", "PrometheusRule", "ovs-datapath-rules", "openshift-ovn-kubernetes", objs)).To(BeNil())
 }
 
-func TestRenderOVNKubernetesDualStackPrecedenceOverUpgrade(t *testing.T) {
-	//cluster was in single-stack and receives a converts to dual-stack
-	config := &operv1.NetworkSpec{
-		ServiceNetwork: []string{"172.30.0.0/16", "fd00:3:2:1::/112"},
-		ClusterNetwork: []operv1.ClusterNetworkEntry{
-			{
-				CIDR:       "10.128.0.0/15",
-				HostPrefix: 23,
+func TestBootstrapOVNDBClient(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	for _, tc := range []struct {
+		annotations      map[string]string
+		reconnectBackoff int
+		maxInflightTxns  int
+	}{
+		{
+			annotations: nil,
+		},
+		{
+			annotations: map[string]string{
+				names.OVNDBClientReconnectBackoffAnnotation: "30000",
+				names.OVNDBClientMaxInflightTxnsAnnotation:  "50",
 			},
-			{
-				CIDR:       "fd00:1:2:3::/64",
-				HostPrefix: 56,
+			reconnectBackoff: 30000,
+			maxInflightTxns:  50,
+		},
+		{
+			annotations: map[string]string{
+				names.OVNDBClientReconnectBackoffAnnotation: "999",
+				names.OVNDBClientMaxInflightTxnsAnnotation:  "1001",
 			},
 		},
-		DefaultNetwork: operv1.DefaultNetworkDefinition{
-			Type: operv1.NetworkTypeOVNKubernetes,
-			OVNKubernetesConfig: &operv1.OVNKubernetesConfig{
-				GenevePort: ptrToUint32(8061),
+		{
+			annotations: map[string]string{
+				names.OVNDBClientReconnectBackoffAnnotation: "30s",
+				names.OVNDBClientMaxInflightTxnsAnnotation:  "0",
 			},
 		},
+	} {
+		conf := &operv1.Network{}
+		conf.Annotations = tc.annotations
+		reconnectBackoff, maxInflightTxns := bootstrapOVNDBClient(conf)
+		g.Expect(reconnectBackoff).To(Equal(tc.reconnectBackoff), "%v", tc.annotations)
+		g.Expect(maxInflightTxns).To(Equal(tc.maxInflightTxns), "%v", tc.annotations)
 	}
-	errs := validateOVNKubernetes(config)
-	if len(errs) > 0 {
-		t.Errorf("Unexpected error: %v", errs)
-	}
-	FillDefaults(config, nil)
+}
 
-	// at the same time we have an upgrade
-	os.Setenv("RELEASE_VERSION", "2.0.0")
+func TestRenderOVNKubernetesDBClient(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
 
-	// bootstrap also represents current status
-	// the current cluster is single-stack and has version 1.9.9
 	bootstrapResult := &bootstrap.BootstrapResult{
 		OVN: bootstrap.OVNBootstrapResult{
-			MasterIPs: []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
-			ExistingMasterDaemonset: &appsv1.DaemonSet{
-				TypeMeta: metav1.TypeMeta{
-					APIVersion: "apps/v1",
-					Kind:       "DaemonSet",
-				},
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "ovnkube-master",
-					Namespace: "openshift-ovn-kubernetes",
-					Annotations: map[string]string{
-						names.NetworkIPFamilyModeAnnotation: names.IPFamilySingleStack,
-						"release.openshift.io/version":      "1.9.9",
-					},
-				},
-			},
-			ExistingNodeDaemonset: &appsv1.DaemonSet{
-				TypeMeta: metav1.TypeMeta{
-					APIVersion: "apps/v1",
-					Kind:       "DaemonSet",
-				},
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "ovnkube-node",
-					Namespace: "openshift-ovn-kubernetes",
-					Annotations: map[string]string{
-						names.NetworkIPFamilyModeAnnotation: names.IPFamilySingleStack,
-						"release.openshift.io/version":      "1.9.9",
-					},
-				},
-			},
-			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{
-				NodeMode: "full",
-			},
+			MasterIPs:           []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
 		},
 	}
-	usNode, err := k8s.ToUnstructured(bootstrapResult.OVN.ExistingNodeDaemonset)
-	if err != nil {
-		t.Errorf("Unexpected error: %v", err)
-	}
-	usMaster, err := k8s.ToUnstructured(bootstrapResult.OVN.ExistingMasterDaemonset)
-	if err != nil {
-		t.Errorf("Unexpected error: %v", err)
+
+	ovnkubeMasterScript := func(objs []*uns.Unstructured) string {
+		master := findInObjs("apps", "DaemonSet", "ovnkube-master", "openshift-ovn-kubernetes", objs)
+		g.Expect(master).NotTo(BeNil())
+		ds := &appsv1.DaemonSet{}
+		g.Expect(convert(master, ds)).To(Succeed())
+		for _, c := range ds.Spec.Template.Spec.Containers {
+			if c.Name == "ovnkube-master" {
+				return c.Command[2]
+			}
+		}
+		return ""
 	}
 
-	// the new rendered config should hold the node to do the dualstack conversion
-	// the upgrade code holds the masters to update the nodes first
+	// the ovn-kubernetes defaults are kept
 	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
-	if err != nil {
-		t.Errorf("Unexpected error: %v", err)
-	}
-	renderedNode := findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs)
-	renderedMaster := findInObjs("apps", "DaemonSet", "ovnkube-master", "openshift-ovn-kubernetes", objs)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ovnkubeMasterScript(objs)).NotTo(ContainSubstring("reconnect-max-backoff"))
+	g.Expect(ovnkubeMasterScript(objs)).NotTo(ContainSubstring("max-inflight-txns"))
 
-	// the node has to be the same
-	if !reflect.DeepEqual(usNode, renderedNode) {
-		t.Errorf("node daemonset are not equal, dual-stack should upgrade masters first %+v", renderedNode)
-	}
-	// the master has to use the new annotations for dual-stack so it has to be mutated
-	if reflect.DeepEqual(usMaster, renderedMaster) {
-		t.Errorf("master daemonset are equal, dual-stack should modify masters")
-	}
+	bootstrapResult.OVN.OVNKubernetesConfig.DBClientReconnectBackoff = 30000
+	bootstrapResult.OVN.OVNKubernetesConfig.DBClientMaxInflightTxns = 50
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	script := ovnkubeMasterScript(objs)
+	g.Expect(script).To(ContainSubstring("--nb-reconnect-max-backoff \"30000\" \\\n"))
+	g.Expect(script).To(ContainSubstring("--sb-reconnect-max-backoff \"30000\" \\\n"))
+	g.Expect(script).To(ContainSubstring("--nb-max-inflight-txns \"50\" \\\n"))
+	g.Expect(script).To(ContainSubstring("--sb-max-inflight-txns \"50\" \\\n"))
 }
 
-func TestRenderOVNKubernetesOVSFlowsConfigMap(t *testing.T) {
-	config := &operv1.NetworkSpec{
-		ServiceNetwork: []string{"172.30.0.0/16"},
-		ClusterNetwork: []operv1.ClusterNetworkEntry{
-			{CIDR: "10.128.0.0/15", HostPrefix: 23},
+func TestBootstrapOVNEgressFirewallDNS(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	for _, tc := range []struct {
+		annotations     map[string]string
+		refreshInterval int
+		resolver        string
+	}{
+		{
+			annotations: nil,
 		},
-		DefaultNetwork: operv1.DefaultNetworkDefinition{
-			Type: operv1.NetworkTypeOVNKubernetes,
-			OVNKubernetesConfig: &operv1.OVNKubernetesConfig{
-				GenevePort:        ptrToUint32(8061),
-				PolicyAuditConfig: &operv1.PolicyAuditConfig{},
+		{
+			annotations: map[string]string{
+				names.OVNEgressFirewallDNSRefreshIntervalAnnotation: "300",
+				names.OVNEgressFirewallDNSResolverAnnotation:        "10.0.0.10",
 			},
+			refreshInterval: 300,
+			resolver:        "10.0.0.10:53",
 		},
-		DisableMultiNetwork: boolPtr(true),
-	}
-	testCases := []struct {
-		Description string
-		FlowsConfig *bootstrap.FlowsConfig
-		Expected    []v1.EnvVar
-		NotExpected []string
-	}{
 		{
-			Description: "No detected OVN flows config",
-			NotExpected: []string{"IPFIX_COLLECTORS", "IPFIX_CACHE_MAX_FLOWS",
-				"IPFIX_CACHE_ACTIVE_TIMEOUT", "IPFIX_SAMPLING"},
+			annotations: map[string]string{
+				names.OVNEgressFirewallDNSResolverAnnotation: "10.0.0.10:5353",
+			},
+			resolver: "10.0.0.10:5353",
 		},
 		{
-			Description: "Only target is specified",
-			FlowsConfig: &bootstrap.FlowsConfig{
-				Target: "1.2.3.4:567",
+			annotations: map[string]string{
+				names.OVNEgressFirewallDNSResolverAnnotation: "fd00::10",
 			},
-			Expected: []v1.EnvVar{{Name: "IPFIX_COLLECTORS", Value: "1.2.3.4:567"}},
-			NotExpected: []string{"IPFIX_CACHE_MAX_FLOWS",
-				"IPFIX_CACHE_ACTIVE_TIMEOUT", "IPFIX_SAMPLING"},
+			resolver: "[fd00::10]:53",
 		},
 		{
-			Description: "IPFIX performance variables are specified",
-			FlowsConfig: &bootstrap.FlowsConfig{
-				Target:             "7.8.9.10:1112",
-				CacheMaxFlows:      uintPtr(123),
-				CacheActiveTimeout: uintPtr(456),
-				Sampling:           uintPtr(789),
+			annotations: map[string]string{
+				names.OVNEgressFirewallDNSResolverAnnotation: "[fd00::10]:5353",
 			},
-			Expected: []v1.EnvVar{
-				{Name: "IPFIX_COLLECTORS", Value: "7.8.9.10:1112"},
-				{Name: "IPFIX_CACHE_MAX_FLOWS", Value: "123"},
-				{Name: "IPFIX_CACHE_ACTIVE_TIMEOUT", Value: "456"},
-				{Name: "IPFIX_SAMPLING", Value: "789"},
+			resolver: "[fd00::10]:5353",
+		},
+		{
+			annotations: map[string]string{
+				names.OVNEgressFirewallDNSRefreshIntervalAnnotation: "4",
+				names.OVNEgressFirewallDNSResolverAnnotation:        "dns.example.com",
 			},
 		},
 		{
-			Description: "Wrong configuration: target missing but performance variables present",
-			FlowsConfig: &bootstrap.FlowsConfig{
-				CacheMaxFlows:      uintPtr(123),
-				CacheActiveTimeout: uintPtr(456),
-				Sampling:           uintPtr(789),
+			annotations: map[string]string{
+				names.OVNEgressFirewallDNSRefreshIntervalAnnotation: "5m",
+				names.OVNEgressFirewallDNSResolverAnnotation:        "10.0.0.10:0",
 			},
-			NotExpected: []string{"IPFIX_COLLECTORS", "IPFIX_CACHE_MAX_FLOWS",
-				"IPFIX_CACHE_ACTIVE_TIMEOUT", "IPFIX_SAMPLING"},
 		},
+	} {
+		conf := &operv1.Network{}
+		conf.Annotations = tc.annotations
+		refreshInterval, resolver := bootstrapOVNEgressFirewallDNS(conf)
+		g.Expect(refreshInterval).To(Equal(tc.refreshInterval), "%v", tc.annotations)
+		g.Expect(resolver).To(Equal(tc.resolver), "%v", tc.annotations)
 	}
-	for _, tc := range testCases {
-		t.Run(tc.Description, func(t *testing.T) {
-			RegisterTestingT(t)
-			g := NewGomegaWithT(t)
-			bootstrapResult := &bootstrap.BootstrapResult{
-				OVN: bootstrap.OVNBootstrapResult{
-					MasterIPs: []string{"1.2.3.4"},
-					OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{
-						GatewayMode: "shared",
-					},
-					FlowsConfig: tc.FlowsConfig,
-				},
-			}
-			objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
-			g.Expect(err).ToNot(HaveOccurred())
-			nodeDS := findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs)
-			ds := appsv1.DaemonSet{}
-			g.Expect(convert(nodeDS, &ds)).To(Succeed())
-			nodeCont, ok := findContainer(ds.Spec.Template.Spec.Containers, "ovnkube-node")
-			g.Expect(ok).To(BeTrue(), "expecting container named ovnkube-node in the DaemonSet")
-			g.Expect(nodeCont.Env).To(ContainElements(tc.Expected))
-			for _, ev := range nodeCont.Env {
-				Expect(tc.NotExpected).ToNot(ContainElement(ev.Name))
-			}
-		})
+}
+
+func TestRenderOVNKubernetesEgressFirewallDNS(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+
+	ovnkubeMasterScript := func() string {
+		objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+		g.Expect(err).NotTo(HaveOccurred())
+		ds := &appsv1.DaemonSet{}
+		g.Expect(convert(findInObjs("apps", "DaemonSet", "ovnkube-master", "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+		c, ok := findContainer(ds.Spec.Template.Spec.Containers, "ovnkube-master")
+		g.Expect(ok).To(BeTrue())
+		return c.Command[2]
+	}
+
+	// the ovn-kubernetes defaults are kept
+	g.Expect(ovnkubeMasterScript()).NotTo(ContainSubstring("egressfirewall-dns"))
+
+	bootstrapResult.OVN.OVNKubernetesConfig.EgressFirewallDNSRefreshInterval = 300
+	bootstrapResult.OVN.OVNKubernetesConfig.EgressFirewallDNSResolver = "[fd00::10]:53"
+	script := ovnkubeMasterScript()
+	g.Expect(script).To(ContainSubstring("--egressfirewall-dns-refresh-interval \"300\" \\\n"))
+	g.Expect(script).To(ContainSubstring("--egressfirewall-dns-resolver \"[fd00::10]:53\" \\\n"))
+}
+
+func TestRenderOVNKubernetesDBEndpoints(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	cm := &v1.ConfigMap{}
+	g.Expect(convert(findInObjs("", "ConfigMap", "ovnkube-db-endpoints", "openshift-ovn-kubernetes", objs), cm)).To(Succeed())
+	g.Expect(cm.Data).To(Equal(map[string]string{
+		"nb": "ssl:1.2.3.4:9641,ssl:5.6.7.8:9641,ssl:9.10.11.12:9641",
+		"sb": "ssl:1.2.3.4:9642,ssl:5.6.7.8:9642,ssl:9.10.11.12:9642",
+	}))
+	node := findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs)
+	g.Expect(node).NotTo(BeNil())
+	g.Expect(bootstrapResult.Events).To(BeEmpty())
+
+	// a renumbered master only changes the ConfigMap, ovnkube-node is not rolled out
+	bootstrapResult.OVN.MasterIPs = []string{"1.2.3.4", "5.6.7.8", "9.10.11.99"}
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(convert(findInObjs("", "ConfigMap", "ovnkube-db-endpoints", "openshift-ovn-kubernetes", objs), cm)).To(Succeed())
+	g.Expect(cm.Data["sb"]).To(Equal("ssl:1.2.3.4:9642,ssl:5.6.7.8:9642,ssl:9.10.11.99:9642"))
+	renumbered := findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs)
+	g.Expect(renumbered).NotTo(BeNil())
+	g.Expect(renumbered.Object["spec"]).To(Equal(node.Object["spec"]))
+
+	// the nodes reach the databases on the DNS name, whatever the master IPs
+	bootstrapResult.OVN.OVNKubernetesConfig.DBEndpointName = "ovn-db.example.com"
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(convert(findInObjs("", "ConfigMap", "ovnkube-db-endpoints", "openshift-ovn-kubernetes", objs), cm)).To(Succeed())
+	g.Expect(cm.Data).To(Equal(map[string]string{
+		"nb": "ssl:ovn-db.example.com:9641",
+		"sb": "ssl:ovn-db.example.com:9642",
+	}))
+}
+
+func TestBootstrapOVNDBEndpointName(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	g.Expect(bootstrapOVNDBEndpointName(crd)).To(BeEmpty())
+
+	for name, expected := range map[string]string{
+		"ovn-db.example.com": "ovn-db.example.com",
+		"ovn-db":             "",
+		"ovn_db.example.com": "",
+	} {
+		crd.Annotations = map[string]string{names.OVNDBEndpointNameAnnotation: name}
+		g.Expect(bootstrapOVNDBEndpointName(crd)).To(Equal(expected), name)
+	}
+}
+
+func TestRecordOVNDBEndpointsEvent(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:         []string{"1.2.3.4", "5.6.7.8", "9.10.11.99"},
+			PreviousMasterIPs: []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+		},
+	}
+	// nothing to update on the first install
+	recordOVNDBEndpointsEvent(bootstrapResult)
+	g.Expect(bootstrapResult.Events).To(BeEmpty())
+
+	bootstrapResult.OVN.ExistingNodeDaemonset = &appsv1.DaemonSet{}
+	recordOVNDBEndpointsEvent(bootstrapResult)
+	g.Expect(bootstrapResult.Events).To(Equal([]bootstrap.Event{{
+		Type:    v1.EventTypeNormal,
+		Reason:  "OVNDBEndpointsChanged",
+		Message: "The master IPs changed from [1.2.3.4 5.6.7.8 9.10.11.12] to [1.2.3.4 5.6.7.8 9.10.11.99], updating the OVN database endpoints of the nodes in place",
+	}}))
+
+	bootstrapResult.Events = nil
+	bootstrapResult.OVN.PreviousMasterIPs = bootstrapResult.OVN.MasterIPs
+	recordOVNDBEndpointsEvent(bootstrapResult)
+	g.Expect(bootstrapResult.Events).To(BeEmpty())
+
+	// the nodes do not depend on the master IPs when they reach the databases on a DNS name
+	bootstrapResult.OVN.PreviousMasterIPs = []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"}
+	bootstrapResult.OVN.OVNKubernetesConfig = &bootstrap.OVNConfigBoostrapResult{DBEndpointName: "ovn-db.example.com"}
+	recordOVNDBEndpointsEvent(bootstrapResult)
+	g.Expect(bootstrapResult.Events).To(BeEmpty())
+}
+
+func TestBootstrapOVNNodePortRange(t *testing.T) {
+	g := NewGomegaWithT(t)
+	g.Expect(configv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	g.Expect(bootstrapOVNNodePortRange(fake.NewClientBuilder().Build())).To(Equal(OVN_NODE_PORT_RANGE))
+
+	for portRange, expected := range map[string]string{
+		"":            OVN_NODE_PORT_RANGE,
+		"20000-22767": "20000-22767",
+		"20000":       OVN_NODE_PORT_RANGE,
+	} {
+		client := fake.NewClientBuilder().WithObjects(&configv1.Network{
+			ObjectMeta: metav1.ObjectMeta{Name: names.CLUSTER_CONFIG},
+			Spec:       configv1.NetworkSpec{ServiceNodePortRange: portRange},
+		}).Build()
+		g.Expect(bootstrapOVNNodePortRange(client)).To(Equal(expected), portRange)
 	}
 }
 
-func TestBootStrapOvsConfigMap_SharedTarget(t *testing.T) {
-	fc := bootstrapFlowsConfig(&fakeClientReader{
-		configMap: &v1.ConfigMap{
-			Data: map[string]string{
-				"sharedTarget":       "1.2.3.4:3030",
-				"cacheActiveTimeout": "3200ms",
-				"cacheMaxFlows":      "33",
-				"sampling":           "55",
-			},
-		},
-	})
+func TestRenderOVNKubernetesLoadBalancerHealthChecks(t *testing.T) {
+	g := NewGomegaWithT(t)
 
-	assert.Equal(t, "1.2.3.4:3030", fc.Target)
-	// verify that the 200ms get truncated
-	assert.EqualValues(t, 3, *fc.CacheActiveTimeout)
-	assert.EqualValues(t, 33, *fc.CacheMaxFlows)
-	assert.EqualValues(t, 55, *fc.Sampling)
-}
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
 
-func TestBootStrapOvsConfigMap_NodePort(t *testing.T) {
-	fc := bootstrapFlowsConfig(&fakeClientReader{
-		configMap: &v1.ConfigMap{
-			Data: map[string]string{
-				"nodePort":           "3131",
-				"cacheActiveTimeout": "invalid timeout",
-				"cacheMaxFlows":      "invalid int",
+	bootstrapResult := &bootstrap.BootstrapResult{
+		Infra: bootstrap.InfraBootstrapResult{
+			PlatformType:                   configv1.GCPPlatformType,
+			LoadBalancerHealthCheckSources: []string{"35.191.0.0/16", "130.211.0.0/22"},
+		},
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs: []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{
+				NodePortRange: OVN_NODE_PORT_RANGE,
 			},
 		},
-	})
+	}
 
-	assert.Equal(t, ":3131", fc.Target)
-	// verify that invalid or unspecified fields are ignored
-	assert.Nil(t, fc.CacheActiveTimeout)
-	assert.Nil(t, fc.CacheMaxFlows)
-	assert.Nil(t, fc.Sampling)
+	nodeScript := func() string {
+		objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+		g.Expect(err).NotTo(HaveOccurred())
+		ds := &appsv1.DaemonSet{}
+		g.Expect(convert(findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+		for _, c := range ds.Spec.Template.Spec.Containers {
+			if c.Name == "ovnkube-node" {
+				return c.Command[2]
+			}
+		}
+		return ""
+	}
+
+	script := nodeScript()
+	g.Expect(script).To(ContainSubstring("for source in 35.191.0.0/16 130.211.0.0/22; do"))
+	g.Expect(script).To(ContainSubstring(`-s "${source}" --dport 30000:32767 -j ACCEPT`))
+
+	// the health checks are not let through in local gateway mode
+	config.DefaultNetwork.OVNKubernetesConfig.GatewayConfig = &operv1.GatewayConfig{RoutingViaHost: true}
+	script = nodeScript()
+	g.Expect(script).NotTo(ContainSubstring("iptables -A OVN-KUBE-LB-HEALTHCHECK"))
+	g.Expect(script).To(ContainSubstring("iptables -X OVN-KUBE-LB-HEALTHCHECK"))
+
+	// nor on the platforms without load balancer health check sources
+	config.DefaultNetwork.OVNKubernetesConfig.GatewayConfig = nil
+	bootstrapResult.Infra = bootstrap.InfraBootstrapResult{PlatformType: configv1.AWSPlatformType}
+	g.Expect(nodeScript()).NotTo(ContainSubstring("iptables -A OVN-KUBE-LB-HEALTHCHECK"))
 }
 
-func TestBootStrapOvsConfigMap_IncompleteMap(t *testing.T) {
-	fc := bootstrapFlowsConfig(&fakeClientReader{
-		configMap: &v1.ConfigMap{
-			Data: map[string]string{
-				"cacheActiveTimeout": "3200ms",
-				"cacheMaxFlows":      "33",
-				"sampling":           "55",
-			},
+func TestRenderOVNKubernetesNodeLocalDNS(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
 		},
-	})
+	}
 
-	// without sharedTarget nor nodePort, flow collection can't be set
-	assert.Nil(t, fc)
-}
+	rendered := func() (string, string) {
+		objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+		g.Expect(err).NotTo(HaveOccurred())
+		cm := &v1.ConfigMap{}
+		g.Expect(convert(findInObjs("", "ConfigMap", "ovnkube-config", "openshift-ovn-kubernetes", objs), cm)).To(Succeed())
+		ds := &appsv1.DaemonSet{}
+		g.Expect(convert(findInObjs("apps", "DaemonSet", "ovnkube-node", "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+		c, ok := findContainer(ds.Spec.Template.Spec.Containers, "ovnkube-node")
+		g.Expect(ok).To(BeTrue())
+		return cm.Data["ovnkube.conf"], c.Command[2]
+	}
 
-func TestBootStrapOvsConfigMap_UnexistingMap(t *testing.T) {
-	fc := bootstrapFlowsConfig(&fakeClientReader{configMap: nil})
+	conf, script := rendered()
+	g.Expect(conf).NotTo(ContainSubstring("no-snat-destinations"))
+	g.Expect(script).NotTo(ContainSubstring("iptables -A OVN-KUBE-NODE-LOCAL-DNS"))
+	g.Expect(script).To(ContainSubstring("iptables -X OVN-KUBE-NODE-LOCAL-DNS"))
 
-	// without sharedTarget nor nodePort, flow collection can't be set
-	assert.Nil(t, fc)
+	bootstrapResult.OVN.OVNKubernetesConfig.NodeLocalDNSIP = "169.254.20.10"
+	conf, script = rendered()
+	g.Expect(conf).To(ContainSubstring("no-snat-destinations=\"169.254.20.10/32\""))
+	g.Expect(script).To(ContainSubstring(`iptables -A OVN-KUBE-NODE-LOCAL-DNS -p "${proto}" -d 169.254.20.10 --dport 53 -j ACCEPT`))
+	g.Expect(script).NotTo(ContainSubstring("iptables -X OVN-KUBE-NODE-LOCAL-DNS"))
 }
 
-type fakeClientReader struct {
-	configMap *v1.ConfigMap
-}
+func TestBootstrapOVNPerformance(t *testing.T) {
+	g := NewGomegaWithT(t)
 
-func (f *fakeClientReader) Get(_ context.Context, _ client.ObjectKey, obj client.Object) error {
-	if cmPtr, ok := obj.(*v1.ConfigMap); !ok {
-		return fmt.Errorf("expecting *v1.ConfigMap, got %T", obj)
-	} else if f.configMap == nil {
-		return &kapierrors.StatusError{ErrStatus: metav1.Status{
-			Reason: metav1.StatusReasonNotFound,
-		}}
-	} else {
-		*cmPtr = *f.configMap
+	for _, tc := range []struct {
+		annotations     map[string]string
+		ovsdbMode       string
+		txnBatchSize    int
+		lflowCacheLimit int
+	}{
+		{
+			annotations: nil,
+			ovsdbMode:   OVN_OVSDB_MODE_LEGACY,
+		},
+		{
+			annotations: map[string]string{
+				names.OVNOVSDBModeAnnotation:       "libovsdb",
+				names.OVNTxnBatchSizeAnnotation:    "200",
+				names.OVNLflowCacheLimitAnnotation: "500000",
+			},
+			ovsdbMode:       OVN_OVSDB_MODE_LIBOVSDB,
+			txnBatchSize:    200,
+			lflowCacheLimit: 500000,
+		},
+		{
+			annotations: map[string]string{
+				names.OVNOVSDBModeAnnotation:       "native",
+				names.OVNTxnBatchSizeAnnotation:    "0",
+				names.OVNLflowCacheLimitAnnotation: "10000001",
+			},
+			ovsdbMode: OVN_OVSDB_MODE_LEGACY,
+		},
+	} {
+		conf := &operv1.Network{}
+		conf.Annotations = tc.annotations
+		ovsdbMode, txnBatchSize, lflowCacheLimit := bootstrapOVNPerformance(conf)
+		g.Expect(ovsdbMode).To(Equal(tc.ovsdbMode), "%v", tc.annotations)
+		g.Expect(txnBatchSize).To(Equal(tc.txnBatchSize), "%v", tc.annotations)
+		g.Expect(lflowCacheLimit).To(Equal(tc.lflowCacheLimit), "%v", tc.annotations)
 	}
-	return nil
 }
 
-func (f *fakeClientReader) List(_ context.Context, _ client.ObjectList, _ ...client.ListOption) error {
-	return errors.New("unexpected invocation to List")
-}
+func TestRenderOVNKubernetesPerformance(t *testing.T) {
+	g := NewGomegaWithT(t)
 
-func findContainer(conts []v1.Container, name string) (v1.Container, bool) {
-	for _, cont := range conts {
-		if cont.Name == name {
-			return cont, true
-		}
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs: []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{
+				OVSDBMode: OVN_OVSDB_MODE_LEGACY,
+			},
+		},
 	}
-	return v1.Container{}, false
-}
 
-func convert(src *uns.Unstructured, dst metav1.Object) error {
-	j, err := src.MarshalJSON()
-	if err != nil {
-		return err
+	containers := func(objs []*uns.Unstructured, name string) map[string]v1.Container {
+		ds := &appsv1.DaemonSet{}
+		g.Expect(convert(findInObjs("apps", "DaemonSet", name, "openshift-ovn-kubernetes", objs), ds)).To(Succeed())
+		containers := map[string]v1.Container{}
+		for _, c := range ds.Spec.Template.Spec.Containers {
+			containers[c.Name] = c
+		}
+		return containers
 	}
-	return json.Unmarshal(j, dst)
+
+	// the nbctl daemon is used by default
+	objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	master := containers(objs, "ovnkube-master")["ovnkube-master"]
+	g.Expect(master.Command[2]).To(ContainSubstring("--nbctl-daemon-mode"))
+	g.Expect(master.Command[2]).To(ContainSubstring("export OVN_NB_DAEMON="))
+	g.Expect(master.Command[2]).NotTo(ContainSubstring("--ovsdb-txn-batch-size"))
+	g.Expect(master.Lifecycle).NotTo(BeNil())
+	g.Expect(containers(objs, "ovnkube-node")["ovn-controller"].Command[2]).To(
+		ContainSubstring("remove Open_vSwitch . external_ids ovn-limit-lflow-cache"))
+
+	bootstrapResult.OVN.OVNKubernetesConfig.OVSDBMode = OVN_OVSDB_MODE_LIBOVSDB
+	bootstrapResult.OVN.OVNKubernetesConfig.TxnBatchSize = 200
+	bootstrapResult.OVN.OVNKubernetesConfig.LflowCacheLimit = 500000
+	objs, err = renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+	g.Expect(err).NotTo(HaveOccurred())
+	master = containers(objs, "ovnkube-master")["ovnkube-master"]
+	g.Expect(master.Command[2]).NotTo(ContainSubstring("--nbctl-daemon-mode"))
+	g.Expect(master.Command[2]).NotTo(ContainSubstring("export OVN_NB_DAEMON="))
+	g.Expect(master.Command[2]).To(ContainSubstring("--ovsdb-txn-batch-size \"200\" \\\n"))
+	g.Expect(master.Lifecycle).To(BeNil())
+	g.Expect(containers(objs, "ovnkube-node")["ovn-controller"].Command[2]).To(
+		ContainSubstring(`set Open_vSwitch . external_ids:ovn-limit-lflow-cache="500000"`))
 }
 
-func findInObjs(group, kind, name, namespace string, objs []*uns.Unstructured) *uns.Unstructured {
-	for _, obj := range objs {
-		if (obj.GroupVersionKind().GroupKind() == schema.GroupKind{Group: group, Kind: kind} &&
-			obj.GetNamespace() == namespace &&
-			obj.GetName() == name) {
-			return obj
+func TestBootstrapOVNMasqueradeSubnets(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	for _, tc := range []struct {
+		annotations map[string]string
+		v4Subnet    string
+		v6Subnet    string
+	}{
+		{
+			annotations: nil,
+		},
+		{
+			annotations: map[string]string{
+				names.OVNV4MasqueradeSubnetAnnotation: "169.254.0.0/28",
+				names.OVNV6MasqueradeSubnetAnnotation: "fd99::1/120",
+			},
+			v4Subnet: "169.254.0.0/28",
+			v6Subnet: "fd99::/120",
+		},
+		{
+			// the wrong family, and too small
+			annotations: map[string]string{
+				names.OVNV4MasqueradeSubnetAnnotation: "fd99::/120",
+				names.OVNV6MasqueradeSubnetAnnotation: "fd99::/126",
+			},
+		},
+		{
+			// overlapping the machine network and the join subnet
+			annotations: map[string]string{
+				names.OVNV4MasqueradeSubnetAnnotation: "10.0.0.0/24",
+				names.OVNV6MasqueradeSubnetAnnotation: "fd98::/120",
+			},
+		},
+		{
+			// overlapping the cluster and service networks
+			annotations: map[string]string{
+				names.OVNV4MasqueradeSubnetAnnotation: "10.128.0.0/29",
+				names.OVNV6MasqueradeSubnetAnnotation: "fd02::/125",
+			},
+		},
+		{
+			annotations: map[string]string{
+				names.OVNV4MasqueradeSubnetAnnotation: "169.254.169.0",
+			},
+		},
+	} {
+		conf := &operv1.Network{
+			Spec: operv1.NetworkSpec{
+				ClusterNetwork: []operv1.ClusterNetworkEntry{
+					{CIDR: "10.128.0.0/14", HostPrefix: 23},
+					{CIDR: "fd01::/48", HostPrefix: 64},
+				},
+				ServiceNetwork: []string{"172.30.0.0/16", "fd02::/112"},
+			},
 		}
+		conf.Annotations = tc.annotations
+		v4Subnet, v6Subnet := bootstrapOVNMasqueradeSubnets(conf, ovnNetworksInUse(conf, []string{"10.0.0.0/16"}), "", "")
+		g.Expect(v4Subnet).To(Equal(tc.v4Subnet), "%v", tc.annotations)
+		g.Expect(v6Subnet).To(Equal(tc.v6Subnet), "%v", tc.annotations)
 	}
-	return nil
 }
 
-func extractOVNKubeConfig(g *WithT, objs []*uns.Unstructured) string {
-	for _, obj := range objs {
-		if obj.GetKind() == "ConfigMap" && obj.GetName() == "ovnkube-config" {
-			val, ok, err := uns.NestedString(obj.Object, "data", "ovnkube.conf")
-			g.Expect(err).NotTo(HaveOccurred())
-			g.Expect(ok).To(BeTrue())
-			return string(val)
+func TestBootstrapOVNNodeLocalDNS(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	for _, tc := range []struct {
+		annotation         string
+		v4MasqueradeSubnet string
+		ip                 string
+	}{
+		{annotation: "", ip: ""},
+		{annotation: "true", ip: "169.254.20.10"},
+		{annotation: "false", ip: ""},
+		{annotation: "169.254.25.10", ip: "169.254.25.10"},
+		// not link-local
+		{annotation: "10.96.0.10", ip: ""},
+		{annotation: "fe80::a", ip: ""},
+		{annotation: "dns", ip: ""},
+		// in the masquerade subnet
+		{annotation: "169.254.169.2", ip: ""},
+		{annotation: "169.254.169.2", v4MasqueradeSubnet: "169.254.0.0/29", ip: "169.254.169.2"},
+		{annotation: "169.254.0.3", v4MasqueradeSubnet: "169.254.0.0/29", ip: ""},
+	} {
+		conf := &operv1.Network{}
+		if tc.annotation != "" {
+			conf.Annotations = map[string]string{names.OVNNodeLocalDNSAnnotation: tc.annotation}
 		}
+		g.Expect(bootstrapOVNNodeLocalDNS(conf, tc.v4MasqueradeSubnet)).To(Equal(tc.ip), "%q in %q", tc.annotation, tc.v4MasqueradeSubnet)
 	}
-	return ""
 }
 
-// checkDaemonsetAnnotation check that all the daemonset have the annotation with the
-// same key and value
-func checkDaemonsetAnnotation(g *WithT, objs []*uns.Unstructured, key, value string) bool {
-	if key == "" || value == "" {
-		return false
-	}
-	foundMaster, foundNode := false, false
-	for _, obj := range objs {
-		if obj.GetAPIVersion() == "apps/v1" && obj.GetKind() == "DaemonSet" &&
-			(obj.GetName() == "ovnkube-master" || obj.GetName() == "ovnkube-node") {
+func TestBootstrapOVNJoinSubnets(t *testing.T) {
+	g := NewGomegaWithT(t)
 
-			// check daemonset annotation
-			anno := obj.GetAnnotations()
-			if anno == nil {
-				return false
-			}
-			v, ok := anno[key]
-			if !ok || v != value {
-				return false
-			}
-			// check template annotation
-			anno, _, _ = uns.NestedStringMap(obj.Object, "spec", "template", "metadata", "annotations")
-			if anno == nil {
-				return false
-			}
-			v, ok = anno[key]
-			if !ok || v != value {
-				return false
-			}
-			// record the daemonsets we have checked
-			if obj.GetName() == "ovnkube-master" {
-				foundMaster = true
-			} else {
-				foundNode = true
-			}
+	for _, tc := range []struct {
+		annotations map[string]string
+		v4Subnet    string
+		v6Subnet    string
+	}{
+		{
+			annotations: nil,
+		},
+		{
+			annotations: map[string]string{
+				names.OVNV4InternalSubnetAnnotation: "100.65.0.0/16",
+				names.OVNV6InternalSubnetAnnotation: "fd97::/64",
+			},
+			v4Subnet: "100.65.0.0/16",
+			v6Subnet: "fd97::/64",
+		},
+		{
+			// too small
+			annotations: map[string]string{
+				names.OVNV4InternalSubnetAnnotation: "100.65.0.0/25",
+				names.OVNV6InternalSubnetAnnotation: "fd97::/121",
+			},
+		},
+		{
+			// overlapping the hybrid overlay network and the default masquerade subnet
+			annotations: map[string]string{
+				names.OVNV4InternalSubnetAnnotation: "10.132.0.0/16",
+				names.OVNV6InternalSubnetAnnotation: "fd69::/64",
+			},
+		},
+		{
+			// overlapping the service and machine networks
+			annotations: map[string]string{
+				names.OVNV4InternalSubnetAnnotation: "172.30.0.0/24",
+				names.OVNV6InternalSubnetAnnotation: "fd03::/64",
+			},
+		},
+	} {
+		conf := &operv1.Network{
+			Spec: operv1.NetworkSpec{
+				ClusterNetwork: []operv1.ClusterNetworkEntry{
+					{CIDR: "10.128.0.0/14", HostPrefix: 23},
+					{CIDR: "fd01::/48", HostPrefix: 64},
+				},
+				ServiceNetwork: []string{"172.30.0.0/16", "fd02::/112"},
+				DefaultNetwork: operv1.DefaultNetworkDefinition{
+					Type: operv1.NetworkTypeOVNKubernetes,
+					OVNKubernetesConfig: &operv1.OVNKubernetesConfig{
+						HybridOverlayConfig: &operv1.HybridOverlayConfig{
+							HybridClusterNetwork: []operv1.ClusterNetworkEntry{{CIDR: "10.132.0.0/14", HostPrefix: 23}},
+						},
+					},
+				},
+			},
 		}
+		conf.Annotations = tc.annotations
+		v4Subnet, v6Subnet := bootstrapOVNJoinSubnets(conf, ovnNetworksInUse(conf, []string{"10.0.0.0/16", "fd03::/48"}))
+		g.Expect(v4Subnet).To(Equal(tc.v4Subnet), "%v", tc.annotations)
+		g.Expect(v6Subnet).To(Equal(tc.v6Subnet), "%v", tc.annotations)
 	}
-	return foundMaster && foundNode
-}
 
-func ptrToUint32(x uint32) *uint32 {
-	return &x
+	// the masquerade subnets may use the default join subnets once they are relocated
+	conf := &operv1.Network{}
+	conf.Annotations = map[string]string{
+		names.OVNV4MasqueradeSubnetAnnotation: "100.64.0.0/29",
+		names.OVNV6MasqueradeSubnetAnnotation: "fd98::/125",
+	}
+	v4Subnet, v6Subnet := bootstrapOVNMasqueradeSubnets(conf, nil, "", "")
+	g.Expect(v4Subnet).To(BeEmpty())
+	g.Expect(v6Subnet).To(BeEmpty())
+	v4Subnet, v6Subnet = bootstrapOVNMasqueradeSubnets(conf, nil, "100.65.0.0/16", "fd97::/64")
+	g.Expect(v4Subnet).To(Equal("100.64.0.0/29"))
+	g.Expect(v6Subnet).To(Equal("fd98::/125"))
 }
 
-func uintPtr(x uint) *uint {
-	return &x
-}
+func TestRenderOVNKubernetesInternalSubnets(t *testing.T) {
+	g := NewGomegaWithT(t)
 
-func boolPtr(x bool) *bool {
-	return &x
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	bootstrapResult := &bootstrap.BootstrapResult{
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4", "5.6.7.8", "9.10.11.12"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+
+	ovnkubeConfig := func() string {
+		objs, err := renderOVNKubernetes(config, bootstrapResult, manifestDirOvn)
+		g.Expect(err).NotTo(HaveOccurred())
+		cm := &v1.ConfigMap{}
+		g.Expect(convert(findInObjs("", "ConfigMap", "ovnkube-config", "openshift-ovn-kubernetes", objs), cm)).To(Succeed())
+		return cm.Data["ovnkube.conf"]
+	}
+
+	// the ovn-kubernetes defaults are kept
+	g.Expect(ovnkubeConfig()).NotTo(ContainSubstring("masquerade-subnet"))
+	g.Expect(ovnkubeConfig()).NotTo(ContainSubstring("join-subnet"))
+
+	bootstrapResult.OVN.OVNKubernetesConfig.V4JoinSubnet = "100.65.0.0/16"
+	bootstrapResult.OVN.OVNKubernetesConfig.V6JoinSubnet = "fd97::/64"
+	bootstrapResult.OVN.OVNKubernetesConfig.V4MasqueradeSubnet = "169.254.0.0/28"
+	bootstrapResult.OVN.OVNKubernetesConfig.V6MasqueradeSubnet = "fd99::/120"
+	conf := ovnkubeConfig()
+	g.Expect(conf).To(ContainSubstring("nodeport=true\nv4-join-subnet=\"100.65.0.0/16\"\nv6-join-subnet=\"fd97::/64\"\nv4-masquerade-subnet=\"169.254.0.0/28\"\nv6-masquerade-subnet=\"fd99::/120\""))
 }
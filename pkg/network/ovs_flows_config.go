@@ -0,0 +1,171 @@
+package network
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetOVSFlowsConfigSpec returns the spec of the openshift-network-operator/ovs-flows-config OVSFlowsConfig or,
+// until it is converted to one, of the legacy ConfigMap. It returns nil if neither exists.
+func GetOVSFlowsConfigSpec(ctx context.Context, cl client.Reader) (*netopv1.OVSFlowsConfigSpec, error) {
+	nsn := types.NamespacedName{Name: OVSFlowsConfigMapName, Namespace: OVSFlowsConfigNamespace}
+	fc := &netopv1.OVSFlowsConfig{}
+	err := cl.Get(ctx, nsn, fc)
+	if err == nil {
+		return &fc.Spec, nil
+	}
+	// the CRD may not be installed yet during upgrades
+	if !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) && !runtime.IsNotRegisteredError(err) {
+		return nil, err
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := cl.Get(ctx, nsn, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// the converted ConfigMap no longer applies, even once the OVSFlowsConfig is deleted
+	if cm.Annotations[names.OVSFlowsConfigConvertedAnnotation] == "true" {
+		return nil, nil
+	}
+	return OVSFlowsConfigSpecFromConfigMap(cm), nil
+}
+
+// OVSFlowsConfigSpecFromConfigMap converts the legacy ovs-flows-config ConfigMap to the spec of an
+// OVSFlowsConfig. The invalid values are ignored, and nil is returned if the ConfigMap has neither a
+// sharedTarget nor a nodePort.
+func OVSFlowsConfigSpecFromConfigMap(cm *corev1.ConfigMap) *netopv1.OVSFlowsConfigSpec {
+	spec := &netopv1.OVSFlowsConfigSpec{}
+	if st, ok := cm.Data["sharedTarget"]; ok {
+		spec.SharedTarget = st
+	} else if np, ok := cm.Data["nodePort"]; ok {
+		port, err := strconv.ParseUint(np, 10, 16)
+		if err != nil || port == 0 {
+			klog.Warningf("%s: wrong nodePort value %s. Ignoring the configuration", OVSFlowsConfigMapName, np)
+			return nil
+		}
+		spec.NodePort = int32(port)
+	} else {
+		klog.Warningf("%s: wrong data section: either sharedTarget or nodePort sections are needed: %+v",
+			OVSFlowsConfigMapName, cm.Data)
+		return nil
+	}
+
+	if catStr, ok := cm.Data["cacheActiveTimeout"]; ok {
+		if catd, err := time.ParseDuration(catStr); err != nil {
+			klog.Warningf("%s: wrong cacheActiveTimeout value %s. Ignoring: %v",
+				OVSFlowsConfigMapName, catStr, err)
+		} else {
+			spec.CacheActiveTimeout = &metav1.Duration{Duration: catd}
+		}
+	}
+
+	if cmfStr, ok := cm.Data["cacheMaxFlows"]; ok {
+		if cmf, err := strconv.ParseUint(cmfStr, 10, 31); err != nil {
+			klog.Warningf("%s: wrong cacheMaxFlows value %s. Ignoring: %v",
+				OVSFlowsConfigMapName, cmfStr, err)
+		} else {
+			cmfi := int32(cmf)
+			spec.CacheMaxFlows = &cmfi
+		}
+	}
+
+	if sStr, ok := cm.Data["sampling"]; ok {
+		if sampling, err := strconv.ParseUint(sStr, 10, 31); err != nil {
+			klog.Warningf("%s: wrong sampling value %s. Ignoring: %v",
+				OVSFlowsConfigMapName, sStr, err)
+		} else {
+			si := int32(sampling)
+			spec.Sampling = &si
+		}
+	}
+
+	if tlsStr, ok := cm.Data["tls"]; ok {
+		if tls, err := strconv.ParseBool(tlsStr); err != nil {
+			klog.Warningf("%s: wrong tls value %s. Ignoring: %v",
+				OVSFlowsConfigMapName, tlsStr, err)
+		} else {
+			spec.TLS = tls
+		}
+	}
+	spec.CABundle = cm.Data["caBundle"]
+
+	return spec
+}
+
+// bootstrapFlowsConfig looks for the openshift-network-operator/ovs-flows-config OVSFlowsConfig, or
+// legacy configmap, and returns it or returns nil if it does not exist (or can't be properly parsed).
+func bootstrapFlowsConfig(cl client.Reader) *bootstrap.FlowsConfig {
+	spec, err := GetOVSFlowsConfigSpec(context.TODO(), cl)
+	if err != nil {
+		klog.Warningf("%s: error fetching the configuration: %v", OVSFlowsConfigMapName, err)
+		return nil
+	}
+	if spec == nil {
+		// ovs-flows-config is not defined. Ignoring from bootstrap
+		return nil
+	}
+
+	fc := bootstrap.FlowsConfig{}
+	// transforming the fields to OVS format
+	if spec.SharedTarget != "" {
+		fc.Target = spec.SharedTarget
+	} else if spec.NodePort != 0 {
+		// empty host will be interpreted as Node IP by ovn-kubernetes
+		fc.Target = ":" + strconv.Itoa(int(spec.NodePort))
+	} else {
+		klog.Warningf("%s: either sharedTarget or nodePort is needed", OVSFlowsConfigMapName)
+		return nil
+	}
+
+	if spec.CacheActiveTimeout != nil {
+		catf := spec.CacheActiveTimeout.Seconds()
+		catu := uint(catf)
+		if catf != float64(catu) {
+			klog.Warningf("%s: cacheActiveTimeout %s will be truncated to %d seconds",
+				OVSFlowsConfigMapName, spec.CacheActiveTimeout.Duration, catu)
+		}
+		fc.CacheActiveTimeout = &catu
+	}
+	if spec.CacheMaxFlows != nil && *spec.CacheMaxFlows >= 0 {
+		cmfu := uint(*spec.CacheMaxFlows)
+		fc.CacheMaxFlows = &cmfu
+	}
+	if spec.Sampling != nil && *spec.Sampling >= 0 {
+		su := uint(*spec.Sampling)
+		fc.Sampling = &su
+	}
+
+	if spec.TLS && spec.SharedTarget == "" {
+		klog.Warningf("%s: tls is only supported with a sharedTarget. Ignoring it", OVSFlowsConfigMapName)
+	} else {
+		fc.TLS = spec.TLS
+	}
+	if spec.CABundle != "" && fc.TLS {
+		if _, err := certutil.ParseCertsPEM([]byte(spec.CABundle)); err != nil {
+			klog.Warningf("%s: wrong caBundle, using the cluster-wide trusted CA bundle instead: %v",
+				OVSFlowsConfigMapName, err)
+		} else {
+			fc.CABundle = spec.CABundle
+		}
+	}
+
+	return &fc
+}
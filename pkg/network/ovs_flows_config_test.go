@@ -0,0 +1,81 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	netopv1 "github.com/openshift/cluster-network-operator/pkg/apis/network/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBootstrapOVSFlowsConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	maxFlows := int32(33)
+	fc := bootstrapFlowsConfig(&fakeClientReader{
+		flowsConfig: &netopv1.OVSFlowsConfig{Spec: netopv1.OVSFlowsConfigSpec{
+			NodePort:           3131,
+			CacheActiveTimeout: &metav1.Duration{Duration: 3200 * time.Millisecond},
+			CacheMaxFlows:      &maxFlows,
+		}},
+		// the legacy ConfigMap is superseded
+		configMap: &v1.ConfigMap{Data: map[string]string{"sharedTarget": "1.2.3.4:3030"}},
+	})
+	g.Expect(fc.Target).To(Equal(":3131"))
+	g.Expect(*fc.CacheActiveTimeout).To(BeEquivalentTo(3))
+	g.Expect(*fc.CacheMaxFlows).To(BeEquivalentTo(33))
+	g.Expect(fc.Sampling).To(BeNil())
+
+	// a collector is required
+	fc = bootstrapFlowsConfig(&fakeClientReader{flowsConfig: &netopv1.OVSFlowsConfig{}})
+	g.Expect(fc).To(BeNil())
+}
+
+func TestGetOVSFlowsConfigSpec(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// the ConfigMap is read when the OVSFlowsConfig kind is not known yet
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: OVSFlowsConfigNamespace, Name: OVSFlowsConfigMapName},
+		Data:       map[string]string{"nodePort": "3131", "sampling": "55", "cacheActiveTimeout": "5s"},
+	}
+	spec, err := GetOVSFlowsConfigSpec(context.TODO(), fake.NewClientBuilder().WithObjects(cm).Build())
+	g.Expect(err).NotTo(HaveOccurred())
+	sampling := int32(55)
+	g.Expect(spec).To(Equal(&netopv1.OVSFlowsConfigSpec{
+		NodePort:           3131,
+		Sampling:           &sampling,
+		CacheActiveTimeout: &metav1.Duration{Duration: 5 * time.Second},
+	}))
+
+	spec, err = GetOVSFlowsConfigSpec(context.TODO(), fake.NewClientBuilder().Build())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(spec).To(BeNil())
+}
+
+func TestOVSFlowsConfigSpecFromConfigMap(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	spec := OVSFlowsConfigSpecFromConfigMap(&v1.ConfigMap{Data: map[string]string{
+		"sharedTarget":       "collector.example.com:4739",
+		"cacheActiveTimeout": "invalid timeout",
+		"cacheMaxFlows":      "-1",
+		"sampling":           "100",
+		"tls":                "true",
+		"caBundle":           "bundle",
+	}})
+	sampling := int32(100)
+	g.Expect(spec).To(Equal(&netopv1.OVSFlowsConfigSpec{
+		SharedTarget: "collector.example.com:4739",
+		Sampling:     &sampling,
+		TLS:          true,
+		CABundle:     "bundle",
+	}))
+
+	g.Expect(OVSFlowsConfigSpecFromConfigMap(&v1.ConfigMap{Data: map[string]string{"nodePort": "http"}})).To(BeNil())
+	g.Expect(OVSFlowsConfigSpecFromConfigMap(&v1.ConfigMap{Data: map[string]string{"sampling": "1"}})).To(BeNil())
+}
@@ -1,7 +1,6 @@
 package network
 
 import (
-	"log"
 	"net"
 	"os"
 	"path/filepath"
@@ -12,42 +11,47 @@ import (
 
 	operv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/featuregates"
 	"github.com/openshift/cluster-network-operator/pkg/render"
 	iputil "github.com/openshift/cluster-network-operator/pkg/util/ip"
+	"github.com/openshift/cluster-network-operator/pkg/util/logging"
 
+	corev1 "k8s.io/api/core/v1"
 	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	utilnet "k8s.io/utils/net"
 )
 
+var renderLog = logging.Logger(logging.Render)
+
 func Render(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult, manifestDir string) ([]*uns.Unstructured, error) {
-	log.Printf("Starting render phase")
+	renderLog.V(1).Info("Starting render phase")
 	objs := []*uns.Unstructured{}
 
 	// render cloud network config controller **before** the network plugin.
 	// the network plugin is dependent upon having the cloud network CRD
 	// defined as to initialize its watcher, otherwise it will error and crash
-	o, err := renderCloudNetworkConfigController(conf, bootstrapResult.Infra, manifestDir)
+	o, err := renderCloudNetworkConfigController(conf, bootstrapResult.Infra, manifestDir, bootstrapResult.FeatureGates)
 	if err != nil {
 		return nil, err
 	}
 	objs = append(objs, o...)
 
 	// render Multus
-	o, err = renderMultus(conf, manifestDir)
+	o, err = renderMultus(conf, bootstrapResult, manifestDir)
 	if err != nil {
 		return nil, err
 	}
 	objs = append(objs, o...)
 
 	// render MultusAdmissionController
-	o, err = renderMultusAdmissionController(conf, manifestDir, bootstrapResult.Infra.ExternalControlPlane)
+	o, err = renderMultusAdmissionController(conf, manifestDir, &bootstrapResult.Infra, bootstrapResult.FeatureGates)
 	if err != nil {
 		return nil, err
 	}
 	objs = append(objs, o...)
 
 	// render MultiNetworkPolicy
-	o, err = renderMultiNetworkpolicy(conf, manifestDir)
+	o, err = renderMultiNetworkpolicy(conf, manifestDir, bootstrapResult.FeatureGates)
 	if err != nil {
 		return nil, err
 	}
@@ -60,14 +64,14 @@ func Render(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult
 	}
 	objs = append(objs, o...)
 
+	// render the cleanup of the state left on the nodes by the former default network
+	o, err = renderNetworkCleanup(conf, bootstrapResult, manifestDir)
+	if err != nil {
+		return nil, err
+	}
+	objs = append(objs, o...)
+
 	// render kube-proxy
-	// DPU_DEV_PREVIEW
-	// There is currently a restriction that renderStandaloneKubeProxy() is
-	// called after renderDefaultNetwork(). The OVN-Kubernetes code is enabling
-	// KubeProxy in Node Mode of "dpu". Once out of DevPreview, CNO API will be
-	// expanded to include Node Mode and it will be stored in conf (operv1.NetworkSpec)
-	// and KubeProxy can read Node Mode and be enabled in KubeProxy code, removing this
-	// dependency.
 	o, err = renderStandaloneKubeProxy(conf, bootstrapResult, manifestDir)
 	if err != nil {
 		return nil, err
@@ -75,26 +79,62 @@ func Render(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult
 	objs = append(objs, o...)
 
 	// render additional networks
-	o, err = renderAdditionalNetworks(conf, manifestDir)
+	o, err = renderAdditionalNetworks(conf, manifestDir, bootstrapResult.FeatureGates)
 	if err != nil {
 		return nil, err
 	}
 	objs = append(objs, o...)
 
 	// render network diagnostics
-	o, err = renderNetworkDiagnostics(conf, manifestDir)
+	o, err = renderNetworkDiagnostics(conf, bootstrapResult, manifestDir)
+	if err != nil {
+		return nil, err
+	}
+	objs = append(objs, o...)
+
+	// render the CNI latency probe
+	o, err = renderCNILatencyProbe(conf, bootstrapResult, manifestDir)
+	if err != nil {
+		return nil, err
+	}
+	objs = append(objs, o...)
+
+	// render the networking plugin of the console
+	o, err = renderNetworkingConsolePlugin(bootstrapResult, manifestDir)
+	if err != nil {
+		return nil, err
+	}
+	objs = append(objs, o...)
+
+	// render the conntrack tuning of the nodes
+	o, err = renderConntrackTuning(bootstrapResult, manifestDir)
 	if err != nil {
 		return nil, err
 	}
 	objs = append(objs, o...)
 
-	o, err = renderNetworkPublic(manifestDir)
+	// render the MachineConfigs of the prerequisites of the network on the nodes
+	o, err = renderMachineConfigs(conf, bootstrapResult, manifestDir)
 	if err != nil {
 		return nil, err
 	}
 	objs = append(objs, o...)
 
-	log.Printf("Render phase done, rendered %d objects", len(objs))
+	o, err = renderNetworkPublic(manifestDir, bootstrapResult.FeatureGates)
+	if err != nil {
+		return nil, err
+	}
+	objs = append(objs, o...)
+
+	// leave out the objects excluded by the administrator
+	objs = excludeObjects(objs, bootstrapResult)
+
+	// roll out the pods when the configuration they use changes
+	if err := setConfigHashAnnotations(objs); err != nil {
+		return nil, err
+	}
+
+	renderLog.V(1).Info("Render phase done", "objects", len(objs))
 	return objs, nil
 }
 
@@ -176,8 +216,8 @@ func DeprecatedCanonicalize(conf *operv1.NetworkSpec) {
 	}
 
 	if !reflect.DeepEqual(orig, conf) {
-		log.Printf("WARNING: One or more fields of Network.operator.openshift.io was incorrectly capitalized. Although this has been fixed now, it is possible that other components previously saw the incorrect value and interpreted it incorrectly.")
-		log.Printf("Original spec: %#v\nModified spec: %#v\n", orig, conf)
+		renderLog.Info("WARNING: One or more fields of Network.operator.openshift.io was incorrectly capitalized. Although this has been fixed now, it is possible that other components previously saw the incorrect value and interpreted it incorrectly.",
+			"original", orig, "modified", conf)
 	}
 }
 
@@ -209,11 +249,25 @@ func FillDefaults(conf, previous *operv1.NetworkSpec) {
 	}
 	if previous == nil { // host mtu isn't used in subsequent runs, elide these logs
 		if err != nil {
-			log.Printf("Failed MTU probe, falling back to 1500: %v", err)
+			renderLog.Error(err, "Failed MTU probe, falling back to 1500")
 		} else {
-			log.Printf("Detected uplink MTU %d", hostMTU)
+			renderLog.Info("Detected uplink MTU", "mtu", hostMTU)
 		}
 	}
+	fillDefaults(conf, previous, hostMTU)
+}
+
+// FillDefaultsWithMTU is FillDefaults with the MTU of the nodes known beforehand, as when rendering
+// the manifests outside of the cluster, rather than probed on the host the operator is running on.
+func FillDefaultsWithMTU(conf, previous *operv1.NetworkSpec, mtu int) {
+	if previous == nil {
+		renderLog.Info("Using the given MTU of the nodes", "mtu", mtu)
+	}
+	fillDefaults(conf, previous, mtu)
+}
+
+// fillDefaults applies the default values, with hostMTU as the MTU of the nodes
+func fillDefaults(conf, previous *operv1.NetworkSpec, hostMTU int) {
 	// DisableMultiNetwork defaults to false
 	if conf.DisableMultiNetwork == nil {
 		disable := false
@@ -277,6 +331,19 @@ func IsChangeSafe(prev, next *operv1.NetworkSpec) error {
 	return nil
 }
 
+// IsChangeForcible returns true if the unsafe changes from prev to next can be forced with the
+// ForceUnsafeChangeAnnotation: they are changes of the genevePort or of the hybrid overlay of
+// ovn-kubernetes, which are rolled out by restarting all of the ovn-kubernetes pods.
+func IsChangeForcible(prev, next *operv1.NetworkSpec) bool {
+	if prev == nil || prev.DefaultNetwork.Type != operv1.NetworkTypeOVNKubernetes ||
+		next.DefaultNetwork.Type != operv1.NetworkTypeOVNKubernetes {
+		return false
+	}
+	forced := prev.DeepCopy()
+	forceOVNKubernetesChange(forced, next)
+	return IsChangeSafe(forced, next) == nil
+}
+
 func isNetworkChangeSafe(prev, next *operv1.NetworkSpec) error {
 	// Forbid changing service network during a migration
 	if prev.Migration != nil {
@@ -451,7 +518,7 @@ func validateDefaultNetwork(conf *operv1.NetworkSpec) []error {
 	case operv1.NetworkTypeKuryr:
 		return validateKuryr(conf)
 	default:
-		return nil
+		return validateThirdPartyCNI(conf)
 	}
 }
 
@@ -471,7 +538,7 @@ func renderDefaultNetwork(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.B
 	case operv1.NetworkTypeKuryr:
 		return renderKuryr(conf, bootstrapResult, manifestDir)
 	default:
-		log.Printf("NOTICE: Unknown network type %s, ignoring", dn.Type)
+		renderLog.Info("NOTICE: Unknown network type, only rendering Multus and reporting its readiness", "type", dn.Type)
 		return nil, nil
 	}
 }
@@ -544,7 +611,7 @@ func validateAdditionalNetworks(conf *operv1.NetworkSpec) []error {
 }
 
 // renderAdditionalNetworks generates the manifests of the requested additional networks
-func renderAdditionalNetworks(conf *operv1.NetworkSpec, manifestDir string) ([]*uns.Unstructured, error) {
+func renderAdditionalNetworks(conf *operv1.NetworkSpec, manifestDir string, featureGates featuregates.FeatureGates) ([]*uns.Unstructured, error) {
 	ans := conf.AdditionalNetworks
 	out := []*uns.Unstructured{}
 
@@ -561,13 +628,13 @@ func renderAdditionalNetworks(conf *operv1.NetworkSpec, manifestDir string) ([]*
 	for _, an := range ans {
 		switch an.Type {
 		case operv1.NetworkTypeRaw:
-			objs, err := renderRawCNIConfig(&an, manifestDir)
+			objs, err := renderRawCNIConfig(&an, manifestDir, featureGates)
 			if err != nil {
 				return nil, err
 			}
 			out = append(out, objs...)
 		case operv1.NetworkTypeSimpleMacvlan:
-			objs, err := renderSimpleMacvlanConfig(&an, manifestDir)
+			objs, err := renderSimpleMacvlanConfig(&an, manifestDir, featureGates)
 			if err != nil {
 				return nil, err
 			}
@@ -581,7 +648,7 @@ func renderAdditionalNetworks(conf *operv1.NetworkSpec, manifestDir string) ([]*
 }
 
 // renderMultusAdmissionController generates the manifests of Multus Admission Controller
-func renderMultusAdmissionController(conf *operv1.NetworkSpec, manifestDir string, externalControlPlane bool) ([]*uns.Unstructured, error) {
+func renderMultusAdmissionController(conf *operv1.NetworkSpec, manifestDir string, infra *bootstrap.InfraBootstrapResult, featureGates featuregates.FeatureGates) ([]*uns.Unstructured, error) {
 	if *conf.DisableMultiNetwork {
 		return nil, nil
 	}
@@ -589,7 +656,7 @@ func renderMultusAdmissionController(conf *operv1.NetworkSpec, manifestDir strin
 	var err error
 	out := []*uns.Unstructured{}
 
-	objs, err := renderMultusAdmissonControllerConfig(manifestDir, externalControlPlane)
+	objs, err := renderMultusAdmissonControllerConfig(manifestDir, infra, featureGates)
 	if err != nil {
 		return nil, err
 	}
@@ -598,7 +665,7 @@ func renderMultusAdmissionController(conf *operv1.NetworkSpec, manifestDir strin
 }
 
 // renderMultiNetworkpolicy generates the manifests of MultiNetworkPolicy
-func renderMultiNetworkpolicy(conf *operv1.NetworkSpec, manifestDir string) ([]*uns.Unstructured, error) {
+func renderMultiNetworkpolicy(conf *operv1.NetworkSpec, manifestDir string, featureGates featuregates.FeatureGates) ([]*uns.Unstructured, error) {
 	// disable it if DisableMultiNetwork = true
 	if *conf.DisableMultiNetwork {
 		return nil, nil
@@ -611,7 +678,7 @@ func renderMultiNetworkpolicy(conf *operv1.NetworkSpec, manifestDir string) ([]*
 	var err error
 	out := []*uns.Unstructured{}
 
-	objs, err := renderMultiNetworkpolicyConfig(manifestDir)
+	objs, err := renderMultiNetworkpolicyConfig(manifestDir, featureGates)
 	if err != nil {
 		return nil, err
 	}
@@ -619,16 +686,23 @@ func renderMultiNetworkpolicy(conf *operv1.NetworkSpec, manifestDir string) ([]*
 	return out, nil
 }
 
-// renderNetworkDiagnostics renders the connectivity checks
-func renderNetworkDiagnostics(conf *operv1.NetworkSpec, manifestDir string) ([]*uns.Unstructured, error) {
+// renderNetworkDiagnostics renders the connectivity checks, and the probe of the path MTU between
+// the nodes when requested
+func renderNetworkDiagnostics(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult, manifestDir string) ([]*uns.Unstructured, error) {
 	if conf.DisableNetworkDiagnostics {
 		return nil, nil
 	}
 
-	data := render.MakeRenderData()
+	data := makeRenderData(bootstrapResult.FeatureGates)
 	data.Data["ReleaseVersion"] = os.Getenv("RELEASE_VERSION")
 	data.Data["NetworkCheckSourceImage"] = os.Getenv("NETWORK_CHECK_SOURCE_IMAGE")
 	data.Data["NetworkCheckTargetImage"] = os.Getenv("NETWORK_CHECK_TARGET_IMAGE")
+	data.Data["MTUProbe"] = uint32(0)
+	if bootstrapResult.MTUProbe {
+		data.Data["MTUProbe"] = mtuProbeMTU(conf)
+	}
+	// the probe script needs bash, kubectl and ping, which the OVN image ships
+	data.Data["MTUProbeImage"] = os.Getenv("OVN_IMAGE")
 
 	manifests, err := render.RenderDir(filepath.Join(manifestDir, "network-diagnostics"), &data)
 	if err != nil {
@@ -639,8 +713,8 @@ func renderNetworkDiagnostics(conf *operv1.NetworkSpec, manifestDir string) ([]*
 }
 
 // renderNetworkPublic renders the common objects related to the openshift-network-features configmap
-func renderNetworkPublic(manifestDir string) ([]*uns.Unstructured, error) {
-	data := render.MakeRenderData()
+func renderNetworkPublic(manifestDir string, featureGates featuregates.FeatureGates) ([]*uns.Unstructured, error) {
+	data := makeRenderData(featureGates)
 
 	manifests, err := render.RenderDir(filepath.Join(manifestDir, "network", "public"), &data)
 	if err != nil {
@@ -648,3 +722,10 @@ func renderNetworkPublic(manifestDir string) ([]*uns.Unstructured, error) {
 	}
 	return manifests, nil
 }
+
+// recordMTUMigrationEvent records the step of the MTU migration being rolled out
+func recordMTUMigrationEvent(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult) {
+	mtuNet := conf.Migration.MTU.Network
+	bootstrapResult.RecordEvent(corev1.EventTypeNormal, "MTUMigration",
+		"Rolling out the migration of the pod network MTU from %d to %d", *mtuNet.From, *mtuNet.To)
+}
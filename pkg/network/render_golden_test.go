@@ -0,0 +1,163 @@
+package network
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	configv1 "github.com/openshift/api/config/v1"
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/testsupport"
+
+	. "github.com/onsi/gomega"
+)
+
+var updateGolden = flag.Bool("update-golden", false, "update the golden files of the render tests")
+
+// goldenEnv are the environment variables read by the renderers, set to fixed values so
+// that the golden files do not depend on the environment of the test
+var goldenEnv = map[string]string{
+	"RELEASE_VERSION":                       "4.10.0",
+	"KUBERNETES_SERVICE_HOST":               "api-int.example.com",
+	"KUBERNETES_SERVICE_PORT":               "6443",
+	"SDN_IMAGE":                             "sdn-image",
+	"OVN_IMAGE":                             "ovn-image",
+	"KURYR_DAEMON_IMAGE":                    "kuryr-daemon-image",
+	"KURYR_CONTROLLER_IMAGE":                "kuryr-controller-image",
+	"KUBE_PROXY_IMAGE":                      "kube-proxy-image",
+	"KUBE_RBAC_PROXY_IMAGE":                 "kube-rbac-proxy-image",
+	"MULTUS_IMAGE":                          "multus-image",
+	"MULTUS_ADMISSION_CONTROLLER_IMAGE":     "multus-admission-controller-image",
+	"MULTUS_NETWORKPOLICY_IMAGE":            "multus-networkpolicy-image",
+	"CNI_PLUGINS_IMAGE":                     "cni-plugins-image",
+	"BOND_CNI_PLUGIN_IMAGE":                 "bond-cni-plugin-image",
+	"WHEREABOUTS_CNI_IMAGE":                 "whereabouts-cni-image",
+	"ROUTE_OVERRRIDE_CNI_IMAGE":             "route-override-cni-image",
+	"EGRESS_ROUTER_CNI_IMAGE":               "egress-router-cni-image",
+	"NETWORK_METRICS_DAEMON_IMAGE":          "network-metrics-daemon-image",
+	"NETWORK_CHECK_SOURCE_IMAGE":            "network-check-source-image",
+	"NETWORK_CHECK_TARGET_IMAGE":            "network-check-target-image",
+	"CLOUD_NETWORK_CONFIG_CONTROLLER_IMAGE": "cloud-network-config-controller-image",
+	"SYSTEM_CNI_CONF_DIR":                   "",
+	"MULTUS_CNI_CONF_DIR":                   "",
+	"CNI_BIN_DIR":                           "",
+}
+
+// setGoldenEnv sets the environment of the golden render tests, and returns a function restoring it
+func setGoldenEnv() func() {
+	previous := map[string]*string{}
+	for k, v := range goldenEnv {
+		if old, ok := os.LookupEnv(k); ok {
+			previous[k] = &old
+		} else {
+			previous[k] = nil
+		}
+		if v == "" {
+			os.Unsetenv(k)
+		} else {
+			os.Setenv(k, v)
+		}
+	}
+	return func() {
+		for k, v := range previous {
+			if v == nil {
+				os.Unsetenv(k)
+			} else {
+				os.Setenv(k, *v)
+			}
+		}
+	}
+}
+
+// TestRenderGolden renders every component for each default network type, and compares the
+// manifests with the golden files in testdata/golden. Run the tests with -update-golden to
+// regenerate them after an intended change of the manifests.
+func TestRenderGolden(t *testing.T) {
+	defer setGoldenEnv()()
+
+	for _, tc := range []struct {
+		name            string
+		spec            *operv1.NetworkSpec
+		bootstrapResult *bootstrap.BootstrapResult
+	}{
+		{
+			name:            "openshift-sdn",
+			spec:            testsupport.NewNetworkSpec(operv1.NetworkTypeOpenShiftSDN).Build(),
+			bootstrapResult: testsupport.NewBootstrapResult().Build(),
+		},
+		{
+			name:            "ovn-kubernetes",
+			spec:            testsupport.NewNetworkSpec(operv1.NetworkTypeOVNKubernetes).Build(),
+			bootstrapResult: testsupport.NewBootstrapResult().Build(),
+		},
+		{
+			name: "ovn-kubernetes-gcp",
+			spec: testsupport.NewNetworkSpec(operv1.NetworkTypeOVNKubernetes).Build(),
+			bootstrapResult: testsupport.NewBootstrapResult().WithInfra(bootstrap.InfraBootstrapResult{
+				PlatformType:                   configv1.GCPPlatformType,
+				PlatformStatus:                 &configv1.PlatformStatus{Type: configv1.GCPPlatformType},
+				EgressIPCapacityLimited:        true,
+				LoadBalancerHealthCheckSources: []string{"35.191.0.0/16", "130.211.0.0/22"},
+			}).Build(),
+		},
+		{
+			name:            "third-party",
+			spec:            testsupport.NewNetworkSpec("Calico").Build(),
+			bootstrapResult: testsupport.NewBootstrapResult().Build(),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			FillDefaultsWithMTU(tc.spec, nil, 1500)
+			objs, err := Render(tc.spec, tc.bootstrapResult, manifestDir)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			var rendered bytes.Buffer
+			for _, obj := range objs {
+				out, err := yaml.Marshal(obj.Object)
+				g.Expect(err).NotTo(HaveOccurred())
+				rendered.WriteString("---\n")
+				rendered.Write(out)
+			}
+
+			path := filepath.Join("testdata", "golden", tc.name+".yaml")
+			if *updateGolden {
+				g.Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+				g.Expect(ioutil.WriteFile(path, rendered.Bytes(), 0644)).To(Succeed())
+				return
+			}
+			golden, err := ioutil.ReadFile(path)
+			g.Expect(err).NotTo(HaveOccurred(), "run the tests with -update-golden to create %s", path)
+			if !bytes.Equal(golden, rendered.Bytes()) {
+				t.Fatalf("the rendered manifests differ from %s, run the tests with -update-golden if intended:\n%s",
+					path, firstDifference(string(golden), rendered.String()))
+			}
+		})
+	}
+}
+
+// firstDifference describes the first line differing between the golden and the rendered manifests
+func firstDifference(golden, rendered string) string {
+	goldenLines, renderedLines := strings.Split(golden, "\n"), strings.Split(rendered, "\n")
+	for i := 0; i < len(goldenLines) || i < len(renderedLines); i++ {
+		var g, r string
+		if i < len(goldenLines) {
+			g = goldenLines[i]
+		}
+		if i < len(renderedLines) {
+			r = renderedLines[i]
+		}
+		if g != r {
+			return fmt.Sprintf("line %d:\n- %s\n+ %s", i+1, g, r)
+		}
+	}
+	return ""
+}
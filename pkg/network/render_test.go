@@ -1,11 +1,19 @@
 package network
 
 import (
+	"fmt"
 	"testing"
 
 	. "github.com/onsi/gomega"
 
+	configv1 "github.com/openshift/api/config/v1"
 	operv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestIsChangeSafe(t *testing.T) {
@@ -185,8 +193,13 @@ func TestRenderUnknownNetwork(t *testing.T) {
 	err = IsChangeSafe(prev, next)
 	g.Expect(err).NotTo(HaveOccurred())
 
-	bootstrapResult, err := Bootstrap(&config, nil)
+	bootstrapResult, err := Bootstrap(&config, fakeInfraClient(g, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "cluster-config-v1"},
+		Data:       map[string]string{"install-config": "fips: true"},
+	}))
 	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(bootstrapResult.Infra.PlatformType).To(Equal(configv1.NonePlatformType))
+	g.Expect(bootstrapResult.Infra.FIPS).To(BeTrue())
 
 	objs, err := Render(prev, bootstrapResult, manifestDir)
 	g.Expect(err).NotTo(HaveOccurred())
@@ -201,5 +214,30 @@ func TestRenderUnknownNetwork(t *testing.T) {
 	g.Expect(objs).To(ContainElement(HaveKubernetesID("Role", "openshift-config-managed", "openshift-network-public-role")))
 	g.Expect(objs).To(ContainElement(HaveKubernetesID("RoleBinding", "openshift-config-managed", "openshift-network-public-role-binding")))
 
+	// validate that the components deployed for every network follow the FIPS mode
+	for _, name := range []string{"multus-admission-controller", "network-metrics-daemon"} {
+		obj := findInObjs("apps", "DaemonSet", name, "openshift-multus", objs)
+		g.Expect(obj).NotTo(BeNil(), "DaemonSet openshift-multus/%s is not rendered", name)
+		containers, _, err := uns.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(fmt.Sprint(containers)).To(ContainSubstring("--tls-cipher-suites=TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256," +
+			"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384 "))
+	}
+
 	// TODO(cdc) validate that kube-proxy is rendered
 }
+
+// fakeInfraClient returns a fake client with the infrastructure configuration of a cluster without
+// platform, and the given objects
+func fakeInfraClient(g *WithT, objs ...crclient.Object) crclient.Client {
+	g.Expect(configv1.AddToScheme(scheme.Scheme)).To(Succeed())
+	infra := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status: configv1.InfrastructureStatus{
+			PlatformStatus:         &configv1.PlatformStatus{Type: configv1.NonePlatformType},
+			ControlPlaneTopology:   configv1.HighlyAvailableTopologyMode,
+			InfrastructureTopology: configv1.HighlyAvailableTopologyMode,
+		},
+	}
+	return fake.NewClientBuilder().WithObjects(append(objs, infra)...).Build()
+}
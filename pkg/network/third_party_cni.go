@@ -0,0 +1,85 @@
+package network
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+	"github.com/pkg/errors"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// the default network types deployed by the operator, any other type is a third-party network
+var operatorNetworkTypes = sets.NewString(string(operv1.NetworkTypeOpenShiftSDN), string(operv1.NetworkTypeOVNKubernetes), string(operv1.NetworkTypeKuryr))
+
+// isThirdPartyCNI returns whether the default network is a third-party network, not deployed by the operator
+func isThirdPartyCNI(conf *operv1.NetworkSpec) bool {
+	return !operatorNetworkTypes.Has(string(conf.DefaultNetwork.Type))
+}
+
+// bootstrapThirdPartyCNI returns the DaemonSet and the CNI configuration file of a third-party
+// default network, as named by annotations on the operator configuration. The DaemonSet is
+// looked up when the network is attached, so that a misnamed DaemonSet is reported right away.
+func bootstrapThirdPartyCNI(conf *operv1.Network, kubeClient client.Reader, res *bootstrap.BootstrapResult) {
+	if !isThirdPartyCNI(&conf.Spec) {
+		return
+	}
+	annotations := conf.GetAnnotations()
+
+	if v, ok := annotations[names.ThirdPartyCNIDaemonSetAnnotation]; ok {
+		parts := strings.Split(v, "/")
+		if len(parts) != 2 || len(validation.IsDNS1123Label(parts[0])) > 0 || len(validation.IsDNS1123Subdomain(parts[1])) > 0 {
+			klog.Warningf("%s must be \"<namespace>/<name>\", is: %q. Ignoring it", names.ThirdPartyCNIDaemonSetAnnotation, v)
+		} else {
+			nsn := types.NamespacedName{Namespace: parts[0], Name: parts[1]}
+			res.ThirdPartyCNI.DaemonSet = &nsn
+			if kubeClient != nil {
+				if err := kubeClient.Get(context.TODO(), nsn, &appsv1.DaemonSet{}); apierrors.IsNotFound(err) {
+					res.RecordEvent(corev1.EventTypeWarning, "ThirdPartyCNINotFound",
+						"The DaemonSet %s of the %s default network was not found", nsn.String(), conf.Spec.DefaultNetwork.Type)
+				} else if err != nil {
+					klog.Warningf("Failed to retrieve the DaemonSet %s of the %s default network: %v", nsn.String(), conf.Spec.DefaultNetwork.Type, err)
+				}
+			}
+		}
+	}
+
+	if v, ok := annotations[names.ThirdPartyCNIConfigFileAnnotation]; ok {
+		if v != filepath.Base(v) || strings.HasPrefix(v, ".") || (filepath.Ext(v) != ".conf" && filepath.Ext(v) != ".conflist") {
+			klog.Warningf("%s must be the name of a .conf or .conflist file, is: %q. Ignoring it", names.ThirdPartyCNIConfigFileAnnotation, v)
+		} else {
+			res.ThirdPartyCNI.ConfigFile = v
+		}
+	}
+}
+
+// validateThirdPartyCNI validates the configuration of a third-party default network. The operator
+// does not deploy it, but the cluster and service networks it is given must still be consistent.
+func validateThirdPartyCNI(conf *operv1.NetworkSpec) []error {
+	out := []error{}
+	if conf.DefaultNetwork.Type == "" {
+		out = append(out, errors.Errorf("defaultNetwork.type must be set"))
+	}
+
+	withHostPrefix := 0
+	for _, cn := range conf.ClusterNetwork {
+		if cn.HostPrefix != 0 {
+			withHostPrefix++
+		}
+	}
+	if withHostPrefix != 0 && withHostPrefix != len(conf.ClusterNetwork) {
+		out = append(out, errors.Errorf("hostPrefix must be set on either all or none of the clusterNetwork entries of the %s network", conf.DefaultNetwork.Type))
+	}
+	return out
+}
@@ -0,0 +1,120 @@
+package network
+
+import (
+	"strings"
+	"testing"
+
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	"github.com/openshift/cluster-network-operator/pkg/names"
+
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var ThirdPartyCNIConfig = operv1.Network{
+	Spec: operv1.NetworkSpec{
+		ServiceNetwork: []string{"172.30.0.0/16"},
+		ClusterNetwork: []operv1.ClusterNetworkEntry{
+			{
+				CIDR:       "10.128.0.0/15",
+				HostPrefix: 23,
+			},
+		},
+		DefaultNetwork: operv1.DefaultNetworkDefinition{
+			Type: "Calico",
+		},
+	},
+}
+
+func TestBootstrapThirdPartyCNI(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// the operator network types are ignored
+	crd := OVNKubernetesConfig.DeepCopy()
+	crd.Annotations = map[string]string{names.ThirdPartyCNIDaemonSetAnnotation: "calico-system/calico-node"}
+	res := &bootstrap.BootstrapResult{}
+	bootstrapThirdPartyCNI(crd, nil, res)
+	g.Expect(res.ThirdPartyCNI).To(Equal(bootstrap.ThirdPartyCNIBootstrapResult{}))
+
+	// invalid values are ignored
+	crd = ThirdPartyCNIConfig.DeepCopy()
+	crd.Annotations = map[string]string{
+		names.ThirdPartyCNIDaemonSetAnnotation:  "calico-node",
+		names.ThirdPartyCNIConfigFileAnnotation: "../10-calico.conflist",
+	}
+	bootstrapThirdPartyCNI(crd, nil, res)
+	g.Expect(res.ThirdPartyCNI).To(Equal(bootstrap.ThirdPartyCNIBootstrapResult{}))
+
+	// a missing daemonset is reported
+	crd.Annotations = map[string]string{
+		names.ThirdPartyCNIDaemonSetAnnotation:  "calico-system/calico-node",
+		names.ThirdPartyCNIConfigFileAnnotation: "10-calico.conflist",
+	}
+	bootstrapThirdPartyCNI(crd, fake.NewClientBuilder().Build(), res)
+	g.Expect(res.ThirdPartyCNI).To(Equal(bootstrap.ThirdPartyCNIBootstrapResult{
+		DaemonSet:  &types.NamespacedName{Namespace: "calico-system", Name: "calico-node"},
+		ConfigFile: "10-calico.conflist",
+	}))
+	g.Expect(res.Events).To(Equal([]bootstrap.Event{{
+		Type:    corev1.EventTypeWarning,
+		Reason:  "ThirdPartyCNINotFound",
+		Message: "The DaemonSet calico-system/calico-node of the Calico default network was not found",
+	}}))
+
+	client := fake.NewClientBuilder().WithObjects(&appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "calico-system", Name: "calico-node"},
+	}).Build()
+	res = &bootstrap.BootstrapResult{}
+	bootstrapThirdPartyCNI(crd, client, res)
+	g.Expect(res.ThirdPartyCNI.DaemonSet).NotTo(BeNil())
+	g.Expect(res.Events).To(BeEmpty())
+}
+
+func TestValidateThirdPartyCNI(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := ThirdPartyCNIConfig.DeepCopy()
+	config := &crd.Spec
+	g.Expect(validateDefaultNetwork(config)).To(BeEmpty())
+
+	config.ClusterNetwork = append(config.ClusterNetwork, operv1.ClusterNetworkEntry{CIDR: "10.132.0.0/14"})
+	g.Expect(validateDefaultNetwork(config)).To(ContainElement(MatchError(
+		ContainSubstring("hostPrefix must be set on either all or none of the clusterNetwork entries"))))
+
+	config.ClusterNetwork[0].HostPrefix = 0
+	g.Expect(validateDefaultNetwork(config)).To(BeEmpty())
+
+	config.DefaultNetwork.Type = ""
+	g.Expect(validateDefaultNetwork(config)).To(ContainElement(MatchError(
+		ContainSubstring("defaultNetwork.type must be set"))))
+}
+
+func TestRenderMultusThirdPartyCNI(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := ThirdPartyCNIConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaults(config, nil)
+
+	// multus waits for the configuration file of the third-party network
+	res := &bootstrap.BootstrapResult{}
+	res.ThirdPartyCNI.ConfigFile = "10-calico.conflist"
+	objs, err := renderMultus(config, res, manifestDir)
+	g.Expect(err).NotTo(HaveOccurred())
+	ds := &appsv1.DaemonSet{}
+	g.Expect(convert(findInObjs("apps", "DaemonSet", "multus", "openshift-multus", objs), ds)).To(Succeed())
+	g.Expect(strings.Join(ds.Spec.Template.Spec.Containers[0].Args, " ")).To(
+		ContainSubstring("--readiness-indicator-file=/var/run/multus/cni/net.d/10-calico.conflist"))
+
+	objs, err = renderMultus(config, &bootstrap.BootstrapResult{}, manifestDir)
+	g.Expect(err).NotTo(HaveOccurred())
+	ds = &appsv1.DaemonSet{}
+	g.Expect(convert(findInObjs("apps", "DaemonSet", "multus", "openshift-multus", objs), ds)).To(Succeed())
+	g.Expect(strings.Join(ds.Spec.Template.Spec.Containers[0].Args, " ")).NotTo(
+		ContainSubstring("--readiness-indicator-file"))
+}
@@ -0,0 +1,122 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	iputil "github.com/openshift/cluster-network-operator/pkg/util/ip"
+)
+
+// ValidationWarnings returns the advice on a configuration that is valid, but likely not what was intended.
+// Unlike the errors of Validate, the warnings do not block the reconciliation.
+// This should be called after FillDefaults and Bootstrap.
+func ValidationWarnings(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult) []string {
+	warnings := []string{}
+	warnings = append(warnings, mtuWarnings(conf, bootstrapResult)...)
+	warnings = append(warnings, masterWarnings(conf, bootstrapResult)...)
+	warnings = append(warnings, externalCIDRWarnings(conf, bootstrapResult)...)
+	return warnings
+}
+
+// mtuWarnings warns when the MTU of the pod network, with the overhead of the overlay, exceeds the lowest
+// uplink MTU reported by the nodes, so that the largest packets are dropped or fragmented on that node, or
+// when it is below the one the uplinks allow, which lowers the throughput of the pods for nothing
+func mtuWarnings(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult) []string {
+	nodeMTU := bootstrapResult.UplinkMTU
+	if nodeMTU == 0 {
+		return nil
+	}
+	var mtu *uint32
+	var overhead uint32
+	switch conf.DefaultNetwork.Type {
+	case operv1.NetworkTypeOVNKubernetes:
+		if c := conf.DefaultNetwork.OVNKubernetesConfig; c != nil {
+			mtu, overhead = c.MTU, getOVNEncapOverhead(conf)
+		}
+	case operv1.NetworkTypeOpenShiftSDN:
+		if c := conf.DefaultNetwork.OpenShiftSDNConfig; c != nil {
+			mtu, overhead = c.MTU, sdnOverhead
+		}
+	}
+	if mtu == nil || int(overhead) >= nodeMTU {
+		return nil
+	}
+	recommended := uint32(nodeMTU) - overhead
+	if *mtu > recommended {
+		return []string{fmt.Sprintf("the MTU %d of the pod network, with %d bytes of overhead of the overlay, exceeds the uplink MTU %d of node %s",
+			*mtu, overhead, nodeMTU, bootstrapResult.UplinkMTUNode)}
+	}
+	// a migration lowers the MTU temporarily
+	if *mtu >= recommended || (conf.Migration != nil && conf.Migration.MTU != nil) {
+		return nil
+	}
+	return []string{fmt.Sprintf("the MTU %d of the pod network is smaller than the recommended %d, the uplink MTU %d of the nodes without the overhead of the overlay",
+		*mtu, recommended, nodeMTU)}
+}
+
+// masterWarnings warns when the OVN databases of a highly available control plane run on a single master,
+// and are unavailable while it is down
+func masterWarnings(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult) []string {
+	if conf.DefaultNetwork.Type != operv1.NetworkTypeOVNKubernetes || bootstrapResult.Infra.ExternalControlPlane ||
+		bootstrapResult.Infra.ControlPlaneTopology != configv1.HighlyAvailableTopologyMode ||
+		len(bootstrapResult.OVN.MasterIPs) != 1 {
+		return nil
+	}
+	return []string{fmt.Sprintf("the OVN databases run on the single master %s of a highly available control plane, the pod network is not updated while it is down",
+		bootstrapResult.OVN.MasterIPs[0])}
+}
+
+// externalCIDRWarnings warns when the CIDRs routed to the cluster from the outside, the addresses of the
+// EgressSNATPools and the hybrid cluster networks, overlap each other, as the replies to the pods are then
+// routed to either
+func externalCIDRWarnings(conf *operv1.NetworkSpec, bootstrapResult *bootstrap.BootstrapResult) []string {
+	type externalCIDR struct {
+		owner string
+		cidr  *net.IPNet
+	}
+	cidrs := []externalCIDR{}
+	if c := conf.DefaultNetwork.OVNKubernetesConfig; c != nil && c.HybridOverlayConfig != nil {
+		for _, hcn := range c.HybridOverlayConfig.HybridClusterNetwork {
+			if _, cidr, err := net.ParseCIDR(hcn.CIDR); err == nil {
+				cidrs = append(cidrs, externalCIDR{owner: "hybridClusterNetwork", cidr: cidr})
+			}
+		}
+	}
+	if bootstrapResult.OVN.OVNKubernetesConfig != nil {
+		for _, pool := range bootstrapResult.OVN.OVNKubernetesConfig.EgressSNATPools {
+			// the addresses of an IP family are listed for each cluster network of the family
+			clusterNetworks := make([]string, 0, len(pool.Addresses))
+			for cn := range pool.Addresses {
+				clusterNetworks = append(clusterNetworks, cn)
+			}
+			sort.Strings(clusterNetworks)
+			seen := map[string]bool{}
+			for _, cn := range clusterNetworks {
+				for _, address := range strings.Split(pool.Addresses[cn], ",") {
+					_, cidr, err := net.ParseCIDR(address)
+					if err != nil || seen[cidr.String()] {
+						continue
+					}
+					seen[cidr.String()] = true
+					cidrs = append(cidrs, externalCIDR{owner: "EgressSNATPool " + pool.Name, cidr: cidr})
+				}
+			}
+		}
+	}
+
+	warnings := []string{}
+	for i := range cidrs {
+		for j := i + 1; j < len(cidrs); j++ {
+			if cidrs[i].owner != cidrs[j].owner && iputil.NetsOverlap(*cidrs[i].cidr, *cidrs[j].cidr) {
+				warnings = append(warnings, fmt.Sprintf("%s of %s overlaps %s of %s, which are both routed to the cluster",
+					cidrs[i].cidr, cidrs[i].owner, cidrs[j].cidr, cidrs[j].owner))
+			}
+		}
+	}
+	return warnings
+}
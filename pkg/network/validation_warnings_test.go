@@ -0,0 +1,68 @@
+package network
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestValidationWarnings(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	crd := OVNKubernetesConfig.DeepCopy()
+	config := &crd.Spec
+	FillDefaultsWithMTU(config, nil, 9000)
+	bootstrapResult := &bootstrap.BootstrapResult{
+		Infra: bootstrap.InfraBootstrapResult{ControlPlaneTopology: configv1.HighlyAvailableTopologyMode},
+		OVN: bootstrap.OVNBootstrapResult{
+			MasterIPs:           []string{"1.2.3.4", "1.2.3.5", "1.2.3.6"},
+			OVNKubernetesConfig: &bootstrap.OVNConfigBoostrapResult{},
+		},
+	}
+	// the MTU of the nodes is unknown
+	g.Expect(ValidationWarnings(config, bootstrapResult)).To(BeEmpty())
+	bootstrapResult.UplinkMTU, bootstrapResult.UplinkMTUNode = 9000, "worker-0"
+	g.Expect(ValidationWarnings(config, bootstrapResult)).To(BeEmpty())
+
+	// the pods do not use the MTU the nodes allow, unless migrating it
+	bootstrapResult.UplinkMTU = 9100
+	g.Expect(ValidationWarnings(config, bootstrapResult)).To(Equal([]string{
+		"the MTU 8900 of the pod network is smaller than the recommended 9000, the uplink MTU 9100 of the nodes without the overhead of the overlay",
+	}))
+	config.Migration = &operv1.NetworkMigration{MTU: &operv1.MTUMigration{}}
+	g.Expect(ValidationWarnings(config, bootstrapResult)).To(BeEmpty())
+	config.Migration = nil
+
+	// the largest packets of the pods are dropped or fragmented on a node
+	bootstrapResult.UplinkMTU = 1500
+	g.Expect(ValidationWarnings(config, bootstrapResult)).To(Equal([]string{
+		"the MTU 8900 of the pod network, with 100 bytes of overhead of the overlay, exceeds the uplink MTU 1500 of node worker-0",
+	}))
+	bootstrapResult.UplinkMTU = 9000
+
+	// a single master of a highly available control plane, but not of a single-node cluster
+	bootstrapResult.OVN.MasterIPs = []string{"1.2.3.4"}
+	g.Expect(ValidationWarnings(config, bootstrapResult)).To(Equal([]string{
+		"the OVN databases run on the single master 1.2.3.4 of a highly available control plane, the pod network is not updated while it is down",
+	}))
+	bootstrapResult.Infra.ControlPlaneTopology = configv1.SingleReplicaTopologyMode
+	g.Expect(ValidationWarnings(config, bootstrapResult)).To(BeEmpty())
+
+	// the addresses of EgressSNATPools and the hybrid cluster networks routed to the cluster overlap
+	config.ClusterNetwork = append(config.ClusterNetwork, operv1.ClusterNetworkEntry{CIDR: "10.130.0.0/15", HostPrefix: 23})
+	config.DefaultNetwork.OVNKubernetesConfig.HybridOverlayConfig = &operv1.HybridOverlayConfig{
+		HybridClusterNetwork: []operv1.ClusterNetworkEntry{{CIDR: "192.168.0.0/16", HostPrefix: 24}},
+	}
+	bootstrapResult.OVN.OVNKubernetesConfig.EgressSNATPools = []bootstrap.EgressSNATPool{
+		{Name: "a", Addresses: map[string]string{"10.128.0.0/15": "172.16.0.0/24,192.168.10.1/32", "10.130.0.0/15": "172.16.0.0/24,192.168.10.1/32"}},
+		{Name: "b", Addresses: map[string]string{"10.128.0.0/15": "172.16.0.128/25,172.17.0.0/24"}},
+	}
+	g.Expect(ValidationWarnings(config, bootstrapResult)).To(Equal([]string{
+		"192.168.0.0/16 of hybridClusterNetwork overlaps 192.168.10.1/32 of EgressSNATPool a, which are both routed to the cluster",
+		"172.16.0.0/24 of EgressSNATPool a overlaps 172.16.0.128/25 of EgressSNATPool b, which are both routed to the cluster",
+	}))
+}
@@ -3,7 +3,10 @@ package platform
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"sync"
 
+	yaml "github.com/ghodss/yaml"
 	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
 
@@ -13,9 +16,48 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-var cloudProviderConfig = types.NamespacedName{
-	Namespace: "openshift-config-managed",
-	Name:      "kube-cloud-config",
+// the ConfigMap holding the install-config of the cluster
+const (
+	clusterConfigNamespace = "kube-system"
+	clusterConfigName      = "cluster-config-v1"
+)
+
+// Provider supplies the bootstrap data specific to a type of platform.
+type Provider interface {
+	// Bootstrap fills in the platform-specific fields of res, from the infrastructure
+	// configuration of the cluster.
+	Bootstrap(kubeClient client.Reader, infraConfig *configv1.Infrastructure, res *bootstrap.InfraBootstrapResult) error
+}
+
+var (
+	providersLock sync.RWMutex
+	providers     = map[configv1.PlatformType]Provider{
+		configv1.AWSPlatformType:       awsProvider{},
+		configv1.AzurePlatformType:     azureProvider{},
+		configv1.GCPPlatformType:       gcpProvider{},
+		configv1.OpenStackPlatformType: onPremProvider{},
+		configv1.BareMetalPlatformType: onPremProvider{},
+		configv1.OvirtPlatformType:     onPremProvider{},
+		configv1.VSpherePlatformType:   onPremProvider{},
+	}
+)
+
+// RegisterProvider sets the provider of a type of platform, replacing the existing one, if any.
+// The platforms without a provider, including None, use the external provider, which supplies
+// no platform-specific data.
+func RegisterProvider(platformType configv1.PlatformType, provider Provider) {
+	providersLock.Lock()
+	defer providersLock.Unlock()
+	providers[platformType] = provider
+}
+
+func providerFor(platformType configv1.PlatformType) Provider {
+	providersLock.RLock()
+	defer providersLock.RUnlock()
+	if provider, ok := providers[platformType]; ok {
+		return provider
+	}
+	return externalProvider{}
 }
 
 func BootstrapInfra(kubeClient client.Client) (*bootstrap.InfraBootstrapResult, error) {
@@ -25,28 +67,78 @@ func BootstrapInfra(kubeClient client.Client) (*bootstrap.InfraBootstrapResult,
 	}
 
 	res := &bootstrap.InfraBootstrapResult{
-		PlatformType:         infraConfig.Status.PlatformStatus.Type,
-		PlatformStatus:       infraConfig.Status.PlatformStatus,
-		ExternalControlPlane: infraConfig.Status.ControlPlaneTopology == configv1.ExternalTopologyMode,
-	}
-
-	if res.PlatformType == configv1.AWSPlatformType {
-		res.PlatformRegion = infraConfig.Status.PlatformStatus.AWS.Region
-	} else if res.PlatformType == configv1.GCPPlatformType {
-		res.PlatformRegion = infraConfig.Status.PlatformStatus.GCP.Region
-	}
-
-	// AWS specifies a CA bundle via a config map; retrieve it.
-	if res.PlatformType == configv1.AWSPlatformType {
-		cm := &corev1.ConfigMap{}
-		if err := kubeClient.Get(context.TODO(), cloudProviderConfig, cm); err != nil {
-			if !apierrors.IsNotFound(err) {
-				return nil, fmt.Errorf("failed to retrieve ConfigMap %s: %w", cloudProviderConfig, err)
-			}
-		} else {
-			res.KubeCloudConfig = cm.Data
-		}
+		PlatformType:           infraConfig.Status.PlatformStatus.Type,
+		PlatformStatus:         infraConfig.Status.PlatformStatus,
+		ExternalControlPlane:   infraConfig.Status.ControlPlaneTopology == configv1.ExternalTopologyMode,
+		ControlPlaneTopology:   infraConfig.Status.ControlPlaneTopology,
+		InfrastructureTopology: infraConfig.Status.InfrastructureTopology,
 	}
 
+	if err := providerFor(res.PlatformType).Bootstrap(kubeClient, infraConfig, res); err != nil {
+		return nil, fmt.Errorf("failed to bootstrap platform %s: %w", res.PlatformType, err)
+	}
+	fips, err := bootstrapFIPS(kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	res.FIPS = fips
+	tlsProfile, err := bootstrapTLSProfile(kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	res.TLSProfile = tlsProfile
 	return res, nil
 }
+
+// bootstrapFIPS returns whether the cluster was installed in FIPS mode, which can only be chosen at install time
+func bootstrapFIPS(kubeClient client.Reader) (bool, error) {
+	clusterConfig := &corev1.ConfigMap{}
+	if err := kubeClient.Get(context.TODO(), types.NamespacedName{Namespace: clusterConfigNamespace, Name: clusterConfigName}, clusterConfig); err != nil {
+		// the clusters with an external control plane have no install-config
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get the install-config: %w", err)
+	}
+	installConfig := struct {
+		FIPS bool `json:"fips"`
+	}{}
+	if err := yaml.Unmarshal([]byte(clusterConfig.Data["install-config"]), &installConfig); err != nil {
+		return false, fmt.Errorf("failed to parse the install-config: %w", err)
+	}
+	return installConfig.FIPS, nil
+}
+
+// bootstrapTLSProfile returns the TLS security profile of the API server, or nil when it keeps the default
+// Intermediate profile
+func bootstrapTLSProfile(kubeClient client.Reader) (*configv1.TLSProfileSpec, error) {
+	apiServer := &configv1.APIServer{}
+	if err := kubeClient.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, apiServer); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get apiserver 'cluster': %w", err)
+	}
+	profile := apiServer.Spec.TLSSecurityProfile
+	if profile == nil {
+		return nil, nil
+	}
+	if profile.Type == configv1.TLSProfileCustomType {
+		if profile.Custom == nil {
+			return nil, nil
+		}
+		return profile.Custom.TLSProfileSpec.DeepCopy(), nil
+	}
+	if spec, ok := configv1.TLSProfiles[profile.Type]; ok {
+		return spec.DeepCopy(), nil
+	}
+	return nil, nil
+}
+
+// TopologyChanged returns whether the topology, or the platform, of the cluster changed between two
+// versions of the infrastructure configuration, which then has to be bootstrapped and rendered again.
+func TopologyChanged(old, new *configv1.Infrastructure) bool {
+	return old.Status.ControlPlaneTopology != new.Status.ControlPlaneTopology ||
+		old.Status.InfrastructureTopology != new.Status.InfrastructureTopology ||
+		!reflect.DeepEqual(old.Status.PlatformStatus, new.Status.PlatformStatus)
+}
@@ -1,11 +1,16 @@
 package platform
 
 import (
+	"fmt"
+	"reflect"
 	"testing"
 
 	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -58,3 +63,226 @@ func TestTopologyModeDetection(t *testing.T) {
 		})
 	}
 }
+
+func TestBootstrapInfraProviders(t *testing.T) {
+	if err := configv1.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("failed to add configv1 to scheme: %v", err)
+	}
+
+	infra := func(status configv1.PlatformStatus) *configv1.Infrastructure {
+		return &configv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Status:     configv1.InfrastructureStatus{PlatformStatus: &status},
+		}
+	}
+
+	// AWS
+	cloudConfig := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config-managed", Name: "kube-cloud-config"},
+		Data:       map[string]string{"ca-bundle.pem": "CA"},
+	}
+	client := fake.NewClientBuilder().WithObjects(infra(configv1.PlatformStatus{
+		Type: configv1.AWSPlatformType,
+		AWS:  &configv1.AWSPlatformStatus{Region: "us-east-1"},
+	}), cloudConfig).Build()
+	res, err := BootstrapInfra(client)
+	if err != nil {
+		t.Fatalf("BootstrapInfra failed: %v", err)
+	}
+	if res.PlatformRegion != "us-east-1" || res.KubeCloudConfig["ca-bundle.pem"] != "CA" {
+		t.Errorf("unexpected AWS bootstrap result: %+v", res)
+	}
+
+	// GCP
+	client = fake.NewClientBuilder().WithObjects(infra(configv1.PlatformStatus{
+		Type: configv1.GCPPlatformType,
+		GCP:  &configv1.GCPPlatformStatus{Region: "us-central1"},
+	})).Build()
+	res, err = BootstrapInfra(client)
+	if err != nil {
+		t.Fatalf("BootstrapInfra failed: %v", err)
+	}
+	if res.PlatformRegion != "us-central1" || !reflect.DeepEqual(res.LoadBalancerHealthCheckSources, gcpLoadBalancerHealthCheckSources) {
+		t.Errorf("unexpected GCP bootstrap result: %+v", res)
+	}
+
+	// BareMetal
+	client = fake.NewClientBuilder().WithObjects(infra(configv1.PlatformStatus{
+		Type:      configv1.BareMetalPlatformType,
+		BareMetal: &configv1.BareMetalPlatformStatus{APIServerInternalIP: "192.168.111.5", IngressIP: "192.168.111.4"},
+	})).Build()
+	res, err = BootstrapInfra(client)
+	if err != nil {
+		t.Fatalf("BootstrapInfra failed: %v", err)
+	}
+	if res.APIServerInternalIP != "192.168.111.5" || res.IngressIP != "192.168.111.4" || !res.SelfHostedLoadBalancer {
+		t.Errorf("unexpected BareMetal bootstrap result: %+v", res)
+	}
+
+	// None
+	client = fake.NewClientBuilder().WithObjects(infra(configv1.PlatformStatus{
+		Type: configv1.NonePlatformType,
+	})).Build()
+	res, err = BootstrapInfra(client)
+	if err != nil {
+		t.Fatalf("BootstrapInfra failed: %v", err)
+	}
+	if res.PlatformType != configv1.NonePlatformType || res.PlatformRegion != "" || res.SelfHostedLoadBalancer {
+		t.Errorf("unexpected None bootstrap result: %+v", res)
+	}
+
+	// a registered provider replaces the built-in one
+	RegisterProvider(configv1.NonePlatformType, fakeProvider{region: "local"})
+	defer RegisterProvider(configv1.NonePlatformType, externalProvider{})
+	res, err = BootstrapInfra(client)
+	if err != nil {
+		t.Fatalf("BootstrapInfra failed: %v", err)
+	}
+	if res.PlatformRegion != "local" {
+		t.Errorf("expected the registered provider to be used, got: %+v", res)
+	}
+
+	RegisterProvider(configv1.NonePlatformType, fakeProvider{err: fmt.Errorf("no cloud")})
+	if _, err = BootstrapInfra(client); err == nil {
+		t.Errorf("expected the error of the provider")
+	}
+}
+
+type fakeProvider struct {
+	region string
+	err    error
+}
+
+func (p fakeProvider) Bootstrap(_ client.Reader, _ *configv1.Infrastructure, res *bootstrap.InfraBootstrapResult) error {
+	res.PlatformRegion = p.region
+	return p.err
+}
+
+func TestTopologyChanged(t *testing.T) {
+	infra := func(controlPlane, infrastructure configv1.TopologyMode, platform configv1.PlatformType) *configv1.Infrastructure {
+		return &configv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Status: configv1.InfrastructureStatus{
+				PlatformStatus:         &configv1.PlatformStatus{Type: platform},
+				ControlPlaneTopology:   controlPlane,
+				InfrastructureTopology: infrastructure,
+			},
+		}
+	}
+	sno := infra(configv1.SingleReplicaTopologyMode, configv1.SingleReplicaTopologyMode, configv1.NonePlatformType)
+
+	testCases := []struct {
+		name     string
+		new      *configv1.Infrastructure
+		expected bool
+	}{
+		{"unchanged", infra(configv1.SingleReplicaTopologyMode, configv1.SingleReplicaTopologyMode, configv1.NonePlatformType), false},
+		{"expanded single node", infra(configv1.SingleReplicaTopologyMode, configv1.HighlyAvailableTopologyMode, configv1.NonePlatformType), true},
+		{"externalized control plane", infra(configv1.ExternalTopologyMode, configv1.SingleReplicaTopologyMode, configv1.NonePlatformType), true},
+		{"platform", infra(configv1.SingleReplicaTopologyMode, configv1.SingleReplicaTopologyMode, configv1.BareMetalPlatformType), true},
+	}
+	for _, tc := range testCases {
+		if changed := TopologyChanged(sno, tc.new); changed != tc.expected {
+			t.Errorf("%s: expected the topology change to be %t, was %t", tc.name, tc.expected, changed)
+		}
+	}
+}
+
+func TestBootstrapFIPS(t *testing.T) {
+	if err := configv1.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("failed to add configv1 to scheme: %v", err)
+	}
+	infrastructure := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status:     configv1.InfrastructureStatus{PlatformStatus: &configv1.PlatformStatus{}},
+	}
+	clusterConfig := func(installConfig string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: clusterConfigNamespace, Name: clusterConfigName},
+			Data:       map[string]string{"install-config": installConfig},
+		}
+	}
+
+	testCases := []struct {
+		name       string
+		objs       []client.Object
+		expectFIPS bool
+		expectErr  bool
+	}{
+		{name: "no install-config", objs: []client.Object{infrastructure}},
+		{name: "FIPS", objs: []client.Object{infrastructure, clusterConfig("fips: true\ncontrolPlane:\n  replicas: 3\n")}, expectFIPS: true},
+		{name: "not FIPS", objs: []client.Object{infrastructure, clusterConfig("controlPlane:\n  replicas: 3\n")}},
+		{name: "invalid install-config", objs: []client.Object{infrastructure, clusterConfig("fips: [")}, expectErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := BootstrapInfra(fake.NewClientBuilder().WithObjects(tc.objs...).Build())
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("expected BootstrapInfra to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BootstrapInfra failed: %v", err)
+			}
+			if res.FIPS != tc.expectFIPS {
+				t.Errorf("expected FIPS to be %t, was %t", tc.expectFIPS, res.FIPS)
+			}
+		})
+	}
+}
+
+func TestBootstrapTLSProfile(t *testing.T) {
+	if err := configv1.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("failed to add configv1 to scheme: %v", err)
+	}
+	infrastructure := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status:     configv1.InfrastructureStatus{PlatformStatus: &configv1.PlatformStatus{}},
+	}
+	apiServer := func(profile *configv1.TLSSecurityProfile) *configv1.APIServer {
+		return &configv1.APIServer{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Spec:       configv1.APIServerSpec{TLSSecurityProfile: profile},
+		}
+	}
+	custom := configv1.TLSProfileSpec{Ciphers: []string{"ECDHE-RSA-AES128-GCM-SHA256"}, MinTLSVersion: configv1.VersionTLS12}
+
+	testCases := []struct {
+		name          string
+		objs          []client.Object
+		expectProfile *configv1.TLSProfileSpec
+	}{
+		{name: "no apiserver", objs: []client.Object{infrastructure}},
+		{name: "no profile", objs: []client.Object{infrastructure, apiServer(nil)}},
+		{
+			name:          "old",
+			objs:          []client.Object{infrastructure, apiServer(&configv1.TLSSecurityProfile{Type: configv1.TLSProfileOldType})},
+			expectProfile: configv1.TLSProfiles[configv1.TLSProfileOldType],
+		},
+		{
+			name: "custom",
+			objs: []client.Object{infrastructure, apiServer(&configv1.TLSSecurityProfile{
+				Type:   configv1.TLSProfileCustomType,
+				Custom: &configv1.CustomTLSProfile{TLSProfileSpec: custom},
+			})},
+			expectProfile: &custom,
+		},
+		{
+			name: "custom without spec",
+			objs: []client.Object{infrastructure, apiServer(&configv1.TLSSecurityProfile{Type: configv1.TLSProfileCustomType})},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := BootstrapInfra(fake.NewClientBuilder().WithObjects(tc.objs...).Build())
+			if err != nil {
+				t.Fatalf("BootstrapInfra failed: %v", err)
+			}
+			if !reflect.DeepEqual(res.TLSProfile, tc.expectProfile) {
+				t.Errorf("expected TLS profile %v, was %v", tc.expectProfile, res.TLSProfile)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	types "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var cloudProviderConfig = types.NamespacedName{
+	Namespace: "openshift-config-managed",
+	Name:      "kube-cloud-config",
+}
+
+// The source ranges of the health checks of the cloud load balancers. The health checks of the AWS
+// load balancers come from the load balancer nodes, within the VPC of the cluster.
+var (
+	// https://cloud.google.com/load-balancing/docs/health-check-concepts#ip-ranges
+	gcpLoadBalancerHealthCheckSources = []string{"35.191.0.0/16", "130.211.0.0/22", "209.85.152.0/22", "209.85.204.0/22"}
+	// https://docs.microsoft.com/en-us/azure/virtual-network/what-is-ip-address-168-63-129-16
+	azureLoadBalancerHealthCheckSources = []string{"168.63.129.16/32"}
+)
+
+type awsProvider struct{}
+
+func (awsProvider) Bootstrap(kubeClient client.Reader, infraConfig *configv1.Infrastructure, res *bootstrap.InfraBootstrapResult) error {
+	if aws := infraConfig.Status.PlatformStatus.AWS; aws != nil {
+		res.PlatformRegion = aws.Region
+	}
+	res.EgressIPCapacityLimited = true
+
+	// AWS specifies a CA bundle via a config map; retrieve it.
+	cm := &corev1.ConfigMap{}
+	if err := kubeClient.Get(context.TODO(), cloudProviderConfig, cm); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to retrieve ConfigMap %s: %w", cloudProviderConfig, err)
+		}
+	} else {
+		res.KubeCloudConfig = cm.Data
+	}
+	return nil
+}
+
+type azureProvider struct{}
+
+func (azureProvider) Bootstrap(_ client.Reader, _ *configv1.Infrastructure, res *bootstrap.InfraBootstrapResult) error {
+	res.LoadBalancerHealthCheckSources = azureLoadBalancerHealthCheckSources
+	res.EgressIPCapacityLimited = true
+	return nil
+}
+
+type gcpProvider struct{}
+
+func (gcpProvider) Bootstrap(_ client.Reader, infraConfig *configv1.Infrastructure, res *bootstrap.InfraBootstrapResult) error {
+	if gcp := infraConfig.Status.PlatformStatus.GCP; gcp != nil {
+		res.PlatformRegion = gcp.Region
+	}
+	res.LoadBalancerHealthCheckSources = gcpLoadBalancerHealthCheckSources
+	res.EgressIPCapacityLimited = true
+	return nil
+}
+
+// onPremProvider supplies the VIPs of the on-premise platforms, where the API and ingress
+// are load-balanced by the nodes themselves.
+type onPremProvider struct{}
+
+func (onPremProvider) Bootstrap(_ client.Reader, infraConfig *configv1.Infrastructure, res *bootstrap.InfraBootstrapResult) error {
+	status := infraConfig.Status.PlatformStatus
+	switch {
+	case status.BareMetal != nil:
+		res.APIServerInternalIP, res.IngressIP = status.BareMetal.APIServerInternalIP, status.BareMetal.IngressIP
+	case status.OpenStack != nil:
+		res.APIServerInternalIP, res.IngressIP = status.OpenStack.APIServerInternalIP, status.OpenStack.IngressIP
+	case status.Ovirt != nil:
+		res.APIServerInternalIP, res.IngressIP = status.Ovirt.APIServerInternalIP, status.Ovirt.IngressIP
+	case status.VSphere != nil:
+		res.APIServerInternalIP, res.IngressIP = status.VSphere.APIServerInternalIP, status.VSphere.IngressIP
+	}
+	// Without VIPs, e.g. on user-provisioned infrastructure, the load balancers are external
+	res.SelfHostedLoadBalancer = res.APIServerInternalIP != ""
+	return nil
+}
+
+// externalProvider is used for the platforms without a provider, whose infrastructure is
+// managed outside of the cluster.
+type externalProvider struct{}
+
+func (externalProvider) Bootstrap(_ client.Reader, _ *configv1.Infrastructure, _ *bootstrap.InfraBootstrapResult) error {
+	return nil
+}
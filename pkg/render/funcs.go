@@ -1,7 +1,13 @@
 package render
 
 import (
+	"fmt"
+	"math/big"
+	"net"
+	"reflect"
 	"strings"
+
+	"github.com/ghodss/yaml"
 )
 
 // Functions available for all templates
@@ -38,3 +44,60 @@ func isSet(m map[string]interface{}, key string) interface{} {
 func iniEscapeCharacters(text string) string {
 	return strings.ReplaceAll(text, "$", "\\$")
 }
+
+// cidrHost returns the address of the host with the given number in the
+// network. Negative numbers count back from the end of the network, -1 being
+// the broadcast address on IPv4.
+func cidrHost(cidr string, num int) (string, error) {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return "", err
+	}
+	ones, bits := ipNet.Mask.Size()
+	size := new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))
+	n := big.NewInt(int64(num))
+	if num < 0 {
+		n.Add(n, size)
+	}
+	if n.Sign() < 0 || n.Cmp(size) >= 0 {
+		return "", fmt.Errorf("cidrHost: %s has no host number %d", cidr, num)
+	}
+
+	ip := ipNet.IP.To16()
+	if bits == 32 {
+		ip = ipNet.IP.To4()
+	}
+	n.Add(n, new(big.Int).SetBytes(ip))
+	out := make(net.IP, len(ip))
+	n.FillBytes(out)
+	return out.String(), nil
+}
+
+// toYAML returns the YAML representation of v, without the trailing newline,
+// to be embedded with indent or nindent
+func toYAML(v interface{}) (string, error) {
+	out, err := yaml.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// required returns val, or fails the rendering with msg if val is nil or empty
+func required(msg string, val interface{}) (interface{}, error) {
+	if val == nil {
+		return nil, fmt.Errorf("required: %s", msg)
+	}
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		if v.Len() == 0 {
+			return nil, fmt.Errorf("required: %s", msg)
+		}
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, fmt.Errorf("required: %s", msg)
+		}
+	}
+	return val, nil
+}
@@ -2,12 +2,16 @@ package render
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"text/template"
+	"unicode"
 
 	"github.com/Masterminds/sprig"
 	"github.com/pkg/errors"
@@ -19,15 +23,51 @@ import (
 type RenderData struct {
 	Funcs template.FuncMap
 	Data  map[string]interface{}
+	// Strict rejects the manifests that print a value that is not set, as
+	// "<no value>", rather than applying them.
+	Strict bool
 }
 
 func MakeRenderData() RenderData {
 	return RenderData{
-		Funcs: template.FuncMap{},
-		Data:  map[string]interface{}{},
+		Funcs:  template.FuncMap{},
+		Data:   map[string]interface{}{},
+		Strict: true,
 	}
 }
 
+// TemplateError is returned when a manifest cannot be rendered. Line, when
+// known, is the line of the template for the read, parse and render errors, and
+// the line of the rendered manifest for the others.
+type TemplateError struct {
+	// Op is what failed: read, parse, render or unmarshal
+	Op   string
+	Path string
+	Line int
+	Err  error
+}
+
+func (e *TemplateError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("failed to %s manifest %s at line %d: %v", e.Op, e.Path, e.Line, e.Err)
+	}
+	return fmt.Sprintf("failed to %s manifest %s: %v", e.Op, e.Path, e.Err)
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// noValue is what text/template prints for a value that is not set
+const noValue = "<no value>"
+
+var (
+	// templateLineRE matches the location text/template prefixes its errors with
+	templateLineRE = regexp.MustCompile(`^template: [^\n]*?:(\d+):`)
+	// yamlLineRE matches the location of the YAML syntax errors
+	yamlLineRE = regexp.MustCompile(`yaml: line (\d+):`)
+)
+
 // RenderDir will render all manifests in a directory, descending in to subdirectories
 // It will perform template substitutions based on the data supplied by the RenderData
 func RenderDir(manifestDir string, d *RenderData) ([]*unstructured.Unstructured, error) {
@@ -68,21 +108,22 @@ func RenderTemplate(path string, d *RenderData) ([]*unstructured.Unstructured, e
 	}
 
 	// Add universal functions
-	tmpl.Funcs(template.FuncMap{"getOr": getOr, "isSet": isSet, "iniEscapeCharacters": iniEscapeCharacters})
+	tmpl.Funcs(template.FuncMap{"getOr": getOr, "isSet": isSet, "iniEscapeCharacters": iniEscapeCharacters,
+		"cidrHost": cidrHost, "toYAML": toYAML, "required": required})
 	tmpl.Funcs(sprig.TxtFuncMap())
 
 	source, err := ioutil.ReadFile(path)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to read manifest %s", path)
+		return nil, &TemplateError{Op: "read", Path: path, Err: err}
 	}
 
 	if _, err := tmpl.Parse(string(source)); err != nil {
-		return nil, errors.Wrapf(err, "failed to parse manifest %s as template", path)
+		return nil, &TemplateError{Op: "parse", Path: path, Line: matchLine(templateLineRE, err), Err: err}
 	}
 
 	rendered := bytes.Buffer{}
 	if err := tmpl.Execute(&rendered, d.Data); err != nil {
-		return nil, errors.Wrapf(err, "failed to render manifest %s", path)
+		return nil, &TemplateError{Op: "render", Path: path, Line: matchLine(templateLineRE, err), Err: err}
 	}
 
 	out := []*unstructured.Unstructured{}
@@ -92,17 +133,69 @@ func RenderTemplate(path string, d *RenderData) ([]*unstructured.Unstructured, e
 		return out, nil
 	}
 
-	decoder := yaml.NewYAMLOrJSONDecoder(&rendered, 4096)
-	for {
-		u := unstructured.Unstructured{}
-		if err := decoder.Decode(&u); err != nil {
-			if err == io.EOF {
-				break
+	if d.Strict {
+		for i, line := range strings.Split(rendered.String(), "\n") {
+			if strings.Contains(line, noValue) {
+				return nil, &TemplateError{Op: "render", Path: path, Line: i + 1,
+					Err: errors.Errorf("a value is not set: %s", strings.TrimSpace(line))}
+			}
+		}
+	}
+
+	for _, doc := range splitDocuments(rendered.Bytes()) {
+		decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(doc.data), 4096)
+		for {
+			u := unstructured.Unstructured{}
+			if err := decoder.Decode(&u); err != nil {
+				if err == io.EOF {
+					break
+				}
+				// point at the start of the document when the error has no line
+				line := doc.line
+				if l := matchLine(yamlLineRE, err); l > 0 {
+					line += l - 1
+				}
+				return nil, &TemplateError{Op: "unmarshal", Path: path, Line: line, Err: err}
+			}
+			// skip the empty documents, like a leading comment before the first separator
+			if len(u.Object) == 0 {
+				continue
 			}
-			return nil, errors.Wrapf(err, "failed to unmarshal manifest %s", path)
+			out = append(out, &u)
 		}
-		out = append(out, &u)
 	}
 
 	return out, nil
 }
+
+// document is a YAML document of a rendered manifest, starting at line
+type document struct {
+	line int
+	data []byte
+}
+
+// splitDocuments splits a rendered manifest on the "---" separators, as the
+// YAML reader of the decoder does, keeping track of the line of every document.
+func splitDocuments(rendered []byte) []document {
+	docs := []document{}
+	current := document{line: 1}
+	for i, line := range bytes.SplitAfter(rendered, []byte("\n")) {
+		if bytes.HasPrefix(line, []byte("---")) && len(bytes.TrimRightFunc(line[3:], unicode.IsSpace)) == 0 {
+			docs = append(docs, current)
+			current = document{line: i + 2}
+			continue
+		}
+		current.data = append(current.data, line...)
+	}
+	return append(docs, current)
+}
+
+// matchLine returns the line number captured by re in the error message, or 0
+func matchLine(re *regexp.Regexp, err error) int {
+	m := re.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0
+	}
+	line, _ := strconv.Atoi(m[1])
+	return line
+}
@@ -1,6 +1,9 @@
 package render
 
 import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -96,3 +99,101 @@ func TestRenderDir(t *testing.T) {
 	g.Expect(err).NotTo(HaveOccurred())
 	g.Expect(o).To(HaveLen(6))
 }
+
+func writeTemplate(t *testing.T, source string) string {
+	p := filepath.Join(t.TempDir(), "manifest.yaml")
+	if err := ioutil.WriteFile(p, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestRenderStrict(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	d := MakeRenderData()
+	d.Data["Unset"] = nil
+
+	// a value that is not set
+	p := writeTemplate(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{.Unset}}\n")
+	_, err := RenderTemplate(p, &d)
+	var tmplErr *TemplateError
+	g.Expect(errors.As(err, &tmplErr)).To(BeTrue())
+	g.Expect(tmplErr.Op).To(Equal("render"))
+	g.Expect(tmplErr.Line).To(Equal(4))
+	g.Expect(err.Error()).To(ContainSubstring("name: <no value>"))
+
+	d.Strict = false
+	o, err := RenderTemplate(p, &d)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(o[0].GetName()).To(Equal("<no value>"))
+	d.Strict = true
+
+	// an object without a kind, at the start of its document
+	p = writeTemplate(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n---\napiVersion: v1\nmetadata:\n  name: b\n")
+	_, err = RenderTemplate(p, &d)
+	g.Expect(errors.As(err, &tmplErr)).To(BeTrue())
+	g.Expect(tmplErr.Op).To(Equal("unmarshal"))
+	g.Expect(tmplErr.Line).To(Equal(6))
+
+	// the YAML errors point at the line of the rendered manifest
+	p = writeTemplate(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: b\n  namespace: b: c\n")
+	_, err = RenderTemplate(p, &d)
+	g.Expect(errors.As(err, &tmplErr)).To(BeTrue())
+	g.Expect(tmplErr.Op).To(Equal("unmarshal"))
+	g.Expect(tmplErr.Line).To(Equal(10))
+
+	// and the template errors at the line of the template
+	p = writeTemplate(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{.Missing}}\n")
+	_, err = RenderTemplate(p, &d)
+	g.Expect(errors.As(err, &tmplErr)).To(BeTrue())
+	g.Expect(tmplErr.Op).To(Equal("render"))
+	g.Expect(tmplErr.Line).To(Equal(4))
+	g.Expect(err.Error()).To(HaveSuffix(`has no entry for key "Missing"`))
+
+	p = writeTemplate(t, "apiVersion: v1\nkind: ConfigMap\n{{if}}\n")
+	_, err = RenderTemplate(p, &d)
+	g.Expect(errors.As(err, &tmplErr)).To(BeTrue())
+	g.Expect(tmplErr.Op).To(Equal("parse"))
+	g.Expect(tmplErr.Line).To(Equal(3))
+}
+
+func TestRenderFuncs(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	d := MakeRenderData()
+	d.Data["Labels"] = map[string]string{"app": "test", "tier": "network"}
+	d.Data["Name"] = ""
+	p := writeTemplate(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: test
+  labels:
+    {{- toYAML .Labels | nindent 4 }}
+data:
+  gateway: {{ cidrHost "10.0.0.0/24" 1 }}
+  broadcast: {{ cidrHost "10.0.0.0/24" -1 }}
+  router: {{ cidrHost "fd00::/64" 10 }}
+`)
+	o, err := RenderTemplate(p, &d)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(o[0].GetLabels()).To(Equal(map[string]string{"app": "test", "tier": "network"}))
+	g.Expect(o[0].Object["data"]).To(Equal(map[string]interface{}{
+		"gateway":   "10.0.0.1",
+		"broadcast": "10.0.0.255",
+		"router":    "fd00::a",
+	}))
+
+	_, err = cidrHost("10.0.0.0/30", 4)
+	g.Expect(err).To(HaveOccurred())
+	_, err = cidrHost("10.0.0.0/30", -5)
+	g.Expect(err).To(HaveOccurred())
+
+	p = writeTemplate(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ required \"the name must be set\" .Name }}\n")
+	_, err = RenderTemplate(p, &d)
+	g.Expect(err).To(MatchError(ContainSubstring("required: the name must be set")))
+	d.Data["Name"] = "test"
+	o, err = RenderTemplate(p, &d)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(o[0].GetName()).To(Equal("test"))
+}
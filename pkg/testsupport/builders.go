@@ -0,0 +1,139 @@
+// Package testsupport provides builders of the inputs of the renderers, with the defaults of a
+// typical self-hosted cluster, so that the render behavior can be tested without a live cluster.
+package testsupport
+
+import (
+	configv1 "github.com/openshift/api/config/v1"
+	operv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-network-operator/pkg/bootstrap"
+)
+
+// The defaults of the builders
+var (
+	DefaultClusterNetworkCIDR = "10.128.0.0/14"
+	DefaultHostPrefix         = uint32(23)
+	DefaultServiceNetwork     = "172.30.0.0/16"
+	DefaultMasterIPs          = []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}
+)
+
+// NewInfra returns the infrastructure of a cluster on the given platform, with a self-hosted
+// control plane.
+func NewInfra(platformType configv1.PlatformType) bootstrap.InfraBootstrapResult {
+	return bootstrap.InfraBootstrapResult{
+		PlatformType:   platformType,
+		PlatformStatus: &configv1.PlatformStatus{Type: platformType},
+	}
+}
+
+// NetworkSpecBuilder builds the operator configuration of a cluster. It is not filled with the
+// defaults of the operator; the renderers expect it to be, through FillDefaults.
+type NetworkSpecBuilder struct {
+	spec operv1.NetworkSpec
+}
+
+// NewNetworkSpec returns a builder of the configuration of a cluster with the given default network
+// type, a single cluster network and a single service network.
+func NewNetworkSpec(networkType operv1.NetworkType) *NetworkSpecBuilder {
+	return &NetworkSpecBuilder{
+		spec: operv1.NetworkSpec{
+			ClusterNetwork: []operv1.ClusterNetworkEntry{
+				{CIDR: DefaultClusterNetworkCIDR, HostPrefix: DefaultHostPrefix},
+			},
+			ServiceNetwork: []string{DefaultServiceNetwork},
+			DefaultNetwork: operv1.DefaultNetworkDefinition{Type: networkType},
+		},
+	}
+}
+
+// WithClusterNetwork replaces the cluster networks.
+func (b *NetworkSpecBuilder) WithClusterNetwork(entries ...operv1.ClusterNetworkEntry) *NetworkSpecBuilder {
+	b.spec.ClusterNetwork = entries
+	return b
+}
+
+// WithServiceNetwork replaces the service networks.
+func (b *NetworkSpecBuilder) WithServiceNetwork(cidrs ...string) *NetworkSpecBuilder {
+	b.spec.ServiceNetwork = cidrs
+	return b
+}
+
+// WithMultus enables or disables Multus and the additional networks.
+func (b *NetworkSpecBuilder) WithMultus(enabled bool) *NetworkSpecBuilder {
+	disabled := !enabled
+	b.spec.DisableMultiNetwork = &disabled
+	return b
+}
+
+// WithAdditionalNetworks appends additional networks.
+func (b *NetworkSpecBuilder) WithAdditionalNetworks(networks ...operv1.AdditionalNetworkDefinition) *NetworkSpecBuilder {
+	b.spec.AdditionalNetworks = append(b.spec.AdditionalNetworks, networks...)
+	return b
+}
+
+// WithDefaultNetwork applies a change to the configuration of the default network.
+func (b *NetworkSpecBuilder) WithDefaultNetwork(mutate func(*operv1.DefaultNetworkDefinition)) *NetworkSpecBuilder {
+	mutate(&b.spec.DefaultNetwork)
+	return b
+}
+
+// Build returns a copy of the configuration, the builder can be reused.
+func (b *NetworkSpecBuilder) Build() *operv1.NetworkSpec {
+	return b.spec.DeepCopy()
+}
+
+// BootstrapResultBuilder builds the state of a cluster, as the operator bootstraps it.
+type BootstrapResultBuilder struct {
+	res       bootstrap.BootstrapResult
+	ovnConfig bootstrap.OVNConfigBoostrapResult
+}
+
+// NewBootstrapResult returns a builder of the state of a cluster with three masters on a platform
+// without a provider, and the ovn-kubernetes defaults.
+func NewBootstrapResult() *BootstrapResultBuilder {
+	return &BootstrapResultBuilder{
+		res: bootstrap.BootstrapResult{
+			Infra: NewInfra(configv1.NonePlatformType),
+			OVN: bootstrap.OVNBootstrapResult{
+				MasterIPs: DefaultMasterIPs,
+			},
+		},
+		ovnConfig: bootstrap.OVNConfigBoostrapResult{
+			GatewayMode:   "shared",
+			OVSDBMode:     "legacy",
+			NodePortRange: "30000-32767",
+		},
+	}
+}
+
+// WithInfra replaces the infrastructure of the cluster.
+func (b *BootstrapResultBuilder) WithInfra(infra bootstrap.InfraBootstrapResult) *BootstrapResultBuilder {
+	b.res.Infra = infra
+	return b
+}
+
+// WithOVNMasterIPs replaces the IPs of the masters running the OVN databases.
+func (b *BootstrapResultBuilder) WithOVNMasterIPs(ips ...string) *BootstrapResultBuilder {
+	b.res.OVN.MasterIPs = ips
+	return b
+}
+
+// WithOVNConfig applies a change to the ovn-kubernetes configuration.
+func (b *BootstrapResultBuilder) WithOVNConfig(mutate func(*bootstrap.OVNConfigBoostrapResult)) *BootstrapResultBuilder {
+	mutate(&b.ovnConfig)
+	return b
+}
+
+// WithTuning replaces the tuning knobs of the operator.
+func (b *BootstrapResultBuilder) WithTuning(tuning bootstrap.OperatorTuning) *BootstrapResultBuilder {
+	b.res.Tuning = tuning
+	return b
+}
+
+// Build returns the state of the cluster. The results of several calls do not share their
+// ovn-kubernetes configuration, but do share the slices of the builder.
+func (b *BootstrapResultBuilder) Build() *bootstrap.BootstrapResult {
+	res := b.res
+	ovnConfig := b.ovnConfig
+	res.OVN.OVNKubernetesConfig = &ovnConfig
+	return &res
+}
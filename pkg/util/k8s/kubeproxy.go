@@ -1,6 +1,8 @@
 package k8s
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"net"
 	"strconv"
@@ -36,6 +38,111 @@ func MergeKubeProxyArguments(defaults, overrides map[string]operv1.ProxyArgument
 // GenerateKubeProxyConfiguration takes a set of defaults and a set of overrides in the
 // form of kube-proxy command-line arguments, and returns a YAML kube-proxy config file.
 func GenerateKubeProxyConfiguration(args map[string]operv1.ProxyArgumentList) (string, error) {
+	kpc, err := newKubeProxyConfiguration(args)
+	if err != nil {
+		return "", err
+	}
+
+	buf, err := yaml.Marshal(kpc)
+	return string(buf), err
+}
+
+// ParseKubeProxyConfiguration parses a YAML KubeProxyConfiguration, rejecting unknown fields
+func ParseKubeProxyConfiguration(config string) (*kubeproxyconfig.KubeProxyConfiguration, error) {
+	buf, err := yaml.YAMLToJSON([]byte(config))
+	if err != nil {
+		return nil, fmt.Errorf("invalid KubeProxyConfiguration: %v", err)
+	}
+	kpc := &kubeproxyconfig.KubeProxyConfiguration{}
+	decoder := json.NewDecoder(bytes.NewReader(buf))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(kpc); err != nil {
+		return nil, fmt.Errorf("invalid KubeProxyConfiguration: %v", err)
+	}
+	if (kpc.APIVersion != "" && kpc.APIVersion != "kubeproxy.config.k8s.io/v1alpha1") ||
+		(kpc.Kind != "" && kpc.Kind != "KubeProxyConfiguration") {
+		return nil, fmt.Errorf("invalid KubeProxyConfiguration: unsupported %s, %s", kpc.APIVersion, kpc.Kind)
+	}
+	return kpc, nil
+}
+
+// MergeKubeProxyConfiguration returns a YAML kube-proxy config file built from a YAML
+// KubeProxyConfiguration, layered between a set of defaults and a set of overrides in the
+// form of kube-proxy command-line arguments. The config fields take precedence over the
+// defaults, and the overrides take precedence over the config fields.
+func MergeKubeProxyConfiguration(defaults map[string]operv1.ProxyArgumentList, config string, overrides map[string]operv1.ProxyArgumentList) (string, error) {
+	kpc, err := newKubeProxyConfiguration(defaults)
+	if err != nil {
+		return "", err
+	}
+
+	// Unmarshalling only sets the fields present in the config, leaving the defaults of the others
+	if _, err := ParseKubeProxyConfiguration(config); err != nil {
+		return "", err
+	}
+	if err := yaml.Unmarshal([]byte(config), kpc); err != nil {
+		return "", err
+	}
+
+	// Likewise, only the overrides which were set are layered on top
+	okpc, err := newKubeProxyConfiguration(overrides)
+	if err != nil {
+		return "", err
+	}
+	buf, err := json.Marshal(okpc)
+	if err != nil {
+		return "", err
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(buf, &fields); err != nil {
+		return "", err
+	}
+	pruneUnsetFields(fields)
+	if buf, err = json.Marshal(fields); err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal(buf, kpc); err != nil {
+		return "", err
+	}
+
+	buf, err = yaml.Marshal(kpc)
+	return string(buf), err
+}
+
+// pruneUnsetFields removes the null, zero and empty values from a JSON object, recursively
+func pruneUnsetFields(fields map[string]interface{}) {
+	for key, val := range fields {
+		switch v := val.(type) {
+		case map[string]interface{}:
+			pruneUnsetFields(v)
+			if len(v) == 0 {
+				delete(fields, key)
+			}
+		case []interface{}:
+			if len(v) == 0 {
+				delete(fields, key)
+			}
+		case string:
+			if v == "" || v == "0s" {
+				delete(fields, key)
+			}
+		case float64:
+			if v == 0 {
+				delete(fields, key)
+			}
+		case bool:
+			if !v {
+				delete(fields, key)
+			}
+		case nil:
+			delete(fields, key)
+		}
+	}
+}
+
+// newKubeProxyConfiguration builds a KubeProxyConfiguration from a set of kube-proxy
+// command-line arguments.
+func newKubeProxyConfiguration(args map[string]operv1.ProxyArgumentList) (*kubeproxyconfig.KubeProxyConfiguration, error) {
 	// We use MergeKubeProxyArguments here to force a copy
 	ka := &kpcArgs{args: MergeKubeProxyArguments(args, nil)}
 
@@ -85,11 +192,9 @@ func GenerateKubeProxyConfiguration(args map[string]operv1.ProxyArgumentList) (s
 	kpc.EnableProfiling = ka.getBool("enable-profiling")
 
 	if err := ka.getError(); err != nil {
-		return "", err
+		return nil, err
 	}
-
-	buf, err := yaml.Marshal(kpc)
-	return string(buf), err
+	return kpc, nil
 }
 
 // kpcArgs is a helper to build the KubeProxyConfiguration. In particular, it
@@ -401,3 +401,56 @@ winkernel:
 		}
 	}
 }
+
+func TestMergeKubeProxyConfiguration(t *testing.T) {
+	defaults := map[string]operv1.ProxyArgumentList{
+		"proxy-mode":   {"iptables"},
+		"healthz-port": {"10255"},
+	}
+	overrides := map[string]operv1.ProxyArgumentList{
+		"bind-address": {"0.0.0.0"},
+		"metrics-port": {"29102"},
+	}
+
+	out, err := MergeKubeProxyConfiguration(defaults, `
+apiVersion: kubeproxy.config.k8s.io/v1alpha1
+kind: KubeProxyConfiguration
+bindAddress: 1.2.3.4
+mode: ipvs
+ipvs:
+  scheduler: wrr
+conntrack:
+  min: 262144
+nodePortAddresses:
+- 10.0.0.0/8
+`, overrides)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	kpc, err := ParseKubeProxyConfiguration(out)
+	if err != nil {
+		t.Fatalf("unexpected error parsing %s: %v", out, err)
+	}
+	if kpc.Mode != "ipvs" || kpc.IPVS.Scheduler != "wrr" {
+		t.Errorf("expected the config to override the defaults, got:\n%s", out)
+	}
+	if kpc.Conntrack.Min == nil || *kpc.Conntrack.Min != 262144 || len(kpc.NodePortAddresses) != 1 {
+		t.Errorf("expected the config fields to be kept, got:\n%s", out)
+	}
+	if kpc.HealthzBindAddress != "0.0.0.0:10255" {
+		t.Errorf("expected the defaults to be kept, got:\n%s", out)
+	}
+	if kpc.BindAddress != "0.0.0.0" || kpc.MetricsBindAddress != "0.0.0.0:29102" {
+		t.Errorf("expected the overrides to override the config, got:\n%s", out)
+	}
+
+	for _, config := range []string{
+		"mode: ipvs\nipvsScheduler: wrr\n",
+		"kind: KubeletConfiguration\n",
+		"mode: [ipvs]\n",
+	} {
+		if _, err := MergeKubeProxyConfiguration(defaults, config, overrides); err == nil {
+			t.Errorf("expected an error for invalid config %q", config)
+		}
+	}
+}
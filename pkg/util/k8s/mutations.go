@@ -0,0 +1,141 @@
+package k8s
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// MutationPhase orders the mutations of a list of rendered objects. All the mutations of a phase are
+// applied, in the order they were added, before those of the next phase, whatever the order they were
+// added in.
+type MutationPhase int
+
+const (
+	// AnnotatePhase mutations change the rendered objects in place
+	AnnotatePhase MutationPhase = iota
+	// ReplacePhase mutations swap rendered objects for others, e.g. the existing objects whose rollout
+	// is deferred, which are then not annotated
+	ReplacePhase
+	// RemovePhase mutations drop rendered objects, after they could have been replaced
+	RemovePhase
+)
+
+// Mutation is a change to a list of rendered objects
+type Mutation struct {
+	Phase MutationPhase
+	// Description identifies the mutation in the errors
+	Description string
+	Apply       func(objs []*uns.Unstructured) ([]*uns.Unstructured, error)
+}
+
+// Mutations is a list of mutations applied together, ordered by phase
+type Mutations []Mutation
+
+// Add appends mutations to the list
+func (m *Mutations) Add(mutations ...Mutation) {
+	*m = append(*m, mutations...)
+}
+
+// Apply applies the mutations to objs, phase by phase, and returns the mutated objects
+func (m Mutations) Apply(objs []*uns.Unstructured) ([]*uns.Unstructured, error) {
+	ordered := make(Mutations, len(m))
+	copy(ordered, m)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Phase < ordered[j].Phase })
+
+	var err error
+	for _, mutation := range ordered {
+		objs, err = mutation.Apply(objs)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to %s", mutation.Description)
+		}
+	}
+	return objs, nil
+}
+
+// ObjRef identifies an object of a list by its group, kind, namespace and name
+type ObjRef struct {
+	Group     string
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// Matches returns true if obj is the referenced object
+func (r ObjRef) Matches(obj *uns.Unstructured) bool {
+	return obj.GroupVersionKind().GroupKind() == schema.GroupKind{Group: r.Group, Kind: r.Kind} &&
+		obj.GetNamespace() == r.Namespace && obj.GetName() == r.Name
+}
+
+func (r ObjRef) String() string {
+	return fmt.Sprintf("%s %s/%s", schema.GroupKind{Group: r.Group, Kind: r.Kind}, r.Namespace, r.Name)
+}
+
+// SetAnnotation annotates the referenced object, if it is in the list, with the given key and value.
+// With podTemplate, the template of its pods is annotated as well, which rolls them out when the value
+// changes.
+func SetAnnotation(ref ObjRef, key, value string, podTemplate bool) Mutation {
+	return Mutation{
+		Phase:       AnnotatePhase,
+		Description: fmt.Sprintf("set annotation %s on %s", key, ref),
+		Apply: func(objs []*uns.Unstructured) ([]*uns.Unstructured, error) {
+			for _, obj := range objs {
+				if !ref.Matches(obj) {
+					continue
+				}
+				anno := obj.GetAnnotations()
+				if anno == nil {
+					anno = map[string]string{}
+				}
+				anno[key] = value
+				obj.SetAnnotations(anno)
+				if !podTemplate {
+					continue
+				}
+
+				anno, _, err := uns.NestedStringMap(obj.Object, "spec", "template", "metadata", "annotations")
+				if err != nil {
+					return nil, err
+				}
+				if anno == nil {
+					anno = map[string]string{}
+				}
+				anno[key] = value
+				if err := uns.SetNestedStringMap(obj.Object, anno, "spec", "template", "metadata", "annotations"); err != nil {
+					return nil, err
+				}
+			}
+			return objs, nil
+		},
+	}
+}
+
+// Replace replaces the object of the list that is the Same as new, if any, with new
+func Replace(new *uns.Unstructured) Mutation {
+	return Mutation{
+		Phase: ReplacePhase,
+		Description: fmt.Sprintf("replace %s", ObjRef{
+			Group:     new.GroupVersionKind().Group,
+			Kind:      new.GetKind(),
+			Namespace: new.GetNamespace(),
+			Name:      new.GetName(),
+		}),
+		Apply: func(objs []*uns.Unstructured) ([]*uns.Unstructured, error) {
+			return ReplaceObj(objs, new), nil
+		},
+	}
+}
+
+// Remove removes the referenced object from the list, if it is in it
+func Remove(ref ObjRef) Mutation {
+	return Mutation{
+		Phase:       RemovePhase,
+		Description: fmt.Sprintf("remove %s", ref),
+		Apply: func(objs []*uns.Unstructured) ([]*uns.Unstructured, error) {
+			return RemoveObjByGroupKindName(objs, ref.Group, ref.Kind, ref.Namespace, ref.Name), nil
+		},
+	}
+}
@@ -0,0 +1,90 @@
+package k8s
+
+import (
+	"fmt"
+	"testing"
+
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestMutations(t *testing.T) {
+	objs := []*uns.Unstructured{
+		parseManifest(t, `
+kind: DaemonSet
+apiVersion: apps/v1
+metadata:
+  name: foo1
+  namespace: myns
+spec:
+  template:
+    metadata:
+      labels:
+        app: foo1`),
+		parseManifest(t, `
+kind: Job
+apiVersion: batch/v1
+metadata:
+  name: foo2
+  namespace: myns`),
+	}
+	existing := parseManifest(t, `
+kind: DaemonSet
+apiVersion: apps/v1
+metadata:
+  name: foo1
+  namespace: myns
+  annotations:
+    existing: "true"`)
+
+	// added out of order: the replaced daemonset must not be annotated, and the removal must see the
+	// replaced objects
+	var mutations Mutations
+	mutations.Add(Remove(ObjRef{Group: "batch", Kind: "Job", Namespace: "myns", Name: "foo2"}))
+	mutations.Add(Replace(existing))
+	mutations.Add(SetAnnotation(ObjRef{Group: "apps", Kind: "DaemonSet", Namespace: "myns", Name: "foo1"}, "foo", "bar", true))
+	mutations.Add(SetAnnotation(ObjRef{Group: "apps", Kind: "DaemonSet", Namespace: "myns", Name: "other"}, "foo", "bar", true))
+
+	out, err := mutations.Apply(objs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 1 || out[0] != existing {
+		t.Fatalf("Expected only the existing daemonset, got %v", out)
+	}
+	if _, ok := existing.GetAnnotations()["foo"]; ok {
+		t.Fatal("The existing daemonset shouldn't be annotated")
+	}
+	anno, _, _ := uns.NestedStringMap(objs[0].Object, "spec", "template", "metadata", "annotations")
+	if objs[0].GetAnnotations()["foo"] != "bar" || anno["foo"] != "bar" {
+		t.Fatalf("Expected the rendered daemonset and its template to be annotated, got %v", objs[0].Object)
+	}
+
+	// without the pod template
+	if _, err := SetAnnotation(ObjRef{Group: "batch", Kind: "Job", Namespace: "myns", Name: "foo2"}, "foo", "bar", false).Apply(objs); err != nil {
+		t.Fatal(err)
+	}
+	if objs[1].GetAnnotations()["foo"] != "bar" {
+		t.Fatal("Expected the job to be annotated")
+	}
+	if _, ok, _ := uns.NestedFieldNoCopy(objs[1].Object, "spec"); ok {
+		t.Fatal("The job shouldn't have a template")
+	}
+
+	// the first error stops the mutations
+	applied := false
+	mutations = Mutations{
+		{Phase: RemovePhase, Description: "apply last", Apply: func(objs []*uns.Unstructured) ([]*uns.Unstructured, error) {
+			applied = true
+			return objs, nil
+		}},
+		{Phase: AnnotatePhase, Description: "fail", Apply: func(objs []*uns.Unstructured) ([]*uns.Unstructured, error) {
+			return nil, fmt.Errorf("failed")
+		}},
+	}
+	if _, err := mutations.Apply(objs); err == nil || err.Error() != "failed to fail: failed" {
+		t.Fatalf("Expected the error of the mutation, got %v", err)
+	}
+	if applied {
+		t.Fatal("The mutations of the later phases shouldn't be applied")
+	}
+}
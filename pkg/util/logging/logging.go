@@ -0,0 +1,228 @@
+// Package logging provides the structured loggers of the modules of the operator. Each module has a
+// verbosity of its own, and the logs are written either as text, through klog like the rest of the
+// operator, or as JSON, along with the klog output, for log pipelines. Both are set at runtime.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-logr/logr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"k8s.io/klog/v2"
+)
+
+// The modules of the operator with a logger of their own
+const (
+	Render    = "render"
+	Bootstrap = "bootstrap"
+	Apply     = "apply"
+	Status    = "status"
+)
+
+// The log formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// DefaultLevel is the verbosity of the modules unless set otherwise. The messages of the modules
+// logged unconditionally before they had a logger of their own are logged at this level or below.
+const DefaultLevel = 2
+
+// module is a module of the operator, with its verbosity
+type module struct {
+	name  string
+	level int32
+}
+
+var (
+	modules = map[string]*module{
+		Render:    {name: Render, level: DefaultLevel},
+		Bootstrap: {name: Bootstrap, level: DefaultLevel},
+		Apply:     {name: Apply, level: DefaultLevel},
+		Status:    {name: Status, level: DefaultLevel},
+	}
+
+	lock   sync.RWMutex
+	format = FormatText
+	// jsonLogger writes the logs to output when the format is JSON
+	jsonLogger *zap.Logger
+	output     io.Writer = os.Stderr
+)
+
+// Modules returns the names of the modules with a logger of their own
+func Modules() []string {
+	names := []string{}
+	for name := range modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Logger returns the logger of a module
+func Logger(name string) logr.Logger {
+	m, ok := modules[name]
+	if !ok {
+		panic(fmt.Sprintf("unknown logging module %q", name))
+	}
+	return &logger{module: m}
+}
+
+// ParseLevels parses the verbosity of modules, as "<module>=<level>[,<module>=<level>...]"
+func ParseLevels(value string) (map[string]int, error) {
+	levels := map[string]int{}
+	if strings.TrimSpace(value) == "" {
+		return levels, nil
+	}
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%q is not <module>=<level>", entry)
+		}
+		if _, ok := modules[parts[0]]; !ok {
+			return nil, fmt.Errorf("unknown module %q, must be one of %s", parts[0], strings.Join(Modules(), ", "))
+		}
+		level, err := strconv.Atoi(parts[1])
+		if err != nil || level < 0 {
+			return nil, fmt.Errorf("the level of %s must be a non-negative integer, is: %q", parts[0], parts[1])
+		}
+		levels[parts[0]] = level
+	}
+	return levels, nil
+}
+
+// Configure sets the log format, and the verbosity of the modules. The modules not in levels are set
+// back to DefaultLevel.
+func Configure(logFormat string, levels map[string]int) error {
+	if logFormat == "" {
+		logFormat = FormatText
+	}
+	if logFormat != FormatText && logFormat != FormatJSON {
+		return fmt.Errorf("the log format must be %q or %q, is: %q", FormatText, FormatJSON, logFormat)
+	}
+	for name, m := range modules {
+		level, ok := levels[name]
+		if !ok {
+			level = DefaultLevel
+		}
+		atomic.StoreInt32(&m.level, int32(level))
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if logFormat == format {
+		return nil
+	}
+	format = logFormat
+	if format == FormatJSON {
+		encoderConfig := zap.NewProductionEncoderConfig()
+		encoderConfig.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+		core := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.Lock(zapcore.AddSync(output)), zapcore.InfoLevel)
+		jsonLogger = zap.New(core)
+		// the klog output is written as JSON too, at the verbosity set by its flags
+		klog.SetLogger(&logger{module: &module{name: "klog"}})
+	} else {
+		klog.SetLogger(nil)
+		jsonLogger = nil
+	}
+	return nil
+}
+
+// logger is the logr.Logger of a module
+type logger struct {
+	module *module
+	// level is the verbosity of the messages of the logger
+	level  int
+	names  []string
+	values []interface{}
+}
+
+var _ logr.Logger = &logger{}
+
+// Enabled tells whether the messages of the logger are logged, at the verbosity of its module
+func (l *logger) Enabled() bool {
+	return int32(l.level) <= atomic.LoadInt32(&l.module.level)
+}
+
+func (l *logger) Info(msg string, keysAndValues ...interface{}) {
+	if l.Enabled() {
+		l.write(zapcore.InfoLevel, klog.InfoDepth, msg, nil, keysAndValues)
+	}
+}
+
+// Error logs a message regardless of the verbosity of the module
+func (l *logger) Error(err error, msg string, keysAndValues ...interface{}) {
+	l.write(zapcore.ErrorLevel, klog.ErrorDepth, msg, err, keysAndValues)
+}
+
+func (l *logger) V(level int) logr.Logger {
+	c := *l
+	c.level += level
+	return &c
+}
+
+func (l *logger) WithValues(keysAndValues ...interface{}) logr.Logger {
+	c := *l
+	c.values = append(append([]interface{}{}, l.values...), keysAndValues...)
+	return &c
+}
+
+func (l *logger) WithName(name string) logr.Logger {
+	c := *l
+	c.names = append(append([]string{}, l.names...), name)
+	return &c
+}
+
+// write logs a message as JSON, or else as text through klog, prefixed with the name of the module
+func (l *logger) write(level zapcore.Level, klogDepth func(int, ...interface{}), msg string, err error, keysAndValues []interface{}) {
+	// the messages of klog end with a newline
+	msg = strings.TrimSuffix(msg, "\n")
+	keysAndValues = append(append([]interface{}{}, l.values...), keysAndValues...)
+	name := strings.Join(append([]string{l.module.name}, l.names...), ".")
+
+	lock.RLock()
+	jl := jsonLogger
+	lock.RUnlock()
+	if jl == nil {
+		line := name + ": " + msg
+		if err != nil {
+			line += ": " + err.Error()
+		}
+		for i := 0; i < len(keysAndValues); i += 2 {
+			if i+1 < len(keysAndValues) {
+				line += fmt.Sprintf(" %v=%v", keysAndValues[i], keysAndValues[i+1])
+			} else {
+				line += fmt.Sprintf(" %v", keysAndValues[i])
+			}
+		}
+		klogDepth(2, line)
+		return
+	}
+
+	fields := []zap.Field{zap.String("module", name)}
+	if l.level > 0 {
+		fields = append(fields, zap.Int("v", l.level))
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if i+1 < len(keysAndValues) {
+			fields = append(fields, zap.Any(fmt.Sprint(keysAndValues[i]), keysAndValues[i+1]))
+		} else {
+			fields = append(fields, zap.Any("extra", keysAndValues[i]))
+		}
+	}
+	if ce := jl.Check(level, msg); ce != nil {
+		ce.Write(fields...)
+	}
+}
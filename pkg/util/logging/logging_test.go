@@ -0,0 +1,66 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestParseLevels(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(ParseLevels("")).To(BeEmpty())
+	g.Expect(ParseLevels("render=4, apply=0")).To(Equal(map[string]int{Render: 4, Apply: 0}))
+
+	for _, value := range []string{"render", "render=-1", "render=debug", "kube-proxy=2"} {
+		_, err := ParseLevels(value)
+		g.Expect(err).To(HaveOccurred(), value)
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	g := NewGomegaWithT(t)
+	defer func() {
+		g.Expect(Configure(FormatText, nil)).To(Succeed())
+		output = os.Stderr
+	}()
+
+	render := Logger(Render)
+	g.Expect(render.V(DefaultLevel).Enabled()).To(BeTrue())
+	g.Expect(render.V(DefaultLevel + 1).Enabled()).To(BeFalse())
+
+	buf := &bytes.Buffer{}
+	output = buf
+	g.Expect(Configure(FormatJSON, map[string]int{Render: 4, Apply: 0})).To(Succeed())
+	g.Expect(render.V(4).Enabled()).To(BeTrue())
+	g.Expect(Logger(Apply).V(1).Enabled()).To(BeFalse())
+	// the modules not set are back to the default level
+	g.Expect(Logger(Status).V(DefaultLevel).Enabled()).To(BeTrue())
+
+	render.WithName("ovn").WithValues("network", "OVNKubernetes").V(3).Info("Rendered", "objects", 42)
+	Logger(Apply).V(1).Info("not logged")
+	Logger(Apply).Error(errors.New("conflict"), "update was unsuccessful", "object", "(apps/v1, Kind=DaemonSet) ns/name")
+
+	decoder := json.NewDecoder(buf)
+	entry := map[string]interface{}{}
+	g.Expect(decoder.Decode(&entry)).To(Succeed())
+	g.Expect(entry).To(HaveKeyWithValue("level", "info"))
+	g.Expect(entry).To(HaveKeyWithValue("msg", "Rendered"))
+	g.Expect(entry).To(HaveKeyWithValue("module", "render.ovn"))
+	g.Expect(entry).To(HaveKeyWithValue("v", BeNumerically("==", 3)))
+	g.Expect(entry).To(HaveKeyWithValue("network", "OVNKubernetes"))
+	g.Expect(entry).To(HaveKeyWithValue("objects", BeNumerically("==", 42)))
+
+	entry = map[string]interface{}{}
+	g.Expect(decoder.Decode(&entry)).To(Succeed())
+	g.Expect(entry).To(HaveKeyWithValue("level", "error"))
+	g.Expect(entry).To(HaveKeyWithValue("module", "apply"))
+	g.Expect(entry).To(HaveKeyWithValue("error", "conflict"))
+	g.Expect(decoder.More()).To(BeFalse())
+
+	g.Expect(Configure("yaml", nil)).NotTo(Succeed())
+}
@@ -0,0 +1,238 @@
+// Package manifestdiff compares two sets of rendered manifests field by field, to assess what an
+// upgrade changes on the cluster.
+package manifestdiff
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ChangeType is the type of the change of an object or of a field
+type ChangeType string
+
+const (
+	Added   ChangeType = "Added"
+	Removed ChangeType = "Removed"
+	Changed ChangeType = "Changed"
+)
+
+// FieldChange is the change of a field of an object, identified by its path, e.g.
+// ".spec.template.spec.containers[name=ovnkube-node].image"
+type FieldChange struct {
+	Type ChangeType `json:"type"`
+	Path string     `json:"path"`
+}
+
+// ObjectDiff is the change of an object, with the changes of its fields when it is Changed
+type ObjectDiff struct {
+	Type      ChangeType    `json:"type"`
+	Group     string        `json:"group,omitempty"`
+	Kind      string        `json:"kind"`
+	Namespace string        `json:"namespace,omitempty"`
+	Name      string        `json:"name"`
+	Fields    []FieldChange `json:"fields,omitempty"`
+}
+
+// ID returns the group, kind, namespace and name of the object
+func (d ObjectDiff) ID() string {
+	kind := d.Kind
+	if d.Group != "" {
+		kind = d.Kind + "." + d.Group
+	}
+	if d.Namespace != "" {
+		return fmt.Sprintf("%s %s/%s", kind, d.Namespace, d.Name)
+	}
+	return fmt.Sprintf("%s %s", kind, d.Name)
+}
+
+type objectKey struct {
+	group, kind, namespace, name string
+}
+
+func keyOf(obj *uns.Unstructured) objectKey {
+	gvk := obj.GroupVersionKind()
+	return objectKey{group: gvk.Group, kind: gvk.Kind, namespace: obj.GetNamespace(), name: obj.GetName()}
+}
+
+// Diff returns the objects added, removed and changed from the manifests in from to those in to,
+// sorted by group, kind, namespace and name. The objects are matched by group, kind, namespace and
+// name, regardless of their API version.
+func Diff(from, to []*uns.Unstructured) []ObjectDiff {
+	fromObjs := map[objectKey]*uns.Unstructured{}
+	for _, obj := range from {
+		fromObjs[keyOf(obj)] = obj
+	}
+	toObjs := map[objectKey]*uns.Unstructured{}
+	for _, obj := range to {
+		toObjs[keyOf(obj)] = obj
+	}
+
+	diffs := []ObjectDiff{}
+	newDiff := func(t ChangeType, k objectKey) ObjectDiff {
+		return ObjectDiff{Type: t, Group: k.group, Kind: k.kind, Namespace: k.namespace, Name: k.name}
+	}
+	for k, fromObj := range fromObjs {
+		toObj, ok := toObjs[k]
+		if !ok {
+			diffs = append(diffs, newDiff(Removed, k))
+			continue
+		}
+		fields := []FieldChange{}
+		diffValues("", fromObj.Object, toObj.Object, &fields)
+		if len(fields) > 0 {
+			d := newDiff(Changed, k)
+			d.Fields = fields
+			diffs = append(diffs, d)
+		}
+	}
+	for k := range toObjs {
+		if _, ok := fromObjs[k]; !ok {
+			diffs = append(diffs, newDiff(Added, k))
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		a, b := diffs[i], diffs[j]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return diffs
+}
+
+// diffValues appends the changes from one value to the other, recursing into the maps and lists
+func diffValues(path string, from, to interface{}, out *[]FieldChange) {
+	switch fromValue := from.(type) {
+	case map[string]interface{}:
+		toValue, ok := to.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := []string{}
+		for k := range fromValue {
+			keys = append(keys, k)
+		}
+		for k := range toValue {
+			if _, ok := fromValue[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fieldPath := path + "." + k
+			fromField, inFrom := fromValue[k]
+			toField, inTo := toValue[k]
+			switch {
+			case !inTo:
+				*out = append(*out, FieldChange{Type: Removed, Path: fieldPath})
+			case !inFrom:
+				*out = append(*out, FieldChange{Type: Added, Path: fieldPath})
+			default:
+				diffValues(fieldPath, fromField, toField, out)
+			}
+		}
+		return
+
+	case []interface{}:
+		toValue, ok := to.([]interface{})
+		if !ok {
+			break
+		}
+		if fromNames, toNames := elementNames(fromValue), elementNames(toValue); fromNames != nil && toNames != nil {
+			diffNamedLists(path, fromValue, toValue, fromNames, toNames, out)
+			return
+		}
+		for i := 0; i < len(fromValue) || i < len(toValue); i++ {
+			elementPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(toValue):
+				*out = append(*out, FieldChange{Type: Removed, Path: elementPath})
+			case i >= len(fromValue):
+				*out = append(*out, FieldChange{Type: Added, Path: elementPath})
+			default:
+				diffValues(elementPath, fromValue[i], toValue[i], out)
+			}
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*out = append(*out, FieldChange{Type: Changed, Path: path})
+	}
+}
+
+// elementNames returns the names of the elements of a list, when they are all objects with a
+// distinct name, like the containers, the volumes or the environment variables, or nil
+func elementNames(list []interface{}) []string {
+	if len(list) == 0 {
+		return nil
+	}
+	names := []string{}
+	seen := map[string]bool{}
+	for _, element := range list {
+		m, ok := element.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		name, ok := m["name"].(string)
+		if !ok || seen[name] {
+			return nil
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// diffNamedLists compares the elements of two lists by name, so that an inserted element does not
+// report every following element as changed
+func diffNamedLists(path string, from, to []interface{}, fromNames, toNames []string, out *[]FieldChange) {
+	toIndex := map[string]int{}
+	for i, name := range toNames {
+		toIndex[name] = i
+	}
+	fromIndex := map[string]int{}
+	for i, name := range fromNames {
+		fromIndex[name] = i
+		elementPath := fmt.Sprintf("%s[name=%s]", path, name)
+		if j, ok := toIndex[name]; ok {
+			diffValues(elementPath, from[i], to[j], out)
+		} else {
+			*out = append(*out, FieldChange{Type: Removed, Path: elementPath})
+		}
+	}
+	for _, name := range toNames {
+		if _, ok := fromIndex[name]; !ok {
+			*out = append(*out, FieldChange{Type: Added, Path: fmt.Sprintf("%s[name=%s]", path, name)})
+		}
+	}
+}
+
+// WriteText writes the changes in a human readable form, one line per object and per field
+func WriteText(w io.Writer, diffs []ObjectDiff) error {
+	if len(diffs) == 0 {
+		_, err := fmt.Fprintln(w, "No changes")
+		return err
+	}
+	var b strings.Builder
+	for _, d := range diffs {
+		fmt.Fprintf(&b, "%s %s\n", d.Type, d.ID())
+		for _, f := range d.Fields {
+			fmt.Fprintf(&b, "  %s %s\n", f.Type, f.Path)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
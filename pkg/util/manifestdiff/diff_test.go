@@ -0,0 +1,130 @@
+package manifestdiff
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	uns "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+func TestDiff(t *testing.T) {
+	from := []*uns.Unstructured{
+		parseManifest(t, `
+kind: DaemonSet
+apiVersion: apps/v1
+metadata:
+  name: ovnkube-node
+  namespace: openshift-ovn-kubernetes
+spec:
+  template:
+    spec:
+      containers:
+      - name: ovn-controller
+        image: ovn-image
+      - name: ovnkube-node
+        image: ovn-image
+        args: ["--a", "--b"]
+      hostNetwork: true`),
+		parseManifest(t, `
+kind: ConfigMap
+apiVersion: v1
+metadata:
+  name: removed
+  namespace: openshift-ovn-kubernetes`),
+		parseManifest(t, `
+kind: ConfigMap
+apiVersion: v1
+metadata:
+  name: unchanged
+  namespace: openshift-ovn-kubernetes
+data:
+  a: b`),
+	}
+	to := []*uns.Unstructured{
+		parseManifest(t, `
+kind: DaemonSet
+apiVersion: apps/v1
+metadata:
+  name: ovnkube-node
+  namespace: openshift-ovn-kubernetes
+spec:
+  template:
+    spec:
+      containers:
+      - name: kube-rbac-proxy
+        image: kube-rbac-proxy-image
+      - name: ovn-controller
+        image: ovn-image
+      - name: ovnkube-node
+        image: ovn-image-2
+        args: ["--a"]
+      priorityClassName: system-node-critical`),
+		parseManifest(t, `
+kind: ConfigMap
+apiVersion: v1
+metadata:
+  name: unchanged
+  namespace: openshift-ovn-kubernetes
+data:
+  a: b`),
+		parseManifest(t, `
+kind: ClusterRole
+apiVersion: rbac.authorization.k8s.io/v1
+metadata:
+  name: added`),
+	}
+
+	expected := []ObjectDiff{
+		{Type: Removed, Kind: "ConfigMap", Namespace: "openshift-ovn-kubernetes", Name: "removed"},
+		{Type: Changed, Group: "apps", Kind: "DaemonSet", Namespace: "openshift-ovn-kubernetes", Name: "ovnkube-node", Fields: []FieldChange{
+			{Type: Removed, Path: ".spec.template.spec.containers[name=ovnkube-node].args[1]"},
+			{Type: Changed, Path: ".spec.template.spec.containers[name=ovnkube-node].image"},
+			{Type: Added, Path: ".spec.template.spec.containers[name=kube-rbac-proxy]"},
+			{Type: Removed, Path: ".spec.template.spec.hostNetwork"},
+			{Type: Added, Path: ".spec.template.spec.priorityClassName"},
+		}},
+		{Type: Added, Group: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: "added"},
+	}
+
+	diffs := Diff(from, to)
+	if !reflect.DeepEqual(diffs, expected) {
+		t.Fatalf("unexpected diff:\n%#v\nexpected:\n%#v", diffs, expected)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := WriteText(buf, diffs); err != nil {
+		t.Fatal(err)
+	}
+	text := `Removed ConfigMap openshift-ovn-kubernetes/removed
+Changed DaemonSet.apps openshift-ovn-kubernetes/ovnkube-node
+  Removed .spec.template.spec.containers[name=ovnkube-node].args[1]
+  Changed .spec.template.spec.containers[name=ovnkube-node].image
+  Added .spec.template.spec.containers[name=kube-rbac-proxy]
+  Removed .spec.template.spec.hostNetwork
+  Added .spec.template.spec.priorityClassName
+Added ClusterRole.rbac.authorization.k8s.io added
+`
+	if buf.String() != text {
+		t.Fatalf("unexpected text:\n%s", buf.String())
+	}
+
+	if diffs := Diff(to, to); len(diffs) != 0 {
+		t.Fatalf("unexpected diff of identical manifests: %#v", diffs)
+	}
+}
+
+func parseManifest(t *testing.T, manifest string) *uns.Unstructured {
+	t.Helper()
+	buf := bytes.Buffer{}
+	buf.WriteString(manifest)
+	decoder := yaml.NewYAMLOrJSONDecoder(&buf, 4096)
+	out := uns.Unstructured{}
+
+	if err := decoder.Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	return &out
+}